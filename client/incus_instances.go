@@ -3304,6 +3304,30 @@ func (r *ProtocolIncus) RepairInstance(name string, repair api.InstanceDebugRepa
 	return nil
 }
 
+// CaptureInstanceDevice captures the packets of an instance NIC, writing them to the output of the arguments.
+func (r *ProtocolIncus) CaptureInstanceDevice(instanceName string, deviceName string, capture api.NetworkCapturePost, args *NetworkCaptureArgs) (Operation, error) {
+	if !r.HasExtension("network_capture") {
+		return nil, errors.New("The server is missing the required \"network_capture\" API extension")
+	}
+
+	if args == nil || args.Output == nil {
+		return nil, errors.New("An output must be set")
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", fmt.Sprintf("%s/%s/devices/%s/capture", path, url.PathEscape(instanceName), url.PathEscape(deviceName)), capture, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return r.captureStream(op, args)
+}
+
 func (r *ProtocolIncus) getInstanceNVRAM(name string, guid string, varName string, accept string) (*http.Response, error) {
 	path, _, err := r.instanceTypeToPath(api.InstanceTypeVM)
 	if err != nil {
@@ -0,0 +1,113 @@
+package incus
+
+import (
+	"github.com/lxc/incus/v7/shared/api"
+)
+
+// GetNetworkEgressGatewayAddresses returns a list of network egress gateway addresses.
+func (r *ProtocolIncus) GetNetworkEgressGatewayAddresses(networkName string) ([]string, error) {
+	err := r.CheckExtension("network_egress_gateway")
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the raw URL values.
+	urls := []string{}
+	u := api.NewURL().Path("networks", networkName, "egress-gateways")
+	_, err = r.queryStruct("GET", u.String(), nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it.
+	return urlsToResourceNames(u.String(), urls...)
+}
+
+// GetNetworkEgressGateways returns a list of Network egress gateway structs.
+func (r *ProtocolIncus) GetNetworkEgressGateways(networkName string) ([]api.NetworkEgressGateway, error) {
+	err := r.CheckExtension("network_egress_gateway")
+	if err != nil {
+		return nil, err
+	}
+
+	gateways := []api.NetworkEgressGateway{}
+
+	// Fetch the raw value.
+	u := api.NewURL().Path("networks", networkName, "egress-gateways").WithQuery("recursion", "1")
+	_, err = r.queryStruct("GET", u.String(), nil, "", &gateways)
+	if err != nil {
+		return nil, err
+	}
+
+	return gateways, nil
+}
+
+// GetNetworkEgressGateway returns a Network egress gateway entry for the provided network and address.
+func (r *ProtocolIncus) GetNetworkEgressGateway(networkName string, address string) (*api.NetworkEgressGateway, string, error) {
+	err := r.CheckExtension("network_egress_gateway")
+	if err != nil {
+		return nil, "", err
+	}
+
+	gateway := api.NetworkEgressGateway{}
+
+	// Fetch the raw value.
+	u := api.NewURL().Path("networks", networkName, "egress-gateways", address)
+	etag, err := r.queryStruct("GET", u.String(), nil, "", &gateway)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &gateway, etag, nil
+}
+
+// CreateNetworkEgressGateway defines a new network egress gateway using the provided struct.
+func (r *ProtocolIncus) CreateNetworkEgressGateway(networkName string, gateway api.NetworkEgressGatewaysPost) error {
+	err := r.CheckExtension("network_egress_gateway")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	u := api.NewURL().Path("networks", networkName, "egress-gateways")
+	_, _, err = r.query("POST", u.String(), gateway, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateNetworkEgressGateway updates the network egress gateway to match the provided struct.
+func (r *ProtocolIncus) UpdateNetworkEgressGateway(networkName string, address string, gateway api.NetworkEgressGatewayPut, ETag string) error {
+	err := r.CheckExtension("network_egress_gateway")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	u := api.NewURL().Path("networks", networkName, "egress-gateways", address)
+	_, _, err = r.query("PUT", u.String(), gateway, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteNetworkEgressGateway deletes an existing network egress gateway.
+func (r *ProtocolIncus) DeleteNetworkEgressGateway(networkName string, address string) error {
+	err := r.CheckExtension("network_egress_gateway")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	u := api.NewURL().Path("networks", networkName, "egress-gateways", address)
+	_, _, err = r.query("DELETE", u.String(), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
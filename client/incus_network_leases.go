@@ -0,0 +1,75 @@
+package incus
+
+import (
+	"github.com/lxc/incus/v7/shared/api"
+)
+
+// GetNetworkLease returns a Network address reservation entry for the provided network and address.
+func (r *ProtocolIncus) GetNetworkLease(networkName string, address string) (*api.NetworkLease, string, error) {
+	err := r.CheckExtension("network_leases_reservations")
+	if err != nil {
+		return nil, "", err
+	}
+
+	lease := api.NetworkLease{}
+
+	// Fetch the raw value.
+	u := api.NewURL().Path("networks", networkName, "leases", address)
+	etag, err := r.queryStruct("GET", u.String(), nil, "", &lease)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &lease, etag, nil
+}
+
+// CreateNetworkLease defines a new network address reservation using the provided struct.
+func (r *ProtocolIncus) CreateNetworkLease(networkName string, lease api.NetworkLeasesPost) error {
+	err := r.CheckExtension("network_leases_reservations")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	u := api.NewURL().Path("networks", networkName, "leases")
+	_, _, err = r.query("POST", u.String(), lease, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateNetworkLease updates the network address reservation to match the provided struct.
+func (r *ProtocolIncus) UpdateNetworkLease(networkName string, address string, lease api.NetworkLeasePut, ETag string) error {
+	err := r.CheckExtension("network_leases_reservations")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	u := api.NewURL().Path("networks", networkName, "leases", address)
+	_, _, err = r.query("PUT", u.String(), lease, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteNetworkLease deletes an existing network address reservation.
+func (r *ProtocolIncus) DeleteNetworkLease(networkName string, address string) error {
+	err := r.CheckExtension("network_leases_reservations")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	u := api.NewURL().Path("networks", networkName, "leases", address)
+	_, _, err = r.query("DELETE", u.String(), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"net/url"
 
 	"github.com/lxc/incus/v7/shared/api"
+	"github.com/lxc/incus/v7/shared/ws"
 )
 
 // GetNetworkNames returns a list of network names.
@@ -210,3 +211,54 @@ func (r *ProtocolIncus) DeleteNetwork(name string) error {
 
 	return nil
 }
+
+// CaptureNetwork captures the packets of a network, writing them to the output of the arguments.
+func (r *ProtocolIncus) CaptureNetwork(name string, capture api.NetworkCapturePost, args *NetworkCaptureArgs) (Operation, error) {
+	if !r.HasExtension("network_capture") {
+		return nil, errors.New("The server is missing the required \"network_capture\" API extension")
+	}
+
+	if args == nil || args.Output == nil {
+		return nil, errors.New("An output must be set")
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", fmt.Sprintf("/networks/%s/capture", url.PathEscape(name)), capture, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return r.captureStream(op, args)
+}
+
+// captureStream connects to the websocket of a capture operation and copies the packets to the output.
+func (r *ProtocolIncus) captureStream(op Operation, args *NetworkCaptureArgs) (Operation, error) {
+	opAPI := op.Get()
+
+	var secret string
+
+	fds, ok := opAPI.Metadata["fds"].(map[string]any)
+	if ok {
+		secret, _ = fds["0"].(string)
+	}
+
+	if secret == "" {
+		return nil, errors.New("Did not receive a file descriptor for the capture")
+	}
+
+	conn, err := r.GetOperationWebsocket(opAPI.ID, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		<-ws.MirrorWrite(conn, args.Output)
+		_ = conn.Close()
+
+		if args.DataDone != nil {
+			close(args.DataDone)
+		}
+	}()
+
+	return op, nil
+}
@@ -221,6 +221,25 @@ func (r *ProtocolIncus) GetStoragePoolBucket(poolName string, bucketName string)
 	return &bucket, etag, nil
 }
 
+// GetStoragePoolBucketState returns the usage of a storage bucket and of its keys.
+func (r *ProtocolIncus) GetStoragePoolBucketState(poolName string, bucketName string) (*api.StorageBucketState, error) {
+	err := r.CheckExtension("storage_bucket_quotas")
+	if err != nil {
+		return nil, err
+	}
+
+	state := api.StorageBucketState{}
+
+	// Fetch the raw value.
+	u := api.NewURL().Path("storage-pools", poolName, "buckets", bucketName, "state")
+	_, err = r.queryStruct("GET", u.String(), nil, "", &state)
+	if err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
 // GetStoragePoolBucketFull returns a full storage bucket entry for the provided pool and bucket name.
 func (r *ProtocolIncus) GetStoragePoolBucketFull(poolName string, bucketName string) (*api.StorageBucketFull, string, error) {
 	err := r.CheckExtension("storage_bucket_full")
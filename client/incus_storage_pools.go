@@ -143,3 +143,19 @@ func (r *ProtocolIncus) GetStoragePoolResources(name string) (*api.ResourcesStor
 
 	return &res, nil
 }
+
+// CheckStoragePool runs an integrity check of the storage pool.
+func (r *ProtocolIncus) CheckStoragePool(name string) (Operation, error) {
+	err := r.CheckExtension("storage_pool_check")
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", fmt.Sprintf("/storage-pools/%s/check", url.PathEscape(name)), nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
@@ -179,6 +179,7 @@ type InstanceServer interface {
 
 	GetInstanceDebugMemory(name string, format string) (rc io.ReadCloser, err error)
 	RepairInstance(name string, repair api.InstanceDebugRepairPost) (err error)
+	CaptureInstanceDevice(instanceName string, deviceName string, capture api.NetworkCapturePost, args *NetworkCaptureArgs) (op Operation, err error)
 	GetInstanceNVRAM(name string) (vars map[string]map[string]*api.InstanceNVRAMVariable, err error)
 	GetInstanceNVRAMGUID(name string, guid string) (vars map[string]*api.InstanceNVRAMVariable, err error)
 	GetRawInstanceNVRAMGUIDVar(name string, guid string, varName string) (resp []byte, attributes uint32, err error)
@@ -224,6 +225,15 @@ type InstanceServer interface {
 	RenameNetwork(name string, network api.NetworkPost) (err error)
 	DeleteNetwork(name string) (err error)
 
+	// Network capture functions ("network_capture" API extension)
+	CaptureNetwork(name string, capture api.NetworkCapturePost, args *NetworkCaptureArgs) (op Operation, err error)
+
+	// Network address reservation functions ("network_leases_reservations" API extension)
+	GetNetworkLease(networkName string, address string) (lease *api.NetworkLease, ETag string, err error)
+	CreateNetworkLease(networkName string, lease api.NetworkLeasesPost) error
+	UpdateNetworkLease(networkName string, address string, lease api.NetworkLeasePut, ETag string) (err error)
+	DeleteNetworkLease(networkName string, address string) (err error)
+
 	// Network forward functions ("network_forward" API extension)
 	GetNetworkForwardAddresses(networkName string) ([]string, error)
 	GetNetworkForwards(networkName string) ([]api.NetworkForward, error)
@@ -241,6 +251,14 @@ type InstanceServer interface {
 	DeleteNetworkLoadBalancer(networkName string, listenAddress string) (err error)
 	GetNetworkLoadBalancerState(networkName string, listenAddress string) (lbState *api.NetworkLoadBalancerState, err error)
 
+	// Network egress gateway functions ("network_egress_gateway" API extension)
+	GetNetworkEgressGatewayAddresses(networkName string) ([]string, error)
+	GetNetworkEgressGateways(networkName string) ([]api.NetworkEgressGateway, error)
+	GetNetworkEgressGateway(networkName string, address string) (gateway *api.NetworkEgressGateway, ETag string, err error)
+	CreateNetworkEgressGateway(networkName string, gateway api.NetworkEgressGatewaysPost) error
+	UpdateNetworkEgressGateway(networkName string, address string, gateway api.NetworkEgressGatewayPut, ETag string) (err error)
+	DeleteNetworkEgressGateway(networkName string, address string) (err error)
+
 	// Network peer functions ("network_peer" API extension)
 	GetNetworkPeerNames(networkName string) ([]string, error)
 	GetNetworkPeers(networkName string) ([]api.NetworkPeer, error)
@@ -343,6 +361,7 @@ type InstanceServer interface {
 	CreateStoragePool(pool api.StoragePoolsPost) (err error)
 	UpdateStoragePool(name string, pool api.StoragePoolPut, ETag string) (err error)
 	DeleteStoragePool(name string) (err error)
+	CheckStoragePool(name string) (op Operation, err error)
 
 	// Storage bucket functions ("storage_buckets" API extension)
 	GetStoragePoolBucketNames(poolName string) ([]string, error)
@@ -355,6 +374,7 @@ type InstanceServer interface {
 	GetStoragePoolBucketsFull(poolName string) ([]api.StorageBucketFull, error)
 	GetStoragePoolBucketsFullWithFilter(poolName string, filters []string) (bucket []api.StorageBucketFull, err error)
 	GetStoragePoolBucket(poolName string, bucketName string) (bucket *api.StorageBucket, ETag string, err error)
+	GetStoragePoolBucketState(poolName string, bucketName string) (state *api.StorageBucketState, err error)
 	GetStoragePoolBucketFull(poolName string, bucketName string) (bucket *api.StorageBucketFull, ETag string, err error)
 	CreateStoragePoolBucket(poolName string, bucket api.StorageBucketsPost) (*api.StorageBucketKey, error)
 	UpdateStoragePoolBucket(poolName string, bucketName string, bucket api.StorageBucketPut, ETag string) (err error)
@@ -742,6 +762,15 @@ type InstanceNBDArgs struct {
 	Reuse bool
 }
 
+// The NetworkCaptureArgs struct is used to pass additional options during a packet capture.
+type NetworkCaptureArgs struct {
+	// Writer receiving the captured packets in the pcapng format
+	Output io.Writer
+
+	// Channel that will be closed when all the captured packets are written
+	DataDone chan bool
+}
+
 // The InstanceFileResponse struct is used as part of the response for a instance file download.
 type InstanceFileResponse struct {
 	// User id that owns the file
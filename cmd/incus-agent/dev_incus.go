@@ -151,6 +151,29 @@ var DevIncusMetadataGet = devIncusHandler{"/1.0/meta-data", func(d *Daemon, w ht
 	return okResponse(metaData, "raw")
 }}
 
+var DevIncusIgnitionGet = devIncusHandler{"/1.0/ignition", func(d *Daemon, w http.ResponseWriter, r *http.Request) *devIncusResponse {
+	client, err := getVsockClient(d)
+	if err != nil {
+		return smartResponse(fmt.Errorf("Failed connecting to host over vsock: %w", err))
+	}
+
+	defer client.Disconnect()
+
+	resp, _, err := client.RawQuery("GET", "/1.0/ignition", nil, "")
+	if err != nil {
+		return smartResponse(err)
+	}
+
+	var ignitionConfig string
+
+	err = resp.MetadataAsStruct(&ignitionConfig)
+	if err != nil {
+		return smartResponse(fmt.Errorf("Failed parsing response from host: %w", err))
+	}
+
+	return okResponse(ignitionConfig, "raw")
+}}
+
 var devIncusEventsGet = devIncusHandler{"/1.0/events", func(d *Daemon, w http.ResponseWriter, r *http.Request) *devIncusResponse {
 	err := eventsGet(d, r).Render(w)
 	if err != nil {
@@ -226,6 +249,7 @@ var handlers = []devIncusHandler{
 	DevIncusConfigGet,
 	DevIncusConfigKeyGet,
 	DevIncusMetadataGet,
+	DevIncusIgnitionGet,
 	devIncusEventsGet,
 	DevIncusDevicesGet,
 }
@@ -18,6 +18,7 @@ import (
 	"github.com/lxc/incus/v7/cmd/incus/color"
 	u "github.com/lxc/incus/v7/cmd/incus/usage"
 	"github.com/lxc/incus/v7/internal/i18n"
+	"github.com/lxc/incus/v7/internal/instance"
 	"github.com/lxc/incus/v7/shared/api"
 	config "github.com/lxc/incus/v7/shared/cliconfig"
 	cli "github.com/lxc/incus/v7/shared/cmd"
@@ -517,17 +518,55 @@ func (c *cmdAction) run(cmd *cobra.Command, args []string) error {
 		return errors.New(i18n.G("--override-boot only works with a single instance"))
 	}
 
-	// Run the action for every listed instance
-	var wg sync.WaitGroup
-	wg.Add(len(batch))
-	for i := range batch {
-		go func(entry *batchEntry) {
-			defer wg.Done()
-			entry.err = c.doAction(action, c.global.conf, entry.parsed)
-		}(&batch[i])
+	// Start instances after the ones they depend on.
+	levels := make([]int, len(batch))
+	if action == "start" && len(batch) > 1 {
+		depends := map[string][]string{}
+		keys := make([]string, len(batch))
+
+		for i, entry := range batch {
+			keys[i] = entry.parsed.RemoteName + ":" + entry.parsed.RemoteObject.String
+			depends[keys[i]] = nil
+
+			// Errors are reported when running the action.
+			inst, _, err := entry.parsed.RemoteServer.GetInstance(entry.parsed.RemoteObject.String)
+			if err != nil {
+				continue
+			}
+
+			for _, name := range instance.DependsList(inst.ExpandedConfig) {
+				depends[keys[i]] = append(depends[keys[i]], entry.parsed.RemoteName+":"+name)
+			}
+		}
+
+		dependsLevels := instance.DependsLevels(depends)
+		for i := range batch {
+			levels[i] = dependsLevels[keys[i]]
+		}
+	}
+
+	maxLevel := 0
+	for _, level := range levels {
+		maxLevel = max(maxLevel, level)
 	}
 
-	wg.Wait()
+	// Run the action for every listed instance
+	for level := 0; level <= maxLevel; level++ {
+		var wg sync.WaitGroup
+		for i := range batch {
+			if levels[i] != level {
+				continue
+			}
+
+			wg.Add(1)
+			go func(entry *batchEntry) {
+				defer wg.Done()
+				entry.err = c.doAction(action, c.global.conf, entry.parsed)
+			}(&batch[i])
+		}
+
+		wg.Wait()
+	}
 
 	// Single instance is easy
 	if len(batch) == 1 {
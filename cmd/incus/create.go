@@ -328,6 +328,7 @@ func (c *cmdCreate) create(conf *config.Config, parsed []*u.Parsed, launch bool)
 	req.Devices = devicesMap
 
 	var opInfo api.Operation
+	var imgProperties map[string]string
 
 	// If an image is provided, use it.
 	if parsed[0].BranchID == 1 {
@@ -346,6 +347,8 @@ func (c *cmdCreate) create(conf *config.Config, parsed []*u.Parsed, launch bool)
 			req.Type = api.InstanceType(imgInfo.Type)
 		}
 
+		imgProperties = imgInfo.Properties
+
 		// Create the instance
 		op, err := d.CreateInstanceFromImage(imgServer, *imgInfo, req)
 		if err != nil {
@@ -413,6 +416,9 @@ func (c *cmdCreate) create(conf *config.Config, parsed []*u.Parsed, launch bool)
 	// Validate the network setup
 	c.checkNetwork(d, instanceName)
 
+	// Validate the provisioning setup
+	c.checkProvisioning(d, instanceName, imgProperties["provisioning"])
+
 	p.RemoteObject = u.ParseString(instanceName)
 	return p, nil
 }
@@ -433,3 +439,40 @@ func (c *cmdCreate) checkNetwork(d incus.InstanceServer, name string) {
 	fmt.Fprint(os.Stderr, "  "+i18n.G("To create a new network, use: incus network create")+"\n")
 	fmt.Fprint(os.Stderr, "  "+i18n.G("To attach a network to an instance, use: incus network attach")+"\n\n")
 }
+
+// checkProvisioning warns when the instance is only given the configuration of a provisioning method other than
+// the one the image declares it expects in its "provisioning" property.
+func (c *cmdCreate) checkProvisioning(d incus.InstanceServer, name string, provisioning string) {
+	if provisioning == "" {
+		return
+	}
+
+	inst, _, err := d.GetInstance(name)
+	if err != nil {
+		return
+	}
+
+	hasCloudInit := false
+	hasIgnition := false
+	for k := range inst.ExpandedConfig {
+		if strings.HasPrefix(k, "cloud-init.") || k == "user.user-data" || k == "user.vendor-data" || k == "user.network-config" {
+			hasCloudInit = true
+		} else if strings.HasPrefix(k, "ignition.") {
+			hasIgnition = true
+		}
+	}
+
+	switch provisioning {
+	case "ignition":
+		if hasCloudInit && !hasIgnition {
+			fmt.Fprint(os.Stderr, "\n"+i18n.G("The image expects an Ignition config but the instance only has cloud-init configuration.")+"\n")
+			fmt.Fprint(os.Stderr, "  "+i18n.G("To provide an Ignition config, set: ignition.config")+"\n\n")
+		}
+
+	case "cloud-init":
+		if hasIgnition && !hasCloudInit {
+			fmt.Fprint(os.Stderr, "\n"+i18n.G("The image expects cloud-init configuration but the instance only has an Ignition config.")+"\n")
+			fmt.Fprint(os.Stderr, "  "+i18n.G("To provide cloud-init configuration, set: cloud-init.user-data")+"\n\n")
+		}
+	}
+}
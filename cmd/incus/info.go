@@ -720,6 +720,21 @@ func (c *cmdInfo) instanceInfo(d incus.InstanceServer, name string, showLog stri
 			fmt.Print(osInfo)
 		}
 
+		// Health check
+		if inst.State.Health != nil {
+			fmt.Println("\n" + i18n.G("Health:"))
+			fmt.Printf("  "+i18n.G("Status: %s")+"\n", inst.State.Health.Status)
+			fmt.Printf("  "+i18n.G("Failures: %d")+"\n", inst.State.Health.Failures)
+
+			if !inst.State.Health.LastCheck.IsZero() {
+				fmt.Printf("  "+i18n.G("Last check: %s")+"\n", inst.State.Health.LastCheck.Local().Format(dateLayout))
+			}
+
+			if inst.State.Health.LastError != "" {
+				fmt.Printf("  "+i18n.G("Last error: %s")+"\n", inst.State.Health.LastError)
+			}
+		}
+
 		fmt.Println("\n" + i18n.G("Resources:"))
 		// Processes
 		fmt.Printf("  "+i18n.G("Processes: %d")+"\n", inst.State.Processes)
@@ -768,6 +783,10 @@ func (c *cmdInfo) instanceInfo(d incus.InstanceServer, name string, showLog stri
 			memoryInfo += fmt.Sprintf("    %s: %s\n", i18n.G("Swap (peak)"), units.GetByteSizeStringIEC(inst.State.Memory.SwapUsagePeak, 2))
 		}
 
+		if inst.State.Memory.Balloon != 0 {
+			memoryInfo += fmt.Sprintf("    %s: %s\n", i18n.G("Balloon"), units.GetByteSizeStringIEC(inst.State.Memory.Balloon, 2))
+		}
+
 		if memoryInfo != "" {
 			fmt.Printf("  %s\n", i18n.G("Memory usage:"))
 			fmt.Print(memoryInfo)
@@ -49,6 +49,9 @@ func (c *cmdLowLevel) command() *cobra.Command {
 	lowLevelBitmapsCmd := cmdLowLevelBitmaps{global: c.global}
 	cmd.AddCommand(lowLevelBitmapsCmd.command())
 
+	lowLevelCaptureCmd := cmdLowLevelCapture{global: c.global}
+	cmd.AddCommand(lowLevelCaptureCmd.command())
+
 	lowLevelAttachCmd := cmdLowLevelMemory{global: c.global, lowLevel: c}
 	cmd.AddCommand(lowLevelAttachCmd.command())
 
@@ -148,6 +151,72 @@ func (c *cmdLowLevelBitmapsCreate) run(cmd *cobra.Command, args []string) error
 	return nil
 }
 
+type cmdLowLevelCapture struct {
+	global *cmdGlobal
+
+	flagFilter   string
+	flagDuration string
+	flagPackets  int64
+}
+
+var cmdLowLevelCaptureUsage = u.Usage{u.Instance.Remote(), u.Device, u.Target(u.File)}
+
+func (c *cmdLowLevelCapture) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = cli.U("capture", cmdLowLevelCaptureUsage...)
+	cmd.Short = i18n.G("Capture the packets of instance NICs")
+	cmd.Long = cli.FormatSection(color.DescriptionPrefix, i18n.G(
+		`Capture the packets going through the host side interface of an instance NIC
+
+The packets are written in the pcapng format to the file, or to the standard output for "-".
+The capture runs until interrupted, unless limited in duration or number of packets.`,
+	))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus debug capture c1 eth0 c1.pcapng --filter "udp port 53" --duration 1m
+    Captures the DNS traffic of the eth0 NIC of c1 for a minute.`,
+	))
+
+	cli.AddStringFlag(cmd.Flags(), &c.flagFilter, "filter|f", "", "", i18n.G("Filter expression selecting the captured packets"))
+	cli.AddStringFlag(cmd.Flags(), &c.flagDuration, "duration|d", "", "", i18n.G("Maximum duration of the capture (e.g. 30s, 5m)"))
+	cli.AddInt64Flag(cmd.Flags(), &c.flagPackets, "packets|c", i18n.G("Maximum number of captured packets"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstances(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpInstanceDeviceNames(args[0])
+		}
+
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+
+	return cmd
+}
+
+func (c *cmdLowLevelCapture) run(cmd *cobra.Command, args []string) error {
+	parsed, err := c.global.Parse(cmdLowLevelCaptureUsage, cmd, args)
+	if err != nil {
+		return err
+	}
+
+	d := parsed[0].RemoteServer
+	instanceName := parsed[0].RemoteObject.String
+	deviceName := parsed[1].String
+	targetPath := parsed[2].String
+
+	req, err := networkCaptureRequest(c.flagFilter, c.flagDuration, c.flagPackets)
+	if err != nil {
+		return err
+	}
+
+	return packetCapture(targetPath, func(args *incus.NetworkCaptureArgs) (incus.Operation, error) {
+		return d.CaptureInstanceDevice(instanceName, deviceName, *req, args)
+	})
+}
+
 type cmdLowLevelRepair struct {
 	global *cmdGlobal
 }
@@ -10,10 +10,12 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"go.yaml.in/yaml/v4"
 
+	incus "github.com/lxc/incus/v7/client"
 	"github.com/lxc/incus/v7/cmd/incus/color"
 	u "github.com/lxc/incus/v7/cmd/incus/usage"
 	"github.com/lxc/incus/v7/internal/i18n"
@@ -51,6 +53,10 @@ func (c *cmdNetwork) command() *cobra.Command {
 	networkAttachProfileCmd := cmdNetworkAttachProfile{global: c.global, network: c}
 	cmd.AddCommand(networkAttachProfileCmd.command())
 
+	// Capture
+	networkCaptureCmd := cmdNetworkCapture{global: c.global, network: c}
+	cmd.AddCommand(networkCaptureCmd.command())
+
 	// Create
 	networkCreateCmd := cmdNetworkCreate{global: c.global, network: c}
 	cmd.AddCommand(networkCreateCmd.command())
@@ -115,6 +121,10 @@ func (c *cmdNetwork) command() *cobra.Command {
 	networkAddressSetCmd := cmdNetworkAddressSet{global: c.global}
 	cmd.AddCommand(networkAddressSetCmd.command())
 
+	// Egress gateway
+	networkEgressGatewayCmd := cmdNetworkEgressGateway{global: c.global}
+	cmd.AddCommand(networkEgressGatewayCmd.command())
+
 	// Forward
 	networkForwardCmd := cmdNetworkForward{global: c.global}
 	cmd.AddCommand(networkForwardCmd.command())
@@ -123,6 +133,10 @@ func (c *cmdNetwork) command() *cobra.Command {
 	networkIntegrationCmd := cmdNetworkIntegration{global: c.global}
 	cmd.AddCommand(networkIntegrationCmd.command())
 
+	// Lease
+	networkLeaseCmd := cmdNetworkLease{global: c.global}
+	cmd.AddCommand(networkLeaseCmd.command())
+
 	// Load Balancer
 	networkLoadBalancerCmd := cmdNetworkLoadBalancer{global: c.global}
 	cmd.AddCommand(networkLoadBalancerCmd.command())
@@ -323,6 +337,101 @@ func (c *cmdNetworkAttachProfile) run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// Capture.
+type cmdNetworkCapture struct {
+	global  *cmdGlobal
+	network *cmdNetwork
+
+	flagFilter   string
+	flagDuration string
+	flagPackets  int64
+}
+
+var cmdNetworkCaptureUsage = u.Usage{u.Network.Remote(), u.Target(u.File)}
+
+func (c *cmdNetworkCapture) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = cli.U("capture", cmdNetworkCaptureUsage...)
+	cmd.Short = i18n.G("Capture the packets of networks")
+	cmd.Long = cli.FormatSection(color.DescriptionPrefix, i18n.G(
+		`Capture the packets of networks
+
+The packets are written in the pcapng format to the file, or to the standard output for "-".
+The capture runs until interrupted, unless limited in duration or number of packets.`,
+	))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus network capture incusbr0 incusbr0.pcapng --filter "host 10.0.0.2 and tcp port 443"
+    Captures the HTTPS traffic of 10.0.0.2 on the incusbr0 network.
+
+incus network capture incusbr0 - --packets 100 | tcpdump -r -
+    Shows the next 100 packets of the incusbr0 network.`,
+	))
+
+	cli.AddStringFlag(cmd.Flags(), &c.flagFilter, "filter|f", "", "", i18n.G("Capture filter selecting the captured packets (restricted subset of the pcap-filter syntax)"))
+	cli.AddStringFlag(cmd.Flags(), &c.flagDuration, "duration|d", "", "", i18n.G("Maximum duration of the capture (e.g. 30s, 5m)"))
+	cli.AddInt64Flag(cmd.Flags(), &c.flagPackets, "packets|c", i18n.G("Maximum number of captured packets"))
+	cli.AddStringFlag(cmd.Flags(), &c.network.flagTarget, "target", "", "", i18n.G("Cluster member name"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkCapture) run(cmd *cobra.Command, args []string) error {
+	parsed, err := c.global.Parse(cmdNetworkCaptureUsage, cmd, args)
+	if err != nil {
+		return err
+	}
+
+	d := parsed[0].RemoteServer
+	networkName := parsed[0].RemoteObject.String
+	targetPath := parsed[1].String
+
+	req, err := networkCaptureRequest(c.flagFilter, c.flagDuration, c.flagPackets)
+	if err != nil {
+		return err
+	}
+
+	// Targeting.
+	if c.network.flagTarget != "" {
+		if !d.IsClustered() {
+			return errors.New(i18n.G("To use --target, the destination remote must be a cluster"))
+		}
+
+		d = d.UseTarget(c.network.flagTarget)
+	}
+
+	return packetCapture(targetPath, func(args *incus.NetworkCaptureArgs) (incus.Operation, error) {
+		return d.CaptureNetwork(networkName, *req, args)
+	})
+}
+
+// networkCaptureRequest returns the packet capture request matching the command line flags.
+func networkCaptureRequest(filter string, duration string, packets int64) (*api.NetworkCapturePost, error) {
+	req := api.NetworkCapturePost{
+		Filter:  filter,
+		Packets: packets,
+	}
+
+	if duration != "" {
+		value, err := time.ParseDuration(duration)
+		if err != nil || value < time.Second {
+			return nil, fmt.Errorf(i18n.G("Invalid capture duration %q"), duration)
+		}
+
+		req.Duration = int64(value / time.Second)
+	}
+
+	return &req, nil
+}
+
 // Create.
 type cmdNetworkCreate struct {
 	global  *cmdGlobal
@@ -0,0 +1,925 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v4"
+
+	"github.com/lxc/incus/v7/cmd/incus/color"
+	u "github.com/lxc/incus/v7/cmd/incus/usage"
+	"github.com/lxc/incus/v7/internal/i18n"
+	"github.com/lxc/incus/v7/shared/api"
+	cli "github.com/lxc/incus/v7/shared/cmd"
+	"github.com/lxc/incus/v7/shared/termios"
+)
+
+type cmdNetworkEgressGateway struct {
+	global *cmdGlobal
+}
+
+func (c *cmdNetworkEgressGateway) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = cli.U("egress-gateway")
+	cmd.Short = i18n.G("Manage network egress gateways")
+	cmd.Long = cli.FormatSection(color.DescriptionPrefix, i18n.G("Manage network egress gateways"))
+
+	// List.
+	networkEgressGatewayListCmd := cmdNetworkEgressGatewayList{global: c.global, networkEgressGateway: c}
+	cmd.AddCommand(networkEgressGatewayListCmd.command())
+
+	// Show.
+	networkEgressGatewayShowCmd := cmdNetworkEgressGatewayShow{global: c.global, networkEgressGateway: c}
+	cmd.AddCommand(networkEgressGatewayShowCmd.command())
+
+	// Create.
+	networkEgressGatewayCreateCmd := cmdNetworkEgressGatewayCreate{global: c.global, networkEgressGateway: c}
+	cmd.AddCommand(networkEgressGatewayCreateCmd.command())
+
+	// Get.
+	networkEgressGatewayGetCmd := cmdNetworkEgressGatewayGet{global: c.global, networkEgressGateway: c}
+	cmd.AddCommand(networkEgressGatewayGetCmd.command())
+
+	// Set.
+	networkEgressGatewaySetCmd := cmdNetworkEgressGatewaySet{global: c.global, networkEgressGateway: c}
+	cmd.AddCommand(networkEgressGatewaySetCmd.command())
+
+	// Unset.
+	networkEgressGatewayUnsetCmd := cmdNetworkEgressGatewayUnset{global: c.global, networkEgressGateway: c, networkEgressGatewaySet: &networkEgressGatewaySetCmd}
+	cmd.AddCommand(networkEgressGatewayUnsetCmd.command())
+
+	// Edit.
+	networkEgressGatewayEditCmd := cmdNetworkEgressGatewayEdit{global: c.global, networkEgressGateway: c}
+	cmd.AddCommand(networkEgressGatewayEditCmd.command())
+
+	// Delete.
+	networkEgressGatewayDeleteCmd := cmdNetworkEgressGatewayDelete{global: c.global, networkEgressGateway: c}
+	cmd.AddCommand(networkEgressGatewayDeleteCmd.command())
+
+	// Attach.
+	networkEgressGatewayAttachCmd := cmdNetworkEgressGatewayAttach{global: c.global, networkEgressGateway: c}
+	cmd.AddCommand(networkEgressGatewayAttachCmd.command())
+
+	// Detach.
+	networkEgressGatewayDetachCmd := cmdNetworkEgressGatewayDetach{global: c.global, networkEgressGateway: c}
+	cmd.AddCommand(networkEgressGatewayDetachCmd.command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, _ []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// List.
+type cmdNetworkEgressGatewayList struct {
+	global               *cmdGlobal
+	networkEgressGateway *cmdNetworkEgressGateway
+
+	flagFormat  string
+	flagColumns string
+}
+
+type networkEgressGatewayColumn struct {
+	Name string
+	Data func(api.NetworkEgressGateway) string
+}
+
+var cmdNetworkEgressGatewayListUsage = u.Usage{u.Network.Remote()}
+
+func (c *cmdNetworkEgressGatewayList) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = cli.U("list", cmdNetworkEgressGatewayListUsage...)
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List available network egress gateways")
+	cmd.Long = cli.FormatSection(color.DescriptionPrefix, i18n.G(
+		`List available network egress gateways
+
+Default column layout: adipP
+
+== Columns ==
+The -c option takes a comma separated list of arguments that control
+which network egress gateway attributes to output when displaying
+in table or csv format.
+
+Column arguments are either pre-defined shorthand chars (see below),
+or (extended) config keys.
+
+Commas between consecutive shorthand chars are optional.
+
+Pre-defined column shorthand chars:
+  a - Address
+  d - Description
+  i - Instances
+  p - Profiles
+  P - Projects`,
+	))
+
+	cmd.RunE = c.run
+	cli.AddStringFlag(cmd.Flags(), &c.flagFormat, "format|f", c.global.defaultListFormat(), "", i18n.G(`Format (csv|json|table|yaml|compact|markdown), use suffix ",noheader" to disable headers and ",header" to enable it if missing, e.g. csv,header`))
+	cli.AddStringFlag(cmd.Flags(), &c.flagColumns, "columns|c", defaultNetworkEgressGatewayColumns, "", i18n.G("Columns"))
+
+	cmd.PreRunE = func(cmd *cobra.Command, _ []string) error {
+		return cli.ValidateFlagFormatForListOutput(cmd.Flag("format").Value.String())
+	}
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+const defaultNetworkEgressGatewayColumns = "adipP"
+
+func (c *cmdNetworkEgressGatewayList) parseColumns() ([]networkEgressGatewayColumn, error) {
+	columnsShorthandMap := map[rune]networkEgressGatewayColumn{
+		'a': {i18n.G("ADDRESS"), c.addressColumnData},
+		'd': {i18n.G("DESCRIPTION"), c.descriptionColumnData},
+		'i': {i18n.G("INSTANCES"), c.instancesColumnData},
+		'p': {i18n.G("PROFILES"), c.profilesColumnData},
+		'P': {i18n.G("PROJECTS"), c.projectsColumnData},
+	}
+
+	columnList := strings.Split(c.flagColumns, ",")
+	columns := []networkEgressGatewayColumn{}
+
+	for _, columnEntry := range columnList {
+		if columnEntry == "" {
+			return nil, fmt.Errorf(i18n.G("Empty column entry (redundant, leading or trailing command) in '%s'"), c.flagColumns)
+		}
+
+		for _, columnRune := range columnEntry {
+			column, ok := columnsShorthandMap[columnRune]
+			if !ok {
+				return nil, fmt.Errorf(i18n.G("Unknown column shorthand char '%c' in '%s'"), columnRune, columnEntry)
+			}
+
+			columns = append(columns, column)
+		}
+	}
+
+	return columns, nil
+}
+
+func (c *cmdNetworkEgressGatewayList) addressColumnData(gateway api.NetworkEgressGateway) string {
+	return gateway.Address
+}
+
+func (c *cmdNetworkEgressGatewayList) descriptionColumnData(gateway api.NetworkEgressGateway) string {
+	return gateway.Description
+}
+
+func (c *cmdNetworkEgressGatewayList) instancesColumnData(gateway api.NetworkEgressGateway) string {
+	return strings.Join(gateway.Instances, "\n")
+}
+
+func (c *cmdNetworkEgressGatewayList) profilesColumnData(gateway api.NetworkEgressGateway) string {
+	return strings.Join(gateway.Profiles, "\n")
+}
+
+func (c *cmdNetworkEgressGatewayList) projectsColumnData(gateway api.NetworkEgressGateway) string {
+	return strings.Join(gateway.Projects, "\n")
+}
+
+func (c *cmdNetworkEgressGatewayList) run(cmd *cobra.Command, args []string) error {
+	parsed, err := c.global.Parse(cmdNetworkEgressGatewayListUsage, cmd, args)
+	if err != nil {
+		return err
+	}
+
+	d := parsed[0].RemoteServer
+	networkName := parsed[0].RemoteObject.String
+
+	gateways, err := d.GetNetworkEgressGateways(networkName)
+	if err != nil {
+		return err
+	}
+
+	// Parse column flags.
+	columns, err := c.parseColumns()
+	if err != nil {
+		return err
+	}
+
+	// Render the table
+	data := [][]string{}
+	for _, gateway := range gateways {
+		line := []string{}
+		for _, column := range columns {
+			line = append(line, column.Data(gateway))
+		}
+
+		data = append(data, line)
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(data))
+
+	header := []string{}
+	for _, column := range columns {
+		header = append(header, column.Name)
+	}
+
+	return cli.RenderTable(os.Stdout, c.flagFormat, header, data, gateways)
+}
+
+// Show.
+type cmdNetworkEgressGatewayShow struct {
+	global               *cmdGlobal
+	networkEgressGateway *cmdNetworkEgressGateway
+}
+
+var cmdNetworkEgressGatewayShowUsage = u.Usage{u.Network.Remote(), u.Address}
+
+func (c *cmdNetworkEgressGatewayShow) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = cli.U("show", cmdNetworkEgressGatewayShowUsage...)
+	cmd.Short = i18n.G("Show network egress gateway configurations")
+	cmd.Long = cli.FormatSection(color.DescriptionPrefix, i18n.G("Show network egress gateway configurations"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpNetworkEgressGateways(args[0])
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkEgressGatewayShow) run(cmd *cobra.Command, args []string) error {
+	parsed, err := c.global.Parse(cmdNetworkEgressGatewayShowUsage, cmd, args)
+	if err != nil {
+		return err
+	}
+
+	d := parsed[0].RemoteServer
+	networkName := parsed[0].RemoteObject.String
+	address := parsed[1].String
+
+	// Show the network egress gateway config.
+	gateway, _, err := d.GetNetworkEgressGateway(networkName, address)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Dump(&gateway, yaml.WithV2Defaults())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+// Create.
+type cmdNetworkEgressGatewayCreate struct {
+	global               *cmdGlobal
+	networkEgressGateway *cmdNetworkEgressGateway
+
+	flagDescription string
+	flagInstances   []string
+	flagProfiles    []string
+}
+
+var cmdNetworkEgressGatewayCreateUsage = u.Usage{u.Network.Remote(), u.Address, u.KV.List(0)}
+
+func (c *cmdNetworkEgressGatewayCreate) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = cli.U("create", cmdNetworkEgressGatewayCreateUsage...)
+	cmd.Aliases = []string{"add"}
+	cmd.Short = i18n.G("Create new network egress gateways")
+	cmd.Long = cli.FormatSection(color.DescriptionPrefix, i18n.G("Create new network egress gateways"))
+	cmd.Example = cli.FormatSection("", i18n.G(`incus network egress-gateway create n1 192.0.2.10 --instance c1 --profile customer-a
+    Create an egress gateway on network n1 for instance c1 and the instances using profile customer-a
+
+incus network egress-gateway create n1 192.0.2.10 < config.yaml
+    Create an egress gateway on network n1 with configuration from config.yaml`))
+
+	cmd.RunE = c.run
+
+	cli.AddStringFlag(cmd.Flags(), &c.flagDescription, "description", "", "", i18n.G("Egress gateway description"))
+	cli.AddStringArrayFlag(cmd.Flags(), &c.flagInstances, "instance", i18n.G("Instance using the egress gateway (may be passed multiple times)"))
+	cli.AddStringArrayFlag(cmd.Flags(), &c.flagProfiles, "profile", i18n.G("Profile whose instances use the egress gateway (may be passed multiple times)"))
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkEgressGatewayCreate) run(cmd *cobra.Command, args []string) error {
+	parsed, err := c.global.Parse(cmdNetworkEgressGatewayCreateUsage, cmd, args)
+	if err != nil {
+		return err
+	}
+
+	d := parsed[0].RemoteServer
+	networkName := parsed[0].RemoteObject.String
+	address := parsed[1].String
+	keys, err := kvToMap(parsed[2])
+	if err != nil {
+		return err
+	}
+
+	// If stdin isn't a terminal, read yaml from it.
+	var gatewayPut api.NetworkEgressGatewayPut
+	if !termios.IsTerminal(getStdinFd()) {
+		loader, err := yaml.NewLoader(os.Stdin, yaml.WithKnownFields())
+		if err != nil {
+			return err
+		}
+
+		err = loader.Load(&gatewayPut)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+	}
+
+	if gatewayPut.Config == nil {
+		gatewayPut.Config = map[string]string{}
+	}
+
+	maps.Copy(gatewayPut.Config, keys)
+
+	// Create the network egress gateway.
+	gateway := api.NetworkEgressGatewaysPost{
+		Address:                 address,
+		NetworkEgressGatewayPut: gatewayPut,
+	}
+
+	if c.flagDescription != "" {
+		gateway.Description = c.flagDescription
+	}
+
+	gateway.Instances = append(gateway.Instances, c.flagInstances...)
+	gateway.Profiles = append(gateway.Profiles, c.flagProfiles...)
+
+	gateway.Normalise()
+
+	err = d.CreateNetworkEgressGateway(networkName, gateway)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network egress gateway %s created")+"\n", gateway.Address)
+	}
+
+	return nil
+}
+
+// Get.
+type cmdNetworkEgressGatewayGet struct {
+	global               *cmdGlobal
+	networkEgressGateway *cmdNetworkEgressGateway
+
+	flagIsProperty bool
+}
+
+var cmdNetworkEgressGatewayGetUsage = u.Usage{u.Network.Remote(), u.Address, u.Key}
+
+func (c *cmdNetworkEgressGatewayGet) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = cli.U("get", cmdNetworkEgressGatewayGetUsage...)
+	cmd.Short = i18n.G("Get values for network egress gateway configuration keys")
+	cmd.Long = cli.FormatSection(color.DescriptionPrefix, i18n.G("Get values for network egress gateway configuration keys"))
+	cmd.RunE = c.run
+
+	cli.AddBoolFlag(cmd.Flags(), &c.flagIsProperty, "property|p", i18n.G("Get the key as a network egress gateway property"))
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpNetworkEgressGateways(args[0])
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkEgressGatewayGet) run(cmd *cobra.Command, args []string) error {
+	parsed, err := c.global.Parse(cmdNetworkEgressGatewayGetUsage, cmd, args)
+	if err != nil {
+		return err
+	}
+
+	d := parsed[0].RemoteServer
+	networkName := parsed[0].RemoteObject.String
+	address := parsed[1].String
+	key := parsed[2].String
+
+	// Get the current config.
+	gateway, _, err := d.GetNetworkEgressGateway(networkName, address)
+	if err != nil {
+		return err
+	}
+
+	if c.flagIsProperty {
+		w := gateway.Writable()
+		res, err := getFieldByJSONTag(&w, key)
+		if err != nil {
+			return fmt.Errorf(i18n.G("The property %q does not exist on the egress gateway %q: %v"), key, address, err)
+		}
+
+		fmt.Printf("%v\n", res)
+	} else {
+		for k, v := range gateway.Config {
+			if k == key {
+				fmt.Printf("%s\n", v)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Set.
+type cmdNetworkEgressGatewaySet struct {
+	global               *cmdGlobal
+	networkEgressGateway *cmdNetworkEgressGateway
+
+	flagIsProperty bool
+}
+
+var cmdNetworkEgressGatewaySetUsage = u.Usage{u.Network.Remote(), u.Address, u.KV.List(1)}
+
+func (c *cmdNetworkEgressGatewaySet) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = cli.U("set", cmdNetworkEgressGatewaySetUsage...)
+	cmd.Short = i18n.G("Set network egress gateway keys")
+	cmd.Long = cli.FormatSection(color.DescriptionPrefix, i18n.G("Set network egress gateway keys"))
+	cmd.RunE = c.run
+
+	cli.AddBoolFlag(cmd.Flags(), &c.flagIsProperty, "property|p", i18n.G("Set the key as a network egress gateway property"))
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpNetworkEgressGateways(args[0])
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// set runs the post-parsing command logic.
+func (c *cmdNetworkEgressGatewaySet) set(cmd *cobra.Command, parsed []*u.Parsed) error {
+	d := parsed[0].RemoteServer
+	networkName := parsed[0].RemoteObject.String
+	address := parsed[1].String
+	keys, err := kvToMap(parsed[2])
+	if err != nil {
+		return err
+	}
+
+	// Get the current config.
+	gateway, etag, err := d.GetNetworkEgressGateway(networkName, address)
+	if err != nil {
+		return err
+	}
+
+	if gateway.Config == nil {
+		gateway.Config = map[string]string{}
+	}
+
+	writable := gateway.Writable()
+	if c.flagIsProperty {
+		if cmd.Name() == "unset" {
+			for k := range keys {
+				err := unsetFieldByJSONTag(&writable, k)
+				if err != nil {
+					return fmt.Errorf(i18n.G("Error unsetting property: %v"), err)
+				}
+			}
+		} else {
+			err := unpackKVToWritable(&writable, keys)
+			if err != nil {
+				return fmt.Errorf(i18n.G("Error setting properties: %v"), err)
+			}
+		}
+	} else {
+		maps.Copy(writable.Config, keys)
+	}
+
+	writable.Normalise()
+
+	return d.UpdateNetworkEgressGateway(networkName, gateway.Address, writable, etag)
+}
+
+func (c *cmdNetworkEgressGatewaySet) run(cmd *cobra.Command, args []string) error {
+	parsed, err := c.global.Parse(cmdNetworkEgressGatewaySetUsage, cmd, args)
+	if err != nil {
+		return err
+	}
+
+	return c.set(cmd, parsed)
+}
+
+// Unset.
+type cmdNetworkEgressGatewayUnset struct {
+	global                  *cmdGlobal
+	networkEgressGateway    *cmdNetworkEgressGateway
+	networkEgressGatewaySet *cmdNetworkEgressGatewaySet
+
+	flagIsProperty bool
+}
+
+var cmdNetworkEgressGatewayUnsetUsage = u.Usage{u.Network.Remote(), u.Address, u.Key.List(1)}
+
+func (c *cmdNetworkEgressGatewayUnset) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = cli.U("unset", cmdNetworkEgressGatewayUnsetUsage...)
+	cmd.Short = i18n.G("Unset network egress gateway configuration keys")
+	cmd.Long = cli.FormatSection(color.DescriptionPrefix, i18n.G("Unset network egress gateway keys"))
+	cmd.RunE = c.run
+
+	cli.AddBoolFlag(cmd.Flags(), &c.flagIsProperty, "property|p", i18n.G("Unset the keys as network egress gateway properties"))
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpNetworkEgressGateways(args[0])
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkEgressGatewayUnset) run(cmd *cobra.Command, args []string) error {
+	parsed, err := c.global.Parse(cmdNetworkEgressGatewayUnsetUsage, cmd, args)
+	if err != nil {
+		return err
+	}
+
+	c.networkEgressGatewaySet.flagIsProperty = c.flagIsProperty
+	return unsetKey(c.networkEgressGatewaySet, cmd, parsed)
+}
+
+// Edit.
+type cmdNetworkEgressGatewayEdit struct {
+	global               *cmdGlobal
+	networkEgressGateway *cmdNetworkEgressGateway
+}
+
+var cmdNetworkEgressGatewayEditUsage = u.Usage{u.Network.Remote(), u.Address}
+
+func (c *cmdNetworkEgressGatewayEdit) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = cli.U("edit", cmdNetworkEgressGatewayEditUsage...)
+	cmd.Short = i18n.G("Edit network egress gateway configurations as YAML")
+	cmd.Long = cli.FormatSection(color.DescriptionPrefix, i18n.G("Edit network egress gateway configurations as YAML"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpNetworkEgressGateways(args[0])
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkEgressGatewayEdit) helpTemplate() string {
+	return i18n.G(
+		`### This is a YAML representation of the network egress gateway.
+### Any line starting with a '# will be ignored.
+###
+### A network egress gateway is the source address of the outbound traffic
+### of the selected instances. Instances and profiles from other projects
+### are prefixed with their project.
+###
+### An example would look like:
+### address: 192.0.2.10
+### config:
+###   user.foo: bar
+### description: Outbound address of customer A
+### instances:
+### - c1
+### - customer-a/c2
+### profiles:
+### - customer-a
+### projects:
+### - customer-a
+###
+### Note that the address cannot be changed.`,
+	)
+}
+
+func (c *cmdNetworkEgressGatewayEdit) run(cmd *cobra.Command, args []string) error {
+	parsed, err := c.global.Parse(cmdNetworkEgressGatewayEditUsage, cmd, args)
+	if err != nil {
+		return err
+	}
+
+	d := parsed[0].RemoteServer
+	networkName := parsed[0].RemoteObject.String
+	address := parsed[1].String
+
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(getStdinFd()) {
+		loader, err := yaml.NewLoader(os.Stdin, yaml.WithKnownFields())
+		if err != nil {
+			return err
+		}
+
+		// Allow output of `incus network egress-gateway show` command to be passed in here, but only take
+		// the contents of the NetworkEgressGatewayPut fields when updating.
+		// The other fields are silently discarded.
+		newData := api.NetworkEgressGateway{}
+		err = loader.Load(&newData)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+
+		newData.Normalise()
+
+		return d.UpdateNetworkEgressGateway(networkName, address, newData.NetworkEgressGatewayPut, "")
+	}
+
+	// Get the current config.
+	gateway, etag, err := d.GetNetworkEgressGateway(networkName, address)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Dump(&gateway, yaml.WithV2Defaults())
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor.
+	content, err := cli.TextEditor("", []byte(c.helpTemplate()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor.
+		newData := api.NetworkEgressGateway{} // We show the full info, but only send the writable fields.
+		err = yaml.Load(content, &newData, yaml.WithKnownFields())
+		if err == nil {
+			newData.Normalise()
+			err = d.UpdateNetworkEgressGateway(networkName, address, newData.Writable(), etag)
+		}
+
+		// Respawn the editor.
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Config parsing error: %s")+"\n", err)
+			fmt.Println(i18n.G("Press enter to open the editor again or ctrl+c to abort change"))
+
+			_, err := os.Stdin.Read(make([]byte, 1))
+			if err != nil {
+				return err
+			}
+
+			content, err = cli.TextEditor("", content)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		break
+	}
+
+	return nil
+}
+
+// Delete.
+type cmdNetworkEgressGatewayDelete struct {
+	global               *cmdGlobal
+	networkEgressGateway *cmdNetworkEgressGateway
+}
+
+var cmdNetworkEgressGatewayDeleteUsage = u.Usage{u.Network.Remote(), u.Address}
+
+func (c *cmdNetworkEgressGatewayDelete) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = cli.U("delete", cmdNetworkEgressGatewayDeleteUsage...)
+	cmd.Aliases = []string{"rm", "remove"}
+	cmd.Short = i18n.G("Delete network egress gateways")
+	cmd.Long = cli.FormatSection(color.DescriptionPrefix, i18n.G("Delete network egress gateways"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpNetworkEgressGateways(args[0])
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkEgressGatewayDelete) run(cmd *cobra.Command, args []string) error {
+	parsed, err := c.global.Parse(cmdNetworkEgressGatewayDeleteUsage, cmd, args)
+	if err != nil {
+		return err
+	}
+
+	d := parsed[0].RemoteServer
+	networkName := parsed[0].RemoteObject.String
+	address := parsed[1].String
+
+	// Delete the network egress gateway.
+	err = d.DeleteNetworkEgressGateway(networkName, address)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network egress gateway %s deleted")+"\n", address)
+	}
+
+	return nil
+}
+
+// networkEgressGatewaySelection returns the list of the egress gateway selecting the given entity type.
+func networkEgressGatewaySelection(gateway *api.NetworkEgressGatewayPut, entityType string) *[]string {
+	switch entityType {
+	case "instance":
+		return &gateway.Instances
+	case "profile":
+		return &gateway.Profiles
+	default:
+		return &gateway.Projects
+	}
+}
+
+var cmdNetworkEgressGatewaySelectionUsage = u.Usage{u.Network.Remote(), u.Address, u.EitherVerbatim("instance", "profile", "project"), u.Placeholder(i18n.G("name"))}
+
+// Attach.
+type cmdNetworkEgressGatewayAttach struct {
+	global               *cmdGlobal
+	networkEgressGateway *cmdNetworkEgressGateway
+}
+
+func (c *cmdNetworkEgressGatewayAttach) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = cli.U("attach", cmdNetworkEgressGatewaySelectionUsage...)
+	cmd.Short = i18n.G("Route the outbound traffic of instances through an egress gateway")
+	cmd.Long = cli.FormatSection(color.DescriptionPrefix, i18n.G(
+		`Route the outbound traffic of instances through an egress gateway
+
+Instances can be selected directly, through one of their profiles or through their project.
+Instances and profiles from other projects are prefixed with their project.`))
+	cmd.Example = cli.FormatSection("", i18n.G(`incus network egress-gateway attach n1 192.0.2.10 instance customer-a/c1
+    Route the outbound traffic of instance c1 of project customer-a through the egress gateway 192.0.2.10 of network n1`))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpNetworkEgressGateways(args[0])
+		}
+
+		if len(args) == 2 {
+			return []string{"instance", "profile", "project"}, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkEgressGatewayAttach) run(cmd *cobra.Command, args []string) error {
+	parsed, err := c.global.Parse(cmdNetworkEgressGatewaySelectionUsage, cmd, args)
+	if err != nil {
+		return err
+	}
+
+	d := parsed[0].RemoteServer
+	networkName := parsed[0].RemoteObject.String
+	address := parsed[1].String
+	entityType := parsed[2].String
+	name := parsed[3].String
+
+	gateway, etag, err := d.GetNetworkEgressGateway(networkName, address)
+	if err != nil {
+		return err
+	}
+
+	writable := gateway.Writable()
+	selection := networkEgressGatewaySelection(&writable, entityType)
+	if slices.Contains(*selection, name) {
+		return fmt.Errorf(i18n.G("The %s %q already uses the egress gateway"), entityType, name)
+	}
+
+	*selection = append(*selection, name)
+
+	return d.UpdateNetworkEgressGateway(networkName, gateway.Address, writable, etag)
+}
+
+// Detach.
+type cmdNetworkEgressGatewayDetach struct {
+	global               *cmdGlobal
+	networkEgressGateway *cmdNetworkEgressGateway
+}
+
+func (c *cmdNetworkEgressGatewayDetach) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = cli.U("detach", cmdNetworkEgressGatewaySelectionUsage...)
+	cmd.Short = i18n.G("Stop routing the outbound traffic of instances through an egress gateway")
+	cmd.Long = cli.FormatSection(color.DescriptionPrefix, i18n.G("Stop routing the outbound traffic of instances through an egress gateway"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpNetworkEgressGateways(args[0])
+		}
+
+		if len(args) == 2 {
+			return []string{"instance", "profile", "project"}, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkEgressGatewayDetach) run(cmd *cobra.Command, args []string) error {
+	parsed, err := c.global.Parse(cmdNetworkEgressGatewaySelectionUsage, cmd, args)
+	if err != nil {
+		return err
+	}
+
+	d := parsed[0].RemoteServer
+	networkName := parsed[0].RemoteObject.String
+	address := parsed[1].String
+	entityType := parsed[2].String
+	name := parsed[3].String
+
+	gateway, etag, err := d.GetNetworkEgressGateway(networkName, address)
+	if err != nil {
+		return err
+	}
+
+	writable := gateway.Writable()
+	selection := networkEgressGatewaySelection(&writable, entityType)
+	if !slices.Contains(*selection, name) {
+		return fmt.Errorf(i18n.G("The %s %q doesn't use the egress gateway"), entityType, name)
+	}
+
+	*selection = slices.DeleteFunc(*selection, func(entry string) bool { return entry == name })
+
+	return d.UpdateNetworkEgressGateway(networkName, gateway.Address, writable, etag)
+}
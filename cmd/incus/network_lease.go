@@ -0,0 +1,388 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v4"
+
+	"github.com/lxc/incus/v7/cmd/incus/color"
+	u "github.com/lxc/incus/v7/cmd/incus/usage"
+	"github.com/lxc/incus/v7/internal/i18n"
+	"github.com/lxc/incus/v7/shared/api"
+	cli "github.com/lxc/incus/v7/shared/cmd"
+	"github.com/lxc/incus/v7/shared/termios"
+)
+
+type cmdNetworkLease struct {
+	global *cmdGlobal
+}
+
+func (c *cmdNetworkLease) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = cli.U("lease")
+	cmd.Short = i18n.G("Manage network address reservations")
+	cmd.Long = cli.FormatSection(color.DescriptionPrefix, i18n.G("Manage network address reservations"))
+
+	// Show.
+	networkLeaseShowCmd := cmdNetworkLeaseShow{global: c.global, networkLease: c}
+	cmd.AddCommand(networkLeaseShowCmd.command())
+
+	// Create.
+	networkLeaseCreateCmd := cmdNetworkLeaseCreate{global: c.global, networkLease: c}
+	cmd.AddCommand(networkLeaseCreateCmd.command())
+
+	// Edit.
+	networkLeaseEditCmd := cmdNetworkLeaseEdit{global: c.global, networkLease: c}
+	cmd.AddCommand(networkLeaseEditCmd.command())
+
+	// Delete.
+	networkLeaseDeleteCmd := cmdNetworkLeaseDelete{global: c.global, networkLease: c}
+	cmd.AddCommand(networkLeaseDeleteCmd.command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, _ []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// Show.
+type cmdNetworkLeaseShow struct {
+	global       *cmdGlobal
+	networkLease *cmdNetworkLease
+}
+
+var cmdNetworkLeaseShowUsage = u.Usage{u.Network.Remote(), u.Address}
+
+func (c *cmdNetworkLeaseShow) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = cli.U("show", cmdNetworkLeaseShowUsage...)
+	cmd.Short = i18n.G("Show network address reservations")
+	cmd.Long = cli.FormatSection(color.DescriptionPrefix, i18n.G("Show network address reservations"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpNetworkLeases(args[0])
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkLeaseShow) run(cmd *cobra.Command, args []string) error {
+	parsed, err := c.global.Parse(cmdNetworkLeaseShowUsage, cmd, args)
+	if err != nil {
+		return err
+	}
+
+	d := parsed[0].RemoteServer
+	networkName := parsed[0].RemoteObject.String
+	address := parsed[1].String
+
+	// Show the network address reservation.
+	lease, _, err := d.GetNetworkLease(networkName, address)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Dump(&lease, yaml.WithV2Defaults())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+// Create.
+type cmdNetworkLeaseCreate struct {
+	global       *cmdGlobal
+	networkLease *cmdNetworkLease
+
+	flagDescription string
+	flagHostname    string
+	flagHwaddr      string
+}
+
+var cmdNetworkLeaseCreateUsage = u.Usage{u.Network.Remote(), u.Address}
+
+func (c *cmdNetworkLeaseCreate) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = cli.U("create", cmdNetworkLeaseCreateUsage...)
+	cmd.Aliases = []string{"add"}
+	cmd.Short = i18n.G("Create new network address reservations")
+	cmd.Long = cli.FormatSection(color.DescriptionPrefix, i18n.G("Create new network address reservations"))
+	cmd.Example = cli.FormatSection("", i18n.G(`incus network lease create n1 10.0.0.50 --hwaddr 10:66:6a:2c:89:d9 --hostname printer
+    Hand out 10.0.0.50 over DHCP to the device with MAC address 10:66:6a:2c:89:d9 on network n1
+
+incus network lease create n1 10.0.0.51 --description "Appliance with a static address"
+    Keep 10.0.0.51 out of the DHCP pool of network n1`))
+
+	cmd.RunE = c.run
+
+	cli.AddStringFlag(cmd.Flags(), &c.flagDescription, "description", "", "", i18n.G("Address reservation description"))
+	cli.AddStringFlag(cmd.Flags(), &c.flagHostname, "hostname", "", "", i18n.G("Hostname associated with the reserved address"))
+	cli.AddStringFlag(cmd.Flags(), &c.flagHwaddr, "hwaddr", "", "", i18n.G("MAC address the reserved address is handed out to"))
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkLeaseCreate) run(cmd *cobra.Command, args []string) error {
+	parsed, err := c.global.Parse(cmdNetworkLeaseCreateUsage, cmd, args)
+	if err != nil {
+		return err
+	}
+
+	d := parsed[0].RemoteServer
+	networkName := parsed[0].RemoteObject.String
+	address := parsed[1].String
+
+	// If stdin isn't a terminal, read yaml from it.
+	var leasePut api.NetworkLeasePut
+	if !termios.IsTerminal(getStdinFd()) {
+		loader, err := yaml.NewLoader(os.Stdin, yaml.WithKnownFields())
+		if err != nil {
+			return err
+		}
+
+		err = loader.Load(&leasePut)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+	}
+
+	// Create the network address reservation.
+	lease := api.NetworkLeasesPost{
+		Address:         address,
+		NetworkLeasePut: leasePut,
+	}
+
+	if c.flagDescription != "" {
+		lease.Description = c.flagDescription
+	}
+
+	if c.flagHostname != "" {
+		lease.Hostname = c.flagHostname
+	}
+
+	if c.flagHwaddr != "" {
+		lease.Hwaddr = c.flagHwaddr
+	}
+
+	lease.Normalise()
+
+	err = d.CreateNetworkLease(networkName, lease)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network address reservation %s created")+"\n", lease.Address)
+	}
+
+	return nil
+}
+
+// Edit.
+type cmdNetworkLeaseEdit struct {
+	global       *cmdGlobal
+	networkLease *cmdNetworkLease
+}
+
+var cmdNetworkLeaseEditUsage = u.Usage{u.Network.Remote(), u.Address}
+
+func (c *cmdNetworkLeaseEdit) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = cli.U("edit", cmdNetworkLeaseEditUsage...)
+	cmd.Short = i18n.G("Edit network address reservations as YAML")
+	cmd.Long = cli.FormatSection(color.DescriptionPrefix, i18n.G("Edit network address reservations as YAML"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpNetworkLeases(args[0])
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkLeaseEdit) helpTemplate() string {
+	return i18n.G(
+		`### This is a YAML representation of the network address reservation.
+### Any line starting with a '# will be ignored.
+###
+### A network address reservation keeps the address out of the DHCP pool
+### and, if a MAC address is set, hands it out to that MAC address.
+###
+### An example would look like:
+### address: 10.0.0.50
+### description: Printer on the second floor
+### hostname: printer
+### hwaddr: 10:66:6a:2c:89:d9
+###
+### Note that the address cannot be changed.`,
+	)
+}
+
+func (c *cmdNetworkLeaseEdit) run(cmd *cobra.Command, args []string) error {
+	parsed, err := c.global.Parse(cmdNetworkLeaseEditUsage, cmd, args)
+	if err != nil {
+		return err
+	}
+
+	d := parsed[0].RemoteServer
+	networkName := parsed[0].RemoteObject.String
+	address := parsed[1].String
+
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(getStdinFd()) {
+		loader, err := yaml.NewLoader(os.Stdin, yaml.WithKnownFields())
+		if err != nil {
+			return err
+		}
+
+		// Allow output of `incus network lease show` command to be passed in here, but only take
+		// the contents of the NetworkLeasePut fields when updating.
+		// The other fields are silently discarded.
+		newData := api.NetworkLease{}
+		err = loader.Load(&newData)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+
+		leasePut := newData.Writable()
+		leasePut.Normalise()
+
+		return d.UpdateNetworkLease(networkName, address, leasePut, "")
+	}
+
+	// Get the current config.
+	lease, etag, err := d.GetNetworkLease(networkName, address)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Dump(&lease, yaml.WithV2Defaults())
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor.
+	content, err := cli.TextEditor("", []byte(c.helpTemplate()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor.
+		newData := api.NetworkLease{} // We show the full info, but only send the writable fields.
+		err = yaml.Load(content, &newData, yaml.WithKnownFields())
+		if err == nil {
+			leasePut := newData.Writable()
+			leasePut.Normalise()
+			err = d.UpdateNetworkLease(networkName, address, leasePut, etag)
+		}
+
+		// Respawn the editor.
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Config parsing error: %s")+"\n", err)
+			fmt.Println(i18n.G("Press enter to open the editor again or ctrl+c to abort change"))
+
+			_, err := os.Stdin.Read(make([]byte, 1))
+			if err != nil {
+				return err
+			}
+
+			content, err = cli.TextEditor("", content)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		break
+	}
+
+	return nil
+}
+
+// Delete.
+type cmdNetworkLeaseDelete struct {
+	global       *cmdGlobal
+	networkLease *cmdNetworkLease
+}
+
+var cmdNetworkLeaseDeleteUsage = u.Usage{u.Network.Remote(), u.Address}
+
+func (c *cmdNetworkLeaseDelete) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = cli.U("delete", cmdNetworkLeaseDeleteUsage...)
+	cmd.Aliases = []string{"rm", "remove"}
+	cmd.Short = i18n.G("Delete network address reservations")
+	cmd.Long = cli.FormatSection(color.DescriptionPrefix, i18n.G("Delete network address reservations"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpNetworkLeases(args[0])
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkLeaseDelete) run(cmd *cobra.Command, args []string) error {
+	parsed, err := c.global.Parse(cmdNetworkLeaseDeleteUsage, cmd, args)
+	if err != nil {
+		return err
+	}
+
+	d := parsed[0].RemoteServer
+	networkName := parsed[0].RemoteObject.String
+	address := parsed[1].String
+
+	// Delete the network address reservation.
+	err = d.DeleteNetworkLease(networkName, address)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network address reservation %s deleted")+"\n", address)
+	}
+
+	return nil
+}
@@ -41,6 +41,10 @@ func (c *cmdStorage) command() *cobra.Command {
 	cmd.Short = i18n.G("Manage storage pools and volumes")
 	cmd.Long = cli.FormatSection(color.DescriptionPrefix, i18n.G(`Manage storage pools and volumes`))
 
+	// Check
+	storageCheckCmd := cmdStorageCheck{global: c.global, storage: c}
+	cmd.AddCommand(storageCheckCmd.command())
+
 	// Create
 	storageCreateCmd := cmdStorageCreate{global: c.global, storage: c}
 	cmd.AddCommand(storageCreateCmd.command())
@@ -91,6 +95,91 @@ func (c *cmdStorage) command() *cobra.Command {
 	return cmd
 }
 
+// Check.
+type cmdStorageCheck struct {
+	global  *cmdGlobal
+	storage *cmdStorage
+}
+
+var cmdStorageCheckUsage = u.Usage{u.Pool.Remote()}
+
+func (c *cmdStorageCheck) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = cli.U("check", cmdStorageCheckUsage...)
+	cmd.Short = i18n.G("Check the integrity of storage pools")
+	cmd.Long = cli.FormatSection(color.DescriptionPrefix, i18n.G(
+		`Check the integrity of storage pools
+
+The check depends on the storage driver (ZFS or Btrfs scrub, LVM health status, Ceph RBD object maps
+and qcow2 volume checks). Problems which are found are also reported as warnings.`,
+	))
+
+	cli.AddStringFlag(cmd.Flags(), &c.storage.flagTarget, "target", "", "", i18n.G("Cluster member name"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpStoragePools(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdStorageCheck) run(cmd *cobra.Command, args []string) error {
+	parsed, err := c.global.Parse(cmdStorageCheckUsage, cmd, args)
+	if err != nil {
+		return err
+	}
+
+	d := parsed[0].RemoteServer
+	poolName := parsed[0].RemoteObject.String
+
+	if c.storage.flagTarget != "" {
+		d = d.UseTarget(c.storage.flagTarget)
+	}
+
+	op, err := d.CheckStoragePool(poolName)
+	if err != nil {
+		return err
+	}
+
+	progress := cli.ProgressRenderer{
+		Quiet: c.global.flagQuiet,
+	}
+
+	_, err = op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	err = cli.CancelableWait(op, &progress)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	progress.Done("")
+
+	problems, _ := op.Get().Metadata["problems"].([]any)
+	if len(problems) == 0 {
+		if !c.global.flagQuiet {
+			fmt.Printf(i18n.G("No problems found in storage pool %s")+"\n", formatRemote(c.global.conf, parsed[0]))
+		}
+
+		return nil
+	}
+
+	for _, problem := range problems {
+		fmt.Printf("- %v\n", problem)
+	}
+
+	return fmt.Errorf(i18n.G("Found %d problems in storage pool %s"), len(problems), formatRemote(c.global.conf, parsed[0]))
+}
+
 // Create.
 type cmdStorageCreate struct {
 	global  *cmdGlobal
@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -56,6 +57,10 @@ func (c *cmdStorageBucket) command() *cobra.Command {
 	storageBucketGetCmd := cmdStorageBucketGet{global: c.global, storageBucket: c}
 	cmd.AddCommand(storageBucketGetCmd.command())
 
+	// Info.
+	storageBucketInfoCmd := cmdStorageBucketInfo{global: c.global, storageBucket: c}
+	cmd.AddCommand(storageBucketInfoCmd.command())
+
 	// List.
 	storageBucketListCmd := cmdStorageBucketList{global: c.global, storageBucket: c}
 	cmd.AddCommand(storageBucketListCmd.command())
@@ -417,6 +422,82 @@ func (c *cmdStorageBucketGet) run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// Info.
+type cmdStorageBucketInfo struct {
+	global        *cmdGlobal
+	storageBucket *cmdStorageBucket
+}
+
+var cmdStorageBucketInfoUsage = u.Usage{u.Pool.Remote(), u.Bucket}
+
+func (c *cmdStorageBucketInfo) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = cli.U("info", cmdStorageBucketInfoUsage...)
+	cmd.Short = i18n.G("Show storage bucket usage")
+	cmd.Long = cli.FormatSection(color.DescriptionPrefix, i18n.G(`Show storage bucket usage
+
+The usage of each of the bucket keys is shown along with their quota.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus storage bucket info default data
+    Will show the usage of a bucket called "data" in the "default" pool.`,
+	))
+
+	cli.AddStringFlag(cmd.Flags(), &c.storageBucket.flagTarget, "target", "", "", i18n.G("Cluster member name"))
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdStorageBucketInfo) run(cmd *cobra.Command, args []string) error {
+	parsed, err := c.global.Parse(cmdStorageBucketInfoUsage, cmd, args)
+	if err != nil {
+		return err
+	}
+
+	d := parsed[0].RemoteServer
+	poolName := parsed[0].RemoteObject.String
+	bucketName := parsed[1].String
+
+	// If a target member was specified, get the bucket with the matching name on that member, if any.
+	if c.storageBucket.flagTarget != "" {
+		d = d.UseTarget(c.storageBucket.flagTarget)
+	}
+
+	state, err := d.GetStoragePoolBucketState(poolName, bucketName)
+	if err != nil {
+		return err
+	}
+
+	printUsage := func(indent string, usage api.StorageBucketStateUsage) {
+		if usage.ObjectsQuota >= 0 {
+			fmt.Printf(indent+i18n.G("Objects: %d (quota: %d)")+"\n", usage.Objects, usage.ObjectsQuota)
+		} else {
+			fmt.Printf(indent+i18n.G("Objects: %d")+"\n", usage.Objects)
+		}
+
+		fmt.Printf(indent+i18n.G("Used: %v")+"\n", units.GetByteSizeStringIEC(usage.Used, 2))
+
+		if usage.Total >= 0 {
+			fmt.Printf(indent+i18n.G("Total: %v")+"\n", units.GetByteSizeStringIEC(usage.Total, 2))
+		}
+	}
+
+	fmt.Println(i18n.G("Usage:"))
+	printUsage("  ", state.Usage)
+
+	if len(state.Keys) > 0 {
+		fmt.Println("\n" + i18n.G("Keys:"))
+
+		names := slices.Sorted(maps.Keys(state.Keys))
+		for _, name := range names {
+			fmt.Printf("  %s:\n", name)
+			printUsage("    ", state.Keys[name])
+		}
+	}
+
+	return nil
+}
+
 // List.
 type cmdStorageBucketList struct {
 	global        *cmdGlobal
@@ -967,6 +1048,7 @@ type cmdStorageBucketKeyCreate struct {
 	flagAccessKey    string
 	flagSecretKey    string
 	flagDescription  string
+	flagQuota        string
 }
 
 var cmdStorageBucketKeyCreateUsage = u.Usage{u.Pool.Remote(), u.Bucket, u.NewName(u.Key)}
@@ -990,6 +1072,7 @@ incus storage bucket key create p1 b01 k1 < config.yaml
 	cli.AddStringFlag(cmd.Flags(), &c.flagAccessKey, "access-key", "", "", i18n.G("Access key (auto-generated if empty)"))
 	cli.AddStringFlag(cmd.Flags(), &c.flagSecretKey, "secret-key", "", "", i18n.G("Secret key (auto-generated if empty)"))
 	cli.AddStringFlag(cmd.Flags(), &c.flagDescription, "description", "", "", i18n.G("Key description"))
+	cli.AddStringFlag(cmd.Flags(), &c.flagQuota, "quota", "", "", i18n.G("Maximum size of the objects stored with the key"))
 
 	return cmd
 }
@@ -1045,6 +1128,10 @@ func (c *cmdStorageBucketKeyCreate) runAdd(cmd *cobra.Command, args []string) er
 		req.Description = c.flagDescription
 	}
 
+	if c.flagQuota != "" {
+		req.Quota = c.flagQuota
+	}
+
 	key, err := d.CreateStoragePoolBucketKey(poolName, bucketName, req)
 	if err != nil {
 		return err
@@ -1374,6 +1374,18 @@ func (c *cmdStorageVolumeInfo) run(cmd *cobra.Command, args []string) error {
 		if volState.Usage.Total > 0 {
 			fmt.Printf(i18n.G("Total: %s")+"\n", units.GetByteSizeStringIEC(int64(volState.Usage.Total), 2))
 		}
+
+		if volState.Usage.Snapshots > 0 {
+			fmt.Printf(i18n.G("Snapshots usage: %s")+"\n", units.GetByteSizeStringIEC(int64(volState.Usage.Snapshots), 2))
+		}
+
+		if volState.Usage.CompressionRatio > 0 {
+			fmt.Printf(i18n.G("Compression ratio: %.2f")+"\n", volState.Usage.CompressionRatio)
+		}
+
+		if volState.Usage.OvercommitRatio > 0 {
+			fmt.Printf(i18n.G("Overcommit ratio: %.2f")+"\n", volState.Usage.OvercommitRatio)
+		}
 	}
 
 	if !vol.CreatedAt.IsZero() {
@@ -733,6 +733,58 @@ func sshSFTPServer(ctx context.Context, sftpConn func() (net.Conn, error), authN
 	}
 }
 
+// packetCapture runs a packet capture, writing the packets to the target file (or stdout) until the capture
+// ends or the user interrupts it.
+func packetCapture(targetPath string, start func(args *incus.NetworkCaptureArgs) (incus.Operation, error)) error {
+	var output io.Writer = os.Stdout
+
+	if !isStdout(targetPath) {
+		target, err := os.Create(targetPath)
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = target.Close() }()
+
+		output = target
+	}
+
+	args := &incus.NetworkCaptureArgs{
+		Output:   output,
+		DataDone: make(chan bool),
+	}
+
+	chSignal := make(chan os.Signal, 1)
+	signal.Notify(chSignal, os.Interrupt)
+	defer signal.Stop(chSignal)
+
+	op, err := start(args)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(os.Stderr, i18n.G("Capturing packets, press ctrl+c to finish"))
+
+	// Stop the capture when interrupted, the packets captured so far are still written.
+	interrupted := false
+
+	select {
+	case <-args.DataDone:
+	case <-chSignal:
+		interrupted = true
+
+		_ = op.Cancel()
+		<-args.DataDone
+	}
+
+	err = op.Wait()
+	if err != nil && !interrupted {
+		return err
+	}
+
+	return nil
+}
+
 // formatRemote formats a remote object.
 func formatRemote(conf *config.Config, p *u.Parsed) string {
 	if p.RemoteName == conf.DefaultRemote {
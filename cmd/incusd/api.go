@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"maps"
 	"net/http"
 	"net/url"
 	"os"
@@ -15,6 +16,7 @@ import (
 	clusterRequest "github.com/lxc/incus/v7/internal/server/cluster/request"
 	"github.com/lxc/incus/v7/internal/server/db"
 	"github.com/lxc/incus/v7/internal/server/instance"
+	"github.com/lxc/incus/v7/internal/server/lifecycle"
 	"github.com/lxc/incus/v7/internal/server/request"
 	"github.com/lxc/incus/v7/internal/server/response"
 	storagePools "github.com/lxc/incus/v7/internal/server/storage"
@@ -22,6 +24,7 @@ import (
 	"github.com/lxc/incus/v7/internal/server/storage/s3/local"
 	"github.com/lxc/incus/v7/shared/api"
 	"github.com/lxc/incus/v7/shared/logger"
+	"github.com/lxc/incus/v7/shared/units"
 	"github.com/lxc/incus/v7/shared/util"
 )
 
@@ -363,10 +366,21 @@ func serveLocalBucket(d *Daemon, w http.ResponseWriter, r *http.Request, bucket
 
 	creds := make([]local.Credential, 0, len(keys))
 	for _, k := range keys {
+		var quota int64
+		if k.Quota != "" {
+			quota, err = units.ParseByteSizeString(k.Quota)
+			if err != nil {
+				(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
+				return
+			}
+		}
+
 		creds = append(creds, local.Credential{
+			Name:      k.Name,
 			AccessKey: k.AccessKey,
 			SecretKey: k.SecretKey,
 			Role:      local.Role(k.Role),
+			Quota:     quota,
 		})
 	}
 
@@ -385,6 +399,31 @@ func serveLocalBucket(d *Daemon, w http.ResponseWriter, r *http.Request, bucket
 
 	srv := local.NewServer(bucketDir, creds)
 
+	err = srv.SetConfig(bucket.Config)
+	if err != nil {
+		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
+		return
+	}
+
+	// Persist versioning changes requested through the S3 API in the bucket configuration.
+	srv.OnVersioningChange = func(status local.Versioning) error {
+		config := maps.Clone(bucket.Config)
+		if config == nil {
+			config = map[string]string{}
+		}
+
+		config["versioning"] = strings.ToLower(string(status))
+
+		err := pool.UpdateBucket(bucket.Project, bucket.Name, api.StorageBucketPut{Config: config, Description: bucket.Description}, nil)
+		if err != nil {
+			return err
+		}
+
+		s.Events.SendLifecycle(bucket.Project, lifecycle.StorageBucketUpdated.Event(pool, bucket.Project, bucket.Name, request.CreateRequestor(r), nil))
+
+		return nil
+	}
+
 	// Migrate any data left over from the legacy minio layout, but only
 	// once the request has cleared authentication. This is a no-op once
 	// the bucket has been migrated.
@@ -80,6 +80,7 @@ var api10 = []APIEndpoint{
 	instanceAccessCmd,
 	instanceDebugMemoryCmd,
 	instanceDebugRepairCmd,
+	instanceDeviceCaptureCmd,
 	eventsCmd,
 	imageAliasCmd,
 	imageAliasesCmd,
@@ -87,6 +88,8 @@ var api10 = []APIEndpoint{
 	imagesCmd,
 	metadataConfigurationCmd,
 	networkCmd,
+	networkCaptureCmd,
+	networkLeaseCmd,
 	networkLeasesCmd,
 	networksCmd,
 	networkStateCmd,
@@ -96,6 +99,8 @@ var api10 = []APIEndpoint{
 	networkAddressSetCmd,
 	networkAddressSetsCmd,
 	networkAllocationsCmd,
+	networkEgressGatewayCmd,
+	networkEgressGatewaysCmd,
 	networkForwardCmd,
 	networkForwardsCmd,
 	networkIntegrationCmd,
@@ -120,12 +125,14 @@ var api10 = []APIEndpoint{
 	projectStateCmd,
 	projectAccessCmd,
 	storagePoolCmd,
+	storagePoolCheckCmd,
 	storagePoolResourcesCmd,
 	storagePoolsCmd,
 	storagePoolBucketsCmd,
 	storagePoolBucketCmd,
 	storagePoolBucketKeysCmd,
 	storagePoolBucketKeyCmd,
+	storagePoolBucketStateCmd,
 	storagePoolBucketBackupsCmd,
 	storagePoolBucketBackupCmd,
 	storagePoolBucketBackupsExportCmd,
@@ -7,6 +7,7 @@ import (
 	"net"
 	"net/http"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -704,6 +705,11 @@ func evacuateClusterSelectTarget(ctx context.Context, s *state.State, inst insta
 			return err
 		}
 
+		// The instance must move away from its current location.
+		candidateMembers = slices.DeleteFunc(candidateMembers, func(member db.NodeInfo) bool {
+			return member.ID == srcMember.ID
+		})
+
 		return nil
 	})
 	if err != nil {
@@ -40,6 +40,12 @@ var (
 	metricsCacheLock sync.Mutex
 )
 
+// Storage volume metrics are cached for longer as gathering them requires querying the storage for every volume.
+var (
+	storageVolumeMetricsCache         map[string]metricsCacheEntry
+	storageVolumeMetricsCacheDuration = time.Minute
+)
+
 var metricsCmd = APIEndpoint{
 	Path: "metrics",
 
@@ -165,6 +171,9 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 		labels := map[string]string{"pool": poolName, "driver": pool.Driver().Info().Name}
 		intMetrics.AddSamples(metrics.StoragePoolUsedBytes, metrics.Sample{Labels: labels, Value: float64(res.Space.Used)})
 		intMetrics.AddSamples(metrics.StoragePoolSizeBytes, metrics.Sample{Labels: labels, Value: float64(res.Space.Total)})
+
+		// Add the metrics of the pool's volumes.
+		intMetrics.Merge(storageVolumeMetrics(r.Context(), s, pool, projectNames))
 	}
 
 	// invalidProjectFilters returns project filters which are either not in cache or have expired.
@@ -338,6 +347,123 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 	return getFilteredMetrics(s, r, compress, metricSet)
 }
 
+// storageVolumeMetrics returns the metrics of the pool's volumes available on this server for the given projects.
+func storageVolumeMetrics(ctx context.Context, s *state.State, pool storagePools.Pool, projectNames []string) *metrics.MetricSet {
+	out := metrics.NewMetricSet(nil)
+
+	// Use the cached metrics when still valid.
+	metricsCacheLock.Lock()
+
+	missingProjects := make(map[string]*metrics.MetricSet, len(projectNames))
+	for _, projectName := range projectNames {
+		cache, ok := storageVolumeMetricsCache[pool.Name()+"/"+projectName]
+		if ok && cache.expiry.After(time.Now()) {
+			out.Merge(cache.metrics)
+			continue
+		}
+
+		missingProjects[projectName] = metrics.NewMetricSet(nil)
+	}
+
+	metricsCacheLock.Unlock()
+
+	if len(missingProjects) == 0 {
+		return out
+	}
+
+	var dbVolumes []*db.StorageVolume
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		dbVolumes, err = tx.GetStoragePoolVolumes(ctx, pool.ID(), true)
+		return err
+	})
+	if err != nil {
+		logger.Warn("Failed loading storage volumes", logger.Ctx{"pool": pool.Name(), "err": err})
+		return out
+	}
+
+	for _, dbVol := range dbVolumes {
+		projectMetrics, ok := missingProjects[dbVol.Project]
+		_, snapName, _ := api.GetParentAndSnapshotName(dbVol.Name)
+		if !ok || snapName != "" {
+			continue
+		}
+
+		var usage *storagePools.VolumeUsage
+
+		switch dbVol.Type {
+		case db.StoragePoolVolumeTypeNameCustom:
+			usage, err = pool.GetCustomVolumeUsage(dbVol.Project, dbVol.Name)
+
+		case db.StoragePoolVolumeTypeNameContainer, db.StoragePoolVolumeTypeNameVM:
+			var inst instance.Instance
+
+			inst, err = instance.LoadByProjectAndName(s, dbVol.Project, dbVol.Name)
+			if err != nil {
+				break
+			}
+
+			// Instance volumes on remote pools are reported by the server running the instance.
+			if s.ServerClustered && inst.Location() != s.ServerName {
+				continue
+			}
+
+			usage, err = pool.GetInstanceUsage(inst)
+
+		default:
+			continue
+		}
+
+		if err != nil {
+			logger.Warn("Failed getting storage volume usage", logger.Ctx{"pool": pool.Name(), "project": dbVol.Project, "type": dbVol.Type, "volume": dbVol.Name, "err": err})
+			continue
+		}
+
+		labels := map[string]string{"pool": pool.Name(), "driver": pool.Driver().Info().Name, "project": dbVol.Project, "type": dbVol.Type, "volume": dbVol.Name}
+
+		if usage.Used >= 0 {
+			projectMetrics.AddSamples(metrics.StorageVolumeUsedBytes, metrics.Sample{Labels: labels, Value: float64(usage.Used)})
+		}
+
+		if usage.Total > 0 {
+			projectMetrics.AddSamples(metrics.StorageVolumeSizeBytes, metrics.Sample{Labels: labels, Value: float64(usage.Total)})
+		}
+
+		if usage.SnapshotsUsed >= 0 {
+			projectMetrics.AddSamples(metrics.StorageVolumeSnapshotsBytes, metrics.Sample{Labels: labels, Value: float64(usage.SnapshotsUsed)})
+		}
+
+		if usage.CompressionRatio > 0 {
+			projectMetrics.AddSamples(metrics.StorageVolumeCompression, metrics.Sample{Labels: labels, Value: usage.CompressionRatio})
+		}
+
+		if usage.OvercommitRatio > 0 {
+			projectMetrics.AddSamples(metrics.StorageVolumeOvercommit, metrics.Sample{Labels: labels, Value: usage.OvercommitRatio})
+		}
+	}
+
+	// Put the new data in the cache and in the result.
+	metricsCacheLock.Lock()
+
+	if storageVolumeMetricsCache == nil {
+		storageVolumeMetricsCache = map[string]metricsCacheEntry{}
+	}
+
+	for projectName, projectMetrics := range missingProjects {
+		storageVolumeMetricsCache[pool.Name()+"/"+projectName] = metricsCacheEntry{
+			expiry:  time.Now().Add(storageVolumeMetricsCacheDuration),
+			metrics: projectMetrics,
+		}
+
+		out.Merge(projectMetrics)
+	}
+
+	metricsCacheLock.Unlock()
+
+	return out
+}
+
 func getFilteredMetrics(s *state.State, r *http.Request, compress bool, metricSet *metrics.MetricSet) response.Response {
 	if !s.GlobalConfig.MetricsAuthentication() {
 		return response.SyncResponsePlain(true, compress, metricSet.String())
@@ -1739,6 +1739,13 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		// shortdesc: MAC address template
 		"network.hwaddr_pattern": validate.Optional(validate.IsMACPattern),
 
+		// gendoc:generate(entity=project, group=specific, key=network.qos_class)
+		// The NICs of the project's instances that don't set `qos.class` are put in this QoS class of their network, if the network has it.
+		// ---
+		// type: string
+		// shortdesc: Default network QoS class of the instance NICs
+		"network.qos_class": validate.IsAny,
+
 		// gendoc:generate(entity=project, group=restricted, key=restricted)
 		// This option must be enabled to allow the `restricted.*` keys to take effect.
 		// To temporarily remove the restrictions, you can disable this option instead of clearing the related keys.
@@ -1933,6 +1940,8 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		// - When set to `block`, this option prevents using all network devices.
 		// - When set to `managed`, this option allows using network devices only if `network=` is set.
 		// - When set to `allow`, there is no restriction on which network devices can be used.
+		//
+		// Host interfaces can only be used as traffic mirror targets of network devices when set to `allow`.
 		// ---
 		//  type: string
 		//  defaultdesc: `managed`
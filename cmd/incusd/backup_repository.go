@@ -0,0 +1,394 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	internalInstance "github.com/lxc/incus/v7/internal/instance"
+	"github.com/lxc/incus/v7/internal/revert"
+	"github.com/lxc/incus/v7/internal/server/auth"
+	"github.com/lxc/incus/v7/internal/server/backup/repository"
+	"github.com/lxc/incus/v7/internal/server/db"
+	"github.com/lxc/incus/v7/internal/server/db/operationtype"
+	"github.com/lxc/incus/v7/internal/server/instance"
+	"github.com/lxc/incus/v7/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v7/internal/server/operations"
+	"github.com/lxc/incus/v7/internal/server/response"
+	"github.com/lxc/incus/v7/internal/server/state"
+	storagePools "github.com/lxc/incus/v7/internal/server/storage"
+	storageDrivers "github.com/lxc/incus/v7/internal/server/storage/drivers"
+	"github.com/lxc/incus/v7/shared/api"
+	"github.com/lxc/incus/v7/shared/logger"
+)
+
+// backupRepositoryBitmapPrefix is the name prefix of the dirty bitmaps tracking changes between repository backups.
+const backupRepositoryBitmapPrefix = "incus-repository-"
+
+// backupRepositoryOpen opens the backup repository of the backup target named location.
+func backupRepositoryOpen(s *state.State, location string) (*repository.Repository, error) {
+	err := internalInstance.ValidateBackupRepository(location)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.GlobalConfig.BackupTarget(location)
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.OpenTarget(target)
+}
+
+// backupRepositoryCreate stores a block volume in the backup repository at location and returns the backup name.
+// inst and deviceName are the instance using the volume and the disk device name, if any. While the instance is
+// running, changes since the previous backup are tracked through a dirty bitmap, so only changed chunks get read.
+func backupRepositoryCreate(s *state.State, location string, key string, inst instance.Instance, deviceName string, connect func() (net.Conn, func(), error), op *operations.Operation) (string, error) {
+	repo, err := backupRepositoryOpen(s, location)
+	if err != nil {
+		return "", err
+	}
+
+	defer repo.Close()
+
+	names, err := repo.Backups(key)
+	if err != nil {
+		return "", err
+	}
+
+	var previous *repository.Manifest
+	if len(names) > 0 {
+		previous, err = repo.Manifest(key, names[len(names)-1])
+		if err != nil {
+			return "", err
+		}
+	}
+
+	name := time.Now().UTC().Format("20060102-150405")
+	if slices.Contains(names, name) {
+		return "", fmt.Errorf("Backup %q already exists in the repository", name)
+	}
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	// Without a running instance, the dirty bitmap of the previous backup can still be used if it's persistent
+	// (it's then exported by the NBD server if present), it just keeps tracking changes for the next backup.
+	trackedBitmap := ""
+	newBitmap := ""
+
+	if previous != nil {
+		trackedBitmap = previous.Bitmap
+	}
+
+	if inst != nil && inst.IsRunning() {
+		if trackedBitmap != "" {
+			bitmaps, err := inst.GetBitmaps(deviceName)
+			if err != nil {
+				return "", fmt.Errorf("Failed getting dirty bitmaps: %w", err)
+			}
+
+			if !slices.ContainsFunc(bitmaps, func(b api.StorageVolumeBitmap) bool { return b.Name == trackedBitmap && !b.Inconsistent }) {
+				trackedBitmap = ""
+			}
+		}
+
+		newBitmap = backupRepositoryBitmapPrefix + name
+
+		// Persistent bitmaps require a qcow2 disk, fallback to a bitmap lasting until the instance stops.
+		req := api.StorageVolumeBitmapsPost{Name: newBitmap, Persistent: true}
+
+		err = inst.CreateBitmap([]string{deviceName}, req)
+		if err != nil {
+			req.Persistent = false
+
+			err = inst.CreateBitmap([]string{deviceName}, req)
+			if err != nil {
+				return "", fmt.Errorf("Failed creating dirty bitmap: %w", err)
+			}
+		}
+
+		reverter.Add(func() { _ = inst.DeleteBitmap(deviceName, newBitmap) })
+	}
+
+	conn, disconnect, err := connect()
+	if err != nil {
+		return "", err
+	}
+
+	defer disconnect()
+
+	vol, err := repository.NewNBDVolume(conn, trackedBitmap)
+	if err != nil {
+		return "", err
+	}
+
+	defer func() { _ = vol.Close() }()
+
+	if !vol.Tracked() {
+		previous = nil
+		trackedBitmap = ""
+	}
+
+	manifestBitmap := newBitmap
+	if inst == nil || !inst.IsRunning() {
+		manifestBitmap = trackedBitmap
+	}
+
+	var progress func(percent int64)
+	if op != nil {
+		metadata := make(map[string]any)
+		progress = func(percent int64) {
+			operations.SetProgressMetadata(metadata, "backup_progress", "Backing up", percent, 0, 0)
+			_ = op.UpdateMetadata(metadata)
+		}
+	}
+
+	_, err = repo.Backup(key, name, manifestBitmap, vol, previous, progress)
+	if err != nil {
+		return "", err
+	}
+
+	// Changes are now tracked from this backup onwards.
+	if newBitmap != "" && trackedBitmap != "" {
+		err = inst.DeleteBitmap(deviceName, trackedBitmap)
+		if err != nil {
+			logger.Warn("Failed deleting previous backup dirty bitmap", logger.Ctx{"bitmap": trackedBitmap, "err": err})
+		}
+	}
+
+	reverter.Success()
+
+	return name, nil
+}
+
+// backupRepositoryInstance stores the root disk of a virtual machine in the backup repository at location.
+func backupRepositoryInstance(s *state.State, inst instance.Instance, location string, op *operations.Operation) (string, error) {
+	if inst.Type() != instancetype.VM {
+		return "", errors.New("Backup repositories are only supported for virtual machines")
+	}
+
+	pool, err := storagePools.LoadByInstance(s, inst)
+	if err != nil {
+		return "", err
+	}
+
+	deviceName, _, err := internalInstance.GetRootDiskDevice(inst.ExpandedDevices().CloneNative())
+	if err != nil {
+		return "", err
+	}
+
+	connect := func() (net.Conn, func(), error) {
+		return pool.GetInstanceNBD(inst, false)
+	}
+
+	key := repository.VolumeKey(inst.Project().Name, db.StoragePoolVolumeTypeNameVM, inst.Name())
+
+	return backupRepositoryCreate(s, location, key, inst, deviceName, connect, op)
+}
+
+// backupRepositoryCustomVolume stores a custom block volume in the backup repository at location.
+func backupRepositoryCustomVolume(s *state.State, projectName string, poolName string, volName string, location string, op *operations.Operation) (string, error) {
+	pool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		return "", err
+	}
+
+	dbVol, err := storagePools.VolumeDBGet(pool, projectName, volName, storageDrivers.VolumeTypeCustom)
+	if err != nil {
+		return "", err
+	}
+
+	if dbVol.ContentType != db.StoragePoolVolumeContentTypeNameBlock {
+		return "", errors.New("Backup repositories are only supported for block volumes")
+	}
+
+	inst, deviceName, err := storagePools.InstanceByVolumeName(s, poolName, projectName, volName, db.StoragePoolVolumeTypeCustom)
+	if err != nil && !errors.Is(err, storagePools.ErrVolumeNotAttachedToRunningInstance) {
+		return "", err
+	}
+
+	connect := func() (net.Conn, func(), error) {
+		return pool.GetCustomVolumeNBD(projectName, volName, false)
+	}
+
+	key := repository.VolumeKey(projectName, db.StoragePoolVolumeTypeNameCustom, volName)
+
+	return backupRepositoryCreate(s, location, key, inst, deviceName, connect, op)
+}
+
+// backupRepositoryPrune deletes the oldest backups of the volume so that at most keep are left, then removes
+// the chunks which aren't used anymore.
+func backupRepositoryPrune(s *state.State, location string, key string, keep int) error {
+	repo, err := backupRepositoryOpen(s, location)
+	if err != nil {
+		return err
+	}
+
+	defer repo.Close()
+
+	names, err := repo.Backups(key)
+	if err != nil {
+		return err
+	}
+
+	if len(names) <= keep {
+		return nil
+	}
+
+	for _, name := range names[:len(names)-keep] {
+		err = repo.Delete(key, name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return repo.Prune()
+}
+
+// doVolumeCreateFromRepository creates a custom block volume from a backup stored in a backup repository.
+func doVolumeCreateFromRepository(s *state.State, r *http.Request, requestProjectName string, projectName string, poolName string, req *api.StorageVolumesPost) response.Response {
+	if req.Source.Repository == "" {
+		return response.BadRequest(errors.New("No backup repository supplied"))
+	}
+
+	err := internalInstance.ValidateBackupRepository(req.Source.Repository)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// The source name can be prefixed with the volume type, custom volumes being assumed otherwise.
+	srcType := db.StoragePoolVolumeTypeNameCustom
+	srcName := req.Source.Name
+
+	before, after, found := strings.Cut(req.Source.Name, "/")
+	if found {
+		srcType = before
+		srcName = after
+	}
+
+	if srcName == "" {
+		return response.BadRequest(errors.New("No source volume name supplied"))
+	}
+
+	// The names are used as keys within the repository, so they can't be paths.
+	for _, name := range []string{srcName, req.Source.Backup} {
+		if strings.Contains(name, "/") || strings.Contains(name, "..") {
+			return response.BadRequest(fmt.Errorf("Invalid name %q", name))
+		}
+	}
+
+	if !slices.Contains([]string{db.StoragePoolVolumeTypeNameCustom, db.StoragePoolVolumeTypeNameVM}, srcType) {
+		return response.BadRequest(fmt.Errorf("Unsupported source volume type %q", srcType))
+	}
+
+	srcProjectName := req.Source.Project
+	if srcProjectName == "" {
+		srcProjectName = projectName
+	}
+
+	// The backups of a project may outlive its volumes, so access is checked on the source project.
+	err = s.Authorizer.CheckPermission(r.Context(), r, auth.ObjectProject(srcProjectName), auth.EntitlementCanCreateStorageVolumes)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	run := func(op *operations.Operation) error {
+		repo, err := backupRepositoryOpen(s, req.Source.Repository)
+		if err != nil {
+			return err
+		}
+
+		defer repo.Close()
+
+		key := repository.VolumeKey(srcProjectName, srcType, srcName)
+
+		backupName := req.Source.Backup
+		if backupName == "" {
+			names, err := repo.Backups(key)
+			if err != nil {
+				return err
+			}
+
+			if len(names) == 0 {
+				return fmt.Errorf("No backups of %q found in the repository", req.Source.Name)
+			}
+
+			backupName = names[len(names)-1]
+		}
+
+		manifest, err := repo.Manifest(key, backupName)
+		if err != nil {
+			return err
+		}
+
+		config := maps.Clone(req.Config)
+		if config == nil {
+			config = map[string]string{}
+		}
+
+		if config["size"] == "" {
+			config["size"] = fmt.Sprintf("%dB", manifest.Size)
+		}
+
+		reverter := revert.New()
+		defer reverter.Fail()
+
+		err = pool.CreateCustomVolume(projectName, req.Name, req.Description, config, storageDrivers.ContentTypeBlock, op)
+		if err != nil {
+			return err
+		}
+
+		reverter.Add(func() { _ = pool.DeleteCustomVolume(projectName, req.Name, op) })
+
+		conn, disconnect, err := pool.GetCustomVolumeNBD(projectName, req.Name, true)
+		if err != nil {
+			return err
+		}
+
+		defer disconnect()
+
+		vol, err := repository.NewNBDVolume(conn, "")
+		if err != nil {
+			return err
+		}
+
+		metadata := make(map[string]any)
+		progress := func(percent int64) {
+			operations.SetProgressMetadata(metadata, "restore_progress", "Restoring", percent, 0, 0)
+			_ = op.UpdateMetadata(metadata)
+		}
+
+		err = repo.Restore(key, backupName, vol, progress)
+		if err != nil {
+			_ = vol.Close()
+			return err
+		}
+
+		err = vol.Close()
+		if err != nil {
+			return err
+		}
+
+		reverter.Success()
+
+		return nil
+	}
+
+	op, err := operations.OperationCreate(s, requestProjectName, operations.OperationClassTask, operationtype.CustomVolumeBackupRestore, nil, nil, run, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
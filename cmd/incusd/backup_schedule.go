@@ -0,0 +1,601 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	internalInstance "github.com/lxc/incus/v7/internal/instance"
+	internalBackup "github.com/lxc/incus/v7/internal/server/backup"
+	"github.com/lxc/incus/v7/internal/server/backup/repository"
+	"github.com/lxc/incus/v7/internal/server/db"
+	dbCluster "github.com/lxc/incus/v7/internal/server/db/cluster"
+	"github.com/lxc/incus/v7/internal/server/db/operationtype"
+	"github.com/lxc/incus/v7/internal/server/instance"
+	"github.com/lxc/incus/v7/internal/server/lifecycle"
+	"github.com/lxc/incus/v7/internal/server/operations"
+	"github.com/lxc/incus/v7/internal/server/project"
+	"github.com/lxc/incus/v7/internal/server/state"
+	"github.com/lxc/incus/v7/internal/server/task"
+	localUtil "github.com/lxc/incus/v7/internal/server/util"
+	"github.com/lxc/incus/v7/shared/api"
+	"github.com/lxc/incus/v7/shared/logger"
+)
+
+// scheduledBackupPrefix is the name prefix of backups created through backups.schedule.
+const scheduledBackupPrefix = "auto-backup"
+
+// backupNextName returns the first unused "<prefix><n>" backup name for the given parent.
+func backupNextName(parentName string, prefix string, backupNames []string) string {
+	base := parentName + internalInstance.SnapshotDelimiter + prefix
+	backupID := 0
+
+	for _, backupName := range backupNames {
+		// Ignore backups not containing base.
+		if !strings.HasPrefix(backupName, base) {
+			continue
+		}
+
+		var num int
+		count, err := fmt.Sscanf(backupName[len(base):], "%d", &num)
+		if err != nil || count != 1 {
+			continue
+		}
+
+		if num >= backupID {
+			backupID = num + 1
+		}
+	}
+
+	return fmt.Sprintf("%s%d", prefix, backupID)
+}
+
+// scheduledBackup is a backup considered by backups.retention.count.
+type scheduledBackup struct {
+	name      string
+	createdAt time.Time
+}
+
+// scheduledBackupsToPrune returns the names of the backups to delete so that only the newest keep backups whose
+// name starts with prefix are left. The other backups are never deleted.
+func scheduledBackupsToPrune(backups []scheduledBackup, prefix string, keep int) []string {
+	backups = slices.DeleteFunc(slices.Clone(backups), func(b scheduledBackup) bool {
+		return !strings.HasPrefix(b.name, prefix)
+	})
+
+	// Newest first.
+	slices.SortFunc(backups, func(a scheduledBackup, b scheduledBackup) int {
+		return cmp.Or(b.createdAt.Compare(a.createdAt), strings.Compare(b.name, a.name))
+	})
+
+	names := []string{}
+	for _, b := range backups[min(keep, len(backups)):] {
+		names = append(names, b.name)
+	}
+
+	return names
+}
+
+// scheduledBackupRetentionCount returns the number of scheduled backups to keep, 0 if unlimited.
+func scheduledBackupRetentionCount(retention string) (int, error) {
+	if retention == "" {
+		return 0, nil
+	}
+
+	keep, err := strconv.Atoi(retention)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid backups.retention.count: %w", err)
+	}
+
+	return max(keep, 0), nil
+}
+
+// scheduledBackupTarget returns the S3 upload target for a scheduled backup, or nil if none is configured.
+func scheduledBackupTarget(s *state.State, config map[string]string, elems ...string) (*api.BackupTarget, error) {
+	if config["backups.target"] == "" {
+		return nil, nil
+	}
+
+	target, err := s.GlobalConfig.BackupTarget(config["backups.target"])
+	if err != nil {
+		return nil, err
+	}
+
+	if target.Protocol != "s3" {
+		return nil, fmt.Errorf("Backup target %q can only be used as a backup repository", config["backups.target"])
+	}
+
+	elems = append([]string{target.Path}, elems...)
+	target.Path = strings.TrimPrefix(path.Join(append(elems, scheduledBackupPrefix+"-"+time.Now().UTC().Format("20060102-150405"))...), "/")
+
+	return target, nil
+}
+
+// scheduledBackupTargetRetention applies backups.retention.count to the scheduled backups uploaded alongside the
+// backup of target.
+func scheduledBackupTargetRetention(target *api.BackupTarget, retention string) error {
+	keep, err := scheduledBackupRetentionCount(retention)
+	if err != nil || keep == 0 {
+		return err
+	}
+
+	prefix := path.Join(path.Dir(target.Path), scheduledBackupPrefix+"-")
+
+	uploads, err := internalBackup.ListUploads(target, prefix)
+	if err != nil {
+		return fmt.Errorf("Failed listing uploaded backups: %w", err)
+	}
+
+	backups := make([]scheduledBackup, 0, len(uploads))
+	for key, modTime := range uploads {
+		backups = append(backups, scheduledBackup{name: key, createdAt: modTime})
+	}
+
+	for _, key := range scheduledBackupsToPrune(backups, prefix, keep) {
+		err = internalBackup.DeleteUpload(target, key)
+		if err != nil {
+			return fmt.Errorf("Failed deleting uploaded backup %q: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// scheduledBackupRepositoryRetention applies backups.retention.count to the backups of a volume in a backup repository.
+func scheduledBackupRepositoryRetention(s *state.State, location string, key string, retention string) error {
+	keep, err := scheduledBackupRetentionCount(retention)
+	if err != nil || keep == 0 {
+		return err
+	}
+
+	return backupRepositoryPrune(s, location, key, keep)
+}
+
+// scheduledBackupUpload runs create with a pipe writer whose content is uploaded to target.
+func scheduledBackupUpload(target *api.BackupTarget, create func(writer *io.PipeWriter) error) error {
+	reader, writer := io.Pipe()
+
+	uploadRes := make(chan error, 1)
+	go func() {
+		uploadRes <- internalBackup.Upload(reader, target)
+	}()
+
+	err := create(writer)
+	if err != nil {
+		// Fail the upload and prefer its error if it's the reason the pipe got closed.
+		_ = reader.Close()
+		uploadErr := <-uploadRes
+		if errors.Is(err, io.ErrClosedPipe) && uploadErr != nil {
+			return uploadErr
+		}
+
+		return err
+	}
+
+	return <-uploadRes
+}
+
+func autoCreateBackupsTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+		var instances []instance.Instance
+		var volumes, remoteVolumes []db.StorageVolumeArgs
+		var memberCount int
+		var onlineMemberIDs []int64
+
+		err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			// Get list of instances on the local member that are due to have backups created.
+			filter := dbCluster.InstanceFilter{Node: &s.ServerName}
+			err := tx.InstanceList(ctx, func(dbInst db.InstanceArgs, p api.Project) error {
+				inst, err := instance.Load(s, dbInst, p)
+				if err != nil {
+					return fmt.Errorf("Failed loading instance %q (project %q) for backup task: %w", dbInst.Name, dbInst.Project, err)
+				}
+
+				// Check if backup is scheduled.
+				schedule := inst.ExpandedConfig()["backups.schedule"]
+				if schedule == "" || !snapshotIsScheduledNow(schedule, int64(inst.ID())) {
+					return nil
+				}
+
+				err = project.AllowBackupCreation(tx, p.Name)
+				if err != nil {
+					return nil
+				}
+
+				logger.Debug("Scheduling auto instance backup", logger.Ctx{"instance": inst.Name(), "project": inst.Project().Name})
+				instances = append(instances, inst)
+
+				return nil
+			}, filter)
+			if err != nil {
+				return err
+			}
+
+			// Get list of custom volumes that are due to have backups created.
+			allVolumes, err := tx.GetStoragePoolVolumesWithType(ctx, db.StoragePoolVolumeTypeCustom, true)
+			if err != nil {
+				return fmt.Errorf("Failed getting volumes for auto custom volume backup task: %w", err)
+			}
+
+			for _, v := range allVolumes {
+				schedule := v.Config["backups.schedule"]
+				if schedule == "" || !snapshotIsScheduledNow(schedule, v.ID) {
+					continue
+				}
+
+				err = project.AllowBackupCreation(tx, v.ProjectName)
+				if err != nil {
+					continue
+				}
+
+				if v.NodeID < 0 {
+					// Keep a separate list of remote volumes in order to select a member to
+					// perform the backup later.
+					remoteVolumes = append(remoteVolumes, v)
+				} else {
+					logger.Debug("Scheduling local auto custom volume backup", logger.Ctx{"volName": v.Name, "project": v.ProjectName, "pool": v.PoolName})
+					volumes = append(volumes, v) // Always include local volumes.
+				}
+			}
+
+			if len(remoteVolumes) > 0 {
+				// Get list of cluster members.
+				members, err := tx.GetNodes(ctx)
+				if err != nil {
+					return fmt.Errorf("Failed getting cluster members: %w", err)
+				}
+
+				memberCount = len(members)
+
+				// Filter to online members.
+				for _, member := range members {
+					if member.IsOffline(s.GlobalConfig.OfflineThreshold()) {
+						continue
+					}
+
+					onlineMemberIDs = append(onlineMemberIDs, member.ID)
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			logger.Error("Failed getting backup schedule info", logger.Ctx{"err": err})
+			return
+		}
+
+		if len(remoteVolumes) > 0 {
+			// Skip backing up remote custom volumes if there are no online members, as we can't be
+			// sure that the cluster isn't partitioned and we may end up attempting the backup on
+			// multiple members.
+			if memberCount > 1 && len(onlineMemberIDs) <= 0 {
+				logger.Error("Skipping remote volumes for auto custom volume backup task due to no online members")
+			} else {
+				localMemberID := s.DB.Cluster.GetNodeID()
+
+				for _, v := range remoteVolumes {
+					// If there are multiple cluster members, a stable random member is chosen
+					// to perform the backup from.
+					if memberCount > 1 {
+						selectedNodeID, err := localUtil.GetStableRandomInt64FromList(int64(v.ID), onlineMemberIDs)
+						if err != nil {
+							logger.Error("Failed scheduling remote auto custom volume backup task", logger.Ctx{"volName": v.Name, "project": v.ProjectName, "pool": v.PoolName, "err": err})
+							continue
+						}
+
+						// Don't back up, if we're not the chosen one.
+						if localMemberID != selectedNodeID {
+							continue
+						}
+					}
+
+					logger.Debug("Scheduling remote auto custom volume backup", logger.Ctx{"volName": v.Name, "project": v.ProjectName, "pool": v.PoolName})
+					volumes = append(volumes, v)
+				}
+			}
+		}
+
+		if len(instances) > 0 {
+			opRun := func(op *operations.Operation) error {
+				return autoCreateInstanceBackups(ctx, s, op, instances)
+			}
+
+			op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.BackupCreate, nil, nil, opRun, nil, nil, nil)
+			if err != nil {
+				logger.Error("Failed creating scheduled instance backup operation", logger.Ctx{"err": err})
+			} else {
+				logger.Info("Creating scheduled instance backups")
+				err = op.Start()
+				if err != nil {
+					logger.Error("Failed starting scheduled instance backup operation", logger.Ctx{"err": err})
+				} else {
+					err = op.Wait(ctx)
+					if err != nil {
+						logger.Error("Failed scheduled instance backups", logger.Ctx{"err": err})
+					} else {
+						logger.Info("Done creating scheduled instance backups")
+					}
+				}
+			}
+		}
+
+		if len(volumes) > 0 {
+			opRun := func(op *operations.Operation) error {
+				return autoCreateCustomVolumeBackups(ctx, s, volumes)
+			}
+
+			op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.CustomVolumeBackupCreate, nil, nil, opRun, nil, nil, nil)
+			if err != nil {
+				logger.Error("Failed creating scheduled custom volume backup operation", logger.Ctx{"err": err})
+			} else {
+				logger.Info("Creating scheduled custom volume backups")
+				err = op.Start()
+				if err != nil {
+					logger.Error("Failed starting scheduled custom volume backup operation", logger.Ctx{"err": err})
+				} else {
+					err = op.Wait(ctx)
+					if err != nil {
+						logger.Error("Failed scheduled custom volume backups", logger.Ctx{"err": err})
+					} else {
+						logger.Info("Done creating scheduled custom volume backups")
+					}
+				}
+			}
+		}
+	}
+
+	first := true
+	schedule := func() (time.Duration, error) {
+		interval := time.Minute
+
+		if first {
+			first = false
+			return interval, task.ErrSkip
+		}
+
+		return interval, nil
+	}
+
+	return f, schedule
+}
+
+func autoCreateInstanceBackups(ctx context.Context, s *state.State, op *operations.Operation, instances []instance.Instance) error {
+	// Make the backups sequentially.
+	for _, inst := range instances {
+		err := ctx.Err()
+		if err != nil {
+			return err // Stop if context is cancelled.
+		}
+
+		err = autoCreateInstanceBackup(s, op, inst)
+		if err != nil {
+			return fmt.Errorf("Error creating backup for instance %q (project %q): %w", inst.Name(), inst.Project().Name, err)
+		}
+	}
+
+	return nil
+}
+
+func autoCreateInstanceBackup(s *state.State, op *operations.Operation, inst instance.Instance) error {
+	config := inst.ExpandedConfig()
+
+	args := db.InstanceBackup{
+		InstanceID:   inst.ID(),
+		CreationDate: time.Now(),
+	}
+
+	// Store the root disk in the backup repository without keeping a local copy.
+	location := config["backups.repository"]
+	if location != "" {
+		_, err := backupRepositoryInstance(s, inst, location, op)
+		if err != nil {
+			return err
+		}
+
+		key := repository.VolumeKey(inst.Project().Name, db.StoragePoolVolumeTypeNameVM, inst.Name())
+
+		return scheduledBackupRepositoryRetention(s, location, key, config["backups.retention.count"])
+	}
+
+	target, err := scheduledBackupTarget(s, config, inst.Project().Name, inst.Name())
+	if err != nil {
+		return err
+	}
+
+	// Upload the backup without keeping a local copy.
+	if target != nil {
+		err = scheduledBackupUpload(target, func(writer *io.PipeWriter) error {
+			return backupCreate(s, args, inst, op, writer)
+		})
+		if err != nil {
+			return err
+		}
+
+		s.Events.SendLifecycle(inst.Project().Name, lifecycle.InstanceBackupCreated.Event(args.Name, inst, nil))
+
+		return scheduledBackupTargetRetention(target, config["backups.retention.count"])
+	}
+
+	backups, err := inst.Backups()
+	if err != nil {
+		return err
+	}
+
+	backupNames := make([]string, 0, len(backups))
+	for _, b := range backups {
+		backupNames = append(backupNames, b.Name())
+	}
+
+	args.Name = inst.Name() + internalInstance.SnapshotDelimiter + backupNextName(inst.Name(), scheduledBackupPrefix, backupNames)
+
+	args.ExpiryDate, err = internalInstance.GetExpiry(args.CreationDate, config["backups.expiry"])
+	if err != nil {
+		return err
+	}
+
+	err = backupCreate(s, args, inst, op, nil)
+	if err != nil {
+		return err
+	}
+
+	s.Events.SendLifecycle(inst.Project().Name, lifecycle.InstanceBackupCreated.Event(args.Name, inst, nil))
+
+	keep, err := scheduledBackupRetentionCount(config["backups.retention.count"])
+	if err != nil || keep == 0 {
+		return err
+	}
+
+	backups, err = inst.Backups()
+	if err != nil {
+		return err
+	}
+
+	byName := make(map[string]*internalBackup.InstanceBackup, len(backups))
+	candidates := make([]scheduledBackup, 0, len(backups))
+	for i, b := range backups {
+		byName[b.Name()] = &backups[i]
+		candidates = append(candidates, scheduledBackup{name: b.Name(), createdAt: b.Render().CreatedAt})
+	}
+
+	for _, name := range scheduledBackupsToPrune(candidates, inst.Name()+internalInstance.SnapshotDelimiter+scheduledBackupPrefix, keep) {
+		err = byName[name].Delete()
+		if err != nil {
+			return fmt.Errorf("Failed deleting backup %q: %w", name, err)
+		}
+
+		s.Events.SendLifecycle(inst.Project().Name, lifecycle.InstanceBackupDeleted.Event(name, inst, nil))
+	}
+
+	return nil
+}
+
+func autoCreateCustomVolumeBackups(ctx context.Context, s *state.State, volumes []db.StorageVolumeArgs) error {
+	// Make the backups sequentially.
+	for _, v := range volumes {
+		err := ctx.Err()
+		if err != nil {
+			return err // Stop if context is cancelled.
+		}
+
+		err = autoCreateCustomVolumeBackup(ctx, s, v)
+		if err != nil {
+			return fmt.Errorf("Error creating backup for volume %q (project %q, pool %q): %w", v.Name, v.ProjectName, v.PoolName, err)
+		}
+	}
+
+	return nil
+}
+
+func autoCreateCustomVolumeBackup(ctx context.Context, s *state.State, v db.StorageVolumeArgs) error {
+	args := db.StoragePoolVolumeBackup{
+		VolumeID:     v.ID,
+		CreationDate: time.Now(),
+	}
+
+	// Store the volume in the backup repository without keeping a local copy.
+	location := v.Config["backups.repository"]
+	if location != "" {
+		_, err := backupRepositoryCustomVolume(s, v.ProjectName, v.PoolName, v.Name, location, nil)
+		if err != nil {
+			return err
+		}
+
+		key := repository.VolumeKey(v.ProjectName, db.StoragePoolVolumeTypeNameCustom, v.Name)
+
+		return scheduledBackupRepositoryRetention(s, location, key, v.Config["backups.retention.count"])
+	}
+
+	target, err := scheduledBackupTarget(s, v.Config, v.ProjectName, v.PoolName, v.Name)
+	if err != nil {
+		return err
+	}
+
+	// Upload the backup without keeping a local copy.
+	if target != nil {
+		err = scheduledBackupUpload(target, func(writer *io.PipeWriter) error {
+			return volumeBackupCreate(s, args, v.ProjectName, v.PoolName, v.Name, writer)
+		})
+		if err != nil {
+			return err
+		}
+
+		return scheduledBackupTargetRetention(target, v.Config["backups.retention.count"])
+	}
+
+	var backups []db.StoragePoolVolumeBackup
+
+	loadBackups := func() error {
+		return s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			poolID, err := tx.GetStoragePoolID(ctx, v.PoolName)
+			if err != nil {
+				return err
+			}
+
+			backups, err = tx.GetStoragePoolVolumeBackups(ctx, v.ProjectName, v.Name, poolID)
+			return err
+		})
+	}
+
+	err = loadBackups()
+	if err != nil {
+		return err
+	}
+
+	backupNames := make([]string, 0, len(backups))
+	for _, b := range backups {
+		backupNames = append(backupNames, b.Name)
+	}
+
+	args.Name = v.Name + internalInstance.SnapshotDelimiter + backupNextName(v.Name, scheduledBackupPrefix, backupNames)
+
+	args.ExpiryDate, err = internalInstance.GetExpiry(args.CreationDate, v.Config["backups.expiry"])
+	if err != nil {
+		return err
+	}
+
+	err = volumeBackupCreate(s, args, v.ProjectName, v.PoolName, v.Name, nil)
+	if err != nil {
+		return err
+	}
+
+	s.Events.SendLifecycle(v.ProjectName, lifecycle.StorageVolumeBackupCreated.Event(v.PoolName, db.StoragePoolVolumeTypeNameCustom, args.Name, v.ProjectName, nil, nil))
+
+	keep, err := scheduledBackupRetentionCount(v.Config["backups.retention.count"])
+	if err != nil || keep == 0 {
+		return err
+	}
+
+	err = loadBackups()
+	if err != nil {
+		return err
+	}
+
+	byName := make(map[string]db.StoragePoolVolumeBackup, len(backups))
+	candidates := make([]scheduledBackup, 0, len(backups))
+	for _, b := range backups {
+		byName[b.Name] = b
+		candidates = append(candidates, scheduledBackup{name: b.Name, createdAt: b.CreationDate})
+	}
+
+	for _, name := range scheduledBackupsToPrune(candidates, v.Name+internalInstance.SnapshotDelimiter+scheduledBackupPrefix, keep) {
+		b := byName[name]
+		volBackup := internalBackup.NewVolumeBackup(s, v.ProjectName, v.PoolName, v.Name, b.ID, b.Name, b.CreationDate, b.ExpiryDate, b.VolumeOnly, b.OptimizedStorage)
+
+		err = volBackup.Delete()
+		if err != nil {
+			return fmt.Errorf("Failed deleting backup %q: %w", b.Name, err)
+		}
+
+		s.Events.SendLifecycle(v.ProjectName, lifecycle.StorageVolumeBackupDeleted.Event(v.PoolName, db.StoragePoolVolumeTypeNameCustom, b.Name, v.ProjectName, nil, nil))
+	}
+
+	return nil
+}
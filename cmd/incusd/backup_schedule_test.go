@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackupNextName(t *testing.T) {
+	// No existing backups.
+	assert.Equal(t, "auto-backup0", backupNextName("c1", scheduledBackupPrefix, nil))
+
+	// Only backups matching the prefix are considered, and gaps aren't reused.
+	names := []string{
+		"c1/auto-backup0",
+		"c1/auto-backup3",
+		"c1/backup7",
+		"c1/auto-backupfoo",
+		"c2/auto-backup9",
+	}
+
+	assert.Equal(t, "auto-backup4", backupNextName("c1", scheduledBackupPrefix, names))
+	assert.Equal(t, "backup8", backupNextName("c1", "backup", names))
+	assert.Equal(t, "auto-backup10", backupNextName("c2", scheduledBackupPrefix, names))
+}
+
+func TestScheduledBackupsToPrune(t *testing.T) {
+	now := time.Now()
+
+	backups := []scheduledBackup{
+		{name: "c1/auto-backup0", createdAt: now.Add(-3 * time.Hour)},
+		{name: "c1/auto-backup2", createdAt: now.Add(-1 * time.Hour)},
+		{name: "c1/manual", createdAt: now.Add(-4 * time.Hour)},
+		{name: "c1/auto-backup1", createdAt: now.Add(-2 * time.Hour)},
+		{name: "c1/auto-backup3", createdAt: now},
+	}
+
+	// The oldest scheduled backups are pruned, manual backups are kept.
+	assert.Equal(t, []string{"c1/auto-backup1", "c1/auto-backup0"}, scheduledBackupsToPrune(backups, "c1/auto-backup", 2))
+
+	// Nothing is pruned when there are fewer backups than the count.
+	assert.Empty(t, scheduledBackupsToPrune(backups, "c1/auto-backup", 10))
+
+	// Backups created at the same time are ordered by name.
+	backups = []scheduledBackup{
+		{name: "p/c1/auto-backup-20260101-000000", createdAt: now},
+		{name: "p/c1/auto-backup-20260102-000000", createdAt: now},
+	}
+
+	assert.Equal(t, []string{"p/c1/auto-backup-20260101-000000"}, scheduledBackupsToPrune(backups, "p/c1/auto-backup-", 1))
+
+	// The input isn't modified.
+	assert.Equal(t, "p/c1/auto-backup-20260101-000000", backups[0].name)
+}
+
+func TestScheduledBackupRetentionCount(t *testing.T) {
+	keep, err := scheduledBackupRetentionCount("")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, keep)
+
+	keep, err = scheduledBackupRetentionCount("5")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, keep)
+
+	_, err = scheduledBackupRetentionCount("five")
+	assert.Error(t, err)
+}
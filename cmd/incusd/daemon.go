@@ -1603,6 +1603,11 @@ func (d *Daemon) init() error {
 		// Reap any forkproxy helpers left behind by an out-of-cgroup kill of the previous daemon.
 		cleanupOrphanedProxyHelpers(instances)
 
+		// Track the running instances for the periodic instance tasks.
+		for _, inst := range instances {
+			instanceDrivers.TrackRunning(inst, inst.IsRunning())
+		}
+
 		// Setup seccomp handler
 		seccompServer, err := seccomp.NewSeccompServer(d.State(), internalUtil.RunPath("seccomp.socket"), func(pid int32, state *state.State) (seccomp.Instance, error) {
 			return findContainerForPid(pid, state)
@@ -1673,12 +1678,21 @@ func (d *Daemon) init() error {
 		// Remove expired backups (hourly)
 		d.tasks.Add(pruneExpiredBackupsTask(d))
 
+		// Apply storage bucket lifecycle rules (hourly)
+		d.tasks.Add(applyStorageBucketLifecycleTask(d))
+
 		// Prune expired instance snapshots and take snapshot of instances (minutely check of configurable cron expression)
 		d.tasks.Add(pruneExpiredAndAutoCreateInstanceSnapshotsTask(d))
 
 		// Prune expired custom volume snapshots and take snapshots of custom volumes (minutely check of configurable cron expression)
 		d.tasks.Add(pruneExpiredAndAutoCreateCustomVolumeSnapshotsTask(d))
 
+		// Take scheduled backups of instances and custom volumes (minutely check of configurable cron expression)
+		d.tasks.Add(autoCreateBackupsTask(d))
+
+		// Run scheduled storage pool integrity checks (minutely check of configurable cron expression)
+		d.tasks.Add(autoCheckStoragePoolsTask(d))
+
 		// Remove resolved warnings (daily)
 		d.tasks.Add(pruneResolvedWarningsTask(d))
 
@@ -1687,6 +1701,12 @@ func (d *Daemon) init() error {
 
 		// Remove expired tokens (hourly)
 		d.tasks.Add(autoRemoveExpiredTokensTask(d))
+
+		// Run instance health checks (every 5s check of configurable interval)
+		d.tasks.Add(instanceHealthCheckTask(d))
+
+		// Resize the memory balloons of VMs (every 10s)
+		d.tasks.Add(instanceBalloonTask(d))
 	}
 
 	// Start all background tasks
@@ -128,6 +128,23 @@ var devIncusMetadataGet = devIncusHandler{"/1.0/meta-data", func(d *Daemon, inst
 	return response.DevIncusResponse(http.StatusOK, fmt.Sprintf("#cloud-config\ninstance-id: %s\nlocal-hostname: %s\n%s", inst.CloudInitID(), inst.Name(), value), "raw", inst.Type() == instancetype.VM)
 }}
 
+var devIncusIgnitionGet = devIncusHandler{"/1.0/ignition", func(d *Daemon, inst instance.Instance, w http.ResponseWriter, r *http.Request) response.Response {
+	if util.IsFalse(inst.ExpandedConfig()["security.guestapi"]) {
+		return response.DevIncusErrorResponse(api.StatusErrorf(http.StatusForbidden, "not authorized"), inst.Type() == instancetype.VM)
+	}
+
+	value, err := instance.IgnitionConfig(inst)
+	if err != nil {
+		return response.DevIncusErrorResponse(api.StatusErrorf(http.StatusInternalServerError, "%v", err), inst.Type() == instancetype.VM)
+	}
+
+	if value == "" {
+		return response.DevIncusErrorResponse(api.StatusErrorf(http.StatusNotFound, "not found"), inst.Type() == instancetype.VM)
+	}
+
+	return response.DevIncusResponse(http.StatusOK, value, "raw", inst.Type() == instancetype.VM)
+}}
+
 var devIncusEventsGet = devIncusHandler{"/1.0/events", func(d *Daemon, c instance.Instance, w http.ResponseWriter, r *http.Request) response.Response {
 	if util.IsFalse(c.ExpandedConfig()["security.guestapi"]) {
 		return response.DevIncusErrorResponse(api.StatusErrorf(http.StatusForbidden, "not authorized"), c.Type() == instancetype.VM)
@@ -271,6 +288,7 @@ var handlers = []devIncusHandler{
 	devIncusConfigGet,
 	devIncusConfigKeyGet,
 	devIncusMetadataGet,
+	devIncusIgnitionGet,
 	devIncusEventsGet,
 	devIncusImageExport,
 	devIncusDevicesGet,
@@ -645,11 +645,45 @@ func pruneExpiredInstanceSnapshots(ctx context.Context, snapshots []instance.Ins
 	return nil
 }
 
+// retentionPrunedInstanceSnapshots returns the snapshots of the instance which aren't kept by its snapshots.retention policy.
+// Only the snapshots named after snapshots.pattern are considered, so that explicitly named snapshots are never deleted.
+func retentionPrunedInstanceSnapshots(inst instance.Instance) ([]instance.Instance, error) {
+	retention, err := internalInstance.ParseSnapshotRetention(inst.ExpandedConfig()["snapshots.retention"])
+	if err != nil {
+		return nil, err
+	}
+
+	allSnapshots, err := inst.Snapshots()
+	if err != nil {
+		return nil, err
+	}
+
+	pattern := inst.ExpandedConfig()["snapshots.pattern"]
+	snapshots := make([]instance.Instance, 0, len(allSnapshots))
+	creationDates := make([]time.Time, 0, len(allSnapshots))
+	for _, snapshot := range allSnapshots {
+		_, snapName, _ := api.GetParentAndSnapshotName(snapshot.Name())
+		if !internalInstance.IsSnapshotNameFromPattern(pattern, snapName, snapshot.CreationDate()) {
+			continue
+		}
+
+		snapshots = append(snapshots, snapshot)
+		creationDates = append(creationDates, snapshot.CreationDate())
+	}
+
+	pruned := []instance.Instance{}
+	for _, i := range retention.Prune(creationDates) {
+		pruned = append(pruned, snapshots[i])
+	}
+
+	return pruned, nil
+}
+
 func pruneExpiredAndAutoCreateInstanceSnapshotsTask(d *Daemon) (task.Func, task.Schedule) {
 	// `f` creates new scheduled instance snapshots and then, prune the expired ones
 	f := func(ctx context.Context) {
 		s := d.State()
-		var instances, expiredSnapshotInstances []instance.Instance
+		var instances, expiredSnapshotInstances, retentionInstances []instance.Instance
 
 		// Get list of expired instance snapshots for this local member.
 		err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
@@ -727,14 +761,19 @@ func pruneExpiredAndAutoCreateInstanceSnapshotsTask(d *Daemon) (task.Func, task.
 
 		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
 			return tx.InstanceList(ctx, func(dbInst db.InstanceArgs, p api.Project) error {
-				err = project.AllowSnapshotCreation(&p)
+				inst, err := instance.Load(s, dbInst, p)
 				if err != nil {
-					return nil
+					return fmt.Errorf("Failed loading instance %q (project %q) for snapshot task: %w", dbInst.Name, dbInst.Project, err)
 				}
 
-				inst, err := instance.Load(s, dbInst, p)
+				// Check if instance has a snapshot retention policy.
+				if inst.ExpandedConfig()["snapshots.retention"] != "" {
+					retentionInstances = append(retentionInstances, inst)
+				}
+
+				err = project.AllowSnapshotCreation(&p)
 				if err != nil {
-					return fmt.Errorf("Failed loading instance %q (project %q) for snapshot task: %w", dbInst.Name, dbInst.Project, err)
+					return nil
 				}
 
 				// Check if instance has snapshot schedule enabled.
@@ -764,6 +803,30 @@ func pruneExpiredAndAutoCreateInstanceSnapshotsTask(d *Daemon) (task.Func, task.
 			return
 		}
 
+		// Add the snapshots not kept by the retention policies to the expired ones.
+		expiredSnapshotIDs := make(map[int]bool, len(expiredSnapshotInstances))
+		for _, snapshot := range expiredSnapshotInstances {
+			expiredSnapshotIDs[snapshot.ID()] = true
+		}
+
+		for _, inst := range retentionInstances {
+			snapshots, err := retentionPrunedInstanceSnapshots(inst)
+			if err != nil {
+				logger.Error("Failed applying instance snapshot retention policy", logger.Ctx{"instance": inst.Name(), "project": inst.Project().Name, "err": err})
+				continue
+			}
+
+			for _, snapshot := range snapshots {
+				if expiredSnapshotIDs[snapshot.ID()] {
+					continue
+				}
+
+				logger.Debug("Scheduling instance snapshot retention pruning", logger.Ctx{"instance": snapshot.Name(), "project": snapshot.Project().Name})
+				expiredSnapshotIDs[snapshot.ID()] = true
+				expiredSnapshotInstances = append(expiredSnapshotInstances, snapshot)
+			}
+		}
+
 		// Handle snapshot expiry first before creating new ones to reduce the chances of running out of
 		// disk space.
 		if len(expiredSnapshotInstances) > 0 {
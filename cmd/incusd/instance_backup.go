@@ -16,6 +16,7 @@ import (
 	"github.com/lxc/incus/v7/internal/server/db"
 	"github.com/lxc/incus/v7/internal/server/db/operationtype"
 	"github.com/lxc/incus/v7/internal/server/instance"
+	"github.com/lxc/incus/v7/internal/server/instance/instancetype"
 	"github.com/lxc/incus/v7/internal/server/lifecycle"
 	"github.com/lxc/incus/v7/internal/server/operations"
 	"github.com/lxc/incus/v7/internal/server/project"
@@ -315,6 +316,36 @@ func instanceBackupsPost(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
+	if req.Repository != "" {
+		if direct || req.Target != nil || req.Name != "" {
+			return response.BadRequest(errors.New("Backups stored in a backup repository can't be named, streamed or uploaded"))
+		}
+
+		err = internalInstance.ValidateBackupRepository(req.Repository)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+
+		if inst.Type() != instancetype.VM {
+			return response.BadRequest(errors.New("Backup repositories are only supported for virtual machines"))
+		}
+
+		run := func(op *operations.Operation) error {
+			_, err := backupRepositoryInstance(s, inst, req.Repository, op)
+			return err
+		}
+
+		resources := map[string][]api.URL{}
+		resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", name)}
+
+		op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.BackupCreate, resources, nil, run, nil, nil, r)
+		if err != nil {
+			return response.InternalError(err)
+		}
+
+		return operations.OperationResponse(op)
+	}
+
 	var reader *io.PipeReader
 	var writer *io.PipeWriter
 	var fullName string
@@ -337,29 +368,12 @@ func instanceBackupsPost(d *Daemon, r *http.Request) response.Response {
 				return response.BadRequest(err)
 			}
 
-			base := name + internalInstance.SnapshotDelimiter + "backup"
-			length := len(base)
-			backupID := 0
-
+			backupNames := make([]string, 0, len(backups))
 			for _, backup := range backups {
-				// Ignore backups not containing base.
-				if !strings.HasPrefix(backup.Name(), base) {
-					continue
-				}
-
-				substr := backup.Name()[length:]
-				var num int
-				count, err := fmt.Sscanf(substr, "%d", &num)
-				if err != nil || count != 1 {
-					continue
-				}
-
-				if num >= backupID {
-					backupID = num + 1
-				}
+				backupNames = append(backupNames, backup.Name())
 			}
 
-			req.Name = fmt.Sprintf("backup%d", backupID)
+			req.Name = backupNextName(name, "backup", backupNames)
 		}
 
 		// Validate the name.
@@ -414,6 +428,8 @@ func instanceBackupsPost(d *Daemon, r *http.Request) response.Response {
 			return err
 		}
 
+		s.Events.SendLifecycle(projectName, lifecycle.InstanceBackupCreated.Event(args.Name, inst, nil))
+
 		return nil
 	}
 
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/lxc/incus/v7/internal/linux"
+	"github.com/lxc/incus/v7/internal/server/instance"
+	instanceDrivers "github.com/lxc/incus/v7/internal/server/instance/drivers"
+	"github.com/lxc/incus/v7/internal/server/task"
+	"github.com/lxc/incus/v7/shared/logger"
+)
+
+// instanceBalloonPressure is the percentage of the host memory left available below which the host is considered
+// under memory pressure, so that memory gets reclaimed from the VMs.
+const instanceBalloonPressure = 10
+
+// instanceBalloonTask resizes the memory balloons of the local running VMs that have "limits.memory.balloon" enabled.
+// Only those VMs are loaded, as tracked when they start, stop or change.
+func instanceBalloonTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		ids := instanceDrivers.RunningWith(instanceDrivers.RunningWithMemoryBalloon)
+		if len(ids) == 0 {
+			return
+		}
+
+		total, err := linux.DeviceTotalMemory()
+		if err != nil {
+			logger.Error("Failed getting host memory for memory balloons", logger.Ctx{"err": err})
+			return
+		}
+
+		available, err := linux.GetMeminfo("MemAvailable")
+		if err != nil {
+			logger.Error("Failed getting host available memory for memory balloons", logger.Ctx{"err": err})
+			return
+		}
+
+		reclaim := available < total/100*instanceBalloonPressure
+
+		for _, id := range ids {
+			inst, err := instance.LoadByID(s, id)
+			if err != nil {
+				logger.Warn("Failed loading instance for memory balloon", logger.Ctx{"id": id, "err": err})
+				continue
+			}
+
+			vm, ok := inst.(instance.VM)
+			if !ok {
+				continue
+			}
+
+			err = vm.UpdateMemoryBalloon(reclaim)
+			if err != nil {
+				logger.Warn("Failed updating memory balloon", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+			}
+		}
+	}
+
+	return f, task.Every(10 * time.Second)
+}
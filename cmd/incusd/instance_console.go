@@ -2,10 +2,12 @@ package main
 
 import (
 	"bytes"
+	"crypto/rand"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"net/http"
 	"os"
 	"path"
@@ -69,8 +71,11 @@ type consoleWs struct {
 	// terminal height
 	height int
 
-	// channel type (either console or vga)
+	// channel type (either console, vga or vnc)
 	protocol string
+
+	// VNC password of the session (vnc only)
+	password string
 }
 
 func (s *consoleWs) metadata() any {
@@ -83,6 +88,10 @@ func (s *consoleWs) metadata() any {
 		}
 	}
 
+	if s.protocol == instance.ConsoleTypeVNC {
+		return jmap.Map{"fds": fds, "password": s.password}
+	}
+
 	return jmap.Map{"fds": fds}
 }
 
@@ -95,7 +104,7 @@ func (s *consoleWs) connect(op *operations.Operation, r *http.Request, w http.Re
 	switch s.protocol {
 	case instance.ConsoleTypeConsole:
 		return s.connectConsole(r, w)
-	case instance.ConsoleTypeVGA:
+	case instance.ConsoleTypeVGA, instance.ConsoleTypeVNC:
 		return s.connectVGA(r, w)
 	default:
 		return fmt.Errorf("Unknown protocol %q", s.protocol)
@@ -178,7 +187,7 @@ func (s *consoleWs) connectVGA(r *http.Request, w http.ResponseWriter) error {
 
 		logger.Debug("VGA dynamic websocket connected")
 
-		console, _, err := s.instance.Console("vga")
+		console, _, err := s.instance.Console(s.protocol)
 		if err != nil {
 			_ = conn.Close()
 			return err
@@ -217,7 +226,7 @@ func (s *consoleWs) do(op *operations.Operation) error {
 	switch s.protocol {
 	case instance.ConsoleTypeConsole:
 		return s.doConsole()
-	case instance.ConsoleTypeVGA:
+	case instance.ConsoleTypeVGA, instance.ConsoleTypeVNC:
 		return s.doVGA()
 	default:
 		return fmt.Errorf("Unknown protocol %q", s.protocol)
@@ -411,9 +420,106 @@ func (s *consoleWs) doVGA() error {
 	// Indicate to the control socket go routine to end if not already.
 	close(s.controlConnected)
 
+	// Prevent reusing the VNC password of the session.
+	if s.protocol == instance.ConsoleTypeVNC {
+		vncErr := consoleEndVNCSession(s.instance, s.password)
+		if vncErr != nil {
+			logger.Warn("Failed resetting VNC password", logger.Ctx{"project": s.instance.Project().Name, "instance": s.instance.Name(), "err": vncErr})
+		}
+	}
+
+	return err
+}
+
+// Track the VNC password of the current console session of the local VMs, by instance ID.
+var (
+	consoleVNCPasswords   = map[int]string{}
+	muConsoleVNCPasswords sync.Mutex
+)
+
+// consoleVNCPassword returns a random VNC password. VNC passwords are limited to 8 characters, so they're
+// made of any printable ASCII character rather than only hexadecimal ones.
+func consoleVNCPassword() (string, error) {
+	const first, last = '!', '~'
+
+	password := make([]byte, 8)
+	for i := range password {
+		n, err := rand.Int(rand.Reader, big.NewInt(last-first+1))
+		if err != nil {
+			return "", err
+		}
+
+		password[i] = byte(first + n.Int64())
+	}
+
+	return string(password), nil
+}
+
+// consoleSetVNCPassword sets a new random password for the VNC console of the VM and returns it.
+func consoleSetVNCPassword(inst instance.Instance) (string, error) {
+	vm, ok := inst.(instance.VM)
+	if !ok {
+		return "", errors.New("VNC console is only supported by virtual machines")
+	}
+
+	password, err := consoleVNCPassword()
+	if err != nil {
+		return "", err
+	}
+
+	err = vm.SetVNCPassword(password)
+	if err != nil {
+		return "", err
+	}
+
+	return password, nil
+}
+
+// consoleStartVNCSession sets a new password for the VNC console of the VM, taking over the console from the
+// previous session, and returns it.
+func consoleStartVNCSession(inst instance.Instance) (string, error) {
+	muConsoleVNCPasswords.Lock()
+	defer muConsoleVNCPasswords.Unlock()
+
+	password, err := consoleSetVNCPassword(inst)
+	if err != nil {
+		return "", err
+	}
+
+	consoleVNCPasswords[inst.ID()] = password
+
+	return password, nil
+}
+
+// consoleEndVNCSession replaces the VNC password of the session by one which isn't handed out. Nothing is done
+// if another session took over the console since, so that its password stays valid.
+func consoleEndVNCSession(inst instance.Instance, password string) error {
+	muConsoleVNCPasswords.Lock()
+	defer muConsoleVNCPasswords.Unlock()
+
+	if consoleVNCPasswords[inst.ID()] != password {
+		return nil
+	}
+
+	delete(consoleVNCPasswords, inst.ID())
+
+	if !inst.IsRunning() {
+		return nil
+	}
+
+	_, err := consoleSetVNCPassword(inst)
+
 	return err
 }
 
+// consoleForgetVNCSession forgets about the VNC console session of a deleted instance.
+func consoleForgetVNCSession(instID int) {
+	muConsoleVNCPasswords.Lock()
+	defer muConsoleVNCPasswords.Unlock()
+
+	delete(consoleVNCPasswords, instID)
+}
+
 // Cancel is responsible for closing websocket connections.
 func (s *consoleWs) cancel(*operations.Operation) error {
 	s.connsLock.Lock()
@@ -527,7 +633,7 @@ func instanceConsolePost(d *Daemon, r *http.Request) response.Response {
 	}
 
 	// Basic parameter validation.
-	if !slices.Contains([]string{instance.ConsoleTypeConsole, instance.ConsoleTypeVGA}, post.Type) {
+	if !slices.Contains([]string{instance.ConsoleTypeConsole, instance.ConsoleTypeVGA, instance.ConsoleTypeVNC}, post.Type) {
 		return response.BadRequest(fmt.Errorf("Unknown console type %q", post.Type))
 	}
 
@@ -540,6 +646,14 @@ func instanceConsolePost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(errors.New("VGA console is only supported by virtual machines"))
 	}
 
+	if post.Type == instance.ConsoleTypeVNC && inst.Type() != instancetype.VM {
+		return response.BadRequest(errors.New("VNC console is only supported by virtual machines"))
+	}
+
+	if post.Type == instance.ConsoleTypeVNC && util.IsFalseOrEmpty(inst.ExpandedConfig()["security.vnc"]) {
+		return response.BadRequest(errors.New("VNC console requires security.vnc to be enabled"))
+	}
+
 	if !inst.IsRunning() {
 		return response.BadRequest(errors.New("Instance is not running"))
 	}
@@ -603,6 +717,14 @@ func instanceConsolePost(d *Daemon, r *http.Request) response.Response {
 	consoleWS.height = post.Height
 	consoleWS.protocol = post.Type
 
+	// Each VNC console session gets its own password.
+	if post.Type == instance.ConsoleTypeVNC {
+		consoleWS.password, err = consoleStartVNCSession(inst)
+		if err != nil {
+			return response.SmartError(fmt.Errorf("Failed setting VNC password: %w", err))
+		}
+	}
+
 	resources := map[string][]api.URL{}
 	resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", consoleWS.instance.Name())}
 
@@ -86,7 +86,15 @@ func instanceDelete(d *Daemon, r *http.Request) response.Response {
 
 	run := func(op *operations.Operation) error {
 		inst.SetOperation(op)
-		return inst.Delete(false, true)
+
+		err := inst.Delete(false, true)
+		if err != nil {
+			return err
+		}
+
+		consoleForgetVNCSession(inst.ID())
+
+		return nil
 	}
 
 	resources := map[string][]api.URL{}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	internalInstance "github.com/lxc/incus/v7/internal/instance"
+	"github.com/lxc/incus/v7/internal/server/cluster"
+	"github.com/lxc/incus/v7/internal/server/instance"
+	"github.com/lxc/incus/v7/internal/server/project"
+	"github.com/lxc/incus/v7/internal/server/state"
+	"github.com/lxc/incus/v7/shared/api"
+	"github.com/lxc/incus/v7/shared/util"
+)
+
+// instanceDependsDefaultTimeout is the default time (in seconds) to wait for the dependencies of an instance.
+const instanceDependsDefaultTimeout = 120
+
+// instanceDependsAutoStarting returns whether a stopped dependency of an instance being auto-started is being
+// auto-started too. The local instances were already started after the ones they depend on, so only the instances
+// of other cluster members which get auto-started there are waited for.
+func instanceDependsAutoStarting(s *state.State) func(dependency instance.Instance) bool {
+	return func(dependency instance.Instance) bool {
+		if !s.ServerClustered || dependency.Location() == s.ServerName {
+			return false
+		}
+
+		return instanceShouldAutoStart(dependency)
+	}
+}
+
+// instancesDependsLevels returns the start level of each instance from the "boot.depends" config of the instances,
+// keyed by project and instance name. Instances are started after the ones at a lower level.
+// If reverse is true, the levels are those to stop the instances in, before the ones they depend on.
+func instancesDependsLevels(instances []instance.Instance, reverse bool) map[string]int {
+	depends := make(map[string][]string, len(instances))
+
+	for _, inst := range instances {
+		key := project.Instance(inst.Project().Name, inst.Name())
+		depends[key] = nil
+
+		for _, name := range internalInstance.DependsList(inst.ExpandedConfig()) {
+			depends[key] = append(depends[key], project.Instance(inst.Project().Name, name))
+		}
+	}
+
+	if reverse {
+		depends = internalInstance.DependsReverse(depends)
+	}
+
+	return internalInstance.DependsLevels(depends)
+}
+
+// instanceDependsState returns whether an instance is running and whether it meets the condition.
+func instanceDependsState(s *state.State, inst instance.Instance, condition string) (bool, bool, error) {
+	projectName := inst.Project().Name
+	name := inst.Name()
+
+	// The ready state is stored in the database so it's available for instances on other members too.
+	ready := util.IsTrue(inst.LocalConfig()["volatile.last_state.ready"])
+
+	var instState *api.InstanceState
+
+	client, err := cluster.ConnectIfInstanceIsRemote(s, projectName, name, nil)
+	if err != nil {
+		return false, false, err
+	}
+
+	if client != nil {
+		instState, _, err = client.UseProject(projectName).GetInstanceState(name)
+		if err != nil {
+			return false, false, err
+		}
+	} else {
+		if !inst.IsRunning() {
+			return false, false, nil
+		}
+
+		instState = &api.InstanceState{StatusCode: api.Running}
+		if condition == "healthy" {
+			instState, err = inst.RenderState(nil)
+			if err != nil {
+				return false, false, err
+			}
+		}
+	}
+
+	if instState.StatusCode != api.Running && instState.StatusCode != api.Ready {
+		return false, false, nil
+	}
+
+	switch condition {
+	case "ready":
+		return true, ready, nil
+	case "healthy":
+		// Instances without a health check only need to be running.
+		return true, instState.Health == nil || instState.Health.Status == "healthy", nil
+	}
+
+	return true, true, nil
+}
+
+// instanceWaitDepends waits for the instances listed in the "boot.depends" config of the instance to be running
+// and to meet the "boot.depends.condition". Stopped dependencies are only waited for if waitStopped returns true
+// for them, for when they're being started at the same time as the instance. Otherwise, or if waitStopped is nil,
+// they fail the check right away.
+func instanceWaitDepends(ctx context.Context, s *state.State, inst instance.Instance, waitStopped func(dependency instance.Instance) bool) error {
+	depends := internalInstance.DependsList(inst.ExpandedConfig())
+	if len(depends) == 0 {
+		return nil
+	}
+
+	condition := inst.ExpandedConfig()["boot.depends.condition"]
+	if condition == "" {
+		condition = "running"
+	}
+
+	timeout := instanceDependsDefaultTimeout
+	if inst.ExpandedConfig()["boot.depends.timeout"] != "" {
+		value, err := strconv.Atoi(inst.ExpandedConfig()["boot.depends.timeout"])
+		if err != nil {
+			return fmt.Errorf("Invalid boot.depends.timeout: %w", err)
+		}
+
+		timeout = value
+	}
+
+	deadline := time.Now().Add(time.Duration(timeout) * time.Second)
+
+	for _, name := range depends {
+		for {
+			dependency, err := instance.LoadByProjectAndName(s, inst.Project().Name, name)
+			if err != nil {
+				return fmt.Errorf("Failed loading dependency %q of instance %q: %w", name, inst.Name(), err)
+			}
+
+			running, ok, err := instanceDependsState(s, dependency, condition)
+			if err != nil {
+				return fmt.Errorf("Failed checking dependency %q of instance %q: %w", name, inst.Name(), err)
+			}
+
+			if ok {
+				break
+			}
+
+			if !running && (waitStopped == nil || !waitStopped(dependency)) {
+				return api.StatusErrorf(http.StatusBadRequest, "Dependency %q of instance %q isn't running", name, inst.Name())
+			}
+
+			if time.Now().After(deadline) {
+				return fmt.Errorf("Timed out waiting for dependency %q of instance %q to be %s", name, inst.Name(), condition)
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Second):
+			}
+		}
+	}
+
+	return nil
+}
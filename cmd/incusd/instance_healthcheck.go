@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lxc/incus/v7/internal/server/db/operationtype"
+	"github.com/lxc/incus/v7/internal/server/instance"
+	instanceDrivers "github.com/lxc/incus/v7/internal/server/instance/drivers"
+	"github.com/lxc/incus/v7/internal/server/lifecycle"
+	"github.com/lxc/incus/v7/internal/server/operations"
+	"github.com/lxc/incus/v7/internal/server/state"
+	"github.com/lxc/incus/v7/internal/server/task"
+	"github.com/lxc/incus/v7/internal/version"
+	"github.com/lxc/incus/v7/shared/api"
+	"github.com/lxc/incus/v7/shared/logger"
+)
+
+// Track the health checks of the local instances.
+var (
+	instanceHealthChecks   = map[int]*instanceHealthCheckState{}
+	muInstanceHealthChecks sync.Mutex
+)
+
+// instanceHealthCheckState represents the scheduling state of the health check of an instance.
+type instanceHealthCheckState struct {
+	lastCheck time.Time
+	interval  time.Duration
+	running   bool
+	status    string
+}
+
+// instanceHealthCheckInterval returns the interval between two health checks of the instance.
+func instanceHealthCheckInterval(inst instance.Instance) time.Duration {
+	value, err := strconv.Atoi(inst.ExpandedConfig()["healthcheck.interval"])
+	if err != nil {
+		return 30 * time.Second
+	}
+
+	return time.Duration(value) * time.Second
+}
+
+// instanceHealthCheckTask runs the health checks of the local running instances when they're due.
+// Only the instances with a health check are loaded, and only when their check is due.
+func instanceHealthCheckTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		muInstanceHealthChecks.Lock()
+		defer muInstanceHealthChecks.Unlock()
+
+		checked := map[int]bool{}
+		for _, id := range instanceDrivers.RunningWith(instanceDrivers.RunningWithHealthCheck) {
+			checked[id] = true
+
+			check, ok := instanceHealthChecks[id]
+			if ok && (check.running || time.Since(check.lastCheck) < check.interval) {
+				continue
+			}
+
+			inst, err := instance.LoadByID(s, id)
+			if err != nil {
+				logger.Error("Failed loading instance for health check", logger.Ctx{"id": id, "err": err})
+				continue
+			}
+
+			// Give the instance a full interval before its first check.
+			if !ok {
+				instanceHealthChecks[id] = &instanceHealthCheckState{lastCheck: time.Now(), interval: instanceHealthCheckInterval(inst), status: "starting"}
+				continue
+			}
+
+			// Pick up changes to the interval.
+			check.interval = instanceHealthCheckInterval(inst)
+			if time.Since(check.lastCheck) < check.interval {
+				continue
+			}
+
+			check.running = true
+			check.lastCheck = time.Now()
+
+			go instanceHealthCheck(ctx, s, inst, check)
+		}
+
+		// Forget the instances that aren't checked anymore.
+		for id, check := range instanceHealthChecks {
+			if !checked[id] && !check.running {
+				delete(instanceHealthChecks, id)
+			}
+		}
+	}
+
+	return f, task.Every(5 * time.Second)
+}
+
+// instanceHealthCheck runs the health check of the instance and applies its action when it becomes unhealthy.
+func instanceHealthCheck(ctx context.Context, s *state.State, inst instance.Instance, check *instanceHealthCheckState) {
+	l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+	health, err := inst.HealthCheck()
+
+	muInstanceHealthChecks.Lock()
+	check.running = false
+	previous := check.status
+	if err == nil {
+		check.status = health.Status
+	}
+
+	muInstanceHealthChecks.Unlock()
+
+	if err != nil {
+		if !errors.Is(err, instanceDrivers.ErrInstanceIsStopped) {
+			l.Warn("Failed running instance health check", logger.Ctx{"err": err})
+		}
+
+		return
+	}
+
+	if health.Status == previous || health.Status == "starting" {
+		return
+	}
+
+	ctxMap := map[string]any{
+		"status":   health.Status,
+		"previous": previous,
+	}
+
+	action := ""
+	if health.Status == "unhealthy" {
+		action = inst.ExpandedConfig()["healthcheck.action"]
+		if action == "" {
+			action = "event-only"
+		}
+
+		ctxMap["action"] = action
+
+		l.Warn("Instance is unhealthy", logger.Ctx{"failures": health.Failures, "err": health.LastError, "action": action})
+	} else {
+		l.Info("Instance is healthy")
+	}
+
+	s.Events.SendLifecycle(inst.Project().Name, lifecycle.InstanceHealthChanged.Event(inst, ctxMap))
+
+	if action == "" || action == "event-only" {
+		return
+	}
+
+	err = instanceHealthCheckAction(ctx, s, inst, action)
+	if err != nil {
+		l.Error("Failed applying instance health check action", logger.Ctx{"action": action, "err": err})
+	}
+
+	// Apply the action again should the instance still be unhealthy at its next check.
+	muInstanceHealthChecks.Lock()
+	check.status = "starting"
+	muInstanceHealthChecks.Unlock()
+}
+
+// instanceHealthCheckAction applies the health check action to the unhealthy instance.
+func instanceHealthCheckAction(ctx context.Context, s *state.State, inst instance.Instance, action string) error {
+	var opType operationtype.Type
+	var run func(op *operations.Operation) error
+
+	switch action {
+	case "restart":
+		opType = operationtype.InstanceRestart
+		run = func(op *operations.Operation) error {
+			inst.SetOperation(op)
+
+			return doInstanceStatePut(inst, api.InstanceStatePut{Action: "restart", Force: true})
+		}
+
+	case "stop":
+		opType = operationtype.InstanceStop
+		run = func(op *operations.Operation) error {
+			inst.SetOperation(op)
+
+			return instanceShutdownOrForceStop(inst)
+		}
+
+	case "migrate":
+		opType = operationtype.InstanceMigrate
+		run = func(op *operations.Operation) error {
+			return instanceHealthCheckMigrate(ctx, s, inst, op)
+		}
+
+	default:
+		return fmt.Errorf("Unknown health check action %q", action)
+	}
+
+	resources := map[string][]api.URL{}
+	resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", inst.Name())}
+
+	op, err := operations.OperationCreate(s, inst.Project().Name, operations.OperationClassTask, opType, resources, nil, run, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	err = op.Start()
+	if err != nil {
+		return err
+	}
+
+	return op.Wait(ctx)
+}
+
+// instanceHealthCheckMigrate moves the unhealthy instance to another cluster member and starts it there.
+// The instance is restarted in place if there is no other cluster member to move it to.
+func instanceHealthCheckMigrate(ctx context.Context, s *state.State, inst instance.Instance, op *operations.Operation) error {
+	l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+	inst.SetOperation(op)
+
+	if !s.ServerClustered {
+		l.Warn("Server isn't clustered, restarting unhealthy instance in place")
+
+		return doInstanceStatePut(inst, api.InstanceStatePut{Action: "restart", Force: true})
+	}
+
+	sourceMemberInfo, targetMemberInfo, err := evacuateClusterSelectTarget(ctx, s, inst)
+	if err != nil {
+		if api.StatusErrorCheck(err, http.StatusNotFound) {
+			l.Warn("No migration target available, restarting unhealthy instance in place")
+
+			return doInstanceStatePut(inst, api.InstanceStatePut{Action: "restart", Force: true})
+		}
+
+		return err
+	}
+
+	err = instanceShutdownOrForceStop(inst)
+	if err != nil {
+		return err
+	}
+
+	return evacuateMigrateInstance(nil)(ctx, s, inst, sourceMemberInfo, targetMemberInfo, "migrate", true, op)
+}
@@ -249,18 +249,14 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 				}
 			}
 
-			// Storage pool changes require a target flag.
+			// Storage pool changes require either a live move or a target flag.
 			if req.Pool != "" {
 				if inst.Type() != instancetype.VM {
 					return response.BadRequest(errors.New("Live storage pool changes aren't supported for containers"))
 				}
 
-				if !s.ServerClustered {
-					return response.BadRequest(errors.New("Live storage pool changes aren't supported on standalone systems"))
-				}
-
-				if target == "" {
-					return response.BadRequest(errors.New("Live storage pool changes require the VM be moved to another cluster member"))
+				if target == "" && !req.Live {
+					return response.BadRequest(errors.New("Storage pool changes of running VMs require a live move or the VM be moved to another cluster member"))
 				}
 			}
 
@@ -624,6 +620,35 @@ func migrateInstance(ctx context.Context, s *state.State, inst instance.Instance
 		return nil
 	}
 
+	// Handle live storage pool moves of VMs staying on the same server.
+	if req.Pool != "" && req.Live && targetMemberInfo == nil {
+		if req.Project != "" || req.Name != "" {
+			return errors.New("Live storage pool moves can't be combined with project or name changes")
+		}
+
+		if len(req.Config) > 0 || len(req.Devices) > 0 || req.Profiles != nil {
+			return errors.New("Live storage pool moves don't support configuration, device or profile overrides")
+		}
+
+		vm, ok := inst.(instance.VM)
+		if !ok {
+			return errors.New("Live storage pool moves are only supported for virtual machines")
+		}
+
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			_, err := tx.GetStoragePoolID(ctx, req.Pool)
+
+			return err
+		})
+		if response.IsNotFoundError(err) {
+			return fmt.Errorf("Can't find a storage pool '%s' for the instance to use", req.Pool)
+		} else if err != nil {
+			return err
+		}
+
+		return vm.MoveStoragePool(req.Pool)
+	}
+
 	// Save the original value of the "volatile.apply_template" config key,
 	// since we'll want to preserve it in the copied container.
 	instVolatileApplyTemplate := inst.LocalConfig()["volatile.apply_template"]
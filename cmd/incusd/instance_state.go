@@ -204,6 +204,14 @@ func instanceStatePut(d *Daemon, r *http.Request) response.Response {
 	do := func(op *operations.Operation) error {
 		inst.SetOperation(op)
 
+		// Wait for the instances it depends on.
+		if internalInstance.InstanceAction(req.Action) == internalInstance.Start {
+			err := instanceWaitDepends(s.ShutdownCtx, s, inst, nil)
+			if err != nil {
+				return err
+			}
+		}
+
 		return doInstanceStatePut(inst, req)
 	}
 
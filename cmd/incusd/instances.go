@@ -189,6 +189,13 @@ var instanceAccessCmd = APIEndpoint{
 	Get: APIEndpointAction{Handler: instanceAccess, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanView, "name")},
 }
 
+var instanceDeviceCaptureCmd = APIEndpoint{
+	Name: "instanceDeviceCapture",
+	Path: "instances/{name}/devices/{device}/capture",
+
+	Post: APIEndpointAction{Handler: instanceDeviceCapturePost, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanEdit, "name")},
+}
+
 var instanceDebugMemoryCmd = APIEndpoint{
 	Name: "instanceDebugMemory",
 	Path: "instances/{name}/debug/memory",
@@ -260,8 +267,9 @@ func bulkStartInstances(instances []instance.Instance) ([]instance.Instance, []i
 		config := inst.ExpandedConfig()
 		autoStartDelay := config["boot.autostart.delay"]
 		autoStartPriority := config["boot.autostart.priority"]
+		autoStartDepends := config["boot.depends"]
 
-		if autoStartDelay == "" && autoStartPriority == "" {
+		if autoStartDelay == "" && autoStartPriority == "" && autoStartDepends == "" {
 			bulkStart = append(bulkStart, inst)
 		} else {
 			rest = append(rest, inst)
@@ -302,6 +310,24 @@ func instanceStart(s *state.State, inst instance.Instance) error {
 
 	instLogger := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
 
+	recordFailure := func(err error) {
+		warnErr := s.DB.Cluster.Transaction(s.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpsertWarning(ctx, s.ServerName, inst.Project().Name, cluster.TypeInstance, inst.ID(), warningtype.InstanceAutostartFailure, fmt.Sprintf("%v", err))
+		})
+		if warnErr != nil {
+			instLogger.Warn("Failed to create instance autostart failure warning", logger.Ctx{"err": warnErr})
+		}
+
+		instLogger.Error("Failed to auto start instance", logger.Ctx{"err": err})
+	}
+
+	// Wait for the instances it depends on, which may still be starting on other cluster members.
+	err := instanceWaitDepends(s.ShutdownCtx, s, inst, instanceDependsAutoStarting(s))
+	if err != nil {
+		recordFailure(err)
+		return nil
+	}
+
 	// Try to start the instance.
 	attempt := 0
 	for {
@@ -324,15 +350,8 @@ func instanceStart(s *state.State, inst instance.Instance) error {
 			instLogger.Warn("Failed auto start instance attempt", logger.Ctx{"attempt": attempt, "maxAttempts": maxAttempts, "err": err})
 
 			if attempt >= maxAttempts {
-				warnErr := s.DB.Cluster.Transaction(s.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
-					// If unable to start after 3 tries, record a warning.
-					return tx.UpsertWarning(ctx, s.ServerName, inst.Project().Name, cluster.TypeInstance, inst.ID(), warningtype.InstanceAutostartFailure, fmt.Sprintf("%v", err))
-				})
-				if warnErr != nil {
-					instLogger.Warn("Failed to create instance autostart failure warning", logger.Ctx{"err": warnErr})
-				}
-
-				instLogger.Error("Failed to auto start instance", logger.Ctx{"err": err})
+				// If unable to start after 3 tries, record a warning.
+				recordFailure(err)
 
 				break
 			}
@@ -389,9 +408,17 @@ func instancesStart(s *state.State, instances []instance.Instance) {
 
 	_ = group.Wait()
 
-	// Sort based on instance boot priority.
+	// Sort based on instance boot priority, starting instances after the ones they depend on.
 	sort.Sort(instanceAutostartList(sequentialInstances))
 
+	levels := instancesDependsLevels(instances, false)
+	sort.SliceStable(sequentialInstances, func(i int, j int) bool {
+		iLevel := levels[project.Instance(sequentialInstances[i].Project().Name, sequentialInstances[i].Name())]
+		jLevel := levels[project.Instance(sequentialInstances[j].Project().Name, sequentialInstances[j].Name())]
+
+		return iLevel < jLevel
+	})
+
 	for _, inst := range sequentialInstances {
 		_ = instanceStart(s, inst)
 	}
@@ -491,8 +518,18 @@ func instancesOnDisk(s *state.State) ([]instance.Instance, error) {
 }
 
 func instancesShutdown(instances []instance.Instance) {
+	// Sort based on instance stop priority, stopping instances before the ones they depend on.
 	sort.Sort(instanceStopList(instances))
 
+	levels := instancesDependsLevels(instances, true)
+	instanceLevel := func(inst instance.Instance) int {
+		return levels[project.Instance(inst.Project().Name, inst.Name())]
+	}
+
+	sort.SliceStable(instances, func(i int, j int) bool {
+		return instanceLevel(instances[i]) < instanceLevel(instances[j])
+	})
+
 	// Limit shutdown concurrency to number of instances or number of CPU cores (which ever is less).
 	var wg sync.WaitGroup
 	instShutdownCh := make(chan instance.Instance)
@@ -583,6 +620,7 @@ func instancesShutdown(instances []instance.Instance) {
 	}
 
 	var currentBatchPriority int
+	var currentBatchLevel int
 	for i, inst := range instances {
 		// Skip stopped instances.
 		if !inst.IsRunning() {
@@ -590,14 +628,16 @@ func instancesShutdown(instances []instance.Instance) {
 		}
 
 		priority, _ := strconv.Atoi(inst.ExpandedConfig()["boot.stop.priority"])
+		level := instanceLevel(inst)
 
-		// Shutdown instances in priority batches, logging at the start of each batch.
-		if i == 0 || priority != currentBatchPriority {
+		// Shutdown instances in dependency level and priority batches, logging at the start of each batch.
+		if i == 0 || priority != currentBatchPriority || level != currentBatchLevel {
 			currentBatchPriority = priority
+			currentBatchLevel = level
 
-			// Wait for instances with higher priority to finish before starting next batch.
+			// Wait for instances with higher priority or depending on the next ones to finish before starting next batch.
 			wg.Wait()
-			logger.Info("Stopping instances", logger.Ctx{"stopPriority": currentBatchPriority})
+			logger.Info("Stopping instances", logger.Ctx{"stopPriority": currentBatchPriority, "dependsLevel": currentBatchLevel})
 		}
 
 		wg.Add(1)
@@ -15,6 +15,7 @@ import (
 	"github.com/lxc/incus/v7/internal/server/instance"
 	"github.com/lxc/incus/v7/internal/server/instance/instancetype"
 	"github.com/lxc/incus/v7/internal/server/operations"
+	"github.com/lxc/incus/v7/internal/server/project"
 	"github.com/lxc/incus/v7/internal/server/request"
 	"github.com/lxc/incus/v7/internal/server/response"
 	"github.com/lxc/incus/v7/internal/version"
@@ -160,24 +161,54 @@ func instancesPut(d *Daemon, r *http.Request) response.Response {
 		localAction := func(local bool) error {
 			failures := map[string]error{}
 			failuresLock := sync.Mutex{}
-			wgAction := sync.WaitGroup{}
 
-			for _, inst := range instances {
-				wgAction.Add(1)
-				go func(inst instance.Instance) {
-					defer wgAction.Done()
+			// Start instances after the ones they depend on.
+			levels := map[string]int{}
+			if action == internalInstance.Start {
+				levels = instancesDependsLevels(instances, false)
+			}
 
-					inst.SetOperation(op)
-					err := doInstanceStatePut(inst, *req.State)
-					if err != nil {
-						failuresLock.Lock()
-						failures[inst.Name()] = err
-						failuresLock.Unlock()
+			maxLevel := 0
+			for _, level := range levels {
+				maxLevel = max(maxLevel, level)
+			}
+
+			for level := 0; level <= maxLevel; level++ {
+				wgAction := sync.WaitGroup{}
+
+				for _, inst := range instances {
+					if levels[project.Instance(inst.Project().Name, inst.Name())] != level {
+						continue
 					}
-				}(inst)
+
+					wgAction.Add(1)
+					go func(inst instance.Instance) {
+						defer wgAction.Done()
+
+						inst.SetOperation(op)
+
+						var err error
+						if action == internalInstance.Start {
+							// The stopped instances of the project, including on other cluster members, are
+							// being started at the same time.
+							err = instanceWaitDepends(s.ShutdownCtx, s, inst, func(dependency instance.Instance) bool { return true })
+						}
+
+						if err == nil {
+							err = doInstanceStatePut(inst, *req.State)
+						}
+
+						if err != nil {
+							failuresLock.Lock()
+							failures[inst.Name()] = err
+							failuresLock.Unlock()
+						}
+					}(inst)
+				}
+
+				wgAction.Wait()
 			}
 
-			wgAction.Wait()
 			return coalesceErrors(local, failures)
 		}
 
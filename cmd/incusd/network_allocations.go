@@ -28,7 +28,7 @@ var networkAllocationsCmd = APIEndpoint{
 
 // swagger:operation GET /1.0/network-allocations network-allocations network_allocations_get
 //
-//	Get the network allocations in use (`network`, `network-forward` and `load-balancer` and `instance`)
+//	Get the network allocations in use (`network`, `network-forward`, `load-balancer`, `egress-gateway` and `instance`)
 //
 //	Returns a list of network allocations.
 //
@@ -271,6 +271,40 @@ func networkAllocationsGet(d *Daemon, r *http.Request) response.Response {
 					},
 				)
 			}
+
+			var dbEgressGateways []dbCluster.NetworkEgressGateway
+			err = d.db.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+				networkID := n.ID()
+
+				// Get the egress gateways.
+				dbEgressGateways, err = dbCluster.GetNetworkEgressGateways(ctx, tx.Tx(), dbCluster.NetworkEgressGatewayFilter{NetworkID: &networkID})
+				if err != nil {
+					return err
+				}
+
+				return nil
+			})
+			if err != nil {
+				return response.SmartError(fmt.Errorf("Failed getting egress gateways for network %q in project %q: %w", networkName, projectName, err))
+			}
+
+			for _, gateway := range dbEgressGateways {
+				cidrAddr, _, err := ipToCIDR(gateway.Address, netConf)
+				if err != nil {
+					return response.SmartError(err)
+				}
+
+				result = append(
+					result,
+					api.NetworkAllocations{
+						Network: networkName,
+						Address: cidrAddr,
+						UsedBy:  api.NewURL().Path(version.APIVersion, "networks", networkName, "egress-gateways", gateway.Address).Project(projectName).String(),
+						Type:    "network-egress-gateway",
+						NAT:     true, // Egress gateways are the source address of the outbound traffic.
+					},
+				)
+			}
 		}
 	}
 
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/lxc/incus/v7/internal/jmap"
+	"github.com/lxc/incus/v7/internal/server/auth"
+	"github.com/lxc/incus/v7/internal/server/db/operationtype"
+	"github.com/lxc/incus/v7/internal/server/network"
+	"github.com/lxc/incus/v7/internal/server/network/capture"
+	"github.com/lxc/incus/v7/internal/server/operations"
+	"github.com/lxc/incus/v7/internal/server/project"
+	"github.com/lxc/incus/v7/internal/server/request"
+	"github.com/lxc/incus/v7/internal/server/response"
+	internalUtil "github.com/lxc/incus/v7/internal/util"
+	"github.com/lxc/incus/v7/internal/version"
+	"github.com/lxc/incus/v7/shared/api"
+	"github.com/lxc/incus/v7/shared/logger"
+	"github.com/lxc/incus/v7/shared/ws"
+)
+
+var networkCaptureCmd = APIEndpoint{
+	Path: "networks/{networkName}/capture",
+
+	Post: APIEndpointAction{Handler: networkCapturePost, AccessHandler: allowPermission(auth.ObjectTypeNetwork, auth.EntitlementCanEdit, "networkName")},
+}
+
+// captureWs streams a packet capture of an interface to a websocket.
+type captureWs struct {
+	ifName string
+	req    api.NetworkCapturePost
+	secret string
+
+	// websocket connection, set once the client connected
+	conn      *websocket.Conn
+	connLock  sync.Mutex
+	connected chan struct{}
+
+	// context of the capture, cancelled with the operation
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// newCaptureWs validates the capture request and returns a new capture websocket for the interface.
+func newCaptureWs(ifName string, req api.NetworkCapturePost) (*captureWs, error) {
+	if req.Duration < 0 {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Invalid capture duration %d", req.Duration)
+	}
+
+	if req.Packets < 0 {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Invalid capture packets limit %d", req.Packets)
+	}
+
+	_, err := capture.CompileFilter(req.Filter)
+	if err != nil {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Invalid capture filter: %v", err)
+	}
+
+	if !network.InterfaceExists(ifName) {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Interface %q doesn't exist", ifName)
+	}
+
+	secret, err := internalUtil.RandomHexString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &captureWs{
+		ifName:    ifName,
+		req:       req,
+		secret:    secret,
+		connected: make(chan struct{}),
+		ctx:       ctx,
+		cancel:    cancel,
+	}, nil
+}
+
+func (c *captureWs) metadata() any {
+	return jmap.Map{"fds": jmap.Map{"0": c.secret}}
+}
+
+func (c *captureWs) connect(op *operations.Operation, r *http.Request, w http.ResponseWriter) error {
+	// Check that the user connecting is the same who started the capture.
+	if !op.IsSameRequestor(r) {
+		return api.StatusErrorf(http.StatusForbidden, "Requestor mismatch")
+	}
+
+	secret := r.FormValue("secret")
+	if secret == "" {
+		return errors.New("missing secret")
+	}
+
+	// A bad secret is a 403, not a 404, since this operation actually exists.
+	if secret != c.secret {
+		return os.ErrPermission
+	}
+
+	c.connLock.Lock()
+	defer c.connLock.Unlock()
+
+	if c.conn != nil {
+		return api.StatusErrorf(http.StatusConflict, "Capture websocket is already connected")
+	}
+
+	conn, err := ws.Upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return err
+	}
+
+	c.conn = conn
+	close(c.connected)
+
+	return nil
+}
+
+func (c *captureWs) do(op *operations.Operation) error {
+	defer c.cancel()
+
+	// Wait for the client to connect.
+	select {
+	case <-c.connected:
+	case <-c.ctx.Done():
+		return nil
+	}
+
+	defer func() { _ = c.conn.Close() }()
+
+	ctx := c.ctx
+	if c.req.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(c.req.Duration)*time.Second)
+		defer cancel()
+	}
+
+	// Stop capturing when the client disconnects.
+	go func() {
+		for {
+			_, _, err := c.conn.NextReader()
+			if err != nil {
+				c.cancel()
+				return
+			}
+		}
+	}()
+
+	logger.Debug("Packet capture started", logger.Ctx{"interface": c.ifName, "filter": c.req.Filter})
+	defer logger.Debug("Packet capture finished", logger.Ctx{"interface": c.ifName})
+
+	conn := ws.NewWrapper(c.conn)
+
+	err := capture.Capture(ctx, conn, c.ifName, c.req.Filter, c.req.Packets)
+	if err != nil {
+		return err
+	}
+
+	// Send the barrier marking the end of the stream.
+	return conn.Close()
+}
+
+func (c *captureWs) cancelOp(*operations.Operation) error {
+	c.cancel()
+
+	return nil
+}
+
+// swagger:operation POST /1.0/networks/{networkName}/capture networks network_capture_post
+//
+//	Capture packets on a network
+//
+//	Captures the packets going through the network on the server.
+//
+//	The returned operation metadata will contain a websocket streaming the packets in the pcapng format.
+//	Only supported on bridge networks.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: path
+//	    name: networkName
+//	    description: Network name
+//	    type: string
+//	    required: true
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    x-example: default
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    x-example: server01
+//	  - in: body
+//	    name: capture
+//	    description: Capture request
+//	    schema:
+//	      $ref: "#/definitions/NetworkCapturePost"
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkCapturePost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	// If a target was specified, forward the request to the relevant node.
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	networkName, err := pathVar(r, "networkName")
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName, _, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.NetworkCapturePost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	n, err := network.LoadByName(s, projectName, networkName)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	if n.Type() != "bridge" {
+		return response.BadRequest(errors.New("Packet captures are only supported on bridge networks"))
+	}
+
+	captureWS, err := newCaptureWs(n.Name(), req)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	resources := map[string][]api.URL{}
+	resources["networks"] = []api.URL{*api.NewURL().Path(version.APIVersion, "networks", n.Name()).Project(projectName)}
+
+	op, err := operations.OperationCreate(s, projectName, operations.OperationClassWebsocket, operationtype.NetworkCapture, resources, captureWS.metadata(), captureWS.do, captureWS.cancelOp, captureWS.connect, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
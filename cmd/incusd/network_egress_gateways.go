@@ -0,0 +1,804 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/lxc/incus/v7/internal/filter"
+	"github.com/lxc/incus/v7/internal/server/auth"
+	clusterRequest "github.com/lxc/incus/v7/internal/server/cluster/request"
+	"github.com/lxc/incus/v7/internal/server/db"
+	dbCluster "github.com/lxc/incus/v7/internal/server/db/cluster"
+	"github.com/lxc/incus/v7/internal/server/lifecycle"
+	"github.com/lxc/incus/v7/internal/server/network"
+	"github.com/lxc/incus/v7/internal/server/project"
+	"github.com/lxc/incus/v7/internal/server/request"
+	"github.com/lxc/incus/v7/internal/server/response"
+	localUtil "github.com/lxc/incus/v7/internal/server/util"
+	"github.com/lxc/incus/v7/internal/version"
+	"github.com/lxc/incus/v7/shared/api"
+)
+
+var networkEgressGatewaysCmd = APIEndpoint{
+	Path: "networks/{networkName}/egress-gateways",
+
+	Get:  APIEndpointAction{Handler: networkEgressGatewaysGet, AccessHandler: allowPermission(auth.ObjectTypeNetwork, auth.EntitlementCanView, "networkName")},
+	Post: APIEndpointAction{Handler: networkEgressGatewaysPost, AccessHandler: allowPermission(auth.ObjectTypeNetwork, auth.EntitlementCanEdit, "networkName")},
+}
+
+var networkEgressGatewayCmd = APIEndpoint{
+	Path: "networks/{networkName}/egress-gateways/{address}",
+
+	Delete: APIEndpointAction{Handler: networkEgressGatewayDelete, AccessHandler: allowPermission(auth.ObjectTypeNetwork, auth.EntitlementCanEdit, "networkName")},
+	Get:    APIEndpointAction{Handler: networkEgressGatewayGet, AccessHandler: allowPermission(auth.ObjectTypeNetwork, auth.EntitlementCanView, "networkName")},
+	Put:    APIEndpointAction{Handler: networkEgressGatewayPut, AccessHandler: allowPermission(auth.ObjectTypeNetwork, auth.EntitlementCanEdit, "networkName")},
+	Patch:  APIEndpointAction{Handler: networkEgressGatewayPut, AccessHandler: allowPermission(auth.ObjectTypeNetwork, auth.EntitlementCanEdit, "networkName")},
+}
+
+// API endpoints
+
+// swagger:operation GET /1.0/networks/{networkName}/egress-gateways network-egress-gateways network_egress_gateways_get
+//
+//  Get the network egress gateways
+//
+//  Returns a list of network egress gateways (URLs).
+//
+//  ---
+//  produces:
+//    - application/json
+//  parameters:
+//    - in: path
+//      name: networkName
+//      description: Network name
+//      type: string
+//      required: true
+//    - in: query
+//      name: project
+//      description: Project name
+//      type: string
+//      x-example: default
+//    - in: query
+//      name: filter
+//      description: Collection filter
+//      type: string
+//      x-example: default
+//  responses:
+//    "200":
+//      description: API endpoints
+//      schema:
+//        type: object
+//        description: Sync response
+//        properties:
+//          type:
+//            type: string
+//            description: Response type
+//            example: sync
+//          status:
+//            type: string
+//            description: Status description
+//            example: Success
+//          status_code:
+//            type: integer
+//            description: Status code
+//            example: 200
+//          metadata:
+//            type: array
+//            description: List of endpoints
+//            items:
+//              type: string
+//            example:
+//              - /1.0/networks/mybr0/egress-gateways/192.0.2.1
+//              - /1.0/networks/mybr0/egress-gateways/192.0.2.2
+//    "400":
+//      $ref: "#/responses/BadRequest"
+//    "403":
+//      $ref: "#/responses/Forbidden"
+//    "404":
+//      $ref: "#/responses/NotFound"
+//    "409":
+//      $ref: "#/responses/Conflict"
+//    "500":
+//      $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/networks/{networkName}/egress-gateways?recursion=1 network-egress-gateways network_egress_gateway_get_recursion1
+//
+//  Get the network egress gateways
+//
+//  Returns a list of network egress gateways (structs).
+//
+//  ---
+//  produces:
+//    - application/json
+//  parameters:
+//    - in: path
+//      name: networkName
+//      description: Network name
+//      type: string
+//      required: true
+//    - in: query
+//      name: project
+//      description: Project name
+//      type: string
+//      x-example: default
+//    - in: query
+//      name: filter
+//      description: Collection filter
+//      type: string
+//      x-example: default
+//  responses:
+//    "200":
+//      description: API endpoints
+//      schema:
+//        type: object
+//        description: Sync response
+//        properties:
+//          type:
+//            type: string
+//            description: Response type
+//            example: sync
+//          status:
+//            type: string
+//            description: Status description
+//            example: Success
+//          status_code:
+//            type: integer
+//            description: Status code
+//            example: 200
+//          metadata:
+//            type: array
+//            description: List of network egress gateways
+//            items:
+//              $ref: "#/definitions/NetworkEgressGateway"
+//    "400":
+//      $ref: "#/responses/BadRequest"
+//    "403":
+//      $ref: "#/responses/Forbidden"
+//    "404":
+//      $ref: "#/responses/NotFound"
+//    "409":
+//      $ref: "#/responses/Conflict"
+//    "500":
+//      $ref: "#/responses/InternalServerError"
+
+func networkEgressGatewaysGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName, reqProject, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	networkName, err := pathVar(r, "networkName")
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	n, err := network.LoadByName(s, projectName, networkName)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	// Check if project allows access to network.
+	if !project.NetworkAllowed(reqProject.Config, networkName, n.IsManaged()) {
+		return response.SmartError(api.StatusErrorf(http.StatusNotFound, "Network not found"))
+	}
+
+	if !n.Info().EgressGateways {
+		return response.BadRequest(fmt.Errorf("Network driver %q does not support egress gateways", n.Type()))
+	}
+
+	recursion := localUtil.IsRecursionRequest(r)
+
+	// Parse filter value.
+	filterStr := r.FormValue("filter")
+	clauses, err := filter.Parse(filterStr, filter.QueryOperatorSet())
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Invalid filter: %w", err))
+	}
+
+	mustLoadObjects := recursion || (clauses != nil && len(clauses.Clauses) > 0)
+
+	fullResults := make([]api.NetworkEgressGateway, 0)
+	linkResults := make([]string, 0)
+
+	if mustLoadObjects {
+		var records map[int64]*api.NetworkEgressGateway
+
+		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+			networkID := n.ID()
+
+			// Get the egress gateways.
+			dbGateways, err := dbCluster.GetNetworkEgressGateways(ctx, tx.Tx(), dbCluster.NetworkEgressGatewayFilter{NetworkID: &networkID})
+			if err != nil {
+				return err
+			}
+
+			records = make(map[int64]*api.NetworkEgressGateway)
+
+			for _, gateway := range dbGateways {
+				// Get the full API record.
+				apiGateway, err := gateway.ToAPI(ctx, tx.Tx())
+				if err != nil {
+					return err
+				}
+
+				records[gateway.ID] = apiGateway
+			}
+
+			return nil
+		})
+		if err != nil {
+			return response.SmartError(fmt.Errorf("Failed loading network egress gateways: %w", err))
+		}
+
+		for _, record := range records {
+			if clauses != nil && len(clauses.Clauses) > 0 {
+				match, err := filter.Match(*record, *clauses)
+				if err != nil {
+					return response.SmartError(err)
+				}
+
+				if !match {
+					continue
+				}
+			}
+
+			fullResults = append(fullResults, *record)
+			u := api.NewURL().Path(version.APIVersion, "networks", n.Name(), "egress-gateways", record.Address)
+			linkResults = append(linkResults, u.String())
+		}
+	} else {
+		addresses := []string{}
+
+		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+			networkID := n.ID()
+
+			// Get the egress gateways.
+			dbGateways, err := dbCluster.GetNetworkEgressGateways(ctx, tx.Tx(), dbCluster.NetworkEgressGatewayFilter{
+				NetworkID: &networkID,
+			})
+			if err != nil {
+				return fmt.Errorf("Failed loading network egress gateways: %w", err)
+			}
+
+			for _, gateway := range dbGateways {
+				addresses = append(addresses, gateway.Address)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return response.SmartError(fmt.Errorf("Failed loading network egress gateways: %w", err))
+		}
+
+		for _, address := range addresses {
+			u := api.NewURL().Path(version.APIVersion, "networks", n.Name(), "egress-gateways", address)
+			linkResults = append(linkResults, u.String())
+		}
+	}
+
+	if recursion {
+		return response.SyncResponse(true, fullResults)
+	}
+
+	return response.SyncResponse(true, linkResults)
+}
+
+// swagger:operation POST /1.0/networks/{networkName}/egress-gateways network-egress-gateways network_egress_gateways_post
+//
+//	Add a network egress gateway
+//
+//	Creates a new network egress gateway.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: path
+//	    name: networkName
+//	    description: Network name
+//	    type: string
+//	    required: true
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    x-example: default
+//	  - in: body
+//	    name: egress-gateway
+//	    description: Egress gateway
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/NetworkEgressGatewaysPost"
+//	responses:
+//	  "201":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "409":
+//	    $ref: "#/responses/Conflict"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkEgressGatewaysPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	projectName, reqProject, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Parse the request into a record.
+	req := api.NetworkEgressGatewaysPost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	req.Normalise() // So we handle the request in normalised/canonical form.
+
+	networkName, err := pathVar(r, "networkName")
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	n, err := network.LoadByName(s, projectName, networkName)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	// Check if project allows access to network.
+	if !project.NetworkAllowed(reqProject.Config, networkName, n.IsManaged()) {
+		return response.SmartError(api.StatusErrorf(http.StatusNotFound, "Network not found"))
+	}
+
+	if !n.Info().EgressGateways {
+		return response.BadRequest(fmt.Errorf("Network driver %q does not support egress gateways", n.Type()))
+	}
+
+	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
+
+	err = n.EgressGatewayCreate(req, clientType)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed creating egress gateway: %w", err))
+	}
+
+	lc := lifecycle.NetworkEgressGatewayCreated.Event(n, req.Address, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(projectName, lc)
+
+	return response.SyncResponseLocation(true, nil, lc.Source)
+}
+
+// swagger:operation DELETE /1.0/networks/{networkName}/egress-gateways/{address} network-egress-gateways network_egress_gateway_delete
+//
+//	Delete the network egress gateway
+//
+//	Removes the network egress gateway.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: path
+//	    name: networkName
+//	    description: Network name
+//	    type: string
+//	    required: true
+//	  - in: path
+//	    name: address
+//	    description: Egress gateway address
+//	    type: string
+//	    required: true
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    x-example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "409":
+//	    $ref: "#/responses/Conflict"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkEgressGatewayDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	projectName, reqProject, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	networkName, err := pathVar(r, "networkName")
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	n, err := network.LoadByName(s, projectName, networkName)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	// Check if project allows access to network.
+	if !project.NetworkAllowed(reqProject.Config, networkName, n.IsManaged()) {
+		return response.SmartError(api.StatusErrorf(http.StatusNotFound, "Network not found"))
+	}
+
+	if !n.Info().EgressGateways {
+		return response.BadRequest(fmt.Errorf("Network driver %q does not support egress gateways", n.Type()))
+	}
+
+	address, err := pathVar(r, "address")
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
+
+	err = n.EgressGatewayDelete(address, clientType)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed deleting egress gateway: %w", err))
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.NetworkEgressGatewayDeleted.Event(n, address, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation GET /1.0/networks/{networkName}/egress-gateways/{address} network-egress-gateways network_egress_gateway_get
+//
+//	Get the network egress gateway
+//
+//	Gets a specific network egress gateway.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: path
+//	    name: networkName
+//	    description: Network name
+//	    type: string
+//	    required: true
+//	  - in: path
+//	    name: address
+//	    description: Egress gateway address
+//	    type: string
+//	    required: true
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    x-example: default
+//	responses:
+//	  "200":
+//	    description: Egress gateway
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/NetworkEgressGateway"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "409":
+//	    $ref: "#/responses/Conflict"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkEgressGatewayGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	projectName, reqProject, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	networkName, err := pathVar(r, "networkName")
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	n, err := network.LoadByName(s, projectName, networkName)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	// Check if project allows access to network.
+	if !project.NetworkAllowed(reqProject.Config, networkName, n.IsManaged()) {
+		return response.SmartError(api.StatusErrorf(http.StatusNotFound, "Network not found"))
+	}
+
+	if !n.Info().EgressGateways {
+		return response.BadRequest(fmt.Errorf("Network driver %q does not support egress gateways", n.Type()))
+	}
+
+	address, err := pathVar(r, "address")
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var gateway *api.NetworkEgressGateway
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		networkID := n.ID()
+
+		// Get the egress gateway.
+		dbGateways, err := dbCluster.GetNetworkEgressGateways(ctx, tx.Tx(), dbCluster.NetworkEgressGatewayFilter{
+			NetworkID: &networkID,
+			Address:   &address,
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(dbGateways) != 1 {
+			return api.StatusErrorf(http.StatusNotFound, "Network egress gateway not found")
+		}
+
+		// Get API struct.
+		gateway, err = dbGateways[0].ToAPI(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponseETag(true, gateway, gateway.Etag())
+}
+
+// swagger:operation PATCH /1.0/networks/{networkName}/egress-gateways/{address} network-egress-gateways network_egress_gateway_patch
+//
+//  Partially update the network egress gateway
+//
+//  Updates a subset of the network egress gateway configuration.
+//
+//  ---
+//  consumes:
+//    - application/json
+//  produces:
+//    - application/json
+//  parameters:
+//    - in: path
+//      name: networkName
+//      description: Network name
+//      type: string
+//      required: true
+//    - in: path
+//      name: address
+//      description: Egress gateway address
+//      type: string
+//      required: true
+//    - in: query
+//      name: project
+//      description: Project name
+//      type: string
+//      x-example: default
+//    - in: body
+//      name: egress-gateway
+//      description: Egress gateway configuration
+//      required: true
+//      schema:
+//        $ref: "#/definitions/NetworkEgressGatewayPut"
+//  responses:
+//    "200":
+//      $ref: "#/responses/EmptySyncResponse"
+//    "400":
+//      $ref: "#/responses/BadRequest"
+//    "403":
+//      $ref: "#/responses/Forbidden"
+//    "404":
+//      $ref: "#/responses/NotFound"
+//    "409":
+//      $ref: "#/responses/Conflict"
+//    "412":
+//      $ref: "#/responses/PreconditionFailed"
+//    "500":
+//      $ref: "#/responses/InternalServerError"
+
+// swagger:operation PUT /1.0/networks/{networkName}/egress-gateways/{address} network-egress-gateways network_egress_gateway_put
+//
+//	Update the network egress gateway
+//
+//	Updates the entire network egress gateway configuration.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: path
+//	    name: networkName
+//	    description: Network name
+//	    type: string
+//	    required: true
+//	  - in: path
+//	    name: address
+//	    description: Egress gateway address
+//	    type: string
+//	    required: true
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    x-example: default
+//	  - in: body
+//	    name: egress-gateway
+//	    description: Egress gateway configuration
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/NetworkEgressGatewayPut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "409":
+//	    $ref: "#/responses/Conflict"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkEgressGatewayPut(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	projectName, reqProject, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	networkName, err := pathVar(r, "networkName")
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	n, err := network.LoadByName(s, projectName, networkName)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	// Check if project allows access to network.
+	if !project.NetworkAllowed(reqProject.Config, networkName, n.IsManaged()) {
+		return response.SmartError(api.StatusErrorf(http.StatusNotFound, "Network not found"))
+	}
+
+	if !n.Info().EgressGateways {
+		return response.BadRequest(fmt.Errorf("Network driver %q does not support egress gateways", n.Type()))
+	}
+
+	address, err := pathVar(r, "address")
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Decode the request.
+	req := api.NetworkEgressGatewayPut{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if r.Method == http.MethodPatch {
+		var gateway *api.NetworkEgressGateway
+
+		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+			networkID := n.ID()
+
+			// Get the egress gateway.
+			dbGateways, err := dbCluster.GetNetworkEgressGateways(ctx, tx.Tx(), dbCluster.NetworkEgressGatewayFilter{
+				NetworkID: &networkID,
+				Address:   &address,
+			})
+			if err != nil {
+				return err
+			}
+
+			if len(dbGateways) != 1 {
+				return api.StatusErrorf(http.StatusNotFound, "Network egress gateway not found")
+			}
+
+			// Get the API struct.
+			gateway, err = dbGateways[0].ToAPI(ctx, tx.Tx())
+			if err != nil {
+				return err
+			}
+
+			return nil
+		})
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		// If config being updated via "patch" method, then merge all existing config with the keys that
+		// are present in the request config.
+		for k, v := range gateway.Config {
+			_, ok := req.Config[k]
+			if !ok {
+				req.Config[k] = v
+			}
+		}
+
+		// If egress gateway being updated via "patch" method and selections not specified, then merge
+		// the existing ones into the egress gateway.
+		if req.Instances == nil {
+			req.Instances = gateway.Instances
+		}
+
+		if req.Profiles == nil {
+			req.Profiles = gateway.Profiles
+		}
+
+		if req.Projects == nil {
+			req.Projects = gateway.Projects
+		}
+	}
+
+	req.Normalise() // So we handle the request in normalised/canonical form.
+
+	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
+
+	err = n.EgressGatewayUpdate(address, req, clientType)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed updating egress gateway: %w", err))
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.NetworkEgressGatewayUpdated.Event(n, address, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
@@ -5,14 +5,18 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"strings"
 
+	incus "github.com/lxc/incus/v7/client"
 	"github.com/lxc/incus/v7/internal/filter"
 	"github.com/lxc/incus/v7/internal/server/auth"
+	"github.com/lxc/incus/v7/internal/server/cluster"
 	"github.com/lxc/incus/v7/internal/server/db"
 	dbCluster "github.com/lxc/incus/v7/internal/server/db/cluster"
 	"github.com/lxc/incus/v7/internal/server/lifecycle"
+	"github.com/lxc/incus/v7/internal/server/network"
 	"github.com/lxc/incus/v7/internal/server/project"
 	"github.com/lxc/incus/v7/internal/server/request"
 	"github.com/lxc/incus/v7/internal/server/response"
@@ -312,6 +316,17 @@ func networkIntegrationsPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	if req.Type == "wireguard" {
+		if req.Config == nil {
+			req.Config = map[string]string{}
+		}
+
+		err = networkIntegrationWireGuardKeys(nil, req.Config)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	// Convert the API type to DB type.
 	dbType := -1
 	for k, v := range dbCluster.NetworkIntegrationTypeNames {
@@ -693,6 +708,16 @@ func networkIntegrationPut(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	// Refresh the WireGuard tunnels when notified by the cluster member that updated the integration.
+	if isClusterNotification(r) {
+		err = network.WireGuardApply(s)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		return response.EmptySyncResponse
+	}
+
 	// Get the existing network integration.
 	var dbRecord *dbCluster.NetworkIntegration
 	var info *api.NetworkIntegration
@@ -790,6 +815,13 @@ func networkIntegrationPut(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	if info.Type == "wireguard" {
+		err = networkIntegrationWireGuardKeys(info.Config, req.Config)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	// Update the database record.
 	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
 		// Update the description if needed.
@@ -813,6 +845,26 @@ func networkIntegrationPut(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	// Refresh the WireGuard tunnels using the integration.
+	if info.Type == "wireguard" && len(usedBy) > 0 {
+		err = network.WireGuardApply(s)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAll)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		err = notifier(func(client incus.InstanceServer) error {
+			return client.UpdateNetworkIntegration(integrationName, req, "")
+		})
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	// Emit the lifecycle event.
 	s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.NetworkIntegrationUpdated.Event(integrationName, request.CreateRequestor(r), nil))
 
@@ -902,64 +954,171 @@ func networkIntegrationPost(d *Daemon, r *http.Request) response.Response {
 	return response.SyncResponseLocation(true, nil, lc.Source)
 }
 
-// networkIntegrationValidate validates the configuration keys/values for network integration.
-func networkIntegrationValidate(integrationType string, inUse bool, oldConfig map[string]string, config map[string]string) error {
-	if integrationType != "ovn" {
-		return fmt.Errorf("Invalid integration type %q", integrationType)
+// networkIntegrationWireGuardKeys fills in the keys of the local end of a WireGuard integration.
+// The existing private key is kept unless a new one is provided, and a new one is generated if there's none.
+func networkIntegrationWireGuardKeys(oldConfig map[string]string, config map[string]string) error {
+	if config["wireguard.private_key"] == "" {
+		config["wireguard.private_key"] = oldConfig["wireguard.private_key"]
 	}
 
-	configKeys := map[string]func(value string) error{
-		// gendoc:generate(entity=network_integration, group=ovn, key=ovn.northbound_connection)
-		//
-		// ---
-		//  type: string
-		//  scope: global
-		//  shortdesc: OVN northbound inter-connection connection string
-		"ovn.northbound_connection": validate.IsAny,
+	if config["wireguard.private_key"] == "" {
+		privateKey, err := network.WireGuardGenerateKey()
+		if err != nil {
+			return err
+		}
 
-		// gendoc:generate(entity=network_integration, group=ovn, key=ovn.southbound_connection)
-		//
-		// ---
-		//  type: string
-		//  scope: global
-		//  shortdesc: OVN southbound inter-connection connection string
-		"ovn.southbound_connection": validate.IsAny,
+		config["wireguard.private_key"] = privateKey
+	}
 
-		// gendoc:generate(entity=network_integration, group=ovn, key=ovn.ca_cert)
-		//
-		// ---
-		//  type: string
-		//  scope: global
-		//  shortdesc: OVN SSL certificate authority for the inter-connection database
-		"ovn.ca_cert": validate.Optional(validate.IsAny),
+	publicKey, err := network.WireGuardPublicKey(config["wireguard.private_key"])
+	if err != nil {
+		return err
+	}
 
-		// gendoc:generate(entity=network_integration, group=ovn, key=ovn.client_cert)
-		//
-		// ---
-		//  type: string
-		//  scope: global
-		//  shortdesc: OVN SSL client certificate
-		"ovn.client_cert": validate.Optional(validate.IsAny),
+	config["wireguard.public_key"] = publicKey
 
-		// gendoc:generate(entity=network_integration, group=ovn, key=ovn.client_key)
-		//
-		// ---
-		//  type: string
-		//  scope: global
-		//  shortdesc: OVN SSL client key
-		"ovn.client_key": validate.Optional(validate.IsAny),
-
-		// gendoc:generate(entity=network_integration, group=ovn, key=ovn.transit.pattern)
-		// Specify a Pongo2 template string that represents the transit switch name.
-		// This template gets access to the project name (`projectName`),
-		// integration name (`integrationName`), network name (`networkName`)
-		// and peer name (`peerName`).
-		//
-		// ---
-		//  type: string
-		//  defaultdesc: `ts-incus-{{ integrationName }}-{{ projectName }}-{{ networkName }}`
-		//  shortdesc: Template for the transit switch name
-		"ovn.transit.pattern": validate.IsAny,
+	return nil
+}
+
+// networkIntegrationValidate validates the configuration keys/values for network integration.
+func networkIntegrationValidate(integrationType string, inUse bool, oldConfig map[string]string, config map[string]string) error {
+	var configKeys map[string]func(value string) error
+
+	switch integrationType {
+	case "ovn":
+		configKeys = map[string]func(value string) error{
+			// gendoc:generate(entity=network_integration, group=ovn, key=ovn.northbound_connection)
+			//
+			// ---
+			//  type: string
+			//  scope: global
+			//  shortdesc: OVN northbound inter-connection connection string
+			"ovn.northbound_connection": validate.IsAny,
+
+			// gendoc:generate(entity=network_integration, group=ovn, key=ovn.southbound_connection)
+			//
+			// ---
+			//  type: string
+			//  scope: global
+			//  shortdesc: OVN southbound inter-connection connection string
+			"ovn.southbound_connection": validate.IsAny,
+
+			// gendoc:generate(entity=network_integration, group=ovn, key=ovn.ca_cert)
+			//
+			// ---
+			//  type: string
+			//  scope: global
+			//  shortdesc: OVN SSL certificate authority for the inter-connection database
+			"ovn.ca_cert": validate.Optional(validate.IsAny),
+
+			// gendoc:generate(entity=network_integration, group=ovn, key=ovn.client_cert)
+			//
+			// ---
+			//  type: string
+			//  scope: global
+			//  shortdesc: OVN SSL client certificate
+			"ovn.client_cert": validate.Optional(validate.IsAny),
+
+			// gendoc:generate(entity=network_integration, group=ovn, key=ovn.client_key)
+			//
+			// ---
+			//  type: string
+			//  scope: global
+			//  shortdesc: OVN SSL client key
+			"ovn.client_key": validate.Optional(validate.IsAny),
+
+			// gendoc:generate(entity=network_integration, group=ovn, key=ovn.transit.pattern)
+			// Specify a Pongo2 template string that represents the transit switch name.
+			// This template gets access to the project name (`projectName`),
+			// integration name (`integrationName`), network name (`networkName`)
+			// and peer name (`peerName`).
+			//
+			// ---
+			//  type: string
+			//  defaultdesc: `ts-incus-{{ integrationName }}-{{ projectName }}-{{ networkName }}`
+			//  shortdesc: Template for the transit switch name
+			"ovn.transit.pattern": validate.IsAny,
+		}
+
+	case "wireguard":
+		configKeys = map[string]func(value string) error{
+			// gendoc:generate(entity=network_integration, group=wireguard, key=wireguard.private_key)
+			// A new key is generated if not set.
+			// ---
+			//  type: string
+			//  defaultdesc: generated
+			//  shortdesc: Private key of the local end of the tunnel
+			"wireguard.private_key": validate.Optional(network.WireGuardValidateKey),
+
+			// gendoc:generate(entity=network_integration, group=wireguard, key=wireguard.public_key)
+			// This key is derived from `wireguard.private_key` and must be configured on the remote end of the tunnel.
+			// ---
+			//  type: string
+			//  defaultdesc: generated
+			//  shortdesc: Public key of the local end of the tunnel (read-only)
+			"wireguard.public_key": validate.Optional(network.WireGuardValidateKey),
+
+			// gendoc:generate(entity=network_integration, group=wireguard, key=wireguard.listen_port)
+			// Each WireGuard integration used on a server needs its own port.
+			// ---
+			//  type: integer
+			//  defaultdesc: `51820`
+			//  shortdesc: UDP port the tunnel listens on
+			"wireguard.listen_port": validate.Optional(validate.IsNetworkPort),
+
+			// gendoc:generate(entity=network_integration, group=wireguard, key=wireguard.mtu)
+			//
+			// ---
+			//  type: integer
+			//  defaultdesc: `1420`
+			//  shortdesc: MTU of the tunnel interface
+			"wireguard.mtu": validate.Optional(validate.IsNetworkMTU),
+
+			// gendoc:generate(entity=network_integration, group=wireguard, key=wireguard.member)
+			// In a cluster, the tunnel is only set up on this cluster member.
+			// ---
+			//  type: string
+			//  shortdesc: Cluster member hosting the tunnel
+			"wireguard.member": validate.IsAny,
+
+			// gendoc:generate(entity=network_integration, group=wireguard, key=wireguard.peer.endpoint)
+			// If not set, the remote end must connect first.
+			// ---
+			//  type: string
+			//  shortdesc: Address and port of the remote end of the tunnel (`<host>:<port>`)
+			"wireguard.peer.endpoint": validate.Optional(func(value string) error {
+				_, port, err := net.SplitHostPort(value)
+				if err != nil {
+					return err
+				}
+
+				return validate.IsNetworkPort(port)
+			}),
+
+			// gendoc:generate(entity=network_integration, group=wireguard, key=wireguard.peer.public_key)
+			//
+			// ---
+			//  type: string
+			//  shortdesc: Public key of the remote end of the tunnel
+			"wireguard.peer.public_key": network.WireGuardValidateKey,
+
+			// gendoc:generate(entity=network_integration, group=wireguard, key=wireguard.peer.preshared_key)
+			//
+			// ---
+			//  type: string
+			//  shortdesc: Optional pre-shared key of the tunnel
+			"wireguard.peer.preshared_key": validate.Optional(network.WireGuardValidateKey),
+
+			// gendoc:generate(entity=network_integration, group=wireguard, key=wireguard.peer.persistent_keepalive)
+			// Useful when the local end is behind NAT.
+			// ---
+			//  type: integer
+			//  shortdesc: Interval in seconds between keepalive packets sent to the remote end
+			"wireguard.peer.persistent_keepalive": validate.Optional(validate.IsInRange(1, 65535)),
+		}
+
+	default:
+		return fmt.Errorf("Invalid integration type %q", integrationType)
 	}
 
 	for k, v := range config {
@@ -985,6 +1144,10 @@ func networkIntegrationValidate(integrationType string, inUse bool, oldConfig ma
 		}
 	}
 
+	if integrationType == "wireguard" && config["wireguard.peer.public_key"] == "" {
+		return errors.New("The WireGuard peer public key (wireguard.peer.public_key) is required")
+	}
+
 	if oldConfig != nil && oldConfig["ovn.transit.pattern"] != config["ovn.transit.pattern"] && inUse {
 		return errors.New("The OVN transit switch pattern cannot be changed while the integration is in use")
 	}
@@ -0,0 +1,451 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/lxc/incus/v7/internal/server/auth"
+	clusterRequest "github.com/lxc/incus/v7/internal/server/cluster/request"
+	"github.com/lxc/incus/v7/internal/server/db"
+	dbCluster "github.com/lxc/incus/v7/internal/server/db/cluster"
+	"github.com/lxc/incus/v7/internal/server/lifecycle"
+	"github.com/lxc/incus/v7/internal/server/network"
+	"github.com/lxc/incus/v7/internal/server/project"
+	"github.com/lxc/incus/v7/internal/server/request"
+	"github.com/lxc/incus/v7/internal/server/response"
+	"github.com/lxc/incus/v7/shared/api"
+)
+
+var networkLeaseCmd = APIEndpoint{
+	Path: "networks/{networkName}/leases/{address}",
+
+	Delete: APIEndpointAction{Handler: networkLeaseDelete, AccessHandler: allowPermission(auth.ObjectTypeNetwork, auth.EntitlementCanEdit, "networkName")},
+	Get:    APIEndpointAction{Handler: networkLeaseGet, AccessHandler: allowPermission(auth.ObjectTypeNetwork, auth.EntitlementCanView, "networkName")},
+	Put:    APIEndpointAction{Handler: networkLeasePut, AccessHandler: allowPermission(auth.ObjectTypeNetwork, auth.EntitlementCanEdit, "networkName")},
+	Patch:  APIEndpointAction{Handler: networkLeasePut, AccessHandler: allowPermission(auth.ObjectTypeNetwork, auth.EntitlementCanEdit, "networkName")},
+}
+
+// networkLeaseLoadNetwork loads the network of the request and checks it supports address reservations.
+func networkLeaseLoadNetwork(d *Daemon, r *http.Request) (network.Network, string, error) {
+	s := d.State()
+
+	projectName, reqProject, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return nil, "", err
+	}
+
+	networkName, err := pathVar(r, "networkName")
+	if err != nil {
+		return nil, "", err
+	}
+
+	n, err := network.LoadByName(s, projectName, networkName)
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed loading network: %w", err)
+	}
+
+	// Check if project allows access to network.
+	if !project.NetworkAllowed(reqProject.Config, networkName, n.IsManaged()) {
+		return nil, "", api.StatusErrorf(http.StatusNotFound, "Network not found")
+	}
+
+	if !n.Info().Reservations {
+		return nil, "", api.StatusErrorf(http.StatusBadRequest, "Network driver %q does not support address reservations", n.Type())
+	}
+
+	return n, projectName, nil
+}
+
+// networkLeaseLoad returns the address reservation of the network.
+func networkLeaseLoad(ctx context.Context, s *db.Cluster, n network.Network, address string) (*api.NetworkLease, error) {
+	var lease *api.NetworkLease
+
+	err := s.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		dbRecord, err := dbCluster.GetNetworkLease(ctx, tx.Tx(), n.ID(), address)
+		if err != nil {
+			if api.StatusErrorCheck(err, http.StatusNotFound) {
+				return api.StatusErrorf(http.StatusNotFound, "Network address reservation not found")
+			}
+
+			return err
+		}
+
+		lease = dbRecord.ToAPI()
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return lease, nil
+}
+
+// swagger:operation POST /1.0/networks/{name}/leases networks networks_leases_post
+//
+//	Add a network address reservation
+//
+//	Reserves an address of the network, optionally for a MAC address.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: path
+//	    name: name
+//	    description: Network name
+//	    type: string
+//	    required: true
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    x-example: default
+//	  - in: body
+//	    name: lease
+//	    description: Address reservation
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/NetworkLeasesPost"
+//	responses:
+//	  "201":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "409":
+//	    $ref: "#/responses/Conflict"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkLeasesPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	// Parse the request into a record.
+	req := api.NetworkLeasesPost{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	req.Normalise() // So we handle the request in normalised/canonical form.
+
+	n, projectName, err := networkLeaseLoadNetwork(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
+
+	err = n.LeaseCreate(req, clientType)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed creating address reservation: %w", err))
+	}
+
+	lc := lifecycle.NetworkLeaseCreated.Event(n, req.Address, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(projectName, lc)
+
+	return response.SyncResponseLocation(true, nil, lc.Source)
+}
+
+// swagger:operation DELETE /1.0/networks/{networkName}/leases/{address} networks network_lease_delete
+//
+//	Delete the network address reservation
+//
+//	Removes the network address reservation.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: path
+//	    name: networkName
+//	    description: Network name
+//	    type: string
+//	    required: true
+//	  - in: path
+//	    name: address
+//	    description: Reserved address
+//	    type: string
+//	    required: true
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    x-example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkLeaseDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	n, projectName, err := networkLeaseLoadNetwork(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	address, err := pathVar(r, "address")
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
+
+	err = n.LeaseDelete(address, clientType)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed deleting address reservation: %w", err))
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.NetworkLeaseDeleted.Event(n, address, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation GET /1.0/networks/{networkName}/leases/{address} networks network_lease_get
+//
+//	Get the network address reservation
+//
+//	Gets a specific network address reservation.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: path
+//	    name: networkName
+//	    description: Network name
+//	    type: string
+//	    required: true
+//	  - in: path
+//	    name: address
+//	    description: Reserved address
+//	    type: string
+//	    required: true
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    x-example: default
+//	responses:
+//	  "200":
+//	    description: Address reservation
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/NetworkLease"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkLeaseGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	n, _, err := networkLeaseLoadNetwork(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	address, err := pathVar(r, "address")
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	lease, err := networkLeaseLoad(r.Context(), s.DB.Cluster, n, address)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponseETag(true, lease, lease.Etag())
+}
+
+// swagger:operation PATCH /1.0/networks/{networkName}/leases/{address} networks network_lease_patch
+//
+//  Partially update the network address reservation
+//
+//  Updates a subset of the network address reservation.
+//
+//  ---
+//  consumes:
+//    - application/json
+//  produces:
+//    - application/json
+//  parameters:
+//    - in: path
+//      name: networkName
+//      description: Network name
+//      type: string
+//      required: true
+//    - in: path
+//      name: address
+//      description: Reserved address
+//      type: string
+//      required: true
+//    - in: query
+//      name: project
+//      description: Project name
+//      type: string
+//      x-example: default
+//    - in: body
+//      name: lease
+//      description: Address reservation
+//      required: true
+//      schema:
+//        $ref: "#/definitions/NetworkLeasePut"
+//  responses:
+//    "200":
+//      $ref: "#/responses/EmptySyncResponse"
+//    "400":
+//      $ref: "#/responses/BadRequest"
+//    "403":
+//      $ref: "#/responses/Forbidden"
+//    "404":
+//      $ref: "#/responses/NotFound"
+//    "409":
+//      $ref: "#/responses/Conflict"
+//    "412":
+//      $ref: "#/responses/PreconditionFailed"
+//    "500":
+//      $ref: "#/responses/InternalServerError"
+
+// swagger:operation PUT /1.0/networks/{networkName}/leases/{address} networks network_lease_put
+//
+//	Update the network address reservation
+//
+//	Updates the entire network address reservation.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: path
+//	    name: networkName
+//	    description: Network name
+//	    type: string
+//	    required: true
+//	  - in: path
+//	    name: address
+//	    description: Reserved address
+//	    type: string
+//	    required: true
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    x-example: default
+//	  - in: body
+//	    name: lease
+//	    description: Address reservation
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/NetworkLeasePut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "409":
+//	    $ref: "#/responses/Conflict"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkLeasePut(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	n, projectName, err := networkLeaseLoadNetwork(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	address, err := pathVar(r, "address")
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Decode the request, on top of the current reservation when patching.
+	req := api.NetworkLeasePut{}
+	if r.Method == http.MethodPatch {
+		lease, err := networkLeaseLoad(r.Context(), s.DB.Cluster, n, address)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		req = lease.Writable()
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	req.Normalise() // So we handle the request in normalised/canonical form.
+
+	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
+
+	err = n.LeaseUpdate(address, req, clientType)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed updating address reservation: %w", err))
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.NetworkLeaseUpdated.Event(n, address, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
@@ -108,6 +108,7 @@ var patches = []patch{
 	{name: "network_ovn_l2proxy_arp_proxy", stage: patchPostDaemonStorage, run: patchGenericNetwork(patchNetworkOVNL2ProxyARPProxy)},
 	{name: "auth_openfga_instance_tcp", stage: patchPostNetworks, run: patchGenericAuthorization},
 	{name: "auth_openfga_shared_networks", stage: patchPostNetworks, run: patchGenericAuthorization},
+	{name: "storage_encryption_master_key", stage: patchPreDaemonStorage, run: patchStorageEncryptionMasterKey},
 }
 
 type patchRun func(name string, d *Daemon) error
@@ -2093,3 +2094,45 @@ func patchNetworkOVNL2ProxyARPProxy(_ string, d *Daemon) error {
 }
 
 // Patches end here
+
+// patchStorageEncryptionMasterKey generates the encryption master key of the existing storage pools supporting
+// encrypted volumes.
+func patchStorageEncryptionMasterKey(_ string, d *Daemon) error {
+	encryptionDrivers := storageDrivers.EncryptionDriverNames()
+
+	return d.db.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		pools, err := tx.GetStoragePoolNames(ctx)
+		if err != nil {
+			// Skip the rest of the patch if no storage pools were found.
+			if api.StatusErrorCheck(err, http.StatusNotFound) {
+				return nil
+			}
+
+			return fmt.Errorf("Failed getting storage pool names: %w", err)
+		}
+
+		for _, poolName := range pools {
+			poolID, pool, _, err := tx.GetStoragePoolInAnyState(ctx, poolName)
+			if err != nil {
+				return fmt.Errorf("Failed loading storage pool %q: %w", poolName, err)
+			}
+
+			// The key may already have been generated by another cluster member.
+			if !slices.Contains(encryptionDrivers, pool.Driver) || pool.Config[storageDrivers.EncryptionMasterKeyConfigKey] != "" {
+				continue
+			}
+
+			masterKey, err := storageDrivers.GenerateEncryptionMasterKey()
+			if err != nil {
+				return err
+			}
+
+			err = tx.CreateStoragePoolConfig(poolID, 0, map[string]string{storageDrivers.EncryptionMasterKeyConfigKey: masterKey})
+			if err != nil {
+				return fmt.Errorf("Failed setting encryption master key of storage pool %q: %w", poolName, err)
+			}
+		}
+
+		return nil
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"sort"
 	"strconv"
+	"time"
 
 	"github.com/lxc/incus/v7/internal/filter"
 	internalIO "github.com/lxc/incus/v7/internal/io"
@@ -24,6 +25,8 @@ import (
 	"github.com/lxc/incus/v7/internal/server/response"
 	"github.com/lxc/incus/v7/internal/server/state"
 	storagePools "github.com/lxc/incus/v7/internal/server/storage"
+	"github.com/lxc/incus/v7/internal/server/storage/s3/local"
+	"github.com/lxc/incus/v7/internal/server/task"
 	localUtil "github.com/lxc/incus/v7/internal/server/util"
 	internalUtil "github.com/lxc/incus/v7/internal/util"
 	"github.com/lxc/incus/v7/internal/version"
@@ -1759,3 +1762,108 @@ func createStoragePoolBucketFromBackup(s *state.State, r *http.Request, requestP
 	reverter.Success()
 	return operations.OperationResponse(op)
 }
+
+func applyStorageBucketLifecycleTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		opRun := func(op *operations.Operation) error {
+			return applyStorageBucketLifecycle(ctx, s)
+		}
+
+		op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.BucketsLifecycle, nil, nil, opRun, nil, nil, nil)
+		if err != nil {
+			logger.Error("Failed creating storage bucket lifecycle operation", logger.Ctx{"err": err})
+			return
+		}
+
+		err = op.Start()
+		if err != nil {
+			logger.Error("Failed starting storage bucket lifecycle operation", logger.Ctx{"err": err})
+			return
+		}
+
+		err = op.Wait(ctx)
+		if err != nil {
+			logger.Error("Failed applying storage bucket lifecycle rules", logger.Ctx{"err": err})
+			return
+		}
+	}
+
+	first := true
+	schedule := func() (time.Duration, error) {
+		interval := time.Hour
+
+		if first {
+			first = false
+			return interval, task.ErrSkip
+		}
+
+		return interval, nil
+	}
+
+	return f, schedule
+}
+
+// applyStorageBucketLifecycle enforces the lifecycle rules of the local buckets stored on this member.
+func applyStorageBucketLifecycle(ctx context.Context, s *state.State) error {
+	var buckets []*db.StorageBucket
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+		buckets, err = tx.GetStoragePoolBuckets(ctx, true)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading storage buckets: %w", err)
+	}
+
+	for _, bucket := range buckets {
+		srv := local.NewServer("", nil)
+
+		err := srv.SetConfig(bucket.Config)
+		if err != nil {
+			logger.Warn("Invalid storage bucket lifecycle configuration", logger.Ctx{"project": bucket.Project, "pool": bucket.PoolName, "bucket": bucket.Name, "err": err})
+			continue
+		}
+
+		if srv.Lifecycle.IsEmpty() {
+			continue
+		}
+
+		pool, err := storagePools.LoadByName(s, bucket.PoolName)
+		if err != nil {
+			return err
+		}
+
+		// Buckets on remote pools are handled by their own S3 server.
+		if pool.Driver().Info().Remote {
+			continue
+		}
+
+		err = applyLocalBucketLifecycle(pool, bucket)
+		if err != nil {
+			logger.Warn("Failed applying storage bucket lifecycle rules", logger.Ctx{"project": bucket.Project, "pool": bucket.PoolName, "bucket": bucket.Name, "err": err})
+		}
+	}
+
+	return nil
+}
+
+func applyLocalBucketLifecycle(pool storagePools.Pool, bucket *db.StorageBucket) error {
+	bucketDir, unmount, err := pool.MountLocalBucket(bucket.Project, bucket.Name, nil)
+	if err != nil {
+		return err
+	}
+
+	defer logger.WarnOnError(unmount, "Failed to unmount bucket")
+
+	srv := local.NewServer(bucketDir, nil)
+
+	err = srv.SetConfig(bucket.Config)
+	if err != nil {
+		return err
+	}
+
+	return srv.ApplyLifecycle(time.Now())
+}
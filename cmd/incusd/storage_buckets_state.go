@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/lxc/incus/v7/internal/server/auth"
+	"github.com/lxc/incus/v7/internal/server/db"
+	"github.com/lxc/incus/v7/internal/server/project"
+	"github.com/lxc/incus/v7/internal/server/request"
+	"github.com/lxc/incus/v7/internal/server/response"
+	storagePools "github.com/lxc/incus/v7/internal/server/storage"
+	"github.com/lxc/incus/v7/internal/server/storage/s3/local"
+	"github.com/lxc/incus/v7/shared/api"
+	"github.com/lxc/incus/v7/shared/logger"
+	"github.com/lxc/incus/v7/shared/units"
+)
+
+var storagePoolBucketStateCmd = APIEndpoint{
+	Path: "storage-pools/{poolName}/buckets/{bucketName}/state",
+
+	Get: APIEndpointAction{Handler: storagePoolBucketStateGet, AccessHandler: allowPermission(auth.ObjectTypeStorageBucket, auth.EntitlementCanView, "poolName", "bucketName", "location")},
+}
+
+// swagger:operation GET /1.0/storage-pools/{poolName}/buckets/{bucketName}/state storage storage_pool_bucket_state_get
+//
+//	Get the storage pool bucket state
+//
+//	Gets the usage of a storage pool bucket and of its keys.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: path
+//	    name: poolName
+//	    description: Storage pool name
+//	    type: string
+//	    required: true
+//	  - in: path
+//	    name: bucketName
+//	    description: Storage bucket name
+//	    type: string
+//	    required: true
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    x-example: default
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    x-example: server01
+//	responses:
+//	  "200":
+//	    description: Storage pool bucket state
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/StorageBucketState"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func storagePoolBucketStateGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	bucketProjectName, err := project.StorageBucketProject(r.Context(), s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	poolName, err := pathVar(r, "poolName")
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading storage pool: %w", err))
+	}
+
+	if !pool.Driver().Info().Buckets {
+		return response.BadRequest(errors.New("Storage pool does not support buckets"))
+	}
+
+	// Buckets on remote pools are served by their own S3 server.
+	if pool.Driver().Info().Remote {
+		return response.BadRequest(errors.New("Bucket state is only available on local storage pools"))
+	}
+
+	bucketName, err := pathVar(r, "bucketName")
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	resp = forwardedResponseIfBucketIsRemote(s, r, poolName, bucketProjectName, bucketName)
+	if resp != nil {
+		return resp
+	}
+
+	var bucket *db.StorageBucket
+	var keys []*db.StorageBucketKey
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		bucket, err = tx.GetStoragePoolBucket(ctx, pool.ID(), bucketProjectName, true, bucketName)
+		if err != nil {
+			return err
+		}
+
+		keys, err = tx.GetStoragePoolBucketKeys(ctx, bucket.ID)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	bucketDir, unmount, err := pool.MountLocalBucket(bucket.Project, bucket.Name, nil)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	defer logger.WarnOnError(unmount, "Failed to unmount bucket")
+
+	srv := local.NewServer(bucketDir, nil)
+
+	err = srv.SetConfig(bucket.Config)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	usage, keysUsage, err := srv.Usage()
+	if err != nil {
+		return response.InternalError(fmt.Errorf("Failed getting bucket usage: %w", err))
+	}
+
+	// Quotas are reported as -1 when unlimited.
+	unlimited := func(quota int64) int64 {
+		if quota == 0 {
+			return -1
+		}
+
+		return quota
+	}
+
+	state := api.StorageBucketState{
+		Usage: api.StorageBucketStateUsage{
+			Objects:      usage.Objects,
+			Used:         usage.Bytes,
+			ObjectsQuota: unlimited(srv.Quota.Objects),
+			Total:        unlimited(srv.Quota.Size),
+		},
+		Keys: map[string]api.StorageBucketStateUsage{},
+	}
+
+	for _, key := range keys {
+		var quota int64
+		if key.Quota != "" {
+			quota, err = units.ParseByteSizeString(key.Quota)
+			if err != nil {
+				return response.InternalError(err)
+			}
+		}
+
+		state.Keys[key.Name] = api.StorageBucketStateUsage{
+			Objects:      keysUsage[key.Name].Objects,
+			Used:         keysUsage[key.Name].Bytes,
+			ObjectsQuota: -1,
+			Total:        unlimited(quota),
+		}
+	}
+
+	return response.SyncResponse(true, state)
+}
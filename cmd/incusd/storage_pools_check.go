@@ -0,0 +1,327 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lxc/incus/v7/internal/server/auth"
+	"github.com/lxc/incus/v7/internal/server/db"
+	dbCluster "github.com/lxc/incus/v7/internal/server/db/cluster"
+	"github.com/lxc/incus/v7/internal/server/db/operationtype"
+	"github.com/lxc/incus/v7/internal/server/db/warningtype"
+	"github.com/lxc/incus/v7/internal/server/operations"
+	"github.com/lxc/incus/v7/internal/server/response"
+	"github.com/lxc/incus/v7/internal/server/state"
+	storagePools "github.com/lxc/incus/v7/internal/server/storage"
+	storageDrivers "github.com/lxc/incus/v7/internal/server/storage/drivers"
+	"github.com/lxc/incus/v7/internal/server/task"
+	localUtil "github.com/lxc/incus/v7/internal/server/util"
+	"github.com/lxc/incus/v7/internal/server/warnings"
+	"github.com/lxc/incus/v7/internal/version"
+	"github.com/lxc/incus/v7/shared/api"
+	"github.com/lxc/incus/v7/shared/logger"
+)
+
+// storagePoolChecks tracks the storage pools being checked on this server, by ID.
+var (
+	storagePoolChecks     = map[int64]bool{}
+	storagePoolChecksLock sync.Mutex
+)
+
+// storagePoolCheckTrack marks the storage pool as being checked, returning false if it already is.
+func storagePoolCheckTrack(poolID int64) bool {
+	storagePoolChecksLock.Lock()
+	defer storagePoolChecksLock.Unlock()
+
+	if storagePoolChecks[poolID] {
+		return false
+	}
+
+	storagePoolChecks[poolID] = true
+
+	return true
+}
+
+// storagePoolCheckUntrack marks the storage pool as no longer being checked.
+func storagePoolCheckUntrack(poolID int64) {
+	storagePoolChecksLock.Lock()
+	defer storagePoolChecksLock.Unlock()
+
+	delete(storagePoolChecks, poolID)
+}
+
+var storagePoolCheckCmd = APIEndpoint{
+	Path: "storage-pools/{poolName}/check",
+
+	Post: APIEndpointAction{Handler: storagePoolCheckPost, AccessHandler: allowPermission(auth.ObjectTypeStoragePool, auth.EntitlementCanEdit, "poolName")},
+}
+
+// swagger:operation POST /1.0/storage-pools/{poolName}/check storage storage_pool_check_post
+//
+//	Check the storage pool
+//
+//	Runs an integrity check of the storage pool (and of its volumes where supported).
+//	Problems which are found get reported in the operation metadata and as warnings.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: path
+//	    name: poolName
+//	    description: Storage pool name
+//	    type: string
+//	    required: true
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    x-example: server01
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "409":
+//	    description: The storage pool is already being checked
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func storagePoolCheckPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	// If a target was specified, forward the request to the relevant node.
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	poolName, err := pathVar(r, "poolName")
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if pool.LocalStatus() != api.StoragePoolStatusCreated {
+		return response.BadRequest(errors.New("The storage pool isn't available on this server"))
+	}
+
+	if !storagePoolCheckTrack(pool.ID()) {
+		return response.Conflict(fmt.Errorf("Storage pool %q is already being checked", pool.Name()))
+	}
+
+	resources := map[string][]api.URL{}
+	resources["storage_pools"] = []api.URL{*api.NewURL().Path(version.APIVersion, "storage-pools", pool.Name())}
+
+	ctx, cancel := context.WithCancel(s.ShutdownCtx)
+
+	run := func(op *operations.Operation) error {
+		defer storagePoolCheckUntrack(pool.ID())
+		defer cancel()
+
+		return storagePoolCheck(ctx, s, pool, op)
+	}
+
+	onCancel := func(op *operations.Operation) error {
+		cancel()
+		return nil
+	}
+
+	op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.StoragePoolCheck, resources, nil, run, onCancel, nil, r)
+	if err != nil {
+		storagePoolCheckUntrack(pool.ID())
+		cancel()
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
+// storagePoolCheck runs an integrity check of the storage pool and records the problems which were found
+// as a warning of the storage pool, resolving it when none were found.
+func storagePoolCheck(ctx context.Context, s *state.State, pool storagePools.Pool, op *operations.Operation) error {
+	problems, err := pool.Check(ctx, op)
+	if errors.Is(err, storageDrivers.ErrNotSupported) {
+		return fmt.Errorf("Integrity checks aren't supported by the %q storage driver", pool.Driver().Info().Name)
+	} else if err != nil {
+		return err
+	}
+
+	if len(problems) == 0 {
+		return warnings.ResolveWarningsByNodeAndProjectAndTypeAndEntity(s.DB.Cluster, s.ServerName, "", warningtype.StoragePoolCheckFailed, dbCluster.TypeStoragePool, int(pool.ID()))
+	}
+
+	logger.Warn("Storage pool integrity check found problems", logger.Ctx{"pool": pool.Name(), "problems": problems})
+
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpsertWarning(ctx, s.ServerName, "", dbCluster.TypeStoragePool, int(pool.ID()), warningtype.StoragePoolCheckFailed, strings.Join(problems, "; "))
+	})
+	if err != nil {
+		return fmt.Errorf("Failed recording storage pool check warning: %w", err)
+	}
+
+	if op != nil {
+		err = op.UpdateMetadata(map[string]any{"problems": problems})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// autoCheckStoragePool runs a scheduled integrity check of the storage pool as an operation and waits for it.
+// The storage pool must have been marked as being checked by storagePoolCheckTrack.
+func autoCheckStoragePool(ctx context.Context, s *state.State, pool storagePools.Pool) {
+	defer storagePoolCheckUntrack(pool.ID())
+
+	checkCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	opRun := func(op *operations.Operation) error {
+		return storagePoolCheck(checkCtx, s, pool, op)
+	}
+
+	onCancel := func(op *operations.Operation) error {
+		cancel()
+		return nil
+	}
+
+	resources := map[string][]api.URL{}
+	resources["storage_pools"] = []api.URL{*api.NewURL().Path(version.APIVersion, "storage-pools", pool.Name())}
+
+	op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.StoragePoolCheck, resources, nil, opRun, onCancel, nil, nil)
+	if err != nil {
+		logger.Error("Failed creating scheduled storage pool check operation", logger.Ctx{"pool": pool.Name(), "err": err})
+		return
+	}
+
+	logger.Info("Checking storage pool", logger.Ctx{"pool": pool.Name()})
+
+	err = op.Start()
+	if err != nil {
+		logger.Error("Failed starting scheduled storage pool check operation", logger.Ctx{"pool": pool.Name(), "err": err})
+		return
+	}
+
+	err = op.Wait(ctx)
+	if err != nil {
+		logger.Error("Failed scheduled storage pool check", logger.Ctx{"pool": pool.Name(), "err": err})
+		return
+	}
+
+	logger.Info("Done checking storage pool", logger.Ctx{"pool": pool.Name()})
+}
+
+func autoCheckStoragePoolsTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		var poolNames []string
+		var memberCount int
+		var onlineMemberIDs []int64
+
+		err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			var err error
+
+			poolNames, err = tx.GetStoragePoolNames(ctx)
+			if err != nil && !response.IsNotFoundError(err) {
+				return fmt.Errorf("Failed getting storage pools: %w", err)
+			}
+
+			members, err := tx.GetNodes(ctx)
+			if err != nil {
+				return fmt.Errorf("Failed getting cluster members: %w", err)
+			}
+
+			memberCount = len(members)
+
+			// Filter to online members.
+			for _, member := range members {
+				if member.IsOffline(s.GlobalConfig.OfflineThreshold()) {
+					continue
+				}
+
+				onlineMemberIDs = append(onlineMemberIDs, member.ID)
+			}
+
+			return nil
+		})
+		if err != nil {
+			logger.Error("Failed getting storage pool check schedule info", logger.Ctx{"err": err})
+			return
+		}
+
+		var pools []storagePools.Pool
+
+		for _, poolName := range poolNames {
+			pool, err := storagePools.LoadByName(s, poolName)
+			if err != nil {
+				logger.Error("Failed loading storage pool for check task", logger.Ctx{"pool": poolName, "err": err})
+				continue
+			}
+
+			schedule := pool.Driver().Config()["check.schedule"]
+			if schedule == "" || pool.LocalStatus() != api.StoragePoolStatusCreated || !snapshotIsScheduledNow(schedule, pool.ID()) {
+				continue
+			}
+
+			// Remote storage pools are only checked from a stable random member. Skip them if there
+			// are no online members, as the cluster may be partitioned.
+			if pool.Driver().Info().Remote && memberCount > 1 {
+				if len(onlineMemberIDs) == 0 {
+					continue
+				}
+
+				selectedNodeID, err := localUtil.GetStableRandomInt64FromList(pool.ID(), onlineMemberIDs)
+				if err != nil {
+					logger.Error("Failed scheduling storage pool check", logger.Ctx{"pool": poolName, "err": err})
+					continue
+				}
+
+				if s.DB.Cluster.GetNodeID() != selectedNodeID {
+					continue
+				}
+			}
+
+			pools = append(pools, pool)
+		}
+
+		// Checks of large pools can take hours, so they run in the background rather than delaying the
+		// next run of the task. Pools which are still being checked are skipped.
+		for _, pool := range pools {
+			if !storagePoolCheckTrack(pool.ID()) {
+				logger.Warn("Skipping scheduled storage pool check as the pool is already being checked", logger.Ctx{"pool": pool.Name()})
+				continue
+			}
+
+			go autoCheckStoragePool(ctx, s, pool)
+		}
+	}
+
+	first := true
+	schedule := func() (time.Duration, error) {
+		interval := time.Minute
+
+		if first {
+			first = false
+			return interval, task.ErrSkip
+		}
+
+		return interval, nil
+	}
+
+	return f, schedule
+}
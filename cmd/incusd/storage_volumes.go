@@ -229,6 +229,71 @@ var storagePoolVolumeTypeFileCmd = APIEndpoint{
 //    "500":
 //      $ref: "#/responses/InternalServerError"
 
+// swagger:operation GET /1.0/storage-pools/{poolName}/volumes?recursion=2 storage storage_pool_volumes_get_recursion2
+//
+//  Get the storage volumes with all details
+//
+//  Returns a list of storage volumes (structs) including their backups, snapshots and state.
+//
+//  ---
+//  produces:
+//    - application/json
+//  parameters:
+//    - in: path
+//      name: poolName
+//      description: Storage pool name
+//      type: string
+//      required: true
+//    - in: query
+//      name: project
+//      description: Project name
+//      type: string
+//      x-example: default
+//    - in: query
+//      name: target
+//      description: Cluster member name
+//      type: string
+//      x-example: server01
+//    - in: query
+//      name: filter
+//      description: Collection filter
+//      type: string
+//      x-example: default
+//  responses:
+//    "200":
+//      description: API endpoints
+//      schema:
+//        type: object
+//        description: Sync response
+//        properties:
+//          type:
+//            type: string
+//            description: Response type
+//            example: sync
+//          status:
+//            type: string
+//            description: Status description
+//            example: Success
+//          status_code:
+//            type: integer
+//            description: Status code
+//            example: 200
+//          metadata:
+//            type: array
+//            description: List of storage volumes
+//            items:
+//              $ref: "#/definitions/StorageVolumeFull"
+//    "400":
+//      $ref: "#/responses/BadRequest"
+//    "403":
+//      $ref: "#/responses/Forbidden"
+//    "404":
+//      $ref: "#/responses/NotFound"
+//    "409":
+//      $ref: "#/responses/Conflict"
+//    "500":
+//      $ref: "#/responses/InternalServerError"
+
 // swagger:operation GET /1.0/storage-pools/{poolName}/volumes/{type} storage storage_pool_volumes_type_get
 //
 //  Get the storage volumes
@@ -1000,6 +1065,12 @@ func storagePoolVolumesPost(d *Daemon, r *http.Request) response.Response {
 		return doVolumeCreateOrCopy(s, r, request.ProjectParam(r), projectName, poolName, &req)
 	case "migration":
 		return doVolumeMigration(s, r, request.ProjectParam(r), projectName, poolName, &req)
+	case "repository":
+		if dbVolume != nil {
+			return response.Conflict(errors.New("Volume by that name already exists"))
+		}
+
+		return doVolumeCreateFromRepository(s, r, request.ProjectParam(r), projectName, poolName, &req)
 	default:
 		return response.BadRequest(fmt.Errorf("Unknown source type %q", req.Source.Type))
 	}
@@ -2229,20 +2300,7 @@ func getVolumeFull(ctx context.Context, s *state.State, poolName string, vol api
 	default:
 	}
 
-	volState := api.StorageVolumeState{}
-	if usage != nil {
-		volState.Usage = &api.StorageVolumeStateUsage{}
-
-		// Only fill 'used' field if receiving a valid value.
-		if usage.Used >= 0 {
-			volState.Usage.Used = uint64(usage.Used)
-		}
-
-		// Only fill 'total' field if receiving a valid value.
-		if usage.Total >= 0 {
-			volState.Usage.Total = usage.Total
-		}
-	}
+	volState := api.StorageVolumeState{Usage: storagePoolVolumeStateUsage(usage)}
 
 	resp.State = &volState
 
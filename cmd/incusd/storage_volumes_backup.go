@@ -462,6 +462,36 @@ func storagePoolVolumeTypeCustomBackupsPost(d *Daemon, r *http.Request) response
 		}
 	}
 
+	if req.Repository != "" {
+		if direct || req.Target != nil || req.Name != "" {
+			return response.BadRequest(errors.New("Backups stored in a backup repository can't be named, streamed or uploaded"))
+		}
+
+		err = internalInstance.ValidateBackupRepository(req.Repository)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+
+		if dbVolume.ContentType != db.StoragePoolVolumeContentTypeNameBlock {
+			return response.BadRequest(errors.New("Backup repositories are only supported for block volumes"))
+		}
+
+		run := func(op *operations.Operation) error {
+			_, err := backupRepositoryCustomVolume(s, projectName, poolName, volumeName, req.Repository, op)
+			return err
+		}
+
+		resources := map[string][]api.URL{}
+		resources["storage_volumes"] = []api.URL{*api.NewURL().Path(version.APIVersion, "storage-pools", poolName, "volumes", volumeTypeName, volumeName)}
+
+		op, err := operations.OperationCreate(s, request.ProjectParam(r), operations.OperationClassTask, operationtype.CustomVolumeBackupCreate, resources, nil, run, nil, nil, r)
+		if err != nil {
+			return response.InternalError(err)
+		}
+
+		return operations.OperationResponse(op)
+	}
+
 	var reader *io.PipeReader
 	var writer *io.PipeWriter
 	var fullName string
@@ -489,29 +519,7 @@ func storagePoolVolumeTypeCustomBackupsPost(d *Daemon, r *http.Request) response
 				return response.BadRequest(err)
 			}
 
-			base := volumeName + internalInstance.SnapshotDelimiter + "backup"
-			length := len(base)
-			backupID := 0
-
-			for _, backup := range backups {
-				// Ignore backups not containing base.
-				if !strings.HasPrefix(backup, base) {
-					continue
-				}
-
-				substr := backup[length:]
-				var num int
-				count, err := fmt.Sscanf(substr, "%d", &num)
-				if err != nil || count != 1 {
-					continue
-				}
-
-				if num >= backupID {
-					backupID = num + 1
-				}
-			}
-
-			req.Name = fmt.Sprintf("backup%d", backupID)
+			req.Name = backupNextName(volumeName, "backup", backups)
 		}
 
 		// Quick checks.
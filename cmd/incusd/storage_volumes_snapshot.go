@@ -1414,6 +1414,38 @@ func pruneExpiredAndAutoCreateCustomVolumeSnapshotsTask(d *Daemon) (task.Func, t
 				return fmt.Errorf("Failed getting expired custom volume snapshots: %w", err)
 			}
 
+			allVolumes, err := tx.GetStoragePoolVolumesWithType(ctx, db.StoragePoolVolumeTypeCustom, true)
+			if err != nil {
+				return fmt.Errorf("Failed getting volumes for auto custom volume snapshot task: %w", err)
+			}
+
+			expiredSnapshotIDs := make(map[int64]bool, len(allExpiredSnapshots))
+			for _, v := range allExpiredSnapshots {
+				expiredSnapshotIDs[v.ID] = true
+			}
+
+			for _, v := range allVolumes {
+				if v.Config["snapshots.retention"] == "" {
+					continue
+				}
+
+				// Add the snapshots not kept by the retention policy to the expired ones.
+				pruned, err := retentionPrunedCustomVolumeSnapshots(ctx, tx, v)
+				if err != nil {
+					logger.Error("Failed applying custom volume snapshot retention policy", logger.Ctx{"volName": v.Name, "project": v.ProjectName, "pool": v.PoolName, "err": err})
+					continue
+				}
+
+				for _, snapshot := range pruned {
+					if expiredSnapshotIDs[snapshot.ID] {
+						continue
+					}
+
+					expiredSnapshotIDs[snapshot.ID] = true
+					allExpiredSnapshots = append(allExpiredSnapshots, snapshot)
+				}
+			}
+
 			for _, v := range allExpiredSnapshots {
 				if v.NodeID < 0 {
 					// Keep a separate list of remote volumes in order to select a member to
@@ -1439,11 +1471,6 @@ func pruneExpiredAndAutoCreateCustomVolumeSnapshotsTask(d *Daemon) (task.Func, t
 				}
 			}
 
-			allVolumes, err := tx.GetStoragePoolVolumesWithType(ctx, db.StoragePoolVolumeTypeCustom, true)
-			if err != nil {
-				return fmt.Errorf("Failed getting volumes for auto custom volume snapshot task: %w", err)
-			}
-
 			for _, v := range allVolumes {
 				err = project.AllowSnapshotCreation(projects[v.ProjectName])
 				if err != nil {
@@ -1658,6 +1685,47 @@ func pruneExpiredCustomVolumeSnapshots(ctx context.Context, s *state.State, expi
 	return nil
 }
 
+// retentionPrunedCustomVolumeSnapshots returns the snapshots of the custom volume which aren't kept by its snapshots.retention policy.
+// Only the snapshots named after snapshots.pattern are considered, so that explicitly named snapshots are never deleted.
+func retentionPrunedCustomVolumeSnapshots(ctx context.Context, tx *db.ClusterTx, volume db.StorageVolumeArgs) ([]db.StorageVolumeArgs, error) {
+	retention, err := internalInstance.ParseSnapshotRetention(volume.Config["snapshots.retention"])
+	if err != nil {
+		return nil, err
+	}
+
+	poolID, err := tx.GetStoragePoolID(ctx, volume.PoolName)
+	if err != nil {
+		return nil, err
+	}
+
+	allSnapshots, err := tx.GetLocalStoragePoolVolumeSnapshotsWithType(ctx, volume.ProjectName, volume.Name, db.StoragePoolVolumeTypeCustom, poolID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]db.StorageVolumeArgs, 0, len(allSnapshots))
+	creationDates := make([]time.Time, 0, len(allSnapshots))
+	for _, snapshot := range allSnapshots {
+		_, snapName, _ := api.GetParentAndSnapshotName(snapshot.Name)
+		if !internalInstance.IsSnapshotNameFromPattern(volume.Config["snapshots.pattern"], snapName, snapshot.CreationDate) {
+			continue
+		}
+
+		snapshots = append(snapshots, snapshot)
+		creationDates = append(creationDates, snapshot.CreationDate)
+	}
+
+	pruned := []db.StorageVolumeArgs{}
+	for _, i := range retention.Prune(creationDates) {
+		snapshot := snapshots[i]
+		snapshot.PoolName = volume.PoolName
+		snapshot.NodeID = volume.NodeID
+		pruned = append(pruned, snapshot)
+	}
+
+	return pruned, nil
+}
+
 func autoCreateCustomVolumeSnapshots(ctx context.Context, s *state.State, volumes []db.StorageVolumeArgs) error {
 	// Make the snapshots sequentially.
 	for _, v := range volumes {
@@ -164,21 +164,36 @@ func storagePoolVolumeTypeStateGet(d *Daemon, r *http.Request) response.Response
 	}
 
 	// Prepare the state struct.
-	state := api.StorageVolumeState{}
+	state := api.StorageVolumeState{Usage: storagePoolVolumeStateUsage(usage)}
 
-	if usage != nil {
-		state.Usage = &api.StorageVolumeStateUsage{}
+	return response.SyncResponse(true, state)
+}
 
-		// Only fill 'used' field if receiving a valid value.
-		if usage.Used >= 0 {
-			state.Usage.Used = uint64(usage.Used)
-		}
+// storagePoolVolumeStateUsage converts the volume usage to its API representation.
+func storagePoolVolumeStateUsage(usage *storagePools.VolumeUsage) *api.StorageVolumeStateUsage {
+	if usage == nil {
+		return nil
+	}
 
-		// Only fill 'total' field if receiving a valid value.
-		if usage.Total >= 0 {
-			state.Usage.Total = usage.Total
-		}
+	apiUsage := api.StorageVolumeStateUsage{
+		CompressionRatio: usage.CompressionRatio,
+		OvercommitRatio:  usage.OvercommitRatio,
 	}
 
-	return response.SyncResponse(true, state)
+	// Only fill 'used' field if receiving a valid value.
+	if usage.Used >= 0 {
+		apiUsage.Used = uint64(usage.Used)
+	}
+
+	// Only fill 'total' field if receiving a valid value.
+	if usage.Total >= 0 {
+		apiUsage.Total = usage.Total
+	}
+
+	// Only fill 'snapshots' field if receiving a valid value.
+	if usage.SnapshotsUsed >= 0 {
+		apiUsage.Snapshots = uint64(usage.SnapshotsUsed)
+	}
+
+	return &apiUsage
 }
@@ -0,0 +1,82 @@
+package instance
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/lxc/incus/v7/shared/api"
+)
+
+// BackupScheduleAliases are the schedule aliases supported by `backups.schedule`.
+var BackupScheduleAliases = []string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@annually", "@yearly", "@never"}
+
+// ValidateBackupTargetName validates the name of a backup target defined in the server configuration.
+func ValidateBackupTargetName(value string) error {
+	if value == "" {
+		return errors.New("Backup target name can't be empty")
+	}
+
+	for _, r := range value {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			return fmt.Errorf("Invalid character %q in backup target name %q", r, value)
+		}
+	}
+
+	return nil
+}
+
+// ValidateBackupRepository validates a backup repository, which is referenced through the name of a backup target
+// so that only the locations configured on the server can be used.
+func ValidateBackupRepository(value string) error {
+	if strings.HasPrefix(value, "/") {
+		return errors.New("Backup repositories must be referenced through a backup target name rather than a path")
+	}
+
+	return ValidateBackupTargetName(value)
+}
+
+// ParseBackupTargetURL parses the URL of a backup target into a backup target without credentials.
+// The expected format is either "<http|https>://<host>[:<port>]/<bucket>[/<prefix>]" or an absolute local path,
+// the latter only being usable for backup repositories.
+func ParseBackupTargetURL(value string) (*api.BackupTarget, error) {
+	if strings.HasPrefix(value, "/") {
+		if filepath.Clean(value) != value {
+			return nil, fmt.Errorf("Backup target path %q isn't clean", value)
+		}
+
+		return &api.BackupTarget{Protocol: "local", Path: value}, nil
+	}
+
+	uri, err := url.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid backup target URL: %w", err)
+	}
+
+	if uri.Scheme != "http" && uri.Scheme != "https" {
+		return nil, fmt.Errorf("Unsupported backup target scheme %q", uri.Scheme)
+	}
+
+	if uri.Host == "" {
+		return nil, errors.New("Backup target is missing a host")
+	}
+
+	if uri.User != nil {
+		return nil, errors.New("Backup target URL can't contain credentials")
+	}
+
+	bucketName, prefix, _ := strings.Cut(strings.Trim(uri.Path, "/"), "/")
+	if bucketName == "" {
+		return nil, errors.New("Backup target is missing a bucket name")
+	}
+
+	return &api.BackupTarget{
+		Protocol:   "s3",
+		URL:        fmt.Sprintf("%s://%s", uri.Scheme, uri.Host),
+		BucketName: bucketName,
+		Path:       prefix,
+	}, nil
+}
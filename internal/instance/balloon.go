@@ -0,0 +1,74 @@
+package instance
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/lxc/incus/v7/shared/units"
+)
+
+// IsMemoryBalloonSize validates a memory balloon size, either a fixed value in bytes or a percentage of the memory
+// limit of the instance.
+func IsMemoryBalloonSize(value string) error {
+	_, err := MemoryBalloonSize(value, 0)
+	return err
+}
+
+// MemoryBalloonSize returns the size in bytes of a memory balloon size relative to the memory limit of the instance.
+func MemoryBalloonSize(value string, limit int64) (int64, error) {
+	before, ok := strings.CutSuffix(value, "%")
+	if ok {
+		percent, err := strconv.ParseInt(before, 10, 64)
+		if err != nil {
+			return -1, err
+		}
+
+		if percent < 0 || percent > 100 {
+			return -1, errors.New("Percentage must be between 0 and 100")
+		}
+
+		return limit * percent / 100, nil
+	}
+
+	size, err := units.ParseByteSizeString(value)
+	if err != nil {
+		return -1, err
+	}
+
+	return size, nil
+}
+
+// MemoryBalloonTarget returns the memory size a VM should be resized to by its balloon, or -1 to leave it as is.
+// The limit is the memory limit of the VM, actual its current memory size and available the memory available
+// inside of it. The VM is never shrunk below minimum and is left with target of available memory when shrunk.
+//
+// Memory is only reclaimed if reclaim is true, a bit at a time, while it is returned as soon as the VM runs low.
+func MemoryBalloonTarget(limit int64, actual int64, available int64, minimum int64, target int64, reclaim bool) int64 {
+	minimum = min(minimum, limit)
+	used := max(actual-available, 0)
+
+	// Ignore changes of less than 1% of the limit to avoid resizing the balloon all the time.
+	resize := func(size int64) int64 {
+		if size == actual || max(size-actual, actual-size) < limit/100 {
+			return -1
+		}
+
+		return size
+	}
+
+	// Return memory to the VM when it runs low.
+	if available < target/2 && actual < limit {
+		return resize(min(used+target, limit))
+	}
+
+	// Reclaim memory from the VM, at most a tenth of its limit at a time.
+	if reclaim && available > target && actual > minimum {
+		size := max(used+target, minimum, actual-limit/10)
+		if size < actual {
+			return resize(size)
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,61 @@
+package instance
+
+import (
+	"testing"
+)
+
+func TestMemoryBalloonSize(t *testing.T) {
+	tests := []struct {
+		value   string
+		limit   int64
+		want    int64
+		wantErr bool
+	}{
+		{"50%", 4096, 2048, false},
+		{"0%", 4096, 0, false},
+		{"1MiB", 0, 1024 * 1024, false},
+		{"101%", 4096, -1, true},
+		{"abc", 4096, -1, true},
+	}
+
+	for _, tt := range tests {
+		got, err := MemoryBalloonSize(tt.value, tt.limit)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("MemoryBalloonSize(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+		}
+
+		if got != tt.want {
+			t.Errorf("MemoryBalloonSize(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestMemoryBalloonTarget(t *testing.T) {
+	const mib = 1024 * 1024
+
+	tests := []struct {
+		name      string
+		actual    int64
+		available int64
+		reclaim   bool
+		want      int64
+	}{
+		{"idle without pressure", 1024 * mib, 800 * mib, false, -1},
+		{"idle under pressure", 1024 * mib, 800 * mib, true, 1024*mib - 1024*mib/10},
+		{"reclaim down to target", 800 * mib, 250 * mib, true, 750 * mib},
+		{"reclaim down to minimum", 550 * mib, 500 * mib, true, 512 * mib},
+		{"at minimum", 512 * mib, 450 * mib, true, -1},
+		{"busy under pressure", 1024 * mib, 150 * mib, true, -1},
+		{"low on memory", 600 * mib, 50 * mib, true, 750 * mib},
+		{"low on memory near limit", 1000 * mib, 50 * mib, false, 1024 * mib},
+		{"low on memory at limit", 1024 * mib, 50 * mib, false, -1},
+		{"small change", 1024 * mib, 205 * mib, true, -1},
+	}
+
+	for _, tt := range tests {
+		got := MemoryBalloonTarget(1024*mib, tt.actual, tt.available, 512*mib, 200*mib, tt.reclaim)
+		if got != tt.want {
+			t.Errorf("%s: MemoryBalloonTarget() = %d MiB, want %d MiB", tt.name, got/mib, tt.want/mib)
+		}
+	}
+}
@@ -82,6 +82,40 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: What order to start the instances in
 	"boot.autostart.priority": validate.Optional(validate.IsInt64),
 
+	// gendoc:generate(entity=instance, group=boot, key=boot.depends)
+	// Comma-separated list of instances of the same project that must be running before the instance starts.
+	// When several instances are started together, including on daemon startup, the instances are started
+	// after the instances they depend on. On host shutdown, they're stopped before the instances they depend on.
+	//
+	// See {ref}`instances-manage-depends` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  shortdesc: Instances that must be running before the instance starts
+	"boot.depends": validate.Optional(validate.IsListOf(validate.IsHostname)),
+
+	// gendoc:generate(entity=instance, group=boot, key=boot.depends.condition)
+	// Possible values are:
+	//
+	//   - `running`: The instances in `boot.depends` must be running.
+	//   - `ready`: The instances in `boot.depends` must be running and ready, as reported by the `incus-agent` or through the guest API.
+	//   - `healthy`: The instances in `boot.depends` must be running and pass their health check. Instances without a health check only need to be running.
+	// ---
+	//  type: string
+	//  defaultdesc: `running`
+	//  liveupdate: no
+	//  shortdesc: Condition that the instances in `boot.depends` must meet
+	"boot.depends.condition": validate.Optional(validate.IsOneOf("running", "ready", "healthy")),
+
+	// gendoc:generate(entity=instance, group=boot, key=boot.depends.timeout)
+	// The start of the instance fails if its dependencies don't meet `boot.depends.condition` within this delay.
+	// ---
+	//  type: integer
+	//  defaultdesc: `120`
+	//  liveupdate: no
+	//  shortdesc: Number of seconds to wait for the instances in `boot.depends`
+	"boot.depends.timeout": validate.Optional(validate.IsUint32),
+
 	// gendoc:generate(entity=instance, group=boot, key=boot.stop.priority)
 	// The instance with the highest value is shut down first.
 	// ---
@@ -199,6 +233,125 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: What to do when evacuating the instance
 	"cluster.evacuate": validate.Optional(validate.IsOneOf("auto", "migrate", "live-migrate", "refresh-migrate", "stop", "stateful-stop", "force-stop")),
 
+	// gendoc:generate(entity=instance, group=healthcheck, key=healthcheck.type)
+	// Possible values are:
+	//
+	//   - `exec`: Run `healthcheck.command` inside the instance, a non-zero exit code is a failure.
+	//   - `tcp`: Connect to `healthcheck.address` and `healthcheck.port` from inside the instance.
+	//   - `http`: Send an HTTP `GET` request for `healthcheck.path` to `healthcheck.address` and `healthcheck.port` from inside the instance, a status code of 400 or higher is a failure.
+	//
+	// See {ref}`instances-healthcheck` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Type of health check (`exec`, `tcp` or `http`)
+	"healthcheck.type": validate.Optional(validate.IsOneOf("exec", "tcp", "http")),
+
+	// gendoc:generate(entity=instance, group=healthcheck, key=healthcheck.command)
+	// The command is run directly (not through a shell) with the `root` user.
+	// For virtual machines, this requires the `incus-agent` to be running.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  condition: `healthcheck.type` is `exec`
+	//  shortdesc: Command to run to check the health of the instance
+	"healthcheck.command": validate.IsAny,
+
+	// gendoc:generate(entity=instance, group=healthcheck, key=healthcheck.address)
+	//
+	// ---
+	//  type: string
+	//  defaultdesc: `127.0.0.1`
+	//  liveupdate: yes
+	//  condition: `healthcheck.type` is `tcp` or `http`
+	//  shortdesc: IP address to connect to from inside the instance
+	"healthcheck.address": validate.Optional(validate.IsNetworkAddress),
+
+	// gendoc:generate(entity=instance, group=healthcheck, key=healthcheck.port)
+	//
+	// ---
+	//  type: integer
+	//  liveupdate: yes
+	//  condition: `healthcheck.type` is `tcp` or `http`
+	//  shortdesc: Port to connect to from inside the instance
+	"healthcheck.port": validate.Optional(validate.IsNetworkPort),
+
+	// gendoc:generate(entity=instance, group=healthcheck, key=healthcheck.path)
+	//
+	// ---
+	//  type: string
+	//  defaultdesc: `/`
+	//  liveupdate: yes
+	//  condition: `healthcheck.type` is `http`
+	//  shortdesc: Path of the HTTP request
+	"healthcheck.path": validate.Optional(func(value string) error {
+		if !strings.HasPrefix(value, "/") {
+			return errors.New("Path must start with /")
+		}
+
+		return nil
+	}),
+
+	// gendoc:generate(entity=instance, group=healthcheck, key=healthcheck.interval)
+	// Health checks are scheduled in steps of 5 seconds.
+	// ---
+	//  type: integer
+	//  defaultdesc: `30`
+	//  liveupdate: yes
+	//  shortdesc: Number of seconds between two checks
+	"healthcheck.interval": validate.Optional(validate.IsInRange(5, 86400)),
+
+	// gendoc:generate(entity=instance, group=healthcheck, key=healthcheck.timeout)
+	//
+	// ---
+	//  type: integer
+	//  defaultdesc: `5`
+	//  liveupdate: yes
+	//  shortdesc: Number of seconds after which a check is considered failed
+	"healthcheck.timeout": validate.Optional(validate.IsInRange(1, 3600)),
+
+	// gendoc:generate(entity=instance, group=healthcheck, key=healthcheck.failures)
+	//
+	// ---
+	//  type: integer
+	//  defaultdesc: `3`
+	//  liveupdate: yes
+	//  shortdesc: Number of consecutive failed checks after which the instance is unhealthy
+	"healthcheck.failures": validate.Optional(validate.IsInRange(1, 100)),
+
+	// gendoc:generate(entity=instance, group=healthcheck, key=healthcheck.start_period)
+	// Failed checks during that period after the instance started don't count towards `healthcheck.failures`, unless a check already succeeded.
+	// ---
+	//  type: integer
+	//  defaultdesc: `0`
+	//  liveupdate: yes
+	//  shortdesc: Number of seconds given to the instance to start before failed checks count
+	"healthcheck.start_period": validate.Optional(validate.IsInRange(0, 86400)),
+
+	// gendoc:generate(entity=instance, group=healthcheck, key=healthcheck.action)
+	// Possible values are:
+	//
+	//   - `event-only`: Only emit an `instance-health-changed` lifecycle event.
+	//   - `restart`: Force a restart of the instance.
+	//   - `stop`: Stop the instance, forcing it to stop if it doesn't shut down cleanly within `boot.host_shutdown_timeout`.
+	//   - `migrate`: Stop the instance and start it on another cluster member. If the server isn't clustered or no other member is available, the instance is restarted instead.
+	// ---
+	//  type: string
+	//  defaultdesc: `event-only`
+	//  liveupdate: yes
+	//  shortdesc: Action to take when the instance becomes unhealthy
+	"healthcheck.action": validate.Optional(validate.IsOneOf("event-only", "restart", "stop", "migrate")),
+
+	// gendoc:generate(entity=instance, group=ignition, key=ignition.config)
+	// The content is a JSON Ignition config (spec version 2 or 3), as used by Fedora CoreOS and Flatcar.
+	// The configs set in the profiles of the instance and in the instance itself are merged, in that order.
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  condition: If supported by image
+	//  shortdesc: Ignition config
+	"ignition.config": validate.Optional(IsIgnitionConfig),
+
 	// gendoc:generate(entity=instance, group=resource-limits, key=limits.cpu)
 	// A number or a specific range of CPUs to expose to the instance.
 	// For virtual machines, a CPU topology of the form `sockets=2,cores=4,threads=2` may also be provided.
@@ -476,6 +629,72 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 		return err
 	},
 
+	// gendoc:generate(entity=instance, group=snapshots, key=snapshots.retention)
+	// Specify a comma-separated list of `<count> <period>` rules like `24 hourly, 7 daily, 4 weekly`.
+	//
+	// The supported periods are `latest`, `hourly`, `daily`, `weekly`, `monthly` and `yearly`.
+	// For each rule, the most recent snapshot of each of the last `<count>` periods containing a snapshot is kept.
+	// Only the snapshots named after {config:option}`instance-snapshots:snapshots.pattern` (like scheduled snapshots) are considered.
+	// Those not kept by any rule are deleted, while snapshots that were given an explicit name are never deleted.
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  shortdesc: Count-based retention policy for snapshots
+	"snapshots.retention": func(value string) error {
+		_, err := ParseSnapshotRetention(value)
+		return err
+	},
+
+	// gendoc:generate(entity=instance, group=backups, key=backups.schedule)
+	// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-and-space-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or leave empty to disable automatic backups.
+	//
+	// Note that unlike most other configuration keys, this one must be comma-and-space-separated and not just comma-separated as cron expression can themselves contain commas.
+	// ---
+	//  type: string
+	//  defaultdesc: empty
+	//  liveupdate: no
+	//  shortdesc: Schedule for automatic instance backups
+	"backups.schedule": validate.Optional(validate.IsCron(BackupScheduleAliases)),
+
+	// gendoc:generate(entity=instance, group=backups, key=backups.expiry)
+	// Specify an expression like `1M 2H 3d 4w 5m 6y`.
+	//
+	// This value is used to compute the expiry date of backups created through scheduling.
+	// It has no effect when {config:option}`instance-backups:backups.target` is set.
+	//
+	// See {config:option}`instance-snapshots:snapshots.expiry` for the supported units.
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  shortdesc: When scheduled backups are to be deleted
+	"backups.expiry": func(value string) error {
+		// Validate expression
+		_, err := GetExpiry(time.Time{}, value)
+		return err
+	},
+
+	// gendoc:generate(entity=instance, group=backups, key=backups.retention.count)
+	// After each scheduled backup, the oldest scheduled backups are deleted so that at most this many are kept.
+	// Backups created manually are never deleted.
+	// When {config:option}`instance-backups:backups.target` is set, this applies to the scheduled backups uploaded to the target.
+	// ---
+	//  type: integer
+	//  defaultdesc: `0` (unlimited)
+	//  liveupdate: no
+	//  shortdesc: Number of scheduled backups to keep
+	"backups.retention.count": validate.Optional(validate.IsUint32),
+
+	// gendoc:generate(entity=instance, group=backups, key=backups.target)
+	// Specify the name of a backup target defined through the {config:option}`server-backups:backups.targets.NAME.url` server configuration.
+	//
+	// When set, scheduled backups are uploaded to that target instead of being stored on the server.
+	// Each backup is stored as `<prefix>/<project>/<instance>/auto-backup-<date>`.
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  shortdesc: S3 target for scheduled backups
+	"backups.target": validate.Optional(ValidateBackupTargetName),
+
 	// Volatile keys.
 
 	// gendoc:generate(entity=instance, group=volatile, key=volatile.apply_template)
@@ -1187,6 +1406,19 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 
 // InstanceConfigKeysVM is a map of config key to validator. (keys applying to VM only).
 var InstanceConfigKeysVM = map[string]func(value string) error{
+	// gendoc:generate(entity=instance, group=backups, key=backups.repository)
+	// Specify the name of a backup target defined through the {config:option}`server-backups:backups.targets.NAME.url` server configuration.
+	//
+	// When set, scheduled backups of the root disk are stored in that backup repository instead of being stored on the server.
+	// Only the chunks which changed since the previous backup are stored.
+	// It takes precedence over {config:option}`instance-backups:backups.target`.
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: Backup repository for scheduled backups
+	"backups.repository": validate.Optional(ValidateBackupRepository),
+
 	// gendoc:generate(entity=instance, group=resource-limits, key=limits.memory.hotplug)
 	// If this option is set to `false`, disable memory hotplug entirely.
 	// Alternatively, it can be set to a bytes value which will define an upper limit for hotplugged memory.
@@ -1199,6 +1431,50 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//  shortdesc: Control upper limit for hotplugged memory or disable memory hotplug.
 	"limits.memory.hotplug": validate.Optional(validate.Or(validate.IsBool, validate.IsSize)),
 
+	// gendoc:generate(entity=instance, group=resource-limits, key=limits.memory.balloon)
+	// If this option is set to `true`, Incus resizes the memory balloon of the VM automatically, reclaiming memory
+	// from it when the host is under memory pressure and returning it when the VM runs low on memory.
+	//
+	// See {ref}`instances-balloon` for more information.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Whether to automatically manage the memory balloon
+	"limits.memory.balloon": validate.Optional(validate.IsBool),
+
+	// gendoc:generate(entity=instance, group=resource-limits, key=limits.memory.balloon.min)
+	// Percentage of `limits.memory` or a fixed value in bytes.
+	// ---
+	//  type: string
+	//  defaultdesc: `50%`
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Minimum memory size the memory balloon can shrink the VM to
+	"limits.memory.balloon.min": validate.Optional(IsMemoryBalloonSize),
+
+	// gendoc:generate(entity=instance, group=resource-limits, key=limits.memory.balloon.target)
+	// Percentage of `limits.memory` or a fixed value in bytes.
+	// Memory is returned to the VM when its available memory drops below half of this value.
+	// ---
+	//  type: string
+	//  defaultdesc: `20%`
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Available memory to leave to the VM when reclaiming memory from it
+	"limits.memory.balloon.target": validate.Optional(IsMemoryBalloonSize),
+
+	// gendoc:generate(entity=instance, group=resource-limits, key=limits.memory.balloon.free_page_reporting)
+	// If this option is set to `true`, the VM reports the memory pages it doesn't use, so that the host can reclaim them.
+	// ---
+	//  type: bool
+	//  defaultdesc: `true`
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: Whether to enable free page reporting when the memory balloon is managed
+	"limits.memory.balloon.free_page_reporting": validate.Optional(validate.IsBool),
+
 	// gendoc:generate(entity=instance, group=resource-limits, key=limits.memory.hugepages)
 	// If this option is set to `false`, regular system memory is used.
 	// ---
@@ -1345,6 +1621,16 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//  shortdesc: The guest owner's `base64`-encoded session blob
 	"security.sev.session.data": validate.Optional(validate.IsAny),
 
+	// gendoc:generate(entity=instance, group=security, key=security.vnc)
+	// When enabled, the virtual machine gets a VNC server which is only reachable through `vnc` console sessions of the API.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: Whether the graphical console is available through VNC
+	"security.vnc": validate.Optional(validate.IsBool),
+
 	// gendoc:generate(entity=instance, group=miscellaneous, key=agent.nic_config)
 	// For containers, the name and MTU of the default network interfaces is used for the instance devices.
 	// For virtual machines, set this option to `true` to set the name and MTU of the default network interfaces to be the same as the instance devices.
@@ -1377,6 +1663,13 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//  shortdesc: Indicates that the VM needs a full reset on next reboot
 	"volatile.vm.needs_reset": validate.Optional(validate.IsBool),
 
+	// gendoc:generate(entity=instance, group=volatile, key=volatile.vm.previous_pool)
+	// Set after a live storage pool move until the volume left on the previous pool gets deleted when the instance stops.
+	// ---
+	//  type: string
+	//  shortdesc: Storage pool the VM was live moved from
+	"volatile.vm.previous_pool": validate.Optional(validate.IsAny),
+
 	// gendoc:generate(entity=instance, group=volatile, key=volatile.vm.rtc_adjustment)
 	// Real Time Clock adjustment time to allow virtual machines to run on a different base than the host.
 	// ---
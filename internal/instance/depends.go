@@ -0,0 +1,124 @@
+package instance
+
+import (
+	"strings"
+)
+
+// DependsList returns the names of the instances that an instance depends on from its "boot.depends" config.
+func DependsList(config map[string]string) []string {
+	var names []string
+
+	for _, name := range strings.Split(config["boot.depends"], ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// DependsLevels returns the level of each instance in the dependency graph, mapping an instance name to the
+// names of the instances it depends on. Instances without dependencies are at level 0 and the others one level
+// above their highest dependency, so that starting the instances level by level starts every instance after the
+// instances it depends on. Dependencies outside of the graph are ignored, as are dependency cycles.
+//
+// Stopping the instances in reverse is done by passing the graph of the instances depending on each instance.
+func DependsLevels(depends map[string][]string) map[string]int {
+	levels := make(map[string]int, len(depends))
+	visiting := map[string]bool{}
+
+	var level func(name string) int
+	level = func(name string) int {
+		result, ok := levels[name]
+		if ok {
+			return result
+		}
+
+		visiting[name] = true
+
+		for _, dependency := range depends[name] {
+			_, ok := depends[dependency]
+			if !ok || visiting[dependency] {
+				continue
+			}
+
+			result = max(result, level(dependency)+1)
+		}
+
+		visiting[name] = false
+		levels[name] = result
+
+		return result
+	}
+
+	for name := range depends {
+		level(name)
+	}
+
+	return levels
+}
+
+// DependsReverse returns the graph of the instances depending on each instance of the dependency graph.
+func DependsReverse(depends map[string][]string) map[string][]string {
+	dependents := make(map[string][]string, len(depends))
+
+	for name, dependencies := range depends {
+		_, ok := dependents[name]
+		if !ok {
+			dependents[name] = nil
+		}
+
+		for _, dependency := range dependencies {
+			_, ok := depends[dependency]
+			if !ok {
+				continue
+			}
+
+			dependents[dependency] = append(dependents[dependency], name)
+		}
+	}
+
+	return dependents
+}
+
+// DependsCycle returns a dependency cycle of the dependency graph going through the instance, as the names of the
+// instances from the instance back to itself, or nil if there's none.
+func DependsCycle(depends map[string][]string, name string) []string {
+	visited := map[string]bool{}
+	path := []string{}
+
+	var walk func(current string) bool
+	walk = func(current string) bool {
+		path = append(path, current)
+
+		for _, dependency := range depends[current] {
+			if dependency == name {
+				path = append(path, name)
+				return true
+			}
+
+			if visited[dependency] {
+				continue
+			}
+
+			visited[dependency] = true
+
+			if walk(dependency) {
+				return true
+			}
+		}
+
+		path = path[:len(path)-1]
+
+		return false
+	}
+
+	if !walk(name) {
+		return nil
+	}
+
+	return path
+}
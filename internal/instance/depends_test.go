@@ -0,0 +1,49 @@
+package instance_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lxc/incus/v7/internal/instance"
+)
+
+func TestDependsList(t *testing.T) {
+	assert.Equal(t, []string{"db", "cache"}, instance.DependsList(map[string]string{"boot.depends": "db, cache,"}))
+	assert.Empty(t, instance.DependsList(map[string]string{}))
+}
+
+func TestDependsLevels(t *testing.T) {
+	depends := map[string][]string{
+		"db":    nil,
+		"cache": nil,
+		"app":   {"db", "cache"},
+		"web":   {"app", "missing"},
+		"other": nil,
+	}
+
+	assert.Equal(t, map[string]int{"db": 0, "cache": 0, "app": 1, "web": 2, "other": 0}, instance.DependsLevels(depends))
+
+	// Stopping goes in reverse.
+	assert.Equal(t, map[string]int{"db": 2, "cache": 2, "app": 1, "web": 0, "other": 0}, instance.DependsLevels(instance.DependsReverse(depends)))
+
+	// Cycles don't loop forever.
+	levels := instance.DependsLevels(map[string][]string{"a": {"b"}, "b": {"a"}})
+	assert.Len(t, levels, 2)
+}
+
+func TestDependsCycle(t *testing.T) {
+	depends := map[string][]string{
+		"db":  nil,
+		"app": {"db", "cache"},
+		"web": {"app"},
+	}
+
+	assert.Nil(t, instance.DependsCycle(depends, "web"))
+
+	depends["db"] = []string{"web"}
+	assert.Equal(t, []string{"web", "app", "db", "web"}, instance.DependsCycle(depends, "web"))
+
+	// Instances can't depend on themselves.
+	assert.Equal(t, []string{"cache", "cache"}, instance.DependsCycle(map[string][]string{"cache": {"cache"}}, "cache"))
+}
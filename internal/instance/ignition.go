@@ -0,0 +1,101 @@
+package instance
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/lxc/incus/v7/internal/version"
+)
+
+// ignitionVersion returns the spec version of an Ignition config.
+func ignitionVersion(config string) (*version.DottedVersion, error) {
+	var header struct {
+		Ignition struct {
+			Version string `json:"version"`
+		} `json:"ignition"`
+	}
+
+	err := json.Unmarshal([]byte(config), &header)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid Ignition config: %w", err)
+	}
+
+	if header.Ignition.Version == "" {
+		return nil, errors.New("Invalid Ignition config: Missing ignition.version")
+	}
+
+	specVersion, err := version.NewDottedVersion(header.Ignition.Version)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid Ignition config: %w", err)
+	}
+
+	if specVersion.Major != 2 && specVersion.Major != 3 {
+		return nil, fmt.Errorf("Unsupported Ignition config version %q", header.Ignition.Version)
+	}
+
+	return specVersion, nil
+}
+
+// IsIgnitionConfig validates an Ignition config.
+func IsIgnitionConfig(value string) error {
+	_, err := ignitionVersion(value)
+
+	return err
+}
+
+// IgnitionMerge combines the Ignition configs into a single one, the later configs taking precedence.
+// A single config is returned as is, while several configs are referenced by a parent config as merged
+// (or appended for version 2) configs.
+func IgnitionMerge(configs []string) (string, error) {
+	if len(configs) == 0 {
+		return "", nil
+	}
+
+	if len(configs) == 1 {
+		return configs[0], nil
+	}
+
+	var parentVersion *version.DottedVersion
+	sources := make([]map[string]string, 0, len(configs))
+
+	for _, config := range configs {
+		specVersion, err := ignitionVersion(config)
+		if err != nil {
+			return "", err
+		}
+
+		if parentVersion != nil && specVersion.Major != parentVersion.Major {
+			return "", errors.New("Ignition configs of different major versions can't be combined")
+		}
+
+		if parentVersion == nil || specVersion.Compare(parentVersion) > 0 {
+			parentVersion = specVersion
+		}
+
+		sources = append(sources, map[string]string{"source": "data:;base64," + base64.StdEncoding.EncodeToString([]byte(config))})
+	}
+
+	// Version 2 appends configs while version 3 merges them.
+	key := "merge"
+	if parentVersion.Major == 2 {
+		key = "append"
+	}
+
+	parent := map[string]any{
+		"ignition": map[string]any{
+			"version": parentVersion.String(),
+			"config": map[string]any{
+				key: sources,
+			},
+		},
+	}
+
+	content, err := json.Marshal(parent)
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
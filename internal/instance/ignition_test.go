@@ -0,0 +1,45 @@
+package instance_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v7/internal/instance"
+)
+
+func TestIsIgnitionConfig(t *testing.T) {
+	assert.NoError(t, instance.IsIgnitionConfig(`{"ignition": {"version": "3.4.0"}}`))
+	assert.NoError(t, instance.IsIgnitionConfig(`{"ignition": {"version": "2.3.0"}}`))
+
+	for _, value := range []string{"", "#cloud-config", `{"ignition": {}}`, `{"ignition": {"version": "1.0.0"}}`, `{"ignition": {"version": "abc"}}`} {
+		assert.Error(t, instance.IsIgnitionConfig(value), value)
+	}
+}
+
+func TestIgnitionMerge(t *testing.T) {
+	profile := `{"ignition": {"version": "3.3.0"}}`
+	local := `{"ignition": {"version": "3.4.0"}, "passwd": {}}`
+
+	merged, err := instance.IgnitionMerge(nil)
+	require.NoError(t, err)
+	assert.Empty(t, merged)
+
+	merged, err = instance.IgnitionMerge([]string{local})
+	require.NoError(t, err)
+	assert.Equal(t, local, merged)
+
+	merged, err = instance.IgnitionMerge([]string{profile, local})
+	require.NoError(t, err)
+
+	source := func(config string) string {
+		return "data:;base64," + base64.StdEncoding.EncodeToString([]byte(config))
+	}
+
+	assert.JSONEq(t, `{"ignition": {"version": "3.4.0", "config": {"merge": [{"source": "`+source(profile)+`"}, {"source": "`+source(local)+`"}]}}}`, merged)
+
+	_, err = instance.IgnitionMerge([]string{`{"ignition": {"version": "2.3.0"}}`, local})
+	assert.Error(t, err)
+}
@@ -0,0 +1,149 @@
+package instance
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flosch/pongo2/v6"
+
+	internalUtil "github.com/lxc/incus/v7/internal/util"
+)
+
+// ErrInvalidRetention is returned if the provided retention policy cannot be parsed.
+var ErrInvalidRetention = errors.New("Invalid retention policy")
+
+// snapshotRetentionPeriods maps each retention period to the function bucketing a date into it.
+var snapshotRetentionPeriods = map[string]func(t time.Time) string{
+	"latest": func(t time.Time) string { return t.Format(time.RFC3339Nano) },
+	"hourly": func(t time.Time) string { return t.Format("2006-01-02 15") },
+	"daily":  func(t time.Time) string { return t.Format("2006-01-02") },
+	"weekly": func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-%d", year, week)
+	},
+	"monthly": func(t time.Time) string { return t.Format("2006-01") },
+	"yearly":  func(t time.Time) string { return t.Format("2006") },
+}
+
+// SnapshotRetention is a count-based retention policy, mapping a period to the number of periods to keep.
+type SnapshotRetention map[string]int
+
+// ParseSnapshotRetention parses a retention policy.
+// The format is a comma-separated list of "<count> <period>" with period being one of
+// "latest", "hourly", "daily", "weekly", "monthly" or "yearly", e.g. "24 hourly, 7 daily, 4 weekly".
+func ParseSnapshotRetention(s string) (SnapshotRetention, error) {
+	retention := SnapshotRetention{}
+
+	for _, rule := range strings.Split(s, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		fields := strings.Fields(rule)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidRetention, rule)
+		}
+
+		count, err := strconv.Atoi(fields[0])
+		if err != nil || count <= 0 {
+			return nil, fmt.Errorf("%w: Invalid count %q", ErrInvalidRetention, fields[0])
+		}
+
+		_, ok := snapshotRetentionPeriods[fields[1]]
+		if !ok {
+			return nil, fmt.Errorf("%w: Unknown period %q", ErrInvalidRetention, fields[1])
+		}
+
+		_, ok = retention[fields[1]]
+		if ok {
+			// We don't allow periods to be set multiple times.
+			return nil, fmt.Errorf("%w: Period %q set multiple times", ErrInvalidRetention, fields[1])
+		}
+
+		retention[fields[1]] = count
+	}
+
+	return retention, nil
+}
+
+// IsSnapshotNameFromPattern reports whether a snapshot created at creationDate is named after the snapshot
+// naming pattern (defaulting to "snap%d"), like the snapshots created through scheduling.
+func IsSnapshotNameFromPattern(pattern string, name string, creationDate time.Time) bool {
+	if pattern == "" {
+		pattern = "snap%d"
+	}
+
+	rendered, err := internalUtil.RenderTemplate(pattern, pongo2.Context{
+		"creation_date": creationDate.Local(),
+	})
+	if err != nil {
+		return false
+	}
+
+	// Without a counter in the pattern, one is appended when the name is already in use.
+	expr := regexp.QuoteMeta(rendered)
+	if strings.Contains(expr, "%d") {
+		expr = strings.Replace(expr, "%d", `\d+`, 1)
+	} else {
+		expr += `(-\d+)?`
+	}
+
+	matched, err := regexp.MatchString("^"+expr+"$", name)
+
+	return err == nil && matched
+}
+
+// Prune returns the indexes of the creation dates which aren't kept by the policy.
+// For each period, the most recent date of each of the last <count> periods having a date is kept.
+// An empty policy keeps everything.
+func (r SnapshotRetention) Prune(creationDates []time.Time) []int {
+	if len(r) == 0 {
+		return nil
+	}
+
+	// Walk the dates newest first.
+	order := make([]int, len(creationDates))
+	for i := range order {
+		order[i] = i
+	}
+
+	slices.SortStableFunc(order, func(a int, b int) int {
+		return creationDates[b].Compare(creationDates[a])
+	})
+
+	keep := make([]bool, len(creationDates))
+
+	for period, count := range r {
+		bucket := snapshotRetentionPeriods[period]
+		seen := map[string]bool{}
+
+		for _, i := range order {
+			if len(seen) >= count {
+				break
+			}
+
+			key := bucket(creationDates[i].Local())
+			if seen[key] {
+				continue
+			}
+
+			seen[key] = true
+			keep[i] = true
+		}
+	}
+
+	var prune []int
+	for i, kept := range keep {
+		if !kept {
+			prune = append(prune, i)
+		}
+	}
+
+	return prune
+}
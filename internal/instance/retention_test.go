@@ -0,0 +1,59 @@
+package instance_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v7/internal/instance"
+)
+
+func TestParseSnapshotRetention(t *testing.T) {
+	retention, err := instance.ParseSnapshotRetention("24 hourly, 7 daily,4 weekly")
+	require.NoError(t, err)
+	assert.Equal(t, instance.SnapshotRetention{"hourly": 24, "daily": 7, "weekly": 4}, retention)
+
+	retention, err = instance.ParseSnapshotRetention("")
+	require.NoError(t, err)
+	assert.Empty(t, retention)
+
+	for _, value := range []string{"hourly", "24", "-1 daily", "2 fortnightly", "1 daily, 2 daily", "1 daily 2", "0 daily"} {
+		_, err := instance.ParseSnapshotRetention(value)
+		assert.ErrorIs(t, err, instance.ErrInvalidRetention, value)
+	}
+}
+
+func TestSnapshotRetentionPrune(t *testing.T) {
+	base := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.Local)
+
+	// Snapshots every 6 hours over 3 days, oldest first.
+	var dates []time.Time
+	for i := 11; i >= 0; i-- {
+		dates = append(dates, base.Add(-time.Duration(i)*6*time.Hour))
+	}
+
+	retention, err := instance.ParseSnapshotRetention("2 latest, 3 daily")
+	require.NoError(t, err)
+
+	// Keep the 2 newest (11 and 10) and the newest of each of the last 3 days,
+	// which are 11 (March 10th), 8 (March 9th) and 4 (March 8th).
+	assert.Equal(t, []int{0, 1, 2, 3, 5, 6, 7, 9}, retention.Prune(dates))
+
+	assert.Empty(t, instance.SnapshotRetention{}.Prune(dates))
+}
+
+func TestIsSnapshotNameFromPattern(t *testing.T) {
+	created := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.Local)
+
+	assert.True(t, instance.IsSnapshotNameFromPattern("", "snap12", created))
+	assert.False(t, instance.IsSnapshotNameFromPattern("", "before-upgrade", created))
+	assert.False(t, instance.IsSnapshotNameFromPattern("", "snap", created))
+
+	pattern := `auto-{{ creation_date|date:"2006-01-02" }}`
+	assert.True(t, instance.IsSnapshotNameFromPattern(pattern, "auto-2026-03-10", created))
+	assert.True(t, instance.IsSnapshotNameFromPattern(pattern, "auto-2026-03-10-1", created))
+	assert.False(t, instance.IsSnapshotNameFromPattern(pattern, "auto-2026-03-09", created))
+	assert.False(t, instance.IsSnapshotNameFromPattern(pattern, "manual", created))
+}
@@ -26,7 +26,7 @@ func AvailableStorageDrivers(path string, supportedDrivers []api.ServerStorageDr
 			continue
 		}
 
-		if poolType == util.PoolTypeAny && (driver.Name == "cephfs" || driver.Name == "cephobject") {
+		if poolType == util.PoolTypeAny && (driver.Name == "cephfs" || driver.Name == "cephobject" || driver.Name == "nfs") {
 			continue
 		}
 
@@ -154,7 +154,8 @@ func UpdateInstanceConfig(c *db.Cluster, b Info, mountPath string) error {
 
 	rootDiskDeviceFound := false
 
-	// Change the pool in the backup.yaml.
+	// Change the pool in the backup.yaml, leaving out the encryption master key of its volumes.
+	delete(pool.Config, "volatile.encryption.master_key")
 	backup.Pool = pool
 
 	if backup.Container != nil && updateRootDevicePool(backup.Container.Devices, pool.Name) {
@@ -6,12 +6,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"net/url"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/transfermanager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 
@@ -20,7 +18,6 @@ import (
 	"github.com/lxc/incus/v7/shared/api"
 	"github.com/lxc/incus/v7/shared/archive"
 	"github.com/lxc/incus/v7/shared/logger"
-	localtls "github.com/lxc/incus/v7/shared/tls"
 )
 
 // TarReader rewinds backup file handle r and returns new tar reader and process cleanup function.
@@ -63,27 +60,7 @@ func Upload(reader *io.PipeReader, req *api.BackupTarget) error {
 		return err
 	}
 
-	// Get a basic TLS client.
-	tlsConfig := localtls.InitTLSConfig()
-
-	// Setup the transport.
-	ts := &http.Transport{
-		MaxIdleConns:       10,
-		IdleConnTimeout:    30 * time.Second,
-		DisableCompression: true,
-		TLSClientConfig:    tlsConfig,
-	}
-
-	cfg := aws.Config{
-		Region:      s3util.RegionFromURL(uri),
-		Credentials: credentials.NewStaticCredentialsProvider(req.AccessKey, req.SecretKey, ""),
-		HTTPClient:  &http.Client{Transport: ts},
-	}
-
-	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
-		o.BaseEndpoint = aws.String(fmt.Sprintf("%s://%s", uri.Scheme, uri.Host))
-		o.UsePathStyle = true
-	})
+	client := s3util.NewClient(uri, req.AccessKey, req.SecretKey)
 
 	uploader := transfermanager.New(client)
 
@@ -98,3 +75,57 @@ func Upload(reader *io.PipeReader, req *api.BackupTarget) error {
 
 	return nil
 }
+
+// ListUploads returns the last modification time of the objects of the backup target whose key starts with prefix.
+func ListUploads(req *api.BackupTarget, prefix string) (map[string]time.Time, error) {
+	if req.Protocol != "s3" {
+		return nil, fmt.Errorf("Unsupported backup target protocol %q", req.Protocol)
+	}
+
+	uri, err := url.Parse(req.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	client := s3util.NewClient(uri, req.AccessKey, req.SecretKey)
+
+	paginator := s3.NewListObjectsV2Paginator(client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(req.BucketName),
+		Prefix: aws.String(prefix),
+	})
+
+	uploads := map[string]time.Time{}
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range page.Contents {
+			uploads[aws.ToString(obj.Key)] = aws.ToTime(obj.LastModified)
+		}
+	}
+
+	return uploads, nil
+}
+
+// DeleteUpload deletes an object of the backup target.
+func DeleteUpload(req *api.BackupTarget, key string) error {
+	if req.Protocol != "s3" {
+		return fmt.Errorf("Unsupported backup target protocol %q", req.Protocol)
+	}
+
+	uri, err := url.Parse(req.URL)
+	if err != nil {
+		return err
+	}
+
+	client := s3util.NewClient(uri, req.AccessKey, req.SecretKey)
+
+	_, err = client.DeleteObject(context.Background(), &s3.DeleteObjectInput{
+		Bucket: aws.String(req.BucketName),
+		Key:    aws.String(key),
+	})
+
+	return err
+}
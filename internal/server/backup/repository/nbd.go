@@ -0,0 +1,469 @@
+package repository
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// NBD protocol constants, see https://github.com/NetworkBlockDevice/nbd/blob/master/doc/proto.md.
+const (
+	nbdMagic                = 0x4e42444d41474943 // NBDMAGIC
+	nbdOptMagic             = 0x49484156454f5054 // IHAVEOPT
+	nbdRepMagic             = 0x0003e889045565a9
+	nbdRequestMagic         = 0x25609513
+	nbdSimpleReplyMagic     = 0x67446698
+	nbdStructuredReplyMagic = 0x668e33ef
+
+	nbdFlagFixedNewstyle   = 1 << 0
+	nbdFlagNoZeroes        = 1 << 1
+	nbdFlagReadOnly        = 1 << 1
+	nbdFlagSendFlush       = 1 << 2
+	nbdFlagSendWriteZeroes = 1 << 6
+
+	nbdOptGo              = 7
+	nbdOptStructuredReply = 8
+	nbdOptSetMetaContext  = 10
+
+	nbdRepAck         = 1
+	nbdRepInfo        = 3
+	nbdRepMetaContext = 4
+	nbdRepFlagError   = 1 << 31
+
+	nbdInfoExport = 0
+
+	nbdCmdRead        = 0
+	nbdCmdWrite       = 1
+	nbdCmdDisc        = 2
+	nbdCmdFlush       = 3
+	nbdCmdWriteZeroes = 6
+	nbdCmdBlockStatus = 7
+
+	nbdReplyFlagDone        = 1 << 0
+	nbdReplyTypeNone        = 0
+	nbdReplyTypeOffsetData  = 1
+	nbdReplyTypeOffsetHole  = 2
+	nbdReplyTypeBlockStatus = 5
+	nbdReplyTypeError       = 1 << 15
+
+	nbdStateDirty = 1 << 0
+
+	// nbdMaxPayload is the largest reply payload which is accepted from the server.
+	nbdMaxPayload = 64 * 1024 * 1024
+)
+
+// nbdClient is a minimal client of the NBD protocol, as served by QEMU and qemu-nbd.
+type nbdClient struct {
+	conn       io.ReadWriter
+	size       int64
+	flags      uint16
+	structured bool
+	contextID  uint32
+	hasContext bool
+	handle     uint64
+}
+
+// newNBDClient negotiates the default export on conn, requesting the given metadata context if not empty.
+func newNBDClient(conn io.ReadWriter, metaContext string) (*nbdClient, error) {
+	c := &nbdClient{conn: conn}
+
+	var hello struct {
+		Magic    uint64
+		OptMagic uint64
+		Flags    uint16
+	}
+
+	err := binary.Read(conn, binary.BigEndian, &hello)
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading NBD handshake: %w", err)
+	}
+
+	if hello.Magic != nbdMagic || hello.OptMagic != nbdOptMagic || hello.Flags&nbdFlagFixedNewstyle == 0 {
+		return nil, errors.New("Unsupported NBD handshake")
+	}
+
+	clientFlags := uint32(nbdFlagFixedNewstyle)
+	if hello.Flags&nbdFlagNoZeroes != 0 {
+		clientFlags |= nbdFlagNoZeroes
+	}
+
+	err = binary.Write(conn, binary.BigEndian, clientFlags)
+	if err != nil {
+		return nil, err
+	}
+
+	// Metadata contexts can only be queried with structured replies.
+	if metaContext != "" {
+		err = c.sendOption(nbdOptStructuredReply, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		replyType, _, err := c.readOptionReply(nbdOptStructuredReply)
+		if err != nil {
+			return nil, err
+		}
+
+		c.structured = replyType == nbdRepAck
+	}
+
+	if c.structured {
+		data := binary.BigEndian.AppendUint32(nil, 0) // Default export.
+		data = binary.BigEndian.AppendUint32(data, 1)
+		data = binary.BigEndian.AppendUint32(data, uint32(len(metaContext)))
+		data = append(data, metaContext...)
+
+		err = c.sendOption(nbdOptSetMetaContext, data)
+		if err != nil {
+			return nil, err
+		}
+
+		for {
+			replyType, payload, err := c.readOptionReply(nbdOptSetMetaContext)
+			if err != nil {
+				return nil, err
+			}
+
+			if replyType != nbdRepMetaContext || len(payload) < 4 {
+				break
+			}
+
+			c.contextID = binary.BigEndian.Uint32(payload)
+			c.hasContext = true
+		}
+	}
+
+	// Select the default export without requesting any extra information.
+	data := binary.BigEndian.AppendUint32(nil, 0)
+	data = binary.BigEndian.AppendUint16(data, 0)
+
+	err = c.sendOption(nbdOptGo, data)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		replyType, payload, err := c.readOptionReply(nbdOptGo)
+		if err != nil {
+			return nil, err
+		}
+
+		if replyType&nbdRepFlagError != 0 {
+			return nil, fmt.Errorf("NBD server refused the export: %s", payload)
+		}
+
+		if replyType == nbdRepAck {
+			break
+		}
+
+		if replyType == nbdRepInfo && len(payload) >= 12 && binary.BigEndian.Uint16(payload) == nbdInfoExport {
+			c.size = int64(binary.BigEndian.Uint64(payload[2:]))
+			c.flags = binary.BigEndian.Uint16(payload[10:])
+		}
+	}
+
+	return c, nil
+}
+
+// sendOption sends a handshake option.
+func (c *nbdClient) sendOption(option uint32, data []byte) error {
+	buf := binary.BigEndian.AppendUint64(nil, nbdOptMagic)
+	buf = binary.BigEndian.AppendUint32(buf, option)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(data)))
+	buf = append(buf, data...)
+
+	_, err := c.conn.Write(buf)
+	if err != nil {
+		return fmt.Errorf("Failed sending NBD option %d: %w", option, err)
+	}
+
+	return nil
+}
+
+// readOptionReply reads the next reply to the given handshake option.
+func (c *nbdClient) readOptionReply(option uint32) (uint32, []byte, error) {
+	var hdr struct {
+		Magic  uint64
+		Option uint32
+		Type   uint32
+		Length uint32
+	}
+
+	err := binary.Read(c.conn, binary.BigEndian, &hdr)
+	if err != nil {
+		return 0, nil, fmt.Errorf("Failed reading NBD option reply: %w", err)
+	}
+
+	if hdr.Magic != nbdRepMagic || hdr.Option != option || hdr.Length > nbdMaxPayload {
+		return 0, nil, errors.New("Invalid NBD option reply")
+	}
+
+	payload := make([]byte, hdr.Length)
+	_, err = io.ReadFull(c.conn, payload)
+	if err != nil {
+		return 0, nil, fmt.Errorf("Failed reading NBD option reply: %w", err)
+	}
+
+	return hdr.Type, payload, nil
+}
+
+// request sends a transmission request and returns its handle.
+func (c *nbdClient) request(command uint16, offset int64, length uint32, data []byte) (uint64, error) {
+	c.handle++
+
+	buf := binary.BigEndian.AppendUint32(nil, nbdRequestMagic)
+	buf = binary.BigEndian.AppendUint16(buf, 0)
+	buf = binary.BigEndian.AppendUint16(buf, command)
+	buf = binary.BigEndian.AppendUint64(buf, c.handle)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(offset))
+	buf = binary.BigEndian.AppendUint32(buf, length)
+	buf = append(buf, data...)
+
+	_, err := c.conn.Write(buf)
+	if err != nil {
+		return 0, fmt.Errorf("Failed sending NBD request: %w", err)
+	}
+
+	return c.handle, nil
+}
+
+// readReply reads the reply to the request with the given handle. The payload of simple replies is read into
+// data while the chunks of structured replies are handed to chunkFunc.
+func (c *nbdClient) readReply(handle uint64, data []byte, chunkFunc func(chunkType uint16, payload []byte) error) error {
+	for {
+		var magic uint32
+
+		err := binary.Read(c.conn, binary.BigEndian, &magic)
+		if err != nil {
+			return fmt.Errorf("Failed reading NBD reply: %w", err)
+		}
+
+		switch magic {
+		case nbdSimpleReplyMagic:
+			var hdr struct {
+				Error  uint32
+				Handle uint64
+			}
+
+			err = binary.Read(c.conn, binary.BigEndian, &hdr)
+			if err != nil {
+				return fmt.Errorf("Failed reading NBD reply: %w", err)
+			}
+
+			if hdr.Handle != handle {
+				return errors.New("Unexpected NBD reply handle")
+			}
+
+			if hdr.Error != 0 {
+				return fmt.Errorf("NBD request failed with error %d", hdr.Error)
+			}
+
+			if data != nil {
+				_, err = io.ReadFull(c.conn, data)
+				if err != nil {
+					return fmt.Errorf("Failed reading NBD reply: %w", err)
+				}
+			}
+
+			return nil
+		case nbdStructuredReplyMagic:
+			var hdr struct {
+				Flags  uint16
+				Type   uint16
+				Handle uint64
+				Length uint32
+			}
+
+			err = binary.Read(c.conn, binary.BigEndian, &hdr)
+			if err != nil {
+				return fmt.Errorf("Failed reading NBD reply: %w", err)
+			}
+
+			if hdr.Handle != handle || hdr.Length > nbdMaxPayload {
+				return errors.New("Invalid NBD reply")
+			}
+
+			payload := make([]byte, hdr.Length)
+			_, err = io.ReadFull(c.conn, payload)
+			if err != nil {
+				return fmt.Errorf("Failed reading NBD reply: %w", err)
+			}
+
+			if hdr.Type&nbdReplyTypeError != 0 {
+				if len(payload) < 6 {
+					return errors.New("NBD request failed")
+				}
+
+				msgLen := min(int(binary.BigEndian.Uint16(payload[4:])), len(payload)-6)
+
+				return fmt.Errorf("NBD request failed with error %d: %s", binary.BigEndian.Uint32(payload), payload[6:6+msgLen])
+			}
+
+			if hdr.Type != nbdReplyTypeNone {
+				err = chunkFunc(hdr.Type, payload)
+				if err != nil {
+					return err
+				}
+			}
+
+			if hdr.Flags&nbdReplyFlagDone != 0 {
+				return nil
+			}
+
+		default:
+			return errors.New("Invalid NBD reply magic")
+		}
+	}
+}
+
+// ReadAt reads len(p) bytes from the export at the given offset.
+func (c *nbdClient) ReadAt(p []byte, off int64) (int, error) {
+	handle, err := c.request(nbdCmdRead, off, uint32(len(p)), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	err = c.readReply(handle, p, func(chunkType uint16, payload []byte) error {
+		if len(payload) < 8 {
+			return errors.New("Invalid NBD read reply")
+		}
+
+		start := int64(binary.BigEndian.Uint64(payload)) - off
+
+		switch chunkType {
+		case nbdReplyTypeOffsetData:
+			if start < 0 || start+int64(len(payload)-8) > int64(len(p)) {
+				return errors.New("Invalid NBD read reply offset")
+			}
+
+			copy(p[start:], payload[8:])
+		case nbdReplyTypeOffsetHole:
+			if len(payload) < 12 {
+				return errors.New("Invalid NBD read reply")
+			}
+
+			end := start + int64(binary.BigEndian.Uint32(payload[8:]))
+			if start < 0 || end > int64(len(p)) {
+				return errors.New("Invalid NBD read reply offset")
+			}
+
+			clear(p[start:end])
+		default:
+			return fmt.Errorf("Unexpected NBD read reply type %d", chunkType)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// WriteAt writes p to the export at the given offset.
+func (c *nbdClient) WriteAt(p []byte, off int64) (int, error) {
+	handle, err := c.request(nbdCmdWrite, off, uint32(len(p)), p)
+	if err != nil {
+		return 0, err
+	}
+
+	err = c.readReply(handle, nil, func(chunkType uint16, payload []byte) error {
+		return fmt.Errorf("Unexpected NBD write reply type %d", chunkType)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// WriteZeroes zeroes the given range of the export.
+func (c *nbdClient) WriteZeroes(off int64, length int64) error {
+	if c.flags&nbdFlagSendWriteZeroes == 0 {
+		_, err := c.WriteAt(make([]byte, length), off)
+		return err
+	}
+
+	handle, err := c.request(nbdCmdWriteZeroes, off, uint32(length), nil)
+	if err != nil {
+		return err
+	}
+
+	return c.readReply(handle, nil, func(chunkType uint16, payload []byte) error {
+		return fmt.Errorf("Unexpected NBD write reply type %d", chunkType)
+	})
+}
+
+// Flush makes sure that all writes reached permanent storage.
+func (c *nbdClient) Flush() error {
+	if c.flags&nbdFlagReadOnly != 0 || c.flags&nbdFlagSendFlush == 0 {
+		return nil
+	}
+
+	handle, err := c.request(nbdCmdFlush, 0, 0, nil)
+	if err != nil {
+		return err
+	}
+
+	return c.readReply(handle, nil, func(chunkType uint16, payload []byte) error {
+		return fmt.Errorf("Unexpected NBD flush reply type %d", chunkType)
+	})
+}
+
+// dirty returns whether any part of the given range is flagged in the negotiated metadata context.
+func (c *nbdClient) dirty(off int64, length int64) (bool, error) {
+	for length > 0 {
+		handle, err := c.request(nbdCmdBlockStatus, off, uint32(length), nil)
+		if err != nil {
+			return false, err
+		}
+
+		var covered int64
+		dirty := false
+
+		err = c.readReply(handle, nil, func(chunkType uint16, payload []byte) error {
+			if chunkType != nbdReplyTypeBlockStatus || len(payload) < 4 {
+				return fmt.Errorf("Unexpected NBD block status reply type %d", chunkType)
+			}
+
+			if binary.BigEndian.Uint32(payload) != c.contextID {
+				return nil
+			}
+
+			for i := 4; i+8 <= len(payload); i += 8 {
+				covered += int64(binary.BigEndian.Uint32(payload[i:]))
+
+				if binary.BigEndian.Uint32(payload[i+4:])&nbdStateDirty != 0 {
+					dirty = true
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return false, err
+		}
+
+		if dirty {
+			return true, nil
+		}
+
+		if covered <= 0 {
+			return false, errors.New("NBD block status reply didn't cover the requested range")
+		}
+
+		off += covered
+		length -= covered
+	}
+
+	return false, nil
+}
+
+// Close ends the NBD session.
+func (c *nbdClient) Close() error {
+	_, err := c.request(nbdCmdDisc, 0, 0, nil)
+
+	return err
+}
@@ -0,0 +1,480 @@
+// Package repository implements a content-addressed store for backups of block volumes.
+//
+// Volumes are split into fixed size chunks which are stored compressed under their SHA-256 hash, so that chunks
+// shared between backups (or between volumes) are only stored once. Each backup is described by a manifest
+// listing the hashes of its chunks. When the source tracks changes (through a dirty bitmap), only the changed
+// chunks need to be read, the other ones being taken from the previous backup.
+package repository
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/lxc/incus/v7/shared/api"
+)
+
+// defaultChunkSize is the size of the chunks volumes are split into.
+const defaultChunkSize = 4 * 1024 * 1024
+
+// pruneGracePeriod is how long unreferenced chunks are kept, giving backups running concurrently with a prune
+// the time to store their manifest. Backups refresh the modification time of the existing chunks they reuse.
+const pruneGracePeriod = 24 * time.Hour
+
+// touchPeriod is the age after which the modification time of a reused chunk gets refreshed.
+const touchPeriod = time.Hour
+
+// Manifest describes a backup stored in a repository.
+type Manifest struct {
+	// Name of the backup.
+	Name string `json:"name"`
+
+	// When the backup was created.
+	CreatedAt time.Time `json:"created_at"`
+
+	// Size of the volume in bytes.
+	Size int64 `json:"size"`
+
+	// Size of the chunks in bytes.
+	ChunkSize int64 `json:"chunk_size"`
+
+	// Name of the dirty bitmap tracking the changes since the backup (if any).
+	Bitmap string `json:"bitmap,omitempty"`
+
+	// SHA-256 hash of each chunk of the volume, empty for chunks only holding zeroes.
+	Chunks []string `json:"chunks"`
+}
+
+// validate checks that the manifest is consistent with the volume size and only references valid chunks.
+func (m *Manifest) validate() error {
+	if m.Size < 0 || m.ChunkSize <= 0 {
+		return fmt.Errorf("Invalid size %d or chunk size %d", m.Size, m.ChunkSize)
+	}
+
+	if int64(len(m.Chunks)) != (m.Size+m.ChunkSize-1)/m.ChunkSize {
+		return fmt.Errorf("Expected %d chunks but got %d", (m.Size+m.ChunkSize-1)/m.ChunkSize, len(m.Chunks))
+	}
+
+	for _, hash := range m.Chunks {
+		if hash != "" && !validHash(hash) {
+			return fmt.Errorf("Invalid chunk hash %q", hash)
+		}
+	}
+
+	return nil
+}
+
+// Repository is a backup repository.
+type Repository struct {
+	store     store
+	chunkSize int64
+	encoder   *zstd.Encoder
+	decoder   *zstd.Decoder
+}
+
+// Open returns the backup repository stored in the local directory at path.
+func Open(path string) (*Repository, error) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("Backup repository path %q isn't absolute", path)
+	}
+
+	if filepath.Clean(path) != path {
+		return nil, fmt.Errorf("Backup repository path %q isn't clean", path)
+	}
+
+	return newRepository(&dirStore{path: path})
+}
+
+// OpenTarget returns the backup repository stored in the local directory or the S3 bucket of target.
+func OpenTarget(target *api.BackupTarget) (*Repository, error) {
+	if target.Protocol == "local" {
+		return Open(target.Path)
+	}
+
+	s, err := newS3Store(target)
+	if err != nil {
+		return nil, err
+	}
+
+	return newRepository(s)
+}
+
+// newRepository returns a repository using the given store.
+func newRepository(s store) (*Repository, error) {
+	r := &Repository{store: s, chunkSize: defaultChunkSize}
+
+	var err error
+
+	r.encoder, err = zstd.NewWriter(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r.decoder, err = zstd.NewReader(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Close releases the resources used by the repository.
+func (r *Repository) Close() {
+	_ = r.encoder.Close()
+	r.decoder.Close()
+}
+
+// VolumeKey returns the key under which the backups of a volume are stored.
+func VolumeKey(projectName string, volumeType string, volumeName string) string {
+	return path.Join(projectName, volumeType, volumeName)
+}
+
+// manifestKey returns the store key of the manifest of a backup, making sure it stays within the manifests of
+// the volume.
+func manifestKey(volume string, name string) (string, error) {
+	for _, elem := range strings.Split(volume, "/") {
+		if elem == "" || elem == "." || elem == ".." {
+			return "", fmt.Errorf("Invalid backup volume key %q", volume)
+		}
+	}
+
+	if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+		return "", fmt.Errorf("Invalid backup name %q", name)
+	}
+
+	return "manifests/" + volume + "/" + name + ".json", nil
+}
+
+// validHash returns whether hash is a hex encoded SHA-256 hash.
+func validHash(hash string) bool {
+	if len(hash) != hex.EncodedLen(sha256.Size) {
+		return false
+	}
+
+	_, err := hex.DecodeString(hash)
+
+	return err == nil && strings.ToLower(hash) == hash
+}
+
+// chunkKey returns the store key of the chunk with the given hash, which must have been checked by validHash.
+func chunkKey(hash string) string {
+	return "chunks/" + hash[:2] + "/" + hash
+}
+
+// Backups returns the names of the backups of the volume, oldest first.
+func (r *Repository) Backups(volume string) ([]string, error) {
+	objects, err := r.store.List("manifests/" + volume + "/")
+	if err != nil {
+		return nil, fmt.Errorf("Failed listing backups: %w", err)
+	}
+
+	var names []string
+	for _, obj := range objects {
+		name, ok := strings.CutSuffix(strings.TrimPrefix(obj.Key, "manifests/"+volume+"/"), ".json")
+		if !ok || strings.Contains(name, "/") {
+			continue
+		}
+
+		names = append(names, name)
+	}
+
+	// Backup names are creation timestamps.
+	slices.Sort(names)
+
+	return names, nil
+}
+
+// Manifest returns the manifest of a backup of the volume.
+func (r *Repository) Manifest(volume string, name string) (*Manifest, error) {
+	key, err := manifestKey(volume, name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := r.store.Get(key)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("Backup %q not found", name)
+	} else if err != nil {
+		return nil, fmt.Errorf("Failed reading manifest of backup %q: %w", name, err)
+	}
+
+	manifest := &Manifest{}
+
+	err = json.Unmarshal(data, manifest)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing manifest of backup %q: %w", name, err)
+	}
+
+	err = manifest.validate()
+	if err != nil {
+		return nil, fmt.Errorf("Invalid manifest of backup %q: %w", name, err)
+	}
+
+	return manifest, nil
+}
+
+// Backup stores the content of src as a new backup of the volume and returns its manifest.
+// If previous is set, the chunks which src doesn't report as changed are taken from it instead of being read.
+func (r *Repository) Backup(volume string, name string, bitmap string, src Source, previous *Manifest, progress func(percent int64)) (*Manifest, error) {
+	key, err := manifestKey(volume, name)
+	if err != nil {
+		return nil, err
+	}
+
+	size := src.Size()
+
+	manifest := &Manifest{
+		Name:      name,
+		CreatedAt: time.Now().UTC(),
+		Size:      size,
+		ChunkSize: r.chunkSize,
+		Bitmap:    bitmap,
+		Chunks:    make([]string, 0, (size+r.chunkSize-1)/r.chunkSize),
+	}
+
+	// Chunks can only be taken from a previous backup with the same layout.
+	if previous != nil && (previous.Size != size || previous.ChunkSize != r.chunkSize || previous.validate() != nil) {
+		previous = nil
+	}
+
+	buf := make([]byte, r.chunkSize)
+	zero := make([]byte, r.chunkSize)
+	lastPercent := int64(-1)
+
+	for off := int64(0); off < size; off += r.chunkSize {
+		chunk := buf[:min(r.chunkSize, size-off)]
+		hash := ""
+
+		changed := true
+		if previous != nil {
+			var err error
+
+			changed, err = src.Changed(off, int64(len(chunk)))
+			if err != nil {
+				return nil, fmt.Errorf("Failed getting changes at offset %d: %w", off, err)
+			}
+
+			// Chunks taken from the previous backup stay referenced by its manifest.
+			if !changed {
+				hash = previous.Chunks[off/r.chunkSize]
+			}
+		}
+
+		if changed {
+			_, err := src.ReadAt(chunk, off)
+			if err != nil {
+				return nil, fmt.Errorf("Failed reading volume at offset %d: %w", off, err)
+			}
+
+			if !bytes.Equal(chunk, zero[:len(chunk)]) {
+				hash, err = r.writeChunk(chunk)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		manifest.Chunks = append(manifest.Chunks, hash)
+
+		percent := (off + int64(len(chunk))) * 100 / size
+		if progress != nil && percent != lastPercent {
+			progress(percent)
+			lastPercent = percent
+		}
+	}
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	// The manifest is written last so that a failed backup never gets referenced.
+	err = r.store.Put(key, data)
+	if err != nil {
+		return nil, fmt.Errorf("Failed writing manifest of backup %q: %w", name, err)
+	}
+
+	return manifest, nil
+}
+
+// writeChunk stores the chunk unless already present and returns its hash.
+// An existing chunk may be unreferenced, so its modification time gets refreshed to keep Prune from removing it
+// before the manifest of the backup is written.
+func (r *Repository) writeChunk(chunk []byte) (string, error) {
+	sum := sha256.Sum256(chunk)
+	hash := hex.EncodeToString(sum[:])
+
+	obj, err := r.store.Stat(chunkKey(hash))
+	if err != nil {
+		return "", fmt.Errorf("Failed checking chunk %q: %w", hash, err)
+	}
+
+	if obj == nil {
+		err = r.store.Put(chunkKey(hash), r.encoder.EncodeAll(chunk, nil))
+		if err != nil {
+			return "", fmt.Errorf("Failed writing chunk %q: %w", hash, err)
+		}
+	} else if time.Since(obj.ModTime) > touchPeriod {
+		err = r.store.Touch(chunkKey(hash))
+		if err != nil {
+			return "", fmt.Errorf("Failed refreshing chunk %q: %w", hash, err)
+		}
+	}
+
+	return hash, nil
+}
+
+// readChunk returns the verified content of the chunk with the given hash.
+func (r *Repository) readChunk(hash string) ([]byte, error) {
+	data, err := r.store.Get(chunkKey(hash))
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading chunk %q: %w", hash, err)
+	}
+
+	chunk, err := r.decoder.DecodeAll(data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed decompressing chunk %q: %w", hash, err)
+	}
+
+	sum := sha256.Sum256(chunk)
+	if hex.EncodeToString(sum[:]) != hash {
+		return nil, fmt.Errorf("Chunk %q is corrupted", hash)
+	}
+
+	return chunk, nil
+}
+
+// Restore writes the content of a backup of the volume to dst.
+func (r *Repository) Restore(volume string, name string, dst Target, progress func(percent int64)) error {
+	manifest, err := r.Manifest(volume, name)
+	if err != nil {
+		return err
+	}
+
+	lastPercent := int64(-1)
+
+	for i, hash := range manifest.Chunks {
+		off := int64(i) * manifest.ChunkSize
+		length := min(manifest.ChunkSize, manifest.Size-off)
+
+		if hash == "" {
+			err = dst.WriteZeroes(off, length)
+			if err != nil {
+				return fmt.Errorf("Failed zeroing volume at offset %d: %w", off, err)
+			}
+		} else {
+			chunk, err := r.readChunk(hash)
+			if err != nil {
+				return err
+			}
+
+			if int64(len(chunk)) != length {
+				return fmt.Errorf("Chunk %q has an unexpected size", hash)
+			}
+
+			_, err = dst.WriteAt(chunk, off)
+			if err != nil {
+				return fmt.Errorf("Failed writing volume at offset %d: %w", off, err)
+			}
+		}
+
+		percent := (off + length) * 100 / manifest.Size
+		if progress != nil && percent != lastPercent {
+			progress(percent)
+			lastPercent = percent
+		}
+	}
+
+	return nil
+}
+
+// Delete removes a backup of the volume. Its chunks are removed by the next Prune if not used by other backups.
+func (r *Repository) Delete(volume string, name string) error {
+	key, err := manifestKey(volume, name)
+	if err != nil {
+		return err
+	}
+
+	err = r.store.Delete(key)
+	if err != nil {
+		return fmt.Errorf("Failed deleting backup %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// Prune removes the chunks which aren't referenced by any backup.
+func (r *Repository) Prune() error {
+	manifests, err := r.store.List("manifests/")
+	if err != nil {
+		return fmt.Errorf("Failed listing backups: %w", err)
+	}
+
+	referenced := map[string]bool{}
+
+	for _, obj := range manifests {
+		if !strings.HasSuffix(obj.Key, ".json") {
+			continue
+		}
+
+		data, err := r.store.Get(obj.Key)
+		if err != nil {
+			return fmt.Errorf("Failed reading manifest %q: %w", obj.Key, err)
+		}
+
+		manifest := Manifest{}
+
+		err = json.Unmarshal(data, &manifest)
+		if err != nil {
+			return fmt.Errorf("Failed parsing manifest %q: %w", obj.Key, err)
+		}
+
+		err = manifest.validate()
+		if err != nil {
+			return fmt.Errorf("Invalid manifest %q: %w", obj.Key, err)
+		}
+
+		for _, hash := range manifest.Chunks {
+			referenced[hash] = true
+		}
+	}
+
+	chunks, err := r.store.List("chunks/")
+	if err != nil {
+		return fmt.Errorf("Failed listing chunks: %w", err)
+	}
+
+	for _, obj := range chunks {
+		if referenced[path.Base(obj.Key)] || time.Since(obj.ModTime) < pruneGracePeriod {
+			continue
+		}
+
+		// A backup may have reused the chunk since it was listed.
+		current, err := r.store.Stat(obj.Key)
+		if err != nil {
+			return fmt.Errorf("Failed checking chunk %q: %w", obj.Key, err)
+		}
+
+		if current == nil || time.Since(current.ModTime) < pruneGracePeriod {
+			continue
+		}
+
+		err = r.store.Delete(obj.Key)
+		if err != nil {
+			return fmt.Errorf("Failed deleting chunk %q: %w", obj.Key, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memVolume is an in-memory volume tracking the chunks written since the last reset.
+type memVolume struct {
+	data  []byte
+	dirty map[int64]bool
+}
+
+func (v *memVolume) Size() int64 {
+	return int64(len(v.data))
+}
+
+func (v *memVolume) Changed(off int64, length int64) (bool, error) {
+	return v.dirty[off], nil
+}
+
+func (v *memVolume) ReadAt(p []byte, off int64) (int, error) {
+	return copy(p, v.data[off:]), nil
+}
+
+func (v *memVolume) WriteAt(p []byte, off int64) (int, error) {
+	return copy(v.data[off:], p), nil
+}
+
+func (v *memVolume) WriteZeroes(off int64, length int64) error {
+	clear(v.data[off : off+length])
+	return nil
+}
+
+func TestRepositoryIncremental(t *testing.T) {
+	dir := t.TempDir()
+
+	repo, err := Open(dir)
+	require.NoError(t, err)
+
+	defer repo.Close()
+
+	repo.chunkSize = 16
+
+	// Three chunks of data, one of zeroes and a partial one.
+	src := &memVolume{data: append(bytes.Repeat([]byte("a"), 48), append(make([]byte, 16), []byte("tail")...)...)}
+	src.data[20] = 'b'
+
+	key := VolumeKey("default", "custom", "vol")
+
+	first, err := repo.Backup(key, "1", "", src, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, first.Chunks, 5)
+	require.Equal(t, first.Chunks[0], first.Chunks[2])
+	require.Empty(t, first.Chunks[3])
+
+	chunks, err := repo.store.List("chunks/")
+	require.NoError(t, err)
+	require.Len(t, chunks, 3)
+
+	// Only the chunks flagged as changed get read.
+	src.data[0] = 'c'
+	src.data[40] = 'c'
+	src.dirty = map[int64]bool{32: true}
+
+	second, err := repo.Backup(key, "2", "bitmap", src, first, nil)
+	require.NoError(t, err)
+	require.Equal(t, first.Chunks[0], second.Chunks[0])
+	require.NotEqual(t, first.Chunks[2], second.Chunks[2])
+
+	names, err := repo.Backups(key)
+	require.NoError(t, err)
+	require.Equal(t, []string{"1", "2"}, names)
+
+	dst := &memVolume{data: bytes.Repeat([]byte("x"), len(src.data))}
+	err = repo.Restore(key, "1", dst, nil)
+	require.NoError(t, err)
+	require.Equal(t, byte('a'), dst.data[0])
+	require.Equal(t, byte('a'), dst.data[40])
+	require.Equal(t, make([]byte, 16), dst.data[48:64])
+	require.Equal(t, []byte("tail"), dst.data[64:])
+
+	// Unreferenced chunks are only removed after the grace period.
+	err = repo.Delete(key, "1")
+	require.NoError(t, err)
+
+	err = repo.Prune()
+	require.NoError(t, err)
+
+	chunks, err = repo.store.List("chunks/")
+	require.NoError(t, err)
+	require.Len(t, chunks, 4)
+
+	err = repo.Restore(key, "2", dst, nil)
+	require.NoError(t, err)
+	require.Equal(t, byte('c'), dst.data[40])
+}
+
+// pruningVolume is a memVolume which prunes the repository before reading a given offset.
+type pruningVolume struct {
+	memVolume
+
+	repo    *Repository
+	pruneAt int64
+	err     error
+}
+
+func (v *pruningVolume) ReadAt(p []byte, off int64) (int, error) {
+	if off == v.pruneAt {
+		v.err = v.repo.Prune()
+	}
+
+	return v.memVolume.ReadAt(p, off)
+}
+
+func TestRepositoryPruneDuringBackup(t *testing.T) {
+	dir := t.TempDir()
+
+	repo, err := Open(dir)
+	require.NoError(t, err)
+
+	defer repo.Close()
+
+	repo.chunkSize = 16
+
+	key := VolumeKey("default", "custom", "vol")
+	data := append(bytes.Repeat([]byte("a"), 16), bytes.Repeat([]byte("b"), 16)...)
+
+	first, err := repo.Backup(key, "1", "", &memVolume{data: bytes.Clone(data)}, nil, nil)
+	require.NoError(t, err)
+
+	// The chunks of the deleted backup are unreferenced and past the grace period.
+	err = repo.Delete(key, "1")
+	require.NoError(t, err)
+
+	old := time.Now().Add(-2 * pruneGracePeriod)
+	for _, hash := range first.Chunks {
+		err = os.Chtimes(filepath.Join(dir, chunkKey(hash)), old, old)
+		require.NoError(t, err)
+	}
+
+	// Another server prunes the repository while a backup reuses those chunks.
+	other, err := Open(dir)
+	require.NoError(t, err)
+
+	defer other.Close()
+
+	src := &pruningVolume{memVolume: memVolume{data: bytes.Clone(data)}, repo: other, pruneAt: 16}
+
+	_, err = repo.Backup(key, "2", "", src, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, src.err)
+
+	dst := &memVolume{data: make([]byte, len(data))}
+	err = repo.Restore(key, "2", dst, nil)
+	require.NoError(t, err)
+	require.Equal(t, data, dst.data)
+}
+
+func TestManifestInvalid(t *testing.T) {
+	dir := t.TempDir()
+
+	repo, err := Open(dir)
+	require.NoError(t, err)
+
+	defer repo.Close()
+
+	key := VolumeKey("default", "custom", "vol")
+
+	manifests := map[string]Manifest{
+		"hash":   {Size: 16, ChunkSize: 16, Chunks: []string{"a"}},
+		"chunks": {Size: 32, ChunkSize: 16, Chunks: []string{""}},
+		"size":   {Size: 16, ChunkSize: 0},
+	}
+
+	for name, manifest := range manifests {
+		data, err := json.Marshal(manifest)
+		require.NoError(t, err)
+
+		err = repo.store.Put("manifests/"+key+"/"+name+".json", data)
+		require.NoError(t, err)
+
+		_, err = repo.Manifest(key, name)
+		require.Error(t, err, name)
+
+		err = repo.Restore(key, name, &memVolume{data: make([]byte, 32)}, nil)
+		require.Error(t, err, name)
+	}
+
+	// A previous backup with a mismatching chunk list is ignored.
+	previous := &Manifest{Size: 32, ChunkSize: 16, Chunks: []string{""}}
+	src := &memVolume{data: bytes.Repeat([]byte("a"), 32), dirty: map[int64]bool{}}
+
+	repo.chunkSize = 16
+
+	manifest, err := repo.Backup(key, "1", "", src, previous, nil)
+	require.NoError(t, err)
+	require.Len(t, manifest.Chunks, 2)
+	require.NotEmpty(t, manifest.Chunks[1])
+}
+
+func TestOpenInvalidLocation(t *testing.T) {
+	_, err := Open("relative/path")
+	require.Error(t, err)
+
+	_, err = Open("/srv/../backups")
+	require.Error(t, err)
+}
+
+func TestManifestKeyInvalid(t *testing.T) {
+	repo, err := Open(t.TempDir())
+	require.NoError(t, err)
+	defer repo.Close()
+
+	_, err = repo.Manifest(VolumeKey("default", "custom", "vol1"), "../../../etc/passwd")
+	require.Error(t, err)
+
+	_, err = repo.Manifest("default/custom/../../secret", "1")
+	require.Error(t, err)
+
+	err = repo.Delete(VolumeKey("default", "custom", "vol1"), "..")
+	require.Error(t, err)
+}
@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/lxc/incus/v7/internal/server/storage/s3util"
+	"github.com/lxc/incus/v7/shared/api"
+)
+
+// storeObject is an object of a store.
+type storeObject struct {
+	Key     string
+	ModTime time.Time
+}
+
+// store is a flat key/value object store holding the content of a repository.
+type store interface {
+	// Get returns the content of the object. A missing object results in an fs.ErrNotExist error.
+	Get(key string) ([]byte, error)
+	Put(key string, data []byte) error
+
+	// Stat returns the object, or nil if it doesn't exist.
+	Stat(key string) (*storeObject, error)
+
+	// Touch sets the modification time of an existing object to the current time.
+	Touch(key string) error
+	List(prefix string) ([]storeObject, error)
+	Delete(key string) error
+}
+
+// dirStore is a store backed by a local directory.
+type dirStore struct {
+	path string
+}
+
+// Get returns the content of the object.
+func (d *dirStore) Get(key string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(d.path, key))
+}
+
+// Put atomically writes the object.
+func (d *dirStore) Put(key string, data []byte) error {
+	objPath := filepath.Join(d.path, key)
+
+	err := os.MkdirAll(filepath.Dir(objPath), 0o700)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(objPath), ".tmp-")
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = os.Remove(f.Name()) }()
+
+	_, err = f.Write(data)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), objPath)
+}
+
+// Stat returns the object, or nil if it doesn't exist.
+func (d *dirStore) Stat(key string) (*storeObject, error) {
+	info, err := os.Stat(filepath.Join(d.path, key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &storeObject{Key: key, ModTime: info.ModTime()}, nil
+}
+
+// Touch sets the modification time of the object to the current time.
+func (d *dirStore) Touch(key string) error {
+	now := time.Now()
+
+	return os.Chtimes(filepath.Join(d.path, key), now, now)
+}
+
+// List returns the objects whose key starts with prefix.
+func (d *dirStore) List(prefix string) ([]storeObject, error) {
+	var objects []storeObject
+
+	err := filepath.WalkDir(filepath.Join(d.path, prefix), func(objPath string, entry fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".tmp-") {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		key, err := filepath.Rel(d.path, objPath)
+		if err != nil {
+			return err
+		}
+
+		objects = append(objects, storeObject{Key: filepath.ToSlash(key), ModTime: info.ModTime()})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return objects, nil
+}
+
+// Delete removes the object.
+func (d *dirStore) Delete(key string) error {
+	err := os.Remove(filepath.Join(d.path, key))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// s3Store is a store backed by an S3 bucket.
+type s3Store struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+// newS3Store returns a store for the bucket and path of target.
+func newS3Store(target *api.BackupTarget) (*s3Store, error) {
+	uri, err := url.Parse(target.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s3Store{
+		client: s3util.NewClient(uri, target.AccessKey, target.SecretKey),
+		bucket: target.BucketName,
+		prefix: strings.Trim(target.Path, "/"),
+	}, nil
+}
+
+// key returns the object key within the bucket.
+func (s *s3Store) key(key string) string {
+	return path.Join(s.prefix, key)
+}
+
+// Get returns the content of the object.
+func (s *s3Store) Get(key string) ([]byte, error) {
+	resp, err := s.client.GetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(key)),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, fs.ErrNotExist
+		}
+
+		return nil, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	return io.ReadAll(resp.Body)
+}
+
+// Put writes the object.
+func (s *s3Store) Put(key string, data []byte) error {
+	_, err := s.client.PutObject(context.Background(), &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(key)),
+		Body:   bytes.NewReader(data),
+	})
+
+	return err
+}
+
+// Stat returns the object, or nil if it doesn't exist.
+func (s *s3Store) Stat(key string) (*storeObject, error) {
+	resp, err := s.client.HeadObject(context.Background(), &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(key)),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &storeObject{Key: key, ModTime: aws.ToTime(resp.LastModified)}, nil
+}
+
+// Touch sets the modification time of the object to the current time by copying it onto itself.
+func (s *s3Store) Touch(key string) error {
+	_, err := s.client.CopyObject(context.Background(), &s3.CopyObjectInput{
+		Bucket:            aws.String(s.bucket),
+		Key:               aws.String(s.key(key)),
+		CopySource:        aws.String(path.Join(s.bucket, s.key(key))),
+		MetadataDirective: types.MetadataDirectiveReplace,
+	})
+
+	return err
+}
+
+// List returns the objects whose key starts with prefix.
+func (s *s3Store) List(prefix string) ([]storeObject, error) {
+	var objects []storeObject
+
+	// Keep the trailing slash so that only the content of a directory-like prefix is listed.
+	bucketPrefix := s.key(prefix)
+	if strings.HasSuffix(prefix, "/") {
+		bucketPrefix += "/"
+	}
+
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(bucketPrefix),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range page.Contents {
+			key := strings.TrimPrefix(aws.ToString(obj.Key), s.prefix)
+			objects = append(objects, storeObject{Key: strings.TrimPrefix(key, "/"), ModTime: aws.ToTime(obj.LastModified)})
+		}
+	}
+
+	return objects, nil
+}
+
+// Delete removes the object.
+func (s *s3Store) Delete(key string) error {
+	_, err := s.client.DeleteObject(context.Background(), &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(key)),
+	})
+
+	return err
+}
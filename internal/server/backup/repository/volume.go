@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"io"
+)
+
+// Source is a block volume to back up.
+type Source interface {
+	io.ReaderAt
+
+	// Size returns the size of the volume in bytes.
+	Size() int64
+
+	// Changed returns whether the given range changed since the previous backup.
+	// Sources which don't track changes always return true.
+	Changed(off int64, length int64) (bool, error)
+}
+
+// Target is a block volume to restore into.
+type Target interface {
+	io.WriterAt
+
+	// WriteZeroes zeroes the given range.
+	WriteZeroes(off int64, length int64) error
+}
+
+// NBDVolume is a block volume exported over NBD.
+type NBDVolume struct {
+	client *nbdClient
+}
+
+// NewNBDVolume negotiates the default NBD export on conn.
+// If bitmap isn't empty, changes are tracked through the dirty bitmap of that name.
+func NewNBDVolume(conn io.ReadWriter, bitmap string) (*NBDVolume, error) {
+	metaContext := ""
+	if bitmap != "" {
+		metaContext = "qemu:dirty-bitmap:" + bitmap
+	}
+
+	client, err := newNBDClient(conn, metaContext)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NBDVolume{client: client}, nil
+}
+
+// Tracked returns whether changes of the volume are tracked through a dirty bitmap.
+func (v *NBDVolume) Tracked() bool {
+	return v.client.hasContext
+}
+
+// Size returns the size of the volume in bytes.
+func (v *NBDVolume) Size() int64 {
+	return v.client.size
+}
+
+// Changed returns whether the given range is dirty in the bitmap (always true if untracked).
+func (v *NBDVolume) Changed(off int64, length int64) (bool, error) {
+	if !v.client.hasContext {
+		return true, nil
+	}
+
+	return v.client.dirty(off, length)
+}
+
+// ReadAt reads len(p) bytes from the volume at the given offset.
+func (v *NBDVolume) ReadAt(p []byte, off int64) (int, error) {
+	return v.client.ReadAt(p, off)
+}
+
+// WriteAt writes p to the volume at the given offset.
+func (v *NBDVolume) WriteAt(p []byte, off int64) (int, error) {
+	return v.client.WriteAt(p, off)
+}
+
+// WriteZeroes zeroes the given range of the volume.
+func (v *NBDVolume) WriteZeroes(off int64, length int64) error {
+	return v.client.WriteZeroes(off, length)
+}
+
+// Close flushes pending writes and ends the NBD session.
+func (v *NBDVolume) Close() error {
+	err := v.client.Flush()
+	if err != nil {
+		return err
+	}
+
+	return v.client.Close()
+}
@@ -30,6 +30,7 @@ type DebugInfoPeer struct {
 	Password  string `json:"password" yaml:"password"`
 	Count     int    `json:"count" yaml:"count"`
 	HoldTime  uint64 `json:"holdtime" yaml:"holdtime"`
+	BFD       bool   `json:"bfd" yaml:"bfd"`
 }
 
 // Debug returns a dump of the current configuration.
@@ -63,6 +64,7 @@ func (s *Server) debug() DebugInfo {
 		entry.Password = peer.password
 		entry.Count = peer.count
 		entry.HoldTime = peer.holdtime
+		entry.BFD = peer.bfd
 
 		debug.Peers = append(debug.Peers, entry)
 	}
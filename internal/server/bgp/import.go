@@ -0,0 +1,435 @@
+package bgp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	bgpAPI "github.com/osrg/gobgp/v4/api"
+	bgpAPIutil "github.com/osrg/gobgp/v4/pkg/apiutil"
+	bgpPacket "github.com/osrg/gobgp/v4/pkg/packet/bgp"
+	bgpServer "github.com/osrg/gobgp/v4/pkg/server"
+
+	"github.com/lxc/incus/v7/shared/logger"
+)
+
+// importRetryInterval is how often imports which couldn't be applied are retried.
+const importRetryInterval = 30 * time.Second
+
+// ErrImportSkipped can be returned by an ImportFunc to have the routes applied again on the next refresh.
+var ErrImportSkipped = errors.New("Route import skipped")
+
+// Route represents a route learned from a BGP peer.
+type Route struct {
+	Prefix  net.IPNet
+	Nexthop net.IP
+
+	// Peer is the address (or interface name for unnumbered peers) of the peer the route was learned from.
+	Peer string
+
+	// Interface is set for routes learned from unnumbered peers.
+	Interface string
+}
+
+// Session represents the state of a BGP session.
+type Session struct {
+	Peer     string
+	ASN      uint32
+	State    string
+	BFDState string
+}
+
+// ImportFunc applies the routes imported for an owner, oldRoutes being the previously applied routes.
+type ImportFunc func(oldRoutes []Route, newRoutes []Route) error
+
+// PrefixFilter represents an entry of a route import prefix list.
+type PrefixFilter struct {
+	Prefix net.IPNet
+
+	// MaxLength is the longest prefix length accepted within Prefix (0 to only accept Prefix itself).
+	MaxLength int
+}
+
+// Match returns whether the subnet is accepted by the filter entry.
+func (f PrefixFilter) Match(subnet net.IPNet) bool {
+	filterLen, filterBits := f.Prefix.Mask.Size()
+	subnetLen, subnetBits := subnet.Mask.Size()
+
+	if filterBits != subnetBits || subnetLen < filterLen || !f.Prefix.Contains(subnet.IP) {
+		return false
+	}
+
+	if f.MaxLength == 0 {
+		return subnetLen == filterLen
+	}
+
+	return subnetLen <= f.MaxLength
+}
+
+// String returns the filter entry in the format parsed by ParsePrefixFilters.
+func (f PrefixFilter) String() string {
+	if f.MaxLength == 0 {
+		return f.Prefix.String()
+	}
+
+	return fmt.Sprintf("%s..%d", f.Prefix.String(), f.MaxLength)
+}
+
+// ParsePrefixFilters parses a comma separated list of prefixes, each optionally followed by the longest
+// prefix length accepted within it (for example "0.0.0.0/0,10.0.0.0/8..24").
+func ParsePrefixFilters(value string) ([]PrefixFilter, error) {
+	filters := []PrefixFilter{}
+
+	for entry := range strings.SplitSeq(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		cidr, maxLength, hasMaxLength := strings.Cut(entry, "..")
+
+		_, subnet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid prefix %q: %w", cidr, err)
+		}
+
+		filter := PrefixFilter{Prefix: *subnet}
+
+		if hasMaxLength {
+			subnetLen, subnetBits := subnet.Mask.Size()
+
+			filter.MaxLength, err = strconv.Atoi(maxLength)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid prefix length in %q: %w", entry, err)
+			}
+
+			if filter.MaxLength < subnetLen || filter.MaxLength > subnetBits {
+				return nil, fmt.Errorf("Invalid prefix length in %q (must be between %d and %d)", entry, subnetLen, subnetBits)
+			}
+		}
+
+		filters = append(filters, filter)
+	}
+
+	return filters, nil
+}
+
+type routeImport struct {
+	peers   []string
+	filters []PrefixFilter
+	fn      ImportFunc
+	applied []Route
+	force   bool
+}
+
+// AddImport registers (or updates) the route import for the owner.
+// The routes learned from the listed peers (addresses or interface names) which match one of the filters
+// are passed to fn on registration and then whenever they change.
+func (s *Server) AddImport(owner string, peers []string, filters []PrefixFilter, fn ImportFunc) {
+	s.importMu.Lock()
+	defer s.importMu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var applied []Route
+	existing, ok := s.imports[owner]
+	if ok {
+		applied = existing.applied
+	}
+
+	s.imports[owner] = &routeImport{
+		peers:   peers,
+		filters: filters,
+		fn:      fn,
+		applied: applied,
+		force:   true,
+	}
+
+	s.refreshImports()
+}
+
+// RemoveImport removes the route import for the owner, withdrawing its applied routes.
+func (s *Server) RemoveImport(owner string) error {
+	s.importMu.Lock()
+	defer s.importMu.Unlock()
+
+	s.mu.Lock()
+	imp, ok := s.imports[owner]
+	delete(s.imports, owner)
+	s.mu.Unlock()
+
+	if !ok || len(imp.applied) == 0 {
+		return nil
+	}
+
+	err := imp.fn(imp.applied, nil)
+	if err != nil && !errors.Is(err, ErrImportSkipped) {
+		return err
+	}
+
+	return nil
+}
+
+// Routes returns the routes learned from the listed peers.
+func (s *Server) Routes(peers []string) ([]Route, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	routes, err := s.learnedRoutes()
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(routes, func(route Route) bool { return !slices.Contains(peers, route.Peer) }), nil
+}
+
+// Sessions returns the state of the sessions with the listed peers.
+func (s *Server) Sessions(peers []string) ([]Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions := []Session{}
+
+	for _, peerName := range peers {
+		bgpPeer, ok := s.peers[peerName]
+		if !ok {
+			continue
+		}
+
+		sessions = append(sessions, Session{
+			Peer:  peerName,
+			ASN:   bgpPeer.asn,
+			State: "idle",
+		})
+	}
+
+	if s.bgp == nil {
+		return sessions, nil
+	}
+
+	err := s.bgp.ListPeer(context.Background(), &bgpAPI.ListPeerRequest{}, func(p *bgpAPI.Peer) {
+		peerName := p.GetConf().GetNeighborInterface()
+		if peerName == "" {
+			peerName = p.GetConf().GetNeighborAddress()
+		}
+
+		for i := range sessions {
+			if sessions[i].Peer != peerName {
+				continue
+			}
+
+			state := bgpAPI.PeerState_SessionState_name[int32(p.GetState().GetSessionState())]
+			sessions[i].State = strings.ToLower(strings.TrimPrefix(state, "SESSION_STATE_"))
+
+			if p.GetBfd().GetEnabled() {
+				bfdState := bgpAPI.BfdSessionState_name[int32(p.GetState().GetBfdState().GetSessionState())]
+				sessions[i].BFDState = strings.ToLower(strings.TrimPrefix(bfdState, "BFD_SESSION_STATE_"))
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// watch starts watching for path and peer changes in the BGP server to refresh the route imports.
+func (s *Server) watch() error {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	callbacks := bgpServer.WatchEventMessageCallbacks{
+		OnPathUpdate: func(_ []*bgpAPIutil.Path, _ time.Time) { s.scheduleImportRefresh() },
+		OnPeerUpdate: func(_ *bgpAPIutil.WatchEventMessage_PeerEvent, _ time.Time) { s.scheduleImportRefresh() },
+	}
+
+	err := s.bgp.WatchEvent(ctx, callbacks, bgpServer.WatchUpdate(false, "", ""), bgpServer.WatchPeer())
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	s.watchCancel = cancel
+
+	return nil
+}
+
+// scheduleImportRefresh asks for the route imports to be refreshed.
+func (s *Server) scheduleImportRefresh() {
+	select {
+	case s.importRefresh <- struct{}{}:
+	default:
+	}
+}
+
+// importLoop refreshes the route imports when requested and periodically retries the skipped ones.
+func (s *Server) importLoop() {
+	ticker := time.NewTicker(importRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.importRefresh:
+		case <-ticker.C:
+		}
+
+		s.importMu.Lock()
+		s.mu.Lock()
+		s.refreshImports()
+		s.mu.Unlock()
+		s.importMu.Unlock()
+	}
+}
+
+// refreshImports applies the route imports whose routes changed (or were never applied).
+// Must be called with both importMu and mu held.
+func (s *Server) refreshImports() {
+	if len(s.imports) == 0 {
+		return
+	}
+
+	learned, err := s.learnedRoutes()
+	if err != nil {
+		logger.Warn("Failed listing BGP learned routes", logger.Ctx{"err": err})
+		return
+	}
+
+	for owner, imp := range s.imports {
+		routes := []Route{}
+		for _, route := range learned {
+			if !slices.Contains(imp.peers, route.Peer) {
+				continue
+			}
+
+			if !slices.ContainsFunc(imp.filters, func(f PrefixFilter) bool { return f.Match(route.Prefix) }) {
+				continue
+			}
+
+			// Only keep the first route learned for a prefix.
+			if slices.ContainsFunc(routes, func(r Route) bool { return r.Prefix.String() == route.Prefix.String() }) {
+				continue
+			}
+
+			routes = append(routes, route)
+		}
+
+		if !imp.force && routesEqual(imp.applied, routes) {
+			continue
+		}
+
+		err := imp.fn(imp.applied, routes)
+		if err != nil {
+			if !errors.Is(err, ErrImportSkipped) {
+				logger.Warn("Failed applying BGP imported routes", logger.Ctx{"owner": owner, "err": err})
+			}
+
+			continue
+		}
+
+		imp.applied = routes
+		imp.force = false
+	}
+}
+
+// learnedRoutes returns the routes learned from established peers, ordered by preference.
+func (s *Server) learnedRoutes() ([]Route, error) {
+	routes := []Route{}
+
+	if s.bgp == nil {
+		return routes, nil
+	}
+
+	// Map the peer addresses to the peer names.
+	peerNames := map[string]string{}
+	for peerName, bgpPeer := range s.peers {
+		if bgpPeer.address != nil {
+			peerNames[bgpPeer.address.String()] = peerName
+		} else if bgpPeer.neighbor != "" {
+			neighbor, _, _ := strings.Cut(bgpPeer.neighbor, "%")
+			peerNames[neighbor] = peerName
+		}
+	}
+
+	for _, family := range []bgpPacket.Family{bgpPacket.RF_IPv4_UC, bgpPacket.RF_IPv6_UC} {
+		req := bgpAPIutil.ListPathRequest{
+			TableType: bgpAPI.TableType_TABLE_TYPE_GLOBAL,
+			Family:    family,
+		}
+
+		err := s.bgp.ListPath(req, func(prefix bgpPacket.NLRI, paths []*bgpAPIutil.Path) {
+			_, subnet, err := net.ParseCIDR(prefix.String())
+			if err != nil {
+				return
+			}
+
+			for _, p := range paths {
+				if p.Withdrawal || p.Stale || p.IsNexthopInvalid || !p.PeerAddress.IsValid() {
+					continue
+				}
+
+				peerName, ok := peerNames[p.PeerAddress.WithZone("").String()]
+				if !ok {
+					continue
+				}
+
+				nexthop := pathNexthop(p)
+				if nexthop == nil {
+					continue
+				}
+
+				route := Route{
+					Prefix:  *subnet,
+					Nexthop: nexthop,
+					Peer:    peerName,
+				}
+
+				if s.peers[peerName].address == nil {
+					route.Interface = s.peers[peerName].iface
+				}
+
+				routes = append(routes, route)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return routes, nil
+}
+
+// pathNexthop returns the next hop of a learned path.
+func pathNexthop(p *bgpAPIutil.Path) net.IP {
+	for _, attr := range p.Attrs {
+		switch a := attr.(type) {
+		case *bgpPacket.PathAttributeNextHop:
+			if a.Value.IsValid() {
+				return net.IP(a.Value.AsSlice())
+			}
+
+		case *bgpPacket.PathAttributeMpReachNLRI:
+			if a.Nexthop.IsValid() && !a.Nexthop.IsUnspecified() {
+				return net.IP(a.Nexthop.WithZone("").AsSlice())
+			}
+
+			if a.LinkLocalNexthop.IsValid() {
+				return net.IP(a.LinkLocalNexthop.WithZone("").AsSlice())
+			}
+		}
+	}
+
+	return nil
+}
+
+// routesEqual returns whether both route lists are identical.
+func routesEqual(a []Route, b []Route) bool {
+	return slices.EqualFunc(a, b, func(x Route, y Route) bool {
+		return x.Prefix.String() == y.Prefix.String() && x.Nexthop.Equal(y.Nexthop) && x.Peer == y.Peer && x.Interface == y.Interface
+	})
+}
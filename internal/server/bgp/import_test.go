@@ -0,0 +1,50 @@
+package bgp
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePrefixFilters(t *testing.T) {
+	filters, err := ParsePrefixFilters("0.0.0.0/0, 10.0.0.0/8..24,fd00::/8..64")
+	require.NoError(t, err)
+	require.Len(t, filters, 3)
+
+	assert.Equal(t, "0.0.0.0/0", filters[0].String())
+	assert.Equal(t, "10.0.0.0/8..24", filters[1].String())
+	assert.Equal(t, "fd00::/8..64", filters[2].String())
+
+	for _, value := range []string{"10.0.0.0", "10.0.0.0/8..4", "10.0.0.0/8..33", "10.0.0.0/8..foo"} {
+		_, err := ParsePrefixFilters(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestPrefixFilterMatch(t *testing.T) {
+	filters, err := ParsePrefixFilters("0.0.0.0/0,10.0.0.0/8..24")
+	require.NoError(t, err)
+
+	tests := []struct {
+		subnet string
+		match  bool
+	}{
+		{"0.0.0.0/0", true},
+		{"192.0.2.0/24", false},
+		{"10.0.0.0/8", true},
+		{"10.1.0.0/16", true},
+		{"10.1.2.0/24", true},
+		{"10.1.2.0/25", false},
+		{"::/0", false},
+	}
+
+	for _, test := range tests {
+		_, subnet, err := net.ParseCIDR(test.subnet)
+		require.NoError(t, err)
+
+		match := filters[0].Match(*subnet) || filters[1].Match(*subnet)
+		assert.Equal(t, test.match, match, test.subnet)
+	}
+}
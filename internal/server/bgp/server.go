@@ -22,6 +22,12 @@ import (
 	"github.com/lxc/incus/v7/shared/revert"
 )
 
+// BFD timers (in microseconds) detecting a failed peer in under a second.
+const (
+	bfdInterval   = 300000
+	bfdMultiplier = 3
+)
+
 // Server represents a BGP server instance.
 type Server struct {
 	bgp *bgpServer.BgpServer
@@ -33,7 +39,13 @@ type Server struct {
 	paths    map[string]path
 	peers    map[string]peer
 
-	mu sync.Mutex
+	// Route imports.
+	imports       map[string]*routeImport
+	importRefresh chan struct{}
+	watchCancel   context.CancelFunc
+
+	mu       sync.Mutex
+	importMu sync.Mutex
 }
 
 type path struct {
@@ -48,6 +60,8 @@ type peer struct {
 	asn      uint32
 	password string
 	holdtime uint64
+	bfd      bool
+	neighbor string
 	count    int
 }
 
@@ -64,10 +78,15 @@ func peerKey(address net.IP, iface string) string {
 func NewServer() *Server {
 	// Setup new struct.
 	s := &Server{
-		paths: map[string]path{},
-		peers: map[string]peer{},
+		paths:         map[string]path{},
+		peers:         map[string]peer{},
+		imports:       map[string]*routeImport{},
+		importRefresh: make(chan struct{}, 1),
 	}
 
+	// Apply the route imports in the background.
+	go s.importLoop()
+
 	return s
 }
 
@@ -127,6 +146,12 @@ func (s *Server) start(address string, asn uint32, routerID net.IP) error {
 		return err
 	}
 
+	// Watch for learned routes.
+	err = s.watch()
+	if err != nil {
+		return err
+	}
+
 	// Copy the path list
 	oldPaths := map[string]path{}
 	maps.Copy(oldPaths, s.paths)
@@ -147,7 +172,7 @@ func (s *Server) start(address string, asn uint32, routerID net.IP) error {
 	// Add existing peers.
 	s.peers = map[string]peer{}
 	for _, peer := range oldPeers {
-		err := s.addPeer(peer.address, peer.iface, peer.asn, peer.password, peer.holdtime)
+		err := s.addPeer(peer.address, peer.iface, peer.asn, peer.password, peer.holdtime, peer.bfd)
 		if err != nil {
 			return err
 		}
@@ -183,12 +208,21 @@ func (s *Server) stop() error {
 	// Restore peer list.
 	s.peers = oldPeers
 
+	// Stop watching for learned routes.
+	if s.watchCancel != nil {
+		s.watchCancel()
+		s.watchCancel = nil
+	}
+
 	// Stop the listener.
 	err := s.bgp.StopBgp(context.Background(), &bgpAPI.StopBgpRequest{})
 	if err != nil {
 		return err
 	}
 
+	// Withdraw the imported routes.
+	s.scheduleImportRefresh()
+
 	// Mark the daemon as down.
 	s.address = ""
 	s.asn = 0
@@ -472,16 +506,16 @@ func (s *Server) removePrefixByUUID(pathUUID string) error {
 	return nil
 }
 
-// AddPeer adds a new BGP peer.
-func (s *Server) AddPeer(address net.IP, iface string, asn uint32, password string, holdTime uint64) error {
+// AddPeer adds a new BGP peer, optionally monitored through BFD.
+func (s *Server) AddPeer(address net.IP, iface string, asn uint32, password string, holdTime uint64, bfd bool) error {
 	// Locking.
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	return s.addPeer(address, iface, asn, password, holdTime)
+	return s.addPeer(address, iface, asn, password, holdTime, bfd)
 }
 
-func (s *Server) addPeer(address net.IP, iface string, asn uint32, password string, holdTime uint64) error {
+func (s *Server) addPeer(address net.IP, iface string, asn uint32, password string, holdTime uint64, bfd bool) error {
 	peerName := peerKey(address, iface)
 
 	// Look for an existing peer.
@@ -495,6 +529,10 @@ func (s *Server) addPeer(address net.IP, iface string, asn uint32, password stri
 			return fmt.Errorf("Peer %q already used but with a different password", peerName)
 		}
 
+		if bgpPeer.bfd != bfd {
+			return fmt.Errorf("Peer %q already used but with a different BFD setting", peerName)
+		}
+
 		// Reuse the existing entry.
 		bgpPeer.count++
 		s.peers[peerName] = bgpPeer
@@ -540,6 +578,16 @@ func (s *Server) addPeer(address net.IP, iface string, asn uint32, password stri
 		}
 	}
 
+	// Add BFD if configured.
+	if bfd {
+		n.Bfd = &bgpAPI.BfdPeerConfig{
+			Enabled:                  true,
+			DesiredMinimumTxInterval: bfdInterval,
+			RequiredMinimumReceive:   bfdInterval,
+			DetectionMultiplier:      bfdMultiplier,
+		}
+	}
+
 	// Setup peer for dual-stack.
 	n.AfiSafis = make([]*bgpAPI.AfiSafi, 0)
 	for _, f := range []string{"ipv4-unicast", "ipv6-unicast"} {
@@ -566,6 +614,7 @@ func (s *Server) addPeer(address net.IP, iface string, asn uint32, password stri
 	}
 
 	// Add the peer.
+	var neighbor string
 	if s.bgp != nil {
 		// The API path doesn't resolve unnumbered peers, do it ourselves.
 		if address == nil {
@@ -575,6 +624,7 @@ func (s *Server) addPeer(address net.IP, iface string, asn uint32, password stri
 			}
 
 			n.State = &bgpAPI.PeerState{NeighborAddress: neighborAddress}
+			neighbor = neighborAddress
 		}
 
 		err := s.bgp.AddPeer(context.Background(), &bgpAPI.AddPeerRequest{Peer: n})
@@ -594,6 +644,8 @@ func (s *Server) addPeer(address net.IP, iface string, asn uint32, password stri
 			asn:      asn,
 			password: password,
 			holdtime: holdTime,
+			bfd:      bfd,
+			neighbor: neighbor,
 			count:    1,
 		}
 	}
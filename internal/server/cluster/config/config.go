@@ -15,6 +15,7 @@ import (
 	"github.com/lxc/incus/v7/internal/server/config"
 	"github.com/lxc/incus/v7/internal/server/db"
 	scriptletLoad "github.com/lxc/incus/v7/internal/server/scriptlet/load"
+	"github.com/lxc/incus/v7/shared/api"
 	"github.com/lxc/incus/v7/shared/validate"
 )
 
@@ -41,6 +42,26 @@ func Load(ctx context.Context, tx *db.ClusterTx) (*Config, error) {
 	return &Config{tx: tx, m: m}, nil
 }
 
+// BackupTarget returns the backup target with the given name, including its credentials.
+func (c *Config) BackupTarget(name string) (*api.BackupTarget, error) {
+	prefix := fmt.Sprintf("backups.targets.%s", name)
+
+	location := c.m.GetString(fmt.Sprintf("%s.%s", prefix, "url"))
+	if location == "" {
+		return nil, fmt.Errorf("Backup target %q isn't defined", name)
+	}
+
+	target, err := internalInstance.ParseBackupTargetURL(location)
+	if err != nil {
+		return nil, err
+	}
+
+	target.AccessKey = c.m.GetString(fmt.Sprintf("%s.%s", prefix, "access_key"))
+	target.SecretKey = c.m.GetString(fmt.Sprintf("%s.%s", prefix, "secret_key"))
+
+	return target, nil
+}
+
 // BackupsCompressionAlgorithm returns the compression algorithm to use for backups.
 func (c *Config) BackupsCompressionAlgorithm() string {
 	return c.m.GetString("backups.compression_algorithm")
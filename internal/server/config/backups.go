@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	internalInstance "github.com/lxc/incus/v7/internal/instance"
+	"github.com/lxc/incus/v7/shared/validate"
+)
+
+// IsBackupTargetConfig reports whether the config key is for a backup target configuration.
+func IsBackupTargetConfig(key string) bool {
+	return strings.HasPrefix(key, "backups.targets.")
+}
+
+// GetBackupTargetRuleForKey returns the rule for the specified backup target config key.
+func GetBackupTargetRuleForKey(key string) (Key, error) {
+	fields := strings.Split(key, ".")
+	if len(fields) != 4 {
+		return Key{}, fmt.Errorf("%s is not a valid backup target config key", key)
+	}
+
+	err := internalInstance.ValidateBackupTargetName(fields[2])
+	if err != nil {
+		return Key{}, err
+	}
+
+	switch fields[3] {
+	case "url":
+		// gendoc:generate(entity=server, group=backups, key=backups.targets.NAME.url)
+		// Specify an S3 URL of the form `https://<host>[:<port>]/<bucket>[/<prefix>]`.
+		// For backup repositories, an absolute path on the server can be used instead.
+		// ---
+		//  type: string
+		//  scope: global
+		//  shortdesc: Location of the backup target
+		return Key{Validator: validate.Optional(func(value string) error {
+			_, err := internalInstance.ParseBackupTargetURL(value)
+			return err
+		})}, nil
+	case "access_key":
+		// gendoc:generate(entity=server, group=backups, key=backups.targets.NAME.access_key)
+		//
+		// ---
+		//  type: string
+		//  scope: global
+		//  shortdesc: S3 access key of the backup target
+		return Key{}, nil
+	case "secret_key":
+		// gendoc:generate(entity=server, group=backups, key=backups.targets.NAME.secret_key)
+		//
+		// ---
+		//  type: string
+		//  scope: global
+		//  shortdesc: S3 secret key of the backup target
+		return Key{}, nil
+	}
+
+	return Key{}, fmt.Errorf("%s is not a valid backup target config key", key)
+}
@@ -109,9 +109,9 @@ func (m *Map) GetRaw(name string) string {
 		return value
 	}
 
-	if IsLoggingConfig(name) {
+	if isDynamicConfig(name) {
 		if !ok {
-			key, err := GetLoggingRuleForKey(name)
+			key, err := getDynamicRuleForKey(name)
 			if err != nil {
 				panic(err)
 			}
@@ -133,7 +133,7 @@ func (m *Map) GetRaw(name string) string {
 
 // GetString returns the value of the given key, which must be of type String.
 func (m *Map) GetString(name string) string {
-	if !internalInstance.IsUserConfig(name) && !IsLoggingConfig(name) {
+	if !internalInstance.IsUserConfig(name) && !isDynamicConfig(name) {
 		m.schema.assertKeyType(name, String)
 	}
 
@@ -148,7 +148,7 @@ func (m *Map) GetBool(name string) bool {
 
 // GetInt64 returns the value of the given key, which must be of type Int64.
 func (m *Map) GetInt64(name string) int64 {
-	if !IsLoggingConfig(name) {
+	if !isDynamicConfig(name) {
 		m.schema.assertKeyType(name, Int64)
 	}
 
@@ -226,8 +226,8 @@ func (m *Map) set(name string, value string, initial bool) (bool, error) {
 		return true, nil
 	}
 
-	if IsLoggingConfig(name) {
-		rule, err := GetLoggingRuleForKey(name)
+	if isDynamicConfig(name) {
+		rule, err := getDynamicRuleForKey(name)
 		if err != nil {
 			return false, err
 		}
@@ -290,3 +290,17 @@ func normalizeBool(value string) string {
 
 	return "false"
 }
+
+// isDynamicConfig reports whether the config key belongs to a namespace whose keys are only known at runtime.
+func isDynamicConfig(key string) bool {
+	return IsLoggingConfig(key) || IsBackupTargetConfig(key)
+}
+
+// getDynamicRuleForKey returns the rule for the specified dynamic config key.
+func getDynamicRuleForKey(key string) (Key, error) {
+	if IsBackupTargetConfig(key) {
+		return GetBackupTargetRuleForKey(key)
+	}
+
+	return GetLoggingRuleForKey(key)
+}
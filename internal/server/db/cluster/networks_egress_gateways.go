@@ -0,0 +1,71 @@
+//go:build linux && cgo && !agent
+
+package cluster
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lxc/incus/v7/shared/api"
+)
+
+// Code generation directives.
+//
+//generate-database:mapper target networks_egress_gateways.mapper.go
+//generate-database:mapper reset -i -b "//go:build linux && cgo && !agent"
+//
+//generate-database:mapper stmt -e network_egress_gateway objects table=networks_egress_gateways
+//generate-database:mapper stmt -e network_egress_gateway objects-by-NetworkID table=networks_egress_gateways
+//generate-database:mapper stmt -e network_egress_gateway objects-by-NetworkID-and-Address table=networks_egress_gateways
+//generate-database:mapper stmt -e network_egress_gateway id table=networks_egress_gateways
+//generate-database:mapper stmt -e network_egress_gateway create table=networks_egress_gateways
+//generate-database:mapper stmt -e network_egress_gateway update table=networks_egress_gateways
+//generate-database:mapper stmt -e network_egress_gateway delete-by-NetworkID-and-ID table=networks_egress_gateways
+//
+//generate-database:mapper method -i -e network_egress_gateway GetMany references=Config table=networks_egress_gateways
+//generate-database:mapper method -i -e network_egress_gateway GetOne table=networks_egress_gateways
+//generate-database:mapper method -i -e network_egress_gateway ID table=networks_egress_gateways
+//generate-database:mapper method -i -e network_egress_gateway Create references=Config table=networks_egress_gateways
+//generate-database:mapper method -i -e network_egress_gateway Update references=Config table=networks_egress_gateways
+//generate-database:mapper method -i -e network_egress_gateway DeleteOne-by-NetworkID-and-ID table=networks_egress_gateways
+
+// NetworkEgressGateway is the generated entity backing the networks_egress_gateways table.
+type NetworkEgressGateway struct {
+	ID          int64
+	NetworkID   int64  `db:"primary=yes&column=network_id"`
+	Address     string `db:"primary=yes"`
+	Description string
+	Instances   []string `db:"marshal=json"`
+	Profiles    []string `db:"marshal=json"`
+	Projects    []string `db:"marshal=json"`
+}
+
+// NetworkEgressGatewayFilter defines the optional WHERE-clause fields.
+type NetworkEgressGatewayFilter struct {
+	ID        *int64
+	NetworkID *int64
+	Address   *string
+}
+
+// ToAPI converts the DB record into the external API type.
+func (n *NetworkEgressGateway) ToAPI(ctx context.Context, tx *sql.Tx) (*api.NetworkEgressGateway, error) {
+	// Get the config.
+	cfg, err := GetNetworkEgressGatewayConfig(ctx, tx, int(n.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	out := api.NetworkEgressGateway{
+		NetworkEgressGatewayPut: api.NetworkEgressGatewayPut{
+			Description: n.Description,
+			Config:      cfg,
+			Instances:   n.Instances,
+			Profiles:    n.Profiles,
+			Projects:    n.Projects,
+		},
+
+		Address: n.Address,
+	}
+
+	return &out, nil
+}
@@ -0,0 +1,44 @@
+//go:build linux && cgo && !agent
+
+package cluster
+
+import "context"
+
+// NetworkEgressGatewayGenerated is an interface of generated methods for NetworkEgressGateway.
+type NetworkEgressGatewayGenerated interface {
+	// GetNetworkEgressGatewayConfig returns all available NetworkEgressGateway Config
+	// generator: network_egress_gateway GetMany
+	GetNetworkEgressGatewayConfig(ctx context.Context, db tx, networkEgressGatewayID int, filters ...ConfigFilter) (map[string]string, error)
+
+	// GetNetworkEgressGateways returns all available network_egress_gateways.
+	// generator: network_egress_gateway GetMany
+	GetNetworkEgressGateways(ctx context.Context, db dbtx, filters ...NetworkEgressGatewayFilter) ([]NetworkEgressGateway, error)
+
+	// GetNetworkEgressGateway returns the network_egress_gateway with the given key.
+	// generator: network_egress_gateway GetOne
+	GetNetworkEgressGateway(ctx context.Context, db dbtx, networkID int64, address string) (*NetworkEgressGateway, error)
+
+	// GetNetworkEgressGatewayID return the ID of the network_egress_gateway with the given key.
+	// generator: network_egress_gateway ID
+	GetNetworkEgressGatewayID(ctx context.Context, db tx, networkID int64, address string) (int64, error)
+
+	// CreateNetworkEgressGatewayConfig adds new network_egress_gateway Config to the database.
+	// generator: network_egress_gateway Create
+	CreateNetworkEgressGatewayConfig(ctx context.Context, db dbtx, networkEgressGatewayID int64, config map[string]string) error
+
+	// CreateNetworkEgressGateway adds a new network_egress_gateway to the database.
+	// generator: network_egress_gateway Create
+	CreateNetworkEgressGateway(ctx context.Context, db dbtx, object NetworkEgressGateway) (int64, error)
+
+	// UpdateNetworkEgressGatewayConfig updates the network_egress_gateway Config matching the given key parameters.
+	// generator: network_egress_gateway Update
+	UpdateNetworkEgressGatewayConfig(ctx context.Context, db tx, networkEgressGatewayID int64, config map[string]string) error
+
+	// UpdateNetworkEgressGateway updates the network_egress_gateway matching the given key parameters.
+	// generator: network_egress_gateway Update
+	UpdateNetworkEgressGateway(ctx context.Context, db tx, networkID int64, address string, object NetworkEgressGateway) error
+
+	// DeleteNetworkEgressGateway deletes the network_egress_gateway matching the given key parameters.
+	// generator: network_egress_gateway DeleteOne-by-NetworkID-and-ID
+	DeleteNetworkEgressGateway(ctx context.Context, db dbtx, networkID int64, id int64) error
+}
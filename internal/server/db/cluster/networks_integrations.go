@@ -35,11 +35,15 @@ import (
 const (
 	// NetworkIntegrationTypeOVN represents an OVN network integration.
 	NetworkIntegrationTypeOVN = iota
+
+	// NetworkIntegrationTypeWireGuard represents a WireGuard network integration.
+	NetworkIntegrationTypeWireGuard
 )
 
 // NetworkIntegrationTypeNames is a map between DB type to their string representation.
 var NetworkIntegrationTypeNames = map[int]string{
-	NetworkIntegrationTypeOVN: "ovn",
+	NetworkIntegrationTypeOVN:       "ovn",
+	NetworkIntegrationTypeWireGuard: "wireguard",
 }
 
 // NetworkIntegration is a value object holding db-related details about a network integration.
@@ -0,0 +1,56 @@
+//go:build linux && cgo && !agent
+
+package cluster
+
+import (
+	"github.com/lxc/incus/v7/shared/api"
+)
+
+// Code generation directives.
+//
+//generate-database:mapper target networks_leases.mapper.go
+//generate-database:mapper reset -i -b "//go:build linux && cgo && !agent"
+//
+//generate-database:mapper stmt -e network_lease objects table=networks_leases
+//generate-database:mapper stmt -e network_lease objects-by-NetworkID table=networks_leases
+//generate-database:mapper stmt -e network_lease objects-by-NetworkID-and-Address table=networks_leases
+//generate-database:mapper stmt -e network_lease id table=networks_leases
+//generate-database:mapper stmt -e network_lease create table=networks_leases
+//generate-database:mapper stmt -e network_lease update table=networks_leases
+//generate-database:mapper stmt -e network_lease delete-by-NetworkID-and-ID table=networks_leases
+//
+//generate-database:mapper method -i -e network_lease GetMany table=networks_leases
+//generate-database:mapper method -i -e network_lease GetOne table=networks_leases
+//generate-database:mapper method -i -e network_lease ID table=networks_leases
+//generate-database:mapper method -i -e network_lease Create table=networks_leases
+//generate-database:mapper method -i -e network_lease Update table=networks_leases
+//generate-database:mapper method -i -e network_lease DeleteOne-by-NetworkID-and-ID table=networks_leases
+
+// NetworkLease is the generated entity backing the networks_leases table.
+// Each record reserves an address of the network, optionally for a specific MAC address.
+type NetworkLease struct {
+	ID          int64
+	NetworkID   int64  `db:"primary=yes&column=network_id"`
+	Address     string `db:"primary=yes"`
+	Hwaddr      string
+	Hostname    string
+	Description string
+}
+
+// NetworkLeaseFilter defines the optional WHERE-clause fields.
+type NetworkLeaseFilter struct {
+	ID        *int64
+	NetworkID *int64
+	Address   *string
+}
+
+// ToAPI converts the DB record into the external API type.
+func (n *NetworkLease) ToAPI() *api.NetworkLease {
+	return &api.NetworkLease{
+		Hostname:    n.Hostname,
+		Hwaddr:      n.Hwaddr,
+		Address:     n.Address,
+		Type:        "reserved",
+		Description: n.Description,
+	}
+}
@@ -0,0 +1,32 @@
+//go:build linux && cgo && !agent
+
+package cluster
+
+import "context"
+
+// NetworkLeaseGenerated is an interface of generated methods for NetworkLease.
+type NetworkLeaseGenerated interface {
+	// GetNetworkLeases returns all available network_leases.
+	// generator: network_lease GetMany
+	GetNetworkLeases(ctx context.Context, db dbtx, filters ...NetworkLeaseFilter) ([]NetworkLease, error)
+
+	// GetNetworkLease returns the network_lease with the given key.
+	// generator: network_lease GetOne
+	GetNetworkLease(ctx context.Context, db dbtx, networkID int64, address string) (*NetworkLease, error)
+
+	// GetNetworkLeaseID return the ID of the network_lease with the given key.
+	// generator: network_lease ID
+	GetNetworkLeaseID(ctx context.Context, db tx, networkID int64, address string) (int64, error)
+
+	// CreateNetworkLease adds a new network_lease to the database.
+	// generator: network_lease Create
+	CreateNetworkLease(ctx context.Context, db dbtx, object NetworkLease) (int64, error)
+
+	// UpdateNetworkLease updates the network_lease matching the given key parameters.
+	// generator: network_lease Update
+	UpdateNetworkLease(ctx context.Context, db tx, networkID int64, address string, object NetworkLease) error
+
+	// DeleteNetworkLease deletes the network_lease matching the given key parameters.
+	// generator: network_lease DeleteOne-by-NetworkID-and-ID
+	DeleteNetworkLease(ctx context.Context, db dbtx, networkID int64, id int64) error
+}
@@ -267,6 +267,25 @@ CREATE TABLE "networks_config" (
     FOREIGN KEY (network_id) REFERENCES "networks" (id) ON DELETE CASCADE,
     FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE
 );
+CREATE TABLE "networks_egress_gateways" (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    network_id INTEGER NOT NULL,
+    address TEXT NOT NULL,
+    description TEXT NOT NULL,
+    instances TEXT NOT NULL,
+    profiles TEXT NOT NULL,
+    projects TEXT NOT NULL,
+    UNIQUE (network_id, address),
+    FOREIGN KEY (network_id) REFERENCES "networks" (id) ON DELETE CASCADE
+);
+CREATE TABLE "networks_egress_gateways_config" (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    network_egress_gateway_id INTEGER NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    UNIQUE (network_egress_gateway_id, key),
+    FOREIGN KEY (network_egress_gateway_id) REFERENCES "networks_egress_gateways" (id) ON DELETE CASCADE
+);
 CREATE TABLE "networks_forwards" (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     network_id INTEGER NOT NULL,
@@ -301,6 +320,16 @@ CREATE TABLE networks_integrations_config (
     UNIQUE (network_integration_id, key),
     FOREIGN KEY (network_integration_id) REFERENCES networks_integrations (id) ON DELETE CASCADE
 );
+CREATE TABLE "networks_leases" (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    network_id INTEGER NOT NULL,
+    address TEXT NOT NULL,
+    hwaddr TEXT NOT NULL,
+    hostname TEXT NOT NULL,
+    description TEXT NOT NULL,
+    UNIQUE (network_id, address),
+    FOREIGN KEY (network_id) REFERENCES "networks" (id) ON DELETE CASCADE
+);
 CREATE TABLE "networks_load_balancers" (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     network_id INTEGER NOT NULL,
@@ -522,6 +551,7 @@ CREATE TABLE "storage_buckets_keys" (
     access_key TEXT NOT NULL,
     secret_key TEXT NOT NULL,
     role TEXT NOT NULL,
+    quota TEXT NOT NULL DEFAULT '',
     UNIQUE (storage_bucket_id, name),
     FOREIGN KEY (storage_bucket_id) REFERENCES "storage_buckets" (id) ON DELETE CASCADE
 );
@@ -675,5 +705,5 @@ CREATE TABLE "warnings" (
 );
 CREATE UNIQUE INDEX warnings_unique_node_id_project_id_entity_type_code_entity_id_type_code ON warnings(IFNULL(node_id, -1), IFNULL(project_id, -1), entity_type_code, entity_id, type_code);
 
-INSERT INTO schema (version, updated_at) VALUES (77, strftime("%s"))
+INSERT INTO schema (version, updated_at) VALUES (80, strftime("%s"))
 `
@@ -114,6 +114,69 @@ var updates = map[int]schema.Update{
 	75: updateFromV74,
 	76: updateFromV75,
 	77: updateFromV76,
+	78: updateFromV77,
+	79: updateFromV78,
+	80: updateFromV79,
+}
+
+// updateFromV79 creates the networks_leases table.
+func updateFromV79(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+CREATE TABLE "networks_leases" (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    network_id INTEGER NOT NULL,
+    address TEXT NOT NULL,
+    hwaddr TEXT NOT NULL,
+    hostname TEXT NOT NULL,
+    description TEXT NOT NULL,
+    UNIQUE (network_id, address),
+    FOREIGN KEY (network_id) REFERENCES "networks" (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return fmt.Errorf("Failed creating network leases table: %w", err)
+	}
+
+	return nil
+}
+
+// updateFromV78 creates the networks_egress_gateways and networks_egress_gateways_config tables.
+func updateFromV78(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+CREATE TABLE "networks_egress_gateways" (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    network_id INTEGER NOT NULL,
+    address TEXT NOT NULL,
+    description TEXT NOT NULL,
+    instances TEXT NOT NULL,
+    profiles TEXT NOT NULL,
+    projects TEXT NOT NULL,
+    UNIQUE (network_id, address),
+    FOREIGN KEY (network_id) REFERENCES "networks" (id) ON DELETE CASCADE
+);
+
+CREATE TABLE "networks_egress_gateways_config" (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    network_egress_gateway_id INTEGER NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    UNIQUE (network_egress_gateway_id, key),
+    FOREIGN KEY (network_egress_gateway_id) REFERENCES "networks_egress_gateways" (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return fmt.Errorf("Failed creating network egress gateways tables: %w", err)
+	}
+
+	return nil
+}
+
+func updateFromV77(ctx context.Context, tx *sql.Tx) error {
+	stmts := `
+ALTER TABLE storage_buckets_keys ADD COLUMN quota TEXT NOT NULL DEFAULT '';
+`
+	_, err := tx.Exec(stmts)
+	return err
 }
 
 func updateFromV76(ctx context.Context, tx *sql.Tx) error {
@@ -80,6 +80,10 @@ const (
 	BucketBackupRename
 	BucketBackupRestore
 	VolumeRebuild
+	BucketsLifecycle
+	StoragePoolCheck
+	NetworkCapture
+	InstanceCapture
 )
 
 // Description return a human-readable description of the operation type.
@@ -213,6 +217,14 @@ func (t Type) Description() string {
 		return "Renaming bucket backup"
 	case BucketBackupRestore:
 		return "Restoring bucket backup"
+	case BucketsLifecycle:
+		return "Applying storage bucket lifecycle rules"
+	case StoragePoolCheck:
+		return "Checking storage pool"
+	case NetworkCapture:
+		return "Capturing network packets"
+	case InstanceCapture:
+		return "Capturing instance packets"
 	default:
 		return "Executing operation"
 	}
@@ -306,6 +318,15 @@ func (t Type) Permission() (auth.ObjectType, auth.Entitlement) {
 	case BucketBackupRestore:
 		return auth.ObjectTypeStorageVolume, auth.EntitlementCanEdit
 
+	case StoragePoolCheck:
+		return auth.ObjectTypeStoragePool, auth.EntitlementCanEdit
+
+	case NetworkCapture:
+		return auth.ObjectTypeNetwork, auth.EntitlementCanEdit
+
+	case InstanceCapture:
+		return auth.ObjectTypeInstance, auth.EntitlementCanEdit
+
 	default:
 		return "", ""
 	}
@@ -58,6 +58,8 @@ const (
 	UnableToUpdateClusterCertificate
 	// SELinuxNotAvailable represents the SELinux not available warning.
 	SELinuxNotAvailable
+	// StoragePoolCheckFailed represents problems found by a storage pool integrity check.
+	StoragePoolCheckFailed
 )
 
 // TypeNames associates a warning code to its name.
@@ -87,6 +89,7 @@ var TypeNames = map[Type]string{
 	StoragePoolUnvailable:             "Storage pool unavailable",
 	UnableToUpdateClusterCertificate:  "Unable to update cluster certificate",
 	SELinuxNotAvailable:               "SELinux support has been disabled",
+	StoragePoolCheckFailed:            "Storage pool integrity check found problems",
 }
 
 // Severity returns the severity of the warning type.
@@ -142,6 +145,8 @@ func (t Type) Severity() Severity {
 		return SeverityLow
 	case SELinuxNotAvailable:
 		return SeverityLow
+	case StoragePoolCheckFailed:
+		return SeverityHigh
 	}
 
 	return SeverityLow
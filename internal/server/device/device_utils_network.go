@@ -644,6 +644,12 @@ func networkSetupHostVethLimits(d *deviceCommon, oldConfig deviceConfig.Device,
 		}
 	}
 
+	// Apply traffic mirroring (after the limits as those reset the qdiscs).
+	err = networkSetupHostVethMirror(d, veth)
+	if err != nil {
+		return err
+	}
+
 	var networkPriority uint64
 	if d.config["limits.priority"] != "" {
 		networkPriority, err = strconv.ParseUint(d.config["limits.priority"], 10, 32)
@@ -671,6 +677,166 @@ func networkSetupHostVethLimits(d *deviceCommon, oldConfig deviceConfig.Device,
 	return nil
 }
 
+// networkMirrorTargetInterface returns the host interface the NIC traffic is mirrored to.
+// A mirror.target of the form "<instance>/<device>" refers to the host side interface of a NIC of
+// another instance in the same project, any other value is the name of a host interface.
+// An empty name is returned when the target NIC isn't running, its mirror being set up once it starts.
+func networkMirrorTargetInterface(d *deviceCommon) (string, error) {
+	target := d.config["mirror.target"]
+
+	instName, devName, found := strings.Cut(target, "/")
+	if !found {
+		if !network.InterfaceExists(target) {
+			return "", fmt.Errorf("Mirror target interface %q doesn't exist", target)
+		}
+
+		return target, nil
+	}
+
+	inst, err := instance.LoadByProjectAndName(d.state, d.inst.Project().Name, instName)
+	if err != nil {
+		return "", fmt.Errorf("Failed loading mirror target instance %q: %w", instName, err)
+	}
+
+	if inst.ExpandedDevices()[devName]["type"] != "nic" {
+		return "", fmt.Errorf("Mirror target instance %q has no NIC device %q", instName, devName)
+	}
+
+	hostName := inst.LocalConfig()[fmt.Sprintf("volatile.%s.host_name", devName)]
+	if !inst.IsRunning() || hostName == "" || !network.InterfaceExists(hostName) {
+		d.logger.Warn("Skipping traffic mirroring as the mirror target isn't running on this server", logger.Ctx{"target": target})
+		return "", nil
+	}
+
+	if hostName == d.config["host_name"] {
+		return "", errors.New("Cannot mirror a NIC to itself")
+	}
+
+	return hostName, nil
+}
+
+// nicMirrorRefresher is implemented by the NIC devices which can mirror their traffic to another NIC.
+type nicMirrorRefresher interface {
+	refreshMirror() error
+}
+
+// networkRefreshMirrorSources sets up again the mirrors of the NICs of the project which use the NIC as their
+// mirror target, as its host side interface gets a new name every time it starts.
+func networkRefreshMirrorSources(d *deviceCommon) {
+	target := fmt.Sprintf("%s/%s", d.inst.Name(), d.name)
+
+	instances, err := instance.LoadNodeAll(d.state, instancetype.Any)
+	if err != nil {
+		d.logger.Warn("Failed loading instances to refresh traffic mirrors", logger.Ctx{"err": err})
+		return
+	}
+
+	for _, inst := range instances {
+		if inst.Project().Name != d.inst.Project().Name || !inst.IsRunning() {
+			continue
+		}
+
+		for devName, devConfig := range inst.ExpandedDevices() {
+			if devConfig["type"] != "nic" || devConfig["mirror.target"] != target {
+				continue
+			}
+
+			volatileGet := func() map[string]string {
+				volatile := make(map[string]string)
+				prefix := fmt.Sprintf("volatile.%s.", devName)
+				for k, v := range inst.LocalConfig() {
+					after, ok := strings.CutPrefix(k, prefix)
+					if ok {
+						volatile[after] = v
+					}
+				}
+
+				return volatile
+			}
+
+			dev, err := New(inst, d.state, devName, devConfig.Clone(), false, volatileGet, nil)
+			if err != nil {
+				d.logger.Warn("Failed loading traffic mirror source device", logger.Ctx{"instance": inst.Name(), "device": devName, "err": err})
+				continue
+			}
+
+			mirrorDev, ok := dev.(nicMirrorRefresher)
+			if !ok {
+				continue
+			}
+
+			err = mirrorDev.refreshMirror()
+			if err != nil {
+				d.logger.Warn("Failed refreshing traffic mirror", logger.Ctx{"instance": inst.Name(), "device": devName, "err": err})
+			}
+		}
+	}
+}
+
+// networkSetupHostVethMirror mirrors the traffic of the host side veth device to the mirror.target interface.
+// Traffic sent by the instance is received by the host side veth device and traffic received by the instance
+// is sent by it, so ingress and egress are swapped when matching the instance directions.
+func networkSetupHostVethMirror(d *deviceCommon, veth string) error {
+	if d.config["mirror.target"] == "" {
+		return nil
+	}
+
+	target, err := networkMirrorTargetInterface(d)
+	if err != nil {
+		return err
+	}
+
+	direction := d.config["mirror.direction"]
+	if direction == "" {
+		direction = "both"
+	}
+
+	// Remove the mirror filters towards a previous host side interface of the target.
+	for _, parent := range []string{"ffff:0", "1:0"} {
+		filter := &ip.MatchallFilter{Filter: ip.Filter{Dev: veth, Parent: parent, Protocol: "all"}, Priority: 1}
+		err := filter.Delete()
+		if err != nil && !errors.Is(err, unix.ENOENT) && !errors.Is(err, unix.EINVAL) {
+			return fmt.Errorf("Failed to remove mirror tc filter: %w", err)
+		}
+	}
+
+	if target == "" {
+		return nil
+	}
+
+	actions := []ip.Action{&ip.ActionMirred{Dev: target}}
+
+	if direction != "ingress" {
+		qdiscIngress := &ip.QdiscIngress{Qdisc: ip.Qdisc{Dev: veth, Handle: "ffff:0"}}
+		err := qdiscIngress.Add()
+		if err != nil && !errors.Is(err, unix.EEXIST) {
+			return fmt.Errorf("Failed to create ingress tc qdisc: %w", err)
+		}
+
+		filter := &ip.MatchallFilter{Filter: ip.Filter{Dev: veth, Parent: "ffff:0", Protocol: "all"}, Priority: 1, Actions: actions}
+		err = filter.Add()
+		if err != nil {
+			return fmt.Errorf("Failed to create egress mirror tc filter: %w", err)
+		}
+	}
+
+	if direction != "egress" {
+		qdiscHTB := &ip.QdiscHTB{Qdisc: ip.Qdisc{Dev: veth, Handle: "1:0", Parent: "root"}}
+		err := qdiscHTB.Add()
+		if err != nil && !errors.Is(err, unix.EEXIST) {
+			return fmt.Errorf("Failed to create root tc qdisc: %w", err)
+		}
+
+		filter := &ip.MatchallFilter{Filter: ip.Filter{Dev: veth, Parent: "1:0", Protocol: "all"}, Priority: 1, Actions: actions}
+		err = filter.Add()
+		if err != nil {
+			return fmt.Errorf("Failed to create ingress mirror tc filter: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // networkClearHostVethLimits clears any network rate limits to the veth device specified in the config.
 func networkClearHostVethLimits(d *deviceCommon) error {
 	// Detached NICs cannot be cleaned up this way.
@@ -10,6 +10,7 @@ import (
 	deviceConfig "github.com/lxc/incus/v7/internal/server/device/config"
 	"github.com/lxc/incus/v7/internal/server/instance"
 	"github.com/lxc/incus/v7/internal/server/instance/instancetype"
+	"github.com/lxc/incus/v7/internal/server/network"
 	"github.com/lxc/incus/v7/internal/server/network/acl"
 	"github.com/lxc/incus/v7/shared/util"
 	"github.com/lxc/incus/v7/shared/validate"
@@ -66,6 +67,9 @@ func nicValidationRules(requiredFields []string, optionalFields []string, instCo
 		"pci":                                  validate.IsPCIAddress,
 		"attached":                             validate.Optional(validate.IsBool),
 		"connected":                            validate.Optional(validate.IsBool),
+		"mirror.target":                        validate.Optional(nicCheckMirrorTarget),
+		"mirror.direction":                     validate.Optional(validate.IsOneOf("both", "ingress", "egress")),
+		"qos.class":                            validate.IsAny,
 	}
 
 	validators := map[string]func(value string) error{}
@@ -237,3 +241,26 @@ func nicCheckIOBus(instConf instance.ConfigReader, value string) error {
 
 	return nil
 }
+
+// nicCheckMirrorTarget validates the mirror.target value, either "<instance>/<device>" or a host interface name.
+func nicCheckMirrorTarget(value string) error {
+	instName, devName, found := strings.Cut(value, "/")
+	if !found {
+		return validate.IsInterfaceName(value)
+	}
+
+	if instName == "" || devName == "" {
+		return fmt.Errorf("Invalid mirror target %q, expected <instance>/<device>", value)
+	}
+
+	return nil
+}
+
+// nicCheckQoSClass checks that the QoS class set on the NIC exists on its network.
+func nicCheckQoSClass(config deviceConfig.Device, netConfig map[string]string) error {
+	if config["qos.class"] != "" && !network.QoSHasClass(netConfig, config["qos.class"]) {
+		return fmt.Errorf("Network doesn't have the QoS class %q", config["qos.class"])
+	}
+
+	return nil
+}
@@ -46,6 +46,8 @@ import (
 
 type bridgeNetwork interface {
 	UsesDNSMasq() bool
+	EgressGatewayRefresh() error
+	QoSRefresh(hwAddr string, className string) error
 }
 
 type nicBridged struct {
@@ -350,6 +352,32 @@ func (d *nicBridged) validateConfig(instConf instance.ConfigReader, partialValid
 		//  required: no
 		//  shortdesc: Whether the NIC is connected to the host network
 		"connected",
+
+		// gendoc:generate(entity=devices, group=nic_bridged, key=mirror.target)
+		//
+		// ---
+		//  type: string
+		//  required: no
+		//  shortdesc: Instance NIC (`<instance>/<device>`) or host interface to mirror the NIC traffic to
+		"mirror.target",
+
+		// gendoc:generate(entity=devices, group=nic_bridged, key=mirror.direction)
+		//
+		// ---
+		//  type: string
+		//  default: `both`
+		//  required: no
+		//  shortdesc: Direction of the traffic to mirror, relative to the instance (`both`, `ingress` or `egress`)
+		"mirror.direction",
+
+		// gendoc:generate(entity=devices, group=nic_bridged, key=qos.class)
+		//
+		// ---
+		//  type: string
+		//  default: `network.qos_class` of the project
+		//  required: no
+		//  shortdesc: QoS class of the managed network to put the NIC in
+		"qos.class",
 	}
 
 	// checkWithManagedNetwork validates the device's settings against the managed network.
@@ -364,6 +392,11 @@ func (d *nicBridged) validateConfig(instConf instance.ConfigReader, partialValid
 
 		netConfig := n.Config()
 
+		err := nicCheckQoSClass(d.config, netConfig)
+		if err != nil {
+			return err
+		}
+
 		if d.config["ipv4.address"] != "" && !strings.Contains(d.config["ipv4.address"], "/") {
 			dhcpv4Subnet := n.DHCPv4Subnet()
 
@@ -505,6 +538,10 @@ func (d *nicBridged) validateConfig(instConf instance.ConfigReader, partialValid
 				return err
 			}
 		} else {
+			if d.config["qos.class"] != "" {
+				return errors.New("Cannot use \"qos.class\" when using unmanaged parent bridge")
+			}
+
 			// Check that static IPs are only specified with IP filtering when using an unmanaged
 			// parent bridge. A CIDR address is configured inside the instance rather than through
 			// DHCP, so it's allowed regardless.
@@ -736,7 +773,7 @@ func (d *nicBridged) UpdatableFields(oldDevice Type) []string {
 		return []string{}
 	}
 
-	return []string{"limits.ingress", "limits.egress", "limits.max", "limits.priority", "ipv4.routes", "ipv6.routes", "ipv4.routes.external", "ipv6.routes.external", "ipv4.address", "ipv6.address", "security.mac_filtering", "security.ipv4_filtering", "security.ipv6_filtering", "security.acls", "security.acls.default.egress.action", "security.acls.default.egress.logged", "security.acls.default.ingress.action", "security.acls.default.ingress.logged", "connected"}
+	return []string{"limits.ingress", "limits.egress", "limits.max", "limits.priority", "ipv4.routes", "ipv6.routes", "ipv4.routes.external", "ipv6.routes.external", "ipv4.address", "ipv6.address", "security.mac_filtering", "security.ipv4_filtering", "security.ipv6_filtering", "security.acls", "security.acls.default.egress.action", "security.acls.default.egress.logged", "security.acls.default.ingress.action", "security.acls.default.ingress.logged", "connected", "mirror.target", "mirror.direction"}
 }
 
 // Add is run when a device is added to a non-snapshot instance whether or not the instance is running.
@@ -953,6 +990,19 @@ func (d *nicBridged) Start() (*deviceConfig.RunConfig, error) {
 		return nil, err
 	}
 
+	// Cover the NIC with the egress gateways and QoS classes of the network, its MAC address may be new.
+	if ok && d.network.IsManaged() {
+		err = bridgeNet.EgressGatewayRefresh()
+		if err != nil {
+			return nil, fmt.Errorf("Failed applying network egress gateways: %w", err)
+		}
+
+		err = bridgeNet.QoSRefresh(d.config["hwaddr"], network.QoSNICClass(d.network.Config(), d.config, d.inst.Project().Config))
+		if err != nil {
+			return nil, fmt.Errorf("Failed applying network QoS classes: %w", err)
+		}
+	}
+
 	runConf := deviceConfig.RunConfig{}
 	runConf.PostHooks = []func() error{d.postStart}
 
@@ -992,9 +1042,18 @@ func (d *nicBridged) postStart() error {
 		return err
 	}
 
+	networkRefreshMirrorSources(&d.deviceCommon)
+
 	return nil
 }
 
+// refreshMirror sets up the traffic mirror of the host side interface again, following the mirror target.
+func (d *nicBridged) refreshMirror() error {
+	networkVethFillFromVolatile(d.config, d.volatileGet())
+
+	return networkSetupHostVethMirror(&d.deviceCommon, d.config["host_name"])
+}
+
 // Update applies configuration changes to a started device.
 func (d *nicBridged) Update(oldDevices deviceConfig.Devices, isRunning bool) error {
 	oldConfig := oldDevices[d.name]
@@ -28,6 +28,7 @@ import (
 	"github.com/lxc/incus/v7/internal/server/network/acl"
 	addressset "github.com/lxc/incus/v7/internal/server/network/address-set"
 	"github.com/lxc/incus/v7/internal/server/network/ovn"
+	ovnNB "github.com/lxc/incus/v7/internal/server/network/ovn/schema/ovn-nb"
 	"github.com/lxc/incus/v7/internal/server/project"
 	"github.com/lxc/incus/v7/internal/server/state"
 	localUtil "github.com/lxc/incus/v7/internal/server/util"
@@ -50,6 +51,7 @@ type ovnNet interface {
 	InstanceDevicePortStop(ovsExternalOVNPort ovn.OVNSwitchPort, opts *network.OVNInstanceNICStopOpts) error
 	InstanceDevicePortRemove(instanceUUID string, devName string, devConfig deviceConfig.Device, hasDuplicate bool) error
 	InstanceDevicePortIPs(instanceUUID string, deviceName string) ([]net.IP, error)
+	InstanceDevicePortName(instanceUUID string, deviceName string) ovn.OVNSwitchPort
 }
 
 type nicOVN struct {
@@ -79,7 +81,7 @@ func (d *nicOVN) UpdatableFields(oldDevice Type) []string {
 		return []string{}
 	}
 
-	return []string{"security.acls", "limits.ingress", "limits.egress", "limits.max", "limits.priority", "connected", "ipv4.address.external", "ipv6.address.external"}
+	return []string{"security.acls", "limits.ingress", "limits.egress", "limits.max", "limits.priority", "connected", "ipv4.address.external", "ipv6.address.external", "mirror.target", "mirror.direction", "qos.class"}
 }
 
 // validateConfig checks the supplied config for correctness.
@@ -352,6 +354,32 @@ func (d *nicOVN) validateConfig(instConf instance.ConfigReader, partialValidatio
 		//  shortdesc: Whether the NIC is connected to the host network (requires `acceleration` set to `none`)
 		"connected",
 
+		// gendoc:generate(entity=devices, group=nic_ovn, key=mirror.target)
+		//
+		// ---
+		//  type: string
+		//  required: no
+		//  shortdesc: Instance NIC (`<instance>/<device>`) or host interface to mirror the NIC traffic to (added to the OVN integration bridge if not already connected to it)
+		"mirror.target",
+
+		// gendoc:generate(entity=devices, group=nic_ovn, key=mirror.direction)
+		//
+		// ---
+		//  type: string
+		//  default: `both`
+		//  required: no
+		//  shortdesc: Direction of the traffic to mirror, relative to the instance (`both`, `ingress` or `egress`)
+		"mirror.direction",
+
+		// gendoc:generate(entity=devices, group=nic_ovn, key=qos.class)
+		//
+		// ---
+		//  type: string
+		//  default: `network.qos_class` of the project
+		//  required: no
+		//  shortdesc: QoS class of the network to put the NIC in
+		"qos.class",
+
 		// gendoc:generate(entity=devices, group=nic_ovn, key=io.bus)
 		//
 		// ---
@@ -397,6 +425,11 @@ func (d *nicOVN) validateConfig(instConf instance.ConfigReader, partialValidatio
 	d.network = ovnNet // Stored loaded network for use by other functions.
 	netConfig := d.network.Config()
 
+	err = nicCheckQoSClass(d.config, netConfig)
+	if err != nil {
+		return err
+	}
+
 	if !util.IsNoneOrEmpty(d.config["ipv4.address"]) {
 		ipAddr, subnet, err := net.ParseCIDR(netConfig["ipv4.address"])
 		if err != nil {
@@ -991,6 +1024,7 @@ func (d *nicOVN) Start() (*deviceConfig.RunConfig, error) {
 		DeviceConfig: portConfig,
 		UplinkConfig: uplinkConfig,
 		LastStateIPs: lastStateIPs, // Pass in volatile last state IPs for use with sticky DHCPv4 hint.
+		QoSClass:     network.QoSNICClass(d.network.Config(), d.config, d.inst.Project().Config),
 	}, nil)
 	if err != nil {
 		return nil, fmt.Errorf("Failed setting up OVN port: %w", err)
@@ -1028,6 +1062,12 @@ func (d *nicOVN) Start() (*deviceConfig.RunConfig, error) {
 		reverter.Add(cleanup)
 	}
 
+	// Mirror the logical switch port traffic if requested.
+	err = d.setupMirror(logicalPortName)
+	if err != nil {
+		return nil, err
+	}
+
 	runConf := deviceConfig.RunConfig{}
 
 	// Get local chassis ID for chassis group.
@@ -1133,6 +1173,8 @@ func (d *nicOVN) postStart() error {
 		return err
 	}
 
+	networkRefreshMirrorSources(&d.deviceCommon)
+
 	return nil
 }
 
@@ -1159,14 +1201,17 @@ func (d *nicOVN) Update(oldDevices deviceConfig.Devices, isRunning bool) error {
 		}
 	}
 
-	// Apply any changes needed when assigned ACLs, external NAT addresses or nic limit changes.
+	// Apply any changes needed when assigned ACLs, external NAT addresses, nic limit or QoS class changes.
 	if d.config["security.acls"] != oldConfig["security.acls"] ||
 		d.config["ipv4.address.external"] != oldConfig["ipv4.address.external"] ||
 		d.config["ipv6.address.external"] != oldConfig["ipv6.address.external"] ||
 		d.config["limits.ingress"] != oldConfig["limits.ingress"] ||
 		d.config["limits.egress"] != oldConfig["limits.egress"] ||
 		d.config["limits.max"] != oldConfig["limits.max"] ||
-		d.config["limits.priority"] != oldConfig["limits.priority"] {
+		d.config["limits.priority"] != oldConfig["limits.priority"] ||
+		d.config["mirror.target"] != oldConfig["mirror.target"] ||
+		d.config["mirror.direction"] != oldConfig["mirror.direction"] ||
+		d.config["qos.class"] != oldConfig["qos.class"] {
 		// Work out which ACLs have been removed and remove logical port from those groups.
 		oldACLs := util.SplitNTrimSpace(oldConfig["security.acls"], ",", -1, true)
 		newACLs := util.SplitNTrimSpace(d.config["security.acls"], ",", -1, true)
@@ -1219,17 +1264,23 @@ func (d *nicOVN) Update(oldDevices deviceConfig.Devices, isRunning bool) error {
 			}
 
 			// Update OVN logical switch port for instance.
-			_, _, err := d.network.InstanceDevicePortStart(&network.OVNInstanceNICSetupOpts{
+			logicalPortName, _, err := d.network.InstanceDevicePortStart(&network.OVNInstanceNICSetupOpts{
 				InstanceUUID:             d.inst.LocalConfig()["volatile.uuid"],
 				DNSName:                  d.inst.Name(),
 				DeviceName:               d.name,
 				DeviceConfig:             nicNormalizedAddressConfig(d.config),
 				UplinkConfig:             uplinkConfig,
 				RemovedExternalAddresses: removedExternalIPs,
+				QoSClass:                 network.QoSNICClass(d.network.Config(), d.config, d.inst.Project().Config),
 			}, removedACLs)
 			if err != nil {
 				return fmt.Errorf("Failed updating OVN port: %w", err)
 			}
+
+			err = d.setupMirror(logicalPortName)
+			if err != nil {
+				return err
+			}
 		}
 
 		if len(removedACLs) > 0 {
@@ -1669,6 +1720,66 @@ func (d *nicOVN) setupHostNIC(hostName string, ovnPortName ovn.OVNSwitchPort) (r
 	return cleanup, err
 }
 
+// setupMirror mirrors the traffic of the logical switch port to the mirror.target interface through an OVN
+// local mirror. The target interface is connected to the integration bridge if needed and used as the mirror sink.
+func (d *nicOVN) setupMirror(logicalPortName ovn.OVNSwitchPort) error {
+	if d.config["mirror.target"] == "" {
+		return d.ovnnb.SetLogicalSwitchPortMirror(context.TODO(), logicalPortName, nil)
+	}
+
+	target, err := networkMirrorTargetInterface(&d.deviceCommon)
+	if err != nil {
+		return err
+	}
+
+	if target == "" {
+		return d.ovnnb.SetLogicalSwitchPortMirror(context.TODO(), logicalPortName, nil)
+	}
+
+	vswitch, err := d.state.OVS()
+	if err != nil {
+		return fmt.Errorf("Failed to connect to OVS: %w", err)
+	}
+
+	integrationBridge := d.state.GlobalConfig.NetworkOVNIntegrationBridge()
+
+	err = vswitch.CreateBridgePort(context.TODO(), integrationBridge, target, true)
+	if err != nil {
+		return fmt.Errorf("Failed connecting mirror target %q to OVS integration bridge: %w", target, err)
+	}
+
+	err = vswitch.SetInterfaceMirrorID(context.TODO(), target, target)
+	if err != nil {
+		return fmt.Errorf("Failed setting mirror ID on %q: %w", target, err)
+	}
+
+	// The mirror filter is relative to the logical switch port, matching the instance directions.
+	mirror := &ovn.OVNMirror{Filter: ovnNB.MirrorFilterBoth, Sink: target}
+
+	switch d.config["mirror.direction"] {
+	case "ingress":
+		mirror.Filter = ovnNB.MirrorFilterToLport
+	case "egress":
+		mirror.Filter = ovnNB.MirrorFilterFromLport
+	}
+
+	err = d.ovnnb.SetLogicalSwitchPortMirror(context.TODO(), logicalPortName, mirror)
+	if err != nil {
+		return fmt.Errorf("Failed setting up OVN mirror: %w", err)
+	}
+
+	return nil
+}
+
+// refreshMirror sets up the mirror of the logical switch port again, following the mirror target.
+func (d *nicOVN) refreshMirror() error {
+	if d.config["mirror.target"] == "" {
+		return nil
+	}
+
+	return d.setupMirror(d.network.InstanceDevicePortName(d.inst.LocalConfig()["volatile.uuid"], d.name))
+}
+
 // isVirtualNIC determines whether the device is non-accelerated.
 func (d *nicOVN) isVirtualNIC() bool {
 	return slices.Contains([]string{"", "none"}, d.config["acceleration"])
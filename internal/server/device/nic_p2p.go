@@ -143,6 +143,23 @@ func (d *nicP2P) validateConfig(instConf instance.ConfigReader, partialValidatio
 		//  required: no
 		//  shortdesc: Whether the NIC is connected to the host network
 		"connected",
+
+		// gendoc:generate(entity=devices, group=nic_p2p, key=mirror.target)
+		//
+		// ---
+		//  type: string
+		//  required: no
+		//  shortdesc: Instance NIC (`<instance>/<device>`) or host interface to mirror the NIC traffic to
+		"mirror.target",
+
+		// gendoc:generate(entity=devices, group=nic_p2p, key=mirror.direction)
+		//
+		// ---
+		//  type: string
+		//  default: `both`
+		//  required: no
+		//  shortdesc: Direction of the traffic to mirror, relative to the instance (`both`, `ingress` or `egress`)
+		"mirror.direction",
 	}
 
 	err := d.config.Validate(nicValidationRules([]string{}, optionalFields, instConf))
@@ -170,7 +187,7 @@ func (d *nicP2P) UpdatableFields(oldDevice Type) []string {
 		return []string{}
 	}
 
-	return []string{"limits.ingress", "limits.egress", "limits.max", "limits.priority", "ipv4.routes", "ipv6.routes", "connected"}
+	return []string{"limits.ingress", "limits.egress", "limits.max", "limits.priority", "ipv4.routes", "ipv6.routes", "connected", "mirror.target", "mirror.direction"}
 }
 
 // Start is run when the device is added to a running instance or instance is starting up.
@@ -266,11 +283,27 @@ func (d *nicP2P) Start() (*deviceConfig.RunConfig, error) {
 			}...)
 	}
 
+	runConf.PostHooks = []func() error{d.postStart}
+
 	reverter.Success()
 
 	return &runConf, nil
 }
 
+// postStart is run after the device is added to the instance.
+func (d *nicP2P) postStart() error {
+	networkRefreshMirrorSources(&d.deviceCommon)
+
+	return nil
+}
+
+// refreshMirror sets up the traffic mirror of the host side interface again, following the mirror target.
+func (d *nicP2P) refreshMirror() error {
+	networkVethFillFromVolatile(d.config, d.volatileGet())
+
+	return networkSetupHostVethMirror(&d.deviceCommon, d.config["host_name"])
+}
+
 // Update applies configuration changes to a started device.
 func (d *nicP2P) Update(oldDevices deviceConfig.Devices, isRunning bool) error {
 	if !isRunning {
@@ -45,7 +45,7 @@ func (d *nicRouted) UpdatableFields(oldDevice Type) []string {
 		return []string{}
 	}
 
-	return []string{"limits.ingress", "limits.egress", "limits.max", "limits.priority", "connected"}
+	return []string{"limits.ingress", "limits.egress", "limits.max", "limits.priority", "connected", "mirror.target", "mirror.direction"}
 }
 
 // validateConfig checks the supplied config for correctness.
@@ -262,6 +262,23 @@ func (d *nicRouted) validateConfig(instConf instance.ConfigReader, partialValida
 		//  required: no
 		//  shortdesc: Whether the NIC is connected to the host network
 		"connected",
+
+		// gendoc:generate(entity=devices, group=nic_routed, key=mirror.target)
+		//
+		// ---
+		//  type: string
+		//  required: no
+		//  shortdesc: Instance NIC (`<instance>/<device>`) or host interface to mirror the NIC traffic to
+		"mirror.target",
+
+		// gendoc:generate(entity=devices, group=nic_routed, key=mirror.direction)
+		//
+		// ---
+		//  type: string
+		//  default: `both`
+		//  required: no
+		//  shortdesc: Direction of the traffic to mirror, relative to the instance (`both`, `ingress` or `egress`)
+		"mirror.direction",
 	}
 
 	rules := nicValidationRules(requiredFields, optionalFields, instConf)
@@ -825,11 +842,27 @@ func (d *nicRouted) Start() (*deviceConfig.RunConfig, error) {
 		}...)
 	}
 
+	runConf.PostHooks = []func() error{d.postStart}
+
 	reverter.Success()
 
 	return &runConf, nil
 }
 
+// postStart is run after the device is added to the instance.
+func (d *nicRouted) postStart() error {
+	networkRefreshMirrorSources(&d.deviceCommon)
+
+	return nil
+}
+
+// refreshMirror sets up the traffic mirror of the host side interface again, following the mirror target.
+func (d *nicRouted) refreshMirror() error {
+	networkVethFillFromVolatile(d.config, d.volatileGet())
+
+	return networkSetupHostVethMirror(&d.deviceCommon, d.config["host_name"])
+}
+
 // setupParentSysctls configures the required sysctls on the parent to allow l2proxy to work.
 // Because of our policy not to modify sysctls on existing interfaces, this should only be called
 // if we created the parent interface.
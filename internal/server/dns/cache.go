@@ -0,0 +1,172 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"github.com/lxc/incus/v7/internal/server/db"
+	dbCluster "github.com/lxc/incus/v7/internal/server/db/cluster"
+	"github.com/lxc/incus/v7/shared/api"
+)
+
+// How long a rendered zone is used before checking it for changes. Changes to the zones and their records
+// apply right away, the others (such as instance addresses) within that delay.
+const zoneCacheTTL = 10 * time.Second
+
+// cachedZone is a rendered zone along with the signatures of its record sets.
+// Its SOA serial only changes when its records change.
+type cachedZone struct {
+	hash      string
+	keyConfig string
+	checked   time.Time
+
+	data *zoneData
+	key  *dnssecKey
+
+	// Signatures of the record sets of the zone, by record set.
+	signatures map[string]*dns.RRSIG
+}
+
+// ZoneChanged makes the next queries to the zone render it again.
+func (s *Server) ZoneChanged(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cached := s.zones[strings.ToLower(name)]
+	if cached != nil {
+		cached.checked = time.Time{}
+	}
+}
+
+// closestZone returns the name of the closest zone containing name, or an empty string if there is none.
+// Names are matched case insensitively, as resolvers may randomize their case.
+// Must be called with the server lock held.
+func (s *Server) closestZone(name string) (string, error) {
+	if s.db != nil && time.Since(s.zoneNamesChecked) >= zoneCacheTTL {
+		zoneNames := map[string]string{}
+
+		err := s.db.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			zones, err := dbCluster.GetNetworkZones(ctx, tx.Tx())
+			if err != nil {
+				return err
+			}
+
+			for _, zone := range zones {
+				zoneNames[strings.ToLower(zone.Name)] = zone.Name
+			}
+
+			return nil
+		})
+		if err != nil {
+			return "", fmt.Errorf("Failed loading DNS zones: %w", err)
+		}
+
+		s.zoneNames = zoneNames
+		s.zoneNamesChecked = time.Now()
+	}
+
+	for candidate := strings.ToLower(name); candidate != ""; _, candidate, _ = strings.Cut(candidate, ".") {
+		zoneName, ok := s.zoneNames[candidate]
+		if ok {
+			return zoneName, nil
+		}
+	}
+
+	return "", nil
+}
+
+// cachedZone returns the rendered zone, rendering it again if it may have changed.
+// Must be called with the server lock held.
+func (s *Server) cachedZone(info api.NetworkZone) (*cachedZone, error) {
+	name := strings.ToLower(info.Name)
+	keyConfig := info.Config["dnssec.enabled"] + "/" + info.Config["dnssec.private_key"]
+
+	cached := s.zones[name]
+	if cached != nil && cached.keyConfig == keyConfig && time.Since(cached.checked) < zoneCacheTTL {
+		return cached, nil
+	}
+
+	zone, err := s.zoneRetriever(info.Name, true)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := zoneHash(zone.Content)
+	if err != nil {
+		return nil, fmt.Errorf("Bad DNS record in zone %q: %w", info.Name, err)
+	}
+
+	if cached != nil && cached.keyConfig == keyConfig && cached.hash == hash {
+		cached.checked = time.Now()
+		return cached, nil
+	}
+
+	data, err := newZoneData(zone.Info.Name, zone.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the serial increasing even with several changes within the same second.
+	if cached != nil && data.soa.Serial <= cached.data.soa.Serial {
+		data.soa.Serial = cached.data.soa.Serial + 1
+	}
+
+	key, err := zoneDNSSECKey(zone)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading DNSSEC key: %w", err)
+	}
+
+	if key != nil {
+		data.records[data.name] = append(data.records[data.name], key.dnskey)
+	}
+
+	if s.zones == nil {
+		s.zones = map[string]*cachedZone{}
+	}
+
+	cached = &cachedZone{
+		hash:       hash,
+		keyConfig:  keyConfig,
+		checked:    time.Now(),
+		data:       data,
+		key:        key,
+		signatures: map[string]*dns.RRSIG{},
+	}
+
+	s.zones[name] = cached
+
+	return cached, nil
+}
+
+// transfer returns the records of the zone for a zone transfer, starting and ending with its SOA record.
+func (c *cachedZone) transfer() []dns.RR {
+	names := make([]string, 0, len(c.data.records))
+	for name := range c.data.records {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	rrs := []dns.RR{c.data.soa}
+	for _, name := range names {
+		for _, rr := range c.data.records[name] {
+			if rr == dns.RR(c.data.soa) {
+				continue
+			}
+
+			rrs = append(rrs, rr)
+		}
+	}
+
+	return append(rrs, c.data.soa)
+}
+
+// sign adds the DNSSEC records to a reply from the zone, reusing the signatures of the zone record sets.
+func (c *cachedZone) sign(m *dns.Msg, res *queryResult) error {
+	return c.key.sign(c.data, m, res, c.signatures)
+}
@@ -0,0 +1,215 @@
+package dns
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"github.com/lxc/incus/v7/shared/util"
+)
+
+// DNSSEC signatures are generated on the fly and valid for a week. The signatures of the zone record sets are
+// reused until half of their validity period has passed.
+const (
+	dnssecInception  = time.Hour
+	dnssecExpiration = 7 * 24 * time.Hour
+)
+
+// dnssecKey is the key used to sign a zone.
+type dnssecKey struct {
+	dnskey  *dns.DNSKEY
+	private *ecdsa.PrivateKey
+}
+
+// DNSSECGenerateKey returns a new private key for signing a zone.
+func DNSSECGenerateKey() (string, error) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("Failed generating DNSSEC key: %w", err)
+	}
+
+	raw, err := privateKey.Bytes()
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+// DNSSECValidateKey checks that the value is a valid private key for signing a zone.
+func DNSSECValidateKey(value string) error {
+	_, err := newDNSSECKey(".", value)
+
+	return err
+}
+
+// DNSSECDS returns the DS record to publish in the parent zone for the key signing the zone.
+func DNSSECDS(zoneName string, privateKey string) (string, error) {
+	key, err := newDNSSECKey(zoneName, privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	ds := key.dnskey.ToDS(dns.SHA256)
+	if ds == nil {
+		return "", errors.New("Failed generating the DS record")
+	}
+
+	return fmt.Sprintf("%d %d %d %s", ds.KeyTag, ds.Algorithm, ds.DigestType, ds.Digest), nil
+}
+
+// newDNSSECKey loads the key signing the zone from its private key.
+// A single ECDSA P-256 key is used as both the key signing key and the zone signing key.
+func newDNSSECKey(zoneName string, privateKey string) (*dnssecKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid DNSSEC key: %w", err)
+	}
+
+	private, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), raw)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid DNSSEC key: %w", err)
+	}
+
+	public, err := private.PublicKey.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	dnskey := &dns.DNSKEY{
+		Hdr:       dns.RR_Header{Name: dns.Fqdn(strings.ToLower(zoneName)), Rrtype: dns.TypeDNSKEY, Class: dns.ClassINET, Ttl: 3600},
+		Flags:     dns.ZONE | dns.SEP,
+		Protocol:  3,
+		Algorithm: dns.ECDSAP256SHA256,
+		// Strip the uncompressed point prefix.
+		PublicKey: base64.StdEncoding.EncodeToString(public[1:]),
+	}
+
+	return &dnssecKey{dnskey: dnskey, private: private}, nil
+}
+
+// zoneDNSSECKey returns the key signing the zone, if DNSSEC is enabled on it.
+func zoneDNSSECKey(zone *Zone) (*dnssecKey, error) {
+	if util.IsFalseOrEmpty(zone.Info.Config["dnssec.enabled"]) || zone.Info.Config["dnssec.private_key"] == "" {
+		return nil, nil
+	}
+
+	return newDNSSECKey(zone.Info.Name, zone.Info.Config["dnssec.private_key"])
+}
+
+// sign adds the DNSSEC records to a reply from the zone, reusing and adding to the signatures of the zone record sets.
+// Denial of existence uses minimally covering NSEC records generated on the fly, with NXDOMAIN
+// replies turned into NODATA ones so that the rest of the zone can't be walked.
+func (k *dnssecKey) sign(z *zoneData, m *dns.Msg, res *queryResult, signatures map[string]*dns.RRSIG) error {
+	if res.denial != "" {
+		nsec := &dns.NSEC{
+			Hdr:        dns.RR_Header{Name: res.denial, Rrtype: dns.TypeNSEC, Class: dns.ClassINET, Ttl: z.negativeTTL()},
+			NextDomain: "\\000." + res.denial,
+			TypeBitMap: append([]uint16{dns.TypeRRSIG, dns.TypeNSEC}, res.denialTypes...),
+		}
+
+		slices.Sort(nsec.TypeBitMap)
+		nsec.TypeBitMap = slices.Compact(nsec.TypeBitMap)
+
+		m.Ns = append(m.Ns, nsec)
+		if m.Rcode == dns.RcodeNameError {
+			m.Rcode = dns.RcodeSuccess
+		}
+	}
+
+	var err error
+
+	m.Answer, err = k.signRRs(z, m.Answer, signatures)
+	if err != nil {
+		return err
+	}
+
+	// Delegations aren't signed as the zone doesn't know about the child zone keys.
+	if !res.delegation {
+		m.Ns, err = k.signRRs(z, m.Ns, signatures)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// signRRs returns the records followed by the signatures of each of their record sets.
+func (k *dnssecKey) signRRs(z *zoneData, rrs []dns.RR, signatures map[string]*dns.RRSIG) ([]dns.RR, error) {
+	type rrsetKey struct {
+		name   string
+		rrtype uint16
+	}
+
+	rrsets := map[rrsetKey][]dns.RR{}
+	order := []rrsetKey{}
+	for _, rr := range rrs {
+		key := rrsetKey{name: strings.ToLower(rr.Header().Name), rrtype: rr.Header().Rrtype}
+		_, ok := rrsets[key]
+		if !ok {
+			order = append(order, key)
+		}
+
+		rrsets[key] = append(rrsets[key], rr)
+	}
+
+	now := time.Now()
+	signed := slices.Clone(rrs)
+	for _, key := range order {
+		// Only the record sets of the zone are cached, not the ones generated for the query.
+		cacheKey := ""
+		if key.rrtype != dns.TypeNSEC && rrsetInZone(z, rrsets[key]) {
+			for _, rr := range rrsets[key] {
+				cacheKey += rr.String() + "\n"
+			}
+
+			rrsig := signatures[cacheKey]
+			if rrsig != nil && now.Add(dnssecExpiration/2).Before(time.Unix(int64(rrsig.Expiration), 0)) {
+				signed = append(signed, rrsig)
+				continue
+			}
+		}
+
+		rrsig := &dns.RRSIG{
+			Hdr:        dns.RR_Header{Name: key.name, Rrtype: dns.TypeRRSIG, Class: dns.ClassINET, Ttl: rrsets[key][0].Header().Ttl},
+			KeyTag:     k.dnskey.KeyTag(),
+			SignerName: k.dnskey.Hdr.Name,
+			Algorithm:  k.dnskey.Algorithm,
+			Inception:  uint32(now.Add(-dnssecInception).Unix()),
+			Expiration: uint32(now.Add(dnssecExpiration).Unix()),
+		}
+
+		err := rrsig.Sign(k.private, rrsets[key])
+		if err != nil {
+			return nil, fmt.Errorf("Failed signing %s records of %q: %w", dns.TypeToString[key.rrtype], key.name, err)
+		}
+
+		if cacheKey != "" {
+			signatures[cacheKey] = rrsig
+		}
+
+		signed = append(signed, rrsig)
+	}
+
+	return signed, nil
+}
+
+// rrsetInZone returns whether the records are records of the zone, rather than synthesized from a wildcard.
+func rrsetInZone(z *zoneData, rrs []dns.RR) bool {
+	for _, rr := range rrs {
+		if !slices.Contains(z.records[rr.Header().Name], rr) {
+			return false
+		}
+	}
+
+	return true
+}
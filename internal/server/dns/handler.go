@@ -47,7 +47,8 @@ func (d dnsHandler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 	}
 
 	// Check that it's a supported request type.
-	if r.Question[0].Qtype != dns.TypeAXFR && r.Question[0].Qtype != dns.TypeIXFR && r.Question[0].Qtype != dns.TypeSOA {
+	qtype := r.Question[0].Qtype
+	if qtype == dns.TypeANY || r.Question[0].Qclass != dns.ClassINET {
 		m := &dns.Msg{}
 		m.SetRcode(r, dns.RcodeNotImplemented)
 		err := w.WriteMsg(m)
@@ -72,13 +73,19 @@ func (d dnsHandler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 		return
 	}
 
+	// Handle standard queries.
+	if qtype != dns.TypeAXFR && qtype != dns.TypeIXFR {
+		d.serveQuery(w, r, name, ip)
+		return
+	}
+
 	// Prepare the response.
 	m := &dns.Msg{}
 	m.SetReply(r)
 	m.Authoritative = true
 
 	// Load the zone.
-	zone, err := d.server.zoneRetriever(name, r.Question[0].Qtype != dns.TypeSOA)
+	zone, err := d.server.zoneRetriever(name, false)
 	if err != nil {
 		// On failure, return NXDOMAIN.
 		m := &dns.Msg{}
@@ -104,30 +111,23 @@ func (d dnsHandler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 		return
 	}
 
-	zoneRR := dns.NewZoneParser(strings.NewReader(zone.Content), "", "")
-	for {
-		rr, ok := zoneRR.Next()
-		if !ok {
-			err := zoneRR.Err()
-			if err != nil {
-				logger.Errorf("Bad DNS record in zone %q: %v", name, err)
-
-				m := &dns.Msg{}
-				m.SetRcode(r, dns.RcodeFormatError)
-				err := w.WriteMsg(m)
-				if err != nil {
-					logger.Error("Unable to write message", logger.Ctx{"err": err})
-				}
-
-				return
-			}
+	// Transfer the cached zone so that its SOA serial matches the one of the queries.
+	cached, err := d.server.cachedZone(zone.Info)
+	if err != nil {
+		logger.Error("Failed loading DNS zone", logger.Ctx{"zone": name, "err": err})
 
-			break
+		m := &dns.Msg{}
+		m.SetRcode(r, dns.RcodeServerFailure)
+		err := w.WriteMsg(m)
+		if err != nil {
+			logger.Error("Unable to write message", logger.Ctx{"err": err})
 		}
 
-		m.Answer = append(m.Answer, rr)
+		return
 	}
 
+	m.Answer = cached.transfer()
+
 	tsig := r.IsTsig()
 	if tsig != nil && w.TsigStatus() == nil {
 		m.SetTsig(tsig.Hdr.Name, tsig.Algorithm, 300, time.Now().Unix())
@@ -139,42 +139,109 @@ func (d dnsHandler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 	}
 }
 
-func isAllowed(zone api.NetworkZone, ip string, tsig *dns.TSIG, tsigStatus bool) bool {
-	type peer struct {
-		address string
-		key     string
+// serveQuery answers a standard query from the zone containing the name.
+func (d dnsHandler) serveQuery(w dns.ResponseWriter, r *dns.Msg, name string, ip string) {
+	q := r.Question[0]
+
+	// Find the closest zone containing the name, only loading that one.
+	zoneName, err := d.server.closestZone(name)
+	if err != nil {
+		logger.Error("Failed looking up DNS zone", logger.Ctx{"name": name, "err": err})
+
+		m := &dns.Msg{}
+		m.SetRcode(r, dns.RcodeServerFailure)
+		err := w.WriteMsg(m)
+		if err != nil {
+			logger.Error("Unable to write message", logger.Ctx{"err": err})
+		}
+
+		return
 	}
 
-	// Build a list of peers.
-	peers := map[string]*peer{}
-	for k, v := range zone.Config {
-		if !strings.HasPrefix(k, "peers.") {
-			continue
+	var zone *Zone
+	if zoneName != "" {
+		zone, err = d.server.zoneRetriever(zoneName, false)
+		if err != nil {
+			zone = nil
 		}
+	}
 
-		// Extract the fields.
-		fields := strings.SplitN(k, ".", 3)
-		if len(fields) != 3 {
-			continue
+	// Check access (replying NXDOMAIN to unknown zones too to avoid information leaks).
+	if zone == nil || !isQueryAllowed(zone.Info, ip, r.IsTsig(), w.TsigStatus() == nil) {
+		m := &dns.Msg{}
+		m.SetRcode(r, dns.RcodeNameError)
+		err := w.WriteMsg(m)
+		if err != nil {
+			logger.Error("Unable to write message", logger.Ctx{"err": err})
 		}
 
-		peerName := fields[1]
+		return
+	}
 
-		if peers[peerName] == nil {
-			peers[peerName] = &peer{}
+	// The zone is only rendered and signed again once it may have changed.
+	cached, err := d.server.cachedZone(zone.Info)
+	if err != nil {
+		logger.Error("Failed loading DNS zone", logger.Ctx{"zone": zone.Info.Name, "err": err})
+
+		m := &dns.Msg{}
+		m.SetRcode(r, dns.RcodeServerFailure)
+		err := w.WriteMsg(m)
+		if err != nil {
+			logger.Error("Unable to write message", logger.Ctx{"err": err})
 		}
 
-		// Add the correct validation rule for the dynamic field based on last part of key.
-		switch fields[2] {
-		case "address":
-			peers[peerName].address = v
-		case "key":
-			peers[peerName].key = v
+		return
+	}
+
+	// Prepare the response.
+	m := &dns.Msg{}
+	m.SetReply(r)
+	res := cached.data.query(m, q)
+
+	// Reply with EDNS if the client used it.
+	size := dns.MinMsgSize
+	opt := r.IsEdns0()
+	if opt != nil {
+		size = max(int(opt.UDPSize()), dns.MinMsgSize)
+		m.SetEdns0(uint16(size), cached.key != nil && opt.Do())
+	}
+
+	// Sign the response if DNSSEC is enabled and requested.
+	if cached.key != nil && opt != nil && opt.Do() {
+		err := cached.sign(m, res)
+		if err != nil {
+			logger.Error("Failed signing DNS response", logger.Ctx{"zone": zone.Info.Name, "err": err})
+
+			m := &dns.Msg{}
+			m.SetRcode(r, dns.RcodeServerFailure)
+			err := w.WriteMsg(m)
+			if err != nil {
+				logger.Error("Unable to write message", logger.Ctx{"err": err})
+			}
+
+			return
 		}
 	}
 
+	// Truncate responses too large for UDP.
+	if w.LocalAddr().Network() == "udp" {
+		m.Truncate(size)
+	}
+
+	tsig := r.IsTsig()
+	if tsig != nil && w.TsigStatus() == nil {
+		m.SetTsig(tsig.Hdr.Name, tsig.Algorithm, 300, time.Now().Unix())
+	}
+
+	err = w.WriteMsg(m)
+	if err != nil {
+		logger.Error("Unable to write message", logger.Ctx{"err": err})
+	}
+}
+
+func isAllowed(zone api.NetworkZone, ip string, tsig *dns.TSIG, tsigStatus bool) bool {
 	// Validate access.
-	for peerName, peer := range peers {
+	for peerName, peer := range zonePeers(zone.Config) {
 		peerKeyName := fmt.Sprintf("%s_%s.", zone.Name, peerName)
 
 		if peer.address != "" && ip != peer.address {
@@ -198,3 +265,42 @@ func isAllowed(zone api.NetworkZone, ip string, tsig *dns.TSIG, tsigStatus bool)
 
 	return false
 }
+
+// zonePeer represents a peer of a zone.
+type zonePeer struct {
+	address string
+	key     string
+}
+
+// zonePeers returns the peers of a zone from its configuration.
+func zonePeers(config map[string]string) map[string]*zonePeer {
+	// Build a list of peers.
+	peers := map[string]*zonePeer{}
+	for k, v := range config {
+		if !strings.HasPrefix(k, "peers.") {
+			continue
+		}
+
+		// Extract the fields.
+		fields := strings.SplitN(k, ".", 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		peerName := fields[1]
+
+		if peers[peerName] == nil {
+			peers[peerName] = &zonePeer{}
+		}
+
+		// Fill in the field based on the last part of the key.
+		switch fields[2] {
+		case "address":
+			peers[peerName].address = v
+		case "key":
+			peers[peerName].key = v
+		}
+	}
+
+	return peers
+}
@@ -0,0 +1,187 @@
+package dns
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"github.com/lxc/incus/v7/internal/server/db"
+	dbCluster "github.com/lxc/incus/v7/internal/server/db/cluster"
+	"github.com/lxc/incus/v7/shared/logger"
+)
+
+// Interval between the checks for zone changes to notify the peers about.
+const notifyInterval = 30 * time.Second
+
+// runNotify periodically checks the zones for changes and sends NOTIFY messages to their peers.
+func (s *Server) runNotify(ctx context.Context) {
+	hashes := map[string]string{}
+
+	ticker := time.NewTicker(notifyInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := s.notifyChanges(ctx, hashes)
+		if err != nil {
+			logger.Warn("Failed checking DNS zones for changes", logger.Ctx{"err": err})
+		}
+	}
+}
+
+// notifyChanges sends NOTIFY messages to the peers of the zones whose records changed since the last check.
+func (s *Server) notifyChanges(ctx context.Context, hashes map[string]string) error {
+	if s.db == nil || s.zoneRetriever == nil {
+		return nil
+	}
+
+	zones := map[string]map[string]string{}
+
+	err := s.db.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		dbZones, err := dbCluster.GetNetworkZones(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		for _, zone := range dbZones {
+			config, err := dbCluster.GetNetworkZoneConfig(ctx, tx.Tx(), zone.ID)
+			if err != nil {
+				return err
+			}
+
+			zones[zone.Name] = config
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Forget about deleted zones.
+	for name := range hashes {
+		_, ok := zones[name]
+		if !ok {
+			delete(hashes, name)
+		}
+	}
+
+	for name, config := range zones {
+		peers := zonePeers(config)
+
+		// Skip zones without any peer to notify.
+		hasAddress := false
+		for _, peer := range peers {
+			if peer.address != "" {
+				hasAddress = true
+				break
+			}
+		}
+
+		if !hasAddress {
+			delete(hashes, name)
+			continue
+		}
+
+		zone, err := s.zoneRetriever(name, true)
+		if err != nil {
+			continue
+		}
+
+		hash, err := zoneHash(zone.Content)
+		if err != nil {
+			logger.Warn("Failed loading DNS zone", logger.Ctx{"zone": name, "err": err})
+			continue
+		}
+
+		oldHash, ok := hashes[name]
+		hashes[name] = hash
+		if !ok || oldHash == hash {
+			continue
+		}
+
+		for peerName, peer := range peers {
+			if peer.address == "" {
+				continue
+			}
+
+			err := sendNotify(name, peerName, peer)
+			if err != nil {
+				logger.Warn("Failed notifying DNS peer", logger.Ctx{"zone": name, "peer": peerName, "err": err})
+			}
+		}
+	}
+
+	return nil
+}
+
+// zoneHash returns a hash of the zone records, ignoring the serial of the SOA record as it changes every time.
+func zoneHash(content string) (string, error) {
+	records := []string{}
+
+	parser := dns.NewZoneParser(strings.NewReader(content), "", "")
+	for {
+		rr, ok := parser.Next()
+		if !ok {
+			err := parser.Err()
+			if err != nil {
+				return "", err
+			}
+
+			break
+		}
+
+		soa, ok := rr.(*dns.SOA)
+		if ok {
+			soa.Serial = 0
+		}
+
+		records = append(records, rr.String())
+	}
+
+	// The records aren't always generated in the same order.
+	slices.Sort(records)
+
+	hash := sha256.New()
+	for _, record := range records {
+		_, _ = fmt.Fprintln(hash, record)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// sendNotify sends a NOTIFY message for the zone to the peer, signed with its TSIG key if it has one.
+func sendNotify(zoneName string, peerName string, peer *zonePeer) error {
+	m := &dns.Msg{}
+	m.SetNotify(dns.Fqdn(zoneName))
+
+	client := &dns.Client{Timeout: 5 * time.Second}
+	if peer.key != "" {
+		keyName := fmt.Sprintf("%s_%s.", zoneName, peerName)
+		client.TsigSecret = map[string]string{keyName: peer.key}
+		m.SetTsig(keyName, dns.HmacSHA256, 300, time.Now().Unix())
+	}
+
+	resp, _, err := client.Exchange(m, net.JoinHostPort(peer.address, "53"))
+	if err != nil {
+		return err
+	}
+
+	if resp.Rcode != dns.RcodeSuccess {
+		return fmt.Errorf("Peer replied with %s", dns.RcodeToString[resp.Rcode])
+	}
+
+	return nil
+}
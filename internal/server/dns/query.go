@@ -0,0 +1,255 @@
+package dns
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/miekg/dns"
+
+	"github.com/lxc/incus/v7/shared/api"
+)
+
+// Maximum number of CNAME records followed within a zone.
+const maxCNAMEChain = 8
+
+// zoneData holds the records of a zone indexed by name.
+type zoneData struct {
+	name    string
+	soa     *dns.SOA
+	records map[string][]dns.RR
+}
+
+// queryResult holds information about the reply to a query needed to sign it.
+type queryResult struct {
+	// Name for which the requested records don't exist (NODATA or NXDOMAIN) and its record types.
+	denial      string
+	denialTypes []uint16
+
+	// Whether the reply is a referral to a delegated zone.
+	delegation bool
+}
+
+// newZoneData parses the zone content.
+func newZoneData(name string, content string) (*zoneData, error) {
+	z := &zoneData{
+		name:    dns.Fqdn(strings.ToLower(name)),
+		records: map[string][]dns.RR{},
+	}
+
+	parser := dns.NewZoneParser(strings.NewReader(content), "", "")
+	for {
+		rr, ok := parser.Next()
+		if !ok {
+			err := parser.Err()
+			if err != nil {
+				return nil, fmt.Errorf("Bad DNS record in zone %q: %w", name, err)
+			}
+
+			break
+		}
+
+		rr.Header().Name = strings.ToLower(rr.Header().Name)
+
+		soa, ok := rr.(*dns.SOA)
+		if ok {
+			// The SOA record is repeated at the end of the zone.
+			if z.soa == nil {
+				z.soa = soa
+				z.records[z.name] = append(z.records[z.name], soa)
+			}
+
+			continue
+		}
+
+		z.records[rr.Header().Name] = append(z.records[rr.Header().Name], rr)
+	}
+
+	if z.soa == nil {
+		return nil, fmt.Errorf("Missing SOA record in zone %q", name)
+	}
+
+	return z, nil
+}
+
+// negativeTTL returns the TTL of negative answers from the zone.
+func (z *zoneData) negativeTTL() uint32 {
+	return min(z.soa.Hdr.Ttl, z.soa.Minttl)
+}
+
+// exists checks whether the name exists in the zone, either with records or as an empty non-terminal.
+func (z *zoneData) exists(name string) bool {
+	if len(z.records[name]) > 0 {
+		return true
+	}
+
+	for recordName := range z.records {
+		if strings.HasSuffix(recordName, "."+name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lookup returns the records of the name, synthesized from a wildcard if needed.
+func (z *zoneData) lookup(name string) ([]dns.RR, bool) {
+	if z.exists(name) {
+		return z.records[name], true
+	}
+
+	// Look for a wildcard at the closest encloser.
+	encloser := name
+	for encloser != z.name {
+		_, encloser, _ = strings.Cut(encloser, ".")
+		if !z.exists(encloser) {
+			continue
+		}
+
+		wildcard := z.records["*."+encloser]
+		if len(wildcard) == 0 {
+			return nil, false
+		}
+
+		rrs := make([]dns.RR, 0, len(wildcard))
+		for _, rr := range wildcard {
+			rr = dns.Copy(rr)
+			rr.Header().Name = name
+			rrs = append(rrs, rr)
+		}
+
+		return rrs, true
+	}
+
+	return nil, false
+}
+
+// delegation returns the NS records of the zone cut above the name, if any.
+func (z *zoneData) delegation(name string) []dns.RR {
+	var cut []dns.RR
+
+	for name != z.name {
+		var ns []dns.RR
+		for _, rr := range z.records[name] {
+			if rr.Header().Rrtype == dns.TypeNS {
+				ns = append(ns, rr)
+			}
+		}
+
+		// Keep the zone cut closest to the apex.
+		if len(ns) > 0 {
+			cut = ns
+		}
+
+		_, name, _ = strings.Cut(name, ".")
+	}
+
+	return cut
+}
+
+// query fills in the reply to a standard query for a name within the zone.
+func (z *zoneData) query(m *dns.Msg, q dns.Question) *queryResult {
+	res := &queryResult{}
+	name := strings.ToLower(q.Name)
+
+	m.Authoritative = true
+
+	for range maxCNAMEChain {
+		// Refer the client to the delegated zone.
+		ns := z.delegation(name)
+		if len(ns) > 0 && (name != ns[0].Header().Name || q.Qtype != dns.TypeDS) {
+			m.Authoritative = len(m.Answer) > 0
+			m.Ns = append(m.Ns, ns...)
+			res.delegation = true
+
+			return res
+		}
+
+		rrs, found := z.lookup(name)
+		if !found {
+			m.Rcode = dns.RcodeNameError
+			m.Ns = append(m.Ns, z.negativeSOA())
+			res.denial = name
+
+			return res
+		}
+
+		var cname *dns.CNAME
+		answered := false
+		for _, rr := range rrs {
+			if rr.Header().Rrtype == q.Qtype {
+				m.Answer = append(m.Answer, rr)
+				answered = true
+			}
+
+			target, ok := rr.(*dns.CNAME)
+			if ok {
+				cname = target
+			}
+		}
+
+		if answered {
+			return res
+		}
+
+		if cname == nil || q.Qtype == dns.TypeCNAME {
+			m.Ns = append(m.Ns, z.negativeSOA())
+			res.denial = name
+			for _, rr := range rrs {
+				res.denialTypes = append(res.denialTypes, rr.Header().Rrtype)
+			}
+
+			return res
+		}
+
+		// Follow the alias within the zone.
+		m.Answer = append(m.Answer, cname)
+		name = strings.ToLower(cname.Target)
+		if !dns.IsSubDomain(z.name, name) {
+			return res
+		}
+	}
+
+	return res
+}
+
+// negativeSOA returns the SOA record included in negative answers.
+func (z *zoneData) negativeSOA() dns.RR {
+	soa := dns.Copy(z.soa)
+	soa.Header().Ttl = z.negativeTTL()
+
+	return soa
+}
+
+// isQueryAllowed checks whether the client can send standard queries to the zone.
+// The zone peers are always allowed, other clients depend on the dns.queries setting.
+func isQueryAllowed(zone api.NetworkZone, ip string, tsig *dns.TSIG, tsigStatus bool) bool {
+	if isAllowed(zone, ip, tsig, tsigStatus) {
+		return true
+	}
+
+	switch zone.Config["dns.queries"] {
+	case "", "peers":
+		return false
+	case "all":
+		return true
+	}
+
+	clientIP := net.ParseIP(ip)
+	if clientIP == nil {
+		return false
+	}
+
+	for _, entry := range strings.Split(zone.Config["dns.queries"], ",") {
+		_, subnet, err := net.ParseCIDR(strings.TrimSpace(entry))
+		if err != nil {
+			continue
+		}
+
+		if subnet.Contains(clientIP) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,307 @@
+package dns
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/v7/shared/api"
+)
+
+const testZone = `
+incus.example.net. 3600 IN SOA ns1.incus.example.net. hostmaster.incus.example.net. 1669736788 120 60 86400 30
+incus.example.net. 300 IN NS ns1.incus.example.net.
+c1.incus.example.net. 300 IN A 192.0.2.125
+c1.incus.example.net. 300 IN AAAA fd42:4131:a53c:7211:1266:6aff:fe19:6ede
+www.incus.example.net. 300 IN CNAME c1.incus.example.net.
+ext.incus.example.net. 300 IN CNAME example.com.
+inctest.gw.incus.example.net. 300 IN A 192.0.2.1
+_http._tcp.incus.example.net. 300 IN SRV 10 5 80 c1.incus.example.net.
+*.apps.incus.example.net. 300 IN TXT "wildcard"
+sub.incus.example.net. 300 IN NS ns1.example.com.
+incus.example.net. 3600 IN SOA ns1.incus.example.net. hostmaster.incus.example.net. 1669736788 120 60 86400 30
+`
+
+func testQuery(t *testing.T, z *zoneData, name string, qtype uint16) (*dns.Msg, *queryResult) {
+	t.Helper()
+
+	r := &dns.Msg{}
+	r.SetQuestion(name, qtype)
+
+	m := &dns.Msg{}
+	m.SetReply(r)
+
+	return m, z.query(m, r.Question[0])
+}
+
+func TestZoneQuery(t *testing.T) {
+	z, err := newZoneData("incus.example.net", testZone)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		qtype   uint16
+		rcode   int
+		answers []string
+		denial  string
+	}{
+		{"incus.example.net.", dns.TypeSOA, dns.RcodeSuccess, []string{"incus.example.net.\t3600\tIN\tSOA\tns1.incus.example.net. hostmaster.incus.example.net. 1669736788 120 60 86400 30"}, ""},
+		{"C1.Incus.Example.Net.", dns.TypeA, dns.RcodeSuccess, []string{"c1.incus.example.net.\t300\tIN\tA\t192.0.2.125"}, ""},
+		{"c1.incus.example.net.", dns.TypeTXT, dns.RcodeSuccess, nil, "c1.incus.example.net."},
+		{"missing.incus.example.net.", dns.TypeA, dns.RcodeNameError, nil, "missing.incus.example.net."},
+		{"gw.incus.example.net.", dns.TypeA, dns.RcodeSuccess, nil, "gw.incus.example.net."},
+		{"www.incus.example.net.", dns.TypeAAAA, dns.RcodeSuccess, []string{"www.incus.example.net.\t300\tIN\tCNAME\tc1.incus.example.net.", "c1.incus.example.net.\t300\tIN\tAAAA\tfd42:4131:a53c:7211:1266:6aff:fe19:6ede"}, ""},
+		{"www.incus.example.net.", dns.TypeCNAME, dns.RcodeSuccess, []string{"www.incus.example.net.\t300\tIN\tCNAME\tc1.incus.example.net."}, ""},
+		{"ext.incus.example.net.", dns.TypeA, dns.RcodeSuccess, []string{"ext.incus.example.net.\t300\tIN\tCNAME\texample.com."}, ""},
+		{"_http._tcp.incus.example.net.", dns.TypeSRV, dns.RcodeSuccess, []string{"_http._tcp.incus.example.net.\t300\tIN\tSRV\t10 5 80 c1.incus.example.net."}, ""},
+		{"foo.apps.incus.example.net.", dns.TypeTXT, dns.RcodeSuccess, []string{"foo.apps.incus.example.net.\t300\tIN\tTXT\t\"wildcard\""}, ""},
+		{"foo.apps.incus.example.net.", dns.TypeA, dns.RcodeSuccess, nil, "foo.apps.incus.example.net."},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name+" "+dns.TypeToString[test.qtype], func(t *testing.T) {
+			m, res := testQuery(t, z, test.name, test.qtype)
+			assert.True(t, m.Authoritative)
+			assert.Equal(t, test.rcode, m.Rcode)
+			assert.Equal(t, test.denial, res.denial)
+
+			answers := []string{}
+			for _, rr := range m.Answer {
+				answers = append(answers, rr.String())
+			}
+
+			assert.ElementsMatch(t, test.answers, answers)
+
+			// Negative answers carry the SOA record.
+			if test.denial != "" {
+				require.Len(t, m.Ns, 1)
+				assert.Equal(t, dns.TypeSOA, m.Ns[0].Header().Rrtype)
+				assert.Equal(t, uint32(30), m.Ns[0].Header().Ttl)
+			}
+		})
+	}
+}
+
+func TestZoneQueryDelegation(t *testing.T) {
+	z, err := newZoneData("incus.example.net", testZone)
+	require.NoError(t, err)
+
+	m, res := testQuery(t, z, "host.sub.incus.example.net.", dns.TypeA)
+	assert.True(t, res.delegation)
+	assert.False(t, m.Authoritative)
+	assert.Empty(t, m.Answer)
+	require.Len(t, m.Ns, 1)
+	assert.Equal(t, "ns1.example.com.", m.Ns[0].(*dns.NS).Ns)
+}
+
+func TestZoneQueryDNSSEC(t *testing.T) {
+	privateKey, err := DNSSECGenerateKey()
+	require.NoError(t, err)
+
+	key, err := newDNSSECKey("incus.example.net", privateKey)
+	require.NoError(t, err)
+
+	z, err := newZoneData("incus.example.net", testZone)
+	require.NoError(t, err)
+
+	z.records[z.name] = append(z.records[z.name], key.dnskey)
+	signatures := map[string]*dns.RRSIG{}
+
+	// verify checks that each record set of the section is signed by the key.
+	verify := func(t *testing.T, rrs []dns.RR) {
+		t.Helper()
+
+		rrsets := map[string][]dns.RR{}
+		for _, rr := range rrs {
+			if rr.Header().Rrtype != dns.TypeRRSIG {
+				name := rr.Header().Name + "/" + dns.TypeToString[rr.Header().Rrtype]
+				rrsets[name] = append(rrsets[name], rr)
+			}
+		}
+
+		for _, rr := range rrs {
+			rrsig, ok := rr.(*dns.RRSIG)
+			if !ok {
+				continue
+			}
+
+			name := rrsig.Hdr.Name + "/" + dns.TypeToString[rrsig.TypeCovered]
+			require.Contains(t, rrsets, name)
+			require.NoError(t, rrsig.Verify(key.dnskey, rrsets[name]))
+			assert.True(t, rrsig.ValidityPeriod(time.Now()))
+			delete(rrsets, name)
+		}
+
+		assert.Empty(t, rrsets, "unsigned record sets")
+	}
+
+	t.Run("answer", func(t *testing.T) {
+		m, res := testQuery(t, z, "c1.incus.example.net.", dns.TypeA)
+		require.NoError(t, key.sign(z, m, res, signatures))
+		require.Len(t, m.Answer, 2)
+		verify(t, m.Answer)
+	})
+
+	t.Run("dnskey", func(t *testing.T) {
+		m, res := testQuery(t, z, "incus.example.net.", dns.TypeDNSKEY)
+		require.NoError(t, key.sign(z, m, res, signatures))
+		require.Len(t, m.Answer, 2)
+		verify(t, m.Answer)
+	})
+
+	t.Run("nxdomain", func(t *testing.T) {
+		m, res := testQuery(t, z, "missing.incus.example.net.", dns.TypeA)
+		require.NoError(t, key.sign(z, m, res, signatures))
+		assert.Equal(t, dns.RcodeSuccess, m.Rcode)
+		require.Len(t, m.Ns, 4)
+		verify(t, m.Ns)
+
+		nsec, ok := m.Ns[1].(*dns.NSEC)
+		require.True(t, ok)
+		assert.Equal(t, "missing.incus.example.net.", nsec.Hdr.Name)
+		assert.Equal(t, []uint16{dns.TypeRRSIG, dns.TypeNSEC}, nsec.TypeBitMap)
+	})
+
+	t.Run("nodata", func(t *testing.T) {
+		m, res := testQuery(t, z, "c1.incus.example.net.", dns.TypeTXT)
+		require.NoError(t, key.sign(z, m, res, signatures))
+		require.Len(t, m.Ns, 4)
+		verify(t, m.Ns)
+
+		nsec, ok := m.Ns[1].(*dns.NSEC)
+		require.True(t, ok)
+		assert.Equal(t, []uint16{dns.TypeA, dns.TypeAAAA, dns.TypeRRSIG, dns.TypeNSEC}, nsec.TypeBitMap)
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		m, res := testQuery(t, z, "c1.incus.example.net.", dns.TypeA)
+		require.NoError(t, key.sign(z, m, res, signatures))
+
+		again, res := testQuery(t, z, "c1.incus.example.net.", dns.TypeA)
+		require.NoError(t, key.sign(z, again, res, signatures))
+		assert.Same(t, m.Answer[1], again.Answer[1])
+
+		// Records synthesized from a wildcard and denials aren't cached.
+		for _, name := range []string{"a.apps.incus.example.net.", "b.apps.incus.example.net.", "missing.incus.example.net."} {
+			m, res := testQuery(t, z, name, dns.TypeTXT)
+			require.NoError(t, key.sign(z, m, res, signatures))
+		}
+
+		for _, rrsig := range signatures {
+			assert.NotEqual(t, "a.apps.incus.example.net.", rrsig.Hdr.Name)
+			assert.NotEqual(t, dns.TypeNSEC, rrsig.TypeCovered)
+		}
+	})
+}
+
+func TestCachedZone(t *testing.T) {
+	renders := 0
+	content := testZone
+
+	s := &Server{zoneRetriever: func(name string, full bool) (*Zone, error) {
+		if full {
+			renders++
+		}
+
+		// The serial changes with every render.
+		return &Zone{
+			Info:    api.NetworkZone{Name: name},
+			Content: strings.ReplaceAll(content, "1669736788", strconv.Itoa(1669736788+renders)),
+		}, nil
+	}}
+
+	info := api.NetworkZone{Name: "incus.example.net"}
+
+	cached, err := s.cachedZone(info)
+	require.NoError(t, err)
+	assert.Equal(t, 1, renders)
+	serial := cached.data.soa.Serial
+
+	// The zone isn't rendered again until it may have changed.
+	again, err := s.cachedZone(info)
+	require.NoError(t, err)
+	assert.Same(t, cached, again)
+	assert.Equal(t, 1, renders)
+
+	// The serial is kept if the records didn't change.
+	s.ZoneChanged("Incus.example.net")
+	again, err = s.cachedZone(info)
+	require.NoError(t, err)
+	assert.Same(t, cached, again)
+	assert.Equal(t, 2, renders)
+	assert.Equal(t, serial, again.data.soa.Serial)
+
+	// And increased otherwise.
+	content = strings.ReplaceAll(testZone, "192.0.2.125", "192.0.2.126")
+	s.ZoneChanged("incus.example.net")
+	again, err = s.cachedZone(info)
+	require.NoError(t, err)
+	assert.NotSame(t, cached, again)
+	assert.Greater(t, again.data.soa.Serial, serial)
+
+	rrs := again.transfer()
+	assert.Equal(t, again.data.soa, rrs[0])
+	assert.Equal(t, again.data.soa, rrs[len(rrs)-1])
+	assert.Len(t, rrs, 11)
+}
+
+func TestClosestZone(t *testing.T) {
+	s := &Server{zoneNames: map[string]string{
+		"incus.example.net":      "incus.example.net",
+		"apps.incus.example.net": "Apps.incus.example.net",
+	}}
+
+	for name, expected := range map[string]string{
+		"incus.example.net":            "incus.example.net",
+		"c1.incus.example.net":         "incus.example.net",
+		"c1.apps.INCUS.example.net":    "Apps.incus.example.net",
+		"a.b.apps.incus.example.net":   "Apps.incus.example.net",
+		"example.net":                  "",
+		"incus.example.net.evil.net":   "",
+		"c1.notapps.incus.example.net": "incus.example.net",
+	} {
+		zoneName, err := s.closestZone(name)
+		require.NoError(t, err)
+		assert.Equal(t, expected, zoneName, name)
+	}
+}
+
+func TestDNSSECDS(t *testing.T) {
+	privateKey, err := DNSSECGenerateKey()
+	require.NoError(t, err)
+
+	ds, err := DNSSECDS("incus.example.net", privateKey)
+	require.NoError(t, err)
+
+	_, err = dns.NewRR("incus.example.net. IN DS " + ds)
+	require.NoError(t, err)
+
+	assert.Error(t, DNSSECValidateKey("invalid"))
+	assert.NoError(t, DNSSECValidateKey(privateKey))
+}
+
+func TestIsQueryAllowed(t *testing.T) {
+	zone := api.NetworkZone{
+		Name: "incus.example.net",
+		NetworkZonePut: api.NetworkZonePut{
+			Config: map[string]string{"peers.ns.address": "192.0.2.53"},
+		},
+	}
+
+	assert.True(t, isQueryAllowed(zone, "192.0.2.53", nil, false))
+	assert.False(t, isQueryAllowed(zone, "192.0.2.54", nil, false))
+
+	zone.Config["dns.queries"] = "all"
+	assert.True(t, isQueryAllowed(zone, "198.51.100.1", nil, false))
+
+	zone.Config["dns.queries"] = "10.0.0.0/8, fd42::/16"
+	assert.True(t, isQueryAllowed(zone, "10.1.2.3", nil, false))
+	assert.True(t, isQueryAllowed(zone, "fd42::1", nil, false))
+	assert.True(t, isQueryAllowed(zone, "192.0.2.53", nil, false))
+	assert.False(t, isQueryAllowed(zone, "198.51.100.1", nil, false))
+}
@@ -31,6 +31,16 @@ type Server struct {
 	// Internal state (to handle reconfiguration).
 	address string
 
+	// Stops the NOTIFY loop.
+	notifyCancel context.CancelFunc
+
+	// Rendered zones, by name.
+	zones map[string]*cachedZone
+
+	// Names of all the zones by lowercase name, along with when they were loaded.
+	zoneNames        map[string]string
+	zoneNamesChecked time.Time
+
 	cmd chan serverCmdInfo
 
 	mu sync.Mutex
@@ -200,6 +210,11 @@ func (s *Server) start(address string) error {
 		return err
 	}
 
+	// Notify the zone peers of changes.
+	ctx, cancel := context.WithCancel(context.Background())
+	s.notifyCancel = cancel
+	go s.runNotify(ctx)
+
 	// Record the address.
 	s.address = address
 
@@ -225,6 +240,12 @@ func (s *Server) stop() {
 	_ = s.tcpDNS.Shutdown()
 	_ = s.udpDNS.Shutdown()
 
+	// Stop notifying the zone peers.
+	if s.notifyCancel != nil {
+		s.notifyCancel()
+		s.notifyCancel = nil
+	}
+
 	// Unset the address.
 	s.address = ""
 }
@@ -244,6 +265,9 @@ func (s *Server) UpdateTSIG() error {
 }
 
 func (s *Server) updateTSIG() error {
+	// Zones may have been added or removed.
+	s.zoneNamesChecked = time.Time{}
+
 	// Skip if no instance.
 	if s.tcpDNS == nil || s.udpDNS == nil || s.db == nil {
 		return nil
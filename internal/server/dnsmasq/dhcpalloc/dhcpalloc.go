@@ -41,6 +41,17 @@ func DHCPValidIP(subnet *net.IPNet, ranges []iprange.Range, IP net.IP) bool {
 	return false
 }
 
+// DHCPReservedIP returns whether an IP falls within one of the supplied reserved ranges.
+func DHCPReservedIP(ranges []iprange.Range, IP net.IP) bool {
+	for _, IPRange := range ranges {
+		if bytes.Compare(IP, IPRange.Start) >= 0 && bytes.Compare(IP, IPRange.End) <= 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
 // GetIP returns a net.IP representing the IP belonging to the subnet for the host number supplied.
 func GetIP(subnet *net.IPNet, host int64) net.IP {
 	// Convert IP to a big int.
@@ -85,6 +96,9 @@ type Network interface {
 	DHCPv6Subnet() *net.IPNet
 	DHCPv4Ranges() []iprange.Range
 	DHCPv6Ranges() []iprange.Range
+	ReservedIPv4Ranges() []iprange.Range
+	ReservedIPv6Ranges() []iprange.Range
+	LeaseAddresses(hwaddr string) (net.IP, net.IP, error)
 }
 
 // Options to initialize the allocator with.
@@ -106,6 +120,8 @@ type Transaction struct {
 	allocationsDHCPv6 map[[16]byte]dnsmasq.DHCPAllocation
 	allocatedIPv4     net.IP
 	allocatedIPv6     net.IP
+	reservedIPv4      net.IP
+	reservedIPv6      net.IP
 }
 
 // AllocateIPv4 allocate an IPv4 static DHCP allocation.
@@ -122,11 +138,14 @@ func (t *Transaction) AllocateIPv4() (net.IP, error) {
 		return nil, ErrDHCPNotSupported
 	}
 
-	// Check the existing allocated IP is still valid in the network's subnet & ranges, if not then
-	// we'll need to generate a new one.
-	if t.allocatedIPv4 != nil {
+	// Use the address reserved for the host's MAC address if there is one. Otherwise check the existing
+	// allocated IP is still valid in the network's subnet & ranges and isn't reserved, if not then we'll
+	// need to generate a new one.
+	if t.reservedIPv4 != nil && DHCPValidIP(dhcpSubnet, nil, t.reservedIPv4.To4()) {
+		t.allocatedIPv4 = t.reservedIPv4.To4()
+	} else if t.allocatedIPv4 != nil {
 		ranges := t.opts.Network.DHCPv4Ranges()
-		if !DHCPValidIP(dhcpSubnet, ranges, t.allocatedIPv4.To4()) {
+		if !DHCPValidIP(dhcpSubnet, ranges, t.allocatedIPv4.To4()) || DHCPReservedIP(t.opts.Network.ReservedIPv4Ranges(), t.allocatedIPv4.To4()) {
 			t.allocatedIPv4 = nil // We need a new IP allocated.
 		}
 	}
@@ -156,11 +175,14 @@ func (t *Transaction) AllocateIPv6() (net.IP, error) {
 		return nil, ErrDHCPNotSupported
 	}
 
-	// Check the existing allocated IP is still valid in the network's subnet & ranges, if not then
-	// we'll need to generate a new one.
-	if t.allocatedIPv6 != nil {
+	// Use the address reserved for the host's MAC address if there is one. Otherwise check the existing
+	// allocated IP is still valid in the network's subnet & ranges and isn't reserved, if not then we'll
+	// need to generate a new one.
+	if t.reservedIPv6 != nil && DHCPValidIP(dhcpSubnet, nil, t.reservedIPv6.To16()) {
+		t.allocatedIPv6 = t.reservedIPv6.To16()
+	} else if t.allocatedIPv6 != nil {
 		ranges := t.opts.Network.DHCPv6Ranges()
-		if !DHCPValidIP(dhcpSubnet, ranges, t.allocatedIPv6.To16()) {
+		if !DHCPValidIP(dhcpSubnet, ranges, t.allocatedIPv6.To16()) || DHCPReservedIP(t.opts.Network.ReservedIPv6Ranges(), t.allocatedIPv6.To16()) {
 			t.allocatedIPv6 = nil // We need a new IP allocated.
 		}
 	}
@@ -186,11 +208,12 @@ func (t *Transaction) getDHCPFreeIPv4(usedIPs map[[4]byte]dnsmasq.DHCPAllocation
 	}
 
 	dhcpRanges := t.opts.Network.DHCPv4Ranges()
+	reservedRanges := t.opts.Network.ReservedIPv4Ranges()
 
 	// Lets see if there is already an allocation for our device and that it sits within subnet.
 	// If there are custom DHCP ranges defined, check also that the IP falls within one of the ranges.
 	for _, DHCP := range usedIPs {
-		if (deviceStaticFileName == DHCP.StaticFileName || bytes.Equal(mac, DHCP.MAC)) && DHCPValidIP(subnet, dhcpRanges, DHCP.IP) {
+		if (deviceStaticFileName == DHCP.StaticFileName || bytes.Equal(mac, DHCP.MAC)) && DHCPValidIP(subnet, dhcpRanges, DHCP.IP) && !DHCPReservedIP(reservedRanges, DHCP.IP) {
 			return DHCP.IP, nil
 		}
 	}
@@ -222,6 +245,12 @@ func (t *Transaction) getDHCPFreeIPv4(usedIPs map[[4]byte]dnsmasq.DHCPAllocation
 				continue
 			}
 
+			// Check IP is not reserved for external hosts.
+			if DHCPReservedIP(reservedRanges, IP) {
+				startBig.Add(startBig, inc)
+				continue
+			}
+
 			// Check IP is not already allocated.
 			var IPKey [4]byte
 			copy(IPKey[:], IP.To4())
@@ -253,13 +282,14 @@ func (t *Transaction) getDHCPFreeIPv6(usedIPs map[[16]byte]dnsmasq.DHCPAllocatio
 	}
 
 	dhcpRanges := t.opts.Network.DHCPv6Ranges()
+	reservedRanges := t.opts.Network.ReservedIPv6Ranges()
 
 	// Lets see if there is already an allocation for our device and that it sits within subnet.
 	// Because of dnsmasq's lease file format we can only match safely against static
 	// allocations using instance name. If there are custom DHCP ranges defined, check also
 	// that the IP falls within one of the ranges.
 	for _, DHCP := range usedIPs {
-		if deviceStaticFileName == DHCP.StaticFileName && DHCPValidIP(subnet, dhcpRanges, DHCP.IP) {
+		if deviceStaticFileName == DHCP.StaticFileName && DHCPValidIP(subnet, dhcpRanges, DHCP.IP) && !DHCPReservedIP(reservedRanges, DHCP.IP) {
 			return DHCP.IP, nil
 		}
 	}
@@ -277,7 +307,7 @@ func (t *Transaction) getDHCPFreeIPv6(usedIPs map[[16]byte]dnsmasq.DHCPAllocatio
 		var IPKey [16]byte
 		copy(IPKey[:], IP.To16())
 		_, inUse := usedIPs[IPKey]
-		if !inUse && !IP.Equal(ip) {
+		if !inUse && !IP.Equal(ip) && !DHCPReservedIP(reservedRanges, IP.To16()) {
 			return IP, nil
 		}
 	}
@@ -310,6 +340,12 @@ func (t *Transaction) getDHCPFreeIPv6(usedIPs map[[16]byte]dnsmasq.DHCPAllocatio
 				continue
 			}
 
+			// Check IP is not reserved for external hosts.
+			if DHCPReservedIP(reservedRanges, IP) {
+				startBig.Add(startBig, inc)
+				continue
+			}
+
 			// Check IP is not already allocated.
 			var IPKey [16]byte
 			copy(IPKey[:], IP.To16())
@@ -350,6 +386,12 @@ func AllocateTask(opts *Options, f func(*Transaction) error) error {
 	t.allocatedIPv4 = t.currentDHCPv4.IP
 	t.allocatedIPv6 = t.currentDHCPv6.IP
 
+	// Get the addresses reserved for the host's MAC address on the network.
+	t.reservedIPv4, t.reservedIPv6, err = opts.Network.LeaseAddresses(opts.HostMAC.String())
+	if err != nil {
+		return err
+	}
+
 	// Get all existing allocations in network if leases file exists. If not then we will detect this later
 	// due to the existing allocations maps being nil.
 	if util.PathExists(internalUtil.VarPath("networks", opts.Network.Name(), "dnsmasq.leases")) {
@@ -51,3 +51,42 @@ func Test_DHCPValidIP(t *testing.T) {
 		})
 	}
 }
+
+func Test_DHCPReservedIP(t *testing.T) {
+	tests := []struct {
+		name     string
+		ranges   []string
+		ip       string
+		expected bool
+	}{
+		{name: "no ranges", ranges: nil, ip: "192.168.0.2", expected: false},
+		{name: "in reserved range", ranges: []string{"192.168.0.0-192.168.0.10"}, ip: "192.168.0.2", expected: true},
+		{name: "on reserved range boundary", ranges: []string{"192.168.0.0-192.168.0.10"}, ip: "192.168.0.10", expected: true},
+		{name: "not in reserved range", ranges: []string{"192.168.0.0-192.168.0.10"}, ip: "192.168.0.12", expected: false},
+		{name: "in second reserved range", ranges: []string{"192.168.0.0-192.168.0.10", "192.168.1.0-192.168.1.10"}, ip: "192.168.1.5", expected: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// arrange
+			var ranges []iprange.Range
+			for _, rangesString := range test.ranges {
+				rangeIps := strings.Split(rangesString, "-")
+
+				ranges = append(ranges,
+					iprange.Range{
+						Start: net.ParseIP(rangeIps[0]),
+						End:   net.ParseIP(rangeIps[1]),
+					})
+			}
+
+			ip := net.ParseIP(test.ip)
+
+			// act
+			isReservedIP := DHCPReservedIP(ranges, ip)
+
+			// assert
+			assert.Equal(t, test.expected, isReservedIP)
+		})
+	}
+}
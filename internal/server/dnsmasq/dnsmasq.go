@@ -21,6 +21,14 @@ import (
 
 const staticAllocationDeviceSeparator = "."
 
+// reservedAllocationPrefix is the prefix of the address reservation files.
+// It can't be used by instance names so the files don't clash with the instance ones.
+const reservedAllocationPrefix = "+reserved"
+
+// reservedAllocationID is the client identifier of the address reservations not tied to a MAC address.
+// No client uses it, so dnsmasq just keeps the address out of its dynamic pool.
+const reservedAllocationID = "id:incus-reserved"
+
 // DHCPAllocation represents an IP allocation from dnsmasq.
 type DHCPAllocation struct {
 	IP             net.IP
@@ -73,6 +81,32 @@ func RemoveStaticEntry(network string, projectName string, instanceName string,
 	return nil
 }
 
+// UpdateReservedEntry writes a single dhcp-host line for an address reservation of the network.
+// Reservations without a MAC address only keep the address from being handed out dynamically.
+func UpdateReservedEntry(network string, netConfig map[string]string, address net.IP, hwaddr string, hostname string) error {
+	line := reservedAllocationID
+	if hwaddr != "" {
+		line = strings.ToLower(hwaddr)
+	}
+
+	if address.To4() != nil {
+		line += fmt.Sprintf(",%s", address.String())
+	} else {
+		line += fmt.Sprintf(",[%s]", address.String())
+	}
+
+	if hostname != "" && (netConfig["dns.mode"] == "" || netConfig["dns.mode"] == "managed") {
+		line += fmt.Sprintf(",%s", hostname)
+	}
+
+	err := os.WriteFile(internalUtil.VarPath("networks", network, "dnsmasq.hosts", ReservedAllocationFileName(address.String())), []byte(line+"\n"), 0o644)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // Kill kills dnsmasq for a particular network (or optionally reloads it).
 func Kill(name string, reload bool) error {
 	pidPath := internalUtil.VarPath("networks", name, "dnsmasq.pid")
@@ -270,3 +304,8 @@ func StaticAllocationFileName(projectName string, instanceName string, deviceNam
 
 	return strings.Join([]string{project.Instance(projectName, instanceName), escapedDeviceName}, staticAllocationDeviceSeparator)
 }
+
+// ReservedAllocationFileName returns the file name to use for a dnsmasq address reservation.
+func ReservedAllocationFileName(address string) string {
+	return strings.Join([]string{reservedAllocationPrefix, address}, staticAllocationDeviceSeparator)
+}
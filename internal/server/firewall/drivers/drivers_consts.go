@@ -52,6 +52,40 @@ type AddressForward struct {
 	SNAT          bool
 }
 
+// LoadBalancer represents a load balanced listen port.
+type LoadBalancer struct {
+	ListenAddress net.IP
+	Protocol      string
+	ListenPort    uint64
+	Targets       []LoadBalancerTarget
+}
+
+// LoadBalancerTarget represents a load balancer backend.
+type LoadBalancerTarget struct {
+	Address net.IP
+	Port    uint64
+}
+
+// EgressGateway represents an external address used as the source of the outbound traffic of a set of NICs.
+type EgressGateway struct {
+	Address net.IP   // Source address of the outbound traffic, its family selects the traffic it applies to.
+	HwAddrs []string // MAC addresses of the NICs whose traffic goes through the gateway.
+}
+
+// QoSClass represents a quality of service class applied to the traffic of a set of NICs.
+type QoSClass struct {
+	Priority string   // Traffic control class (major:minor) of the traffic sent to the NICs, empty to leave it untouched.
+	DSCP     string   // DSCP value of the traffic sent by the NICs, empty to leave it untouched.
+	HwAddrs  []string // MAC addresses of the NICs in the class.
+}
+
+// NetworkPeer represents a peered network traffic can be forwarded to.
+type NetworkPeer struct {
+	Interface     string       // Interface name of the peer network.
+	LocalSubnets  []*net.IPNet // Subnets allowed as source of the traffic to the peer.
+	TargetSubnets []*net.IPNet // Subnets allowed as destination of the traffic to the peer.
+}
+
 // AddressSet represent an address set.
 type AddressSet struct {
 	Name      string
@@ -30,6 +30,14 @@ const (
 // to contain underscores (where as instance name is not).
 const nftablesChainSeparator = "."
 
+// Egress gateways mark the traffic of their NICs with their index in these bits of the packet mark, leaving the
+// other bits untouched.
+const (
+	egressGatewayMarkShift = 16
+	egressGatewayMarkMask  = 0xff << egressGatewayMarkShift
+	egressGatewayMarkMax   = 0xff
+)
+
 // Nftables is an implementation of Incus firewall using nftables.
 type Nftables struct{}
 
@@ -319,9 +327,12 @@ func (d Nftables) NetworkSetup(networkName string, opts Opts) error {
 // The delete and ipeVersions arguments have no effect for nftables driver.
 func (d Nftables) NetworkClear(networkName string, _ bool, _ []uint) error {
 	removeChains := []string{
-		"fwd", "pstrt", "in", "out", // Chains used for network operation rules.
+		"fwd", "peer", "pstrt", "in", "out", // Chains used for network operation rules.
 		"aclin", "aclout", "aclfwd", "acl", // Chains used by ACL rules.
 		"fwdprert", "fwdout", "fwdpstrt", // Chains used by Address Forward rules.
+		"lbprert", "lbout", "lbpstrt", // Chains used by Load Balancer rules.
+		"egwprert", "egwpstrt", // Chains used by Egress Gateway rules.
+		"qosin", "qosout", // Chains used by QoS class rules.
 		"egress", // Chains added for limits.priority option
 	}
 
@@ -1428,6 +1439,275 @@ func (d Nftables) NetworkApplyForwards(networkName string, rules []AddressForwar
 	return nil
 }
 
+// NetworkApplyLoadBalancers applies network load balancer rules to firewall.
+// Connections are spread across the targets of each listen port by hashing their source address and port.
+func (d Nftables) NetworkApplyLoadBalancers(networkName string, rules []LoadBalancer) error {
+	var dnatRules []map[string]any
+	var snatRules []map[string]any
+
+	for ruleIndex, rule := range rules {
+		if rule.ListenAddress == nil {
+			return fmt.Errorf("Invalid rule %d, listen address is required", ruleIndex)
+		}
+
+		if rule.Protocol == "" || rule.ListenPort == 0 {
+			return fmt.Errorf("Invalid rule %d, protocol and listen port are required", ruleIndex)
+		}
+
+		// Without any (healthy) target, connections aren't forwarded anywhere.
+		if len(rule.Targets) == 0 {
+			continue
+		}
+
+		ipFamily := "ip"
+		if rule.ListenAddress.To4() == nil {
+			ipFamily = "ip6"
+		}
+
+		var dnat string
+		if len(rule.Targets) == 1 {
+			target := rule.Targets[0]
+			targetDest := fmt.Sprintf("%s:%d", target.Address.String(), target.Port)
+			if ipFamily == "ip6" {
+				targetDest = fmt.Sprintf("[%s]:%d", target.Address.String(), target.Port)
+			}
+
+			dnat = fmt.Sprintf("dnat %s to %s", ipFamily, targetDest)
+		} else {
+			elements := make([]string, 0, len(rule.Targets))
+			for i, target := range rule.Targets {
+				elements = append(elements, fmt.Sprintf("%d : %s . %d", i, target.Address.String(), target.Port))
+			}
+
+			dnat = fmt.Sprintf("dnat %s addr . port to jhash %s saddr . %s sport mod %d map { %s }", ipFamily, ipFamily, rule.Protocol, len(rule.Targets), strings.Join(elements, ", "))
+		}
+
+		dnatRules = append(dnatRules, map[string]any{
+			"ipFamily":      ipFamily,
+			"protocol":      rule.Protocol,
+			"listenAddress": rule.ListenAddress.String(),
+			"listenPort":    rule.ListenPort,
+			"dnat":          dnat,
+		})
+
+		// Allow targets to connect to themselves through the listen address.
+		for _, target := range rule.Targets {
+			snatRules = append(snatRules, map[string]any{
+				"ipFamily":      ipFamily,
+				"protocol":      rule.Protocol,
+				"targetAddress": target.Address.String(),
+				"targetPort":    target.Port,
+			})
+		}
+	}
+
+	tplFields := map[string]any{
+		"namespace":      nftablesNamespace,
+		"chainSeparator": nftablesChainSeparator,
+		"family":         "inet",
+		"label":          networkName,
+		"dnatRules":      dnatRules,
+		"snatRules":      snatRules,
+	}
+
+	// Apply rules or remove chains if no rules generated.
+	if len(dnatRules) > 0 {
+		config := &strings.Builder{}
+		err := nftablesNetLoadBalancer.Execute(config, tplFields)
+		if err != nil {
+			return fmt.Errorf("Failed running %q template: %w", nftablesNetLoadBalancer.Name(), err)
+		}
+
+		err = subprocess.RunCommandWithFds(context.TODO(), strings.NewReader(config.String()), nil, "nft", "-f", "-")
+		if err != nil {
+			return err
+		}
+	} else {
+		err := d.removeChains([]string{"inet"}, networkName, "lbprert", "lbout", "lbpstrt")
+		if err != nil {
+			return fmt.Errorf("Failed clearing nftables load balancer rules for network %q: %w", networkName, err)
+		}
+	}
+
+	return nil
+}
+
+// NetworkApplyEgressGateways applies the network egress gateway rules to firewall.
+// The traffic of the NICs is marked by MAC address as it enters the bridge and then source NATed based on that mark,
+// so that it doesn't depend on the addresses the NICs use.
+func (d Nftables) NetworkApplyEgressGateways(networkName string, gateways []EgressGateway) error {
+	var rules []map[string]any
+
+	if len(gateways) > egressGatewayMarkMax {
+		return fmt.Errorf("Too many egress gateways, the maximum is %d", egressGatewayMarkMax)
+	}
+
+	for i, gateway := range gateways {
+		if gateway.Address == nil {
+			return fmt.Errorf("Invalid egress gateway %d, address is required", i)
+		}
+
+		if len(gateway.HwAddrs) == 0 {
+			continue
+		}
+
+		rule := map[string]any{
+			"mark":          fmt.Sprintf("0x%08x", (i+1)<<egressGatewayMarkShift),
+			"nfproto":       "ipv4",
+			"ipFamily":      "ip",
+			"excludeSubnet": "",
+			"address":       gateway.Address.String(),
+			"hwAddrs":       strings.Join(gateway.HwAddrs, ", "),
+		}
+
+		if gateway.Address.To4() == nil {
+			rule["nfproto"] = "ipv6"
+			rule["ipFamily"] = "ip6"
+			rule["excludeSubnet"] = "fe80::/10" // Link-local addresses are never routed.
+		}
+
+		rules = append(rules, rule)
+	}
+
+	err := d.removeChains([]string{"inet"}, networkName, "egwprert", "egwpstrt")
+	if err != nil {
+		return fmt.Errorf("Failed clearing nftables egress gateway rules for network %q: %w", networkName, err)
+	}
+
+	if len(rules) == 0 {
+		return nil
+	}
+
+	tplFields := map[string]any{
+		"namespace":      nftablesNamespace,
+		"chainSeparator": nftablesChainSeparator,
+		"family":         "inet",
+		"label":          networkName,
+		"markMask":       fmt.Sprintf("0x%08x", egressGatewayMarkMask),
+		"markKeep":       fmt.Sprintf("0x%08x", ^uint32(egressGatewayMarkMask)),
+		"rules":          rules,
+	}
+
+	config := &strings.Builder{}
+	err = nftablesNetEgressGateways.Execute(config, tplFields)
+	if err != nil {
+		return fmt.Errorf("Failed running %q template: %w", nftablesNetEgressGateways.Name(), err)
+	}
+
+	err = subprocess.RunCommandWithFds(context.TODO(), strings.NewReader(config.String()), nil, "nft", "-f", "-")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// NetworkApplyQoS applies the rules classifying the traffic of the NICs of the network in its QoS classes.
+// The traffic sent by the NICs gets the DSCP value of their class and the traffic sent to them goes
+// through the traffic control class of their class on the network interface.
+func (d Nftables) NetworkApplyQoS(networkName string, classes []QoSClass) error {
+	var rules []map[string]any
+
+	for _, class := range classes {
+		if len(class.HwAddrs) == 0 || (class.Priority == "" && class.DSCP == "") {
+			continue
+		}
+
+		rules = append(rules, map[string]any{
+			"priority": class.Priority,
+			"dscp":     class.DSCP,
+			"hwAddrs":  strings.Join(class.HwAddrs, ", "),
+		})
+	}
+
+	err := d.removeChains([]string{"netdev"}, networkName, "qosin", "qosout")
+	if err != nil {
+		return fmt.Errorf("Failed clearing nftables QoS rules for network %q: %w", networkName, err)
+	}
+
+	if len(rules) == 0 {
+		return nil
+	}
+
+	tplFields := map[string]any{
+		"namespace":      nftablesNamespace,
+		"chainSeparator": nftablesChainSeparator,
+		"family":         "netdev",
+		"label":          networkName,
+		"rules":          rules,
+	}
+
+	err = d.applyNftConfig(nftablesNetQoS, tplFields)
+	if err != nil {
+		return fmt.Errorf("Failed adding QoS rules for network %q: %w", networkName, err)
+	}
+
+	return nil
+}
+
+// NetworkApplyPeers applies the rules allowing traffic to be forwarded between a network and its peers.
+func (d Nftables) NetworkApplyPeers(networkName string, peers []NetworkPeer) error {
+	var rules []map[string]any
+
+	for _, peer := range peers {
+		for _, ipFamily := range []string{"ip", "ip6"} {
+			localSubnets := nftSubnetsSet(peer.LocalSubnets, ipFamily == "ip")
+			targetSubnets := nftSubnetsSet(peer.TargetSubnets, ipFamily == "ip")
+
+			if localSubnets == "" || targetSubnets == "" {
+				continue
+			}
+
+			rules = append(rules, map[string]any{
+				"interface":     peer.Interface,
+				"ipFamily":      ipFamily,
+				"localSubnets":  localSubnets,
+				"targetSubnets": targetSubnets,
+			})
+		}
+	}
+
+	tplFields := map[string]any{
+		"namespace":      nftablesNamespace,
+		"chainSeparator": nftablesChainSeparator,
+		"family":         "inet",
+		"networkName":    networkName,
+		"rules":          rules,
+	}
+
+	config := &strings.Builder{}
+	err := nftablesNetPeers.Execute(config, tplFields)
+	if err != nil {
+		return fmt.Errorf("Failed running %q template: %w", nftablesNetPeers.Name(), err)
+	}
+
+	err = subprocess.RunCommandWithFds(context.TODO(), strings.NewReader(config.String()), nil, "nft", "-f", "-")
+	if err != nil {
+		return fmt.Errorf("Failed applying nftables peer rules for network %q: %w", networkName, err)
+	}
+
+	return nil
+}
+
+// nftSubnetsSet returns an anonymous set of the IPv4 or IPv6 subnets, or an empty string if there are none.
+func nftSubnetsSet(subnets []*net.IPNet, ipv4 bool) string {
+	var elements []string
+
+	for _, subnet := range subnets {
+		if (subnet.IP.To4() != nil) != ipv4 {
+			continue
+		}
+
+		elements = append(elements, subnet.String())
+	}
+
+	if len(elements) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("{%s}", strings.Join(elements, ", "))
+}
+
 // NetworkApplyAddressSets creates or updates named nft sets for all address sets.
 func (d Nftables) NetworkApplyAddressSets(sets []AddressSet, nftTable string) error {
 	_, err := subprocess.RunCommand("nft", "create", "table", nftTable, nftablesNamespace)
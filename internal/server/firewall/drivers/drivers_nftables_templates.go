@@ -11,9 +11,14 @@ table {{.family}} {{.namespace}} {
 `))
 
 var nftablesNetForwardingPolicy = template.Must(template.New("nftablesNetForwardingPolicy").Parse(`
+chain peer{{.chainSeparator}}{{.networkName}} {
+}
+
 chain fwd{{.chainSeparator}}{{.networkName}} {
 	type filter hook forward priority 0; policy accept;
 
+	jump peer{{.chainSeparator}}{{.networkName}}
+
 	{{ if .ip4Action }}
 	ip version 4 oifname "{{.networkName}}" {{.ip4Action}}
 	ip version 4 iifname "{{.networkName}}" {{.ip4Action}}
@@ -127,6 +132,103 @@ table {{.family}} {{.namespace}} {
 }
 `))
 
+var nftablesNetLoadBalancer = template.Must(template.New("nftablesNetLoadBalancer").Parse(`
+add table {{.family}} {{.namespace}}
+add chain {{.family}} {{.namespace}} lbprert{{.chainSeparator}}{{.label}} {type nat hook prerouting priority -100; policy accept;}
+add chain {{.family}} {{.namespace}} lbout{{.chainSeparator}}{{.label}} {type nat hook output priority -100; policy accept;}
+add chain {{.family}} {{.namespace}} lbpstrt{{.chainSeparator}}{{.label}} {type nat hook postrouting priority 100; policy accept;}
+flush chain {{.family}} {{.namespace}} lbprert{{.chainSeparator}}{{.label}}
+flush chain {{.family}} {{.namespace}} lbout{{.chainSeparator}}{{.label}}
+flush chain {{.family}} {{.namespace}} lbpstrt{{.chainSeparator}}{{.label}}
+
+table {{.family}} {{.namespace}} {
+	chain lbprert{{.chainSeparator}}{{.label}} {
+		type nat hook prerouting priority -100; policy accept;
+		{{ range .dnatRules }}
+		{{.ipFamily}} daddr {{.listenAddress}} {{.protocol}} dport {{.listenPort}} {{.dnat}}
+		{{ end }}
+	}
+
+	chain lbout{{.chainSeparator}}{{.label}} {
+		type nat hook output priority -100; policy accept;
+		{{ range .dnatRules }}
+		{{.ipFamily}} daddr {{.listenAddress}} {{.protocol}} dport {{.listenPort}} {{.dnat}}
+		{{ end }}
+	}
+
+	chain lbpstrt{{.chainSeparator}}{{.label}} {
+		type nat hook postrouting priority 100; policy accept;
+		{{ range .snatRules }}
+		{{.ipFamily}} saddr {{.targetAddress}} {{.ipFamily}} daddr {{.targetAddress}} {{.protocol}} dport {{.targetPort}} masquerade
+		{{ end }}
+	}
+}
+`))
+
+// nftablesNetQoS defines the rules classifying the traffic of the NICs of a network in its QoS classes.
+var nftablesNetQoS = template.Must(template.New("nftablesNetQoS").Parse(`
+chain qosin{{.chainSeparator}}{{.label}} {
+	type filter hook ingress device "{{.label}}" priority 0; policy accept;
+	{{ range .rules }}
+	{{ if .dscp }}
+	ether saddr { {{.hwAddrs}} } ip dscp set {{.dscp}}
+	ether saddr { {{.hwAddrs}} } ip6 dscp set {{.dscp}}
+	{{ end }}
+	{{ end }}
+}
+
+chain qosout{{.chainSeparator}}{{.label}} {
+	type filter hook egress device "{{.label}}" priority 0; policy accept;
+	{{ range .rules }}
+	{{ if .priority }}
+	ether daddr { {{.hwAddrs}} } meta priority set {{.priority}}
+	{{ end }}
+	{{ end }}
+}
+`))
+
+var nftablesNetEgressGateways = template.Must(template.New("nftablesNetEgressGateways").Parse(`
+add table {{.family}} {{.namespace}}
+add chain {{.family}} {{.namespace}} egwprert{{.chainSeparator}}{{.label}} {type filter hook prerouting priority 0; policy accept;}
+add chain {{.family}} {{.namespace}} egwpstrt{{.chainSeparator}}{{.label}} {type nat hook postrouting priority 99; policy accept;}
+flush chain {{.family}} {{.namespace}} egwprert{{.chainSeparator}}{{.label}}
+flush chain {{.family}} {{.namespace}} egwpstrt{{.chainSeparator}}{{.label}}
+
+table {{.family}} {{.namespace}} {
+	set bridges {
+		type ifname
+	}
+
+	chain egwprert{{.chainSeparator}}{{.label}} {
+		type filter hook prerouting priority 0; policy accept;
+		{{ range .rules }}
+		iifname "{{$.label}}" ether saddr { {{.hwAddrs}} } meta nfproto {{.nfproto}} {{ if .excludeSubnet }}{{.ipFamily}} saddr != {{.excludeSubnet}} {{ end }}meta mark set meta mark and {{$.markKeep}} or {{.mark}}
+		{{ end }}
+	}
+
+	chain egwpstrt{{.chainSeparator}}{{.label}} {
+		type nat hook postrouting priority 99; policy accept;
+		{{ range .rules }}
+		iifname "{{$.label}}" meta mark and {{$.markMask}} == {{.mark}} meta nfproto {{.nfproto}} oifname != @bridges snat {{.ipFamily}} to {{.address}}
+		{{ end }}
+	}
+}
+`))
+var nftablesNetPeers = template.Must(template.New("nftablesNetPeers").Parse(`
+add table {{.family}} {{.namespace}}
+add chain {{.family}} {{.namespace}} peer{{.chainSeparator}}{{.networkName}}
+flush chain {{.family}} {{.namespace}} peer{{.chainSeparator}}{{.networkName}}
+
+table {{.family}} {{.namespace}} {
+	chain peer{{.chainSeparator}}{{.networkName}} {
+		{{ range .rules }}
+		iifname "{{$.networkName}}" oifname "{{.interface}}" {{.ipFamily}} saddr {{.localSubnets}} {{.ipFamily}} daddr {{.targetSubnets}} accept
+		iifname "{{.interface}}" oifname "{{$.networkName}}" {{.ipFamily}} saddr {{.targetSubnets}} {{.ipFamily}} daddr {{.localSubnets}} accept
+		{{ end }}
+	}
+}
+`))
+
 var nftablesNetACLSetup = template.Must(template.New("nftablesNetACLSetup").Parse(`
 add table {{.family}} {{.namespace}}
 add chain {{.family}} {{.namespace}} acl{{.chainSeparator}}{{.networkName}}
@@ -0,0 +1,24 @@
+package drivers
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_nftSubnetsSet(t *testing.T) {
+	var subnets []*net.IPNet
+	for _, cidr := range []string{"10.0.0.0/24", "fd42::/64", "192.0.2.0/28"} {
+		_, subnet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		subnets = append(subnets, subnet)
+	}
+
+	assert.Equal(t, "{10.0.0.0/24, 192.0.2.0/28}", nftSubnetsSet(subnets, true))
+	assert.Equal(t, "{fd42::/64}", nftSubnetsSet(subnets, false))
+	assert.Empty(t, nftSubnetsSet(subnets[1:2], true))
+}
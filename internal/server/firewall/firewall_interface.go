@@ -25,6 +25,10 @@ type Firewall interface {
 	NetworkClear(networkName string, removeChains bool, ipVersions []uint) error
 	NetworkApplyACLRules(networkName string, rules []drivers.ACLRule) error
 	NetworkApplyForwards(networkName string, rules []drivers.AddressForward) error
+	NetworkApplyLoadBalancers(networkName string, rules []drivers.LoadBalancer) error
+	NetworkApplyEgressGateways(networkName string, gateways []drivers.EgressGateway) error
+	NetworkApplyQoS(networkName string, classes []drivers.QoSClass) error
+	NetworkApplyPeers(networkName string, peers []drivers.NetworkPeer) error
 	NetworkApplyAddressSets(sets []drivers.AddressSet, nftTable string) error
 	NetworkDeleteAddressSetsIfUnused(nftTable string) error
 
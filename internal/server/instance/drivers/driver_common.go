@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"maps"
 	"math"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -18,7 +19,9 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/kballard/go-shellquote"
 	"github.com/prometheus/procfs"
+	"golang.org/x/sys/unix"
 
 	internalInstance "github.com/lxc/incus/v7/internal/instance"
 	"github.com/lxc/incus/v7/internal/server/backup"
@@ -32,6 +35,7 @@ import (
 	"github.com/lxc/incus/v7/internal/server/instance/operationlock"
 	"github.com/lxc/incus/v7/internal/server/lifecycle"
 	"github.com/lxc/incus/v7/internal/server/locking"
+	"github.com/lxc/incus/v7/internal/server/network"
 	"github.com/lxc/incus/v7/internal/server/operations"
 	"github.com/lxc/incus/v7/internal/server/project"
 	"github.com/lxc/incus/v7/internal/server/selinux"
@@ -53,6 +57,34 @@ var (
 	muInstancesLastRestart sync.Mutex
 )
 
+// Track the health of an instance.
+var (
+	instancesHealth   = map[int]*instanceHealth{}
+	muInstancesHealth sync.Mutex
+)
+
+// Features of the running instances which the daemon periodically acts on.
+const (
+	// RunningWithHealthCheck is the feature of the running instances which have a health check.
+	RunningWithHealthCheck = "healthcheck"
+
+	// RunningWithMemoryBalloon is the feature of the running VMs which have "limits.memory.balloon" enabled.
+	RunningWithMemoryBalloon = "memory-balloon"
+)
+
+// Track the local running instances with the features the daemon periodically acts on.
+var (
+	instancesRunningWith   = map[string]map[int]bool{}
+	muInstancesRunningWith sync.Mutex
+)
+
+// instanceHealth represents the health of an instance since it was started.
+type instanceHealth struct {
+	api.InstanceStateHealth
+
+	startedAt time.Time
+}
+
 // ErrExecCommandNotFound indicates the command is not found.
 var ErrExecCommandNotFound = api.StatusErrorf(http.StatusBadRequest, "Command not found")
 
@@ -211,6 +243,332 @@ func (d *common) shouldAutoRestart() bool {
 	return false
 }
 
+// runningFeatures returns the features the daemon periodically acts on which the instance config enables.
+func runningFeatures(instanceType instancetype.Type, config map[string]string) []string {
+	features := []string{}
+
+	if config["healthcheck.type"] != "" {
+		features = append(features, RunningWithHealthCheck)
+	}
+
+	if instanceType == instancetype.VM && util.IsTrue(config["limits.memory.balloon"]) {
+		features = append(features, RunningWithMemoryBalloon)
+	}
+
+	return features
+}
+
+// TrackRunning records whether the instance is running, along with the features the daemon periodically acts on
+// which it enables. It's called when the instance starts, stops or changes, and for the running instances when the
+// daemon starts.
+func TrackRunning(inst instance.Instance, running bool) {
+	features := []string{}
+	if running {
+		features = runningFeatures(inst.Type(), inst.ExpandedConfig())
+	}
+
+	muInstancesRunningWith.Lock()
+	for _, ids := range instancesRunningWith {
+		delete(ids, inst.ID())
+	}
+
+	for _, feature := range features {
+		if instancesRunningWith[feature] == nil {
+			instancesRunningWith[feature] = map[int]bool{}
+		}
+
+		instancesRunningWith[feature][inst.ID()] = true
+	}
+
+	muInstancesRunningWith.Unlock()
+
+	// The health of a stopped instance doesn't apply anymore.
+	if !running {
+		muInstancesHealth.Lock()
+		delete(instancesHealth, inst.ID())
+		muInstancesHealth.Unlock()
+	}
+}
+
+// RunningWith returns the IDs of the local running instances which enable the feature.
+func RunningWith(feature string) []int {
+	muInstancesRunningWith.Lock()
+	defer muInstancesRunningWith.Unlock()
+
+	ids := make([]int, 0, len(instancesRunningWith[feature]))
+	for id := range instancesRunningWith[feature] {
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+// healthState returns the health of the instance for the run started at startedAt.
+// It returns nil if the instance doesn't have a health check.
+func (d *common) healthState(startedAt time.Time) *api.InstanceStateHealth {
+	if d.expandedConfig["healthcheck.type"] == "" {
+		return nil
+	}
+
+	muInstancesHealth.Lock()
+	defer muInstancesHealth.Unlock()
+
+	// Results of a previous run of the instance don't apply anymore.
+	health, ok := instancesHealth[d.id]
+	if !ok || !health.startedAt.Equal(startedAt) {
+		return &api.InstanceStateHealth{Status: "starting"}
+	}
+
+	state := health.InstanceStateHealth
+
+	return &state
+}
+
+// healthCheck runs the health check of the instance and returns its updated health.
+func (d *common) healthCheck(inst instance.Instance) (*api.InstanceStateHealth, error) {
+	if d.expandedConfig["healthcheck.type"] == "" {
+		return nil, errors.New("The instance doesn't have a health check")
+	}
+
+	if !inst.IsRunning() {
+		return nil, ErrInstanceIsStopped
+	}
+
+	startedAt, err := d.processStartedAt(inst.InitPID())
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting instance start time: %w", err)
+	}
+
+	timeout := 5 * time.Second
+	if d.expandedConfig["healthcheck.timeout"] != "" {
+		value, err := strconv.Atoi(d.expandedConfig["healthcheck.timeout"])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid healthcheck.timeout: %w", err)
+		}
+
+		timeout = time.Duration(value) * time.Second
+	}
+
+	threshold := int64(3)
+	if d.expandedConfig["healthcheck.failures"] != "" {
+		threshold, err = strconv.ParseInt(d.expandedConfig["healthcheck.failures"], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid healthcheck.failures: %w", err)
+		}
+	}
+
+	var startPeriod time.Duration
+	if d.expandedConfig["healthcheck.start_period"] != "" {
+		value, err := strconv.Atoi(d.expandedConfig["healthcheck.start_period"])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid healthcheck.start_period: %w", err)
+		}
+
+		startPeriod = time.Duration(value) * time.Second
+	}
+
+	checkErr := d.healthProbe(inst, timeout)
+
+	state := d.healthRecord(startedAt, threshold, startPeriod, checkErr)
+
+	return &state, nil
+}
+
+// healthRecord records the result of a health check of the run of the instance started at startedAt and returns
+// its updated health. The instance becomes unhealthy after threshold consecutive failed checks. Failed checks don't
+// count until the start period has passed, unless a check already succeeded.
+func (d *common) healthRecord(startedAt time.Time, threshold int64, startPeriod time.Duration, checkErr error) api.InstanceStateHealth {
+	muInstancesHealth.Lock()
+	defer muInstancesHealth.Unlock()
+
+	health, ok := instancesHealth[d.id]
+	if !ok || !health.startedAt.Equal(startedAt) {
+		health = &instanceHealth{
+			InstanceStateHealth: api.InstanceStateHealth{Status: "starting"},
+			startedAt:           startedAt,
+		}
+
+		instancesHealth[d.id] = health
+	}
+
+	health.LastCheck = time.Now()
+
+	if checkErr == nil {
+		health.Status = "healthy"
+		health.Failures = 0
+		health.LastError = ""
+	} else {
+		health.LastError = checkErr.Error()
+
+		if health.Status == "starting" && health.LastCheck.Sub(startedAt) < startPeriod {
+			return health.InstanceStateHealth
+		}
+
+		health.Failures++
+		if health.Failures >= threshold {
+			health.Status = "unhealthy"
+		}
+	}
+
+	return health.InstanceStateHealth
+}
+
+// healthProbe runs a single health check of the instance.
+func (d *common) healthProbe(inst instance.Instance, timeout time.Duration) error {
+	checkType := d.expandedConfig["healthcheck.type"]
+	if checkType == "exec" {
+		return d.healthProbeExec(inst, timeout)
+	}
+
+	if checkType != "tcp" && checkType != "http" {
+		return fmt.Errorf("Unsupported health check type %q", checkType)
+	}
+
+	address := d.expandedConfig["healthcheck.address"]
+	if address == "" {
+		address = "127.0.0.1"
+	}
+
+	port, err := strconv.Atoi(d.expandedConfig["healthcheck.port"])
+	if err != nil {
+		return fmt.Errorf("Invalid healthcheck.port: %w", err)
+	}
+
+	// Connecting from within the instance can hang, so don't wait for it beyond the timeout.
+	type connResult struct {
+		conn net.Conn
+		err  error
+	}
+
+	connCh := make(chan connResult, 1)
+	go func() {
+		conn, err := inst.PortForwardConn(address, port)
+		connCh <- connResult{conn: conn, err: err}
+	}()
+
+	var conn net.Conn
+
+	select {
+	case res := <-connCh:
+		if res.err != nil {
+			return res.err
+		}
+
+		conn = res.conn
+	case <-time.After(timeout):
+		// Close the connection if it eventually gets established.
+		go func() {
+			res := <-connCh
+			if res.conn != nil {
+				_ = res.conn.Close()
+			}
+		}()
+
+		return fmt.Errorf("Timed out connecting to %s", net.JoinHostPort(address, strconv.Itoa(port)))
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	if checkType == "tcp" {
+		return nil
+	}
+
+	err = conn.SetDeadline(time.Now().Add(timeout))
+	if err != nil {
+		return err
+	}
+
+	path := d.expandedConfig["healthcheck.path"]
+	if path == "" {
+		path = "/"
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(_ context.Context, _ string, _ string) (net.Conn, error) {
+				return conn, nil
+			},
+			DisableKeepAlives: true,
+		},
+		Timeout: timeout,
+	}
+
+	resp, err := client.Get("http://" + net.JoinHostPort(address, strconv.Itoa(port)) + path)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("Unexpected HTTP status %q", resp.Status)
+	}
+
+	return nil
+}
+
+// healthProbeExec runs the health check command of the instance.
+func (d *common) healthProbeExec(inst instance.Instance, timeout time.Duration) error {
+	command, err := shellquote.Split(d.expandedConfig["healthcheck.command"])
+	if err != nil {
+		return fmt.Errorf("Invalid healthcheck.command: %w", err)
+	}
+
+	if len(command) == 0 {
+		return errors.New("Empty healthcheck.command")
+	}
+
+	devNull, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = devNull.Close() }()
+
+	req := api.InstanceExecPost{
+		Command: command,
+		Environment: map[string]string{
+			"PATH": "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
+			"HOME": "/root",
+			"LANG": "C.UTF-8",
+		},
+		Cwd: "/",
+	}
+
+	cmd, err := inst.Exec(req, devNull, devNull, devNull)
+	if err != nil {
+		return err
+	}
+
+	type waitResult struct {
+		exitStatus int
+		err        error
+	}
+
+	waitCh := make(chan waitResult, 1)
+	go func() {
+		exitStatus, err := cmd.Wait()
+		waitCh <- waitResult{exitStatus: exitStatus, err: err}
+	}()
+
+	select {
+	case res := <-waitCh:
+		if res.err != nil {
+			return res.err
+		}
+
+		if res.exitStatus != 0 {
+			return fmt.Errorf("Command exited with status %d", res.exitStatus)
+		}
+
+		return nil
+	case <-time.After(timeout):
+		_ = cmd.Signal(unix.SIGKILL)
+
+		return fmt.Errorf("Command timed out after %s", timeout)
+	}
+}
+
 // ID gets instances's ID.
 func (d *common) ID() int {
 	return d.id
@@ -1519,6 +1877,36 @@ func (d *common) devicesUpdate(inst instance.Instance, removeDevices deviceConfi
 	return nil
 }
 
+// egressGatewaysUpdate re-applies the egress gateways of the networks of the instance when its profiles or NICs
+// changed, as the gateways may select it through its profiles and match its NICs by MAC address.
+func (d *common) egressGatewaysUpdate(oldProfiles []api.Profile, oldExpandedDevices deviceConfig.Devices) error {
+	nics := func(devices deviceConfig.Devices) deviceConfig.Devices {
+		nicDevices := deviceConfig.Devices{}
+		for devName, devConfig := range devices {
+			if devConfig["type"] == "nic" {
+				nicDevices[devName] = devConfig
+			}
+		}
+
+		return nicDevices
+	}
+
+	profilesChanged := !slices.EqualFunc(oldProfiles, d.profiles, func(a api.Profile, b api.Profile) bool { return a.Project == b.Project && a.Name == b.Name })
+	nicsChanged := !maps.EqualFunc(nics(oldExpandedDevices), nics(d.expandedDevices), maps.Equal)
+
+	if !profilesChanged && !nicsChanged {
+		return nil
+	}
+
+	// Cover the networks the instance left too.
+	devices := d.expandedDevices.Clone()
+	for devName, devConfig := range oldExpandedDevices {
+		devices["old/"+devName] = devConfig
+	}
+
+	return network.EgressGatewaysRefreshDevices(d.state, d.project.Name, devices)
+}
+
 // devicesRemove runs device removal function for each device.
 func (d *common) devicesRemove(inst instance.Instance, cleanupDependencies bool) {
 	for _, entry := range d.expandedDevices.Reversed() {
@@ -0,0 +1,93 @@
+package drivers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test healthRecord and healthState.
+func TestHealthTransitions(t *testing.T) {
+	d := &common{id: 1001, expandedConfig: map[string]string{"healthcheck.type": "exec"}}
+	defer delete(instancesHealth, d.id)
+
+	startedAt := time.Now().Add(-time.Minute)
+	checkErr := errors.New("Command exited with status 1")
+
+	// Nothing is known before the first check.
+	health := d.healthState(startedAt)
+	require.NotNil(t, health)
+	assert.Equal(t, "starting", health.Status)
+
+	// Failures only make the instance unhealthy once they reach the threshold.
+	state := d.healthRecord(startedAt, 2, 0, checkErr)
+	assert.Equal(t, "starting", state.Status)
+	assert.Equal(t, int64(1), state.Failures)
+	assert.Equal(t, checkErr.Error(), state.LastError)
+
+	state = d.healthRecord(startedAt, 2, 0, checkErr)
+	assert.Equal(t, "unhealthy", state.Status)
+	assert.Equal(t, int64(2), state.Failures)
+
+	health = d.healthState(startedAt)
+	require.NotNil(t, health)
+	assert.Equal(t, "unhealthy", health.Status)
+
+	// A successful check resets the failures.
+	state = d.healthRecord(startedAt, 2, 0, nil)
+	assert.Equal(t, "healthy", state.Status)
+	assert.Equal(t, int64(0), state.Failures)
+	assert.Empty(t, state.LastError)
+
+	state = d.healthRecord(startedAt, 2, 0, checkErr)
+	assert.Equal(t, "healthy", state.Status)
+	assert.Equal(t, int64(1), state.Failures)
+
+	// The results of a previous run don't apply to a new one.
+	restartedAt := time.Now()
+
+	health = d.healthState(restartedAt)
+	require.NotNil(t, health)
+	assert.Equal(t, "starting", health.Status)
+
+	state = d.healthRecord(restartedAt, 2, 0, checkErr)
+	assert.Equal(t, "starting", state.Status)
+	assert.Equal(t, int64(1), state.Failures)
+
+	// Instances without a health check have no health.
+	d.expandedConfig = map[string]string{}
+	assert.Nil(t, d.healthState(restartedAt))
+}
+
+// Test the start period of healthRecord.
+func TestHealthStartPeriod(t *testing.T) {
+	d := &common{id: 1002, expandedConfig: map[string]string{"healthcheck.type": "tcp"}}
+	defer delete(instancesHealth, d.id)
+
+	startedAt := time.Now()
+	checkErr := errors.New("Timed out connecting to 127.0.0.1:80")
+
+	// Failures within the start period don't count.
+	state := d.healthRecord(startedAt, 1, time.Minute, checkErr)
+	assert.Equal(t, "starting", state.Status)
+	assert.Equal(t, int64(0), state.Failures)
+	assert.Equal(t, checkErr.Error(), state.LastError)
+
+	// Once a check succeeded, failures count even within the start period.
+	state = d.healthRecord(startedAt, 1, time.Minute, nil)
+	assert.Equal(t, "healthy", state.Status)
+
+	state = d.healthRecord(startedAt, 1, time.Minute, checkErr)
+	assert.Equal(t, "unhealthy", state.Status)
+	assert.Equal(t, int64(1), state.Failures)
+
+	// Failures after the start period count.
+	startedAt = time.Now().Add(-2 * time.Minute)
+
+	state = d.healthRecord(startedAt, 1, time.Minute, checkErr)
+	assert.Equal(t, "unhealthy", state.Status)
+	assert.Equal(t, int64(1), state.Failures)
+}
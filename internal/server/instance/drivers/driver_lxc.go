@@ -273,6 +273,11 @@ func lxcCreate(s *state.State, args db.InstanceArgs, p api.Project, partialDevic
 		if err != nil {
 			return nil, nil, fmt.Errorf("Invalid devices: %w", err)
 		}
+
+		err = instance.ValidDepends(s, d.project.Name, d.name, d.expandedConfig)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Invalid config: %w", err)
+		}
 	}
 
 	_, rootDiskDevice, err := d.getRootDiskDevice()
@@ -3057,6 +3062,8 @@ func (d *lxc) Start(stateful bool) error {
 			return fmt.Errorf("Failed clearing instance stateful flag: %w", err)
 		}
 
+		TrackRunning(d, true)
+
 		if op.Action() == "start" {
 			d.logger.Info("Started instance", ctxMap)
 			d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceStarted.Event(d, nil))
@@ -3186,6 +3193,8 @@ func (d *lxc) Start(stateful bool) error {
 		})
 	}
 
+	TrackRunning(d, true)
+
 	if op.Action() == "start" {
 		d.logger.Info("Started instance", ctxMap)
 		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceStarted.Event(d, nil))
@@ -3245,6 +3254,13 @@ func (d *lxc) onStart(_ map[string]string) error {
 		return err
 	}
 
+	// Provide the Ignition config to the container.
+	err = d.ignitionApplyNow()
+	if err != nil {
+		_ = apparmor.InstanceUnload(d.state.OS, d)
+		return err
+	}
+
 	// Trigger a rebalance
 	defer cgroup.TaskSchedulerTrigger("container", d.name, "started")
 
@@ -3676,6 +3692,9 @@ func (d *lxc) onStop(args map[string]string) error {
 		d.logger.Error("Failed recording last power state", logger.Ctx{"err": err})
 	}
 
+	// The periodic instance tasks don't apply anymore.
+	TrackRunning(d, false)
+
 	go func(d *lxc, target string, op *operationlock.InstanceOperation) {
 		d.fromHook = false
 		err = nil
@@ -4156,6 +4175,8 @@ func (d *lxc) renderState(statusCode api.StatusCode, hostInterfaces []net.Interf
 		if err != nil {
 			return nil, err
 		}
+
+		status.Health = d.healthState(status.StartedAt)
 	}
 
 	status.Disk = d.diskState()
@@ -4170,6 +4191,11 @@ func (d *lxc) RenderState(hostInterfaces []net.Interface) (*api.InstanceState, e
 	return d.renderState(d.statusCode(), hostInterfaces)
 }
 
+// HealthCheck runs the health check of the instance and returns its updated health.
+func (d *lxc) HealthCheck() (*api.InstanceStateHealth, error) {
+	return d.healthCheck(d)
+}
+
 // snapshot creates a snapshot of the instance.
 func (d *lxc) snapshot(name string, expiry time.Time, stateful bool) error {
 	// Check that migration.stateful is set for stateful actions.
@@ -5159,6 +5185,13 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 			return fmt.Errorf("Invalid expanded devices: %w", err)
 		}
 
+		if d.expandedConfig["boot.depends"] != oldExpandedConfig["boot.depends"] {
+			err = instance.ValidDepends(d.state, d.project.Name, d.name, d.expandedConfig)
+			if err != nil {
+				return fmt.Errorf("Invalid expanded config: %w", err)
+			}
+		}
+
 		// Validate root device
 		_, oldRootDev, oldErr := internalInstance.GetRootDiskDevice(oldExpandedDevices.CloneNative())
 		_, newRootDev, newErr := internalInstance.GetRootDiskDevice(d.expandedDevices.CloneNative())
@@ -5240,6 +5273,11 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 		return err
 	}
 
+	err = d.egressGatewaysUpdate(oldProfiles, oldExpandedDevices)
+	if err != nil {
+		return fmt.Errorf("Failed applying network egress gateways: %w", err)
+	}
+
 	// Apply the live changes
 	if isRunning {
 		cc, err := d.initLXC(false)
@@ -5694,6 +5732,10 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 	// Success, update the closure to mark that the changes should be kept.
 	undoChanges = false
 
+	if !d.isSnapshot {
+		TrackRunning(d, isRunning)
+	}
+
 	if userRequested {
 		if d.isSnapshot {
 			d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceSnapshotUpdated.Event(d, nil))
@@ -7517,6 +7559,62 @@ func (d *lxc) migrate(args *instance.CriuMigrationArgs) error {
 	return nil
 }
 
+// ignitionApplyNow writes the Ignition config of the container, if any, to /etc/ignition/config.ign in its rootfs.
+func (d *lxc) ignitionApplyNow() error {
+	ignitionConfig, err := instance.IgnitionConfig(d)
+	if err != nil {
+		return err
+	}
+
+	if ignitionConfig == "" {
+		return nil
+	}
+
+	rootfs, err := os.OpenRoot(d.RootfsPath())
+	if err != nil {
+		return fmt.Errorf("Failed to open instance rootfs path: %w", err)
+	}
+
+	defer logger.WarnOnError(rootfs.Close, "Failed to close rootfs")
+
+	relPath := "etc/ignition/config.ign"
+
+	// Get the right uid and gid for the container.
+	idmapset, err := d.DiskIdmap()
+	if err != nil {
+		return fmt.Errorf("Failed to set ID map: %w", err)
+	}
+
+	rootUID := int64(0)
+	rootGID := int64(0)
+
+	if idmapset != nil {
+		rootUID, rootGID = idmapset.ShiftIntoNS(0, 0)
+	}
+
+	err = rootfs.Mkdir("etc/ignition", 0o755)
+	if err == nil {
+		err = rootfs.Chown("etc/ignition", int(rootUID), int(rootGID))
+		if err != nil {
+			return fmt.Errorf("Failed to set ownership on Ignition directory: %w", err)
+		}
+	} else if !errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("Failed to create Ignition directory: %w", err)
+	}
+
+	err = rootfs.WriteFile(relPath, []byte(ignitionConfig), 0o600)
+	if err != nil {
+		return fmt.Errorf("Failed to write Ignition config: %w", err)
+	}
+
+	err = rootfs.Chown(relPath, int(rootUID), int(rootGID))
+	if err != nil {
+		return fmt.Errorf("Failed to set ownership on Ignition config: %w", err)
+	}
+
+	return nil
+}
+
 func (d *lxc) templateApplyNow(trigger instance.TemplateTrigger) error {
 	// If there's no metadata, just return
 	fname := filepath.Join(d.Path(), "metadata.yaml")
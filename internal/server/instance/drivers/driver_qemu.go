@@ -274,6 +274,11 @@ func qemuCreate(s *state.State, args db.InstanceArgs, p api.Project, partialDevi
 		if err != nil {
 			return nil, nil, fmt.Errorf("Invalid devices: %w", err)
 		}
+
+		err = instance.ValidDepends(s, d.project.Name, d.name, d.expandedConfig)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Invalid config: %w", err)
+		}
 	}
 
 	// Retrieve the instance's storage pool.
@@ -774,6 +779,9 @@ func (d *qemu) onStop(target string, reason string) error {
 		monitor.Disconnect()
 	}
 
+	// The periodic instance tasks don't apply anymore.
+	TrackRunning(d, false)
+
 	// Record power state.
 	err = d.VolatileSet(map[string]string{
 		"volatile.last_state.power": instance.PowerStateStopped,
@@ -789,6 +797,13 @@ func (d *qemu) onStop(target string, reason string) error {
 	_ = os.Remove(d.pidFilePath())
 	_ = os.Remove(d.monitorPath())
 	_ = os.Remove(d.spicePath())
+	_ = os.Remove(d.vncPath())
+
+	// Remove the volume left behind by a live storage pool move.
+	err = d.completeStorageMove()
+	if err != nil {
+		d.logger.Error("Failed completing live storage pool move", logger.Ctx{"err": err})
+	}
 
 	// Stop the storage for the instance.
 	err = d.unmount()
@@ -1541,7 +1556,7 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 	}
 
 	// Cleanup old sockets.
-	for _, socketPath := range []string{d.consolePath(), d.spicePath(), d.monitorPath(), d.nbdPath()} {
+	for _, socketPath := range []string{d.consolePath(), d.spicePath(), d.vncPath(), d.monitorPath(), d.nbdPath()} {
 		_ = os.Remove(socketPath)
 	}
 
@@ -2394,6 +2409,8 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		})
 	}
 
+	TrackRunning(d, true)
+
 	if op.Action() == "start" {
 		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceStarted.Event(d, nil))
 	}
@@ -3342,6 +3359,10 @@ func (d *qemu) spicePath() string {
 	return filepath.Join(d.RunPath(), "qemu.spice")
 }
 
+func (d *qemu) vncPath() string {
+	return filepath.Join(d.RunPath(), "qemu.vnc")
+}
+
 func (d *qemu) nbdPath() string {
 	return filepath.Join(d.RunPath(), "qemu.nbd")
 }
@@ -3371,6 +3392,19 @@ func (d *qemu) spiceConfig(fdFiles *[]*os.File) ([]cfg.Section, error) {
 	}}, nil
 }
 
+func (d *qemu) vncConfig(fdFiles *[]*os.File) ([]cfg.Section, error) {
+	// Reference the socket through a short /proc/self/fd path to handle
+	// run paths that exceed the unix socket path limit.
+	vncDir, err := os.OpenFile(d.RunPath(), unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	vncDirFD := d.addFileDescriptor(fdFiles, vncDir)
+
+	return qemuVNC(&qemuVNCOpts{path: fmt.Sprintf("/proc/self/fd/%d/qemu.vnc", vncDirFD)}), nil
+}
+
 // generateConfigShare generates the config share directory that will be exported to the VM via
 // a 9P share. Due to the unknown size of templates inside the images this directory is created
 // inside the VM's config volume so that it can be restricted by quota.
@@ -3669,6 +3703,25 @@ func (d *qemu) generateConfigShare(volatileSet map[string]string) error {
 		}
 	}
 
+	// Ignition config.
+	ignitionConfig, err := instance.IgnitionConfig(d)
+	if err != nil {
+		return err
+	}
+
+	ignitionPath := filepath.Join(configDrivePath, "ignition.ign")
+	if ignitionConfig != "" {
+		err = os.WriteFile(ignitionPath, []byte(ignitionConfig), 0o400)
+		if err != nil {
+			return err
+		}
+	} else {
+		err = os.Remove(ignitionPath)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
 	// Templated files.
 	templateFilesPath := filepath.Join(configDrivePath, "files")
 
@@ -4155,11 +4208,14 @@ func (d *qemu) generateQemuConfig(bs *qemuBootState, mountInfo *storagePools.Mou
 	// total of 256 devices, but this assumes 32 chassis * 8 function. By using VFs for the internal fixed
 	// devices we avoid consuming a chassis for each one.
 	devBus, devAddr, multi := bus.allocate(busFunctionGroupGeneric)
-	balloonOpts := qemuDevOpts{
-		busName:       bus.name,
-		devBus:        devBus,
-		devAddr:       devAddr,
-		multifunction: multi,
+	balloonOpts := qemuBalloonOpts{
+		dev: qemuDevOpts{
+			busName:       bus.name,
+			devBus:        devBus,
+			devAddr:       devAddr,
+			multifunction: multi,
+		},
+		freePageReporting: util.IsTrue(d.expandedConfig["limits.memory.balloon"]) && !util.IsFalse(d.expandedConfig["limits.memory.balloon.free_page_reporting"]),
 	}
 
 	conf = append(conf, qemuBalloon(&balloonOpts)...)
@@ -4216,6 +4272,7 @@ func (d *qemu) generateQemuConfig(bs *qemuBootState, mountInfo *storagePools.Mou
 
 	info := DriverStatuses()[instancetype.VM].Info
 	_, spice := info.Features["spice"]
+	_, vnc := info.Features["vnc"]
 	_, plan9 := info.Features["plan9"]
 	_, virtioSound := info.Features["virtio-sound"]
 	_, virtioVGA := info.Features["virtio-vga"]
@@ -4229,6 +4286,15 @@ func (d *qemu) generateQemuConfig(bs *qemuBootState, mountInfo *storagePools.Mou
 		conf = append(conf, spiceConf...)
 	}
 
+	if vnc && util.IsTrue(d.expandedConfig["security.vnc"]) {
+		vncConf, err := d.vncConfig(fdFiles)
+		if err != nil {
+			return nil, err
+		}
+
+		conf = append(conf, vncConf...)
+	}
+
 	devBus, devAddr, multi = bus.allocate(busFunctionGroupGeneric)
 	serialOpts := qemuSerialOpts{
 		dev: qemuDevOpts{
@@ -4536,6 +4602,14 @@ func (d *qemu) generateQemuConfig(bs *qemuBootState, mountInfo *storagePools.Mou
 		}
 	}
 
+	// Pass the Ignition config through the firmware configuration device where Ignition looks for it.
+	if d.architecture == osarch.ARCH_64BIT_INTEL_X86 || d.architecture == osarch.ARCH_64BIT_ARMV8_LITTLE_ENDIAN {
+		err = d.addIgnitionConfig(&conf, fdFiles)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Allocate 8 PCI slots for hotplug devices.
 	for range 8 {
 		bus.allocate(busFunctionGroupNone)
@@ -6086,6 +6160,29 @@ func (d *qemu) addTPMDeviceConfig(conf *[]cfg.Section, tpmConfig []deviceConfig.
 	return nil
 }
 
+// addIgnitionConfig adds the Ignition config from the config drive to the firmware configuration, if any.
+func (d *qemu) addIgnitionConfig(conf *[]cfg.Section, fdFiles *[]*os.File) error {
+	ignitionPath := filepath.Join(d.Path(), "config", "ignition.ign")
+	if !util.PathExists(ignitionPath) {
+		return nil
+	}
+
+	f, err := os.Open(ignitionPath)
+	if err != nil {
+		return fmt.Errorf("Failed opening Ignition config: %w", err)
+	}
+
+	ignitionFD := d.addFileDescriptor(fdFiles, f)
+
+	fwCfgOpts := qemuFwCfgOpts{
+		name: "opt/com.coreos/config",
+		path: fmt.Sprintf("/proc/self/fd/%d", ignitionFD),
+	}
+	*conf = append(*conf, qemuFwCfg(&fwCfgOpts)...)
+
+	return nil
+}
+
 func (d *qemu) addVmgenDeviceConfig(conf *[]cfg.Section, guid string) error {
 	vmgenIDOpts := qemuVmgenIDOpts{
 		guid: guid,
@@ -7018,6 +7115,13 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 			return fmt.Errorf("Invalid expanded devices: %w", err)
 		}
 
+		if d.expandedConfig["boot.depends"] != oldExpandedConfig["boot.depends"] {
+			err = instance.ValidDepends(d.state, d.project.Name, d.name, d.expandedConfig)
+			if err != nil {
+				return fmt.Errorf("Invalid expanded config: %w", err)
+			}
+		}
+
 		// Validate root device
 		_, oldRootDev, oldErr := internalInstance.GetRootDiskDevice(oldExpandedDevices.CloneNative())
 		_, newRootDev, newErr := internalInstance.GetRootDiskDevice(d.expandedDevices.CloneNative())
@@ -7052,11 +7156,19 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 		return err
 	}
 
+	err = d.egressGatewaysUpdate(oldProfiles, oldExpandedDevices)
+	if err != nil {
+		return fmt.Errorf("Failed applying network egress gateways: %w", err)
+	}
+
 	if isRunning {
 		// Only certain keys can be changed on a running VM.
 		liveUpdateKeys := []string{
 			"cluster.evacuate",
 			"limits.memory",
+			"limits.memory.balloon",
+			"limits.memory.balloon.min",
+			"limits.memory.balloon.target",
 			"security.agent.metrics",
 			"security.csm",
 			"security.protection.delete",
@@ -7154,6 +7266,15 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 						return fmt.Errorf("Failed updating memory limit: %w", err)
 					}
 				}
+			case "limits.memory.balloon":
+				// Give back all the memory reclaimed by the balloon when it stops being managed.
+				if !util.IsTrue(value) {
+					err = d.resetMemoryBalloon()
+					if err != nil {
+						return fmt.Errorf("Failed resetting memory balloon: %w", err)
+					}
+				}
+
 			case "security.csm":
 				// Defer rebuilding nvram until next start.
 				d.localConfig["volatile.apply_nvram"] = "true"
@@ -7328,6 +7449,10 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 		}
 	}
 
+	if !d.isSnapshot {
+		TrackRunning(d, isRunning)
+	}
+
 	if userRequested {
 		if d.isSnapshot {
 			d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceSnapshotUpdated.Event(d, nil))
@@ -9023,6 +9148,497 @@ func (d *qemu) migrateSendLive(ctx context.Context, pool storagePools.Pool, clus
 	return nil
 }
 
+// MoveStoragePool moves the root disk of the running VM, along with the custom block volumes attached
+// to it from the same storage pool, to another storage pool on the same server without stopping it.
+func (d *qemu) MoveStoragePool(poolName string) error {
+	d.logger.Debug("Live storage pool move starting", logger.Ctx{"pool": poolName})
+	defer d.logger.Debug("Live storage pool move stopped", logger.Ctx{"pool": poolName})
+
+	// Setup a new operation.
+	op := operationlock.Get(d.Project().Name, d.Name())
+	if op != nil && op.ActionMatch(operationlock.ActionMigrate) {
+		return errors.New("The instance is already being migrated")
+	}
+
+	op, err := operationlock.CreateWaitGet(d.Project().Name, d.Name(), d.op, operationlock.ActionMigrate, nil, false, true)
+	if err != nil {
+		return err
+	}
+
+	err = d.moveStoragePool(poolName)
+	op.Done(err)
+
+	return err
+}
+
+// moveStoragePool performs the live storage pool move.
+func (d *qemu) moveStoragePool(poolName string) error {
+	if !d.IsRunning() {
+		return errors.New("Live storage pool moves require the instance to be running")
+	}
+
+	if d.localConfig["volatile.vm.previous_pool"] != "" {
+		return errors.New("The instance must be restarted to complete its previous storage pool move first")
+	}
+
+	srcPool, err := d.getStoragePool()
+	if err != nil {
+		return err
+	}
+
+	tgtPool, err := storagePools.LoadByName(d.state, poolName)
+	if err != nil {
+		return fmt.Errorf("Failed loading storage pool %q: %w", poolName, err)
+	}
+
+	if srcPool.Name() == tgtPool.Name() {
+		return errors.New("Requested storage pool is the same as current pool")
+	}
+
+	// Dependent disks have to move along with the instance volume itself.
+	err = d.ForEachDependentDiskType(func(dev deviceConfig.DeviceNamed) error {
+		return fmt.Errorf("Live storage pool moves aren't supported with dependent disk %q", dev.Name)
+	})
+	if err != nil {
+		return err
+	}
+
+	rootDiskName, _, err := d.getRootDiskDevice()
+	if err != nil {
+		return err
+	}
+
+	// Derive the effective storage project name from the instance config's project.
+	storageProjectName, err := project.StorageVolumeProject(d.state.DB.Cluster, d.project.Name, db.StoragePoolVolumeTypeCustom)
+	if err != nil {
+		return err
+	}
+
+	// Find the custom block volumes which can move along with the root disk.
+	// Volumes coming from profiles or which can be shared with other instances are left in place.
+	customDisks := map[string]string{}
+	for _, dev := range d.expandedDevices.Sorted() {
+		if dev.Config["type"] != "disk" || dev.Config["path"] == "/" || dev.Config["pool"] != srcPool.Name() || dev.Config["source"] == "" {
+			continue
+		}
+
+		_, ok := d.localDevices[dev.Name]
+		if !ok {
+			continue
+		}
+
+		volName, snapName := internalInstance.SplitVolumeSource(dev.Config["source"])
+		if snapName != "" {
+			continue
+		}
+
+		dbVol, err := storagePools.VolumeDBGet(srcPool, storageProjectName, volName, storageDrivers.VolumeTypeCustom)
+		if err != nil {
+			return err
+		}
+
+		if dbVol.ContentType != db.StoragePoolVolumeContentTypeNameBlock || util.IsTrue(dbVol.Config["security.shared"]) {
+			continue
+		}
+
+		customDisks[dev.Name] = volName
+	}
+
+	monitor, err := d.qmpConnect()
+	if err != nil {
+		return err
+	}
+
+	// All disks are copied onto their new volumes before switching the guest over to any of them, so
+	// that a failed copy leaves every disk on its current volume.
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	rootDiskSize, err := storagePools.InstanceDiskBlockSize(srcPool, d, d.op)
+	if err != nil {
+		return fmt.Errorf("Failed getting root disk size: %w", err)
+	}
+
+	rootDiskPath, cleanup, err := d.prepareDiskMoveLive(monitor, rootDiskName, rootDiskSize, func() (string, revert.Hook, error) {
+		mountInfo, cleanup, err := tgtPool.CreateInstanceFromLiveMove(d, d.op)
+		if err != nil {
+			return "", nil, err
+		}
+
+		return mountInfo.DiskPath, cleanup, nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed moving root disk: %w", err)
+	}
+
+	reverter.Add(cleanup)
+
+	customDiskPaths := map[string]string{}
+	customDiskCleanups := map[string]revert.Hook{}
+	for _, devName := range slices.Sorted(maps.Keys(customDisks)) {
+		volName := customDisks[devName]
+
+		diskPath, err := srcPool.GetCustomVolumeDisk(storageProjectName, volName)
+		if err != nil {
+			return err
+		}
+
+		diskSize, err := storageDrivers.BlockDiskSizeBytes(diskPath)
+		if err != nil {
+			return fmt.Errorf("Failed getting size of disk %q: %w", devName, err)
+		}
+
+		customDiskPaths[devName], cleanup, err = d.prepareDiskMoveLive(monitor, devName, diskSize, func() (string, revert.Hook, error) {
+			reverter := revert.New()
+			defer reverter.Fail()
+
+			err := tgtPool.CreateCustomVolumeFromCopy(storageProjectName, storageProjectName, volName, "", nil, srcPool.Name(), volName, true, d.op)
+			if err != nil {
+				return "", nil, err
+			}
+
+			reverter.Add(func() { _ = tgtPool.DeleteCustomVolume(storageProjectName, volName, d.op) })
+
+			_, err = tgtPool.MountCustomVolume(storageProjectName, volName, d.op)
+			if err != nil {
+				return "", nil, err
+			}
+
+			reverter.Add(func() { _, _ = tgtPool.UnmountCustomVolume(storageProjectName, volName, d.op) })
+
+			diskPath, err := tgtPool.GetCustomVolumeDisk(storageProjectName, volName)
+			if err != nil {
+				return "", nil, err
+			}
+
+			cleanup := reverter.Clone().Fail
+			reverter.Success()
+
+			return diskPath, cleanup, nil
+		})
+		if err != nil {
+			return fmt.Errorf("Failed moving disk %q: %w", devName, err)
+		}
+
+		reverter.Add(cleanup)
+		customDiskCleanups[devName] = cleanup
+	}
+
+	// Switch the guest over to the new volumes, starting with the root disk.
+	err = d.finishDiskMoveLive(monitor, rootDiskName, rootDiskPath)
+	if err != nil {
+		return fmt.Errorf("Failed moving root disk: %w", err)
+	}
+
+	// Past this point, the guest uses the new volumes, so only the disks which didn't switch yet can be reverted.
+	reverter.Success()
+
+	// The guest now only uses the new volume, but QEMU keeps the firmware variables and other files
+	// of the previous volume open, so that volume is only deleted once the instance stops.
+	err = srcPool.DetachMovedInstance(d, d.op)
+	if err != nil {
+		return err
+	}
+
+	err = d.VolatileSet(map[string]string{"volatile.vm.previous_pool": srcPool.Name()})
+	if err != nil {
+		return err
+	}
+
+	err = d.setDiskPool(rootDiskName, tgtPool.Name())
+	if err != nil {
+		return err
+	}
+
+	d.storagePool = tgtPool
+
+	// Move the custom block volumes.
+	var moveErr error
+	for _, devName := range slices.Sorted(maps.Keys(customDisks)) {
+		volName := customDisks[devName]
+
+		if moveErr == nil {
+			moveErr = d.finishDiskMoveLive(monitor, devName, customDiskPaths[devName])
+			if moveErr != nil {
+				moveErr = fmt.Errorf("Failed moving disk %q: %w", devName, moveErr)
+			}
+		}
+
+		// Leave the disks which didn't switch over on the current pool.
+		if moveErr != nil {
+			customDiskCleanups[devName]()
+			continue
+		}
+
+		err = d.setDiskPool(devName, tgtPool.Name())
+		if err != nil {
+			return err
+		}
+
+		// Nothing uses the previous volume anymore.
+		_, err = srcPool.UnmountCustomVolume(storageProjectName, volName, d.op)
+		if err != nil {
+			d.logger.Warn("Failed unmounting previous volume of disk", logger.Ctx{"device": devName, "err": err})
+		}
+
+		err = srcPool.DeleteCustomVolume(storageProjectName, volName, d.op)
+		if err != nil {
+			d.logger.Warn("Failed deleting previous volume of disk", logger.Ctx{"device": devName, "err": err})
+		}
+	}
+
+	err = d.UpdateBackupFile()
+	if err != nil {
+		d.logger.Warn("Failed updating backup file", logger.Ctx{"err": err})
+	}
+
+	return moveErr
+}
+
+// prepareDiskMoveLive starts moving a disk of the running VM to a new volume without interrupting the guest.
+// The guest writes are redirected to a temporary overlay while copyVolume copies the disk onto its new
+// volume and returns the path to it. The returned hook removes the copy and merges the overlay back.
+func (d *qemu) prepareDiskMoveLive(monitor *qmp.Monitor, devName string, diskSize int64, copyVolume func() (string, revert.Hook, error)) (string, revert.Hook, error) {
+	nodeName := d.blockNodeName(linux.PathNameEncode(devName))
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	cleanup, err := d.createEphemeralSnapshot(nodeName, diskSize)
+	if err != nil {
+		return "", nil, fmt.Errorf("Failed creating temporary storage snapshot: %w", err)
+	}
+
+	reverter.Add(cleanup)
+
+	diskPath, cleanupCopy, err := copyVolume()
+	if err != nil {
+		return "", nil, err
+	}
+
+	reverter.Add(cleanupCopy)
+
+	cleanup = reverter.Clone().Fail
+	reverter.Success()
+
+	return diskPath, cleanup, nil
+}
+
+// finishDiskMoveLive mirrors the overlay of a disk prepared by prepareDiskMoveLive onto its new volume at
+// diskPath and switches the guest over to it. On failure, the disk is left as prepared.
+func (d *qemu) finishDiskMoveLive(monitor *qmp.Monitor, devName string, diskPath string) error {
+	nodeName := d.blockNodeName(linux.PathNameEncode(devName))
+	overlayName := ephemeralSnapshotName(nodeName)
+	moveName := liveMoveTargetName(nodeName)
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	err := d.addLiveMoveBlockDev(monitor, moveName, diskPath)
+	if err != nil {
+		return err
+	}
+
+	reverter.Add(func() {
+		_ = monitor.RemoveBlockDevice(moveName)
+		_ = monitor.RemoveFDFromFDSet(moveName)
+	})
+
+	// Bring the new volume in sync with the overlay and switch the guest over to it.
+	d.logger.Debug("Live storage move mirroring started", logger.Ctx{"device": devName})
+	err = monitor.BlockDevMirrorPivot(overlayName, moveName, "top")
+	if err != nil {
+		return fmt.Errorf("Failed mirroring onto the new volume: %w", err)
+	}
+
+	d.logger.Debug("Live storage move mirroring finished", logger.Ctx{"device": devName})
+	reverter.Success()
+
+	// Release the previous volume, all of its data now being on the new volume.
+	err = monitor.RemoveBlockDevice(overlayName)
+	if err != nil {
+		d.logger.Warn("Failed removing temporary snapshot disk device", logger.Ctx{"device": devName, "err": err})
+	}
+
+	err = monitor.RemoveBlockDevice(nodeName)
+	if err != nil {
+		d.logger.Warn("Failed removing previous disk device", logger.Ctx{"device": devName, "err": err})
+	}
+
+	_ = monitor.RemoveFDFromFDSet(nodeName)
+
+	// Give the new volume back the usual node name of the disk by pivoting onto a second node of the
+	// same volume. No data needs to be copied for this.
+	err = d.addLiveMoveBlockDev(monitor, nodeName, diskPath)
+	if err != nil {
+		d.logger.Warn("Failed restoring disk device name", logger.Ctx{"device": devName, "err": err})
+		return nil
+	}
+
+	err = monitor.BlockDevMirrorPivot(moveName, nodeName, "none")
+	if err != nil {
+		d.logger.Warn("Failed restoring disk device name", logger.Ctx{"device": devName, "err": err})
+		_ = monitor.RemoveBlockDevice(nodeName)
+		_ = monitor.RemoveFDFromFDSet(nodeName)
+		return nil
+	}
+
+	_ = monitor.RemoveBlockDevice(moveName)
+	_ = monitor.RemoveFDFromFDSet(moveName)
+
+	return nil
+}
+
+// addLiveMoveBlockDev adds the volume at diskPath as a block node which isn't attached to a guest device.
+func (d *qemu) addLiveMoveBlockDev(monitor *qmp.Monitor, nodeName string, diskPath string) error {
+	if strings.HasPrefix(diskPath, device.RBDFormatPrefix) {
+		return errors.New("Live storage pool moves aren't supported onto Ceph RBD")
+	}
+
+	diskPathInfo, err := os.Stat(diskPath)
+	if err != nil {
+		return fmt.Errorf("Invalid disk path %q: %w", diskPath, err)
+	}
+
+	isBlockDev := linux.IsBlockdev(diskPathInfo.Mode())
+
+	// Use the same I/O modes as for regular disks.
+	aioMode := "native"
+	directCache := true
+	if !isBlockDev {
+		fsType, err := linux.DetectFilesystem(diskPath)
+		if err != nil {
+			return fmt.Errorf("Failed detecting filesystem type of %q: %w", diskPath, err)
+		}
+
+		f, errDirect := os.OpenFile(diskPath, unix.O_DIRECT|unix.O_RDONLY, 0)
+		if errDirect == nil {
+			_ = f.Close()
+		}
+
+		if fsType == "zfs" || fsType == "btrfs" || errDirect != nil {
+			aioMode = "threads"
+			directCache = false
+		}
+	}
+
+	permissions := unix.O_RDWR
+	if directCache {
+		permissions |= unix.O_DIRECT
+	}
+
+	f, err := os.OpenFile(diskPath, permissions, 0)
+	if err != nil {
+		return fmt.Errorf("Failed opening file descriptor for %q: %w", diskPath, err)
+	}
+
+	defer logger.WarnOnError(f.Close, "Failed to close file")
+
+	info, err := monitor.SendFileWithFDSet(nodeName, f, false)
+	if err != nil {
+		return fmt.Errorf("Failed sending file descriptor of %q: %w", diskPath, err)
+	}
+
+	blockDev := map[string]any{
+		"aio": aioMode,
+		"cache": map[string]any{
+			"direct":   directCache,
+			"no-flush": false,
+		},
+		"discard":   "unmap",
+		"driver":    "file",
+		"filename":  fmt.Sprintf("/dev/fdset/%d", info.ID),
+		"locking":   "off",
+		"node-name": nodeName,
+		"read-only": false,
+	}
+
+	if isBlockDev {
+		blockDev["driver"] = "host_device"
+	}
+
+	err = monitor.AddBlockDevice(blockDev, nil, false)
+	if err != nil {
+		_ = monitor.RemoveFDFromFDSet(nodeName)
+		return fmt.Errorf("Failed adding block device for %q: %w", diskPath, err)
+	}
+
+	return nil
+}
+
+// setDiskPool records the new storage pool of a disk device, moving inherited devices to the local devices.
+func (d *qemu) setDiskPool(devName string, poolName string) error {
+	_, ok := d.localDevices[devName]
+	if !ok {
+		d.localDevices[devName] = maps.Clone(d.expandedDevices[devName])
+	}
+
+	d.localDevices[devName]["pool"] = poolName
+
+	d.expandedDevices[devName] = maps.Clone(d.expandedDevices[devName])
+	d.expandedDevices[devName]["pool"] = poolName
+
+	return d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		devices, err := dbCluster.APIToDevices(d.localDevices.CloneNative())
+		if err != nil {
+			return err
+		}
+
+		return dbCluster.UpdateInstanceDevices(ctx, tx.Tx(), int64(d.id), devices)
+	})
+}
+
+// completeStorageMove deletes the volume left on the previous storage pool by a live storage pool move
+// once the VM has stopped using it, carrying over the firmware and TPM state which got written to it.
+func (d *qemu) completeStorageMove() error {
+	poolName := d.localConfig["volatile.vm.previous_pool"]
+	if poolName == "" {
+		return nil
+	}
+
+	pool, err := storagePools.LoadByName(d.state, poolName)
+	if err != nil {
+		return fmt.Errorf("Failed loading storage pool %q: %w", poolName, err)
+	}
+
+	srcPath := storageDrivers.GetVolumeMountPath(poolName, storageDrivers.VolumeTypeVM, project.Instance(d.project.Name, d.name))
+	if util.PathExists(srcPath) {
+		entries, err := os.ReadDir(srcPath)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			srcEntry := filepath.Join(srcPath, entry.Name())
+			dstEntry := filepath.Join(d.Path(), entry.Name())
+
+			if entry.IsDir() && strings.HasPrefix(entry.Name(), "tpm.") {
+				err = internalUtil.DirCopy(srcEntry, dstEntry)
+				if err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			// The firmware variables are stored in the target of the NVRAM symlink.
+			if entry.Type().IsRegular() && (entry.Name() == filepath.Base(d.nvramPath()) || strings.HasSuffix(entry.Name(), ".fd")) {
+				err = internalUtil.FileCopy(srcEntry, dstEntry)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	err = pool.DeleteMovedInstance(d, nil)
+	if err != nil {
+		return err
+	}
+
+	return d.VolatileSet(map[string]string{"volatile.vm.previous_pool": ""})
+}
+
 // MigrateReceive receives an instance being migrated from a source.
 func (d *qemu) MigrateReceive(args instance.MigrateReceiveArgs) error {
 	d.logger.Debug("Migration receive starting")
@@ -9724,6 +10340,19 @@ func (d *qemu) Console(protocol string) (*os.File, chan error, error) {
 		}
 
 		path = d.spicePath()
+	case instance.ConsoleTypeVNC:
+		info := DriverStatuses()[instancetype.VM].Info
+		_, vncSupported := info.Features["vnc"]
+		if !vncSupported {
+			return nil, nil, fmt.Errorf("VNC is not supported by the host")
+		}
+
+		// The VNC server is only set up at startup.
+		if !util.PathExists(d.vncPath()) {
+			return nil, nil, errors.New("VNC isn't enabled for this instance (requires security.vnc and a restart)")
+		}
+
+		path = d.vncPath()
 	default:
 		return nil, nil, fmt.Errorf("Unknown protocol %q", protocol)
 	}
@@ -9779,7 +10408,7 @@ func (d *qemu) Console(protocol string) (*os.File, chan error, error) {
 
 	// Only emit a lifecycle event for the text console here. SPICE clients open one socket per channel
 	// (display, cursor, inputs, ...) and would otherwise produce a flurry of instance-console events
-	// for a single user session; the VGA emit is handled once per session by the console request handler.
+	// for a single user session; the VGA and VNC emit is handled once per session by the console request handler.
 	if protocol == instance.ConsoleTypeConsole {
 		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceConsole.Event(d, logger.Ctx{"type": protocol}))
 	}
@@ -9787,6 +10416,16 @@ func (d *qemu) Console(protocol string) (*os.File, chan error, error) {
 	return file, chDisconnect, nil
 }
 
+// SetVNCPassword sets the password that VNC clients must use to connect to the VNC console.
+func (d *qemu) SetVNCPassword(password string) error {
+	monitor, err := d.qmpConnect()
+	if err != nil {
+		return err
+	}
+
+	return monitor.SetPassword("vnc", password)
+}
+
 // Exec a command inside the instance.
 func (d *qemu) Exec(req api.InstanceExecPost, stdin *os.File, stdout *os.File, stderr *os.File) (instance.Cmd, error) {
 	reverter := revert.New()
@@ -10079,6 +10718,17 @@ func (d *qemu) renderState(statusCode api.StatusCode) (*api.InstanceState, error
 		status.Memory.Usage = int64(memoryMetrics.MemTotalBytes - memoryMetrics.MemAvailableBytes)
 	}
 
+	// Add the memory reclaimed by the balloon.
+	monitor, err := d.qmpConnect()
+	if err == nil {
+		balloonSize, err := d.memoryBalloonSize(monitor)
+		if err != nil {
+			d.logger.Warn("Error getting memory balloon size", logger.Ctx{"err": err})
+		} else {
+			status.Memory.Balloon = balloonSize
+		}
+	}
+
 	// Populate the disk information.
 	diskState, err := d.diskState()
 	if err != nil && !errors.Is(err, storageDrivers.ErrNotSupported) {
@@ -10131,6 +10781,8 @@ func (d *qemu) renderState(statusCode api.StatusCode) (*api.InstanceState, error
 		return status, err
 	}
 
+	status.Health = d.healthState(status.StartedAt)
+
 	return status, nil
 }
 
@@ -10139,6 +10791,11 @@ func (d *qemu) RenderState(hostInterfaces []net.Interface) (*api.InstanceState,
 	return d.renderState(d.statusCode())
 }
 
+// HealthCheck runs the health check of the instance and returns its updated health.
+func (d *qemu) HealthCheck() (*api.InstanceStateHealth, error) {
+	return d.healthCheck(d)
+}
+
 // diskState gets disk usage info.
 func (d *qemu) diskState() (map[string]api.InstanceStateDisk, error) {
 	pool, err := d.getStoragePool()
@@ -10904,6 +11561,14 @@ func (d *qemu) checkFeatures(hostArch int, qemuPath string) (map[string]any, err
 		features["spice"] = struct{}{}
 	}
 
+	// Check if VNC is compiled into QEMU.
+	err = monitor.QueryVNC()
+	if err != nil {
+		logger.Debug("Failed querying VNC during VM feature check", logger.Ctx{"err": err})
+	} else {
+		features["vnc"] = struct{}{}
+	}
+
 	// Check if virtio-9p-pci is compiled into QEMU.
 	err = monitor.Query9pDevice()
 	if err != nil {
@@ -10991,21 +11656,37 @@ func (d *qemu) Metrics(hostInterfaces []net.Interface) (*metrics.MetricSet, erro
 		return nil, ErrInstanceIsStopped
 	}
 
+	var metricSet *metrics.MetricSet
+	var err error
+
 	if d.agentMetricsEnabled() {
-		agentMetrics, err := d.getAgentMetrics()
+		metricSet, err = d.getAgentMetrics()
 		if err != nil {
 			if !errors.Is(err, errQemuAgentOffline) {
 				d.logger.Warn("Could not get VM metrics from agent", logger.Ctx{"err": err})
 			}
 
 			// Fallback data if agent is not reachable.
-			return d.getQemuMetrics()
+			metricSet, err = d.getQemuMetrics()
 		}
+	} else {
+		metricSet, err = d.getQemuMetrics()
+	}
 
-		return agentMetrics, nil
+	if err != nil {
+		return nil, err
 	}
 
-	return d.getQemuMetrics()
+	// Add the memory reclaimed by the balloon.
+	monitor, err := d.qmpConnect()
+	if err == nil {
+		balloonSize, err := d.memoryBalloonSize(monitor)
+		if err == nil {
+			metricSet.AddSamples(metrics.MemoryBalloonBytes, metrics.Sample{Value: float64(balloonSize)})
+		}
+	}
+
+	return metricSet, nil
 }
 
 func (d *qemu) getAgentMetrics() (*metrics.MetricSet, error) {
@@ -0,0 +1,127 @@
+package drivers
+
+import (
+	"time"
+
+	internalInstance "github.com/lxc/incus/v7/internal/instance"
+	"github.com/lxc/incus/v7/internal/server/instance/drivers/qemudefault"
+	"github.com/lxc/incus/v7/internal/server/instance/drivers/qmp"
+	"github.com/lxc/incus/v7/shared/logger"
+	"github.com/lxc/incus/v7/shared/units"
+	"github.com/lxc/incus/v7/shared/util"
+)
+
+// qemuBalloonStatsInterval is how often (in seconds) the guest reports its memory statistics to the balloon device.
+const qemuBalloonStatsInterval = 5
+
+// memoryLimit returns the memory limit of the VM in bytes.
+func (d *qemu) memoryLimit() (int64, error) {
+	memoryLimit := d.expandedConfig["limits.memory"]
+	if memoryLimit == "" {
+		memoryLimit = qemudefault.MemSize // Default if no memory limit specified.
+	}
+
+	return ParseMemoryStr(memoryLimit)
+}
+
+// memoryBalloonSize returns the amount of memory in bytes currently reclaimed from the VM by its balloon.
+func (d *qemu) memoryBalloonSize(monitor *qmp.Monitor) (int64, error) {
+	limit, err := d.memoryLimit()
+	if err != nil {
+		return -1, err
+	}
+
+	actual, err := monitor.GetMemoryBalloonSizeBytes()
+	if err != nil {
+		return -1, err
+	}
+
+	return max(limit-actual, 0), nil
+}
+
+// resetMemoryBalloon gives back all the memory reclaimed from the VM by its balloon.
+func (d *qemu) resetMemoryBalloon() error {
+	monitor, err := d.qmpConnect()
+	if err != nil {
+		return err
+	}
+
+	limit, err := d.memoryLimit()
+	if err != nil {
+		return err
+	}
+
+	return monitor.SetMemoryBalloonSizeBytes(limit)
+}
+
+// UpdateMemoryBalloon resizes the memory balloon of the VM according to its "limits.memory.balloon" config.
+// Memory is only reclaimed from the VM if reclaim is true, for when the host is under memory pressure.
+func (d *qemu) UpdateMemoryBalloon(reclaim bool) error {
+	if !util.IsTrue(d.expandedConfig["limits.memory.balloon"]) || !d.IsRunning() {
+		return nil
+	}
+
+	monitor, err := d.qmpConnect()
+	if err != nil {
+		return err
+	}
+
+	// The guest only reports its memory statistics once asked to. Ask again when the last report is stale, as
+	// the polling interval isn't kept when the VM is migrated.
+	stats, err := monitor.GetBalloonStats("qemu_balloon")
+	if err != nil {
+		return err
+	}
+
+	if time.Since(time.Unix(stats.LastUpdate, 0)) > 3*qemuBalloonStatsInterval*time.Second {
+		return monitor.SetBalloonStatsPollingInterval("qemu_balloon", qemuBalloonStatsInterval)
+	}
+
+	// Older guests don't report the available memory, fallback to the free memory.
+	available, ok := stats.Stats["stat-available-memory"]
+	if !ok || available < 0 {
+		available, ok = stats.Stats["stat-free-memory"]
+		if !ok || available < 0 {
+			return nil
+		}
+	}
+
+	limit, err := d.memoryLimit()
+	if err != nil {
+		return err
+	}
+
+	actual, err := monitor.GetMemoryBalloonSizeBytes()
+	if err != nil {
+		return err
+	}
+
+	minimumStr := d.expandedConfig["limits.memory.balloon.min"]
+	if minimumStr == "" {
+		minimumStr = "50%"
+	}
+
+	minimum, err := internalInstance.MemoryBalloonSize(minimumStr, limit)
+	if err != nil {
+		return err
+	}
+
+	targetStr := d.expandedConfig["limits.memory.balloon.target"]
+	if targetStr == "" {
+		targetStr = "20%"
+	}
+
+	target, err := internalInstance.MemoryBalloonSize(targetStr, limit)
+	if err != nil {
+		return err
+	}
+
+	size := internalInstance.MemoryBalloonTarget(limit, actual, available, minimum, target, reclaim)
+	if size < 0 {
+		return nil
+	}
+
+	d.logger.Debug("Resizing memory balloon", logger.Ctx{"current": units.GetByteSizeStringIEC(actual, 2), "new": units.GetByteSizeStringIEC(size, 2), "available": units.GetByteSizeStringIEC(available, 2)})
+
+	return monitor.SetMemoryBalloonSizeBytes(size)
+}
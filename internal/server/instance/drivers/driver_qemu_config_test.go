@@ -273,10 +273,10 @@ func TestQemuConfigTemplates(t *testing.T) {
 
 	t.Run("qemu_balloon", func(t *testing.T) {
 		testCases := []struct {
-			opts     qemuDevOpts
+			opts     qemuBalloonOpts
 			expected string
 		}{{
-			qemuDevOpts{"pcie", "qemu_pcie0", "00.0", true},
+			qemuBalloonOpts{dev: qemuDevOpts{"pcie", "qemu_pcie0", "00.0", true}},
 			`# Balloon driver
 			[device "qemu_balloon"]
 			addr = "00.0"
@@ -285,11 +285,21 @@ func TestQemuConfigTemplates(t *testing.T) {
 			multifunction = "on"
 			`,
 		}, {
-			qemuDevOpts{"ccw", "qemu_pcie0", "00.0", false},
+			qemuBalloonOpts{dev: qemuDevOpts{"ccw", "qemu_pcie0", "00.0", false}},
 			`# Balloon driver
 			[device "qemu_balloon"]
 			driver = "virtio-balloon-ccw"
 			`,
+		}, {
+			qemuBalloonOpts{dev: qemuDevOpts{"pcie", "qemu_pcie0", "00.0", true}, freePageReporting: true},
+			`# Balloon driver
+			[device "qemu_balloon"]
+			addr = "00.0"
+			bus = "qemu_pcie0"
+			driver = "virtio-balloon-pci"
+			free-page-reporting = "on"
+			multifunction = "on"
+			`,
 		}}
 		for _, tc := range testCases {
 			runTest(tc.expected, qemuBalloon(&tc.opts))
@@ -785,6 +795,22 @@ func TestQemuConfigTemplates(t *testing.T) {
 		}
 	})
 
+	t.Run("qemu_vnc", func(t *testing.T) {
+		testCases := []struct {
+			opts     qemuVNCOpts
+			expected string
+		}{{
+			qemuVNCOpts{"/proc/self/fd/3/qemu.vnc"},
+			`# VNC
+			[vnc "qemu_vnc"]
+			password = "on"
+			vnc = "unix:/proc/self/fd/3/qemu.vnc"`,
+		}}
+		for _, tc := range testCases {
+			runTest(tc.expected, qemuVNC(&tc.opts))
+		}
+	})
+
 	t.Run("qemu_drive_firmware", func(t *testing.T) {
 		testCases := []struct {
 			opts     qemuDriveFirmwareOpts
@@ -1184,6 +1210,25 @@ func TestQemuConfigTemplates(t *testing.T) {
 		}
 	})
 
+	t.Run("qemu_fw_cfg", func(t *testing.T) {
+		testCases := []struct {
+			opts     qemuFwCfgOpts
+			expected string
+		}{{
+			qemuFwCfgOpts{
+				name: "opt/com.coreos/config",
+				path: "/proc/self/fd/3",
+			},
+			`# Firmware configuration
+			[fw_cfg]
+			file = "/proc/self/fd/3"
+			name = "opt/com.coreos/config"`,
+		}}
+		for _, tc := range testCases {
+			runTest(tc.expected, qemuFwCfg(&tc.opts))
+		}
+	})
+
 	t.Run("qemu_raw_cfg_override", func(t *testing.T) {
 		conf := []cfg.Section{{
 			Name: "global",
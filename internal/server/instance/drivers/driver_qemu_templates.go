@@ -349,17 +349,27 @@ func qemuSCSI(opts *qemuDevOpts, numQueues int) []cfg.Section {
 	}}
 }
 
-func qemuBalloon(opts *qemuDevOpts) []cfg.Section {
+type qemuBalloonOpts struct {
+	dev               qemuDevOpts
+	freePageReporting bool
+}
+
+func qemuBalloon(opts *qemuBalloonOpts) []cfg.Section {
 	entriesOpts := qemuDevEntriesOpts{
-		dev:     *opts,
+		dev:     opts.dev,
 		pciName: "virtio-balloon-pci",
 		ccwName: "virtio-balloon-ccw",
 	}
 
+	entries := qemuDeviceEntries(&entriesOpts)
+	if opts.freePageReporting {
+		entries["free-page-reporting"] = "on"
+	}
+
 	return []cfg.Section{{
 		Name:    `device "qemu_balloon"`,
 		Comment: "Balloon driver",
-		Entries: qemuDeviceEntries(&entriesOpts),
+		Entries: entries,
 	}}
 }
 
@@ -698,6 +708,22 @@ func qemuControlSocket(opts *qemuControlSocketOpts) []cfg.Section {
 	}}
 }
 
+type qemuVNCOpts struct {
+	path string
+}
+
+func qemuVNC(opts *qemuVNCOpts) []cfg.Section {
+	// Clients can't authenticate until a password is set for their console session.
+	return []cfg.Section{{
+		Name:    `vnc "qemu_vnc"`,
+		Comment: "VNC",
+		Entries: map[string]string{
+			"vnc":      "unix:" + opts.path,
+			"password": "on",
+		},
+	}}
+}
+
 func qemuConsole() []cfg.Section {
 	return []cfg.Section{{
 		Name:    `chardev "console"`,
@@ -1050,3 +1076,19 @@ func qemuVmgen(opts *qemuVmgenIDOpts) []cfg.Section {
 		},
 	}}
 }
+
+type qemuFwCfgOpts struct {
+	name string
+	path string
+}
+
+func qemuFwCfg(opts *qemuFwCfgOpts) []cfg.Section {
+	return []cfg.Section{{
+		Name:    "fw_cfg",
+		Comment: "Firmware configuration",
+		Entries: map[string]string{
+			"name": opts.name,
+			"file": opts.path,
+		},
+	}}
+}
@@ -106,6 +106,12 @@ type MemDev struct {
 	HostNodes []int  `json:"host-nodes"`
 }
 
+// BalloonStats contains the memory statistics reported by the guest balloon driver.
+type BalloonStats struct {
+	Stats      map[string]int64 `json:"stats"`
+	LastUpdate int64            `json:"last-update"`
+}
+
 // MigrationStatus contains information about the ongoing migration.
 type MigrationStatus struct {
 	Status string `json:"status"`
@@ -735,6 +741,37 @@ func (m *Monitor) SetMemoryBalloonSizeBytes(sizeBytes int64) error {
 	return m.Run("balloon", args, nil)
 }
 
+// SetBalloonStatsPollingInterval sets how often (in seconds) the guest balloon driver reports its memory statistics.
+func (m *Monitor) SetBalloonStatsPollingInterval(id string, interval int) error {
+	args := map[string]any{
+		"path":     fmt.Sprintf("/machine/peripheral/%s", id),
+		"property": "guest-stats-polling-interval",
+		"value":    interval,
+	}
+
+	return m.Run("qom-set", args, nil)
+}
+
+// GetBalloonStats returns the last memory statistics reported by the guest balloon driver.
+func (m *Monitor) GetBalloonStats(id string) (*BalloonStats, error) {
+	args := map[string]string{
+		"path":     fmt.Sprintf("/machine/peripheral/%s", id),
+		"property": "guest-stats",
+	}
+
+	// Prepare the response.
+	var resp struct {
+		Return BalloonStats `json:"return"`
+	}
+
+	err := m.Run("qom-get", args, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.Return, nil
+}
+
 // GetMemdev retrieves memory devices by executing the query-memdev QMP command.
 func (m *Monitor) GetMemdev() ([]MemDev, error) {
 	// Prepare the response.
@@ -1640,6 +1677,53 @@ func (m *Monitor) BlockDevMirror(deviceNodeName string, targetNodeName string) e
 	return nil
 }
 
+// BlockDevMirrorPivot mirrors the device to the target device and, once both are in sync,
+// switches the users of the device over to the target device.
+// The sync mode controls what gets copied to the target ("top", "full" or "none").
+func (m *Monitor) BlockDevMirrorPivot(deviceNodeName string, targetNodeName string, sync string) error {
+	var args struct {
+		Device      string `json:"device"`
+		Target      string `json:"target"`
+		Sync        string `json:"sync"`
+		JobID       string `json:"job-id"`
+		CopyMode    string `json:"copy-mode"`
+		AutoDismiss bool   `json:"auto-dismiss"`
+	}
+
+	args.Device = deviceNodeName
+	args.Target = targetNodeName
+	args.Sync = sync
+	args.JobID = deviceNodeName
+
+	// Write guest data synchronously to both devices so that they converge.
+	args.CopyMode = "write-blocking"
+
+	// Keep failed jobs around so their actual error can be retrieved,
+	// blockJobWait takes care of dismissing them.
+	args.AutoDismiss = false
+
+	err := m.Run("blockdev-mirror", args, nil)
+	if err != nil {
+		return err
+	}
+
+	concluded, err := m.blockJobWait(args.JobID, true, false)
+	if err != nil {
+		return err
+	}
+
+	if concluded {
+		return errors.New("Block job concluded before reaching the ready state")
+	}
+
+	err = m.BlockJobComplete(args.JobID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // BlockJobCancel cancels an ongoing block job.
 func (m *Monitor) BlockJobCancel(deviceNodeName string) error {
 	var args struct {
@@ -2013,6 +2097,21 @@ func (m *Monitor) QuerySpice() error {
 	return m.Run("query-spice", nil, nil)
 }
 
+// QueryVNC checks whether VNC support is available in QEMU.
+func (m *Monitor) QueryVNC() error {
+	return m.Run("query-vnc", nil, nil)
+}
+
+// SetPassword sets the password of a remote display protocol (vnc or spice).
+func (m *Monitor) SetPassword(protocol string, password string) error {
+	args := map[string]string{
+		"protocol": protocol,
+		"password": password,
+	}
+
+	return m.Run("set_password", args, nil)
+}
+
 // Query9pDevice checks whether virtio-9p-pci support is available in QEMU.
 func (m *Monitor) Query9pDevice() error {
 	return m.Run("device-list-properties", map[string]string{"typename": "virtio-9p-pci"}, nil)
@@ -380,6 +380,11 @@ func ephemeralSnapshotName(diskName string) string {
 	return fmt.Sprintf("%s_snap", diskName)
 }
 
+// liveMoveTargetName returns a name for the new volume of a disk during a live storage pool move.
+func liveMoveTargetName(diskName string) string {
+	return fmt.Sprintf("%s_move", diskName)
+}
+
 // migrationNBDTarget returns a name for a disk exposed via the NBD server.
 func migrationNBDTarget(diskName string) string {
 	return fmt.Sprintf("%s_nbd", diskName)
@@ -40,6 +40,7 @@ const HookStop = "onstop"
 const (
 	ConsoleTypeConsole = "console"
 	ConsoleTypeVGA     = "vga"
+	ConsoleTypeVNC     = "vnc"
 )
 
 // TemplateTrigger trigger name.
@@ -144,6 +145,7 @@ type Instance interface {
 	RenderWithUsage() (any, any, error)
 	RenderFull(hostInterfaces []net.Interface) (*api.InstanceFull, any, error)
 	RenderState(hostInterfaces []net.Interface) (*api.InstanceState, error)
+	HealthCheck() (*api.InstanceStateHealth, error)
 	IsRunning() bool
 	IsFrozen() bool
 	IsEphemeral() bool
@@ -230,6 +232,9 @@ type VM interface {
 	GetNVRAM() (*uefi.Store, error)
 	SetNVRAM(store *uefi.Store) error
 	ResetNVRAM() error
+	MoveStoragePool(poolName string) error
+	UpdateMemoryBalloon(reclaim bool) error
+	SetVNCPassword(password string) error
 }
 
 // CriuMigrationArgs arguments for CRIU migration.
@@ -148,9 +148,45 @@ func ValidConfig(sysOS *sys.OS, config map[string]string, expanded bool, instanc
 		return errors.New("nvidia.runtime is incompatible with privileged containers")
 	}
 
+	if expanded {
+		switch config["healthcheck.type"] {
+		case "exec":
+			if config["healthcheck.command"] == "" {
+				return errors.New("healthcheck.command is required when healthcheck.type is exec")
+			}
+
+		case "tcp", "http":
+			if config["healthcheck.port"] == "" {
+				return fmt.Errorf("healthcheck.port is required when healthcheck.type is %s", config["healthcheck.type"])
+			}
+		}
+
+		if util.IsTrue(config["limits.memory.balloon"]) && util.IsTrue(config["limits.memory.hugepages"]) {
+			return errors.New("limits.memory.balloon can't be used with limits.memory.hugepages")
+		}
+	}
+
 	return nil
 }
 
+// IgnitionConfig returns the Ignition config of the instance, combining the configs set in its profiles
+// and in its own config.
+func IgnitionConfig(inst Instance) (string, error) {
+	var configs []string
+
+	for _, profile := range inst.Profiles() {
+		if profile.Config["ignition.config"] != "" {
+			configs = append(configs, profile.Config["ignition.config"])
+		}
+	}
+
+	if inst.LocalConfig()["ignition.config"] != "" {
+		configs = append(configs, inst.LocalConfig()["ignition.config"])
+	}
+
+	return instance.IgnitionMerge(configs)
+}
+
 func validConfigKey(sysOS *sys.OS, key string, value string, instanceType instancetype.Type) error {
 	f, err := instance.ConfigKeyChecker(key, instanceType.ToAPI())
 	if err != nil {
@@ -262,6 +298,36 @@ func AllowedUnprivilegedOnlyMap(rawIdmap string) error {
 	return nil
 }
 
+// ValidDepends checks that the "boot.depends" config of the instance doesn't create a dependency cycle with the
+// other instances of its project.
+func ValidDepends(s *state.State, projectName string, instanceName string, config map[string]string) error {
+	if len(instance.DependsList(config)) == 0 {
+		return nil
+	}
+
+	depends := map[string][]string{}
+
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.InstanceList(ctx, func(inst db.InstanceArgs, p api.Project) error {
+			depends[inst.Name] = instance.DependsList(db.ExpandInstanceConfig(inst.Config, inst.Profiles))
+
+			return nil
+		}, cluster.InstanceFilter{Project: &projectName})
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading instance dependencies: %w", err)
+	}
+
+	depends[instanceName] = instance.DependsList(config)
+
+	cycle := instance.DependsCycle(depends, instanceName)
+	if cycle != nil {
+		return fmt.Errorf("Instance dependency cycle: %s", strings.Join(cycle, " -> "))
+	}
+
+	return nil
+}
+
 // LoadByID loads an instance by ID.
 func LoadByID(s *state.State, id int) (Instance, error) {
 	var project string
@@ -19,6 +19,7 @@ type Class struct {
 type ClassHTB struct {
 	Class
 	Rate string
+	Ceil string
 }
 
 // Add adds class to a node.
@@ -59,6 +60,15 @@ func (class *ClassHTB) Add() error {
 		htbClassAttrs.Rate = uint64(rate)
 	}
 
+	if class.Ceil != "" {
+		ceil, err := units.ParseBitSizeString(class.Ceil)
+		if err != nil {
+			return fmt.Errorf("Invalid ceil %q: %w", class.Ceil, err)
+		}
+
+		htbClassAttrs.Ceil = uint64(ceil)
+	}
+
 	err = netlink.ClassAdd(netlink.NewHtbClass(classAttrs, htbClassAttrs))
 	if err != nil {
 		return fmt.Errorf("Failed to add htb class: %w", err)
@@ -36,6 +36,32 @@ func (a *ActionPolice) toNetlink() (netlink.Action, error) {
 	return action, nil
 }
 
+// ActionMirred represents an action of 'mirred' type mirroring packets to another device.
+// Classification continues after the action so that the other filters still apply, unless the packets are
+// redirected to the other device.
+type ActionMirred struct {
+	Dev      string
+	Redirect bool
+}
+
+func (a *ActionMirred) toNetlink() (netlink.Action, error) {
+	link, err := linkByName(a.Dev)
+	if err != nil {
+		return nil, err
+	}
+
+	action := netlink.NewMirredAction(link.Attrs().Index)
+	action.MirredAction = netlink.TCA_EGRESS_MIRROR
+	action.Action = netlink.TC_ACT_UNSPEC
+
+	if a.Redirect {
+		action.MirredAction = netlink.TCA_EGRESS_REDIR
+		action.Action = netlink.TC_ACT_STOLEN
+	}
+
+	return action, nil
+}
+
 // Filter represents filter object.
 type Filter struct {
 	Dev      string
@@ -45,11 +71,22 @@ type Filter struct {
 }
 
 // U32Filter represents universal 32bit traffic control filter.
+// The packets match if they match all the keys, or the value and mask at the start of their network header if
+// there's no key.
 type U32Filter struct {
 	Filter
-	Value   uint32
-	Mask    uint32
-	Actions []Action
+	Priority uint16
+	Value    uint32
+	Mask     uint32
+	Keys     []U32Key
+	Actions  []Action
+}
+
+// U32Key represents a value to match at an offset from the network header of the packets.
+type U32Key struct {
+	Value  uint32
+	Mask   uint32
+	Offset int32
 }
 
 func parseProtocol(proto string) (uint16, error) {
@@ -73,21 +110,31 @@ func (u32 *U32Filter) Add() error {
 		return err
 	}
 
+	keys := []netlink.TcU32Key{
+		{
+			Mask: u32.Mask,
+			Val:  u32.Value,
+		},
+	}
+
+	if len(u32.Keys) > 0 {
+		keys = make([]netlink.TcU32Key, 0, len(u32.Keys))
+		for _, key := range u32.Keys {
+			keys = append(keys, netlink.TcU32Key{Mask: key.Mask, Val: key.Value & key.Mask, Off: key.Offset})
+		}
+	}
+
 	filter := &netlink.U32{
 		FilterAttrs: netlink.FilterAttrs{
 			LinkIndex: link.Attrs().Index,
 			Protocol:  proto,
+			Priority:  u32.Priority,
 			Chain:     nil,
 		},
 		Sel: &netlink.TcU32Sel{
 			Flags: netlink.TC_U32_TERMINAL,
-			Nkeys: 1,
-			Keys: []netlink.TcU32Key{
-				{
-					Mask: u32.Mask,
-					Val:  u32.Value,
-				},
-			},
+			Nkeys: uint8(len(keys)),
+			Keys:  keys,
 		},
 	}
 
@@ -125,3 +172,139 @@ func (u32 *U32Filter) Add() error {
 
 	return nil
 }
+
+// Delete removes the universal 32bit traffic control filters of a node with the filter priority.
+func (u32 *U32Filter) Delete() error {
+	link, err := linkByName(u32.Dev)
+	if err != nil {
+		return err
+	}
+
+	proto, err := parseProtocol(u32.Protocol)
+	if err != nil {
+		return err
+	}
+
+	filter := &netlink.U32{
+		FilterAttrs: netlink.FilterAttrs{
+			LinkIndex: link.Attrs().Index,
+			Protocol:  proto,
+			Priority:  u32.Priority,
+		},
+	}
+
+	if u32.Parent != "" {
+		parent, err := parseHandle(u32.Parent)
+		if err != nil {
+			return err
+		}
+
+		filter.Parent = parent
+	}
+
+	err = netlink.FilterDel(filter)
+	if err != nil {
+		return fmt.Errorf("Failed to delete filter %v: %w", filter, err)
+	}
+
+	return nil
+}
+
+// MatchallFilter represents a traffic control filter matching all packets.
+type MatchallFilter struct {
+	Filter
+	Priority uint16
+	Actions  []Action
+}
+
+// Add adds a matchall traffic control filter to a node.
+func (m *MatchallFilter) Add() error {
+	link, err := linkByName(m.Dev)
+	if err != nil {
+		return err
+	}
+
+	proto, err := parseProtocol(m.Protocol)
+	if err != nil {
+		return err
+	}
+
+	filter := &netlink.MatchAll{
+		FilterAttrs: netlink.FilterAttrs{
+			LinkIndex: link.Attrs().Index,
+			Protocol:  proto,
+			Priority:  m.Priority,
+		},
+	}
+
+	for _, action := range m.Actions {
+		netlinkAction, err := action.toNetlink()
+		if err != nil {
+			return err
+		}
+
+		filter.Actions = append(filter.Actions, netlinkAction)
+	}
+
+	if m.Parent != "" {
+		parent, err := parseHandle(m.Parent)
+		if err != nil {
+			return err
+		}
+
+		filter.Parent = parent
+	}
+
+	if m.Flowid != "" {
+		flowid, err := parseHandle(m.Flowid)
+		if err != nil {
+			return err
+		}
+
+		filter.ClassId = flowid
+	}
+
+	err = netlink.FilterAdd(filter)
+	if err != nil {
+		return fmt.Errorf("Failed to add filter %v: %w", filter, err)
+	}
+
+	return nil
+}
+
+// Delete removes the matchall traffic control filters of a node with the filter priority.
+func (m *MatchallFilter) Delete() error {
+	link, err := linkByName(m.Dev)
+	if err != nil {
+		return err
+	}
+
+	proto, err := parseProtocol(m.Protocol)
+	if err != nil {
+		return err
+	}
+
+	filter := &netlink.MatchAll{
+		FilterAttrs: netlink.FilterAttrs{
+			LinkIndex: link.Attrs().Index,
+			Protocol:  proto,
+			Priority:  m.Priority,
+		},
+	}
+
+	if m.Parent != "" {
+		parent, err := parseHandle(m.Parent)
+		if err != nil {
+			return err
+		}
+
+		filter.Parent = parent
+	}
+
+	err = netlink.FilterDel(filter)
+	if err != nil {
+		return fmt.Errorf("Failed to delete filter %v: %w", filter, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,22 @@
+package ip
+
+import (
+	"github.com/vishvananda/netlink"
+)
+
+// Ifb represents arguments for link device of type ifb.
+type Ifb struct {
+	Link
+}
+
+// Add adds new virtual link.
+func (ifb *Ifb) Add() error {
+	attrs, err := ifb.netlinkAttrs()
+	if err != nil {
+		return err
+	}
+
+	return ifb.addLink(&netlink.Ifb{
+		LinkAttrs: attrs,
+	})
+}
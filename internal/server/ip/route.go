@@ -348,3 +348,22 @@ func (r *Route) List() ([]Route, error) {
 
 	return routes, nil
 }
+
+// GetRouteDevice returns the name of the device used to reach the address.
+func GetRouteDevice(address net.IP) (string, error) {
+	netlinkRoutes, err := netlink.RouteGet(address)
+	if err != nil {
+		return "", fmt.Errorf("Failed to get route to %s: %w", address, err)
+	}
+
+	if len(netlinkRoutes) == 0 || netlinkRoutes[0].LinkIndex == 0 {
+		return "", fmt.Errorf("No route to %s", address)
+	}
+
+	link, err := netlink.LinkByIndex(netlinkRoutes[0].LinkIndex)
+	if err != nil {
+		return "", err
+	}
+
+	return link.Attrs().Name, nil
+}
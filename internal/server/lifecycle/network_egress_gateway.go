@@ -0,0 +1,28 @@
+package lifecycle
+
+import (
+	"github.com/lxc/incus/v7/internal/version"
+	"github.com/lxc/incus/v7/shared/api"
+)
+
+// NetworkEgressGatewayAction represents a lifecycle event action for network egress gateways.
+type NetworkEgressGatewayAction string
+
+// All supported lifecycle events for network egress gateways.
+const (
+	NetworkEgressGatewayCreated = NetworkEgressGatewayAction(api.EventLifecycleNetworkEgressGatewayCreated)
+	NetworkEgressGatewayDeleted = NetworkEgressGatewayAction(api.EventLifecycleNetworkEgressGatewayDeleted)
+	NetworkEgressGatewayUpdated = NetworkEgressGatewayAction(api.EventLifecycleNetworkEgressGatewayUpdated)
+)
+
+// Event creates the lifecycle event for an action on a network egress gateway.
+func (a NetworkEgressGatewayAction) Event(n network, address string, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	u := api.NewURL().Path(version.APIVersion, "networks", n.Name(), "egress-gateways", address).Project(n.Project())
+
+	return api.EventLifecycle{
+		Action:    string(a),
+		Source:    u.String(),
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
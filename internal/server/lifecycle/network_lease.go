@@ -0,0 +1,28 @@
+package lifecycle
+
+import (
+	"github.com/lxc/incus/v7/internal/version"
+	"github.com/lxc/incus/v7/shared/api"
+)
+
+// NetworkLeaseAction represents a lifecycle event action for network address reservations.
+type NetworkLeaseAction string
+
+// All supported lifecycle events for network address reservations.
+const (
+	NetworkLeaseCreated = NetworkLeaseAction(api.EventLifecycleNetworkLeaseCreated)
+	NetworkLeaseDeleted = NetworkLeaseAction(api.EventLifecycleNetworkLeaseDeleted)
+	NetworkLeaseUpdated = NetworkLeaseAction(api.EventLifecycleNetworkLeaseUpdated)
+)
+
+// Event creates the lifecycle event for an action on a network address reservation.
+func (a NetworkLeaseAction) Event(n network, address string, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	u := api.NewURL().Path(version.APIVersion, "networks", n.Name(), "leases", address).Project(n.Project())
+
+	return api.EventLifecycle{
+		Action:    string(a),
+		Source:    u.String(),
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
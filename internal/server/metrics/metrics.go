@@ -19,6 +19,8 @@ var metricTypeGauges = []MetricType{
 	ProjectLimit,
 	ProjectResourcesTotal,
 	ProjectUsage,
+	StorageVolumeCompression,
+	StorageVolumeOvercommit,
 }
 
 // NewMetricSet returns a new MetricSet.
@@ -56,3 +56,14 @@ func TestMetricSet_FilterSamples(t *testing.T) {
 		require.Contains(t, hasKeys, "project")
 	}
 }
+
+func TestMetricSet_String(t *testing.T) {
+	m := NewMetricSet(nil)
+	m.AddSamples(StorageVolumeUsedBytes, Sample{Value: 1024, Labels: map[string]string{"pool": "default", "volume": "foo"}})
+	m.AddSamples(StorageVolumeCompression, Sample{Value: 1.5, Labels: map[string]string{"pool": "default", "volume": "foo"}})
+
+	out := m.String()
+
+	require.Contains(t, out, "# TYPE incus_storage_volume_used_bytes gauge\nincus_storage_volume_used_bytes{pool=\"default\",volume=\"foo\"} 1024\n")
+	require.Contains(t, out, "# TYPE incus_storage_volume_compression_ratio gauge\nincus_storage_volume_compression_ratio{pool=\"default\",volume=\"foo\"} 1.5\n")
+}
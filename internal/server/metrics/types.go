@@ -43,6 +43,8 @@ const (
 	MemoryActiveFileBytes
 	// MemoryActiveBytes represents the amount of memory on active LRU list.
 	MemoryActiveBytes
+	// MemoryBalloonBytes represents the amount of memory reclaimed by the memory balloon.
+	MemoryBalloonBytes
 	// MemoryCachedBytes represents the amount of cached memory.
 	MemoryCachedBytes
 	// MemoryDirtyBytes represents the amount of memory waiting to get written back to the disk.
@@ -113,6 +115,16 @@ const (
 	StoragePoolUsedBytes
 	// StoragePoolSizeBytes represents the total space in bytes on a storage pool.
 	StoragePoolSizeBytes
+	// StorageVolumeUsedBytes represents the used space in bytes of a storage volume.
+	StorageVolumeUsedBytes
+	// StorageVolumeSizeBytes represents the size in bytes of a storage volume.
+	StorageVolumeSizeBytes
+	// StorageVolumeSnapshotsBytes represents the space in bytes used by the snapshots of a storage volume.
+	StorageVolumeSnapshotsBytes
+	// StorageVolumeCompression represents the compression ratio of a storage volume.
+	StorageVolumeCompression
+	// StorageVolumeOvercommit represents the overcommit ratio of a thin-provisioned storage volume.
+	StorageVolumeOvercommit
 	// GoGoroutines represents the number of goroutines that currently exist.
 	GoGoroutines
 	// GoAllocBytes represents the number of bytes allocated and still in use.
@@ -199,6 +211,7 @@ var MetricNames = map[MetricType]string{
 	MemoryActiveAnonBytes:       "incus_memory_Active_anon_bytes",
 	MemoryActiveFileBytes:       "incus_memory_Active_file_bytes",
 	MemoryActiveBytes:           "incus_memory_Active_bytes",
+	MemoryBalloonBytes:          "incus_memory_Balloon_bytes",
 	MemoryCachedBytes:           "incus_memory_Cached_bytes",
 	MemoryDirtyBytes:            "incus_memory_Dirty_bytes",
 	MemoryHugePagesFreeBytes:    "incus_memory_HugepagesFree_bytes",
@@ -231,6 +244,11 @@ var MetricNames = map[MetricType]string{
 	ProjectUsage:                "incus_project_usage",
 	StoragePoolUsedBytes:        "incus_storage_pool_used_bytes",
 	StoragePoolSizeBytes:        "incus_storage_pool_size_bytes",
+	StorageVolumeCompression:    "incus_storage_volume_compression_ratio",
+	StorageVolumeOvercommit:     "incus_storage_volume_overcommit_ratio",
+	StorageVolumeSizeBytes:      "incus_storage_volume_size_bytes",
+	StorageVolumeSnapshotsBytes: "incus_storage_volume_snapshots_used_bytes",
+	StorageVolumeUsedBytes:      "incus_storage_volume_used_bytes",
 	TimeSeconds:                 "incus_time_seconds",
 	UptimeSeconds:               "incus_uptime_seconds",
 	WarningsTotal:               "incus_warnings_total",
@@ -274,6 +292,7 @@ var MetricHeaders = map[MetricType]string{
 	MemoryActiveAnonBytes:       "# HELP incus_memory_Active_anon_bytes The amount of anonymous memory on active LRU list.",
 	MemoryActiveFileBytes:       "# HELP incus_memory_Active_file_bytes The amount of file-backed memory on active LRU list.",
 	MemoryActiveBytes:           "# HELP incus_memory_Active_bytes The amount of memory on active LRU list.",
+	MemoryBalloonBytes:          "# HELP incus_memory_Balloon_bytes The amount of memory reclaimed by the memory balloon.",
 	MemoryCachedBytes:           "# HELP incus_memory_Cached_bytes The amount of cached memory.",
 	MemoryDirtyBytes:            "# HELP incus_memory_Dirty_bytes The amount of memory waiting to get written back to the disk.",
 	MemoryHugePagesFreeBytes:    "# HELP incus_memory_HugepagesFree_bytes The amount of free memory for hugetlb.",
@@ -306,6 +325,11 @@ var MetricHeaders = map[MetricType]string{
 	ProjectUsage:                "# HELP incus_project_usage Current project resource usage.",
 	StoragePoolUsedBytes:        "# HELP incus_storage_pool_used_bytes The used space in bytes on a storage pool.",
 	StoragePoolSizeBytes:        "# HELP incus_storage_pool_size_bytes The total space in bytes on a storage pool.",
+	StorageVolumeCompression:    "# HELP incus_storage_volume_compression_ratio The ratio between the logical and the stored size of the data of a storage volume.",
+	StorageVolumeOvercommit:     "# HELP incus_storage_volume_overcommit_ratio The ratio between the provisioned and the allocated size of a thin-provisioned storage volume.",
+	StorageVolumeSizeBytes:      "# HELP incus_storage_volume_size_bytes The size in bytes of a storage volume.",
+	StorageVolumeSnapshotsBytes: "# HELP incus_storage_volume_snapshots_used_bytes The space in bytes used by the snapshots of a storage volume.",
+	StorageVolumeUsedBytes:      "# HELP incus_storage_volume_used_bytes The used space in bytes of a storage volume.",
 	TimeSeconds:                 "# HELP incus_time_seconds The current unix epoch.",
 	UptimeSeconds:               "# HELP incus_uptime_seconds The daemon uptime in seconds.",
 	WarningsTotal:               "# HELP incus_warnings_total The number of active warnings.",
@@ -3,6 +3,9 @@ package acl
 import (
 	"context"
 	"fmt"
+	"net"
+	"slices"
+	"strings"
 
 	"github.com/lxc/incus/v7/internal/server/db"
 	dbCluster "github.com/lxc/incus/v7/internal/server/db/cluster"
@@ -37,11 +40,21 @@ func FirewallACLRules(s *state.State, aclDeviceName string, aclProjectName strin
 				continue
 			}
 
+			source, err := firewallPeerSubjects(s, aclProjectName, rule.Source)
+			if err != nil {
+				return err
+			}
+
+			destination, err := firewallPeerSubjects(s, aclProjectName, rule.Destination)
+			if err != nil {
+				return err
+			}
+
 			firewallACLRule := firewallDrivers.ACLRule{
 				Direction:       direction,
 				Action:          rule.Action,
-				Source:          rule.Source,
-				Destination:     rule.Destination,
+				Source:          source,
+				Destination:     destination,
 				Protocol:        rule.Protocol,
 				SourcePort:      rule.SourcePort,
 				DestinationPort: rule.DestinationPort,
@@ -127,6 +140,81 @@ func FirewallACLRules(s *state.State, aclDeviceName string, aclProjectName strin
 	return rules, nil
 }
 
+// firewallPeerSubjects replaces the network peer subjects ("@<network>/<peer>") of a rule with the subnets they
+// refer to, the remote subnets of the remote peers and the subnets of the target network of the local peers.
+func firewallPeerSubjects(s *state.State, projectName string, subjects string) (string, error) {
+	if !strings.Contains(subjects, "@") {
+		return subjects, nil
+	}
+
+	var result []string
+
+	for _, subject := range util.SplitNTrimSpace(subjects, ",", -1, false) {
+		after, ok := strings.CutPrefix(subject, "@")
+		if !ok || slices.Contains(ruleSubjectInternalAliases, subject) || slices.Contains(ruleSubjectExternalAliases, subject) {
+			result = append(result, subject)
+			continue
+		}
+
+		networkName, peerName, found := strings.Cut(after, "/")
+		if !found {
+			return "", fmt.Errorf("Cannot parse subject as peer %q", subject)
+		}
+
+		var subnets []string
+
+		err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			networkID, err := tx.GetNetworkID(ctx, projectName, networkName)
+			if err != nil {
+				return err
+			}
+
+			dbPeer, err := dbCluster.GetNetworkPeer(ctx, tx.Tx(), networkID, peerName)
+			if err != nil {
+				return err
+			}
+
+			peer, err := dbPeer.ToAPI(ctx, tx.Tx())
+			if err != nil {
+				return err
+			}
+
+			if peer.Type == "remote" {
+				subnets = util.SplitNTrimSpace(peer.Config["wireguard.subnets"], ",", -1, true)
+
+				return nil
+			}
+
+			_, targetNetwork, _, err := tx.GetNetworkInAnyState(ctx, peer.TargetProject, peer.TargetNetwork)
+			if err != nil {
+				return err
+			}
+
+			for _, keyPrefix := range []string{"ipv4", "ipv6"} {
+				_, subnet, err := net.ParseCIDR(targetNetwork.Config[keyPrefix+".address"])
+				if err == nil {
+					subnets = append(subnets, subnet.String())
+				}
+
+				subnets = append(subnets, util.SplitNTrimSpace(targetNetwork.Config[keyPrefix+".routes"], ",", -1, true)...)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return "", fmt.Errorf("Failed loading network peer %q: %w", subject, err)
+		}
+
+		if len(subnets) == 0 {
+			return "", fmt.Errorf("Network peer %q has no subnets", subject)
+		}
+
+		result = append(result, subnets...)
+	}
+
+	return strings.Join(result, ","), nil
+}
+
 // firewallACLDefaults returns the action and logging mode to use for the specified direction's default rule.
 // If the security.acls.default.{in,e}gress.action or security.acls.default.{in,e}gress.logged settings are not
 // specified in the network config, then it returns "reject" and false respectively.
@@ -0,0 +1,106 @@
+package capture
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"runtime"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+	"github.com/mdlayher/packet"
+	"golang.org/x/sys/unix"
+)
+
+// snapLength is the maximum number of bytes captured from each packet.
+const snapLength = 262144
+
+// Capture captures the packets of an interface matching the filter expression and writes them to w in the
+// pcapng format. It returns once ctx is done or after the given number of packets (unless 0).
+func Capture(ctx context.Context, w io.Writer, ifName string, filter string, packets int64) error {
+	ifi, err := net.InterfaceByName(ifName)
+	if err != nil {
+		return fmt.Errorf("Failed getting interface %q: %w", ifName, err)
+	}
+
+	program, err := CompileFilter(filter)
+	if err != nil {
+		return err
+	}
+
+	// The filter is attached before the socket is bound so that no other packet gets through.
+	conn, err := packet.Listen(ifi, packet.Raw, unix.ETH_P_ALL, &packet.Config{Filter: program})
+	if err != nil {
+		return fmt.Errorf("Failed opening packet socket on %q: %w", ifName, err)
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	// Bridges only pass the traffic switched between their ports to the host when promiscuous.
+	err = conn.SetPromiscuous(true)
+	if err != nil {
+		return fmt.Errorf("Failed enabling promiscuous mode on %q: %w", ifName, err)
+	}
+
+	intf := pcapgo.NgInterface{
+		Name:                ifName,
+		Filter:              filter,
+		OS:                  runtime.GOOS,
+		LinkType:            layers.LinkTypeEthernet,
+		SnapLength:          snapLength,
+		TimestampResolution: 9,
+	}
+
+	options := pcapgo.NgWriterOptions{
+		SectionInfo: pcapgo.NgSectionInfo{
+			Hardware:    runtime.GOARCH,
+			OS:          runtime.GOOS,
+			Application: "Incus",
+		},
+	}
+
+	writer, err := pcapgo.NewNgWriterInterface(w, intf, options)
+	if err != nil {
+		return err
+	}
+
+	// Send the headers right away so that clients can start decoding the stream.
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	// Interrupt the pending read once done.
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetReadDeadline(time.Now()) })
+	defer stop()
+
+	buf := make([]byte, snapLength)
+
+	for count := int64(0); packets == 0 || count < packets; count++ {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("Failed reading packet from %q: %w", ifName, err)
+		}
+
+		ci := gopacket.CaptureInfo{Timestamp: time.Now(), CaptureLength: n, Length: n}
+
+		err = writer.WritePacket(ci, buf[:n])
+		if err != nil {
+			return err
+		}
+
+		err = writer.Flush()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,583 @@
+package capture
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/bpf"
+	"golang.org/x/sys/unix"
+)
+
+// Offsets within Ethernet frames (without VLAN tags, which the kernel strips before the socket filter).
+const (
+	offEtherDst  = 0
+	offEtherSrc  = 6
+	offEtherType = 12
+	offNetwork   = 14
+
+	offIPv4Frag  = offNetwork + 6
+	offIPv4Proto = offNetwork + 9
+	offIPv4Src   = offNetwork + 12
+	offIPv4Dst   = offNetwork + 16
+
+	offIPv6NextHeader = offNetwork + 6
+	offIPv6Src        = offNetwork + 8
+	offIPv6Dst        = offNetwork + 24
+	offIPv6Payload    = offNetwork + 40
+)
+
+// filterInsn is an instruction of a filter program whose conditional jumps refer to labels.
+type filterInsn struct {
+	insn    bpf.Instruction
+	cond    bpf.JumpTest
+	val     uint32
+	onTrue  int
+	onFalse int
+}
+
+// filterCompiler generates the classic BPF program of a filter expression.
+type filterCompiler struct {
+	insns  []filterInsn
+	labels []int
+}
+
+// newLabel returns a new, not yet placed, jump target.
+func (c *filterCompiler) newLabel() int {
+	c.labels = append(c.labels, -1)
+	return len(c.labels) - 1
+}
+
+// place sets the jump target of the label to the next instruction.
+func (c *filterCompiler) place(label int) {
+	c.labels[label] = len(c.insns)
+}
+
+// emit adds an instruction which doesn't jump.
+func (c *filterCompiler) emit(insn bpf.Instruction) {
+	c.insns = append(c.insns, filterInsn{insn: insn, onTrue: -1, onFalse: -1})
+}
+
+// emitJump adds a conditional jump to the labels.
+func (c *filterCompiler) emitJump(cond bpf.JumpTest, val uint32, onTrue int, onFalse int) {
+	c.insns = append(c.insns, filterInsn{cond: cond, val: val, onTrue: onTrue, onFalse: onFalse})
+}
+
+// program resolves the labels and returns the instructions of the program.
+func (c *filterCompiler) program() ([]bpf.Instruction, error) {
+	program := make([]bpf.Instruction, 0, len(c.insns))
+
+	for i, insn := range c.insns {
+		if insn.onTrue < 0 {
+			program = append(program, insn.insn)
+			continue
+		}
+
+		// Labels are always placed after the jumps referring to them, so the skips are positive.
+		skipTrue := c.labels[insn.onTrue] - i - 1
+		skipFalse := c.labels[insn.onFalse] - i - 1
+		if skipTrue > 255 || skipFalse > 255 {
+			return nil, errors.New("Filter expression is too complex")
+		}
+
+		program = append(program, bpf.JumpIf{Cond: insn.cond, Val: insn.val, SkipTrue: uint8(skipTrue), SkipFalse: uint8(skipFalse)})
+	}
+
+	return program, nil
+}
+
+// filterExpr generates the code of an expression jumping to onTrue if the packet matches and to onFalse otherwise.
+type filterExpr func(c *filterCompiler, onTrue int, onFalse int)
+
+// filterTest returns an expression comparing the value loaded by the instructions.
+func filterTest(cond bpf.JumpTest, val uint32, loads ...bpf.Instruction) filterExpr {
+	return func(c *filterCompiler, onTrue int, onFalse int) {
+		for _, load := range loads {
+			c.emit(load)
+		}
+
+		c.emitJump(cond, val, onTrue, onFalse)
+	}
+}
+
+// filterAnd returns an expression matching when all the expressions match.
+func filterAnd(exprs ...filterExpr) filterExpr {
+	return func(c *filterCompiler, onTrue int, onFalse int) {
+		for _, expr := range exprs[:len(exprs)-1] {
+			next := c.newLabel()
+			expr(c, next, onFalse)
+			c.place(next)
+		}
+
+		exprs[len(exprs)-1](c, onTrue, onFalse)
+	}
+}
+
+// filterOr returns an expression matching when any of the expressions match.
+func filterOr(exprs ...filterExpr) filterExpr {
+	return func(c *filterCompiler, onTrue int, onFalse int) {
+		for _, expr := range exprs[:len(exprs)-1] {
+			next := c.newLabel()
+			expr(c, onTrue, next)
+			c.place(next)
+		}
+
+		exprs[len(exprs)-1](c, onTrue, onFalse)
+	}
+}
+
+// filterNot returns an expression matching when the expression doesn't.
+func filterNot(expr filterExpr) filterExpr {
+	return func(c *filterCompiler, onTrue int, onFalse int) {
+		expr(c, onFalse, onTrue)
+	}
+}
+
+// filterEtherType returns an expression matching the packets of an Ethernet protocol.
+func filterEtherType(etherType uint32) filterExpr {
+	return filterTest(bpf.JumpEqual, etherType, bpf.LoadAbsolute{Off: offEtherType, Size: 2})
+}
+
+// filterIPProto returns an expression matching the IPv4 and IPv6 packets of the transport protocols.
+func filterIPProto(ipv4 bool, ipv6 bool, protos ...uint32) filterExpr {
+	var exprs []filterExpr
+
+	for _, proto := range protos {
+		if ipv4 {
+			exprs = append(exprs, filterAnd(filterEtherType(unix.ETH_P_IP), filterTest(bpf.JumpEqual, proto, bpf.LoadAbsolute{Off: offIPv4Proto, Size: 1})))
+		}
+
+		if ipv6 {
+			// Extension headers aren't followed, as done by tcpdump.
+			exprs = append(exprs, filterAnd(filterEtherType(unix.ETH_P_IPV6), filterTest(bpf.JumpEqual, proto, bpf.LoadAbsolute{Off: offIPv6NextHeader, Size: 1})))
+		}
+	}
+
+	return filterOr(exprs...)
+}
+
+// filterBytes returns an expression matching the bytes at the offset against the value under the mask, or nil
+// if the mask is empty. The value is loaded in words, and in a half word for the end of MAC addresses.
+func filterBytes(offset uint32, value []byte, mask []byte) filterExpr {
+	var exprs []filterExpr
+
+	for i := 0; i < len(value); i += 4 {
+		size := min(len(value)-i, 4)
+
+		var val, valMask uint32
+		for j := range size {
+			val = val<<8 | uint32(value[i+j])
+			valMask = valMask<<8 | uint32(mask[i+j])
+		}
+
+		if valMask == 0 {
+			continue
+		}
+
+		loads := []bpf.Instruction{bpf.LoadAbsolute{Off: offset + uint32(i), Size: size}}
+		if valMask != (uint32(1)<<(8*size))-1 {
+			loads = append(loads, bpf.ALUOpConstant{Op: bpf.ALUOpAnd, Val: valMask})
+		}
+
+		exprs = append(exprs, filterTest(bpf.JumpEqual, val&valMask, loads...))
+	}
+
+	if len(exprs) == 0 {
+		return nil
+	}
+
+	return filterAnd(exprs...)
+}
+
+// filterDirection returns an expression matching the source, destination or either of them.
+func filterDirection(dir string, src filterExpr, dst filterExpr) filterExpr {
+	switch dir {
+	case "src":
+		return src
+	case "dst":
+		return dst
+	default:
+		return filterOr(src, dst)
+	}
+}
+
+// filterEtherHost returns an expression matching a MAC address.
+func filterEtherHost(dir string, value string) (filterExpr, error) {
+	hwaddr, err := net.ParseMAC(value)
+	if err != nil || len(hwaddr) != 6 {
+		return nil, fmt.Errorf("Invalid MAC address %q", value)
+	}
+
+	mask := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+
+	return filterDirection(dir, filterBytes(offEtherSrc, hwaddr, mask), filterBytes(offEtherDst, hwaddr, mask)), nil
+}
+
+// filterNet returns an expression matching an IP address or subnet.
+func filterNet(dir string, family string, subnet *net.IPNet) (filterExpr, error) {
+	ipv4 := subnet.IP.To4()
+	if ipv4 != nil {
+		if family == "ip6" {
+			return nil, fmt.Errorf("Address %q isn't an IPv6 address", subnet.IP.String())
+		}
+
+		ipv4Mask := subnet.Mask
+		if len(ipv4Mask) == net.IPv6len {
+			ipv4Mask = ipv4Mask[12:]
+		}
+
+		address := func(offset uint32) filterExpr {
+			test := filterBytes(offset, ipv4, ipv4Mask)
+			if test == nil {
+				return filterEtherType(unix.ETH_P_IP)
+			}
+
+			return filterAnd(filterEtherType(unix.ETH_P_IP), test)
+		}
+
+		return filterDirection(dir, address(offIPv4Src), address(offIPv4Dst)), nil
+	}
+
+	if family == "ip" {
+		return nil, fmt.Errorf("Address %q isn't an IPv4 address", subnet.IP.String())
+	}
+
+	address := func(offset uint32) filterExpr {
+		test := filterBytes(offset, subnet.IP.To16(), subnet.Mask)
+		if test == nil {
+			return filterEtherType(unix.ETH_P_IPV6)
+		}
+
+		return filterAnd(filterEtherType(unix.ETH_P_IPV6), test)
+	}
+
+	return filterDirection(dir, address(offIPv6Src), address(offIPv6Dst)), nil
+}
+
+// filterPort returns an expression matching a TCP or UDP port.
+func filterPort(dir string, protos []uint32, value string) (filterExpr, error) {
+	port, err := strconv.ParseUint(value, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid port %q", value)
+	}
+
+	port4 := func(offset uint32) filterExpr {
+		return filterAnd(
+			filterIPProto(true, false, protos...),
+			// Only the first fragment contains the transport header.
+			filterNot(filterTest(bpf.JumpBitsSet, 0x1fff, bpf.LoadAbsolute{Off: offIPv4Frag, Size: 2})),
+			filterTest(bpf.JumpEqual, uint32(port), bpf.LoadMemShift{Off: offNetwork}, bpf.LoadIndirect{Off: offNetwork + offset, Size: 2}),
+		)
+	}
+
+	port6 := func(offset uint32) filterExpr {
+		return filterAnd(
+			filterIPProto(false, true, protos...),
+			filterTest(bpf.JumpEqual, uint32(port), bpf.LoadAbsolute{Off: offIPv6Payload + offset, Size: 2}),
+		)
+	}
+
+	return filterDirection(dir, filterOr(port4(0), port6(0)), filterOr(port4(2), port6(2))), nil
+}
+
+// filterUnsupported lists the pcap-filter keywords outside of the restricted grammar of capture filters.
+// They are rejected explicitly rather than being reported as invalid values.
+var filterUnsupported = map[string]bool{
+	"aarp": true, "ah": true, "atalk": true, "broadcast": true, "carp": true, "decnet": true, "esp": true,
+	"fddi": true, "gateway": true, "geneve": true, "greater": true, "igmp": true, "igrp": true,
+	"inbound": true, "ipx": true, "iso": true, "lat": true, "len": true, "less": true, "link": true, "llc": true,
+	"mopdl": true, "moprc": true, "mpls": true, "multicast": true, "netbeui": true, "outbound": true, "pim": true,
+	"portrange": true, "pppoed": true, "pppoes": true, "proto": true, "protochain": true, "rarp": true,
+	"sctp": true, "stp": true, "tr": true, "vlan": true, "vrrp": true, "vxlan": true, "wlan": true,
+}
+
+// filterCheckSupported returns an error if the token belongs to the pcap-filter syntax but not to the
+// restricted grammar of capture filters.
+func filterCheckSupported(token string) error {
+	if filterUnsupported[token] {
+		return fmt.Errorf("Unsupported %q primitive in filter expression (capture filters only support a restricted subset of the pcap-filter syntax)", token)
+	}
+
+	// Packet data accessors (such as "tcp[13]") and arithmetic expressions.
+	if strings.ContainsAny(token, "[]=<>+*") || token == "&" || token == "|" {
+		return fmt.Errorf("Unsupported %q in filter expression (capture filters don't support packet data accessors and arithmetic expressions)", token)
+	}
+
+	return nil
+}
+
+// filterParser parses filter expressions.
+type filterParser struct {
+	tokens []string
+	pos    int
+}
+
+// peek returns the next token, or an empty string at the end of the expression.
+func (p *filterParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+
+	return p.tokens[p.pos]
+}
+
+// next consumes the next token.
+func (p *filterParser) next() string {
+	token := p.peek()
+	p.pos++
+
+	return token
+}
+
+// parseOr parses a list of expressions separated by "or".
+func (p *filterParser) parseOr() (filterExpr, error) {
+	exprs := []filterExpr{}
+
+	for {
+		expr, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		exprs = append(exprs, expr)
+
+		if p.peek() != "or" && p.peek() != "||" {
+			break
+		}
+
+		p.next()
+	}
+
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+
+	return filterOr(exprs...), nil
+}
+
+// parseAnd parses a list of expressions separated by "and".
+func (p *filterParser) parseAnd() (filterExpr, error) {
+	exprs := []filterExpr{}
+
+	for {
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		exprs = append(exprs, expr)
+
+		if p.peek() != "and" && p.peek() != "&&" {
+			break
+		}
+
+		p.next()
+	}
+
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+
+	return filterAnd(exprs...), nil
+}
+
+// parseNot parses a negated, parenthesized or primitive expression.
+func (p *filterParser) parseNot() (filterExpr, error) {
+	switch p.peek() {
+	case "not", "!":
+		p.next()
+
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		return filterNot(expr), nil
+	case "(":
+		p.next()
+
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.next() != ")" {
+			return nil, errors.New("Missing closing parenthesis in filter expression")
+		}
+
+		return expr, nil
+	}
+
+	return p.parsePrimitive()
+}
+
+// parsePrimitive parses a primitive made of an optional protocol, an optional direction and a value.
+func (p *filterParser) parsePrimitive() (filterExpr, error) {
+	proto := ""
+	switch p.peek() {
+	case "ether", "ip", "ip6", "arp", "tcp", "udp", "icmp", "icmp6":
+		proto = p.next()
+	}
+
+	dir := ""
+	switch p.peek() {
+	case "src", "dst":
+		dir = p.next()
+	}
+
+	kind := ""
+	switch p.peek() {
+	case "host", "net", "port":
+		kind = p.next()
+	}
+
+	// Protocol only.
+	if dir == "" && kind == "" {
+		switch proto {
+		case "ip":
+			return filterEtherType(unix.ETH_P_IP), nil
+		case "ip6":
+			return filterEtherType(unix.ETH_P_IPV6), nil
+		case "arp":
+			return filterEtherType(unix.ETH_P_ARP), nil
+		case "tcp":
+			return filterIPProto(true, true, unix.IPPROTO_TCP), nil
+		case "udp":
+			return filterIPProto(true, true, unix.IPPROTO_UDP), nil
+		case "icmp":
+			return filterIPProto(true, false, unix.IPPROTO_ICMP), nil
+		case "icmp6":
+			return filterIPProto(false, true, unix.IPPROTO_ICMPV6), nil
+		}
+	}
+
+	value := p.next()
+	switch value {
+	case "", "(", ")", "and", "&&", "or", "||", "not", "!":
+		if proto != "" || dir != "" || kind != "" {
+			return nil, fmt.Errorf("Missing value after %q in filter expression", p.tokens[p.pos-2])
+		}
+
+		if value == "" {
+			return nil, errors.New("Unexpected end of filter expression")
+		}
+
+		return nil, fmt.Errorf("Unexpected %q in filter expression", value)
+	}
+
+	switch proto {
+	case "ether":
+		if kind != "" && kind != "host" {
+			return nil, fmt.Errorf("Unsupported %q qualifier for %q in filter expression", kind, proto)
+		}
+
+		return filterEtherHost(dir, value)
+	case "", "ip", "ip6":
+		if kind == "port" {
+			if proto != "" {
+				return nil, fmt.Errorf("Unsupported %q qualifier for %q in filter expression", kind, proto)
+			}
+
+			return filterPort(dir, []uint32{unix.IPPROTO_TCP, unix.IPPROTO_UDP}, value)
+		}
+
+		var subnet *net.IPNet
+		if kind != "host" {
+			_, subnet, _ = net.ParseCIDR(value)
+		}
+
+		if subnet == nil && kind != "net" {
+			address := net.ParseIP(value)
+			if address != nil {
+				subnet = &net.IPNet{IP: address, Mask: net.CIDRMask(len(address)*8, len(address)*8)}
+			}
+		}
+
+		if subnet == nil {
+			return nil, fmt.Errorf("Invalid address %q in filter expression", value)
+		}
+
+		return filterNet(dir, proto, subnet)
+	case "tcp", "udp":
+		if kind != "port" {
+			return nil, fmt.Errorf("Missing %q qualifier after %q in filter expression", "port", proto)
+		}
+
+		protoNumber := uint32(unix.IPPROTO_TCP)
+		if proto == "udp" {
+			protoNumber = unix.IPPROTO_UDP
+		}
+
+		return filterPort(dir, []uint32{protoNumber}, value)
+	}
+
+	return nil, fmt.Errorf("Unsupported qualifiers for %q in filter expression", proto)
+}
+
+// filterTokens splits a filter expression into tokens.
+func filterTokens(expr string) []string {
+	expr = strings.NewReplacer("(", " ( ", ")", " ) ", "!", " ! ").Replace(expr)
+
+	return strings.Fields(expr)
+}
+
+// compileFilter returns the BPF instructions of a filter expression, accepting up to snapLength bytes of the
+// matching packets.
+func compileFilter(expr string, snapLength uint32) ([]bpf.Instruction, error) {
+	parser := &filterParser{tokens: filterTokens(expr)}
+
+	for _, token := range parser.tokens {
+		err := filterCheckSupported(token)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	root, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if parser.peek() != "" {
+		return nil, fmt.Errorf("Unexpected %q in filter expression", parser.peek())
+	}
+
+	c := &filterCompiler{}
+	accept := c.newLabel()
+	reject := c.newLabel()
+
+	root(c, accept, reject)
+
+	c.place(accept)
+	c.emit(bpf.RetConstant{Val: snapLength})
+	c.place(reject)
+	c.emit(bpf.RetConstant{Val: 0})
+
+	return c.program()
+}
+
+// CompileFilter compiles a capture filter expression into a classic BPF program.
+//
+// Capture filters aren't compiled by libpcap but use their own restricted grammar, which is a subset of the
+// pcap-filter syntax. The other pcap-filter primitives are rejected. The grammar is:
+//   - Protocols: ip, ip6, arp, tcp, udp, icmp and icmp6.
+//   - Addresses: [ip|ip6] [src|dst] [host|net] ADDRESS, with the address possibly in CIDR notation.
+//   - MAC addresses: ether [src|dst] [host] MAC.
+//   - Ports: [tcp|udp] [src|dst] port PORT.
+//   - Combinations of those with and (&&), or (||), not (!) and parentheses.
+//
+// An empty expression matches all packets and returns no program.
+func CompileFilter(expr string) ([]bpf.RawInstruction, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+
+	program, err := compileFilter(expr, snapLength)
+	if err != nil {
+		return nil, err
+	}
+
+	return bpf.Assemble(program)
+}
@@ -0,0 +1,152 @@
+package capture
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/bpf"
+)
+
+// testPacket serializes an Ethernet frame carrying a TCP or UDP packet between the addresses.
+func testPacket(t *testing.T, src string, dst string, proto layers.IPProtocol, srcPort uint16, dstPort uint16) []byte {
+	t.Helper()
+
+	eth := &layers.Ethernet{
+		SrcMAC: net.HardwareAddr{0x10, 0x66, 0x6a, 0x00, 0x00, 0x01},
+		DstMAC: net.HardwareAddr{0x10, 0x66, 0x6a, 0x00, 0x00, 0x02},
+	}
+
+	var network gopacket.NetworkLayer
+	var serializable []gopacket.SerializableLayer
+
+	srcIP := net.ParseIP(src)
+	dstIP := net.ParseIP(dst)
+	if srcIP.To4() != nil {
+		eth.EthernetType = layers.EthernetTypeIPv4
+		ipv4 := &layers.IPv4{Version: 4, TTL: 64, Protocol: proto, SrcIP: srcIP.To4(), DstIP: dstIP.To4()}
+		network = ipv4
+		serializable = append(serializable, eth, ipv4)
+	} else {
+		eth.EthernetType = layers.EthernetTypeIPv6
+		ipv6 := &layers.IPv6{Version: 6, HopLimit: 64, NextHeader: proto, SrcIP: srcIP, DstIP: dstIP}
+		network = ipv6
+		serializable = append(serializable, eth, ipv6)
+	}
+
+	switch proto {
+	case layers.IPProtocolTCP:
+		tcp := &layers.TCP{SrcPort: layers.TCPPort(srcPort), DstPort: layers.TCPPort(dstPort), SYN: true, Window: 1024}
+		require.NoError(t, tcp.SetNetworkLayerForChecksum(network))
+		serializable = append(serializable, tcp)
+	case layers.IPProtocolUDP:
+		udp := &layers.UDP{SrcPort: layers.UDPPort(srcPort), DstPort: layers.UDPPort(dstPort)}
+		require.NoError(t, udp.SetNetworkLayerForChecksum(network))
+		serializable = append(serializable, udp)
+	}
+
+	buf := gopacket.NewSerializeBuffer()
+	err := gopacket.SerializeLayers(buf, gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}, append(serializable, gopacket.Payload("incus"))...)
+	require.NoError(t, err)
+
+	return buf.Bytes()
+}
+
+func TestCompileFilter(t *testing.T) {
+	tcp4 := testPacket(t, "10.0.0.2", "192.0.2.1", layers.IPProtocolTCP, 40000, 443)
+	udp4 := testPacket(t, "10.0.0.2", "10.0.0.1", layers.IPProtocolUDP, 53000, 53)
+	tcp6 := testPacket(t, "fd42::2", "2001:db8::1", layers.IPProtocolTCP, 40000, 80)
+	udp6 := testPacket(t, "fd42::2", "fd42::1", layers.IPProtocolUDP, 546, 547)
+
+	tests := []struct {
+		filter  string
+		matches []bool // tcp4, udp4, tcp6, udp6
+	}{
+		{"ip", []bool{true, true, false, false}},
+		{"ip6", []bool{false, false, true, true}},
+		{"arp", []bool{false, false, false, false}},
+		{"tcp", []bool{true, false, true, false}},
+		{"udp", []bool{false, true, false, true}},
+		{"not tcp", []bool{false, true, false, true}},
+		{"host 10.0.0.2", []bool{true, true, false, false}},
+		{"dst host 10.0.0.2", []bool{false, false, false, false}},
+		{"src 10.0.0.2", []bool{true, true, false, false}},
+		{"net 192.0.2.0/24", []bool{true, false, false, false}},
+		{"dst net 10.0.0.0/8", []bool{false, true, false, false}},
+		{"host fd42::1", []bool{false, false, false, true}},
+		{"src net fd42::/64", []bool{false, false, true, true}},
+		{"ip6 dst net 2001:db8::/32", []bool{false, false, true, false}},
+		{"net 0.0.0.0/0", []bool{true, true, false, false}},
+		{"port 443", []bool{true, false, false, false}},
+		{"udp port 53", []bool{false, true, false, false}},
+		{"tcp port 53", []bool{false, false, false, false}},
+		{"dst port 547", []bool{false, false, false, true}},
+		{"src port 40000", []bool{true, false, true, false}},
+		{"ether src 10:66:6a:00:00:01", []bool{true, true, true, true}},
+		{"ether dst host 10:66:6a:00:00:01", []bool{false, false, false, false}},
+		{"ip6 and (port 80 or port 547)", []bool{false, false, true, true}},
+		{"host 10.0.0.2 && !(port 53 || port 443)", []bool{false, false, false, false}},
+		{"tcp or udp and not ip6", []bool{true, true, true, false}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.filter, func(t *testing.T) {
+			program, err := compileFilter(test.filter, snapLength)
+			require.NoError(t, err)
+
+			_, err = bpf.Assemble(program)
+			require.NoError(t, err)
+
+			vm, err := bpf.NewVM(program)
+			require.NoError(t, err)
+
+			for i, pkt := range [][]byte{tcp4, udp4, tcp6, udp6} {
+				n, err := vm.Run(pkt)
+				require.NoError(t, err)
+				assert.Equal(t, test.matches[i], n > 0, "packet %d", i)
+			}
+		})
+	}
+}
+
+func TestCompileFilterErrors(t *testing.T) {
+	tests := []string{
+		"host",
+		"port http",
+		"ip6 host 10.0.0.1",
+		"tcp host 10.0.0.1",
+		"(tcp",
+		"tcp)",
+		"ether host 10.0.0.1",
+		"vlan 100",
+	}
+
+	for _, filter := range tests {
+		t.Run(filter, func(t *testing.T) {
+			_, err := CompileFilter(filter)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCompileFilterUnsupported(t *testing.T) {
+	tests := []string{
+		"vlan 100 and tcp port 80",
+		"tcp portrange 8000-8080",
+		"ip proto 47",
+		"tcp port 80 or sctp",
+		"tcp[13] & 2 != 0",
+		"less 64",
+		"ether broadcast",
+	}
+
+	for _, filter := range tests {
+		t.Run(filter, func(t *testing.T) {
+			_, err := CompileFilter(filter)
+			assert.ErrorContains(t, err, "Unsupported")
+		})
+	}
+}
@@ -3,10 +3,12 @@ package network
 import (
 	"context"
 	"database/sql"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io/fs"
 	"maps"
+	"math"
 	"net"
 	"net/http"
 	"os"
@@ -17,8 +19,10 @@ import (
 	"time"
 
 	"github.com/mdlayher/netx/eui64"
+	"golang.org/x/sys/unix"
 
 	incus "github.com/lxc/incus/v7/client"
+	"github.com/lxc/incus/v7/internal/iprange"
 	"github.com/lxc/incus/v7/internal/server/apparmor"
 	"github.com/lxc/incus/v7/internal/server/cluster"
 	"github.com/lxc/incus/v7/internal/server/cluster/request"
@@ -32,6 +36,8 @@ import (
 	"github.com/lxc/incus/v7/internal/server/ip"
 	"github.com/lxc/incus/v7/internal/server/network/acl"
 	addressset "github.com/lxc/incus/v7/internal/server/network/address-set"
+	"github.com/lxc/incus/v7/internal/server/network/flows"
+	"github.com/lxc/incus/v7/internal/server/network/ovs"
 	"github.com/lxc/incus/v7/internal/server/project"
 	localUtil "github.com/lxc/incus/v7/internal/server/util"
 	"github.com/lxc/incus/v7/internal/server/warnings"
@@ -61,6 +67,10 @@ func (n *bridge) DBType() db.NetworkType {
 func (n *bridge) Info() Info {
 	info := n.common.Info()
 	info.AddressForwards = true
+	info.LoadBalancers = true
+	info.EgressGateways = true
+	info.Reservations = true
+	info.Peering = true
 
 	return info
 }
@@ -174,6 +184,14 @@ func (n *bridge) ValidateName(name string) error {
 func (n *bridge) Validate(config map[string]string, clientType request.ClientType) error {
 	// Build driver specific rules dynamically.
 	rules := map[string]func(value string) error{
+		// gendoc:generate(entity=network_bridge, group=common, key=bgp.import.filter)
+		//
+		// ---
+		//  type: string
+		//  condition: BGP server
+		//  shortdesc: Comma-separated list of prefixes (optionally followed by `..` and the longest accepted prefix length) whose routes learned from the BGP peers are installed into the host routing table
+		"bgp.import.filter": validate.Optional(validateBGPImportFilter),
+
 		// gendoc:generate(entity=network_bridge, group=common, key=bgp.ipv4.nexthop)
 		//
 		// ---
@@ -386,6 +404,15 @@ func (n *bridge) Validate(config map[string]string, clientType request.ClientTyp
 		//  shortdesc: Comma-separated list of IPv4 ranges to use for child OVN network routers (FIRST-LAST format)
 		"ipv4.ovn.ranges": validate.Optional(validate.IsListOf(validate.IsNetworkRangeV4)),
 
+		// gendoc:generate(entity=network_bridge, group=common, key=ipv4.reserved_ranges)
+		//
+		// ---
+		//  type: string
+		//  condition: IPv4 address
+		//  default: -
+		//  shortdesc: Comma-separated list of IPv4 ranges reserved for external hosts, never handed out by DHCP or to instances (FIRST-LAST format)
+		"ipv4.reserved_ranges": validate.Optional(validate.IsListOf(validate.IsNetworkRangeV4)),
+
 		// gendoc:generate(entity=network_bridge, group=common, key=ipv6.address)
 		//
 		// ---
@@ -509,6 +536,15 @@ func (n *bridge) Validate(config map[string]string, clientType request.ClientTyp
 		//  shortdesc: Comma-separated list of IPv6 ranges to use for child OVN network routers (FIRST-LAST format)
 		"ipv6.ovn.ranges": validate.Optional(validate.IsListOf(validate.IsNetworkRangeV6)),
 
+		// gendoc:generate(entity=network_bridge, group=common, key=ipv6.reserved_ranges)
+		//
+		// ---
+		//  type: string
+		//  condition: IPv6 address
+		//  default: -
+		//  shortdesc: Comma-separated list of IPv6 ranges reserved for external hosts, never handed out by DHCP or to instances (FIRST-LAST format)
+		"ipv6.reserved_ranges": validate.Optional(validate.IsListOf(validate.IsNetworkRangeV6)),
+
 		// gendoc:generate(entity=network_bridge, group=common, key=dns.nameservers)
 		//
 		// ---
@@ -572,6 +608,33 @@ func (n *bridge) Validate(config map[string]string, clientType request.ClientTyp
 		//  shortdesc: DNS zone name for IPv6 reverse DNS records
 		"dns.zone.reverse.ipv6": validate.IsAny,
 
+		// gendoc:generate(entity=network_bridge, group=common, key=flows.target)
+		//
+		// ---
+		//  type: string
+		//  condition: -
+		//  default: -
+		//  shortdesc: Comma-separated list of flow collectors (`<address>:<port>`) to export the network flows to
+		"flows.target": validate.Optional(validate.IsListOf(validate.IsListenAddress(false, false, true))),
+
+		// gendoc:generate(entity=network_bridge, group=common, key=flows.protocol)
+		//
+		// ---
+		//  type: string
+		//  condition: -
+		//  default: `ipfix`
+		//  shortdesc: Flow export protocol: `ipfix` or `sflow` (`sflow` requires the `openvswitch` bridge driver)
+		"flows.protocol": validate.Optional(validate.IsOneOf("ipfix", "sflow")),
+
+		// gendoc:generate(entity=network_bridge, group=common, key=flows.sampling)
+		//
+		// ---
+		//  type: integer
+		//  condition: -
+		//  default: `400` (`1` for the `native` bridge driver)
+		//  shortdesc: Export one in this many packets (`openvswitch` bridge driver) or flows (`native` bridge driver)
+		"flows.sampling": validate.Optional(validate.IsInRange(1, math.MaxUint32)),
+
 		// gendoc:generate(entity=network_bridge, group=common, key=raw.dnsmasq)
 		//
 		// ---
@@ -760,6 +823,14 @@ func (n *bridge) Validate(config map[string]string, clientType request.ClientTyp
 	// defaultdesc: `180`
 	// shortdesc: Peer session hold time (in seconds; optional)
 
+	// gendoc:generate(entity=network_bridge, group=bgp, key=bgp.peers.NAME.bfd)
+	//
+	// ---
+	// type: bool
+	// condition: BGP server
+	// defaultdesc: `false`
+	// shortdesc: Whether to monitor the peer session through BFD (failures detected in under a second)
+
 	// Add the BGP validation rules.
 	bgpRules, err := n.bgpValidationRules(config)
 	if err != nil {
@@ -768,6 +839,52 @@ func (n *bridge) Validate(config map[string]string, clientType request.ClientTyp
 
 	maps.Copy(rules, bgpRules)
 
+	// gendoc:generate(entity=network_bridge, group=qos, key=qos.bandwidth)
+	// The traffic sent to the instances through the bridge and the traffic they send to the host or through it are
+	// each shaped to this bandwidth and shared between the QoS classes.
+	// Required to use guaranteed or ceiling bandwidths in the QoS classes.
+	// ---
+	// type: string
+	// condition: -
+	// defaultdesc: -
+	// shortdesc: Total bandwidth in bit/s in each direction of the traffic of the instances (various suffixes supported, see {ref}`instances-limit-units`)
+	rules["qos.bandwidth"] = validate.Optional(func(value string) error {
+		_, err := qosParseBandwidth(value)
+		return err
+	})
+
+	// gendoc:generate(entity=network_bridge, group=qos, key=qos.classes.NAME.guaranteed)
+	// Applies separately to each direction and is shared by all the NICs of the class.
+	// ---
+	// type: string
+	// condition: -
+	// defaultdesc: -
+	// shortdesc: Bandwidth in bit/s guaranteed to the traffic of the NICs of the class
+
+	// gendoc:generate(entity=network_bridge, group=qos, key=qos.classes.NAME.ceiling)
+	// Applies separately to each direction and is shared by all the NICs of the class.
+	// ---
+	// type: string
+	// condition: -
+	// defaultdesc: `qos.bandwidth`
+	// shortdesc: Maximum bandwidth in bit/s of the traffic of the NICs of the class
+
+	// gendoc:generate(entity=network_bridge, group=qos, key=qos.classes.NAME.dscp)
+	//
+	// ---
+	// type: integer
+	// condition: -
+	// defaultdesc: -
+	// shortdesc: DSCP value (0-63) set on the traffic sent by the NICs of the class
+
+	// Add the QoS class validation rules.
+	qosRules, err := n.qosValidationRules(config, "guaranteed", "ceiling", "dscp")
+	if err != nil {
+		return err
+	}
+
+	maps.Copy(rules, qosRules)
+
 	// gendoc:generate(entity=network_bridge, group=common, key=user.*)
 	//
 	// ---
@@ -811,6 +928,31 @@ func (n *bridge) Validate(config map[string]string, clientType request.ClientTyp
 		}
 	}
 
+	// Check the QoS classes fit in the bandwidth of the network.
+	var bandwidth int64
+	if config["qos.bandwidth"] != "" {
+		bandwidth, _ = qosParseBandwidth(config["qos.bandwidth"])
+	}
+
+	classes := qosClasses(config)
+	if bandwidth == 0 {
+		for _, class := range classes {
+			if class.guaranteed > 0 || class.ceiling > 0 {
+				return fmt.Errorf("QoS class %q requires %q to be set", class.name, "qos.bandwidth")
+			}
+		}
+	}
+
+	err = qosValidate(classes, bandwidth)
+	if err != nil {
+		return err
+	}
+
+	// Check the flow export protocol is supported by the bridge driver.
+	if config["flows.protocol"] == "sflow" && config["bridge.driver"] != "openvswitch" {
+		return errors.New("sFlow export requires the openvswitch bridge driver")
+	}
+
 	// Check using same MAC address on every cluster node is safe.
 	if config["bridge.hwaddr"] != "" {
 		err = n.checkClusterWideMACSafe(config)
@@ -887,6 +1029,12 @@ func (n *bridge) Validate(config map[string]string, clientType request.ClientTyp
 		}
 	}
 
+	// Check the reserved ranges are within the network subnets.
+	err = n.validateReservedRanges(config, "ipv4", "ipv6")
+	if err != nil {
+		return err
+	}
+
 	// Check Security ACLs are supported and exist.
 	if config["security.acls"] != "" {
 		err = acl.Exists(n.state, n.Project(), util.SplitNTrimSpace(config["security.acls"], ",", -1, true)...)
@@ -950,7 +1098,25 @@ func (n *bridge) Delete(clientType request.ClientType) error {
 		return err
 	}
 
-	return n.delete(clientType)
+	tunnels, err := n.remotePeerTunnels()
+	if err != nil {
+		return err
+	}
+
+	err = n.delete(clientType)
+	if err != nil {
+		return err
+	}
+
+	// Remove the WireGuard tunnels no longer used once the remote peers are gone.
+	if len(tunnels) > 0 {
+		err = WireGuardApply(n.state)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // Rename renames a network.
@@ -1489,13 +1655,14 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 				expiry = n.config["ipv4.dhcp.expiry"]
 			}
 
-			if n.config["ipv4.dhcp.ranges"] != "" {
-				for _, dhcpRange := range strings.Split(n.config["ipv4.dhcp.ranges"], ",") {
-					dhcpRange = strings.TrimSpace(dhcpRange)
-					dnsmasqCmd = append(dnsmasqCmd, []string{"--dhcp-range", fmt.Sprintf("%s,%s", strings.ReplaceAll(dhcpRange, "-", ","), expiry)}...)
-				}
-			} else {
-				dnsmasqCmd = append(dnsmasqCmd, []string{"--dhcp-range", fmt.Sprintf("%s,%s,%s", dhcpalloc.GetIP(subnet, 2).String(), dhcpalloc.GetIP(subnet, -2).String(), expiry)}...)
+			dhcpRanges := n.DHCPv4Ranges()
+			if len(dhcpRanges) == 0 {
+				dhcpRanges = []iprange.Range{{Start: dhcpalloc.GetIP(subnet, 2).To4(), End: dhcpalloc.GetIP(subnet, -2).To4()}}
+			}
+
+			// Keep the reserved ranges out of the dynamic pool.
+			for _, dhcpRange := range subtractRanges(dhcpRanges, n.ReservedIPv4Ranges()) {
+				dnsmasqCmd = append(dnsmasqCmd, []string{"--dhcp-range", fmt.Sprintf("%s,%s,%s", dhcpRange.Start.String(), dhcpRange.End.String(), expiry)}...)
 			}
 		}
 
@@ -1654,13 +1821,14 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 			}
 
 			if util.IsTrue(n.config["ipv6.dhcp.stateful"]) {
-				if n.config["ipv6.dhcp.ranges"] != "" {
-					for _, dhcpRange := range strings.Split(n.config["ipv6.dhcp.ranges"], ",") {
-						dhcpRange = strings.TrimSpace(dhcpRange)
-						dnsmasqCmd = append(dnsmasqCmd, []string{"--dhcp-range", fmt.Sprintf("%s,%d,%s", strings.ReplaceAll(dhcpRange, "-", ","), subnetSize, expiry)}...)
-					}
-				} else {
-					dnsmasqCmd = append(dnsmasqCmd, []string{"--dhcp-range", fmt.Sprintf("%s,%s,%d,%s", dhcpalloc.GetIP(subnet, 2), dhcpalloc.GetIP(subnet, -1), subnetSize, expiry)}...)
+				dhcpRanges := n.DHCPv6Ranges()
+				if len(dhcpRanges) == 0 {
+					dhcpRanges = []iprange.Range{{Start: dhcpalloc.GetIP(subnet, 2).To16(), End: dhcpalloc.GetIP(subnet, -1).To16()}}
+				}
+
+				// Keep the reserved ranges out of the dynamic pool.
+				for _, dhcpRange := range subtractRanges(dhcpRanges, n.ReservedIPv6Ranges()) {
+					dnsmasqCmd = append(dnsmasqCmd, []string{"--dhcp-range", fmt.Sprintf("%s,%s,%d,%s", dhcpRange.Start.String(), dhcpRange.End.String(), subnetSize, expiry)}...)
 				}
 			} else if util.IsTrueOrEmpty(n.config["ipv6.ra"]) {
 				dnsmasqCmd = append(dnsmasqCmd, []string{"--dhcp-range", fmt.Sprintf("::,constructor:%s,ra-stateless,ra-names", n.name)}...)
@@ -2088,12 +2256,42 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		return err
 	}
 
+	// Setup network load balancers.
+	err = n.loadBalancerSetupFirewall()
+	if err != nil {
+		return err
+	}
+
+	// Setup network egress gateways.
+	err = n.egressGatewaySetupFirewall()
+	if err != nil {
+		return err
+	}
+
+	// Setup QoS classes.
+	err = n.qosSetup()
+	if err != nil {
+		return err
+	}
+
+	// Setup network peers (the rules of the peers depend on the subnets of this network).
+	err = n.peerRefresh()
+	if err != nil {
+		n.logger.Warn("Failed applying network peers", logger.Ctx{"err": err})
+	}
+
 	// Setup BGP.
 	err = n.bgpSetup(oldConfig)
 	if err != nil {
 		return err
 	}
 
+	// Setup flow export.
+	err = n.flowsSetup()
+	if err != nil {
+		return fmt.Errorf("Failed setting up flow export: %w", err)
+	}
+
 	reverter.Success()
 
 	return nil
@@ -2113,6 +2311,23 @@ func (n *bridge) Stop() error {
 		return err
 	}
 
+	// Stop load balancer health checks.
+	healthCheckStop(n.ID())
+
+	// Stop flow export.
+	err = flowsExporterUpdate(n.ID(), nil, "")
+	if err != nil {
+		return err
+	}
+
+	// Remove the QoS ifb device.
+	if InterfaceExists(n.qosIfbName()) {
+		err = InterfaceRemove(n.qosIfbName())
+		if err != nil {
+			return fmt.Errorf("Failed removing QoS ifb device: %w", err)
+		}
+	}
+
 	err = n.deleteChildren()
 	if err != nil {
 		return fmt.Errorf("Failed to delete bridge children interfaces: %w", err)
@@ -2611,6 +2826,7 @@ func (n *bridge) getExternalSubnetInUse() ([]externalSubnetUsage, error) {
 	var err error
 	var projectNetworks map[string]map[int64]api.Network
 	var projectNetworksForwardsOnUplink map[string]map[int64][]string
+	projectNetworksLoadBalancers := make(map[string]map[int64][]string)
 	var externalSubnets []externalSubnetUsage
 
 	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
@@ -2652,6 +2868,22 @@ func (n *bridge) getExternalSubnetInUse() ([]externalSubnetUsage, error) {
 						projectNetworksForwardsOnUplink[projectName][networkID] = append(projectNetworksForwardsOnUplink[projectName][networkID], forward.ListenAddress)
 					}
 				}
+
+				// Get all load balancer listen addresses, bridge load balancers apply to all cluster members.
+				networkLoadBalancers, err := dbCluster.GetNetworkLoadBalancers(ctx, tx.Tx(), dbCluster.NetworkLoadBalancerFilter{
+					NetworkID: &networkID,
+				})
+				if err != nil {
+					return fmt.Errorf("Failed loading network load balancer listen addresses: %w", err)
+				}
+
+				for _, loadBalancer := range networkLoadBalancers {
+					if projectNetworksLoadBalancers[projectName] == nil {
+						projectNetworksLoadBalancers[projectName] = make(map[int64][]string)
+					}
+
+					projectNetworksLoadBalancers[projectName][networkID] = append(projectNetworksLoadBalancers[projectName][networkID], loadBalancer.ListenAddress)
+				}
 			}
 		}
 
@@ -2743,6 +2975,25 @@ func (n *bridge) getExternalSubnetInUse() ([]externalSubnetUsage, error) {
 		}
 	}
 
+	// Add load balancer listen addresses to this list.
+	for projectName, networks := range projectNetworksLoadBalancers {
+		for networkID, listenAddresses := range networks {
+			for _, listenAddress := range listenAddresses {
+				listenAddressNet, err := ParseIPToNet(listenAddress)
+				if err != nil {
+					return nil, fmt.Errorf("Invalid existing load balancer listen address %q", listenAddress)
+				}
+
+				externalSubnets = append(externalSubnets, externalSubnetUsage{
+					subnet:         *listenAddressNet,
+					networkProject: projectName,
+					networkName:    projectNetworks[projectName][networkID].Name,
+					usageType:      subnetUsageNetworkLoadBalancer,
+				})
+			}
+		}
+	}
+
 	return externalSubnets, nil
 }
 
@@ -3312,91 +3563,1970 @@ func (n *bridge) forwardSetupFirewall() error {
 	return nil
 }
 
-// Leases returns a list of leases for the bridged network. It will reach out to other cluster members as needed.
-// The projectName passed here refers to the initial project from the API request which may differ from the network's project.
-func (n *bridge) Leases(projectName string, clientType request.ClientType) ([]api.NetworkLease, error) {
-	var err error
-	var projectMacs []string
-	leases := []api.NetworkLease{}
+// loadBalancerSetupFirewall applies all network load balancers defined for this network and updates the health
+// checks of their backends. Backends found offline by the health checks are left out.
+func (n *bridge) loadBalancerSetupFirewall() error {
+	var loadBalancers []*api.NetworkLoadBalancer
 
-	// Get all static leases.
-	if clientType == request.ClientTypeNormal {
-		// If requested project matches network's project then include gateway and downstream uplink IPs.
-		if projectName == n.project {
-			// Add our own gateway IPs.
-			for _, addr := range []string{n.config["ipv4.address"], n.config["ipv6.address"]} {
-				ipAddress, _, _ := net.ParseCIDR(addr)
-				if ipAddress != nil {
-					leases = append(leases, api.NetworkLease{
-						Hostname: fmt.Sprintf("%s.gw", n.Name()),
-						Address:  ipAddress.String(),
-						Type:     "gateway",
-					})
-				}
-			}
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		networkID := n.ID()
+		dbRecords, err := dbCluster.GetNetworkLoadBalancers(ctx, tx.Tx(), dbCluster.NetworkLoadBalancerFilter{
+			NetworkID: &networkID,
+		})
+		if err != nil {
+			return err
+		}
 
-			// Include downstream OVN routers using the network as an uplink.
-			var projectNetworks map[string]map[int64]api.Network
-			err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-				projectNetworks, err = tx.GetCreatedNetworks(ctx)
-				return err
-			})
+		for _, dbRecord := range dbRecords {
+			loadBalancer, err := dbRecord.ToAPI(ctx, tx.Tx())
 			if err != nil {
-				return nil, err
+				return err
 			}
 
-			// Look for networks using the current network as an uplink.
-			for projectName, networks := range projectNetworks {
-				for _, network := range networks {
-					if network.Config["network"] != n.name {
-						continue
-					}
+			loadBalancers = append(loadBalancers, loadBalancer)
+		}
 
-					// Found a network, add leases.
-					for _, k := range []string{"volatile.network.ipv4.address", "volatile.network.ipv6.address"} {
-						v := network.Config[k]
-						if v != "" {
-							leases = append(leases, api.NetworkLease{
-								Hostname: fmt.Sprintf("%s-%s.uplink", projectName, network.Name),
-								Address:  v,
-								Type:     "uplink",
-							})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading network load balancers: %w", err)
+	}
+
+	var fwLoadBalancers []firewallDrivers.LoadBalancer
+	healthCheckTargets := map[healthCheckTarget]healthCheckConfig{}
+
+	for _, loadBalancer := range loadBalancers {
+		listenAddress := net.ParseIP(loadBalancer.ListenAddress)
+
+		portMaps, err := n.loadBalancerValidate(listenAddress, &loadBalancer.NetworkLoadBalancerPut)
+		if err != nil {
+			return fmt.Errorf("Failed validating load balancer for listen address %q: %w", loadBalancer.ListenAddress, err)
+		}
+
+		healthCheck, err := parseHealthCheckConfig(loadBalancer.Config)
+		if err != nil {
+			return fmt.Errorf("Failed parsing health check of load balancer for listen address %q: %w", loadBalancer.ListenAddress, err)
+		}
+
+		for _, portMap := range portMaps {
+			for i, listenPort := range portMap.listenPorts {
+				fwLoadBalancer := firewallDrivers.LoadBalancer{
+					ListenAddress: listenAddress,
+					Protocol:      portMap.protocol,
+					ListenPort:    listenPort,
+				}
+
+				for _, target := range portMap.targets {
+					targetPort := portMap.targetPort(target, i)
+
+					if healthCheck != nil {
+						checkTarget := healthCheckTarget{
+							protocol: portMap.protocol,
+							address:  target.address.String(),
+							port:     targetPort,
+						}
+
+						healthCheckTargets[checkTarget] = *healthCheck
+
+						if healthCheckStatus(n.ID(), checkTarget) == healthStatusOffline {
+							continue
 						}
 					}
+
+					fwLoadBalancer.Targets = append(fwLoadBalancer.Targets, firewallDrivers.LoadBalancerTarget{
+						Address: target.address,
+						Port:    targetPort,
+					})
 				}
+
+				fwLoadBalancers = append(fwLoadBalancers, fwLoadBalancer)
 			}
 		}
+	}
 
-		// Get all the instances in the requested project that are connected to this network.
-		filter := dbCluster.InstanceFilter{Project: &projectName}
-		err = UsedByInstanceDevices(n.state, n.Project(), n.Name(), n.Type(), func(inst db.InstanceArgs, nicName string, nicConfig map[string]string) error {
-			// Fill in the hwaddr from volatile.
-			if nicConfig["hwaddr"] == "" {
-				nicConfig["hwaddr"] = inst.Config[fmt.Sprintf("volatile.%s.hwaddr", nicName)]
-			}
+	// Re-apply the load balancers whenever a backend goes online or offline.
+	s := n.state
+	projectName := n.project
+	networkName := n.name
 
-			// Record the MAC.
-			hwAddr, _ := net.ParseMAC(nicConfig["hwaddr"])
-			if hwAddr != nil {
-				projectMacs = append(projectMacs, hwAddr.String())
-			}
+	healthCheckUpdate(n.ID(), healthCheckTargets, func() {
+		netw, err := LoadByName(s, projectName, networkName)
+		if err != nil {
+			return
+		}
 
-			// Add the lease.
-			nicIP4 := net.ParseIP(nicAddressIP(nicConfig["ipv4.address"]))
-			if nicIP4 != nil {
-				leases = append(leases, api.NetworkLease{
-					Hostname: inst.Name,
-					Address:  nicIP4.String(),
-					Hwaddr:   hwAddr.String(),
-					Type:     "static",
-					Location: inst.Node,
-				})
-			}
+		bridgeNet, ok := netw.(*bridge)
+		if !ok {
+			return
+		}
 
-			nicIP6 := net.ParseIP(nicAddressIP(nicConfig["ipv6.address"]))
-			if nicIP6 != nil {
-				leases = append(leases, api.NetworkLease{
-					Hostname: inst.Name,
+		err = bridgeNet.loadBalancerSetupFirewall()
+		if err != nil {
+			bridgeNet.logger.Warn("Failed applying load balancers after backend health change", logger.Ctx{"err": err})
+		}
+	})
+
+	err = n.state.Firewall.NetworkApplyLoadBalancers(n.name, fwLoadBalancers)
+	if err != nil {
+		return fmt.Errorf("Failed applying firewall load balancers: %w", err)
+	}
+
+	return nil
+}
+
+// LoadBalancerCreate creates a network load balancer.
+// Load balancers apply to all cluster members, each spreading connections across the backends it can reach.
+func (n *bridge) LoadBalancerCreate(loadBalancer api.NetworkLoadBalancersPost, clientType request.ClientType) error {
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	if clientType == request.ClientTypeNormal {
+		err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			// Check if there is an existing load balancer using the same listen address.
+			_, err := dbCluster.GetNetworkLoadBalancer(ctx, tx.Tx(), n.ID(), loadBalancer.ListenAddress)
+
+			return err
+		})
+		if err == nil {
+			return api.StatusErrorf(http.StatusConflict, "A load balancer for that listen address already exists")
+		}
+
+		// Convert listen address to subnet so we can check its valid and can be used.
+		listenAddressNet, err := ParseIPToNet(loadBalancer.ListenAddress)
+		if err != nil {
+			return fmt.Errorf("Failed parsing load balancer listen address %q: %w", loadBalancer.ListenAddress, err)
+		}
+
+		_, err = n.loadBalancerValidate(listenAddressNet.IP, &loadBalancer.NetworkLoadBalancerPut)
+		if err != nil {
+			return err
+		}
+
+		externalSubnetsInUse, err := n.getExternalSubnetInUse()
+		if err != nil {
+			return err
+		}
+
+		// Check the listen address subnet doesn't fall within any existing network external subnets.
+		for _, externalSubnetUser := range externalSubnetsInUse {
+			// Check if usage is from our own network.
+			if externalSubnetUser.networkProject == n.project && externalSubnetUser.networkName == n.name {
+				// Skip checking conflict with our own network's subnet or SNAT address.
+				// But do not allow other conflict with other usage types within our own network.
+				if externalSubnetUser.usageType == subnetUsageNetwork || externalSubnetUser.usageType == subnetUsageNetworkSNAT {
+					continue
+				}
+			}
+
+			if SubnetContains(&externalSubnetUser.subnet, listenAddressNet) || SubnetContains(listenAddressNet, &externalSubnetUser.subnet) {
+				// This error is purposefully vague so that it doesn't reveal any names of
+				// resources potentially outside of the network.
+				return fmt.Errorf("Load balancer listen address %q overlaps with another network or NIC", listenAddressNet.String())
+			}
+		}
+
+		var loadBalancerID int64
+
+		err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			// Create load balancer DB record.
+			lb := dbCluster.NetworkLoadBalancer{
+				NetworkID:     n.ID(),
+				ListenAddress: loadBalancer.ListenAddress,
+				Description:   loadBalancer.Description,
+				Backends:      loadBalancer.Backends,
+				Ports:         loadBalancer.Ports,
+			}
+
+			loadBalancerID, err = dbCluster.CreateNetworkLoadBalancer(ctx, tx.Tx(), lb)
+			if err != nil {
+				return err
+			}
+
+			// Save the load balancer configuration.
+			return dbCluster.CreateNetworkLoadBalancerConfig(ctx, tx.Tx(), loadBalancerID, loadBalancer.Config)
+		})
+		if err != nil {
+			return err
+		}
+
+		reverter.Add(func() {
+			_ = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+				return dbCluster.DeleteNetworkLoadBalancer(ctx, tx.Tx(), n.ID(), loadBalancerID)
+			})
+
+			_ = n.loadBalancerSetupFirewall()
+		})
+	}
+
+	err := n.loadBalancerSetupFirewall()
+	if err != nil {
+		return err
+	}
+
+	if clientType == request.ClientTypeNormal {
+		// Notify all other members to apply the load balancer.
+		notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAll)
+		if err != nil {
+			return err
+		}
+
+		err = notifier(func(client incus.InstanceServer) error {
+			return client.UseProject(n.project).CreateNetworkLoadBalancer(n.name, loadBalancer)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	reverter.Success()
+
+	return nil
+}
+
+// LoadBalancerUpdate updates a network load balancer.
+func (n *bridge) LoadBalancerUpdate(listenAddress string, req api.NetworkLoadBalancerPut, clientType request.ClientType) error {
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	if clientType == request.ClientTypeNormal {
+		var curLoadBalancer *api.NetworkLoadBalancer
+		var curLoadBalancerID int64
+
+		err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			networkID := n.ID()
+
+			// Get the load balancer.
+			dbLoadBalancers, err := dbCluster.GetNetworkLoadBalancers(ctx, tx.Tx(), dbCluster.NetworkLoadBalancerFilter{
+				NetworkID:     &networkID,
+				ListenAddress: &listenAddress,
+			})
+			if err != nil {
+				return err
+			}
+
+			if len(dbLoadBalancers) != 1 {
+				return api.StatusErrorf(http.StatusNotFound, "Network load balancer not found")
+			}
+
+			curLoadBalancer, err = dbLoadBalancers[0].ToAPI(ctx, tx.Tx())
+			if err != nil {
+				return err
+			}
+
+			curLoadBalancerID = dbLoadBalancers[0].ID
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = n.loadBalancerValidate(net.ParseIP(curLoadBalancer.ListenAddress), &req)
+		if err != nil {
+			return err
+		}
+
+		curEtagHash, err := localUtil.EtagHash(curLoadBalancer.Etag())
+		if err != nil {
+			return err
+		}
+
+		newLoadBalancer := api.NetworkLoadBalancer{
+			ListenAddress:          curLoadBalancer.ListenAddress,
+			NetworkLoadBalancerPut: req,
+		}
+
+		newEtagHash, err := localUtil.EtagHash(newLoadBalancer.Etag())
+		if err != nil {
+			return err
+		}
+
+		if curEtagHash == newEtagHash {
+			return nil // Nothing has changed.
+		}
+
+		updateRecord := func(lb *api.NetworkLoadBalancer) error {
+			return n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+				dbRecord := dbCluster.NetworkLoadBalancer{
+					NetworkID:     n.ID(),
+					ListenAddress: listenAddress,
+					Description:   lb.Description,
+					Backends:      lb.Backends,
+					Ports:         lb.Ports,
+				}
+
+				err := dbCluster.UpdateNetworkLoadBalancer(ctx, tx.Tx(), n.ID(), listenAddress, dbRecord)
+				if err != nil {
+					return err
+				}
+
+				return dbCluster.UpdateNetworkLoadBalancerConfig(ctx, tx.Tx(), curLoadBalancerID, lb.Config)
+			})
+		}
+
+		err = updateRecord(&newLoadBalancer)
+		if err != nil {
+			return err
+		}
+
+		reverter.Add(func() {
+			_ = updateRecord(curLoadBalancer)
+			_ = n.loadBalancerSetupFirewall()
+		})
+	}
+
+	err := n.loadBalancerSetupFirewall()
+	if err != nil {
+		return err
+	}
+
+	if clientType == request.ClientTypeNormal {
+		// Notify all other members to apply the load balancer changes.
+		notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAll)
+		if err != nil {
+			return err
+		}
+
+		err = notifier(func(client incus.InstanceServer) error {
+			return client.UseProject(n.project).UpdateNetworkLoadBalancer(n.name, listenAddress, req, "")
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	reverter.Success()
+
+	return nil
+}
+
+// LoadBalancerState returns the current state of the load balancer, as seen from this cluster member.
+func (n *bridge) LoadBalancerState(lb api.NetworkLoadBalancer) (*api.NetworkLoadBalancerState, error) {
+	lbState := &api.NetworkLoadBalancerState{}
+
+	if !util.IsTrue(lb.Config["healthcheck"]) {
+		return lbState, nil
+	}
+
+	portMaps, err := n.loadBalancerValidate(net.ParseIP(lb.ListenAddress), &lb.NetworkLoadBalancerPut)
+	if err != nil {
+		return nil, err
+	}
+
+	lbState.BackendHealth = map[string]api.NetworkLoadBalancerStateBackendHealth{}
+
+	for _, backend := range lb.Backends {
+		backendHealth := api.NetworkLoadBalancerStateBackendHealth{
+			Address: backend.TargetAddress,
+			Ports:   []api.NetworkLoadBalancerStateBackendHealthPort{},
+		}
+
+		for portIndex, portMap := range portMaps {
+			// Targets are in the same order as the backends of the port specification.
+			for targetIndex, backendName := range lb.Ports[portIndex].TargetBackend {
+				if backendName != backend.Name {
+					continue
+				}
+
+				target := portMap.targets[targetIndex]
+
+				for i, listenPort := range portMap.listenPorts {
+					status := healthCheckStatus(n.ID(), healthCheckTarget{
+						protocol: portMap.protocol,
+						address:  target.address.String(),
+						port:     portMap.targetPort(target, i),
+					})
+
+					backendHealth.Ports = append(backendHealth.Ports, api.NetworkLoadBalancerStateBackendHealthPort{
+						Protocol: portMap.protocol,
+						Port:     int(listenPort),
+						Status:   status,
+					})
+				}
+			}
+		}
+
+		lbState.BackendHealth[backend.Name] = backendHealth
+	}
+
+	return lbState, nil
+}
+
+// LoadBalancerDelete deletes a network load balancer.
+func (n *bridge) LoadBalancerDelete(listenAddress string, clientType request.ClientType) error {
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	if clientType == request.ClientTypeNormal {
+		var lb *api.NetworkLoadBalancer
+		var lbID int64
+
+		err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			networkID := n.ID()
+
+			dbLoadBalancers, err := dbCluster.GetNetworkLoadBalancers(ctx, tx.Tx(), dbCluster.NetworkLoadBalancerFilter{
+				NetworkID:     &networkID,
+				ListenAddress: &listenAddress,
+			})
+			if err != nil {
+				return err
+			}
+
+			if len(dbLoadBalancers) != 1 {
+				return api.StatusErrorf(http.StatusNotFound, "Network load balancer not found")
+			}
+
+			lbID = dbLoadBalancers[0].ID
+			lb, err = dbLoadBalancers[0].ToAPI(ctx, tx.Tx())
+
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return dbCluster.DeleteNetworkLoadBalancer(ctx, tx.Tx(), n.ID(), lbID)
+		})
+		if err != nil {
+			return err
+		}
+
+		reverter.Add(func() {
+			_ = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+				dbRecord := dbCluster.NetworkLoadBalancer{
+					NetworkID:     n.ID(),
+					ListenAddress: lb.ListenAddress,
+					Description:   lb.Description,
+					Backends:      lb.Backends,
+					Ports:         lb.Ports,
+				}
+
+				lbID, err := dbCluster.CreateNetworkLoadBalancer(ctx, tx.Tx(), dbRecord)
+				if err != nil {
+					return err
+				}
+
+				return dbCluster.CreateNetworkLoadBalancerConfig(ctx, tx.Tx(), lbID, lb.Config)
+			})
+
+			_ = n.loadBalancerSetupFirewall()
+		})
+	}
+
+	err := n.loadBalancerSetupFirewall()
+	if err != nil {
+		return err
+	}
+
+	if clientType == request.ClientTypeNormal {
+		// Notify all other members to remove the load balancer.
+		notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAll)
+		if err != nil {
+			return err
+		}
+
+		err = notifier(func(client incus.InstanceServer) error {
+			return client.UseProject(n.project).DeleteNetworkLoadBalancer(n.name, listenAddress)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	reverter.Success()
+
+	return nil
+}
+
+// egressGatewaySetupFirewall applies all network egress gateways defined for this network.
+func (n *bridge) egressGatewaySetupFirewall() error {
+	gateways, err := n.egressGatewaysLoad()
+	if err != nil {
+		return err
+	}
+
+	fwGateways := make([]firewallDrivers.EgressGateway, 0, len(gateways))
+	gatewayIndex := make(map[string]int, len(gateways))
+	for i, gateway := range gateways {
+		fwGateways = append(fwGateways, firewallDrivers.EgressGateway{Address: net.ParseIP(gateway.Address)})
+		gatewayIndex[gateway.Address] = i
+	}
+
+	// Find the MAC addresses of the instance NICs using each gateway.
+	if len(gateways) > 0 {
+		err = UsedByInstanceDevices(n.state, n.project, n.name, n.netType, func(inst db.InstanceArgs, nicName string, nicConfig map[string]string) error {
+			hwAddr := inst.Config[fmt.Sprintf("volatile.%s.hwaddr", nicName)]
+			if nicConfig["hwaddr"] != "" {
+				hwAddr = nicConfig["hwaddr"]
+			}
+
+			// The MAC address is only known once the instance was started.
+			if hwAddr == "" {
+				return nil
+			}
+
+			for _, ipv6 := range []bool{false, true} {
+				gateway := egressGatewaySelect(gateways, n.project, &inst, ipv6)
+				if gateway == nil {
+					continue
+				}
+
+				i := gatewayIndex[gateway.Address]
+				fwGateways[i].HwAddrs = append(fwGateways[i].HwAddrs, hwAddr)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("Failed finding instances using egress gateways: %w", err)
+		}
+	}
+
+	err = n.state.Firewall.NetworkApplyEgressGateways(n.name, fwGateways)
+	if err != nil {
+		return fmt.Errorf("Failed applying firewall egress gateways: %w", err)
+	}
+
+	return nil
+}
+
+// EgressGatewayRefresh re-applies the network egress gateways so that they cover the MAC addresses of the
+// instance NICs started since. Nothing is done if the network has no egress gateway.
+func (n *bridge) EgressGatewayRefresh() error {
+	gateways, err := n.egressGatewaysLoad()
+	if err != nil {
+		return err
+	}
+
+	if len(gateways) == 0 {
+		return nil
+	}
+
+	return n.egressGatewaySetupFirewall()
+}
+
+// qosIfbName returns the name of the ifb device shaping the traffic sent by the instance NICs of the network.
+func (n *bridge) qosIfbName() string {
+	return fmt.Sprintf("incusqos%d", n.id)
+}
+
+// qosClear removes the traffic control hierarchies of the QoS classes.
+func (n *bridge) qosClear() error {
+	qdiscHTB := &ip.QdiscHTB{Qdisc: ip.Qdisc{Dev: n.name, Handle: "1:0", Parent: "root"}}
+	err := qdiscHTB.Delete()
+	if err != nil && !errors.Is(err, unix.ENOENT) {
+		return fmt.Errorf("Failed clearing QoS classes: %w", err)
+	}
+
+	qdiscIngress := &ip.QdiscIngress{Qdisc: ip.Qdisc{Dev: n.name, Handle: "ffff:0"}}
+	err = qdiscIngress.Delete()
+	if err != nil && !errors.Is(err, unix.ENOENT) {
+		return fmt.Errorf("Failed clearing QoS ingress redirection: %w", err)
+	}
+
+	if InterfaceExists(n.qosIfbName()) {
+		err = InterfaceRemove(n.qosIfbName())
+		if err != nil {
+			return fmt.Errorf("Failed removing QoS ifb device: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// qosSetupHTB creates the traffic control hierarchy of the QoS classes on the device.
+func (n *bridge) qosSetupHTB(dev string, classes []qosClass, bandwidth int64) error {
+	// Neither the bridge nor the ifb device have a transmit queue by default which the leaf classes need.
+	link := &ip.Link{Name: dev}
+	err := link.SetTXQueueLength(1000)
+	if err != nil {
+		return fmt.Errorf("Failed setting transmit queue length: %w", err)
+	}
+
+	qdiscHTB := &ip.QdiscHTB{Qdisc: ip.Qdisc{Dev: dev, Handle: "1:0", Parent: "root"}, Default: qosDefaultClassMinor}
+	err = qdiscHTB.Add()
+	if err != nil {
+		return fmt.Errorf("Failed creating QoS root qdisc: %w", err)
+	}
+
+	htbClasses := []*ip.ClassHTB{
+		{Class: ip.Class{Dev: dev, Parent: "1:0", Classid: "1:1"}, Rate: fmt.Sprintf("%dbit", bandwidth), Ceil: fmt.Sprintf("%dbit", bandwidth)},
+	}
+
+	// The traffic outside of any class shares what isn't guaranteed to the classes.
+	defaultRate := bandwidth
+	for _, class := range classes {
+		defaultRate -= class.guaranteed
+	}
+
+	htbClasses = append(htbClasses, &ip.ClassHTB{Class: ip.Class{Dev: dev, Parent: "1:1", Classid: fmt.Sprintf("1:%x", qosDefaultClassMinor)}, Rate: fmt.Sprintf("%dbit", max(defaultRate, qosMinimumRate)), Ceil: fmt.Sprintf("%dbit", bandwidth)})
+
+	for _, class := range classes {
+		rate := max(class.guaranteed, qosMinimumRate)
+		ceil := bandwidth
+		if class.ceiling > 0 {
+			ceil = max(class.ceiling, rate)
+		}
+
+		htbClasses = append(htbClasses, &ip.ClassHTB{Class: ip.Class{Dev: dev, Parent: "1:1", Classid: fmt.Sprintf("1:%x", qosClassMinor(class.name))}, Rate: fmt.Sprintf("%dbit", rate), Ceil: fmt.Sprintf("%dbit", ceil)})
+	}
+
+	for _, htbClass := range htbClasses {
+		err = htbClass.Add()
+		if err != nil {
+			return fmt.Errorf("Failed creating QoS class %q: %w", htbClass.Classid, err)
+		}
+	}
+
+	return nil
+}
+
+// qosSetup sets up the traffic control hierarchies of the QoS classes and the firewall rules classifying the
+// traffic of the instance NICs in them. The traffic sent to the instances is shaped on the bridge. The traffic
+// they send, which the bridge receives, is redirected to an ifb device and shaped there.
+func (n *bridge) qosSetup() error {
+	// Clear any existing hierarchy.
+	err := n.qosClear()
+	if err != nil {
+		return err
+	}
+
+	classes := qosClasses(n.config)
+
+	var bandwidth int64
+	if n.config["qos.bandwidth"] != "" {
+		bandwidth, err = qosParseBandwidth(n.config["qos.bandwidth"])
+		if err != nil {
+			return fmt.Errorf("Invalid %q: %w", "qos.bandwidth", err)
+		}
+	}
+
+	if bandwidth > 0 {
+		reverter := revert.New()
+		defer reverter.Fail()
+
+		reverter.Add(func() { _ = n.qosClear() })
+
+		err = n.qosSetupHTB(n.name, classes, bandwidth)
+		if err != nil {
+			return err
+		}
+
+		ifb := &ip.Ifb{Link: ip.Link{Name: n.qosIfbName()}}
+		err = ifb.Add()
+		if err != nil {
+			return fmt.Errorf("Failed creating QoS ifb device: %w", err)
+		}
+
+		err = ifb.SetUp()
+		if err != nil {
+			return fmt.Errorf("Failed bringing up QoS ifb device: %w", err)
+		}
+
+		err = n.qosSetupHTB(ifb.Name, classes, bandwidth)
+		if err != nil {
+			return err
+		}
+
+		qdiscIngress := &ip.QdiscIngress{Qdisc: ip.Qdisc{Dev: n.name, Handle: "ffff:0"}}
+		err = qdiscIngress.Add()
+		if err != nil {
+			return fmt.Errorf("Failed creating QoS ingress qdisc: %w", err)
+		}
+
+		filter := &ip.MatchallFilter{
+			Filter:   ip.Filter{Dev: n.name, Parent: "ffff:0", Protocol: "all"},
+			Priority: 1,
+			Actions:  []ip.Action{&ip.ActionMirred{Dev: ifb.Name, Redirect: true}},
+		}
+
+		err = filter.Add()
+		if err != nil {
+			return fmt.Errorf("Failed redirecting traffic to QoS ifb device: %w", err)
+		}
+
+		err = n.qosSetupFirewall(classes, true, "", "")
+		if err != nil {
+			return err
+		}
+
+		reverter.Success()
+
+		return nil
+	}
+
+	return n.qosSetupFirewall(classes, false, "", "")
+}
+
+// qosSetupFirewall applies the firewall rules classifying the traffic of the instance NICs in their QoS class.
+// The traffic of the NICs is only assigned to the traffic control class of their QoS class if shaped is true.
+// If hwAddr isn't empty, the NIC with that MAC address is put in className.
+func (n *bridge) qosSetupFirewall(classes []qosClass, shaped bool, hwAddr string, className string) error {
+	hwAddrs, err := n.qosClassHwAddrs(classes, hwAddr, className)
+	if err != nil {
+		return err
+	}
+
+	fwClasses := make([]firewallDrivers.QoSClass, 0, len(classes))
+	for _, class := range classes {
+		fwClass := firewallDrivers.QoSClass{
+			DSCP:    class.dscp,
+			HwAddrs: hwAddrs[class.name],
+		}
+
+		if shaped {
+			fwClass.Priority = fmt.Sprintf("1:%x", qosClassMinor(class.name))
+		}
+
+		fwClasses = append(fwClasses, fwClass)
+	}
+
+	err = n.state.Firewall.NetworkApplyQoS(n.name, fwClasses)
+	if err != nil {
+		return fmt.Errorf("Failed applying firewall QoS classes: %w", err)
+	}
+
+	if shaped {
+		err = n.qosSetupIfbFilters(classes, hwAddrs)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// qosSetupIfbFilters replaces the filters assigning the traffic sent by the instance NICs to the traffic control
+// class of their QoS class on the ifb device. The packets are matched on their source MAC address, which the
+// bridge keeps in front of their network header when redirecting them.
+func (n *bridge) qosSetupIfbFilters(classes []qosClass, hwAddrs map[string][]string) error {
+	ifbName := n.qosIfbName()
+
+	clearFilter := &ip.U32Filter{Filter: ip.Filter{Dev: ifbName, Parent: "1:0", Protocol: "all"}, Priority: 1}
+	err := clearFilter.Delete()
+	if err != nil && !errors.Is(err, unix.ENOENT) {
+		return fmt.Errorf("Failed clearing QoS filters: %w", err)
+	}
+
+	for _, class := range classes {
+		for _, hwAddr := range hwAddrs[class.name] {
+			mac, err := net.ParseMAC(hwAddr)
+			if err != nil || len(mac) != 6 {
+				return fmt.Errorf("Invalid MAC address %q", hwAddr)
+			}
+
+			filter := &ip.U32Filter{
+				Filter:   ip.Filter{Dev: ifbName, Parent: "1:0", Protocol: "all", Flowid: fmt.Sprintf("1:%x", qosClassMinor(class.name))},
+				Priority: 1,
+				Keys: []ip.U32Key{
+					{Value: binary.BigEndian.Uint32(mac[0:4]), Mask: 0xffffffff, Offset: -8},
+					{Value: uint32(mac[4])<<24 | uint32(mac[5])<<16, Mask: 0xffff0000, Offset: -4},
+				},
+			}
+
+			err = filter.Add()
+			if err != nil {
+				return fmt.Errorf("Failed adding QoS filter for %q: %w", hwAddr, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// QoSRefresh re-applies the firewall rules of the QoS classes so that they cover the instance NIC with
+// the MAC address, put in the QoS class, and the other NICs started since.
+// Nothing is done if the network has no QoS class.
+func (n *bridge) QoSRefresh(hwAddr string, className string) error {
+	classes := qosClasses(n.config)
+	if len(classes) == 0 {
+		return nil
+	}
+
+	return n.qosSetupFirewall(classes, n.config["qos.bandwidth"] != "", hwAddr, className)
+}
+
+// egressGatewayValidateAddress checks the egress gateway address doesn't conflict with any other external address.
+func (n *bridge) egressGatewayValidateAddress(address string) (*net.IPNet, error) {
+	addressNet, err := ParseIPToNet(address)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing egress gateway address %q: %w", address, err)
+	}
+
+	externalSubnetsInUse, err := n.getExternalSubnetInUse()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, externalSubnetUser := range externalSubnetsInUse {
+		// Skip checking conflict with our own network's subnet or SNAT address.
+		if externalSubnetUser.networkProject == n.project && externalSubnetUser.networkName == n.name {
+			if externalSubnetUser.usageType == subnetUsageNetwork || externalSubnetUser.usageType == subnetUsageNetworkSNAT {
+				continue
+			}
+		}
+
+		if SubnetContains(&externalSubnetUser.subnet, addressNet) || SubnetContains(addressNet, &externalSubnetUser.subnet) {
+			// This error is purposefully vague so that it doesn't reveal any names of
+			// resources potentially outside of the network.
+			return nil, fmt.Errorf("Egress gateway address %q overlaps with another network or NIC", addressNet.String())
+		}
+	}
+
+	return addressNet, nil
+}
+
+// EgressGatewayCreate creates a network egress gateway.
+// Egress gateways apply to all cluster members, the address must be routed to all of them.
+func (n *bridge) EgressGatewayCreate(gateway api.NetworkEgressGatewaysPost, clientType request.ClientType) error {
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	if clientType == request.ClientTypeNormal {
+		gateways, err := n.egressGatewaysLoad()
+		if err != nil {
+			return err
+		}
+
+		for _, other := range gateways {
+			if other.Address == gateway.Address {
+				return api.StatusErrorf(http.StatusConflict, "An egress gateway for that address already exists")
+			}
+		}
+
+		addressNet, err := n.egressGatewayValidateAddress(gateway.Address)
+		if err != nil {
+			return err
+		}
+
+		err = n.egressGatewayValidate(addressNet.IP, &gateway.NetworkEgressGatewayPut, gateways)
+		if err != nil {
+			return err
+		}
+
+		err = n.egressGatewayCreateRecord(&api.NetworkEgressGateway{
+			Address:                 gateway.Address,
+			NetworkEgressGatewayPut: gateway.NetworkEgressGatewayPut,
+		})
+		if err != nil {
+			return err
+		}
+
+		reverter.Add(func() {
+			_, _ = n.egressGatewayDeleteRecord(gateway.Address)
+			_ = n.egressGatewaySetupFirewall()
+		})
+	}
+
+	err := n.egressGatewaySetupFirewall()
+	if err != nil {
+		return err
+	}
+
+	if clientType == request.ClientTypeNormal {
+		// Notify all other members to apply the egress gateway.
+		notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAll)
+		if err != nil {
+			return err
+		}
+
+		err = notifier(func(client incus.InstanceServer) error {
+			return client.UseProject(n.project).CreateNetworkEgressGateway(n.name, gateway)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	reverter.Success()
+
+	return nil
+}
+
+// EgressGatewayUpdate updates a network egress gateway.
+func (n *bridge) EgressGatewayUpdate(address string, req api.NetworkEgressGatewayPut, clientType request.ClientType) error {
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	if clientType == request.ClientTypeNormal {
+		gateways, err := n.egressGatewaysLoad()
+		if err != nil {
+			return err
+		}
+
+		var curGateway *api.NetworkEgressGateway
+		for _, gateway := range gateways {
+			if gateway.Address == address {
+				curGateway = gateway
+				break
+			}
+		}
+
+		if curGateway == nil {
+			return api.StatusErrorf(http.StatusNotFound, "Network egress gateway not found")
+		}
+
+		err = n.egressGatewayValidate(net.ParseIP(curGateway.Address), &req, gateways)
+		if err != nil {
+			return err
+		}
+
+		curEtagHash, err := localUtil.EtagHash(curGateway.Etag())
+		if err != nil {
+			return err
+		}
+
+		newGateway := api.NetworkEgressGateway{
+			Address:                 curGateway.Address,
+			NetworkEgressGatewayPut: req,
+		}
+
+		newEtagHash, err := localUtil.EtagHash(newGateway.Etag())
+		if err != nil {
+			return err
+		}
+
+		if curEtagHash == newEtagHash {
+			return nil // Nothing has changed.
+		}
+
+		err = n.egressGatewayUpdateRecord(&newGateway)
+		if err != nil {
+			return err
+		}
+
+		reverter.Add(func() {
+			_ = n.egressGatewayUpdateRecord(curGateway)
+			_ = n.egressGatewaySetupFirewall()
+		})
+	}
+
+	err := n.egressGatewaySetupFirewall()
+	if err != nil {
+		return err
+	}
+
+	if clientType == request.ClientTypeNormal {
+		// Notify all other members to apply the egress gateway changes.
+		notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAll)
+		if err != nil {
+			return err
+		}
+
+		err = notifier(func(client incus.InstanceServer) error {
+			return client.UseProject(n.project).UpdateNetworkEgressGateway(n.name, address, req, "")
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	reverter.Success()
+
+	return nil
+}
+
+// EgressGatewayDelete deletes a network egress gateway.
+func (n *bridge) EgressGatewayDelete(address string, clientType request.ClientType) error {
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	if clientType == request.ClientTypeNormal {
+		gateway, err := n.egressGatewayDeleteRecord(address)
+		if err != nil {
+			return err
+		}
+
+		reverter.Add(func() {
+			_ = n.egressGatewayCreateRecord(gateway)
+			_ = n.egressGatewaySetupFirewall()
+		})
+	}
+
+	err := n.egressGatewaySetupFirewall()
+	if err != nil {
+		return err
+	}
+
+	if clientType == request.ClientTypeNormal {
+		// Notify all other members to remove the egress gateway.
+		notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAll)
+		if err != nil {
+			return err
+		}
+
+		err = notifier(func(client incus.InstanceServer) error {
+			return client.UseProject(n.project).DeleteNetworkEgressGateway(n.name, address)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	reverter.Success()
+
+	return nil
+}
+
+// LeaseCreate creates a network address reservation.
+// The reservation is applied to the DHCP server of all cluster members.
+func (n *bridge) LeaseCreate(lease api.NetworkLeasesPost, clientType request.ClientType) error {
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	if clientType == request.ClientTypeNormal {
+		leases, err := n.leasesLoad()
+		if err != nil {
+			return err
+		}
+
+		for _, other := range leases {
+			if other.Address == lease.Address {
+				return api.StatusErrorf(http.StatusConflict, "An address reservation for that address already exists")
+			}
+		}
+
+		err = n.leaseValidate(net.ParseIP(lease.Address), &lease.NetworkLeasePut, leases)
+		if err != nil {
+			return err
+		}
+
+		err = n.leaseCreateRecord(&api.NetworkLease{
+			Address:     lease.Address,
+			Hwaddr:      lease.Hwaddr,
+			Hostname:    lease.Hostname,
+			Description: lease.Description,
+		})
+		if err != nil {
+			return err
+		}
+
+		reverter.Add(func() {
+			_, _ = n.leaseDeleteRecord(lease.Address)
+			_ = UpdateDNSMasqStatic(n.state, n.name)
+		})
+	}
+
+	err := UpdateDNSMasqStatic(n.state, n.name)
+	if err != nil {
+		return err
+	}
+
+	if clientType == request.ClientTypeNormal {
+		// Notify all other members to apply the address reservation.
+		notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAll)
+		if err != nil {
+			return err
+		}
+
+		err = notifier(func(client incus.InstanceServer) error {
+			return client.UseProject(n.project).CreateNetworkLease(n.name, lease)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	reverter.Success()
+
+	return nil
+}
+
+// LeaseUpdate updates a network address reservation.
+func (n *bridge) LeaseUpdate(address string, req api.NetworkLeasePut, clientType request.ClientType) error {
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	if clientType == request.ClientTypeNormal {
+		leases, err := n.leasesLoad()
+		if err != nil {
+			return err
+		}
+
+		var curLease *api.NetworkLease
+		for _, lease := range leases {
+			if lease.Address == address {
+				curLease = lease
+				break
+			}
+		}
+
+		if curLease == nil {
+			return api.StatusErrorf(http.StatusNotFound, "Network address reservation not found")
+		}
+
+		if curLease.Writable() == req {
+			return nil // Nothing has changed.
+		}
+
+		err = n.leaseValidate(net.ParseIP(curLease.Address), &req, leases)
+		if err != nil {
+			return err
+		}
+
+		err = n.leaseUpdateRecord(&api.NetworkLease{
+			Address:     curLease.Address,
+			Hwaddr:      req.Hwaddr,
+			Hostname:    req.Hostname,
+			Description: req.Description,
+		})
+		if err != nil {
+			return err
+		}
+
+		reverter.Add(func() {
+			_ = n.leaseUpdateRecord(curLease)
+			_ = UpdateDNSMasqStatic(n.state, n.name)
+		})
+	}
+
+	err := UpdateDNSMasqStatic(n.state, n.name)
+	if err != nil {
+		return err
+	}
+
+	if clientType == request.ClientTypeNormal {
+		// Notify all other members to apply the address reservation changes.
+		notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAll)
+		if err != nil {
+			return err
+		}
+
+		err = notifier(func(client incus.InstanceServer) error {
+			return client.UseProject(n.project).UpdateNetworkLease(n.name, address, req, "")
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	reverter.Success()
+
+	return nil
+}
+
+// LeaseDelete deletes a network address reservation.
+func (n *bridge) LeaseDelete(address string, clientType request.ClientType) error {
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	if clientType == request.ClientTypeNormal {
+		lease, err := n.leaseDeleteRecord(address)
+		if err != nil {
+			return err
+		}
+
+		reverter.Add(func() {
+			_ = n.leaseCreateRecord(lease)
+			_ = UpdateDNSMasqStatic(n.state, n.name)
+		})
+	}
+
+	err := UpdateDNSMasqStatic(n.state, n.name)
+	if err != nil {
+		return err
+	}
+
+	if clientType == request.ClientTypeNormal {
+		// Notify all other members to remove the address reservation.
+		notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAll)
+		if err != nil {
+			return err
+		}
+
+		err = notifier(func(client incus.InstanceServer) error {
+			return client.UseProject(n.project).DeleteNetworkLease(n.name, address)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	reverter.Success()
+
+	return nil
+}
+
+// peerSubnets returns the subnets routed to the bridge, its own subnets and its routes.
+func (n *bridge) peerSubnets() []*net.IPNet {
+	var subnets []*net.IPNet
+
+	for _, keyPrefix := range []string{"ipv4", "ipv6"} {
+		_, subnet, err := net.ParseCIDR(n.config[keyPrefix+".address"])
+		if err == nil {
+			subnets = append(subnets, subnet)
+		}
+
+		for _, route := range util.SplitNTrimSpace(n.config[keyPrefix+".routes"], ",", -1, true) {
+			_, subnet, err := net.ParseCIDR(route)
+			if err == nil {
+				subnets = append(subnets, subnet)
+			}
+		}
+	}
+
+	return subnets
+}
+
+// forPeers runs f for each target bridge network that this network is peered with.
+func (n *bridge) forPeers(f func(targetBridgeNet *bridge) error) error {
+	var peers []*api.NetworkPeer
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		netID := n.ID()
+		dbPeers, err := dbCluster.GetNetworkPeers(ctx, tx.Tx(), dbCluster.NetworkPeerFilter{NetworkID: &netID})
+		if err != nil {
+			return fmt.Errorf("Failed loading network peer DB objects: %w", err)
+		}
+
+		for _, dbPeer := range dbPeers {
+			peer, err := dbPeer.ToAPI(ctx, tx.Tx())
+			if err != nil {
+				return fmt.Errorf("Failed converting network peer DB object to API object: %w", err)
+			}
+
+			peers = append(peers, peer)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, peer := range peers {
+		// Skip partially defined peers.
+		if peer.Status != api.NetworkStatusCreated || peer.Type != "local" {
+			continue
+		}
+
+		targetNet, err := LoadByName(n.state, peer.TargetProject, peer.TargetNetwork)
+		if err != nil {
+			return fmt.Errorf("Failed loading target network: %w", err)
+		}
+
+		targetBridgeNet, ok := targetNet.(*bridge)
+		if !ok {
+			return errors.New("Target network is not bridge interface type")
+		}
+
+		err = f(targetBridgeNet)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// remotePeerTunnels returns the remote subnets of the remote peers of the network, keyed by the interface of the
+// WireGuard tunnel they're reached through.
+func (n *bridge) remotePeerTunnels() (map[string][]*net.IPNet, error) {
+	tunnels := map[string][]*net.IPNet{}
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		netID := n.ID()
+		dbPeers, err := dbCluster.GetNetworkPeers(ctx, tx.Tx(), dbCluster.NetworkPeerFilter{NetworkID: &netID})
+		if err != nil {
+			return fmt.Errorf("Failed loading network peer DB objects: %w", err)
+		}
+
+		for _, dbPeer := range dbPeers {
+			if dbPeer.Type != dbCluster.NetworkPeerTypeRemote || !dbPeer.TargetNetworkIntegrationID.Valid {
+				continue
+			}
+
+			config, err := dbCluster.GetNetworkPeerConfig(ctx, tx.Tx(), int(dbPeer.ID))
+			if err != nil {
+				return err
+			}
+
+			subnets, err := wireguardPeerSubnets(config)
+			if err != nil {
+				return err
+			}
+
+			interfaceName := fmt.Sprintf("%s%d", wireguardInterfacePrefix, dbPeer.TargetNetworkIntegrationID.Int64)
+			tunnels[interfaceName] = append(tunnels[interfaceName], subnets...)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tunnels, nil
+}
+
+// peerSetupFirewall allows traffic to be forwarded between the network and its peers.
+// Traffic between managed bridges isn't NAT-ed and is routed through the host, so only the subnets of the
+// peered networks (including their routes) are let through the forwarding policy of the network.
+// The same applies to the remote subnets of the remote peers, reached through WireGuard tunnels.
+func (n *bridge) peerSetupFirewall() error {
+	var fwPeers []firewallDrivers.NetworkPeer
+
+	err := n.forPeers(func(targetBridgeNet *bridge) error {
+		fwPeers = append(fwPeers, firewallDrivers.NetworkPeer{
+			Interface:     targetBridgeNet.name,
+			LocalSubnets:  n.peerSubnets(),
+			TargetSubnets: targetBridgeNet.peerSubnets(),
+		})
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	tunnels, err := n.remotePeerTunnels()
+	if err != nil {
+		return err
+	}
+
+	for _, interfaceName := range slices.Sorted(maps.Keys(tunnels)) {
+		fwPeers = append(fwPeers, firewallDrivers.NetworkPeer{
+			Interface:     interfaceName,
+			LocalSubnets:  n.peerSubnets(),
+			TargetSubnets: tunnels[interfaceName],
+		})
+	}
+
+	err = n.state.Firewall.NetworkApplyPeers(n.name, fwPeers)
+	if err != nil {
+		return fmt.Errorf("Failed applying firewall network peers: %w", err)
+	}
+
+	return nil
+}
+
+// peerNotify asks the other cluster members to apply a network peer change.
+func (n *bridge) peerNotify(f func(client incus.InstanceServer) error) error {
+	notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAll)
+	if err != nil {
+		return err
+	}
+
+	return notifier(func(client incus.InstanceServer) error {
+		return f(client.UseProject(n.project))
+	})
+}
+
+// PeerCreate creates a network peering with another bridge network.
+func (n *bridge) PeerCreate(peer api.NetworkPeersPost, clientType request.ClientType) error {
+	if clientType != request.ClientTypeNormal {
+		return n.peerRefresh()
+	}
+
+	// Default type is local.
+	if peer.Type == "" {
+		peer.Type = "local"
+	}
+
+	if peer.Type == "remote" {
+		return n.remotePeerCreate(peer)
+	}
+
+	if peer.Type != "local" {
+		return api.StatusErrorf(http.StatusBadRequest, "Invalid peer type %q", peer.Type)
+	}
+
+	// Default to network's project if target project not specified.
+	if peer.TargetProject == "" {
+		peer.TargetProject = n.Project()
+	}
+
+	// Target network name is required.
+	if peer.TargetNetwork == "" {
+		return api.StatusErrorf(http.StatusBadRequest, "Target network is required")
+	}
+
+	if peer.TargetProject == n.project && peer.TargetNetwork == n.name {
+		return api.StatusErrorf(http.StatusBadRequest, "Target network cannot be the network itself")
+	}
+
+	err := n.peerValidate(peer.Name, &peer.NetworkPeerPut)
+	if err != nil {
+		return err
+	}
+
+	if peer.Config["wireguard.subnets"] != "" {
+		return api.StatusErrorf(http.StatusBadRequest, "WireGuard subnets can only be set on remote peers")
+	}
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	var peerID int64
+	var mutualExists bool
+
+	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		netID := n.ID()
+		dbPeers, err := dbCluster.GetNetworkPeers(ctx, tx.Tx(), dbCluster.NetworkPeerFilter{NetworkID: &netID})
+		if err != nil {
+			return fmt.Errorf("Failed loading network peer DB objects: %w", err)
+		}
+
+		for _, dbPeer := range dbPeers {
+			existingPeer, err := dbPeer.ToAPI(ctx, tx.Tx())
+			if err != nil {
+				return fmt.Errorf("Failed converting network peer DB object to API object: %w", err)
+			}
+
+			if peer.Name == existingPeer.Name {
+				return api.StatusErrorf(http.StatusConflict, "A peer for that name already exists")
+			}
+
+			if peer.TargetProject == existingPeer.TargetProject && peer.TargetNetwork == existingPeer.TargetNetwork {
+				return api.StatusErrorf(http.StatusConflict, "A peer for that target network already exists")
+			}
+		}
+
+		record := dbCluster.NetworkPeer{
+			NetworkID:   n.ID(),
+			Name:        peer.Name,
+			Description: peer.Description,
+			Type:        dbCluster.NetworkPeerTypeLocal,
+		}
+
+		// Check if the target network already has a peer for this network.
+		targetPeers, err := dbCluster.GetNetworkPeers(ctx, tx.Tx(), dbCluster.NetworkPeerFilter{
+			Type:                 &record.Type,
+			TargetNetworkProject: &n.project,
+			TargetNetworkName:    &n.name,
+		})
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		if len(targetPeers) > 1 {
+			return errors.New("More than one matching network peer was found")
+		}
+
+		if len(targetPeers) == 1 {
+			targetPeer := targetPeers[0]
+
+			_, targetNetwork, _, err := tx.GetNetworkInAnyState(ctx, peer.TargetProject, peer.TargetNetwork)
+			if err != nil {
+				return err
+			}
+
+			if targetNetwork.Type != "bridge" {
+				return api.StatusErrorf(http.StatusBadRequest, "Bridge networks can only be peered with other bridge networks")
+			}
+
+			// Link the target peer to this network.
+			targetPeer.TargetNetworkProject = sql.NullString{}
+			targetPeer.TargetNetworkName = sql.NullString{}
+			targetPeer.TargetNetworkID = sql.NullInt64{Int64: n.id, Valid: true}
+
+			err = dbCluster.UpdateNetworkPeer(ctx, tx.Tx(), targetPeer.NetworkID, targetPeer.Name, targetPeer)
+			if err != nil {
+				return err
+			}
+
+			record.TargetNetworkID = sql.NullInt64{Int64: targetPeer.NetworkID, Valid: true}
+			mutualExists = true
+		} else {
+			record.TargetNetworkProject = sql.NullString{String: peer.TargetProject, Valid: true}
+			record.TargetNetworkName = sql.NullString{String: peer.TargetNetwork, Valid: true}
+		}
+
+		peerID, err = dbCluster.CreateNetworkPeer(ctx, tx.Tx(), record)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	reverter.Add(func() {
+		_ = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return dbCluster.DeleteNetworkPeer(ctx, tx.Tx(), n.ID(), peerID)
+		})
+	})
+
+	// Traffic is only let through once both networks are peered with each other.
+	if mutualExists {
+		err = n.peerSetupFirewallAll()
+		if err != nil {
+			return err
+		}
+
+		reverter.Add(func() { _ = n.peerSetupFirewallAll() })
+
+		err = n.peerNotify(func(client incus.InstanceServer) error {
+			return client.CreateNetworkPeer(n.name, peer)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	reverter.Success()
+
+	return nil
+}
+
+// remotePeerValidate validates the remote subnets of a remote peer.
+func (n *bridge) remotePeerValidate(config map[string]string) error {
+	subnets, err := wireguardPeerSubnets(config)
+	if err != nil {
+		return api.StatusErrorf(http.StatusBadRequest, "%v", err)
+	}
+
+	if len(subnets) == 0 {
+		return api.StatusErrorf(http.StatusBadRequest, "The remote subnets (wireguard.subnets) are required for remote peers")
+	}
+
+	for _, subnet := range subnets {
+		for _, localSubnet := range n.peerSubnets() {
+			if SubnetContains(subnet, localSubnet) || SubnetContains(localSubnet, subnet) {
+				return api.StatusErrorf(http.StatusBadRequest, "Remote subnet %q overlaps with the network subnet %q", subnet.String(), localSubnet.String())
+			}
+		}
+	}
+
+	return nil
+}
+
+// remotePeerCreate creates a network peering with a remote network through a WireGuard integration.
+func (n *bridge) remotePeerCreate(peer api.NetworkPeersPost) error {
+	// Target integration name is required.
+	if peer.TargetIntegration == "" {
+		return api.StatusErrorf(http.StatusBadRequest, "Target integration is required")
+	}
+
+	err := n.peerValidate(peer.Name, &peer.NetworkPeerPut)
+	if err != nil {
+		return err
+	}
+
+	err = n.remotePeerValidate(peer.Config)
+	if err != nil {
+		return err
+	}
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	var peerID int64
+
+	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// Validate restrictions.
+		dbProject, err := dbCluster.GetProject(ctx, tx.Tx(), n.project)
+		if err != nil {
+			return err
+		}
+
+		p, err := dbProject.ToAPI(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		if !project.NetworkIntegrationAllowed(p.Config, peer.TargetIntegration) {
+			return api.StatusErrorf(http.StatusForbidden, "Project isn't allowed to use this network integration")
+		}
+
+		// Load the integration.
+		dbIntegration, err := dbCluster.GetNetworkIntegration(ctx, tx.Tx(), peer.TargetIntegration)
+		if err != nil {
+			return fmt.Errorf("Failed to load network integration %q: %w", peer.TargetIntegration, err)
+		}
+
+		if dbIntegration.Type != dbCluster.NetworkIntegrationTypeWireGuard {
+			return api.StatusErrorf(http.StatusBadRequest, "Bridge networks can only be peered through WireGuard integrations")
+		}
+
+		integrationConfig, err := dbCluster.GetNetworkIntegrationConfig(ctx, tx.Tx(), dbIntegration.ID)
+		if err != nil {
+			return err
+		}
+
+		if n.state.ServerClustered && integrationConfig["wireguard.member"] == "" {
+			return api.StatusErrorf(http.StatusBadRequest, "The cluster member hosting the tunnel (wireguard.member) must be set on the integration")
+		}
+
+		netID := n.ID()
+		dbPeers, err := dbCluster.GetNetworkPeers(ctx, tx.Tx(), dbCluster.NetworkPeerFilter{NetworkID: &netID})
+		if err != nil {
+			return fmt.Errorf("Failed loading network peer DB objects: %w", err)
+		}
+
+		for _, dbPeer := range dbPeers {
+			if peer.Name == dbPeer.Name {
+				return api.StatusErrorf(http.StatusConflict, "A peer for that name already exists")
+			}
+		}
+
+		record := dbCluster.NetworkPeer{
+			NetworkID:                  n.ID(),
+			Name:                       peer.Name,
+			Description:                peer.Description,
+			Type:                       dbCluster.NetworkPeerTypeRemote,
+			TargetNetworkIntegrationID: sql.NullInt64{Int64: int64(dbIntegration.ID), Valid: true},
+		}
+
+		peerID, err = dbCluster.CreateNetworkPeer(ctx, tx.Tx(), record)
+		if err != nil {
+			return err
+		}
+
+		return dbCluster.CreateNetworkPeerConfig(ctx, tx.Tx(), peerID, peer.Config)
+	})
+	if err != nil {
+		return err
+	}
+
+	reverter.Add(func() {
+		_ = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return dbCluster.DeleteNetworkPeer(ctx, tx.Tx(), n.ID(), peerID)
+		})
+
+		_ = n.peerRefresh()
+	})
+
+	err = n.peerRefresh()
+	if err != nil {
+		return err
+	}
+
+	err = n.peerNotify(func(client incus.InstanceServer) error {
+		return client.CreateNetworkPeer(n.name, peer)
+	})
+	if err != nil {
+		return err
+	}
+
+	reverter.Success()
+
+	return nil
+}
+
+// peerRefresh applies the WireGuard tunnels of the remote peers, then the peer rules of the network and of the
+// networks it's peered with.
+func (n *bridge) peerRefresh() error {
+	err := WireGuardApply(n.state)
+	if err != nil {
+		return err
+	}
+
+	return n.peerSetupFirewallAll()
+}
+
+// peerSetupFirewallAll applies the peer rules of the network and of the networks it's peered with.
+func (n *bridge) peerSetupFirewallAll() error {
+	err := n.peerSetupFirewall()
+	if err != nil {
+		return err
+	}
+
+	return n.forPeers(func(targetBridgeNet *bridge) error {
+		return targetBridgeNet.peerSetupFirewall()
+	})
+}
+
+// PeerUpdate updates a network peering.
+func (n *bridge) PeerUpdate(peerName string, req api.NetworkPeerPut, clientType request.ClientType) error {
+	if clientType != request.ClientTypeNormal {
+		return n.peerRefresh()
+	}
+
+	var curPeer *api.NetworkPeer
+	var dbCurPeer *dbCluster.NetworkPeer
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		dbCurPeer, err = dbCluster.GetNetworkPeer(ctx, tx.Tx(), n.id, peerName)
+		if err != nil {
+			return fmt.Errorf("Failed getting network peer DB object: %w", err)
+		}
+
+		curPeer, err = dbCurPeer.ToAPI(ctx, tx.Tx())
+		if err != nil {
+			return fmt.Errorf("Failed converting network peer DB object to API object: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = n.peerValidate(peerName, &req)
+	if err != nil {
+		return err
+	}
+
+	if curPeer.Type == "remote" {
+		err = n.remotePeerValidate(req.Config)
+		if err != nil {
+			return err
+		}
+	} else if req.Config["wireguard.subnets"] != "" {
+		return api.StatusErrorf(http.StatusBadRequest, "WireGuard subnets can only be set on remote peers")
+	}
+
+	curPeerEtagHash, err := localUtil.EtagHash(curPeer.Etag())
+	if err != nil {
+		return err
+	}
+
+	newPeer := api.NetworkPeer{
+		Name:           curPeer.Name,
+		NetworkPeerPut: req,
+	}
+
+	newPeerEtagHash, err := localUtil.EtagHash(newPeer.Etag())
+	if err != nil {
+		return err
+	}
+
+	if curPeerEtagHash == newPeerEtagHash {
+		return nil // Nothing has changed.
+	}
+
+	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbCurPeer.Description = newPeer.Description
+
+		err = dbCluster.UpdateNetworkPeer(ctx, tx.Tx(), n.id, dbCurPeer.Name, *dbCurPeer)
+		if err != nil {
+			return fmt.Errorf("Failed to update network peer: %w", err)
+		}
+
+		err = dbCluster.UpdateNetworkPeerConfig(ctx, tx.Tx(), dbCurPeer.ID, newPeer.Config)
+		if err != nil {
+			return fmt.Errorf("Failed to update network peer config: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Route the new remote subnets through the tunnel.
+	if curPeer.Type == "remote" && curPeer.Config["wireguard.subnets"] != newPeer.Config["wireguard.subnets"] {
+		err = n.peerRefresh()
+		if err != nil {
+			return err
+		}
+
+		err = n.peerNotify(func(client incus.InstanceServer) error {
+			return client.UpdateNetworkPeer(n.name, peerName, req, "")
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PeerDelete deletes a network peering.
+func (n *bridge) PeerDelete(peerName string, clientType request.ClientType) error {
+	if clientType != request.ClientTypeNormal {
+		return n.peerRefresh()
+	}
+
+	var peerID int64
+	var peer *api.NetworkPeer
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbPeer, err := dbCluster.GetNetworkPeer(ctx, tx.Tx(), n.id, peerName)
+		if err != nil {
+			return fmt.Errorf("Failed getting network peer DB object: %w", err)
+		}
+
+		peerID = dbPeer.ID
+		peer, err = dbPeer.ToAPI(ctx, tx.Tx())
+		if err != nil {
+			return fmt.Errorf("Failed converting network peer DB object to API object: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	isUsed, err := n.peerIsUsed(peer.Name)
+	if err != nil {
+		return err
+	}
+
+	if isUsed {
+		return errors.New("Cannot delete a peer that is in use")
+	}
+
+	// The rules of the target network must be refreshed once the peering is gone.
+	var targetBridgeNet *bridge
+
+	if peer.Status == api.NetworkStatusCreated && peer.Type == "local" {
+		targetNet, err := LoadByName(n.state, peer.TargetProject, peer.TargetNetwork)
+		if err == nil {
+			targetBridgeNet, _ = targetNet.(*bridge)
+		}
+	}
+
+	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// Deactivate the mutual peer of the target network.
+		peers, err := dbCluster.GetNetworkPeers(ctx, tx.Tx(), dbCluster.NetworkPeerFilter{TargetNetworkID: &n.id})
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		for _, targetPeer := range peers {
+			targetPeer.TargetNetworkID = sql.NullInt64{}
+
+			err = dbCluster.UpdateNetworkPeer(ctx, tx.Tx(), targetPeer.NetworkID, targetPeer.Name, targetPeer)
+			if err != nil {
+				return err
+			}
+		}
+
+		return dbCluster.DeleteNetworkPeer(ctx, tx.Tx(), n.id, peerID)
+	})
+	if err != nil {
+		return err
+	}
+
+	if peer.Type == "remote" {
+		err = n.peerRefresh()
+		if err != nil {
+			return err
+		}
+
+		return n.peerNotify(func(client incus.InstanceServer) error {
+			return client.DeleteNetworkPeer(n.name, peerName)
+		})
+	}
+
+	err = n.peerSetupFirewall()
+	if err != nil {
+		return err
+	}
+
+	if targetBridgeNet != nil {
+		err = targetBridgeNet.peerSetupFirewall()
+		if err != nil {
+			return err
+		}
+
+		// Notified members refresh the rules of both networks.
+		err = n.peerNotify(func(client incus.InstanceServer) error {
+			err := client.DeleteNetworkPeer(n.name, peerName)
+			if err != nil {
+				return err
+			}
+
+			return client.UseProject(targetBridgeNet.project).DeleteNetworkPeer(targetBridgeNet.name, peerName)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Leases returns a list of leases for the bridged network. It will reach out to other cluster members as needed.
+// The projectName passed here refers to the initial project from the API request which may differ from the network's project.
+func (n *bridge) Leases(projectName string, clientType request.ClientType) ([]api.NetworkLease, error) {
+	var err error
+	var projectMacs []string
+	leases := []api.NetworkLease{}
+
+	// Get all static leases.
+	if clientType == request.ClientTypeNormal {
+		// If requested project matches network's project then include gateway and downstream uplink IPs.
+		if projectName == n.project {
+			// Add our own gateway IPs.
+			for _, addr := range []string{n.config["ipv4.address"], n.config["ipv6.address"]} {
+				ipAddress, _, _ := net.ParseCIDR(addr)
+				if ipAddress != nil {
+					leases = append(leases, api.NetworkLease{
+						Hostname: fmt.Sprintf("%s.gw", n.Name()),
+						Address:  ipAddress.String(),
+						Type:     "gateway",
+					})
+				}
+			}
+
+			// Include downstream OVN routers using the network as an uplink.
+			var projectNetworks map[string]map[int64]api.Network
+			err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+				projectNetworks, err = tx.GetCreatedNetworks(ctx)
+				return err
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			// Look for networks using the current network as an uplink.
+			for projectName, networks := range projectNetworks {
+				for _, network := range networks {
+					if network.Config["network"] != n.name {
+						continue
+					}
+
+					// Found a network, add leases.
+					for _, k := range []string{"volatile.network.ipv4.address", "volatile.network.ipv6.address"} {
+						v := network.Config[k]
+						if v != "" {
+							leases = append(leases, api.NetworkLease{
+								Hostname: fmt.Sprintf("%s-%s.uplink", projectName, network.Name),
+								Address:  v,
+								Type:     "uplink",
+							})
+						}
+					}
+				}
+			}
+
+			// Add the address reservations.
+			reservations, err := n.leasesLoad()
+			if err != nil {
+				return nil, err
+			}
+
+			for _, reservation := range reservations {
+				leases = append(leases, *reservation)
+			}
+		}
+
+		// Get all the instances in the requested project that are connected to this network.
+		filter := dbCluster.InstanceFilter{Project: &projectName}
+		err = UsedByInstanceDevices(n.state, n.Project(), n.Name(), n.Type(), func(inst db.InstanceArgs, nicName string, nicConfig map[string]string) error {
+			// Fill in the hwaddr from volatile.
+			if nicConfig["hwaddr"] == "" {
+				nicConfig["hwaddr"] = inst.Config[fmt.Sprintf("volatile.%s.hwaddr", nicName)]
+			}
+
+			// Record the MAC.
+			hwAddr, _ := net.ParseMAC(nicConfig["hwaddr"])
+			if hwAddr != nil {
+				projectMacs = append(projectMacs, hwAddr.String())
+			}
+
+			// Add the lease.
+			nicIP4 := net.ParseIP(nicAddressIP(nicConfig["ipv4.address"]))
+			if nicIP4 != nil {
+				leases = append(leases, api.NetworkLease{
+					Hostname: inst.Name,
+					Address:  nicIP4.String(),
+					Hwaddr:   hwAddr.String(),
+					Type:     "static",
+					Location: inst.Node,
+				})
+			}
+
+			nicIP6 := net.ParseIP(nicAddressIP(nicConfig["ipv6.address"]))
+			if nicIP6 != nil {
+				leases = append(leases, api.NetworkLease{
+					Hostname: inst.Name,
 					Address:  nicIP6.String(),
 					Hwaddr:   hwAddr.String(),
 					Type:     "static",
@@ -3588,3 +5718,46 @@ func (n *bridge) deleteChildren() error {
 
 	return nil
 }
+
+// flowsSetup applies the flow export settings of the bridge. OVS bridges export sampled packets through OVS while
+// native bridges export the conntrack flows of the bridge subnets over IPFIX.
+func (n *bridge) flowsSetup() error {
+	targets := util.SplitNTrimSpace(n.config["flows.target"], ",", -1, true)
+
+	if n.config["bridge.driver"] == "openvswitch" {
+		vswitch, err := n.state.OVS()
+		if err != nil {
+			return fmt.Errorf("Failed to connect to OVS: %w", err)
+		}
+
+		if len(targets) == 0 {
+			return vswitch.SetBridgeFlowExport(context.TODO(), n.name)
+		}
+
+		sampling, err := flowsSampling(n.config, flowsSamplingOVS)
+		if err != nil {
+			return err
+		}
+
+		return vswitch.SetBridgeFlowExport(context.TODO(), n.name, ovs.BridgeFlowExport{
+			Protocol: flowsProtocol(n.config),
+			Targets:  targets,
+			Sampling: sampling,
+			DomainID: int(n.id),
+		})
+	}
+
+	if len(targets) == 0 {
+		return flowsExporterUpdate(n.id, nil, "")
+	}
+
+	sampling, err := flowsSampling(n.config, flowsSamplingNative)
+	if err != nil {
+		return err
+	}
+
+	subnets := n.peerSubnets()
+	settings := fmt.Sprintf("%v/%d/%v", targets, sampling, subnets)
+
+	return flowsExporterUpdate(n.id, flows.NewExporter(uint32(n.id), targets, uint32(sampling), subnets), settings)
+}
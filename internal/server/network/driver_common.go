@@ -22,6 +22,7 @@ import (
 	"github.com/lxc/incus/v7/internal/server/cluster/request"
 	"github.com/lxc/incus/v7/internal/server/db"
 	dbCluster "github.com/lxc/incus/v7/internal/server/db/cluster"
+	"github.com/lxc/incus/v7/internal/server/ip"
 	"github.com/lxc/incus/v7/internal/server/network/acl"
 	"github.com/lxc/incus/v7/internal/server/state"
 	internalUtil "github.com/lxc/incus/v7/internal/util"
@@ -39,6 +40,8 @@ type Info struct {
 	NodeSpecificConfig bool // Whether driver has cluster node specific config as a prerequisite for creation.
 	AddressForwards    bool // Indicates if driver supports address forwards.
 	LoadBalancers      bool // Indicates if driver supports load balancers.
+	EgressGateways     bool // Indicates if driver supports egress gateways.
+	Reservations       bool // Indicates if driver supports address reservations.
 	Peering            bool // Indicates if the driver supports network peering.
 }
 
@@ -62,6 +65,21 @@ type loadBalancerPortMap struct {
 	targets     []forwardTarget
 }
 
+// targetPort returns the port of the target that the listen port at the given index is forwarded to.
+func (p *loadBalancerPortMap) targetPort(target forwardTarget, index int) uint64 {
+	switch len(target.ports) {
+	case 0:
+		// Default to using same port as listen port for target port.
+		return p.listenPorts[index]
+	case 1:
+		// If a single target port is specified, forward all listen ports to it.
+		return target.ports[0]
+	}
+
+	// If more than 1 target port specified, use listen port index to get the target port to use.
+	return target.ports[index]
+}
+
 // subnetUsageType indicates the type of use for a subnet.
 type subnetUsageType uint
 
@@ -70,6 +88,7 @@ const (
 	subnetUsageNetworkSNAT
 	subnetUsageNetworkForward
 	subnetUsageNetworkLoadBalancer
+	subnetUsageNetworkEgressGateway
 	subnetUsageInstance
 	subnetUsageProxy
 )
@@ -290,6 +309,8 @@ func (n *common) Info() Info {
 		NodeSpecificConfig: true,
 		AddressForwards:    false,
 		LoadBalancers:      false,
+		EgressGateways:     false,
+		Reservations:       false,
 	}
 }
 
@@ -338,42 +359,51 @@ func (n *common) DHCPv6Subnet() *net.IPNet {
 
 // DHCPv4Ranges returns a parsed set of DHCPv4 ranges for this network.
 func (n *common) DHCPv4Ranges() []iprange.Range {
-	dhcpRanges := make([]iprange.Range, 0)
-	if n.config["ipv4.dhcp.ranges"] != "" {
-		for _, r := range strings.Split(n.config["ipv4.dhcp.ranges"], ",") {
-			parts := strings.SplitN(strings.TrimSpace(r), "-", 2)
-			if len(parts) == 2 {
-				startIP := net.ParseIP(parts[0])
-				endIP := net.ParseIP(parts[1])
-				dhcpRanges = append(dhcpRanges, iprange.Range{
-					Start: startIP.To4(),
-					End:   endIP.To4(),
-				})
-			}
-		}
-	}
-
-	return dhcpRanges
+	return n.configRanges("ipv4.dhcp.ranges", true)
 }
 
 // DHCPv6Ranges returns a parsed set of DHCPv6 ranges for this network.
 func (n *common) DHCPv6Ranges() []iprange.Range {
-	dhcpRanges := make([]iprange.Range, 0)
-	if n.config["ipv6.dhcp.ranges"] != "" {
-		for _, r := range strings.Split(n.config["ipv6.dhcp.ranges"], ",") {
+	return n.configRanges("ipv6.dhcp.ranges", false)
+}
+
+// ReservedIPv4Ranges returns a parsed set of the IPv4 ranges reserved for external hosts on this network.
+func (n *common) ReservedIPv4Ranges() []iprange.Range {
+	return n.configRanges("ipv4.reserved_ranges", true)
+}
+
+// ReservedIPv6Ranges returns a parsed set of the IPv6 ranges reserved for external hosts on this network.
+func (n *common) ReservedIPv6Ranges() []iprange.Range {
+	return n.configRanges("ipv6.reserved_ranges", false)
+}
+
+// configRanges returns a parsed set of the IP ranges in the config key (FIRST-LAST format).
+func (n *common) configRanges(key string, ipv4 bool) []iprange.Range {
+	ranges := make([]iprange.Range, 0)
+	if n.config[key] != "" {
+		for _, r := range strings.Split(n.config[key], ",") {
 			parts := strings.SplitN(strings.TrimSpace(r), "-", 2)
 			if len(parts) == 2 {
 				startIP := net.ParseIP(parts[0])
 				endIP := net.ParseIP(parts[1])
-				dhcpRanges = append(dhcpRanges, iprange.Range{
-					Start: startIP.To16(),
-					End:   endIP.To16(),
+
+				if ipv4 {
+					startIP = startIP.To4()
+					endIP = endIP.To4()
+				} else {
+					startIP = startIP.To16()
+					endIP = endIP.To16()
+				}
+
+				ranges = append(ranges, iprange.Range{
+					Start: startIP,
+					End:   endIP,
 				})
 			}
 		}
 	}
 
-	return dhcpRanges
+	return ranges
 }
 
 // update the internal config variables, and if not cluster notification, notifies all nodes and updates database.
@@ -625,6 +655,8 @@ func (n *common) bgpValidationRules(config map[string]string) (map[string]func(v
 			rules[k] = validate.Optional(validate.IsAny)
 		case "holdtime":
 			rules[k] = validate.Optional(validate.IsInRange(9, 65535))
+		case "bfd":
+			rules[k] = validate.Optional(validate.IsBool)
 		case "interface":
 			peerName := fields[2]
 			rules[k] = validate.Optional(func(value string) error {
@@ -671,6 +703,14 @@ func (n *common) bgpSetup(oldConfig map[string]string) error {
 		return fmt.Errorf("Failed applying BGP prefixes for address forwards: %w", err)
 	}
 
+	// Import the learned routes (OVN networks import those of their uplink into their router).
+	if n.netType != "ovn" {
+		err = n.bgpSetupImport(n.config["bgp.import.filter"], currentPeers, n.bgpImportHostRoutes)
+		if err != nil {
+			return fmt.Errorf("Failed setting up BGP route import: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -694,6 +734,12 @@ func (n *common) bgpClear(config map[string]string) error {
 		return err
 	}
 
+	// Withdraw the imported routes.
+	err = n.state.BGP.RemoveImport(fmt.Sprintf("network_%d_import", n.id))
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -753,7 +799,7 @@ func (n *common) bgpSetupPeers(oldConfig map[string]string) error {
 			}
 		}
 
-		err = n.state.BGP.AddPeer(net.ParseIP(fields[0]), fields[4], uint32(asn), fields[2], holdTime)
+		err = n.state.BGP.AddPeer(net.ParseIP(fields[0]), fields[4], uint32(asn), fields[2], holdTime, util.IsTrue(fields[5]))
 		if err != nil {
 			return err
 		}
@@ -855,15 +901,174 @@ func (n *common) bgpGetPeers(config map[string]string) []string {
 		peerPassword := config[fmt.Sprintf("bgp.peers.%s.password", peerName)]
 		peerHoldTime := config[fmt.Sprintf("bgp.peers.%s.holdtime", peerName)]
 		peerInterface := config[fmt.Sprintf("bgp.peers.%s.interface", peerName)]
+		peerBFD := config[fmt.Sprintf("bgp.peers.%s.bfd", peerName)]
 
 		if (peerAddress != "" || peerInterface != "") && peerASN != "" {
-			peers = append(peers, fmt.Sprintf("%s,%s,%s,%s,%s", peerAddress, peerASN, peerPassword, peerHoldTime, peerInterface))
+			peers = append(peers, fmt.Sprintf("%s,%s,%s,%s,%s,%s", peerAddress, peerASN, peerPassword, peerHoldTime, peerInterface, peerBFD))
 		}
 	}
 
 	return peers
 }
 
+// bgpPeerName returns the name used by the BGP server for a peer string (its address or interface name).
+func bgpPeerName(peer string) string {
+	fields := strings.Split(peer, ",")
+	if fields[0] != "" {
+		return net.ParseIP(fields[0]).String()
+	}
+
+	return fields[4]
+}
+
+// validateBGPImportFilter validates a bgp.import.filter prefix list.
+func validateBGPImportFilter(value string) error {
+	_, err := bgp.ParsePrefixFilters(value)
+	return err
+}
+
+// bgpSetupImport sets up the import of the routes learned from the peers which match the filter.
+func (n *common) bgpSetupImport(filter string, peers []string, fn bgp.ImportFunc) error {
+	bgpOwner := fmt.Sprintf("network_%d_import", n.id)
+
+	if filter == "" || len(peers) == 0 {
+		return n.state.BGP.RemoveImport(bgpOwner)
+	}
+
+	filters, err := bgp.ParsePrefixFilters(filter)
+	if err != nil {
+		return err
+	}
+
+	peerNames := make([]string, 0, len(peers))
+	for _, peer := range peers {
+		peerNames = append(peerNames, bgpPeerName(peer))
+	}
+
+	n.state.BGP.AddImport(bgpOwner, peerNames, filters, fn)
+
+	return nil
+}
+
+// bgpImportHostRoutes installs the routes learned from the network's BGP peers into the host routing table.
+func (n *common) bgpImportHostRoutes(oldRoutes []bgp.Route, newRoutes []bgp.Route) error {
+	// Remove the withdrawn routes.
+	for _, route := range oldRoutes {
+		if slices.ContainsFunc(newRoutes, func(newRoute bgp.Route) bool { return newRoute.Prefix.String() == route.Prefix.String() }) {
+			continue
+		}
+
+		hostRoute, err := bgpHostRoute(route)
+		if err != nil {
+			continue
+		}
+
+		err = hostRoute.Delete()
+		if err != nil {
+			n.logger.Warn("Failed removing BGP learned route", logger.Ctx{"prefix": route.Prefix.String(), "err": err})
+		}
+	}
+
+	// Add (or update) the learned routes.
+	for _, route := range newRoutes {
+		hostRoute, err := bgpHostRoute(route)
+		if err != nil {
+			n.logger.Warn("Skipping BGP learned route", logger.Ctx{"prefix": route.Prefix.String(), "err": err})
+			continue
+		}
+
+		err = hostRoute.Replace()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bgpHostRoute returns the host route for a route learned through BGP.
+func bgpHostRoute(route bgp.Route) (*ip.Route, error) {
+	family := ip.FamilyV4
+	if route.Prefix.IP.To4() == nil {
+		family = ip.FamilyV6
+	}
+
+	if (route.Nexthop.To4() == nil) != (family == ip.FamilyV6) {
+		return nil, fmt.Errorf("Next hop %q doesn't match the prefix address family", route.Nexthop.String())
+	}
+
+	devName := route.Interface
+	if devName == "" {
+		var err error
+
+		devName, err = ip.GetRouteDevice(route.Nexthop)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ip.Route{
+		DevName: devName,
+		Route:   &route.Prefix,
+		Via:     route.Nexthop,
+		Proto:   "bgp",
+		Family:  family,
+	}, nil
+}
+
+// bgpState returns the state of the sessions with the BGP peers and of the routes learned from them.
+func (n *common) bgpState(peers []string) (*api.NetworkStateBGP, error) {
+	peerNames := make([]string, 0, len(peers))
+	for _, peer := range peers {
+		peerNames = append(peerNames, bgpPeerName(peer))
+	}
+
+	sessions, err := n.state.BGP.Sessions(peerNames)
+	if err != nil {
+		return nil, err
+	}
+
+	routes, err := n.state.BGP.Routes(peerNames)
+	if err != nil {
+		return nil, err
+	}
+
+	var filters []bgp.PrefixFilter
+	if n.config["bgp.import.filter"] != "" {
+		filters, err = bgp.ParsePrefixFilters(n.config["bgp.import.filter"])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	state := &api.NetworkStateBGP{
+		Sessions: make([]api.NetworkStateBGPSession, 0, len(sessions)),
+		Routes:   make([]api.NetworkStateBGPRoute, 0, len(routes)),
+	}
+
+	for _, session := range sessions {
+		state.Sessions = append(state.Sessions, api.NetworkStateBGPSession{
+			Peer:     session.Peer,
+			ASN:      session.ASN,
+			State:    session.State,
+			BFDState: session.BFDState,
+		})
+	}
+
+	for _, route := range routes {
+		imported := slices.ContainsFunc(filters, func(filter bgp.PrefixFilter) bool { return filter.Match(route.Prefix) })
+
+		state.Routes = append(state.Routes, api.NetworkStateBGPRoute{
+			Prefix:   route.Prefix.String(),
+			Nexthop:  route.Nexthop.String(),
+			Peer:     route.Peer,
+			Imported: imported,
+		})
+	}
+
+	return state, nil
+}
+
 // forwardValidate validates the forward request.
 func (n *common) forwardValidate(listenAddress net.IP, forward *api.NetworkForwardPut) ([]*forwardPortMap, error) {
 	if listenAddress == nil {
@@ -1198,9 +1403,11 @@ func (n *common) getExternalSubnetInUse(ctx context.Context, tx *db.ClusterTx, u
 		}
 	}
 
-	// Get all network load balancer and forward listen addresses for all networks (of any type) connected to our uplink.
+	// Get all network load balancer and forward listen addresses as well as egress gateway addresses for all
+	// networks (of any type) connected to our uplink.
 	projectNetworksLoadBalancersOnUplink := map[string]map[string][]string{}
 	projectNetworksForwardsOnUplink := map[string]map[string][]string{}
+	projectNetworksEgressGatewaysOnUplink := map[string]map[string][]string{}
 
 	for networkID, relatedNetwork := range relatedNetworks {
 		// Get all load balancers associated with this network.
@@ -1219,6 +1426,22 @@ func (n *common) getExternalSubnetInUse(ctx context.Context, tx *db.ClusterTx, u
 			projectNetworksLoadBalancersOnUplink[relatedNetwork.Project][relatedNetwork.Name] = append(projectNetworksLoadBalancersOnUplink[relatedNetwork.Project][relatedNetwork.Name], lb.ListenAddress)
 		}
 
+		// Get all egress gateways associated with this network.
+		egressGateways, err := dbCluster.GetNetworkEgressGateways(ctx, tx.Tx(), dbCluster.NetworkEgressGatewayFilter{
+			NetworkID: &networkID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Failed getting list of network egress gateways: %w", err)
+		}
+
+		for _, gateway := range egressGateways {
+			if projectNetworksEgressGatewaysOnUplink[relatedNetwork.Project] == nil {
+				projectNetworksEgressGatewaysOnUplink[relatedNetwork.Project] = map[string][]string{}
+			}
+
+			projectNetworksEgressGatewaysOnUplink[relatedNetwork.Project][relatedNetwork.Name] = append(projectNetworksEgressGatewaysOnUplink[relatedNetwork.Project][relatedNetwork.Name], gateway.Address)
+		}
+
 		// Get all network forwards associated with this network.
 		networkForwards, err := dbCluster.GetNetworkForwards(ctx, tx.Tx(), dbCluster.NetworkForwardFilter{
 			NetworkID: &networkID,
@@ -1286,6 +1509,25 @@ func (n *common) getExternalSubnetInUse(ctx context.Context, tx *db.ClusterTx, u
 		}
 	}
 
+	// Add egress gateway addresses to this list.
+	for projectName, networks := range projectNetworksEgressGatewaysOnUplink {
+		for networkName, addresses := range networks {
+			for _, address := range addresses {
+				addressNet, err := ParseIPToNet(address)
+				if err != nil {
+					return nil, fmt.Errorf("Invalid existing egress gateway address %q", address)
+				}
+
+				externalSubnets = append(externalSubnets, externalSubnetUsage{
+					subnet:         *addressNet,
+					networkProject: projectName,
+					networkName:    networkName,
+					usageType:      subnetUsageNetworkEgressGateway,
+				})
+			}
+		}
+	}
+
 	return externalSubnets, nil
 }
 
@@ -1522,23 +1764,53 @@ func (n *common) LoadBalancerDelete(listenAddress string, clientType request.Cli
 	return ErrNotImplemented
 }
 
+// EgressGatewayCreate returns ErrNotImplemented for drivers that do not support egress gateways.
+func (n *common) EgressGatewayCreate(gateway api.NetworkEgressGatewaysPost, clientType request.ClientType) error {
+	return ErrNotImplemented
+}
+
+// EgressGatewayUpdate returns ErrNotImplemented for drivers that do not support egress gateways.
+func (n *common) EgressGatewayUpdate(address string, newGateway api.NetworkEgressGatewayPut, clientType request.ClientType) error {
+	return ErrNotImplemented
+}
+
+// EgressGatewayDelete returns ErrNotImplemented for drivers that do not support egress gateways.
+func (n *common) EgressGatewayDelete(address string, clientType request.ClientType) error {
+	return ErrNotImplemented
+}
+
+// LeaseCreate returns ErrNotImplemented for drivers that do not support address reservations.
+func (n *common) LeaseCreate(lease api.NetworkLeasesPost, clientType request.ClientType) error {
+	return ErrNotImplemented
+}
+
+// LeaseUpdate returns ErrNotImplemented for drivers that do not support address reservations.
+func (n *common) LeaseUpdate(address string, newLease api.NetworkLeasePut, clientType request.ClientType) error {
+	return ErrNotImplemented
+}
+
+// LeaseDelete returns ErrNotImplemented for drivers that do not support address reservations.
+func (n *common) LeaseDelete(address string, clientType request.ClientType) error {
+	return ErrNotImplemented
+}
+
 // Leases returns ErrNotImplemented for drivers that don't support address leases.
 func (n *common) Leases(projectName string, clientType request.ClientType) ([]api.NetworkLease, error) {
 	return nil, ErrNotImplemented
 }
 
 // PeerCreate returns ErrNotImplemented for drivers that do not support forwards.
-func (n *common) PeerCreate(forward api.NetworkPeersPost) error {
+func (n *common) PeerCreate(forward api.NetworkPeersPost, clientType request.ClientType) error {
 	return ErrNotImplemented
 }
 
 // PeerUpdate returns ErrNotImplemented for drivers that do not support forwards.
-func (n *common) PeerUpdate(peerName string, newPeer api.NetworkPeerPut) error {
+func (n *common) PeerUpdate(peerName string, newPeer api.NetworkPeerPut, clientType request.ClientType) error {
 	return ErrNotImplemented
 }
 
 // PeerDelete returns ErrNotImplemented for drivers that do not support forwards.
-func (n *common) PeerDelete(peerName string) error {
+func (n *common) PeerDelete(peerName string, clientType request.ClientType) error {
 	return ErrNotImplemented
 }
 
@@ -1559,6 +1831,16 @@ func (n *common) peerValidate(peerName string, peer *api.NetworkPeerPut) error {
 			continue
 		}
 
+		// Remote subnets of the peers of bridge networks through WireGuard integrations.
+		if k == "wireguard.subnets" && n.netType == "bridge" {
+			err := validate.IsListOf(validate.IsNetwork)(peer.Config[k])
+			if err != nil {
+				return fmt.Errorf("Invalid value for %q: %w", k, err)
+			}
+
+			continue
+		}
+
 		// User keys are not validated.
 		if internalInstance.IsUserConfig(k) {
 			continue
@@ -1667,11 +1949,29 @@ func (n *common) peerUsedBy(peerName string, firstOnly bool) ([]string, error) {
 
 // State returns the current state of the network.
 func (n *common) State() (*api.NetworkState, error) {
+	var state *api.NetworkState
+	var err error
+
 	if n.config["parent"] != "" {
-		return resources.GetNetworkState(n.config["parent"])
+		state, err = resources.GetNetworkState(n.config["parent"])
+	} else {
+		state, err = resources.GetNetworkState(n.name)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Add the BGP sessions and learned routes.
+	peers := n.bgpGetPeers(n.config)
+	if len(peers) > 0 {
+		state.BGP, err = n.bgpState(peers)
+		if err != nil {
+			return nil, fmt.Errorf("Failed getting BGP state: %w", err)
+		}
 	}
 
-	return resources.GetNetworkState(n.name)
+	return state, nil
 }
 
 func (n *common) setUnavailable() {
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"math"
 	"math/big"
 	"net"
 	"net/http"
@@ -25,6 +26,7 @@ import (
 
 	incus "github.com/lxc/incus/v7/client"
 	"github.com/lxc/incus/v7/internal/iprange"
+	"github.com/lxc/incus/v7/internal/server/bgp"
 	"github.com/lxc/incus/v7/internal/server/cluster"
 	"github.com/lxc/incus/v7/internal/server/cluster/request"
 	"github.com/lxc/incus/v7/internal/server/db"
@@ -95,6 +97,7 @@ type OVNInstanceNICSetupOpts struct {
 	DNSName                  string
 	LastStateIPs             []net.IP
 	RemovedExternalAddresses []net.IP
+	QoSClass                 string
 }
 
 // OVNInstanceNICStopOpts options for stopping an OVN Instance NIC.
@@ -151,6 +154,8 @@ func (n *ovn) Info() Info {
 	info.NodeSpecificConfig = false
 	info.AddressForwards = true
 	info.LoadBalancers = true
+	info.EgressGateways = true
+	info.Reservations = true
 	info.Peering = true
 
 	return info
@@ -186,6 +191,7 @@ func (n *ovn) State() (*api.NetworkState, error) {
 	var hwaddr string
 	var uplinkIPv4 string
 	var uplinkIPv6 string
+	var bgpState *api.NetworkStateBGP
 
 	logicalRouterName := n.getRouterName()
 	logicalSwitchName := n.getIntSwitchName()
@@ -206,6 +212,20 @@ func (n *ovn) State() (*api.NetworkState, error) {
 		if n.config[ovnVolatileUplinkIPv6] != "" {
 			uplinkIPv6 = n.config[ovnVolatileUplinkIPv6]
 		}
+
+		// Get the BGP sessions and learned routes of the uplink.
+		uplinkNet, err := LoadByName(n.state, api.ProjectDefaultName, n.config["network"])
+		if err != nil {
+			return nil, fmt.Errorf("Failed loading uplink network %q: %w", n.config["network"], err)
+		}
+
+		uplinkPeers := n.bgpGetPeers(uplinkNet.Config())
+		if len(uplinkPeers) > 0 {
+			bgpState, err = n.bgpState(uplinkPeers)
+			if err != nil {
+				return nil, fmt.Errorf("Failed getting BGP state: %w", err)
+			}
+		}
 	} else if n.config["ipv4.address"] == "none" && n.config["ipv6.address"] == "none" {
 		// Networks with no uplink and no IP addresses will not have a router.
 		logicalRouterName = ""
@@ -243,6 +263,7 @@ func (n *ovn) State() (*api.NetworkState, error) {
 			UplinkIPv4:    uplinkIPv4,
 			UplinkIPv6:    uplinkIPv6,
 		},
+		BGP: bgpState,
 	}, nil
 }
 
@@ -476,6 +497,14 @@ func (n *ovn) Validate(config map[string]string, clientType request.ClientType)
 		//  shortdesc: Uplink network to use for external network access or `none` to keep isolated
 		"network": validate.IsAny,
 
+		// gendoc:generate(entity=network_ovn, group=common, key=bgp.import.filter)
+		//
+		// ---
+		//  type: string
+		//  condition: BGP server
+		//  shortdesc: Comma-separated list of prefixes (optionally followed by `..` and the longest accepted prefix length) whose routes learned from the uplink's BGP peers are installed into the network's router
+		"bgp.import.filter": validate.Optional(validateBGPImportFilter),
+
 		// gendoc:generate(entity=network_ovn, group=common, key=bridge.hwaddr)
 		//
 		// ---
@@ -583,6 +612,14 @@ func (n *ovn) Validate(config map[string]string, clientType request.ClientType)
 		//  shortdesc: Static routes to provide via DHCP option 121, as a comma-separated list of alternating subnets (CIDR) and gateway addresses (same syntax as dnsmasq and OVN)
 		"ipv4.dhcp.routes": validate.Optional(validate.IsDHCPRouteList),
 
+		// gendoc:generate(entity=network_ovn, group=common, key=ipv4.reserved_ranges)
+		//
+		// ---
+		//  type: string
+		//  condition: IPv4 address
+		//  shortdesc: Comma-separated list of IPv4 ranges reserved for external hosts, never handed out by DHCP or to instances (FIRST-LAST format)
+		"ipv4.reserved_ranges": validate.Optional(validate.IsListOf(validate.IsNetworkRangeV4)),
+
 		// gendoc:generate(entity=network_ovn, group=common, key=ipv6.address)
 		//
 		// ---
@@ -730,6 +767,29 @@ func (n *ovn) Validate(config map[string]string, clientType request.ClientType)
 		//  shortdesc: DNS zone name for IPv6 reverse DNS records
 		"dns.zone.reverse.ipv6": validate.IsAny,
 
+		// gendoc:generate(entity=network_ovn, group=common, key=flows.target)
+		//
+		// ---
+		//  type: string
+		//  shortdesc: Comma-separated list of flow collectors (`<address>:<port>`) to export the flows of the OVS integration bridge to
+		"flows.target": validate.Optional(validate.IsListOf(validate.IsListenAddress(false, false, true))),
+
+		// gendoc:generate(entity=network_ovn, group=common, key=flows.protocol)
+		//
+		// ---
+		//  type: string
+		//  default: `ipfix`
+		//  shortdesc: Flow export protocol: `ipfix` or `sflow`
+		"flows.protocol": validate.Optional(validate.IsOneOf("ipfix", "sflow")),
+
+		// gendoc:generate(entity=network_ovn, group=common, key=flows.sampling)
+		//
+		// ---
+		//  type: integer
+		//  default: `400`
+		//  shortdesc: Export one in this many packets (the lowest value of the networks using the same protocol is used)
+		"flows.sampling": validate.Optional(validate.IsInRange(1, math.MaxUint32)),
+
 		// gendoc:generate(entity=network_ovn, group=common, key=security.acls)
 		//
 		// ---
@@ -879,7 +939,32 @@ func (n *ovn) Validate(config map[string]string, clientType request.ClientType)
 		}
 	}
 
-	err := n.validate(config, rules)
+	// gendoc:generate(entity=network_ovn, group=qos, key=qos.classes.NAME.nic_ceiling)
+	// Unlike the `ceiling` of the QoS classes of bridge networks, which is shared by all the NICs of the class,
+	// this limit applies to each NIC of the class separately.
+	// ---
+	// type: string
+	// condition: -
+	// defaultdesc: -
+	// shortdesc: Maximum bandwidth in bit/s of the traffic sent to each NIC of the class
+
+	// gendoc:generate(entity=network_ovn, group=qos, key=qos.classes.NAME.dscp)
+	//
+	// ---
+	// type: integer
+	// condition: -
+	// defaultdesc: -
+	// shortdesc: DSCP value (0-63) set on the traffic sent by the NICs of the class
+
+	// Add the QoS class validation rules (OVN can't guarantee bandwidth or limit a class as a whole).
+	qosRules, err := n.qosValidationRules(config, "nic_ceiling", "dscp")
+	if err != nil {
+		return err
+	}
+
+	maps.Copy(rules, qosRules)
+
+	err = n.validate(config, rules)
 	if err != nil {
 		return err
 	}
@@ -911,6 +996,12 @@ func (n *ovn) Validate(config map[string]string, clientType request.ClientType)
 		}
 	}
 
+	// Check the reserved ranges are within the network subnet.
+	err = n.validateReservedRanges(config, "ipv4")
+	if err != nil {
+		return err
+	}
+
 	// Check that if IPv6 enabled then the network size must be at least a /64 as both RA and DHCPv6
 	// in OVN (as it generates addresses using EUI64) require at least a /64 subnet to operate.
 	_, ipv6Net, _ := net.ParseCIDR(config["ipv6.address"])
@@ -2612,6 +2703,21 @@ func (n *ovn) getDHCPv4Reservations() ([]iprange.Range, error) {
 		return nil, err
 	}
 
+	// Keep the reserved ranges and the reserved addresses out of the dynamic pool.
+	dhcpReserveIPv4s = append(dhcpReserveIPv4s, n.ReservedIPv4Ranges()...)
+
+	leases, err := n.leasesLoad()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, lease := range leases {
+		ip := net.ParseIP(lease.Address)
+		if ip.To4() != nil && !ipInRanges(ip, dhcpReserveIPv4s) {
+			dhcpReserveIPv4s = append(dhcpReserveIPv4s, iprange.Range{Start: ip})
+		}
+	}
+
 	return dhcpReserveIPv4s, nil
 }
 
@@ -2892,6 +2998,14 @@ func (n *ovn) setup(update bool) error {
 			}
 		}
 
+		// Add egress gateway SNAT rules (removed above on update).
+		if n.config["network"] != "none" {
+			err = n.egressGatewaySetup()
+			if err != nil {
+				return err
+			}
+		}
+
 		// Check if uplink network states its gateway mac for static MAC binding.
 		if uplinkNet != nil && n.config["network"] != "none" {
 			// Load the uplink network.
@@ -3700,6 +3814,14 @@ func (n *ovn) Delete(clientType request.ClientType) error {
 		n.logger.Warn("Failed stopping network during delete, continuing with deletion", logger.Ctx{"err": err})
 	}
 
+	// Stop exporting the flows of the network from the integration bridge.
+	if n.config["flows.target"] != "" {
+		err = n.flowsSetup(n.id)
+		if err != nil {
+			n.logger.Warn("Failed updating flow export during delete", logger.Ctx{"err": err})
+		}
+	}
+
 	if clientType == request.ClientTypeNormal {
 		// Delete the router and anything tied to it (router ports, static routes, policies, nat, ...).
 		err = n.ovnnb.DeleteLogicalRouter(context.TODO(), n.getRouterName())
@@ -3946,6 +4068,16 @@ func (n *ovn) Start() error {
 		return fmt.Errorf("Failed applying BGP prefixes for load balancers: %w", err)
 	}
 
+	err = n.bgpImportSetup()
+	if err != nil {
+		return fmt.Errorf("Failed setting up BGP route import: %w", err)
+	}
+
+	err = n.flowsSetup(-1)
+	if err != nil {
+		return fmt.Errorf("Failed setting up flow export: %w", err)
+	}
+
 	// Setup event handler for monitored services.
 	handler := networkOVN.EventHandler{
 		Tables: []string{"Service_Monitor", "Port_Binding"},
@@ -4141,6 +4273,16 @@ func (n *ovn) Update(newNetwork api.NetworkPut, targetNode string, clientType re
 			return fmt.Errorf("Failed applying BGP prefixes for load balancers: %w", err)
 		}
 
+		err = n.bgpImportSetup()
+		if err != nil {
+			return fmt.Errorf("Failed setting up BGP route import: %w", err)
+		}
+
+		err = n.flowsSetup(-1)
+		if err != nil {
+			return fmt.Errorf("Failed setting up flow export: %w", err)
+		}
+
 		if len(n.getTunnelsFromChangedKeys(changedKeys)) > 0 {
 			err = n.updateTunnels(newNetwork.Config, changedKeys, false)
 			if err != nil {
@@ -4482,6 +4624,33 @@ func (n *ovn) Update(newNetwork api.NetworkPut, targetNode string, clientType re
 		if err != nil {
 			return err
 		}
+
+		err = n.bgpImportSetup()
+		if err != nil {
+			return fmt.Errorf("Failed setting up BGP route import: %w", err)
+		}
+
+		err = n.flowsSetup(-1)
+		if err != nil {
+			return fmt.Errorf("Failed setting up flow export: %w", err)
+		}
+
+		flowsChanged := slices.ContainsFunc(changedKeys, func(key string) bool { return strings.HasPrefix(key, "flows.") })
+
+		if slices.Contains(changedKeys, "bgp.import.filter") || flowsChanged {
+			// Notify all other members to refresh their BGP route import and flow export.
+			notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAll)
+			if err != nil {
+				return err
+			}
+
+			err = notifier(func(client incus.InstanceServer) error {
+				return client.UseProject(n.project).UpdateNetwork(n.name, newNetwork, "")
+			})
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	err = n.loadBalancerBGPSetupPrefixes()
@@ -4489,6 +4658,14 @@ func (n *ovn) Update(newNetwork api.NetworkPut, targetNode string, clientType re
 		return fmt.Errorf("Failed applying BGP prefixes for load balancers: %w", err)
 	}
 
+	// Apply QoS class changes to the started instance NICs (the rules are shared by all cluster members).
+	if clientType == request.ClientTypeNormal && slices.ContainsFunc(changedKeys, func(key string) bool { return strings.HasPrefix(key, "qos.") }) {
+		err = n.qosApply()
+		if err != nil {
+			return fmt.Errorf("Failed applying QoS classes: %w", err)
+		}
+	}
+
 	err = n.updateTunnels(newNetwork.Config, changedKeys, false)
 	if err != nil {
 		return err
@@ -4519,11 +4696,84 @@ func (n *ovn) Update(newNetwork api.NetworkPut, targetNode string, clientType re
 	return nil
 }
 
+// flowsSetup applies the flow export settings of the OVN networks to the local OVS integration bridge. As the
+// integration bridge is shared by all OVN networks, the targets of the networks using the same protocol are
+// combined and the lowest sampling rate is used. The network with the excluded ID (being deleted) is ignored.
+func (n *ovn) flowsSetup(excludeID int64) error {
+	var projectNetworks map[string]map[int64]api.Network
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		projectNetworks, err = tx.GetCreatedNetworks(ctx)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to load all networks: %w", err)
+	}
+
+	exports := map[string]*ovs.BridgeFlowExport{}
+
+	for _, networks := range projectNetworks {
+		for networkID, network := range networks {
+			if networkID == excludeID || network.Type != "ovn" || network.Config["flows.target"] == "" {
+				continue
+			}
+
+			sampling, err := flowsSampling(network.Config, flowsSamplingOVS)
+			if err != nil {
+				return err
+			}
+
+			protocol := flowsProtocol(network.Config)
+
+			export := exports[protocol]
+			if export == nil {
+				export = &ovs.BridgeFlowExport{Protocol: protocol, Sampling: sampling}
+				exports[protocol] = export
+			}
+
+			export.Sampling = min(export.Sampling, sampling)
+
+			for _, target := range util.SplitNTrimSpace(network.Config["flows.target"], ",", -1, true) {
+				if !slices.Contains(export.Targets, target) {
+					export.Targets = append(export.Targets, target)
+				}
+			}
+		}
+	}
+
+	var bridgeExports []ovs.BridgeFlowExport
+
+	for _, protocol := range []string{"ipfix", "sflow"} {
+		export := exports[protocol]
+		if export == nil {
+			continue
+		}
+
+		slices.Sort(export.Targets)
+		bridgeExports = append(bridgeExports, *export)
+	}
+
+	vswitch, err := n.state.OVS()
+	if err != nil {
+		return fmt.Errorf("Failed to connect to OVS: %w", err)
+	}
+
+	return vswitch.SetBridgeFlowExport(context.TODO(), n.state.GlobalConfig.NetworkOVNIntegrationBridge(), bridgeExports...)
+}
+
 // getInstanceDevicePortName returns the switch port name to use for an instance device.
 func (n *ovn) getInstanceDevicePortName(instanceUUID string, deviceName string) networkOVN.OVNSwitchPort {
 	return networkOVN.OVNSwitchPort(fmt.Sprintf("%s-%s-%s", n.getIntSwitchInstancePortPrefix(), instanceUUID, deviceName))
 }
 
+// InstanceDevicePortName returns the switch port name of an instance device.
+func (n *ovn) InstanceDevicePortName(instanceUUID string, deviceName string) networkOVN.OVNSwitchPort {
+	return n.getInstanceDevicePortName(instanceUUID, deviceName)
+}
+
 // instanceDevicePortRoutesParse parses the instance NIC device config for internal routes and external routes.
 func (n *ovn) instanceDevicePortRoutesParse(devConfig map[string]string) ([]*net.IPNet, []*net.IPNet, error) {
 	var err error
@@ -4816,6 +5066,22 @@ func (n *ovn) InstanceDevicePortStart(opts *OVNInstanceNICSetupOpts, securityACL
 	ipv4 := opts.DeviceConfig["ipv4.address"]
 	ipv6 := opts.DeviceConfig["ipv6.address"]
 
+	// Use the addresses reserved for the MAC address of the NIC, unless set on the NIC.
+	if ipv4 == "" || ipv6 == "" {
+		reservedIPv4, reservedIPv6, err := n.LeaseAddresses(opts.DeviceConfig["hwaddr"])
+		if err != nil {
+			return "", nil, err
+		}
+
+		if ipv4 == "" && reservedIPv4 != nil {
+			ipv4 = reservedIPv4.String()
+		}
+
+		if ipv6 == "" && reservedIPv6 != nil {
+			ipv6 = reservedIPv6.String()
+		}
+	}
+
 	internalRoutes, externalRoutes, err := n.instanceDevicePortRoutesParse(opts.DeviceConfig)
 	if err != nil {
 		return "", nil, fmt.Errorf("Failed parsing NIC device routes: %w", err)
@@ -4849,7 +5115,7 @@ func (n *ovn) InstanceDevicePortStart(opts *OVNInstanceNICSetupOpts, securityACL
 	if dhcpv4Subnet != nil && !portExists {
 		// If using dynamic IPv4, look for previously used sticky IPs from the NIC's last state.
 		var dhcpV4StickyIP net.IP
-		if opts.DeviceConfig["ipv4.address"] == "" {
+		if ipv4 == "" {
 			for _, entry := range opts.LastStateIPs {
 				if entry.To4() != nil && SubnetContainsIP(dhcpv4Subnet, entry) {
 					dhcpV4StickyIP = entry
@@ -5015,6 +5281,14 @@ func (n *ovn) InstanceDevicePortStart(opts *OVNInstanceNICSetupOpts, securityACL
 		})
 	}
 
+	// Apply the network egress gateways to the NIC addresses.
+	if n.config["network"] != "none" {
+		err = n.egressGatewaySetup()
+		if err != nil {
+			return "", nil, fmt.Errorf("Failed applying network egress gateways: %w", err)
+		}
+	}
+
 	if n.config["dns.mode"] == "managed" || n.config["dns.mode"] == "" {
 		dnsName := fmt.Sprintf("%s.%s", opts.DNSName, n.getDomainName())
 		dnsUUID, err := n.ovnnb.UpdateLogicalSwitchPortDNS(context.TODO(), n.getIntSwitchName(), instancePortName, dnsName, dnsIPs)
@@ -5373,30 +5647,47 @@ func (n *ovn) InstanceDevicePortStart(opts *OVNInstanceNICSetupOpts, securityACL
 		n.logger.Debug("Cleared NIC default rule", logger.Ctx{"port": instancePortName})
 	}
 
+	rules, err := n.instanceDevicePortQoSRules(instancePortName, opts.DeviceConfig, opts.QoSClass)
+	if err != nil {
+		return "", nil, err
+	}
+
+	err = n.ovnnb.SetLogicalSwitchQoSRules(context.TODO(), n.getIntSwitchName(), instancePortName, rules...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	reverter.Success()
+	return instancePortName, dnsIPs, nil
+}
+
+// instanceDevicePortQoSRules returns the QoS rules of the instance NIC port from its limits and QoS class.
+func (n *ovn) instanceDevicePortQoSRules(instancePortName networkOVN.OVNSwitchPort, devConfig deviceConfig.Device, qosClass string) ([]networkOVN.OVNQoSRule, error) {
+	var err error
 	var qosPriority uint64
-	if opts.DeviceConfig["limits.priority"] != "" {
-		qosPriority, err = strconv.ParseUint(opts.DeviceConfig["limits.priority"], 10, 32)
+	if devConfig["limits.priority"] != "" {
+		qosPriority, err = strconv.ParseUint(devConfig["limits.priority"], 10, 32)
 		if err != nil {
-			return "", nil, fmt.Errorf("Failed to parse limits.priority %q: %w", opts.DeviceConfig["limits.priority"], err)
+			return nil, fmt.Errorf("Failed to parse limits.priority %q: %w", devConfig["limits.priority"], err)
 		}
 	} else {
 		qosPriority = 100
 	}
 
-	egressRate, err := units.ParseBitSizeString(opts.DeviceConfig["limits.egress"])
+	egressRate, err := units.ParseBitSizeString(devConfig["limits.egress"])
 	if err != nil {
-		return "", nil, fmt.Errorf("Failed converting limits.egress to int: %w", err)
+		return nil, fmt.Errorf("Failed converting limits.egress to int: %w", err)
 	}
 
-	ingressRate, err := units.ParseBitSizeString(opts.DeviceConfig["limits.ingress"])
+	ingressRate, err := units.ParseBitSizeString(devConfig["limits.ingress"])
 	if err != nil {
-		return "", nil, fmt.Errorf("Failed converting limits.ingress to int: %w", err)
+		return nil, fmt.Errorf("Failed converting limits.ingress to int: %w", err)
 	}
 
-	if opts.DeviceConfig["limits.max"] != "" {
-		maxRate, err := units.ParseBitSizeString(opts.DeviceConfig["limits.max"])
+	if devConfig["limits.max"] != "" {
+		maxRate, err := units.ParseBitSizeString(devConfig["limits.max"])
 		if err != nil {
-			return "", nil, fmt.Errorf("Failed converting limits.max to int: %w", err)
+			return nil, fmt.Errorf("Failed converting limits.max to int: %w", err)
 		}
 
 		// Overwrite the egress and ingress rate limits if the max rate limit is set.
@@ -5404,23 +5695,45 @@ func (n *ovn) InstanceDevicePortStart(opts *OVNInstanceNICSetupOpts, securityACL
 		egressRate = maxRate
 	}
 
+	// Apply the QoS class of the NIC, its own limits take precedence over the class NIC ceiling.
+	var dscp string
+	for _, class := range qosClasses(n.config) {
+		if class.name != qosClass {
+			continue
+		}
+
+		if ingressRate == 0 {
+			ingressRate = class.nicCeiling
+		}
+
+		dscp = class.dscp
+	}
+
 	var rules []networkOVN.OVNQoSRule
-	if opts.DeviceConfig["limits.egress"] != "" || opts.DeviceConfig["limits.max"] != "" {
-		egressRate /= 1000
+	if devConfig["limits.egress"] != "" || devConfig["limits.max"] != "" || dscp != "" {
 		egressRule := networkOVN.OVNQoSRule{
 			Direction: ovnNB.QoSDirectionFromLport,
 			Action:    map[string]int{},
-			Bandwidth: map[string]int{
-				"rate": int(egressRate),
-			},
-			Match:    fmt.Sprintf("inport == \"%s\"", instancePortName),
-			Priority: int(qosPriority),
+			Bandwidth: map[string]int{},
+			Match:     fmt.Sprintf("inport == \"%s\"", instancePortName),
+			Priority:  int(qosPriority),
+		}
+
+		if devConfig["limits.egress"] != "" || devConfig["limits.max"] != "" {
+			egressRule.Bandwidth["rate"] = int(egressRate / 1000)
+		}
+
+		if dscp != "" {
+			egressRule.Action["dscp"], err = strconv.Atoi(dscp)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid DSCP value %q: %w", dscp, err)
+			}
 		}
 
 		rules = append(rules, egressRule)
 	}
 
-	if opts.DeviceConfig["limits.ingress"] != "" || opts.DeviceConfig["limits.max"] != "" {
+	if devConfig["limits.ingress"] != "" || devConfig["limits.max"] != "" || ingressRate > 0 {
 		ingressRate /= 1000
 		ingressRule := networkOVN.OVNQoSRule{
 			Direction: ovnNB.QoSDirectionToLport,
@@ -5435,13 +5748,52 @@ func (n *ovn) InstanceDevicePortStart(opts *OVNInstanceNICSetupOpts, securityACL
 		rules = append(rules, ingressRule)
 	}
 
-	err = n.ovnnb.SetLogicalSwitchQoSRules(context.TODO(), n.getIntSwitchName(), instancePortName, rules...)
+	return rules, nil
+}
+
+// qosApply re-applies the QoS rules of the started instance NIC ports so that they follow the QoS classes.
+func (n *ovn) qosApply() error {
+	// Get list of active switch ports (avoids repeated querying of OVN NB).
+	activePorts, err := n.ovnnb.GetLogicalSwitchActivePorts(context.TODO(), n.getIntSwitchName())
 	if err != nil {
-		return "", nil, err
+		return fmt.Errorf("Failed getting active ports: %w", err)
 	}
 
-	reverter.Success()
-	return instancePortName, dnsIPs, nil
+	return n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.InstanceList(ctx, func(inst db.InstanceArgs, p api.Project) error {
+			// Skip instances who's effective network project doesn't match this network's project.
+			if n.Project() != project.NetworkProjectForNameFromRecord(&p, n.Name()) {
+				return nil
+			}
+
+			devices := db.ExpandInstanceDevices(inst.Devices.Clone(), inst.Profiles)
+
+			for devName, devConfig := range devices {
+				if devConfig["type"] != "nic" || n.Name() != devConfig["network"] {
+					continue
+				}
+
+				// Skip the ports that aren't started.
+				instancePortName := n.getInstanceDevicePortName(inst.Config["volatile.uuid"], devName)
+				_, found := activePorts[instancePortName]
+				if !found {
+					continue
+				}
+
+				rules, err := n.instanceDevicePortQoSRules(instancePortName, devConfig, QoSNICClass(n.config, devConfig, p.Config))
+				if err != nil {
+					return err
+				}
+
+				err = n.ovnnb.SetLogicalSwitchQoSRules(ctx, n.getIntSwitchName(), instancePortName, rules...)
+				if err != nil {
+					return fmt.Errorf("Failed applying QoS rules of instance %q NIC %q: %w", inst.Name, devName, err)
+				}
+			}
+
+			return nil
+		})
+	})
 }
 
 // instanceDeviceACLDefaults returns the action and logging mode to use for the specified direction's default rule.
@@ -5538,6 +5890,12 @@ func (n *ovn) InstanceDevicePortStop(ovsExternalOVNPort networkOVN.OVNSwitchPort
 		return err
 	}
 
+	// Remove any traffic mirror of the logical switch port.
+	err = n.ovnnb.SetLogicalSwitchPortMirror(context.TODO(), instancePortName, nil)
+	if err != nil && !errors.Is(err, networkOVN.ErrNotFound) {
+		return err
+	}
+
 	var removeRoutes []net.IPNet
 	var removeARPProxyIPNets []net.IPNet
 
@@ -5635,6 +5993,32 @@ func (n *ovn) InstanceDevicePortStop(ovsExternalOVNPort networkOVN.OVNSwitchPort
 		}
 	}
 
+	// Tear down the egress gateway SNAT rules of the NIC addresses.
+	gateways, err := n.egressGatewaysLoad()
+	if err != nil {
+		return err
+	}
+
+	if len(gateways) > 0 {
+		portIPs, err := n.ovnnb.GetLogicalSwitchPortIPs(context.TODO(), instancePortName)
+		if err != nil && !errors.Is(err, networkOVN.ErrNotFound) {
+			return fmt.Errorf("Failed getting instance switch port addresses: %w", err)
+		}
+
+		portNets := make([]*net.IPNet, 0, len(portIPs))
+		for _, portIP := range portIPs {
+			portNet := IPToNet(portIP)
+			portNets = append(portNets, &portNet)
+		}
+
+		if len(portNets) > 0 {
+			err = n.ovnnb.DeleteLogicalRouterNATByLogicalIP(context.TODO(), n.getRouterName(), "snat", portNets...)
+			if err != nil {
+				return fmt.Errorf("Failed removing egress gateway SNAT rules: %w", err)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -6030,6 +6414,7 @@ func (n *ovn) handleDependencyChange(uplinkName string, uplinkConfig map[string]
 							DeviceName:   devName,
 							DeviceConfig: devConfig,
 							UplinkConfig: uplinkConfig,
+							QoSClass:     QoSNICClass(n.config, devConfig, p.Config),
 						}, nil)
 						if err != nil {
 							n.logger.Error("Failed re-adding instance OVN NIC port", logger.Ctx{"project": inst.Project, "instance": inst.Name, "err": err})
@@ -6053,26 +6438,143 @@ func (n *ovn) handleDependencyChange(uplinkName string, uplinkConfig map[string]
 		}
 	}
 
+	// Refresh the BGP route import if the uplink peers changed or the default routes were set up again.
+	if slices.ContainsFunc(changedKeys, func(k string) bool {
+		return strings.HasPrefix(k, "bgp.peers.") || slices.Contains(watchedKeys, k) || slices.Contains(uplinkKeys, k)
+	}) {
+		err := n.bgpImportSetupUplink(uplinkConfig)
+		if err != nil {
+			return fmt.Errorf("Failed setting up BGP route import: %w", err)
+		}
+	}
+
 	return nil
 }
 
-// forwardFlattenVIPs flattens forwards into format compatible with OVN load balancers.
-func (n *ovn) forwardFlattenVIPs(listenAddress net.IP, portMaps []*forwardPortMap) []networkOVN.OVNLoadBalancerVIP {
-	var vips []networkOVN.OVNLoadBalancerVIP
+// bgpImportSetup sets up the import of the routes learned through the uplink's BGP peers.
+func (n *ovn) bgpImportSetup() error {
+	if n.config["network"] == "none" {
+		return n.bgpImportSetupUplink(nil)
+	}
 
-	for _, portMap := range portMaps {
-		targetPortsLen := len(portMap.target.ports)
+	// Uplink network must be in default project.
+	uplinkNet, err := LoadByName(n.state, api.ProjectDefaultName, n.config["network"])
+	if err != nil {
+		return fmt.Errorf("Failed loading uplink network %q: %w", n.config["network"], err)
+	}
 
-		for i, lp := range portMap.listenPorts {
-			targetPort := lp // Default to using same port as listen port for target port.
+	return n.bgpImportSetupUplink(uplinkNet.Config())
+}
 
-			if targetPortsLen == 1 {
-				// If a single target port is specified, forward all listen ports to it.
-				targetPort = portMap.target.ports[0]
-			} else if targetPortsLen > 1 {
-				// If more than 1 target port specified, use listen port index to get the
-				// target port to use.
-				targetPort = portMap.target.ports[i]
+// bgpImportSetupUplink sets up the import of the routes learned through the BGP peers of the uplink network
+// with the provided configuration.
+func (n *ovn) bgpImportSetupUplink(uplinkConfig map[string]string) error {
+	return n.bgpSetupImport(n.config["bgp.import.filter"], n.bgpGetPeers(uplinkConfig), func(oldRoutes []bgp.Route, newRoutes []bgp.Route) error {
+		return n.bgpImportRouterRoutes(uplinkConfig, oldRoutes, newRoutes)
+	})
+}
+
+// bgpImportRouterRoutes installs the routes learned through the uplink's BGP peers into the logical router.
+// Learned routes replace the static routes for the same prefixes, the default routes through the uplink gateway
+// being restored once no longer learned. Only the member hosting the active chassis applies the routes.
+func (n *ovn) bgpImportRouterRoutes(uplinkConfig map[string]string, oldRoutes []bgp.Route, newRoutes []bgp.Route) error {
+	chassisName, err := n.getActiveChassisName()
+	if err != nil {
+		return err
+	}
+
+	if chassisName != n.state.ServerName && chassisName != n.state.OS.Hostname {
+		return bgp.ErrImportSkipped
+	}
+
+	// Remove the routes for all the old and new prefixes.
+	prefixes := []net.IPNet{}
+	for _, route := range slices.Concat(oldRoutes, newRoutes) {
+		if !slices.ContainsFunc(prefixes, func(prefix net.IPNet) bool { return prefix.String() == route.Prefix.String() }) {
+			prefixes = append(prefixes, route.Prefix)
+		}
+	}
+
+	if len(prefixes) > 0 {
+		err = n.ovnnb.DeleteLogicalRouterRoute(context.TODO(), n.getRouterName(), prefixes...)
+		if err != nil {
+			return fmt.Errorf("Failed removing routes: %w", err)
+		}
+	}
+
+	routes := make([]networkOVN.OVNRouterRoute, 0, len(newRoutes))
+	for _, route := range newRoutes {
+		// Link-local next hops of unnumbered peers can't be reached from the router.
+		if route.Interface != "" || (route.Prefix.IP.To4() == nil) != (route.Nexthop.To4() == nil) {
+			continue
+		}
+
+		routes = append(routes, networkOVN.OVNRouterRoute{
+			Prefix:  route.Prefix,
+			NextHop: route.Nexthop,
+			Port:    n.getRouterExtPortName(),
+		})
+	}
+
+	// Restore the default routes through the uplink gateway.
+	for _, ipVersion := range []uint{4, 6} {
+		defaultRoute := net.IPNet{IP: net.IPv4zero, Mask: net.CIDRMask(0, 32)}
+		if ipVersion == 6 {
+			defaultRoute = net.IPNet{IP: net.IPv6zero, Mask: net.CIDRMask(0, 128)}
+		}
+
+		if !slices.ContainsFunc(prefixes, func(prefix net.IPNet) bool { return prefix.String() == defaultRoute.String() }) {
+			continue
+		}
+
+		if slices.ContainsFunc(routes, func(route networkOVN.OVNRouterRoute) bool { return route.Prefix.String() == defaultRoute.String() }) {
+			continue
+		}
+
+		gatewayCIDR := uplinkConfig[fmt.Sprintf("ipv%d.address", ipVersion)]
+		if gatewayCIDR == "" {
+			gatewayCIDR = uplinkConfig[fmt.Sprintf("ipv%d.gateway", ipVersion)]
+		}
+
+		gateway, _, err := net.ParseCIDR(gatewayCIDR)
+		if err != nil {
+			continue
+		}
+
+		routes = append(routes, networkOVN.OVNRouterRoute{
+			Prefix:  defaultRoute,
+			NextHop: gateway,
+			Port:    n.getRouterExtPortName(),
+		})
+	}
+
+	if len(routes) > 0 {
+		err = n.ovnnb.CreateLogicalRouterRoute(context.TODO(), n.getRouterName(), true, routes...)
+		if err != nil {
+			return fmt.Errorf("Failed adding routes: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// forwardFlattenVIPs flattens forwards into format compatible with OVN load balancers.
+func (n *ovn) forwardFlattenVIPs(listenAddress net.IP, portMaps []*forwardPortMap) []networkOVN.OVNLoadBalancerVIP {
+	var vips []networkOVN.OVNLoadBalancerVIP
+
+	for _, portMap := range portMaps {
+		targetPortsLen := len(portMap.target.ports)
+
+		for i, lp := range portMap.listenPorts {
+			targetPort := lp // Default to using same port as listen port for target port.
+
+			if targetPortsLen == 1 {
+				// If a single target port is specified, forward all listen ports to it.
+				targetPort = portMap.target.ports[0]
+			} else if targetPortsLen > 1 {
+				// If more than 1 target port specified, use listen port index to get the
+				// target port to use.
+				targetPort = portMap.target.ports[i]
 			}
 
 			vips = append(vips, networkOVN.OVNLoadBalancerVIP{
@@ -6591,21 +7093,9 @@ func (n *ovn) loadBalancerFlattenVIPs(listenAddress net.IP, portMaps []*loadBala
 			}
 
 			for _, target := range portMap.targets {
-				targetPort := lp // Default to using same port as listen port for target port.
-				targetPortsLen := len(target.ports)
-
-				if targetPortsLen == 1 {
-					// If a single target port is specified, forward all listen ports to it.
-					targetPort = target.ports[0]
-				} else if targetPortsLen > 1 {
-					// If more than 1 target port specified, use listen port index to get the
-					// target port to use.
-					targetPort = target.ports[i]
-				}
-
 				vip.Targets = append(vip.Targets, networkOVN.OVNLoadBalancerTarget{
 					Address: target.address,
-					Port:    targetPort,
+					Port:    portMap.targetPort(target, i),
 				})
 			}
 
@@ -7154,6 +7644,377 @@ func (n *ovn) LoadBalancerDelete(listenAddress string, clientType request.Client
 	return nil
 }
 
+// egressGatewaySNATs returns the SNAT rules of the instance NICs using the egress gateways, as a map of NIC
+// address to egress gateway address.
+func (n *ovn) egressGatewaySNATs(gateways []*api.NetworkEgressGateway) (map[string]net.IP, error) {
+	snats := map[string]net.IP{}
+
+	if len(gateways) == 0 {
+		return snats, nil
+	}
+
+	err := UsedByInstanceDevices(n.state, n.project, n.name, n.netType, func(inst db.InstanceArgs, nicName string, nicConfig map[string]string) error {
+		gatewayV4 := egressGatewaySelect(gateways, n.project, &inst, false)
+		gatewayV6 := egressGatewaySelect(gateways, n.project, &inst, true)
+		if gatewayV4 == nil && gatewayV6 == nil {
+			return nil
+		}
+
+		// The addresses are only known once the instance was started.
+		instancePortName := n.getInstanceDevicePortName(inst.Config["volatile.uuid"], nicName)
+		portIPs, err := n.ovnnb.GetLogicalSwitchPortIPs(context.TODO(), instancePortName)
+		if err != nil {
+			if errors.Is(err, networkOVN.ErrNotFound) {
+				return nil
+			}
+
+			return fmt.Errorf("Failed getting addresses of instance switch port %q: %w", instancePortName, err)
+		}
+
+		for _, portIP := range portIPs {
+			keyPrefix := "ipv4"
+			gateway := gatewayV4
+			if portIP.To4() == nil {
+				keyPrefix = "ipv6"
+				gateway = gatewayV6
+			}
+
+			// The external address of the NIC takes precedence over the egress gateways.
+			if gateway == nil || nicConfig[keyPrefix+".address.external"] != "" || portIP.IsLinkLocalUnicast() {
+				continue
+			}
+
+			snats[portIP.String()] = net.ParseIP(gateway.Address)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed finding instances using egress gateways: %w", err)
+	}
+
+	return snats, nil
+}
+
+// egressGatewaySetup applies the SNAT rules of the network egress gateways. It removes the rules of the egress
+// gateways which no longer apply, including the ones of the removedAddresses egress gateways.
+func (n *ovn) egressGatewaySetup(removedAddresses ...net.IP) error {
+	gateways, err := n.egressGatewaysLoad()
+	if err != nil {
+		return err
+	}
+
+	if len(gateways) == 0 && len(removedAddresses) == 0 {
+		return nil
+	}
+
+	snats, err := n.egressGatewaySNATs(gateways)
+	if err != nil {
+		return err
+	}
+
+	gatewayAddresses := make([]string, 0, len(gateways)+len(removedAddresses))
+	for _, gateway := range gateways {
+		gatewayAddresses = append(gatewayAddresses, gateway.Address)
+	}
+
+	for _, address := range removedAddresses {
+		gatewayAddresses = append(gatewayAddresses, address.String())
+	}
+
+	// Remove the rules of the egress gateways which no longer apply.
+	natRules, err := n.ovnnb.GetLogicalRouterNATs(context.TODO(), n.getRouterName())
+	if err != nil {
+		return fmt.Errorf("Failed getting router NAT rules: %w", err)
+	}
+
+	var staleNets []*net.IPNet
+	for _, natRule := range natRules {
+		if natRule.Type != "snat" || !slices.Contains(gatewayAddresses, natRule.ExternalIP) {
+			continue
+		}
+
+		logicalNet, err := ParseIPCIDRToNet(natRule.LogicalIP)
+		if err != nil {
+			logicalNet, err = ParseIPToNet(natRule.LogicalIP)
+			if err != nil {
+				continue
+			}
+		}
+
+		// Only consider the rules of single addresses, not the ones of the network subnets.
+		ones, bits := logicalNet.Mask.Size()
+		if ones != bits {
+			continue
+		}
+
+		extIP, found := snats[logicalNet.IP.String()]
+		if found && extIP.String() == natRule.ExternalIP {
+			continue
+		}
+
+		staleNets = append(staleNets, logicalNet)
+	}
+
+	if len(staleNets) > 0 {
+		err = n.ovnnb.DeleteLogicalRouterNATByLogicalIP(context.TODO(), n.getRouterName(), "snat", staleNets...)
+		if err != nil {
+			return fmt.Errorf("Failed removing egress gateway SNAT rules: %w", err)
+		}
+	}
+
+	// Add the rules, replacing any other SNAT rule of the instance NIC addresses.
+	for logicalIP, extIP := range snats {
+		intNet := IPToNet(net.ParseIP(logicalIP))
+
+		err = n.ovnnb.CreateLogicalRouterNAT(context.TODO(), n.getRouterName(), "snat", &intNet, extIP, nil, false, true)
+		if err != nil {
+			return fmt.Errorf("Failed adding egress gateway SNAT rule for %q: %w", logicalIP, err)
+		}
+	}
+
+	return nil
+}
+
+// egressGatewayValidateAddress checks the egress gateway address is allowed on the uplink and doesn't conflict
+// with any other external address.
+func (n *ovn) egressGatewayValidateAddress(address string) (*net.IPNet, error) {
+	addressNet, err := ParseIPToNet(address)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing egress gateway address %q: %w", address, err)
+	}
+
+	// Load the project to get uplink network restrictions.
+	var p *api.Project
+	var uplink *api.Network
+
+	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		allAllocatedIPv4, allAllocatedIPv6, err := n.uplinkAllAllocatedIPs(ctx, tx, n.config["network"])
+		if err != nil {
+			return err
+		}
+
+		networkIPv4 := net.ParseIP(n.config[ovnVolatileUplinkIPv4])
+		networkIPv6 := net.ParseIP(n.config[ovnVolatileUplinkIPv6])
+
+		for _, usedIP := range append(allAllocatedIPv4, allAllocatedIPv6...) {
+			// Skip our own network because its a valid overlap.
+			if usedIP.Equal(networkIPv4) || usedIP.Equal(networkIPv6) {
+				continue
+			}
+
+			if usedIP.Equal(addressNet.IP) {
+				return fmt.Errorf("Egress gateway address %q overlaps with another network or NIC", address)
+			}
+		}
+
+		project, err := dbCluster.GetProject(ctx, tx.Tx(), n.project)
+		if err != nil {
+			return fmt.Errorf("Failed to load network restrictions from project %q: %w", n.project, err)
+		}
+
+		p, err = project.ToAPI(ctx, tx.Tx())
+		if err != nil {
+			return fmt.Errorf("Failed to load network restrictions from project %q: %w", n.project, err)
+		}
+
+		_, uplink, _, err = tx.GetNetworkInAnyState(ctx, api.ProjectDefaultName, n.config["network"])
+		if err != nil {
+			return fmt.Errorf("Failed to load uplink network %q: %w", n.config["network"], err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Get project restricted routes.
+	projectRestrictedSubnets, err := n.projectRestrictedSubnets(p, n.config["network"])
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the address is allowed within both the uplink's external routes and any project restricted subnets.
+	err = n.validateExternalSubnet(uplink, projectRestrictedSubnets, addressNet)
+	if err != nil {
+		return nil, err
+	}
+
+	externalSubnetsInUse, err := n.getExternalSubnetInUse(n.config["network"])
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the address doesn't fall within any existing OVN network external subnets.
+	for _, externalSubnetUser := range externalSubnetsInUse {
+		// Skip checking conflict with our own network's subnet or SNAT address.
+		if externalSubnetUser.networkProject == n.project && externalSubnetUser.networkName == n.name {
+			if externalSubnetUser.usageType == subnetUsageNetwork || externalSubnetUser.usageType == subnetUsageNetworkSNAT {
+				continue
+			}
+		}
+
+		if SubnetContains(&externalSubnetUser.subnet, addressNet) || SubnetContains(addressNet, &externalSubnetUser.subnet) {
+			// This error is purposefully vague so that it doesn't reveal any names of
+			// resources potentially outside of the network's project.
+			return nil, fmt.Errorf("Egress gateway address %q overlaps with another network or NIC", addressNet.String())
+		}
+	}
+
+	return addressNet, nil
+}
+
+// EgressGatewayCreate creates a network egress gateway.
+func (n *ovn) EgressGatewayCreate(gateway api.NetworkEgressGatewaysPost, clientType request.ClientType) error {
+	if n.config["network"] == "none" {
+		return errors.New("Isolated OVN network cannot use network egress gateways")
+	}
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	if clientType == request.ClientTypeNormal {
+		gateways, err := n.egressGatewaysLoad()
+		if err != nil {
+			return err
+		}
+
+		for _, other := range gateways {
+			if other.Address == gateway.Address {
+				return api.StatusErrorf(http.StatusConflict, "An egress gateway for that address already exists")
+			}
+		}
+
+		addressNet, err := n.egressGatewayValidateAddress(gateway.Address)
+		if err != nil {
+			return err
+		}
+
+		err = n.egressGatewayValidate(addressNet.IP, &gateway.NetworkEgressGatewayPut, gateways)
+		if err != nil {
+			return err
+		}
+
+		err = n.egressGatewayCreateRecord(&api.NetworkEgressGateway{
+			Address:                 gateway.Address,
+			NetworkEgressGatewayPut: gateway.NetworkEgressGatewayPut,
+		})
+		if err != nil {
+			return err
+		}
+
+		reverter.Add(func() {
+			_, _ = n.egressGatewayDeleteRecord(gateway.Address)
+			_ = n.egressGatewaySetup(addressNet.IP)
+		})
+
+		err = n.egressGatewaySetup()
+		if err != nil {
+			return err
+		}
+	}
+
+	reverter.Success()
+
+	return nil
+}
+
+// EgressGatewayUpdate updates a network egress gateway.
+func (n *ovn) EgressGatewayUpdate(address string, req api.NetworkEgressGatewayPut, clientType request.ClientType) error {
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	if clientType == request.ClientTypeNormal {
+		gateways, err := n.egressGatewaysLoad()
+		if err != nil {
+			return err
+		}
+
+		var curGateway *api.NetworkEgressGateway
+		for _, gateway := range gateways {
+			if gateway.Address == address {
+				curGateway = gateway
+				break
+			}
+		}
+
+		if curGateway == nil {
+			return api.StatusErrorf(http.StatusNotFound, "Network egress gateway not found")
+		}
+
+		err = n.egressGatewayValidate(net.ParseIP(curGateway.Address), &req, gateways)
+		if err != nil {
+			return err
+		}
+
+		curEtagHash, err := localUtil.EtagHash(curGateway.Etag())
+		if err != nil {
+			return err
+		}
+
+		newGateway := api.NetworkEgressGateway{
+			Address:                 curGateway.Address,
+			NetworkEgressGatewayPut: req,
+		}
+
+		newEtagHash, err := localUtil.EtagHash(newGateway.Etag())
+		if err != nil {
+			return err
+		}
+
+		if curEtagHash == newEtagHash {
+			return nil // Nothing has changed.
+		}
+
+		err = n.egressGatewayUpdateRecord(&newGateway)
+		if err != nil {
+			return err
+		}
+
+		reverter.Add(func() {
+			_ = n.egressGatewayUpdateRecord(curGateway)
+			_ = n.egressGatewaySetup()
+		})
+
+		err = n.egressGatewaySetup()
+		if err != nil {
+			return err
+		}
+	}
+
+	reverter.Success()
+
+	return nil
+}
+
+// EgressGatewayDelete deletes a network egress gateway.
+func (n *ovn) EgressGatewayDelete(address string, clientType request.ClientType) error {
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	if clientType == request.ClientTypeNormal {
+		gateway, err := n.egressGatewayDeleteRecord(address)
+		if err != nil {
+			return err
+		}
+
+		reverter.Add(func() {
+			_ = n.egressGatewayCreateRecord(gateway)
+			_ = n.egressGatewaySetup()
+		})
+
+		err = n.egressGatewaySetup(net.ParseIP(gateway.Address))
+		if err != nil {
+			return err
+		}
+	}
+
+	reverter.Success()
+
+	return nil
+}
+
 func (n *ovn) getHealthCheck(loadBalancer api.NetworkLoadBalancerPut) (*networkOVN.OVNLoadBalancerHealthCheck, error) {
 	// Check if load-balancer is enabled.
 	if !util.IsTrue(loadBalancer.Config["healthcheck"]) {
@@ -7227,6 +8088,16 @@ func (n *ovn) Leases(projectName string, clientType request.ClientType) ([]api.N
 				})
 			}
 		}
+
+		// Add the address reservations.
+		reservations, err := n.leasesLoad()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, reservation := range reservations {
+			leases = append(leases, *reservation)
+		}
 	}
 
 	// Get all the instances in the requested project that are connected to this network.
@@ -7415,6 +8286,10 @@ func (n *ovn) remotePeerCreate(peer api.NetworkPeersPost) error {
 		return fmt.Errorf("Failed to load network integration %q: %w", peer.TargetIntegration, err)
 	}
 
+	if integration.Type != "ovn" {
+		return api.StatusErrorf(http.StatusBadRequest, "OVN networks can only be peered through OVN integrations")
+	}
+
 	// Get ICNB.
 	icnb, err := networkOVN.NewICNB(integration.Config["ovn.northbound_connection"], integration.Config["ovn.ca_cert"], integration.Config["ovn.client_cert"], integration.Config["ovn.client_key"])
 	if err != nil {
@@ -7559,7 +8434,7 @@ func (n *ovn) remotePeerCreate(peer api.NetworkPeersPost) error {
 }
 
 // PeerCreate creates a network peering.
-func (n *ovn) PeerCreate(peer api.NetworkPeersPost) error {
+func (n *ovn) PeerCreate(peer api.NetworkPeersPost, clientType request.ClientType) error {
 	reverter := revert.New()
 	defer reverter.Fail()
 
@@ -7895,7 +8770,7 @@ func (n *ovn) peerSetup(ovnnb *networkOVN.NB, targetOVNNet *ovn, opts networkOVN
 }
 
 // PeerUpdate updates a network peering.
-func (n *ovn) PeerUpdate(peerName string, req api.NetworkPeerPut) error {
+func (n *ovn) PeerUpdate(peerName string, req api.NetworkPeerPut, clientType request.ClientType) error {
 	reverter := revert.New()
 	defer reverter.Fail()
 
@@ -8111,7 +8986,7 @@ func (n *ovn) remotePeerDelete(peer *api.NetworkPeer) error {
 }
 
 // PeerDelete deletes a network peering.
-func (n *ovn) PeerDelete(peerName string) error {
+func (n *ovn) PeerDelete(peerName string, clientType request.ClientType) error {
 	var peerID int64
 	var peer *api.NetworkPeer
 
@@ -177,6 +177,14 @@ func (n *physical) Validate(config map[string]string, clientType request.ClientT
 		// shortdesc: List of DNS server IPs on `physical` network
 		"dns.nameservers": validate.Optional(validate.IsListOf(validate.IsNetworkAddress)),
 
+		// gendoc:generate(entity=network_physical, group=bgp, key=bgp.import.filter)
+		//
+		// ---
+		// type: string
+		// condition: BGP server
+		// shortdesc: Comma-separated list of prefixes (optionally followed by `..` and the longest accepted prefix length) whose routes learned from the BGP peers are installed into the host routing table
+		"bgp.import.filter": validate.Optional(validateBGPImportFilter),
+
 		// gendoc:generate(entity=network_physical, group=ovn, key=ovn.ingress_mode)
 		//
 		// ---
@@ -229,6 +237,14 @@ func (n *physical) Validate(config map[string]string, clientType request.ClientT
 	// defaultdesc: `180`
 	// shortdesc: Peer session hold time (in seconds; optional)
 
+	// gendoc:generate(entity=network_physical, group=bgp, key=bgp.peers.NAME.bfd)
+	//
+	// ---
+	// type: bool
+	// condition: BGP server
+	// defaultdesc: `false`
+	// shortdesc: Whether to monitor the peer session through BFD (failures detected in under a second)
+
 	// Add the BGP validation rules.
 	bgpRules, err := n.bgpValidationRules(config)
 	if err != nil {
@@ -0,0 +1,210 @@
+package flows
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+// Conntrack netlink multicast groups (see linux/netfilter/nfnetlink_compat.h).
+const (
+	conntrackGroupNew     = 1
+	conntrackGroupDestroy = 3
+)
+
+// Conntrack netlink message types (see linux/netfilter/nfnetlink_conntrack.h).
+const (
+	conntrackMsgNew    = unix.NFNL_SUBSYS_CTNETLINK<<8 | 0
+	conntrackMsgDelete = unix.NFNL_SUBSYS_CTNETLINK<<8 | 2
+)
+
+// Conntrack netlink attributes (see linux/netfilter/nfnetlink_conntrack.h).
+const (
+	ctaTupleOrig      = 1
+	ctaTupleReply     = 2
+	ctaCountersOrig   = 9
+	ctaCountersReply  = 10
+	ctaZone           = 18
+	ctaTimestamp      = 20
+	ctaTupleIP        = 1
+	ctaTupleProto     = 2
+	ctaIPv4Src        = 1
+	ctaIPv4Dst        = 2
+	ctaIPv6Src        = 3
+	ctaIPv6Dst        = 4
+	ctaProtoNum       = 1
+	ctaProtoSrcPort   = 2
+	ctaProtoDstPort   = 3
+	ctaCountersPkts   = 1
+	ctaCountersBytes  = 2
+	ctaTimestampStart = 1
+	ctaTimestampStop  = 2
+)
+
+// conntrackEvent is a conntrack flow creation or destruction.
+type conntrackEvent struct {
+	destroy bool
+	flow    *netlink.ConntrackFlow
+}
+
+// conntrackSubscribe returns a netlink socket receiving the creation and destruction events of conntrack flows.
+func conntrackSubscribe() (*nl.NetlinkSocket, error) {
+	sock, err := nl.Subscribe(unix.NETLINK_NETFILTER, conntrackGroupNew, conntrackGroupDestroy)
+	if err != nil {
+		return nil, fmt.Errorf("Failed subscribing to conntrack events: %w", err)
+	}
+
+	// Short lived flows come in bursts, avoid losing their events.
+	err = sock.SetReceiveBufferSize(4*1024*1024, false)
+	if err != nil {
+		sock.Close()
+		return nil, fmt.Errorf("Failed setting conntrack events buffer size: %w", err)
+	}
+
+	return sock, nil
+}
+
+// parseConntrackEvent parses a conntrack netlink message into an event.
+func parseConntrackEvent(msg []byte, msgType uint16) (*conntrackEvent, error) {
+	if msgType != conntrackMsgNew && msgType != conntrackMsgDelete {
+		return nil, nil
+	}
+
+	if len(msg) < nl.SizeofNfgenmsg {
+		return nil, errors.New("Short conntrack message")
+	}
+
+	flow := &netlink.ConntrackFlow{FamilyType: msg[0]}
+
+	err := parseAttributes(msg[nl.SizeofNfgenmsg:], func(attrType uint16, value []byte) error {
+		switch attrType {
+		case ctaTupleOrig:
+			return parseTuple(value, &flow.Forward)
+		case ctaTupleReply:
+			return parseTuple(value, &flow.Reverse)
+		case ctaCountersOrig:
+			return parseCounters(value, &flow.Forward)
+		case ctaCountersReply:
+			return parseCounters(value, &flow.Reverse)
+		case ctaZone:
+			if len(value) >= 2 {
+				flow.Zone = binary.BigEndian.Uint16(value)
+			}
+
+		case ctaTimestamp:
+			return parseAttributes(value, func(attrType uint16, value []byte) error {
+				if len(value) < 8 {
+					return nil
+				}
+
+				switch attrType {
+				case ctaTimestampStart:
+					flow.TimeStart = binary.BigEndian.Uint64(value)
+				case ctaTimestampStop:
+					flow.TimeStop = binary.BigEndian.Uint64(value)
+				}
+
+				return nil
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &conntrackEvent{destroy: msgType == conntrackMsgDelete, flow: flow}, nil
+}
+
+// parseTuple parses the addresses, protocol and ports of a conntrack tuple.
+func parseTuple(data []byte, tuple *netlink.IPTuple) error {
+	return parseAttributes(data, func(attrType uint16, value []byte) error {
+		switch attrType {
+		case ctaTupleIP:
+			return parseAttributes(value, func(attrType uint16, value []byte) error {
+				switch attrType {
+				case ctaIPv4Src, ctaIPv6Src:
+					tuple.SrcIP = net.IP(value)
+				case ctaIPv4Dst, ctaIPv6Dst:
+					tuple.DstIP = net.IP(value)
+				}
+
+				return nil
+			})
+
+		case ctaTupleProto:
+			return parseAttributes(value, func(attrType uint16, value []byte) error {
+				switch attrType {
+				case ctaProtoNum:
+					if len(value) >= 1 {
+						tuple.Protocol = value[0]
+					}
+
+				case ctaProtoSrcPort:
+					if len(value) >= 2 {
+						tuple.SrcPort = binary.BigEndian.Uint16(value)
+					}
+
+				case ctaProtoDstPort:
+					if len(value) >= 2 {
+						tuple.DstPort = binary.BigEndian.Uint16(value)
+					}
+				}
+
+				return nil
+			})
+		}
+
+		return nil
+	})
+}
+
+// parseCounters parses the packets and bytes counters of a conntrack flow direction.
+func parseCounters(data []byte, tuple *netlink.IPTuple) error {
+	return parseAttributes(data, func(attrType uint16, value []byte) error {
+		if len(value) < 8 {
+			return nil
+		}
+
+		switch attrType {
+		case ctaCountersPkts:
+			tuple.Packets = binary.BigEndian.Uint64(value)
+		case ctaCountersBytes:
+			tuple.Bytes = binary.BigEndian.Uint64(value)
+		}
+
+		return nil
+	})
+}
+
+// parseAttributes calls f with the type, without flags, and the value of each netlink attribute of data.
+func parseAttributes(data []byte, f func(attrType uint16, value []byte) error) error {
+	for len(data) >= unix.SizeofNlAttr {
+		length := int(nl.NativeEndian().Uint16(data[0:2]))
+		attrType := nl.NativeEndian().Uint16(data[2:4]) & nl.NLA_TYPE_MASK
+
+		if length < unix.SizeofNlAttr || length > len(data) {
+			return fmt.Errorf("Invalid netlink attribute length %d", length)
+		}
+
+		err := f(attrType, data[unix.SizeofNlAttr:length])
+		if err != nil {
+			return err
+		}
+
+		aligned := (length + unix.NLA_ALIGNTO - 1) & ^(unix.NLA_ALIGNTO - 1)
+		if aligned >= len(data) {
+			break
+		}
+
+		data = data[aligned:]
+	}
+
+	return nil
+}
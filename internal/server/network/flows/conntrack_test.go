@@ -0,0 +1,122 @@
+package flows
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+// conntrackTestMessage returns a conntrack netlink message payload for a TCP flow.
+func conntrackTestMessage(src string, dst string, packets uint64, bytes uint64, replyPackets uint64, replyBytes uint64) []byte {
+	be16 := func(v uint16) []byte { return binary.BigEndian.AppendUint16(nil, v) }
+	be64 := func(v uint64) []byte { return binary.BigEndian.AppendUint64(nil, v) }
+
+	tuple := func(attrType int, src net.IP, dst net.IP, srcPort uint16, dstPort uint16) *nl.RtAttr {
+		t := nl.NewRtAttr(attrType|int(nl.NLA_F_NESTED), nil)
+		ip := t.AddRtAttr(ctaTupleIP|int(nl.NLA_F_NESTED), nil)
+		ip.AddRtAttr(ctaIPv4Src, src.To4())
+		ip.AddRtAttr(ctaIPv4Dst, dst.To4())
+		proto := t.AddRtAttr(ctaTupleProto|int(nl.NLA_F_NESTED), nil)
+		proto.AddRtAttr(ctaProtoNum, []byte{unix.IPPROTO_TCP})
+		proto.AddRtAttr(ctaProtoSrcPort, be16(srcPort))
+		proto.AddRtAttr(ctaProtoDstPort, be16(dstPort))
+
+		return t
+	}
+
+	counters := func(attrType int, packets uint64, bytes uint64) *nl.RtAttr {
+		c := nl.NewRtAttr(attrType|int(nl.NLA_F_NESTED), nil)
+		c.AddRtAttr(ctaCountersPkts, be64(packets))
+		c.AddRtAttr(ctaCountersBytes, be64(bytes))
+
+		return c
+	}
+
+	msg := []byte{unix.AF_INET, 0, 0, 0}
+	msg = append(msg, tuple(ctaTupleOrig, net.ParseIP(src), net.ParseIP(dst), 40000, 443).Serialize()...)
+	msg = append(msg, tuple(ctaTupleReply, net.ParseIP(dst), net.ParseIP(src), 443, 40000).Serialize()...)
+	msg = append(msg, counters(ctaCountersOrig, packets, bytes).Serialize()...)
+	msg = append(msg, counters(ctaCountersReply, replyPackets, replyBytes).Serialize()...)
+	msg = append(msg, nl.NewRtAttr(ctaZone, be16(3)).Serialize()...)
+
+	return msg
+}
+
+func TestParseConntrackEvent(t *testing.T) {
+	msg := conntrackTestMessage("10.0.0.2", "192.0.2.1", 3, 1500, 2, 800)
+
+	event, err := parseConntrackEvent(msg, conntrackMsgDelete)
+	require.NoError(t, err)
+	require.NotNil(t, event)
+
+	assert.True(t, event.destroy)
+	assert.Equal(t, uint8(unix.AF_INET), event.flow.FamilyType)
+	assert.Equal(t, uint16(3), event.flow.Zone)
+	assert.True(t, event.flow.Forward.SrcIP.Equal(net.ParseIP("10.0.0.2")))
+	assert.True(t, event.flow.Forward.DstIP.Equal(net.ParseIP("192.0.2.1")))
+	assert.Equal(t, uint16(40000), event.flow.Forward.SrcPort)
+	assert.Equal(t, uint16(443), event.flow.Forward.DstPort)
+	assert.Equal(t, uint8(unix.IPPROTO_TCP), event.flow.Forward.Protocol)
+	assert.Equal(t, uint64(3), event.flow.Forward.Packets)
+	assert.Equal(t, uint64(1500), event.flow.Forward.Bytes)
+	assert.Equal(t, uint64(2), event.flow.Reverse.Packets)
+	assert.Equal(t, uint64(800), event.flow.Reverse.Bytes)
+
+	event, err = parseConntrackEvent(msg, conntrackMsgNew)
+	require.NoError(t, err)
+	assert.False(t, event.destroy)
+
+	// Other messages are ignored.
+	event, err = parseConntrackEvent(msg, unix.NFNL_SUBSYS_CTNETLINK<<8|1)
+	require.NoError(t, err)
+	assert.Nil(t, event)
+
+	_, err = parseConntrackEvent(msg[:2], conntrackMsgNew)
+	assert.Error(t, err)
+
+	_, err = parseConntrackEvent(append(msg, 0xff, 0x00, 0x01, 0x00), conntrackMsgNew)
+	assert.Error(t, err)
+}
+
+func TestExporterDestroyEvent(t *testing.T) {
+	_, subnet, _ := net.ParseCIDR("10.0.0.0/24")
+	e := NewExporter(1, nil, 1, []*net.IPNet{subnet})
+
+	// A flow seen by the last poll only exports the traffic since.
+	event, err := parseConntrackEvent(conntrackTestMessage("10.0.0.2", "192.0.2.1", 3, 1500, 2, 800), conntrackMsgDelete)
+	require.NoError(t, err)
+
+	key, ok := e.flowKey(event.flow)
+	require.True(t, ok)
+	e.counters[key] = flowCounters{bytes: 1000, packets: 2, replyBytes: 800, replyPackets: 2}
+
+	e.handleEvent(event)
+	require.Len(t, e.ended, 1)
+	assert.Equal(t, uint64(500), e.ended[0].Bytes)
+	assert.Equal(t, uint64(1), e.ended[0].Packets)
+	assert.NotContains(t, e.counters, key)
+
+	// A flow which started and ended between two polls exports all its traffic.
+	e.ended = nil
+	event, err = parseConntrackEvent(conntrackTestMessage("192.0.2.1", "10.0.0.3", 1, 60, 1, 40), conntrackMsgDelete)
+	require.NoError(t, err)
+
+	e.handleEvent(event)
+	require.Len(t, e.ended, 2)
+	assert.Equal(t, uint64(60), e.ended[0].Bytes)
+	assert.Equal(t, uint64(40), e.ended[1].Bytes)
+	assert.True(t, e.ended[1].SrcIP.Equal(net.ParseIP("10.0.0.3")))
+
+	// Flows outside of the subnets are ignored.
+	e.ended = nil
+	event, err = parseConntrackEvent(conntrackTestMessage("192.0.2.1", "198.51.100.1", 1, 60, 1, 40), conntrackMsgDelete)
+	require.NoError(t, err)
+
+	e.handleEvent(event)
+	assert.Empty(t, e.ended)
+}
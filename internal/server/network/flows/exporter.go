@@ -0,0 +1,479 @@
+package flows
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+
+	localUtil "github.com/lxc/incus/v7/internal/server/util"
+	"github.com/lxc/incus/v7/shared/logger"
+)
+
+// exportInterval is how often the flow counters are exported.
+const exportInterval = 60 * time.Second
+
+// conntrackAcctSysctl is the sysctl enabling the conntrack bytes and packets counters.
+const conntrackAcctSysctl = "net/netfilter/nf_conntrack_acct"
+
+// conntrackAcct tracks the exporters relying on conntrack accounting so that the original setting is restored
+// once the last one stops.
+var conntrackAcct struct {
+	mu       sync.Mutex
+	users    int
+	previous string
+}
+
+// flowKey identifies a conntrack flow.
+type flowKey struct {
+	protocol uint8
+	src      netip.Addr
+	dst      netip.Addr
+	srcPort  uint16
+	dstPort  uint16
+	zone     uint16
+}
+
+// flowCounters holds the counters of both directions of a flow.
+type flowCounters struct {
+	bytes        uint64
+	packets      uint64
+	replyBytes   uint64
+	replyPackets uint64
+}
+
+// Exporter exports the conntrack flows of a set of subnets over IPFIX.
+type Exporter struct {
+	domainID uint32
+	targets  []string
+	sampling uint32
+	subnets  []*net.IPNet
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// mu protects the flow state shared between the conntrack events and the exports.
+	mu         sync.Mutex
+	conns      []net.Conn
+	counters   map[flowKey]flowCounters
+	starts     map[flowKey]time.Time
+	destroyed  map[flowKey]time.Time
+	ended      []Record
+	lastExport time.Time
+	sequence   uint32
+}
+
+// NewExporter returns a new exporter for the flows from or to the subnets. The flows are sampled by exporting
+// one in every sampling flows, and domainID is used as the IPFIX observation domain.
+func NewExporter(domainID uint32, targets []string, sampling uint32, subnets []*net.IPNet) *Exporter {
+	if sampling == 0 {
+		sampling = 1
+	}
+
+	return &Exporter{
+		domainID:  domainID,
+		targets:   targets,
+		sampling:  sampling,
+		subnets:   subnets,
+		counters:  map[flowKey]flowCounters{},
+		starts:    map[flowKey]time.Time{},
+		destroyed: map[flowKey]time.Time{},
+	}
+}
+
+// Start connects to the collectors and starts exporting the flows.
+func (e *Exporter) Start() error {
+	// Conntrack only counts bytes and packets with accounting enabled.
+	err := conntrackAcctEnable()
+	if err != nil {
+		return err
+	}
+
+	for _, target := range e.targets {
+		conn, err := net.Dial("udp", target)
+		if err != nil {
+			e.closeConns()
+			conntrackAcctRestore()
+			return fmt.Errorf("Failed connecting to flow collector %q: %w", target, err)
+		}
+
+		e.conns = append(e.conns, conn)
+	}
+
+	// Subscribe before listing the flows so that none ends unnoticed in between.
+	sock, err := conntrackSubscribe()
+	if err != nil {
+		e.closeConns()
+		conntrackAcctRestore()
+		return err
+	}
+
+	// Wake up the receive loop regularly to notice the exporter being stopped.
+	err = sock.SetReceiveTimeout(&unix.Timeval{Sec: 1})
+	if err != nil {
+		sock.Close()
+		e.closeConns()
+		conntrackAcctRestore()
+		return fmt.Errorf("Failed setting conntrack events timeout: %w", err)
+	}
+
+	// Only export the traffic from now on as the counters of the existing flows are cumulative.
+	e.lastExport = time.Now()
+
+	_, err = e.collect(e.lastExport)
+	if err != nil {
+		sock.Close()
+		e.closeConns()
+		conntrackAcctRestore()
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+
+	// Short lived flows can start and end between two exports, follow the conntrack events to get their
+	// final counters.
+	e.wg.Go(func() {
+		defer sock.Close()
+
+		for ctx.Err() == nil {
+			msgs, _, err := sock.Receive()
+			if err != nil {
+				if errors.Is(err, unix.EAGAIN) || errors.Is(err, unix.EINTR) {
+					continue
+				}
+
+				// The socket buffer overflowed, some flows will only be exported up to their last poll.
+				if errors.Is(err, unix.ENOBUFS) {
+					logger.Warn("Lost conntrack events", logger.Ctx{"targets": e.targets})
+					continue
+				}
+
+				logger.Warn("Failed receiving conntrack events", logger.Ctx{"targets": e.targets, "err": err})
+				return
+			}
+
+			for _, msg := range msgs {
+				event, err := parseConntrackEvent(msg.Data, msg.Header.Type)
+				if err != nil {
+					logger.Debug("Failed parsing conntrack event", logger.Ctx{"err": err})
+					continue
+				}
+
+				if event != nil {
+					e.handleEvent(event)
+				}
+			}
+		}
+	})
+
+	e.wg.Go(func() {
+		ticker := time.NewTicker(exportInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := e.export()
+				if err != nil {
+					logger.Warn("Failed exporting flows", logger.Ctx{"targets": e.targets, "err": err})
+				}
+			}
+		}
+	})
+
+	return nil
+}
+
+// Stop stops exporting the flows.
+func (e *Exporter) Stop() {
+	if e.cancel == nil {
+		return
+	}
+
+	e.cancel()
+	e.cancel = nil
+	e.wg.Wait()
+
+	e.closeConns()
+	conntrackAcctRestore()
+}
+
+// conntrackAcctEnable enables conntrack accounting, recording the original setting for the first user.
+func conntrackAcctEnable() error {
+	conntrackAcct.mu.Lock()
+	defer conntrackAcct.mu.Unlock()
+
+	if conntrackAcct.users == 0 {
+		previous, err := localUtil.SysctlGet(conntrackAcctSysctl)
+		if err != nil {
+			return fmt.Errorf("Failed getting conntrack accounting: %w", err)
+		}
+
+		err = localUtil.SysctlSet(conntrackAcctSysctl, "1")
+		if err != nil {
+			return fmt.Errorf("Failed enabling conntrack accounting: %w", err)
+		}
+
+		conntrackAcct.previous = strings.TrimSpace(previous)
+	}
+
+	conntrackAcct.users++
+
+	return nil
+}
+
+// conntrackAcctRestore restores the original conntrack accounting setting once the last user is gone.
+func conntrackAcctRestore() {
+	conntrackAcct.mu.Lock()
+	defer conntrackAcct.mu.Unlock()
+
+	conntrackAcct.users--
+	if conntrackAcct.users > 0 {
+		return
+	}
+
+	err := localUtil.SysctlSet(conntrackAcctSysctl, conntrackAcct.previous)
+	if err != nil {
+		logger.Warn("Failed restoring conntrack accounting", logger.Ctx{"value": conntrackAcct.previous, "err": err})
+	}
+}
+
+func (e *Exporter) closeConns() {
+	for _, conn := range e.conns {
+		_ = conn.Close()
+	}
+
+	e.conns = nil
+}
+
+// export sends the records of the flows which changed since the last export to the collectors.
+func (e *Exporter) export() error {
+	now := time.Now()
+
+	records, err := e.collect(now)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	records = append(e.ended, records...)
+	e.ended = nil
+	e.lastExport = now
+	e.mu.Unlock()
+
+	for _, msg := range encodeMessages(e.domainID, &e.sequence, now, records) {
+		for _, conn := range e.conns {
+			// Collectors may be unavailable for a while, keep sending to the others.
+			_, _ = conn.Write(msg)
+		}
+	}
+
+	return nil
+}
+
+// collect returns the records of the sampled conntrack flows since the last export.
+func (e *Exporter) collect(now time.Time) ([]Record, error) {
+	var flows []*netlink.ConntrackFlow
+
+	listed := time.Now()
+
+	for _, family := range []netlink.InetFamily{unix.AF_INET, unix.AF_INET6} {
+		familyFlows, err := netlink.ConntrackTableList(netlink.ConntrackTable, family)
+		if err != nil {
+			return nil, fmt.Errorf("Failed listing conntrack flows: %w", err)
+		}
+
+		flows = append(flows, familyFlows...)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var records []Record
+
+	counters := map[flowKey]flowCounters{}
+
+	for _, flow := range flows {
+		key, ok := e.flowKey(flow)
+		if !ok {
+			continue
+		}
+
+		// The flow ended while listing, its final counters were already recorded.
+		destroyed, ok := e.destroyed[key]
+		if ok && destroyed.After(listed) {
+			continue
+		}
+
+		counters[key] = conntrackFlowCounters(flow)
+		records = append(records, e.flowRecords(key, flow, now)...)
+	}
+
+	// Drop the state of the flows which ended.
+	e.counters = counters
+	clear(e.destroyed)
+
+	for key := range e.starts {
+		_, ok := counters[key]
+		if !ok {
+			delete(e.starts, key)
+		}
+	}
+
+	return records, nil
+}
+
+// handleEvent records the start of the new flows and the final counters of the ended ones.
+func (e *Exporter) handleEvent(event *conntrackEvent) {
+	key, ok := e.flowKey(event.flow)
+	if !ok {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !event.destroy {
+		// Remember when the flow started in case conntrack timestamps are disabled.
+		_, ok := e.starts[key]
+		if !ok {
+			e.starts[key] = time.Now()
+		}
+
+		return
+	}
+
+	end := time.Now()
+	if event.flow.TimeStop > 0 {
+		end = time.Unix(0, int64(event.flow.TimeStop))
+	}
+
+	e.ended = append(e.ended, e.flowRecords(key, event.flow, end)...)
+
+	delete(e.counters, key)
+	delete(e.starts, key)
+	e.destroyed[key] = time.Now()
+}
+
+// flowKey returns the key of the flow and whether it is exported.
+func (e *Exporter) flowKey(flow *netlink.ConntrackFlow) (flowKey, bool) {
+	if !e.match(flow.Forward.SrcIP) && !e.match(flow.Forward.DstIP) {
+		return flowKey{}, false
+	}
+
+	key := conntrackFlowKey(flow)
+
+	return key, e.sampled(key)
+}
+
+// flowRecords returns the records of the traffic of the flow since the last export. Must be called with the lock held.
+func (e *Exporter) flowRecords(key flowKey, flow *netlink.ConntrackFlow, end time.Time) []Record {
+	var records []Record
+
+	start := e.lastExport
+
+	flowStart, ok := e.starts[key]
+	if flow.TimeStart > 0 {
+		flowStart = time.Unix(0, int64(flow.TimeStart))
+		ok = true
+	}
+
+	if ok && flowStart.After(start) {
+		start = flowStart
+	}
+
+	// Only export the traffic since the last export, the flow being unknown if new.
+	current := conntrackFlowCounters(flow)
+	previous := e.counters[key]
+
+	if current.packets > previous.packets {
+		records = append(records, Record{
+			SrcIP:    flow.Forward.SrcIP,
+			DstIP:    flow.Forward.DstIP,
+			SrcPort:  flow.Forward.SrcPort,
+			DstPort:  flow.Forward.DstPort,
+			Protocol: flow.Forward.Protocol,
+			Bytes:    current.bytes - previous.bytes,
+			Packets:  current.packets - previous.packets,
+			Start:    start,
+			End:      end,
+		})
+	}
+
+	// Report replies with the original addresses so they can be attributed to the same instance.
+	if current.replyPackets > previous.replyPackets {
+		records = append(records, Record{
+			SrcIP:    flow.Forward.DstIP,
+			DstIP:    flow.Forward.SrcIP,
+			SrcPort:  flow.Forward.DstPort,
+			DstPort:  flow.Forward.SrcPort,
+			Protocol: flow.Forward.Protocol,
+			Bytes:    current.replyBytes - previous.replyBytes,
+			Packets:  current.replyPackets - previous.replyPackets,
+			Start:    start,
+			End:      end,
+		})
+	}
+
+	return records
+}
+
+// match returns whether the address is in one of the exported subnets.
+func (e *Exporter) match(address net.IP) bool {
+	for _, subnet := range e.subnets {
+		if subnet.Contains(address) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sampled returns whether the flow is exported. The selection is based on a hash of the flow so that the same
+// flows are exported every time.
+func (e *Exporter) sampled(key flowKey) bool {
+	if e.sampling <= 1 {
+		return true
+	}
+
+	h := fnv.New32a()
+	_, _ = fmt.Fprintf(h, "%d/%s/%d/%s/%d/%d", key.protocol, key.src, key.srcPort, key.dst, key.dstPort, key.zone)
+
+	return h.Sum32()%e.sampling == 0
+}
+
+// conntrackFlowCounters returns the counters of both directions of a conntrack flow.
+func conntrackFlowCounters(flow *netlink.ConntrackFlow) flowCounters {
+	return flowCounters{
+		bytes:        flow.Forward.Bytes,
+		packets:      flow.Forward.Packets,
+		replyBytes:   flow.Reverse.Bytes,
+		replyPackets: flow.Reverse.Packets,
+	}
+}
+
+// conntrackFlowKey returns the key identifying a conntrack flow.
+func conntrackFlowKey(flow *netlink.ConntrackFlow) flowKey {
+	src, _ := netip.AddrFromSlice(flow.Forward.SrcIP)
+	dst, _ := netip.AddrFromSlice(flow.Forward.DstIP)
+
+	return flowKey{
+		protocol: flow.Forward.Protocol,
+		src:      src.Unmap(),
+		dst:      dst.Unmap(),
+		srcPort:  flow.Forward.SrcPort,
+		dstPort:  flow.Forward.DstPort,
+		zone:     flow.Zone,
+	}
+}
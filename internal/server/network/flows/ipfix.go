@@ -0,0 +1,191 @@
+package flows
+
+import (
+	"encoding/binary"
+	"net"
+	"time"
+)
+
+// IPFIX protocol constants (RFC 7011).
+const (
+	ipfixVersion       = 10
+	ipfixTemplateSetID = 2
+	ipfixHeaderSize    = 16
+	ipfixSetHeaderSize = 4
+
+	// Keep messages within a typical path MTU as they are sent over UDP.
+	ipfixMaxMessageSize = 1400
+)
+
+// Template IDs of the exported records.
+const (
+	templateIPv4 = 256
+	templateIPv6 = 257
+)
+
+// ipfixField is an information element of a template.
+type ipfixField struct {
+	id     uint16
+	length uint16
+}
+
+var ipfixTemplates = map[uint16][]ipfixField{
+	templateIPv4: {
+		{id: 8, length: 4},   // sourceIPv4Address
+		{id: 12, length: 4},  // destinationIPv4Address
+		{id: 7, length: 2},   // sourceTransportPort
+		{id: 11, length: 2},  // destinationTransportPort
+		{id: 4, length: 1},   // protocolIdentifier
+		{id: 1, length: 8},   // octetDeltaCount
+		{id: 2, length: 8},   // packetDeltaCount
+		{id: 152, length: 8}, // flowStartMilliseconds
+		{id: 153, length: 8}, // flowEndMilliseconds
+	},
+	templateIPv6: {
+		{id: 27, length: 16}, // sourceIPv6Address
+		{id: 28, length: 16}, // destinationIPv6Address
+		{id: 7, length: 2},   // sourceTransportPort
+		{id: 11, length: 2},  // destinationTransportPort
+		{id: 4, length: 1},   // protocolIdentifier
+		{id: 1, length: 8},   // octetDeltaCount
+		{id: 2, length: 8},   // packetDeltaCount
+		{id: 152, length: 8}, // flowStartMilliseconds
+		{id: 153, length: 8}, // flowEndMilliseconds
+	},
+}
+
+// Record is a unidirectional flow record.
+type Record struct {
+	SrcIP    net.IP
+	DstIP    net.IP
+	SrcPort  uint16
+	DstPort  uint16
+	Protocol uint8
+	Bytes    uint64
+	Packets  uint64
+	Start    time.Time
+	End      time.Time
+}
+
+// templateID returns the ID of the template used to export the record.
+func (r *Record) templateID() uint16 {
+	if r.SrcIP.To4() != nil {
+		return templateIPv4
+	}
+
+	return templateIPv6
+}
+
+// size returns the size of the encoded record.
+func (r *Record) size() int {
+	size := 0
+	for _, field := range ipfixTemplates[r.templateID()] {
+		size += int(field.length)
+	}
+
+	return size
+}
+
+// encode appends the encoded record to buf.
+func (r *Record) encode(buf []byte) []byte {
+	if r.templateID() == templateIPv4 {
+		buf = append(buf, r.SrcIP.To4()...)
+		buf = append(buf, r.DstIP.To4()...)
+	} else {
+		buf = append(buf, r.SrcIP.To16()...)
+		buf = append(buf, r.DstIP.To16()...)
+	}
+
+	buf = binary.BigEndian.AppendUint16(buf, r.SrcPort)
+	buf = binary.BigEndian.AppendUint16(buf, r.DstPort)
+	buf = append(buf, r.Protocol)
+	buf = binary.BigEndian.AppendUint64(buf, r.Bytes)
+	buf = binary.BigEndian.AppendUint64(buf, r.Packets)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(r.Start.UnixMilli()))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(r.End.UnixMilli()))
+
+	return buf
+}
+
+// encodeTemplateSet appends the template set describing all the templates to buf.
+func encodeTemplateSet(buf []byte) []byte {
+	start := len(buf)
+
+	buf = binary.BigEndian.AppendUint16(buf, ipfixTemplateSetID)
+	buf = binary.BigEndian.AppendUint16(buf, 0) // Length, set below.
+
+	for _, id := range []uint16{templateIPv4, templateIPv6} {
+		fields := ipfixTemplates[id]
+
+		buf = binary.BigEndian.AppendUint16(buf, id)
+		buf = binary.BigEndian.AppendUint16(buf, uint16(len(fields)))
+
+		for _, field := range fields {
+			buf = binary.BigEndian.AppendUint16(buf, field.id)
+			buf = binary.BigEndian.AppendUint16(buf, field.length)
+		}
+	}
+
+	binary.BigEndian.PutUint16(buf[start+2:], uint16(len(buf)-start))
+
+	return buf
+}
+
+// encodeMessages returns the IPFIX messages holding the records. The templates are included in every message
+// so that collectors can decode them regardless of which messages they received. sequence is the number of
+// data records sent before and is updated with the number of records encoded.
+func encodeMessages(domainID uint32, sequence *uint32, exportTime time.Time, records []Record) [][]byte {
+	var messages [][]byte
+
+	for len(records) > 0 {
+		msg := make([]byte, ipfixHeaderSize, ipfixMaxMessageSize)
+		msg = encodeTemplateSet(msg)
+
+		count := 0
+		setStart := -1
+		setTemplate := uint16(0)
+
+		for _, record := range records {
+			templateID := record.templateID()
+
+			// Start a new data set when the template changes.
+			extra := record.size()
+			if templateID != setTemplate {
+				extra += ipfixSetHeaderSize
+			}
+
+			if len(msg)+extra > ipfixMaxMessageSize && count > 0 {
+				break
+			}
+
+			if templateID != setTemplate {
+				if setStart >= 0 {
+					binary.BigEndian.PutUint16(msg[setStart+2:], uint16(len(msg)-setStart))
+				}
+
+				setStart = len(msg)
+				setTemplate = templateID
+				msg = binary.BigEndian.AppendUint16(msg, templateID)
+				msg = binary.BigEndian.AppendUint16(msg, 0) // Length, set once the set is complete.
+			}
+
+			msg = record.encode(msg)
+			count++
+		}
+
+		binary.BigEndian.PutUint16(msg[setStart+2:], uint16(len(msg)-setStart))
+
+		// Fill the message header.
+		binary.BigEndian.PutUint16(msg[0:], ipfixVersion)
+		binary.BigEndian.PutUint16(msg[2:], uint16(len(msg)))
+		binary.BigEndian.PutUint32(msg[4:], uint32(exportTime.Unix()))
+		binary.BigEndian.PutUint32(msg[8:], *sequence)
+		binary.BigEndian.PutUint32(msg[12:], domainID)
+
+		*sequence += uint32(count)
+		records = records[count:]
+		messages = append(messages, msg)
+	}
+
+	return messages
+}
@@ -0,0 +1,65 @@
+package flows
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeMessages(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	records := []Record{
+		{SrcIP: net.ParseIP("10.0.0.2"), DstIP: net.ParseIP("192.0.2.1"), SrcPort: 40000, DstPort: 443, Protocol: 6, Bytes: 1500, Packets: 3, Start: now.Add(-time.Minute), End: now},
+		{SrcIP: net.ParseIP("fd42::2"), DstIP: net.ParseIP("2001:db8::1"), SrcPort: 53000, DstPort: 53, Protocol: 17, Bytes: 80, Packets: 1, Start: now.Add(-time.Minute), End: now},
+	}
+
+	var sequence uint32
+	messages := encodeMessages(42, &sequence, now, records)
+	require.Len(t, messages, 1)
+	assert.Equal(t, uint32(2), sequence)
+
+	msg := messages[0]
+	assert.Equal(t, uint16(ipfixVersion), binary.BigEndian.Uint16(msg[0:]))
+	assert.Equal(t, uint16(len(msg)), binary.BigEndian.Uint16(msg[2:]))
+	assert.Equal(t, uint32(now.Unix()), binary.BigEndian.Uint32(msg[4:]))
+	assert.Equal(t, uint32(0), binary.BigEndian.Uint32(msg[8:]))
+	assert.Equal(t, uint32(42), binary.BigEndian.Uint32(msg[12:]))
+
+	// Walk the sets and check their IDs and lengths add up to the message length.
+	var setIDs []uint16
+	offset := ipfixHeaderSize
+	for offset < len(msg) {
+		setIDs = append(setIDs, binary.BigEndian.Uint16(msg[offset:]))
+		offset += int(binary.BigEndian.Uint16(msg[offset+2:]))
+	}
+
+	assert.Equal(t, len(msg), offset)
+	assert.Equal(t, []uint16{ipfixTemplateSetID, templateIPv4, templateIPv6}, setIDs)
+}
+
+func TestEncodeMessagesSplit(t *testing.T) {
+	now := time.Now()
+
+	var records []Record
+	for range 100 {
+		records = append(records, Record{SrcIP: net.ParseIP("10.0.0.2"), DstIP: net.ParseIP("192.0.2.1"), Protocol: 6, Start: now, End: now})
+	}
+
+	sequence := uint32(10)
+	messages := encodeMessages(1, &sequence, now, records)
+	require.Greater(t, len(messages), 1)
+	assert.Equal(t, uint32(110), sequence)
+
+	for _, msg := range messages {
+		assert.LessOrEqual(t, len(msg), ipfixMaxMessageSize)
+	}
+
+	// The second message starts after the records of the first one.
+	first := (len(messages[0]) - ipfixHeaderSize - len(encodeTemplateSet(nil)) - ipfixSetHeaderSize) / records[0].size()
+	assert.Equal(t, uint32(10+first), binary.BigEndian.Uint32(messages[1][8:]))
+}
@@ -43,6 +43,8 @@ type Network interface {
 	DHCPv6Subnet() *net.IPNet
 	DHCPv4Ranges() []iprange.Range
 	DHCPv6Ranges() []iprange.Range
+	ReservedIPv4Ranges() []iprange.Range
+	ReservedIPv6Ranges() []iprange.Range
 
 	// Actions.
 	Create(clientType request.ClientType) error
@@ -58,6 +60,12 @@ type Network interface {
 	State() (*api.NetworkState, error)
 	Leases(projectName string, clientType request.ClientType) ([]api.NetworkLease, error)
 
+	// Address reservations.
+	LeaseAddresses(hwaddr string) (net.IP, net.IP, error)
+	LeaseCreate(lease api.NetworkLeasesPost, clientType request.ClientType) error
+	LeaseUpdate(address string, newLease api.NetworkLeasePut, clientType request.ClientType) error
+	LeaseDelete(address string, clientType request.ClientType) error
+
 	// Address Forwards.
 	ForwardCreate(forward api.NetworkForwardsPost, clientType request.ClientType) error
 	ForwardUpdate(listenAddress string, newForward api.NetworkForwardPut, clientType request.ClientType) error
@@ -69,9 +77,14 @@ type Network interface {
 	LoadBalancerState(loadbalancer api.NetworkLoadBalancer) (*api.NetworkLoadBalancerState, error)
 	LoadBalancerDelete(listenAddress string, clientType request.ClientType) error
 
+	// Egress gateways.
+	EgressGatewayCreate(gateway api.NetworkEgressGatewaysPost, clientType request.ClientType) error
+	EgressGatewayUpdate(address string, newGateway api.NetworkEgressGatewayPut, clientType request.ClientType) error
+	EgressGatewayDelete(address string, clientType request.ClientType) error
+
 	// Peerings.
-	PeerCreate(forward api.NetworkPeersPost) error
-	PeerUpdate(peerName string, newPeer api.NetworkPeerPut) error
-	PeerDelete(peerName string) error
+	PeerCreate(forward api.NetworkPeersPost, clientType request.ClientType) error
+	PeerUpdate(peerName string, newPeer api.NetworkPeerPut, clientType request.ClientType) error
+	PeerDelete(peerName string, clientType request.ClientType) error
 	PeerUsedBy(peerName string) ([]string, error)
 }
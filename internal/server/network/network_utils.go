@@ -455,6 +455,27 @@ func UpdateDNSMasqStatic(s *state.State, networkName string) error {
 		networks = []string{networkName}
 	}
 
+	// Get the address reservations of the networks.
+	reservations := map[string][]cluster.NetworkLease{}
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		for _, network := range networks {
+			networkID, err := tx.GetNetworkID(ctx, api.ProjectDefaultName, network)
+			if err != nil {
+				return err
+			}
+
+			reservations[network], err = cluster.GetNetworkLeases(ctx, tx.Tx(), cluster.NetworkLeaseFilter{NetworkID: &networkID})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading network address reservations: %w", err)
+	}
+
 	// Get all the instances.
 	insts, err := instance.LoadNodeAll(s, instancetype.Any)
 	if err != nil {
@@ -499,6 +520,22 @@ func UpdateDNSMasqStatic(s *state.State, networkName string) error {
 				}
 			}
 
+			// Use the addresses reserved for the MAC address of the device, unless set on the device.
+			for _, reservation := range reservations[d["parent"]] {
+				if reservation.Hwaddr == "" || !strings.EqualFold(reservation.Hwaddr, d["hwaddr"]) {
+					continue
+				}
+
+				key := "ipv6.address"
+				if net.ParseIP(reservation.Address).To4() != nil {
+					key = "ipv4.address"
+				}
+
+				if d[key] == "" {
+					d[key] = reservation.Address
+				}
+			}
+
 			// Add the new host entries.
 			_, ok := entries[d["parent"]]
 			if !ok {
@@ -605,6 +642,26 @@ func UpdateDNSMasqStatic(s *state.State, networkName string) error {
 			}
 		}
 
+		// Add the address reservations not already covered by the instance entries.
+		for _, reservation := range reservations[network] {
+			covered := false
+			for _, entry := range entries {
+				if reservation.Hwaddr != "" && strings.EqualFold(reservation.Hwaddr, entry[0]) && (reservation.Address == entry[3] || reservation.Address == entry[4]) {
+					covered = true
+					break
+				}
+			}
+
+			if covered {
+				continue
+			}
+
+			err := dnsmasq.UpdateReservedEntry(network, config, net.ParseIP(reservation.Address), reservation.Hwaddr, reservation.Hostname)
+			if err != nil {
+				return err
+			}
+		}
+
 		// Signal dnsmasq.
 		err = dnsmasq.Kill(network, true)
 		if err != nil {
@@ -1620,6 +1677,58 @@ func complementRanges(ranges []*iprange.Range, netAddr *net.IPNet) ([]iprange.Ra
 	return complement, nil
 }
 
+// subtractRanges returns the parts of the IP ranges that aren't covered by any of the excluded ranges.
+func subtractRanges(ranges []iprange.Range, excluded []iprange.Range) []iprange.Range {
+	for _, excludedRange := range excluded {
+		excludedStart, ok := netip.AddrFromSlice(excludedRange.Start)
+		if !ok {
+			continue
+		}
+
+		excludedEnd := excludedStart
+		if excludedRange.End != nil {
+			excludedEnd, ok = netip.AddrFromSlice(excludedRange.End)
+			if !ok {
+				continue
+			}
+		}
+
+		excludedStart = excludedStart.Unmap()
+		excludedEnd = excludedEnd.Unmap()
+
+		remaining := make([]iprange.Range, 0, len(ranges))
+		for _, r := range ranges {
+			start, okStart := netip.AddrFromSlice(r.Start)
+			end, okEnd := netip.AddrFromSlice(r.End)
+			if !okStart || !okEnd {
+				remaining = append(remaining, r)
+				continue
+			}
+
+			start = start.Unmap()
+			end = end.Unmap()
+
+			// Keep the ranges that don't overlap with the excluded one.
+			if excludedEnd.Less(start) || end.Less(excludedStart) {
+				remaining = append(remaining, r)
+				continue
+			}
+
+			if start.Less(excludedStart) {
+				remaining = append(remaining, iprange.Range{Start: start.AsSlice(), End: excludedStart.Prev().AsSlice()})
+			}
+
+			if excludedEnd.Less(end) {
+				remaining = append(remaining, iprange.Range{Start: excludedEnd.Next().AsSlice(), End: end.AsSlice()})
+			}
+		}
+
+		ranges = remaining
+	}
+
+	return ranges
+}
+
 // ipInRanges checks whether the given IP address is contained within any of the
 // provided IP network ranges.
 func ipInRanges(ipAddr net.IP, ipRanges []iprange.Range) bool {
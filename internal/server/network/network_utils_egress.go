@@ -0,0 +1,308 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	internalInstance "github.com/lxc/incus/v7/internal/instance"
+	"github.com/lxc/incus/v7/internal/server/db"
+	dbCluster "github.com/lxc/incus/v7/internal/server/db/cluster"
+	deviceConfig "github.com/lxc/incus/v7/internal/server/device/config"
+	"github.com/lxc/incus/v7/internal/server/project"
+	"github.com/lxc/incus/v7/internal/server/state"
+	"github.com/lxc/incus/v7/shared/api"
+)
+
+// egressGatewayEntity splits an instance or profile reference of an egress gateway into its project and name.
+// References without a project refer to the project of the network.
+func egressGatewayEntity(networkProject string, entry string) (string, string) {
+	projectName, name, found := strings.Cut(entry, "/")
+	if !found {
+		return networkProject, entry
+	}
+
+	return projectName, name
+}
+
+// egressGatewaySelect returns the egress gateway of the address family used by an instance, or nil if none.
+// The gateway listing the instance takes precedence over the ones listing its profiles (the last applied profile
+// winning), which take precedence over the ones listing its project.
+func egressGatewaySelect(gateways []*api.NetworkEgressGateway, networkProject string, inst *db.InstanceArgs, ipv6 bool) *api.NetworkEgressGateway {
+	var byProfile *api.NetworkEgressGateway
+	byProfileIndex := -1
+	var byProject *api.NetworkEgressGateway
+
+	for _, gateway := range gateways {
+		address := net.ParseIP(gateway.Address)
+		if address == nil || (address.To4() == nil) != ipv6 {
+			continue
+		}
+
+		for _, entry := range gateway.Instances {
+			projectName, name := egressGatewayEntity(networkProject, entry)
+			if projectName == inst.Project && name == inst.Name {
+				return gateway
+			}
+		}
+
+		for _, entry := range gateway.Profiles {
+			projectName, name := egressGatewayEntity(networkProject, entry)
+
+			for i, profile := range inst.Profiles {
+				if i > byProfileIndex && profile.Project == projectName && profile.Name == name {
+					byProfile = gateway
+					byProfileIndex = i
+				}
+			}
+		}
+
+		for _, projectName := range gateway.Projects {
+			if projectName == inst.Project && byProject == nil {
+				byProject = gateway
+			}
+		}
+	}
+
+	if byProfile != nil {
+		return byProfile
+	}
+
+	return byProject
+}
+
+// egressGatewayValidate validates the egress gateway request against the other egress gateways of the network.
+func (n *common) egressGatewayValidate(address net.IP, gateway *api.NetworkEgressGatewayPut, others []*api.NetworkEgressGateway) error {
+	if address == nil {
+		return errors.New("Invalid address")
+	}
+
+	if address.IsUnspecified() {
+		return fmt.Errorf("Cannot use unspecified address: %q", address.String())
+	}
+
+	// Look for any unknown config fields.
+	for k := range gateway.Config {
+		// User keys are not validated.
+
+		// gendoc:generate(entity=network_egress_gateway, group=common, key=user.*)
+		//
+		// ---
+		//  type: string
+		//  shortdesc: User defined key/value configuration
+		if internalInstance.IsUserConfig(k) {
+			continue
+		}
+
+		return fmt.Errorf("Invalid option %q", k)
+	}
+
+	// Check the selection is valid and isn't claimed by another gateway of the same address family.
+	type selection struct {
+		kind  string
+		entry string
+	}
+
+	claimed := map[selection]string{}
+	for _, other := range others {
+		otherAddress := net.ParseIP(other.Address)
+		if otherAddress == nil || otherAddress.Equal(address) || (otherAddress.To4() == nil) != (address.To4() == nil) {
+			continue
+		}
+
+		for kind, entries := range map[string][]string{"instance": other.Instances, "profile": other.Profiles, "project": other.Projects} {
+			for _, entry := range entries {
+				if kind != "project" {
+					projectName, name := egressGatewayEntity(n.project, entry)
+					entry = projectName + "/" + name
+				}
+
+				claimed[selection{kind: kind, entry: entry}] = other.Address
+			}
+		}
+	}
+
+	for kind, entries := range map[string][]string{"instance": gateway.Instances, "profile": gateway.Profiles, "project": gateway.Projects} {
+		seen := map[string]bool{}
+
+		for _, entry := range entries {
+			projectName, name := egressGatewayEntity(n.project, entry)
+			if projectName == "" || name == "" || strings.Contains(name, "/") || (kind == "project" && projectName != n.project) {
+				return fmt.Errorf("Invalid %s %q", kind, entry)
+			}
+
+			if kind != "project" {
+				entry = projectName + "/" + name
+			}
+
+			if seen[entry] {
+				return fmt.Errorf("Duplicate %s %q", kind, entry)
+			}
+
+			seen[entry] = true
+
+			otherAddress, found := claimed[selection{kind: kind, entry: entry}]
+			if found {
+				return api.StatusErrorf(http.StatusConflict, "The %s %q already uses the egress gateway %q", kind, entry, otherAddress)
+			}
+		}
+	}
+
+	return nil
+}
+
+// egressGatewaysLoad returns the egress gateways of the network.
+func (n *common) egressGatewaysLoad() ([]*api.NetworkEgressGateway, error) {
+	var gateways []*api.NetworkEgressGateway
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		networkID := n.ID()
+		dbRecords, err := dbCluster.GetNetworkEgressGateways(ctx, tx.Tx(), dbCluster.NetworkEgressGatewayFilter{
+			NetworkID: &networkID,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, dbRecord := range dbRecords {
+			gateway, err := dbRecord.ToAPI(ctx, tx.Tx())
+			if err != nil {
+				return err
+			}
+
+			gateways = append(gateways, gateway)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading network egress gateways: %w", err)
+	}
+
+	return gateways, nil
+}
+
+// egressGatewayCreateRecord creates the DB record of the egress gateway.
+func (n *common) egressGatewayCreateRecord(gateway *api.NetworkEgressGateway) error {
+	return n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbRecord := dbCluster.NetworkEgressGateway{
+			NetworkID:   n.ID(),
+			Address:     gateway.Address,
+			Description: gateway.Description,
+			Instances:   gateway.Instances,
+			Profiles:    gateway.Profiles,
+			Projects:    gateway.Projects,
+		}
+
+		gatewayID, err := dbCluster.CreateNetworkEgressGateway(ctx, tx.Tx(), dbRecord)
+		if err != nil {
+			return err
+		}
+
+		return dbCluster.CreateNetworkEgressGatewayConfig(ctx, tx.Tx(), gatewayID, gateway.Config)
+	})
+}
+
+// egressGatewayUpdateRecord updates the DB record of the egress gateway.
+func (n *common) egressGatewayUpdateRecord(gateway *api.NetworkEgressGateway) error {
+	return n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		gatewayID, err := dbCluster.GetNetworkEgressGatewayID(ctx, tx.Tx(), n.ID(), gateway.Address)
+		if err != nil {
+			return err
+		}
+
+		dbRecord := dbCluster.NetworkEgressGateway{
+			NetworkID:   n.ID(),
+			Address:     gateway.Address,
+			Description: gateway.Description,
+			Instances:   gateway.Instances,
+			Profiles:    gateway.Profiles,
+			Projects:    gateway.Projects,
+		}
+
+		err = dbCluster.UpdateNetworkEgressGateway(ctx, tx.Tx(), n.ID(), gateway.Address, dbRecord)
+		if err != nil {
+			return err
+		}
+
+		return dbCluster.UpdateNetworkEgressGatewayConfig(ctx, tx.Tx(), gatewayID, gateway.Config)
+	})
+}
+
+// egressGatewayDeleteRecord deletes the DB record of the egress gateway and returns the deleted egress gateway.
+func (n *common) egressGatewayDeleteRecord(address string) (*api.NetworkEgressGateway, error) {
+	var gateway *api.NetworkEgressGateway
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		networkID := n.ID()
+
+		dbRecords, err := dbCluster.GetNetworkEgressGateways(ctx, tx.Tx(), dbCluster.NetworkEgressGatewayFilter{
+			NetworkID: &networkID,
+			Address:   &address,
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(dbRecords) != 1 {
+			return api.StatusErrorf(http.StatusNotFound, "Network egress gateway not found")
+		}
+
+		gateway, err = dbRecords[0].ToAPI(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		return dbCluster.DeleteNetworkEgressGateway(ctx, tx.Tx(), n.ID(), dbRecords[0].ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return gateway, nil
+}
+
+// EgressGatewaysRefreshDevices re-applies the egress gateways of the managed bridge networks used by the NIC
+// devices of an instance of the project. This is needed when the instance changes in a way affecting which
+// egress gateway it uses, such as its profiles changing.
+func EgressGatewaysRefreshDevices(s *state.State, projectName string, devices deviceConfig.Devices) error {
+	refreshed := map[string]bool{}
+
+	for _, devConfig := range devices {
+		if devConfig["type"] != "nic" || devConfig["network"] == "" {
+			continue
+		}
+
+		networkProjectName, _, err := project.NetworkProjectForName(s.DB.Cluster, projectName, devConfig["network"])
+		if err != nil {
+			return err
+		}
+
+		key := networkProjectName + "/" + devConfig["network"]
+		if refreshed[key] {
+			continue
+		}
+
+		refreshed[key] = true
+
+		n, err := LoadByName(s, networkProjectName, devConfig["network"])
+		if err != nil {
+			return fmt.Errorf("Failed loading network %q: %w", devConfig["network"], err)
+		}
+
+		bridgeNet, ok := n.(*bridge)
+		if !ok || !bridgeNet.isRunning() {
+			continue
+		}
+
+		err = bridgeNet.EgressGatewayRefresh()
+		if err != nil {
+			return fmt.Errorf("Failed applying egress gateways of network %q: %w", n.Name(), err)
+		}
+	}
+
+	return nil
+}
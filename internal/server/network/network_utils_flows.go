@@ -0,0 +1,79 @@
+package network
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/lxc/incus/v7/internal/server/network/flows"
+)
+
+// Default sampling rates of the flow export.
+const (
+	flowsSamplingOVS    = 400 // One in 400 packets.
+	flowsSamplingNative = 1   // Every flow.
+)
+
+// flowExporter is a conntrack flow exporter along with the settings it was started with.
+type flowExporter struct {
+	exporter *flows.Exporter
+	settings string
+}
+
+// flowExporters holds the conntrack flow exporters of the native bridges, by network ID.
+var flowExporters = map[int64]*flowExporter{}
+
+var flowExportersMu sync.Mutex
+
+// flowsProtocol returns the flow export protocol of the network.
+func flowsProtocol(config map[string]string) string {
+	if config["flows.protocol"] == "" {
+		return "ipfix"
+	}
+
+	return config["flows.protocol"]
+}
+
+// flowsSampling returns the flow export sampling rate of the network, or defaultSampling if unset.
+func flowsSampling(config map[string]string, defaultSampling int) (int, error) {
+	if config["flows.sampling"] == "" {
+		return defaultSampling, nil
+	}
+
+	sampling, err := strconv.Atoi(config["flows.sampling"])
+	if err != nil {
+		return 0, fmt.Errorf("Invalid flows.sampling value %q: %w", config["flows.sampling"], err)
+	}
+
+	return sampling, nil
+}
+
+// flowsExporterUpdate replaces the conntrack flow exporter of the network, keeping the current one if started
+// with the same settings so that no traffic is missed. A nil exporter stops the flow export.
+func flowsExporterUpdate(networkID int64, exporter *flows.Exporter, settings string) error {
+	flowExportersMu.Lock()
+	defer flowExportersMu.Unlock()
+
+	current := flowExporters[networkID]
+	if current != nil {
+		if exporter != nil && current.settings == settings {
+			return nil
+		}
+
+		current.exporter.Stop()
+		delete(flowExporters, networkID)
+	}
+
+	if exporter == nil {
+		return nil
+	}
+
+	err := exporter.Start()
+	if err != nil {
+		return err
+	}
+
+	flowExporters[networkID] = &flowExporter{exporter: exporter, settings: settings}
+
+	return nil
+}
@@ -0,0 +1,239 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lxc/incus/v7/shared/util"
+)
+
+// Load balancer backend health status (matching the values reported by OVN).
+const (
+	healthStatusUnknown = "unknown"
+	healthStatusOnline  = "online"
+	healthStatusOffline = "offline"
+)
+
+// healthCheckTarget is a load balancer backend port subject to health checks.
+type healthCheckTarget struct {
+	protocol string
+	address  string
+	port     uint64
+}
+
+// healthCheckConfig holds the health check settings of a load balancer.
+type healthCheckConfig struct {
+	interval     time.Duration
+	timeout      time.Duration
+	successCount int
+	failureCount int
+}
+
+// healthCheckState tracks the health of a load balancer backend port.
+type healthCheckState struct {
+	config    healthCheckConfig
+	cancel    context.CancelFunc
+	status    string
+	successes int
+	failures  int
+}
+
+// healthChecks holds the health checks running for each network, by network ID.
+var healthChecks = map[int64]map[healthCheckTarget]*healthCheckState{}
+
+var healthChecksMu sync.Mutex
+
+// parseHealthCheckConfig returns the health check settings of a load balancer, or nil if health checks are
+// disabled. Unset (or zero) values use the same defaults as OVN.
+func parseHealthCheckConfig(config map[string]string) (*healthCheckConfig, error) {
+	if !util.IsTrue(config["healthcheck"]) {
+		return nil, nil
+	}
+
+	values := map[string]int{
+		"healthcheck.interval":      10,
+		"healthcheck.timeout":       30,
+		"healthcheck.success_count": 3,
+		"healthcheck.failure_count": 3,
+	}
+
+	for key := range values {
+		if config[key] == "" {
+			continue
+		}
+
+		value, err := strconv.Atoi(config[key])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value for %q: %w", key, err)
+		}
+
+		if value > 0 {
+			values[key] = value
+		}
+	}
+
+	return &healthCheckConfig{
+		interval:     time.Duration(values["healthcheck.interval"]) * time.Second,
+		timeout:      time.Duration(values["healthcheck.timeout"]) * time.Second,
+		successCount: values["healthcheck.success_count"],
+		failureCount: values["healthcheck.failure_count"],
+	}, nil
+}
+
+// healthCheckUpdate sets the backend ports checked for the network, starting the checks of new targets and
+// stopping those of the targets which aren't listed anymore. The status of the targets which were already
+// checked with the same settings is kept. onChange is called whenever a target goes online or offline.
+func healthCheckUpdate(networkID int64, targets map[healthCheckTarget]healthCheckConfig, onChange func()) {
+	healthChecksMu.Lock()
+	defer healthChecksMu.Unlock()
+
+	states := healthChecks[networkID]
+	if states == nil {
+		states = map[healthCheckTarget]*healthCheckState{}
+	}
+
+	for target, state := range states {
+		config, found := targets[target]
+		if found && config == state.config {
+			continue
+		}
+
+		state.cancel()
+		delete(states, target)
+	}
+
+	for target, config := range targets {
+		_, found := states[target]
+		if found {
+			continue
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		state := &healthCheckState{
+			config: config,
+			cancel: cancel,
+			status: healthStatusUnknown,
+		}
+
+		states[target] = state
+
+		go healthCheckRun(ctx, target, state, onChange)
+	}
+
+	if len(states) == 0 {
+		delete(healthChecks, networkID)
+		return
+	}
+
+	healthChecks[networkID] = states
+}
+
+// healthCheckStop stops all the health checks of the network.
+func healthCheckStop(networkID int64) {
+	healthCheckUpdate(networkID, nil, nil)
+}
+
+// healthCheckStatus returns the health status of a backend port of the network.
+func healthCheckStatus(networkID int64, target healthCheckTarget) string {
+	healthChecksMu.Lock()
+	defer healthChecksMu.Unlock()
+
+	state, found := healthChecks[networkID][target]
+	if !found {
+		return healthStatusUnknown
+	}
+
+	return state.status
+}
+
+// healthCheckRun periodically checks the target until ctx is cancelled.
+func healthCheckRun(ctx context.Context, target healthCheckTarget, state *healthCheckState, onChange func()) {
+	ticker := time.NewTicker(state.config.interval)
+	defer ticker.Stop()
+
+	for {
+		healthy := healthCheckProbe(ctx, target, state.config.timeout)
+
+		healthChecksMu.Lock()
+
+		if ctx.Err() != nil {
+			healthChecksMu.Unlock()
+			return
+		}
+
+		oldStatus := state.status
+
+		if healthy {
+			state.successes++
+			state.failures = 0
+
+			if state.successes >= state.config.successCount {
+				state.status = healthStatusOnline
+			}
+		} else {
+			state.failures++
+			state.successes = 0
+
+			if state.failures >= state.config.failureCount {
+				state.status = healthStatusOffline
+			}
+		}
+
+		changed := state.status != oldStatus
+
+		healthChecksMu.Unlock()
+
+		if changed && onChange != nil {
+			onChange()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// healthCheckProbe returns whether the target accepts connections. TCP targets must accept a connection
+// while UDP targets must not reject a datagram (with an ICMP port unreachable error) within the timeout.
+func healthCheckProbe(ctx context.Context, target healthCheckTarget, timeout time.Duration) bool {
+	dialer := net.Dialer{Timeout: timeout}
+	address := net.JoinHostPort(target.address, strconv.FormatUint(target.port, 10))
+
+	conn, err := dialer.DialContext(ctx, target.protocol, address)
+	if err != nil {
+		return false
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	if target.protocol != "udp" {
+		return true
+	}
+
+	err = conn.SetDeadline(time.Now().Add(timeout))
+	if err != nil {
+		return false
+	}
+
+	_, err = conn.Write([]byte{})
+	if err != nil {
+		return false
+	}
+
+	_, err = conn.Read(make([]byte, 1))
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return err == nil
+}
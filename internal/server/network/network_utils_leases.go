@@ -0,0 +1,220 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/lxc/incus/v7/internal/server/db"
+	dbCluster "github.com/lxc/incus/v7/internal/server/db/cluster"
+	"github.com/lxc/incus/v7/internal/server/dnsmasq/dhcpalloc"
+	"github.com/lxc/incus/v7/shared/api"
+	"github.com/lxc/incus/v7/shared/validate"
+)
+
+// validateReservedRanges checks that the reserved ranges of the address families are within the network subnets.
+func (n *common) validateReservedRanges(config map[string]string, keyPrefixes ...string) error {
+	for _, keyPrefix := range keyPrefixes {
+		key := keyPrefix + ".reserved_ranges"
+		if config[key] == "" {
+			continue
+		}
+
+		_, subnet, err := net.ParseCIDR(config[keyPrefix+".address"])
+		if err != nil {
+			return fmt.Errorf("%q requires %q to be set to a subnet", key, keyPrefix+".address")
+		}
+
+		_, err = parseIPRanges(config[key], subnet)
+		if err != nil {
+			return fmt.Errorf("Failed parsing %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// leaseValidate validates the address reservation request against the network and its other reservations.
+func (n *common) leaseValidate(address net.IP, lease *api.NetworkLeasePut, others []*api.NetworkLease) error {
+	if address == nil {
+		return errors.New("Invalid address")
+	}
+
+	// The address must be within the subnet of the network and not be used by the network itself.
+	keyPrefix, family := "ipv6", "IPv6"
+	if address.To4() != nil {
+		keyPrefix, family = "ipv4", "IPv4"
+	}
+
+	routerIP, subnet, err := net.ParseCIDR(n.config[keyPrefix+".address"])
+	if err != nil {
+		return fmt.Errorf("Network doesn't have an %s subnet", family)
+	}
+
+	if !subnet.Contains(address) {
+		return fmt.Errorf("Address %q isn't within the network subnet %q", address.String(), subnet.String())
+	}
+
+	if address.Equal(routerIP) || address.Equal(subnet.IP) || (keyPrefix == "ipv4" && address.Equal(dhcpalloc.GetIP(subnet, -1))) {
+		return fmt.Errorf("Address %q can't be reserved", address.String())
+	}
+
+	if lease.Hwaddr != "" {
+		err = validate.IsNetworkMAC(lease.Hwaddr)
+		if err != nil {
+			return fmt.Errorf("Invalid MAC address %q", lease.Hwaddr)
+		}
+	}
+
+	if lease.Hostname != "" {
+		err = validate.IsHostname(lease.Hostname)
+		if err != nil {
+			return fmt.Errorf("Invalid hostname %q: %w", lease.Hostname, err)
+		}
+	}
+
+	// A MAC address can only have a single reserved address per address family.
+	for _, other := range others {
+		otherAddress := net.ParseIP(other.Address)
+		if otherAddress == nil || otherAddress.Equal(address) || (otherAddress.To4() == nil) != (address.To4() == nil) {
+			continue
+		}
+
+		if lease.Hwaddr != "" && strings.EqualFold(other.Hwaddr, lease.Hwaddr) {
+			return api.StatusErrorf(http.StatusConflict, "The MAC address %q already has the reserved address %q", lease.Hwaddr, other.Address)
+		}
+	}
+
+	// Check the address isn't statically assigned to an instance NIC with another MAC address.
+	return UsedByInstanceDevices(n.state, n.project, n.name, n.netType, func(inst db.InstanceArgs, nicName string, nicConfig map[string]string) error {
+		nicIP := net.ParseIP(nicAddressIP(nicConfig[keyPrefix+".address"]))
+		if nicIP == nil || !nicIP.Equal(address) {
+			return nil
+		}
+
+		hwaddr := nicConfig["hwaddr"]
+		if hwaddr == "" {
+			hwaddr = inst.Config[fmt.Sprintf("volatile.%s.hwaddr", nicName)]
+		}
+
+		if lease.Hwaddr == "" || !strings.EqualFold(hwaddr, lease.Hwaddr) {
+			return api.StatusErrorf(http.StatusConflict, "Address %q is already used by instance %q", address.String(), inst.Name)
+		}
+
+		return nil
+	})
+}
+
+// leasesLoad returns the address reservations of the network.
+func (n *common) leasesLoad() ([]*api.NetworkLease, error) {
+	var leases []*api.NetworkLease
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		networkID := n.ID()
+		dbRecords, err := dbCluster.GetNetworkLeases(ctx, tx.Tx(), dbCluster.NetworkLeaseFilter{
+			NetworkID: &networkID,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, dbRecord := range dbRecords {
+			leases = append(leases, dbRecord.ToAPI())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading network address reservations: %w", err)
+	}
+
+	return leases, nil
+}
+
+// LeaseAddresses returns the IPv4 and IPv6 addresses reserved for the MAC address, if any.
+func (n *common) LeaseAddresses(hwaddr string) (net.IP, net.IP, error) {
+	var ipv4, ipv6 net.IP
+
+	if hwaddr == "" {
+		return nil, nil, nil
+	}
+
+	leases, err := n.leasesLoad()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, lease := range leases {
+		if !strings.EqualFold(lease.Hwaddr, hwaddr) {
+			continue
+		}
+
+		address := net.ParseIP(lease.Address)
+		if address.To4() != nil {
+			ipv4 = address
+		} else {
+			ipv6 = address
+		}
+	}
+
+	return ipv4, ipv6, nil
+}
+
+// leaseCreateRecord creates the DB record of the address reservation.
+func (n *common) leaseCreateRecord(lease *api.NetworkLease) error {
+	return n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, err := dbCluster.CreateNetworkLease(ctx, tx.Tx(), dbCluster.NetworkLease{
+			NetworkID:   n.ID(),
+			Address:     lease.Address,
+			Hwaddr:      lease.Hwaddr,
+			Hostname:    lease.Hostname,
+			Description: lease.Description,
+		})
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// leaseUpdateRecord updates the DB record of the address reservation.
+func (n *common) leaseUpdateRecord(lease *api.NetworkLease) error {
+	return n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return dbCluster.UpdateNetworkLease(ctx, tx.Tx(), n.ID(), lease.Address, dbCluster.NetworkLease{
+			NetworkID:   n.ID(),
+			Address:     lease.Address,
+			Hwaddr:      lease.Hwaddr,
+			Hostname:    lease.Hostname,
+			Description: lease.Description,
+		})
+	})
+}
+
+// leaseDeleteRecord deletes the DB record of the address reservation and returns the deleted reservation.
+func (n *common) leaseDeleteRecord(address string) (*api.NetworkLease, error) {
+	var lease *api.NetworkLease
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbRecord, err := dbCluster.GetNetworkLease(ctx, tx.Tx(), n.ID(), address)
+		if err != nil {
+			if api.StatusErrorCheck(err, http.StatusNotFound) {
+				return api.StatusErrorf(http.StatusNotFound, "Network address reservation not found")
+			}
+
+			return err
+		}
+
+		lease = dbRecord.ToAPI()
+
+		return dbCluster.DeleteNetworkLease(ctx, tx.Tx(), n.ID(), dbRecord.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return lease, nil
+}
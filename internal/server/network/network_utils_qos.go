@@ -0,0 +1,262 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+
+	"github.com/lxc/incus/v7/internal/server/db"
+	dbCluster "github.com/lxc/incus/v7/internal/server/db/cluster"
+	"github.com/lxc/incus/v7/shared/units"
+	"github.com/lxc/incus/v7/shared/validate"
+)
+
+// qosMinimumRate is the rate in bit/s given to the traffic control classes without a guaranteed bandwidth.
+const qosMinimumRate = 8000
+
+// qosDefaultClassMinor is the minor number of the traffic control class of the traffic outside of any QoS class.
+const qosDefaultClassMinor = 0xffff
+
+// qosClass represents a QoS class of a network.
+type qosClass struct {
+	name       string
+	guaranteed int64  // Guaranteed bandwidth in bit/s, 0 if not set.
+	ceiling    int64  // Maximum bandwidth in bit/s shared by the NICs of the class, 0 if not set.
+	nicCeiling int64  // Maximum bandwidth in bit/s of each NIC of the class, 0 if not set.
+	dscp       string // DSCP value of the traffic sent by the NICs of the class, empty if not set.
+}
+
+// qosClassMinor returns the minor number of the traffic control class of the QoS class.
+// It is derived from the class name so that it remains stable across restarts and changes to the other classes.
+func qosClassMinor(name string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+
+	return 0x100 + h.Sum32()%(0xff00-0x100)
+}
+
+// qosParseBandwidth parses a bandwidth value in bit/s.
+func qosParseBandwidth(value string) (int64, error) {
+	bandwidth, err := units.ParseBitSizeString(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if bandwidth <= 0 {
+		return 0, errors.New("Bandwidth must be greater than zero")
+	}
+
+	return bandwidth, nil
+}
+
+// qosClasses returns the QoS classes defined in the network config sorted by name.
+func qosClasses(config map[string]string) []qosClass {
+	classes := map[string]*qosClass{}
+
+	for k, v := range config {
+		if !strings.HasPrefix(k, "qos.classes.") {
+			continue
+		}
+
+		fields := strings.Split(k, ".")
+		if len(fields) != 4 {
+			continue
+		}
+
+		class, ok := classes[fields[2]]
+		if !ok {
+			class = &qosClass{name: fields[2]}
+			classes[fields[2]] = class
+		}
+
+		switch fields[3] {
+		case "guaranteed":
+			class.guaranteed, _ = qosParseBandwidth(v)
+		case "ceiling":
+			class.ceiling, _ = qosParseBandwidth(v)
+		case "nic_ceiling":
+			class.nicCeiling, _ = qosParseBandwidth(v)
+		case "dscp":
+			class.dscp = v
+		}
+	}
+
+	result := make([]qosClass, 0, len(classes))
+	for _, class := range classes {
+		result = append(result, *class)
+	}
+
+	slices.SortFunc(result, func(a qosClass, b qosClass) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	return result
+}
+
+// QoSHasClass returns whether the network config defines the QoS class.
+func QoSHasClass(config map[string]string, name string) bool {
+	for k := range config {
+		if strings.HasPrefix(k, fmt.Sprintf("qos.classes.%s.", name)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// QoSNICClass returns the QoS class of an instance NIC of the network. This is either the one set on the NIC or
+// else the default one of the project of the instance, if the network has it.
+func QoSNICClass(netConfig map[string]string, nicConfig map[string]string, projectConfig map[string]string) string {
+	if nicConfig["qos.class"] != "" {
+		return nicConfig["qos.class"]
+	}
+
+	className := projectConfig["network.qos_class"]
+	if className != "" && QoSHasClass(netConfig, className) {
+		return className
+	}
+
+	return ""
+}
+
+// qosValidationRules returns the validation rules of the QoS class keys of the network config.
+// Only the class options listed in supported are accepted.
+func (n *common) qosValidationRules(config map[string]string, supported ...string) (map[string]func(value string) error, error) {
+	rules := map[string]func(value string) error{}
+	for k := range config {
+		// QoS class keys have the class name in their name, extract the suffix.
+		if !strings.HasPrefix(k, "qos.classes.") {
+			continue
+		}
+
+		// Validate class name in key.
+		fields := strings.Split(k, ".")
+		if len(fields) != 4 || fields[2] == "" {
+			return nil, fmt.Errorf("Invalid network configuration key: %q", k)
+		}
+
+		// Unsupported keys are left without a rule so that they get rejected.
+		if !slices.Contains(supported, fields[3]) {
+			continue
+		}
+
+		// Add the correct validation rule for the dynamic field based on last part of key.
+		switch fields[3] {
+		case "guaranteed", "ceiling", "nic_ceiling":
+			rules[k] = validate.Optional(func(value string) error {
+				_, err := qosParseBandwidth(value)
+				return err
+			})
+		case "dscp":
+			rules[k] = validate.Optional(validate.IsInRange(0, 63))
+		}
+	}
+
+	return rules, nil
+}
+
+// qosValidate checks the consistency of the QoS classes with the total bandwidth of the network.
+// A bandwidth of 0 means the network doesn't have a total bandwidth.
+func qosValidate(classes []qosClass, bandwidth int64) error {
+	var totalGuaranteed int64
+	minors := map[uint32]string{}
+
+	for _, class := range classes {
+		if class.guaranteed > 0 && class.ceiling > 0 && class.guaranteed > class.ceiling {
+			return fmt.Errorf("The guaranteed bandwidth of QoS class %q can't exceed its ceiling", class.name)
+		}
+
+		if bandwidth > 0 {
+			if class.guaranteed > bandwidth || class.ceiling > bandwidth {
+				return fmt.Errorf("The bandwidth of QoS class %q can't exceed %q", class.name, "qos.bandwidth")
+			}
+
+			minor := qosClassMinor(class.name)
+			other, ok := minors[minor]
+			if ok {
+				return fmt.Errorf("QoS classes %q and %q can't be used together, please rename one of them", other, class.name)
+			}
+
+			minors[minor] = class.name
+		}
+
+		totalGuaranteed += class.guaranteed
+	}
+
+	if bandwidth > 0 && totalGuaranteed > bandwidth {
+		return fmt.Errorf("The guaranteed bandwidths of the QoS classes can't exceed %q", "qos.bandwidth")
+	}
+
+	return nil
+}
+
+// qosClassHwAddrs returns the MAC addresses of the instance NICs of the network per QoS class.
+// The class of a NIC is set by its "qos.class" option, or else by the "network.qos_class" option of
+// the project of its instance when the network has such a class.
+// If hwAddr isn't empty, the NIC with that MAC address is put in className instead, for use by NICs
+// whose configuration isn't stored yet.
+func (n *common) qosClassHwAddrs(classes []qosClass, hwAddr string, className string) (map[string][]string, error) {
+	hwAddrs := map[string][]string{}
+
+	if len(classes) == 0 {
+		return hwAddrs, nil
+	}
+
+	addHwAddr := func(hwAddr string, className string) {
+		for _, class := range classes {
+			if class.name == className {
+				hwAddrs[className] = append(hwAddrs[className], hwAddr)
+				return
+			}
+		}
+	}
+
+	projectConfigs := map[string]map[string]string{}
+
+	err := UsedByInstanceDevices(n.state, n.project, n.name, n.netType, func(inst db.InstanceArgs, nicName string, nicConfig map[string]string) error {
+		nicHwAddr := inst.Config[fmt.Sprintf("volatile.%s.hwaddr", nicName)]
+		if nicConfig["hwaddr"] != "" {
+			nicHwAddr = nicConfig["hwaddr"]
+		}
+
+		// The MAC address is only known once the instance was started.
+		if nicHwAddr == "" || strings.EqualFold(nicHwAddr, hwAddr) {
+			return nil
+		}
+
+		projectConfig, ok := projectConfigs[inst.Project]
+		if !ok {
+			err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+				dbProject, err := dbCluster.GetProject(ctx, tx.Tx(), inst.Project)
+				if err != nil {
+					return err
+				}
+
+				projectConfig, err = dbCluster.GetProjectConfig(ctx, tx.Tx(), dbProject.ID)
+
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("Failed loading project %q: %w", inst.Project, err)
+			}
+
+			projectConfigs[inst.Project] = projectConfig
+		}
+
+		addHwAddr(nicHwAddr, QoSNICClass(n.config, nicConfig, projectConfig))
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed finding instances using QoS classes: %w", err)
+	}
+
+	if hwAddr != "" {
+		addHwAddr(hwAddr, className)
+	}
+
+	return hwAddrs, nil
+}
@@ -0,0 +1,34 @@
+package network
+
+import (
+	"fmt"
+)
+
+func Example_qosValidate() {
+	config := map[string]string{
+		"qos.classes.critical.guaranteed": "300Mbit",
+		"qos.classes.critical.dscp":       "46",
+		"qos.classes.batch.ceiling":       "100Mbit",
+		"qos.classes.batch.dscp":          "8",
+	}
+
+	classes := qosClasses(config)
+	for _, class := range classes {
+		fmt.Printf("%s: guaranteed=%d ceiling=%d dscp=%s\n", class.name, class.guaranteed, class.ceiling, class.dscp)
+	}
+
+	fmt.Printf("Err: %v\n", qosValidate(classes, 1000000000))
+	fmt.Printf("Err: %v\n", qosValidate(classes, 200000000))
+
+	config["qos.classes.other.guaranteed"] = "800Mbit"
+	fmt.Printf("Err: %v\n", qosValidate(qosClasses(config), 1000000000))
+
+	fmt.Println(qosClassMinor("critical") == qosClassMinor("critical"), qosClassMinor("critical") >= 0x100, qosClassMinor("critical") < 0xff00)
+
+	// Output: batch: guaranteed=0 ceiling=100000000 dscp=8
+	// critical: guaranteed=300000000 ceiling=0 dscp=46
+	// Err: <nil>
+	// Err: The bandwidth of QoS class "critical" can't exceed "qos.bandwidth"
+	// Err: The guaranteed bandwidths of the QoS classes can't exceed "qos.bandwidth"
+	// true true true
+}
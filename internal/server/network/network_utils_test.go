@@ -180,3 +180,42 @@ func Example_complementRanges() {
 	// Range2: 10.1.1.1-10.1.1.9, 10.1.1.101-10.1.1.199, 10.1.1.231-10.1.1.254
 	// Range3: 10.1.1.1-10.1.1.9, 10.1.1.26-10.1.1.254
 }
+
+func Example_subtractRanges() {
+	ranges := []iprange.Range{
+		{Start: net.ParseIP("10.1.1.2").To4(), End: net.ParseIP("10.1.1.254").To4()},
+		{Start: net.ParseIP("fd42::2"), End: net.ParseIP("fd42::ffff")},
+	}
+
+	excluded := [][]iprange.Range{
+		nil,
+		{
+			{Start: net.ParseIP("10.1.1.100"), End: net.ParseIP("10.1.1.149")},
+		},
+		{
+			{Start: net.ParseIP("10.1.1.2"), End: net.ParseIP("10.1.1.9")},
+			{Start: net.ParseIP("10.1.1.250")},
+			{Start: net.ParseIP("fd42::100"), End: net.ParseIP("fd42::1ff")},
+		},
+		{
+			{Start: net.ParseIP("10.1.1.1"), End: net.ParseIP("10.1.1.255")},
+		},
+	}
+
+	for idx, r := range excluded {
+		result := subtractRanges(ranges, r)
+
+		parts := make([]string, len(result))
+		for i, r := range result {
+			parts[i] = fmt.Sprintf("%s-%s", r.Start.String(), r.End.String())
+		}
+
+		fmt.Printf("Range%d: %s\n", idx+1, strings.Join(parts, ", "))
+	}
+
+	// Output:
+	// Range1: 10.1.1.2-10.1.1.254, fd42::2-fd42::ffff
+	// Range2: 10.1.1.2-10.1.1.99, 10.1.1.150-10.1.1.254, fd42::2-fd42::ffff
+	// Range3: 10.1.1.10-10.1.1.249, 10.1.1.251-10.1.1.254, fd42::2-fd42::ff, fd42::200-fd42::ffff
+	// Range4: fd42::2-fd42::ffff
+}
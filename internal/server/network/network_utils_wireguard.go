@@ -0,0 +1,282 @@
+package network
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lxc/incus/v7/internal/server/db"
+	dbCluster "github.com/lxc/incus/v7/internal/server/db/cluster"
+	"github.com/lxc/incus/v7/internal/server/ip"
+	"github.com/lxc/incus/v7/internal/server/state"
+	"github.com/lxc/incus/v7/shared/subprocess"
+	"github.com/lxc/incus/v7/shared/util"
+)
+
+// wireguardInterfacePrefix is the prefix of the interfaces of the WireGuard network integrations.
+const wireguardInterfacePrefix = "incuswg"
+
+// wireguardDefaultMTU is the MTU of the WireGuard interfaces unless specified in the integration.
+const wireguardDefaultMTU = 1420
+
+// WireGuardGenerateKey generates a new WireGuard private key.
+func WireGuardGenerateKey() (string, error) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("Failed generating WireGuard key: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(key.Bytes()), nil
+}
+
+// WireGuardPublicKey returns the public key matching the WireGuard private key.
+func WireGuardPublicKey(privateKey string) (string, error) {
+	err := WireGuardValidateKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	rawKey, _ := base64.StdEncoding.DecodeString(privateKey)
+
+	key, err := ecdh.X25519().NewPrivateKey(rawKey)
+	if err != nil {
+		return "", fmt.Errorf("Invalid WireGuard key: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(key.PublicKey().Bytes()), nil
+}
+
+// WireGuardValidateKey validates a base64 encoded WireGuard key.
+func WireGuardValidateKey(value string) error {
+	rawKey, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(rawKey) != 32 {
+		return errors.New("Invalid WireGuard key")
+	}
+
+	return nil
+}
+
+// wireguardPeerSubnets returns the remote subnets of a WireGuard network peer.
+func wireguardPeerSubnets(config map[string]string) ([]*net.IPNet, error) {
+	var subnets []*net.IPNet
+
+	for _, value := range util.SplitNTrimSpace(config["wireguard.subnets"], ",", -1, true) {
+		_, subnet, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid WireGuard subnet %q: %w", value, err)
+		}
+
+		subnets = append(subnets, subnet)
+	}
+
+	return subnets, nil
+}
+
+// wireguardConfig returns the WireGuard configuration of the integration in the format of "wg setconf".
+func wireguardConfig(config map[string]string, allowedIPs []*net.IPNet) string {
+	var sb strings.Builder
+
+	listenPort := config["wireguard.listen_port"]
+	if listenPort == "" {
+		listenPort = "51820"
+	}
+
+	sb.WriteString("[Interface]\n")
+	fmt.Fprintf(&sb, "PrivateKey = %s\n", config["wireguard.private_key"])
+	fmt.Fprintf(&sb, "ListenPort = %s\n", listenPort)
+
+	sb.WriteString("\n[Peer]\n")
+	fmt.Fprintf(&sb, "PublicKey = %s\n", config["wireguard.peer.public_key"])
+
+	if config["wireguard.peer.preshared_key"] != "" {
+		fmt.Fprintf(&sb, "PresharedKey = %s\n", config["wireguard.peer.preshared_key"])
+	}
+
+	if config["wireguard.peer.endpoint"] != "" {
+		fmt.Fprintf(&sb, "Endpoint = %s\n", config["wireguard.peer.endpoint"])
+	}
+
+	if config["wireguard.peer.persistent_keepalive"] != "" {
+		fmt.Fprintf(&sb, "PersistentKeepalive = %s\n", config["wireguard.peer.persistent_keepalive"])
+	}
+
+	subnets := make([]string, 0, len(allowedIPs))
+	for _, subnet := range allowedIPs {
+		subnets = append(subnets, subnet.String())
+	}
+
+	fmt.Fprintf(&sb, "AllowedIPs = %s\n", strings.Join(subnets, ", "))
+
+	return sb.String()
+}
+
+// wireguardTunnel represents the WireGuard tunnel of a network integration.
+type wireguardTunnel struct {
+	id      int
+	name    string
+	config  map[string]string
+	subnets []*net.IPNet
+}
+
+// WireGuardApply sets up the tunnels of the WireGuard network integrations used by network peers on the local
+// server, routing the subnets of the peers through them, and removes the tunnels which are no longer used.
+func WireGuardApply(s *state.State) error {
+	var tunnels []*wireguardTunnel
+
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		integrations, err := dbCluster.GetNetworkIntegrations(ctx, tx.Tx())
+		if err != nil {
+			return fmt.Errorf("Failed loading network integrations: %w", err)
+		}
+
+		tunnelsByID := map[int64]*wireguardTunnel{}
+		for _, integration := range integrations {
+			if integration.Type != dbCluster.NetworkIntegrationTypeWireGuard {
+				continue
+			}
+
+			config, err := dbCluster.GetNetworkIntegrationConfig(ctx, tx.Tx(), integration.ID)
+			if err != nil {
+				return err
+			}
+
+			tunnel := &wireguardTunnel{id: integration.ID, name: integration.Name, config: config}
+			tunnels = append(tunnels, tunnel)
+			tunnelsByID[int64(integration.ID)] = tunnel
+		}
+
+		if len(tunnels) == 0 {
+			return nil
+		}
+
+		peers, err := dbCluster.GetNetworkPeers(ctx, tx.Tx())
+		if err != nil {
+			return fmt.Errorf("Failed loading network peers: %w", err)
+		}
+
+		for _, peer := range peers {
+			if peer.Type != dbCluster.NetworkPeerTypeRemote || !peer.TargetNetworkIntegrationID.Valid {
+				continue
+			}
+
+			tunnel, found := tunnelsByID[peer.TargetNetworkIntegrationID.Int64]
+			if !found {
+				continue
+			}
+
+			config, err := dbCluster.GetNetworkPeerConfig(ctx, tx.Tx(), int(peer.ID))
+			if err != nil {
+				return err
+			}
+
+			subnets, err := wireguardPeerSubnets(config)
+			if err != nil {
+				return err
+			}
+
+			tunnel.subnets = append(tunnel.subnets, subnets...)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, tunnel := range tunnels {
+		err := wireguardTunnelApply(s, tunnel)
+		if err != nil {
+			return fmt.Errorf("Failed setting up WireGuard tunnel of network integration %q: %w", tunnel.name, err)
+		}
+	}
+
+	return nil
+}
+
+// wireguardTunnelApply sets up or removes the interface of a WireGuard tunnel.
+func wireguardTunnelApply(s *state.State, tunnel *wireguardTunnel) error {
+	link := &ip.Wireguard{Link: ip.Link{Name: fmt.Sprintf("%s%d", wireguardInterfacePrefix, tunnel.id)}}
+
+	// In a cluster, the tunnel is only set up on the member hosting it.
+	if len(tunnel.subnets) == 0 || (s.ServerClustered && tunnel.config["wireguard.member"] != s.ServerName) {
+		if InterfaceExists(link.Name) {
+			return link.Delete()
+		}
+
+		return nil
+	}
+
+	mtu := uint32(wireguardDefaultMTU)
+	if tunnel.config["wireguard.mtu"] != "" {
+		value, err := strconv.ParseUint(tunnel.config["wireguard.mtu"], 10, 32)
+		if err != nil {
+			return fmt.Errorf("Invalid MTU %q: %w", tunnel.config["wireguard.mtu"], err)
+		}
+
+		mtu = uint32(value)
+	}
+
+	if !InterfaceExists(link.Name) {
+		err := link.Add()
+		if err != nil {
+			return err
+		}
+	}
+
+	// The configuration is passed through stdin to keep the keys off the command line.
+	err := subprocess.RunCommandWithFds(context.TODO(), strings.NewReader(wireguardConfig(tunnel.config, tunnel.subnets)), nil, "wg", "setconf", link.Name, "/dev/stdin")
+	if err != nil {
+		return err
+	}
+
+	err = link.SetMTU(mtu)
+	if err != nil {
+		return err
+	}
+
+	err = link.SetUp()
+	if err != nil {
+		return err
+	}
+
+	// Remove the routes of the subnets no longer peered.
+	for _, family := range []ip.Family{ip.FamilyV4, ip.FamilyV6} {
+		routes, err := (&ip.Route{DevName: link.Name, Family: family, Table: "main", Proto: "static"}).List()
+		if err != nil {
+			return err
+		}
+
+		for _, route := range routes {
+			if route.Route == nil || slices.ContainsFunc(tunnel.subnets, func(subnet *net.IPNet) bool { return subnet.String() == route.Route.String() }) {
+				continue
+			}
+
+			err = (&ip.Route{DevName: link.Name, Route: route.Route, Family: family, Table: "main", Proto: "static"}).Delete()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Route the subnets of the peers through the tunnel.
+	for _, subnet := range tunnel.subnets {
+		family := ip.FamilyV4
+		if subnet.IP.To4() == nil {
+			family = ip.FamilyV6
+		}
+
+		err = (&ip.Route{DevName: link.Name, Route: subnet, Family: family, Table: "main", Proto: "static"}).Replace()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package network
+
+import (
+	"fmt"
+	"net"
+)
+
+func Example_wireguardConfig() {
+	// Key pair from the X25519 test vectors of RFC 7748.
+	privateKey := "dwdtCnMYpX08FsFyUbJmRd9ML4frwJkqsXf7pR25LCo="
+
+	publicKey, err := WireGuardPublicKey(privateKey)
+	if err != nil {
+		fmt.Printf("Err: %v\n", err)
+		return
+	}
+
+	fmt.Println(publicKey)
+
+	_, err = WireGuardPublicKey("not-a-key")
+	fmt.Printf("Err: %v\n", err)
+
+	_, subnetV4, _ := net.ParseCIDR("10.20.0.0/16")
+	_, subnetV6, _ := net.ParseCIDR("fd20::/64")
+
+	fmt.Print(wireguardConfig(map[string]string{
+		"wireguard.private_key":               privateKey,
+		"wireguard.peer.public_key":           publicKey,
+		"wireguard.peer.endpoint":             "core.example.net:51821",
+		"wireguard.peer.persistent_keepalive": "25",
+	}, []*net.IPNet{subnetV4, subnetV6}))
+
+	// Output: hSDwCYkwp1R0i33ctD73Wg2/Og0mOBr066SpjqqbTmo=
+	// Err: Invalid WireGuard key
+	// [Interface]
+	// PrivateKey = dwdtCnMYpX08FsFyUbJmRd9ML4frwJkqsXf7pR25LCo=
+	// ListenPort = 51820
+	//
+	// [Peer]
+	// PublicKey = hSDwCYkwp1R0i33ctD73Wg2/Og0mOBr066SpjqqbTmo=
+	// Endpoint = core.example.net:51821
+	// PersistentKeepalive = 25
+	// AllowedIPs = 10.20.0.0/16, fd20::/64
+}
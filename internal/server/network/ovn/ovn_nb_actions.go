@@ -158,6 +158,12 @@ type OVNQoSRule struct {
 	Priority  int
 }
 
+// OVNMirror represents an OVN mirror of a logical switch port to a local interface.
+type OVNMirror struct {
+	Filter string // One of from-lport, to-lport or both.
+	Sink   string // Matches the mirror-id external ID of the target OVS interface.
+}
+
 // OVNLoadBalancerTarget represents an OVN load balancer Virtual IP target.
 type OVNLoadBalancerTarget struct {
 	Address net.IP
@@ -584,6 +590,82 @@ func (o *NB) DeleteLogicalRouterNAT(ctx context.Context, routerName OVNRouter, n
 	return nil
 }
 
+// DeleteLogicalRouterNATByLogicalIP deletes the NAT rules of a particular type translating packets from intNets.
+func (o *NB) DeleteLogicalRouterNATByLogicalIP(ctx context.Context, routerName OVNRouter, natType string, intNets ...*net.IPNet) error {
+	// Get the logical router.
+	logicalRouter, err := o.GetLogicalRouter(ctx, routerName)
+	if err != nil {
+		return err
+	}
+
+	logicalIPs := make([]string, 0, len(intNets))
+	for _, intNet := range intNets {
+		logicalIPs = append(logicalIPs, intNet.String())
+
+		// Single addresses may also be recorded without prefix length.
+		ones, bits := intNet.Mask.Size()
+		if ones == bits {
+			logicalIPs = append(logicalIPs, intNet.IP.String())
+		}
+	}
+
+	operations := []ovsdb.Operation{}
+
+	// Go through all rules.
+	for _, natUUID := range logicalRouter.Nat {
+		natRule := ovnNB.NAT{
+			UUID: natUUID,
+		}
+
+		err = o.get(ctx, &natRule)
+		if err != nil {
+			return err
+		}
+
+		// Check if rule is of the requested type and matches one of the logical addresses.
+		if natRule.Type != natType || !slices.Contains(logicalIPs, natRule.LogicalIP) {
+			continue
+		}
+
+		// Delete the rule.
+		deleteOps, err := o.client.Where(&natRule).Delete()
+		if err != nil {
+			return err
+		}
+
+		operations = append(operations, deleteOps...)
+
+		// Delete the entry from the logical router.
+		deleteOps, err = o.client.Where(logicalRouter).Mutate(logicalRouter, ovsModel.Mutation{
+			Field:   &logicalRouter.Nat,
+			Mutator: ovsdb.MutateOperationDelete,
+			Value:   []string{natRule.UUID},
+		})
+		if err != nil {
+			return err
+		}
+
+		operations = append(operations, deleteOps...)
+	}
+
+	if len(operations) == 0 {
+		return nil
+	}
+
+	// Apply the changes.
+	resp, err := o.client.Transact(ctx, operations...)
+	if err != nil {
+		return err
+	}
+
+	_, err = ovsdb.CheckOperationResults(resp, operations)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // CreateStaticMACBinding ensures a Static_Mac_Binding row exists in NB for this router port/IP.
 func (o *NB) CreateStaticMACBinding(ctx context.Context, portName OVNRouterPort, ip net.IP, mac net.HardwareAddr, mayExist bool) error {
 	binding := ovnNB.StaticMACBinding{
@@ -3332,6 +3414,100 @@ func (o *NB) SetLogicalSwitchQoSRules(ctx context.Context, switchName OVNSwitch,
 	return nil
 }
 
+// SetLogicalSwitchPortMirror replaces the mirror of the specified logical switch port (nil removes it).
+func (o *NB) SetLogicalSwitchPortMirror(ctx context.Context, portName OVNSwitchPort, mirror *OVNMirror) error {
+	var operations []ovsdb.Operation
+
+	logicalSwitchPort := ovnNB.LogicalSwitchPort{
+		Name: string(portName),
+	}
+
+	err := o.get(ctx, &logicalSwitchPort)
+	if err != nil {
+		return err
+	}
+
+	// Remove any existing mirror for the port.
+	mirrors := []ovnNB.Mirror{}
+
+	err = o.client.WhereCache(func(mirror *ovnNB.Mirror) bool {
+		return mirror.ExternalIDs != nil && mirror.ExternalIDs[ovnExtIDIncusSwitchPort] == string(portName)
+	}).List(ctx, &mirrors)
+	if err != nil {
+		return err
+	}
+
+	for _, existing := range mirrors {
+		if slices.Contains(logicalSwitchPort.MirrorRules, existing.UUID) {
+			updateOps, err := o.client.Where(&logicalSwitchPort).Mutate(&logicalSwitchPort, ovsModel.Mutation{
+				Field:   &logicalSwitchPort.MirrorRules,
+				Mutator: ovsdb.MutateOperationDelete,
+				Value:   []string{existing.UUID},
+			})
+			if err != nil {
+				return err
+			}
+
+			operations = append(operations, updateOps...)
+		}
+
+		deleteOps, err := o.client.Where(&existing).Delete()
+		if err != nil {
+			return err
+		}
+
+		operations = append(operations, deleteOps...)
+	}
+
+	// Add the new mirror.
+	if mirror != nil {
+		newMirror := ovnNB.Mirror{
+			UUID:   "mirror",
+			Name:   string(portName),
+			Filter: mirror.Filter,
+			Sink:   mirror.Sink,
+			Type:   ovnNB.MirrorTypeLocal,
+			ExternalIDs: map[string]string{
+				ovnExtIDIncusSwitchPort: string(portName),
+			},
+		}
+
+		createOps, err := o.client.Create(&newMirror)
+		if err != nil {
+			return err
+		}
+
+		operations = append(operations, createOps...)
+
+		updateOps, err := o.client.Where(&logicalSwitchPort).Mutate(&logicalSwitchPort, ovsModel.Mutation{
+			Field:   &logicalSwitchPort.MirrorRules,
+			Mutator: ovsdb.MutateOperationInsert,
+			Value:   []string{newMirror.UUID},
+		})
+		if err != nil {
+			return err
+		}
+
+		operations = append(operations, updateOps...)
+	}
+
+	if len(operations) == 0 {
+		return nil
+	}
+
+	resp, err := o.client.Transact(ctx, operations...)
+	if err != nil {
+		return err
+	}
+
+	_, err = ovsdb.CheckOperationResults(resp, operations)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (o *NB) qosRuleAddOperations(ctx context.Context, entityTable string, entityName string, externalIDs map[string]string, matchReplace map[string]string, qosRules ...OVNQoSRule) ([]ovsdb.Operation, error) {
 	operations := []ovsdb.Operation{}
 
@@ -169,6 +169,102 @@ func (o *VSwitch) DeleteBridge(ctx context.Context, bridgeName string) error {
 	return nil
 }
 
+// BridgeFlowExport represents the flow export settings of a bridge.
+type BridgeFlowExport struct {
+	Protocol string // Either ipfix or sflow.
+	Targets  []string
+	Sampling int
+	DomainID int // IPFIX observation domain.
+}
+
+// SetBridgeFlowExport replaces the flow export settings of the bridge, with at most one export per protocol.
+// Flow export is disabled when no export is provided.
+func (o *VSwitch) SetBridgeFlowExport(ctx context.Context, bridgeName string, exports ...BridgeFlowExport) error {
+	// Get the bridge.
+	bridge := ovsSwitch.Bridge{
+		Name: bridgeName,
+	}
+
+	err := o.client.Get(ctx, &bridge)
+	if err != nil {
+		return err
+	}
+
+	// The previous records are garbage collected once no longer referenced by the bridge.
+	operations := []ovsdb.Operation{}
+	bridge.IPFIX = nil
+	bridge.Sflow = nil
+
+	for _, export := range exports {
+		sampling := export.Sampling
+
+		switch export.Protocol {
+		case "ipfix":
+			if bridge.IPFIX != nil {
+				return errors.New("Only one IPFIX export is supported per bridge")
+			}
+
+			domainID := export.DomainID
+			activeTimeout := 60
+
+			ipfix := ovsSwitch.IPFIX{
+				UUID:               "ipfix",
+				Targets:            export.Targets,
+				Sampling:           &sampling,
+				ObsDomainID:        &domainID,
+				CacheActiveTimeout: &activeTimeout,
+			}
+
+			createOps, err := o.client.Create(&ipfix)
+			if err != nil {
+				return err
+			}
+
+			operations = append(operations, createOps...)
+			bridge.IPFIX = &ipfix.UUID
+		case "sflow":
+			if bridge.Sflow != nil {
+				return errors.New("Only one sFlow export is supported per bridge")
+			}
+
+			sflow := ovsSwitch.SFlow{
+				UUID:     "sflow",
+				Targets:  export.Targets,
+				Sampling: &sampling,
+			}
+
+			createOps, err := o.client.Create(&sflow)
+			if err != nil {
+				return err
+			}
+
+			operations = append(operations, createOps...)
+			bridge.Sflow = &sflow.UUID
+		default:
+			return fmt.Errorf("Unsupported flow export protocol %q", export.Protocol)
+		}
+	}
+
+	updateOps, err := o.client.Where(&bridge).Update(&bridge, &bridge.IPFIX, &bridge.Sflow)
+	if err != nil {
+		return err
+	}
+
+	operations = append(operations, updateOps...)
+
+	resp, err := o.client.Transact(ctx, operations...)
+	if err != nil {
+		return err
+	}
+
+	_, err = ovsdb.CheckOperationResults(resp, operations)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // CreateBridgePort adds a port to the bridge.
 func (o *VSwitch) CreateBridgePort(ctx context.Context, bridgeName string, portName string, mayExist bool) error {
 	// Get the bridge.
@@ -478,6 +574,43 @@ func (o *VSwitch) AssociateInterfaceOVNSwitchPort(ctx context.Context, interface
 	return nil
 }
 
+// SetInterfaceMirrorID sets the mirror-id used to select the interface as the sink of OVN local mirrors.
+func (o *VSwitch) SetInterfaceMirrorID(ctx context.Context, interfaceName string, mirrorID string) error {
+	// Get the OVS interface.
+	ovsInterface := &ovsSwitch.Interface{
+		Name: interfaceName,
+	}
+
+	err := o.client.Get(ctx, ovsInterface)
+	if err != nil {
+		return err
+	}
+
+	// Update the record.
+	if ovsInterface.ExternalIDs == nil {
+		ovsInterface.ExternalIDs = map[string]string{}
+	}
+
+	ovsInterface.ExternalIDs["mirror-id"] = mirrorID
+
+	operations, err := o.client.Where(ovsInterface).Update(ovsInterface)
+	if err != nil {
+		return err
+	}
+
+	resp, err := o.client.Transact(ctx, operations...)
+	if err != nil {
+		return err
+	}
+
+	_, err = ovsdb.CheckOperationResults(resp, operations)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // GetInterfaceAssociatedOVNSwitchPort returns the OVN switch port associated to the interface.
 func (o *VSwitch) GetInterfaceAssociatedOVNSwitchPort(ctx context.Context, interfaceName string) (string, error) {
 	// Get the OVS interface.
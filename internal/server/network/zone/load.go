@@ -102,6 +102,15 @@ func Create(s *state.State, projectName string, zoneInfo *api.NetworkZonesPost)
 		return err
 	}
 
+	if zoneInfo.Config == nil {
+		zoneInfo.Config = map[string]string{}
+	}
+
+	err = dnssecKeys(zoneInfo.Name, nil, zoneInfo.Config)
+	if err != nil {
+		return err
+	}
+
 	// Load the project.
 	var p *api.Project
 	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
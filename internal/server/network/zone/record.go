@@ -60,6 +60,8 @@ func (d *zone) AddRecord(req api.NetworkZoneRecordsPost) error {
 		return err
 	}
 
+	d.state.DNS.ZoneChanged(d.info.Name)
+
 	return nil
 }
 
@@ -190,6 +192,8 @@ func (d *zone) UpdateRecord(name string, req api.NetworkZoneRecordPut, clientTyp
 		return err
 	}
 
+	d.state.DNS.ZoneChanged(d.info.Name)
+
 	return nil
 }
 
@@ -226,6 +230,8 @@ func (d *zone) DeleteRecord(name string) error {
 		return err
 	}
 
+	d.state.DNS.ZoneChanged(d.info.Name)
+
 	return nil
 }
 
@@ -15,6 +15,7 @@ import (
 	"github.com/lxc/incus/v7/internal/server/cluster/request"
 	"github.com/lxc/incus/v7/internal/server/db"
 	dbCluster "github.com/lxc/incus/v7/internal/server/db/cluster"
+	"github.com/lxc/incus/v7/internal/server/dns"
 	"github.com/lxc/incus/v7/internal/server/network"
 	"github.com/lxc/incus/v7/internal/server/response"
 	"github.com/lxc/incus/v7/internal/server/state"
@@ -198,6 +199,49 @@ func (d *zone) validateConfig(info *api.NetworkZonePut) error {
 	//  shortdesc: Whether to generate records for NAT-ed subnets
 	rules["network.nat"] = validate.Optional(validate.IsBool)
 
+	// gendoc:generate(entity=network_zone, group=common, key=dns.queries)
+	// The zone peers can always query the zone.
+	// Set to `all` to allow any client, or to a comma-separated list of subnets to allow the clients from those subnets.
+	// ---
+	//  type: string
+	//  required: no
+	//  defaultdesc: `peers`
+	//  shortdesc: Clients allowed to query the records of the zone on the built-in DNS server
+	rules["dns.queries"] = func(value string) error {
+		if value == "" || value == "peers" || value == "all" {
+			return nil
+		}
+
+		return validate.IsListOf(validate.IsNetwork)(value)
+	}
+
+	// gendoc:generate(entity=network_zone, group=common, key=dnssec.enabled)
+	// The built-in DNS server then signs its answers to the queries requesting DNSSEC records.
+	// ---
+	//  type: bool
+	//  required: no
+	//  defaultdesc: `false`
+	//  shortdesc: Whether to sign the zone with DNSSEC
+	rules["dnssec.enabled"] = validate.Optional(validate.IsBool)
+
+	// gendoc:generate(entity=network_zone, group=common, key=dnssec.private_key)
+	// A new key is generated when DNSSEC is enabled if not set.
+	// ---
+	//  type: string
+	//  required: no
+	//  defaultdesc: generated
+	//  shortdesc: ECDSA P-256 private key signing the zone (base64-encoded)
+	rules["dnssec.private_key"] = validate.Optional(dns.DNSSECValidateKey)
+
+	// gendoc:generate(entity=network_zone, group=common, key=dnssec.ds)
+	// This record is derived from `dnssec.private_key` and must be added to the parent zone to establish the chain of trust.
+	// ---
+	//  type: string
+	//  required: no
+	//  defaultdesc: generated
+	//  shortdesc: DS record of the key signing the zone (read-only)
+	rules["dnssec.ds"] = validate.IsAny
+
 	// Validate peer config.
 	for k := range info.Config {
 		if !strings.HasPrefix(k, "peers.") {
@@ -285,6 +329,15 @@ func (d *zone) Update(config *api.NetworkZonePut, clientType request.ClientType)
 		return err
 	}
 
+	if config.Config == nil {
+		config.Config = map[string]string{}
+	}
+
+	err = dnssecKeys(d.info.Name, d.info.Config, config.Config)
+	if err != nil {
+		return err
+	}
+
 	reverter := revert.New()
 	defer reverter.Fail()
 
@@ -366,10 +419,45 @@ func (d *zone) Update(config *api.NetworkZonePut, clientType request.ClientType)
 		return err
 	}
 
+	d.state.DNS.ZoneChanged(d.info.Name)
+
 	reverter.Success()
 	return nil
 }
 
+// dnssecKeys fills in the DNSSEC keys of the zone.
+// The existing private key is kept unless a new one is provided, and a new one is generated when DNSSEC is enabled.
+func dnssecKeys(zoneName string, oldConfig map[string]string, config map[string]string) error {
+	if config["dnssec.private_key"] == "" {
+		config["dnssec.private_key"] = oldConfig["dnssec.private_key"]
+	}
+
+	if config["dnssec.private_key"] == "" && util.IsTrue(config["dnssec.enabled"]) {
+		privateKey, err := dns.DNSSECGenerateKey()
+		if err != nil {
+			return err
+		}
+
+		config["dnssec.private_key"] = privateKey
+	}
+
+	if config["dnssec.private_key"] == "" {
+		delete(config, "dnssec.private_key")
+		delete(config, "dnssec.ds")
+
+		return nil
+	}
+
+	ds, err := dns.DNSSECDS(zoneName, config["dnssec.private_key"])
+	if err != nil {
+		return err
+	}
+
+	config["dnssec.ds"] = ds
+
+	return nil
+}
+
 // Delete deletes the zone.
 func (d *zone) Delete() error {
 	isUsed, err := d.isUsed()
@@ -397,6 +485,8 @@ func (d *zone) Delete() error {
 		return err
 	}
 
+	d.state.DNS.ZoneChanged(d.info.Name)
+
 	return nil
 }
 
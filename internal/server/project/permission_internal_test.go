@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/lxc/incus/v7/shared/api"
 	"github.com/lxc/incus/v7/shared/idmap"
 )
 
@@ -75,3 +76,23 @@ func TestParseHostIDMapRange(t *testing.T) {
 		assert.Equal(t, idmaps, expected)
 	}
 }
+
+func TestCheckRestrictions_MirrorTarget(t *testing.T) {
+	project := api.Project{Name: "p1"}
+	project.Config = map[string]string{"restricted": "true"}
+
+	instances := []api.Instance{{Name: "c1", Type: "container"}}
+	instances[0].Devices = map[string]map[string]string{
+		"eth0": {"type": "nic", "network": "incusbr0", "mirror.target": "ids/eth1"},
+	}
+
+	// Instance NICs of the project are always allowed.
+	assert.NoError(t, checkRestrictions(project, instances, nil))
+
+	// Host interfaces are only allowed when all NICs are.
+	instances[0].Devices["eth0"]["mirror.target"] = "eth1"
+	assert.Error(t, checkRestrictions(project, instances, nil))
+
+	project.Config["restricted.devices.nic"] = "allow"
+	assert.NoError(t, checkRestrictions(project, instances, nil))
+}
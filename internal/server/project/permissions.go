@@ -763,6 +763,11 @@ func checkRestrictions(project api.Project, instances []api.Instance, profiles [
 					}
 				}
 
+				// Traffic can only be mirrored to host interfaces when all NICs are allowed.
+				if device["mirror.target"] != "" && !strings.Contains(device["mirror.target"], "/") && restrictionValue != "allow" {
+					return errors.New("Host interfaces can't be used as mirror targets in this project")
+				}
+
 				return nil
 			}
 
@@ -61,6 +61,7 @@ import (
 	"github.com/lxc/incus/v7/shared/revert"
 	"github.com/lxc/incus/v7/shared/units"
 	"github.com/lxc/incus/v7/shared/util"
+	"github.com/lxc/incus/v7/shared/validate"
 )
 
 var (
@@ -255,6 +256,136 @@ func (b *backend) GetResources() (*api.ResourcesStoragePool, error) {
 	return b.driver.GetResources()
 }
 
+// Check runs an integrity check of the storage pool and of its local qcow2 volumes, and returns the
+// problems which were found.
+func (b *backend) Check(ctx context.Context, op *operations.Operation) ([]string, error) {
+	l := b.logger.AddContext(nil)
+	l.Debug("Check started")
+	defer l.Debug("Check finished")
+
+	if b.Status() == api.StoragePoolStatusPending {
+		return nil, errors.New("The pool is in pending state")
+	}
+
+	metadata := make(map[string]any)
+	progress := func(percent int64) {
+		if op == nil {
+			return
+		}
+
+		operations.SetProgressMetadata(metadata, "check", "Checking storage pool", percent, 0, 0)
+		_ = op.UpdateMetadata(metadata)
+	}
+
+	problems, err := b.driver.Check(ctx, progress, op)
+	if err != nil && !errors.Is(err, drivers.ErrNotSupported) {
+		return nil, fmt.Errorf("Failed checking storage pool: %w", err)
+	}
+
+	supported := err == nil
+
+	// Only block volumes with their own image format have a consistency check of their own.
+	volTypeCustom := db.StoragePoolVolumeTypeCustom
+	volTypeVM := db.StoragePoolVolumeTypeVM
+
+	var dbVols []*db.StorageVolume
+
+	err = b.state.DB.Cluster.Transaction(b.state.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		dbVols, err = tx.GetStoragePoolVolumes(ctx, b.ID(), true, db.StorageVolumeFilter{Type: &volTypeCustom}, db.StorageVolumeFilter{Type: &volTypeVM})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting storage volumes: %w", err)
+	}
+
+	for _, dbVol := range dbVols {
+		if internalInstance.IsSnapshot(dbVol.Name) || dbVol.ContentType != db.StoragePoolVolumeContentTypeNameBlock || dbVol.Config["block.type"] != drivers.BlockVolumeTypeQcow2 {
+			continue
+		}
+
+		var volType drivers.VolumeType
+		switch dbVol.Type {
+		case db.StoragePoolVolumeTypeNameVM:
+			volType = drivers.VolumeTypeVM
+		case db.StoragePoolVolumeTypeNameCustom:
+			volType = drivers.VolumeTypeCustom
+		default:
+			continue
+		}
+
+		// Volumes in use by running instances are skipped, as their images may change while being checked.
+		inUse, err := b.checkVolumeInUse(dbVol, volType)
+		if err != nil {
+			return nil, fmt.Errorf("Failed checking whether volume %q in project %q is in use: %w", dbVol.Name, dbVol.Project, err)
+		}
+
+		if inUse {
+			l.Info("Skipping check of volume in use", logger.Ctx{"project": dbVol.Project, "volume": dbVol.Name})
+			continue
+		}
+
+		err = ctx.Err()
+		if err != nil {
+			return nil, err
+		}
+
+		volStorageName := project.StorageVolume(dbVol.Project, dbVol.Name)
+		vol := b.GetVolume(volType, drivers.ContentTypeBlock, volStorageName, dbVol.Config)
+
+		volProblems, err := b.driver.CheckVolume(vol, op)
+		if errors.Is(err, drivers.ErrNotSupported) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("Failed checking volume %q in project %q: %w", dbVol.Name, dbVol.Project, err)
+		}
+
+		supported = true
+
+		for _, problem := range volProblems {
+			problems = append(problems, fmt.Sprintf("Volume %q in project %q: %s", dbVol.Name, dbVol.Project, problem))
+		}
+	}
+
+	if !supported {
+		return nil, drivers.ErrNotSupported
+	}
+
+	return problems, nil
+}
+
+// checkVolumeInUse returns whether a volume is in use by a running instance.
+func (b *backend) checkVolumeInUse(dbVol *db.StorageVolume, volType drivers.VolumeType) (bool, error) {
+	if volType == drivers.VolumeTypeVM {
+		inst, err := instance.LoadByProjectAndName(b.state, dbVol.Project, dbVol.Name)
+		if err != nil {
+			return false, err
+		}
+
+		return inst.IsRunning(), nil
+	}
+
+	inUse := false
+	err := VolumeUsedByInstanceDevices(b.state, b.name, dbVol.Project, &dbVol.StorageVolume, true, func(dbInst db.InstanceArgs, project api.Project, usedByDevices []string) error {
+		inst, err := instance.Load(b.state, dbInst, project)
+		if err != nil {
+			return err
+		}
+
+		if inst.IsRunning() {
+			inUse = true
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return inUse, nil
+}
+
 // IsUsed returns whether the storage pool is used by any volumes or profiles (excluding image volumes).
 func (b *backend) IsUsed() (bool, error) {
 	usedBy, err := UsedBy(context.TODO(), b.state, b, true, true, db.StoragePoolVolumeTypeNameImage)
@@ -271,6 +402,16 @@ func (b *backend) Update(clientType request.ClientType, newDesc string, newConfi
 	l.Debug("Update started")
 	defer l.Debug("Update finished")
 
+	// The encryption master key protects the keys of the encrypted volumes so can't be changed or removed.
+	masterKey := b.db.Config[drivers.EncryptionMasterKeyConfigKey]
+	if masterKey != "" {
+		if newConfig[drivers.EncryptionMasterKeyConfigKey] == "" {
+			newConfig[drivers.EncryptionMasterKeyConfigKey] = masterKey
+		} else if newConfig[drivers.EncryptionMasterKeyConfigKey] != masterKey {
+			return fmt.Errorf("The %q setting cannot be changed", drivers.EncryptionMasterKeyConfigKey)
+		}
+	}
+
 	// Validate config.
 	err := b.driver.Validate(newConfig)
 	if err != nil {
@@ -865,6 +1006,15 @@ func (b *backend) CreateInstanceFromBackup(srcBackup backup.Info, srcData io.Rea
 		return nil, nil, err
 	}
 
+	// Fill the volume config ahead of its database record so an encrypted volume is restored using the key
+	// stored in it.
+	if volumeConfig != nil {
+		err = b.driver.FillVolumeConfig(drivers.NewVolume(b.driver, b.name, volType, contentType, volStorageName, volumeConfig, b.db.Config))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	vol := b.GetVolume(volType, contentType, volStorageName, volumeConfig)
 
 	importRevert := revert.New()
@@ -1958,6 +2108,187 @@ func (b *backend) RefreshInstance(inst instance.Instance, src instance.Instance,
 	return nil
 }
 
+// CreateInstanceFromLiveMove copies the volume of a running virtual machine and its snapshots from
+// its current storage pool onto this pool and mounts the copy, leaving the source volume in place.
+// The caller is expected to have redirected the guest writes away from the source volume beforehand.
+// The returned revert hook removes the copy and points the instance paths back to the source volume.
+func (b *backend) CreateInstanceFromLiveMove(inst instance.Instance, op *operations.Operation) (*MountInfo, revert.Hook, error) {
+	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+	l.Debug("CreateInstanceFromLiveMove started")
+	defer l.Debug("CreateInstanceFromLiveMove finished")
+
+	err := b.isStatusReady()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if inst.Type() != instancetype.VM {
+		return nil, nil, errors.New("Live storage pool moves are only supported for virtual machines")
+	}
+
+	if !slices.Contains(b.driver.Info().VolumeTypes, drivers.VolumeTypeVM) {
+		return nil, nil, fmt.Errorf("Storage pool %q doesn't support virtual machines", b.name)
+	}
+
+	// Get the source storage pool.
+	srcPool, err := LoadByInstance(b.state, inst)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	srcPoolBackend, ok := srcPool.(*backend)
+	if !ok {
+		return nil, nil, errors.New("Source pool is not a backend")
+	}
+
+	if srcPool.Name() == b.name {
+		return nil, nil, errors.New("Requested storage pool is the same as current pool")
+	}
+
+	// The guest writes can only be redirected for raw disks.
+	srcDBVol, err := VolumeDBGet(srcPool, inst.Project().Name, inst.Name(), drivers.VolumeTypeVM)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if srcDBVol.Config["block.type"] == drivers.BlockVolumeTypeQcow2 {
+		return nil, nil, errors.New("Live storage pool moves aren't supported for qcow2 volumes")
+	}
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	err = b.CreateInstanceFromCopy(inst, inst, true, true, op)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reverter.Add(func() {
+		_ = b.DetachMovedInstance(inst, op)
+		_ = b.DeleteMovedInstance(inst, op)
+
+		// Restore the instance paths.
+		srcVol := srcPoolBackend.GetVolume(drivers.VolumeTypeVM, drivers.ContentTypeBlock, project.Instance(inst.Project().Name, inst.Name()), nil)
+		_ = srcPoolBackend.ensureInstanceSymlink(inst.Type(), inst.Project().Name, inst.Name(), srcVol.MountPath())
+		if util.PathExists(drivers.GetVolumeSnapshotDir(srcPool.Name(), drivers.VolumeTypeVM, srcVol.Name())) {
+			_ = srcPoolBackend.ensureInstanceSnapshotSymlink(inst.Type(), inst.Project().Name, inst.Name())
+		}
+	})
+
+	dbVol, err := VolumeDBGet(b, inst.Project().Name, inst.Name(), drivers.VolumeTypeVM)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if dbVol.Config["block.type"] == drivers.BlockVolumeTypeQcow2 {
+		return nil, nil, errors.New("Live storage pool moves aren't supported for qcow2 volumes")
+	}
+
+	mountInfo, err := b.MountInstance(inst, op)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reverter.Add(func() { _ = b.UnmountInstance(inst, op) })
+
+	cleanup := reverter.Clone().Fail
+	reverter.Success()
+
+	return mountInfo, cleanup, nil
+}
+
+// DetachMovedInstance removes the database records of the instance volume and its snapshots from
+// this pool once the instance has been moved to another pool. The volume is left on storage.
+func (b *backend) DetachMovedInstance(inst instance.Instance, op *operations.Operation) error {
+	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+	l.Debug("DetachMovedInstance started")
+	defer l.Debug("DetachMovedInstance finished")
+
+	volType, err := InstanceTypeToVolumeType(inst.Type())
+	if err != nil {
+		return err
+	}
+
+	dbVolSnaps, err := VolumeDBSnapshotsGet(b, inst.Project().Name, inst.Name(), volType)
+	if err != nil {
+		return err
+	}
+
+	for _, dbVolSnap := range dbVolSnaps {
+		err = VolumeDBDelete(b, inst.Project().Name, dbVolSnap.Name, volType)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = VolumeDBDelete(b, inst.Project().Name, inst.Name(), volType)
+	if err != nil {
+		return err
+	}
+
+	// Record volume deletion with authorizer.
+	err = b.state.Authorizer.DeleteStoragePoolVolume(b.state.ShutdownCtx, inst.Project().Name, b.Name(), volType.Singular(), inst.Name(), "")
+	if err != nil {
+		logger.Error("Failed to remove storage volume from authorizer", logger.Ctx{"name": inst.Name(), "type": volType, "pool": b.Name(), "project": inst.Project().Name, "error": err})
+	}
+
+	return nil
+}
+
+// DeleteMovedInstance unmounts and deletes the volume and snapshots left on this pool by an
+// instance which has been moved to another pool. The instance paths and records are left untouched.
+func (b *backend) DeleteMovedInstance(inst instance.Instance, op *operations.Operation) error {
+	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+	l.Debug("DeleteMovedInstance started")
+	defer l.Debug("DeleteMovedInstance finished")
+
+	volType, err := InstanceTypeToVolumeType(inst.Type())
+	if err != nil {
+		return err
+	}
+
+	contentType := InstanceContentType(inst)
+	volStorageName := project.Instance(inst.Project().Name, inst.Name())
+
+	// There's no need to pass config as it's not needed when deleting a volume.
+	vol := b.GetVolume(volType, contentType, volStorageName, nil)
+
+	volExists, err := b.driver.HasVolume(vol)
+	if err != nil {
+		return err
+	}
+
+	if !volExists {
+		return nil
+	}
+
+	_, err = b.driver.UnmountVolume(vol, false, op)
+	if err != nil {
+		return fmt.Errorf("Failed unmounting storage volume: %w", err)
+	}
+
+	snapshots, err := b.driver.VolumeSnapshots(vol, op)
+	if err != nil {
+		return err
+	}
+
+	for _, snapName := range snapshots {
+		snapVol := b.GetVolume(volType, contentType, drivers.GetSnapshotVolumeName(volStorageName, snapName), nil)
+
+		err = b.driver.DeleteVolumeSnapshot(snapVol, op)
+		if err != nil {
+			return fmt.Errorf("Error deleting storage volume snapshot: %w", err)
+		}
+	}
+
+	err = b.driver.DeleteVolume(vol, op)
+	if err != nil {
+		return fmt.Errorf("Error deleting storage volume: %w", err)
+	}
+
+	return nil
+}
+
 // imageFiller returns a function that can be used as a filler function with CreateVolume().
 // The function returned will unpack the specified image archive into the specified mount path
 // provided, and for VM images, a raw root block path is required to unpack the qcow2 image into.
@@ -3166,6 +3497,9 @@ func (b *backend) GetInstanceUsage(inst instance.Instance) (*VolumeUsage, error)
 		val.Used = size
 	}
 
+	// Get the detailed space accounting.
+	b.fillVolumeStats(vol, &val)
+
 	// Get the total size.
 	_, rootDiskConf, err := internalInstance.GetRootDiskDevice(inst.ExpandedDevices().CloneNative())
 	if err != nil {
@@ -3191,6 +3525,25 @@ func (b *backend) GetInstanceUsage(inst instance.Instance) (*VolumeUsage, error)
 	return &val, nil
 }
 
+// fillVolumeStats adds the driver's detailed space accounting of the volume to its usage.
+// Failing to get it only gets logged, as it's not essential to the usage.
+func (b *backend) fillVolumeStats(vol drivers.Volume, usage *VolumeUsage) {
+	usage.SnapshotsUsed = -1
+
+	stats, err := b.driver.GetVolumeStats(vol)
+	if err != nil {
+		if !errors.Is(err, drivers.ErrNotSupported) {
+			b.logger.Warn("Failed getting volume stats", logger.Ctx{"volName": vol.Name(), "err": err})
+		}
+
+		return
+	}
+
+	usage.SnapshotsUsed = stats.SnapshotsUsed
+	usage.CompressionRatio = stats.CompressionRatio
+	usage.OvercommitRatio = stats.OvercommitRatio
+}
+
 // SetInstanceQuota sets the quota on the instance's root volume.
 // Returns ErrInUse if the instance is running and the storage driver doesn't support online resizing.
 func (b *backend) SetInstanceQuota(inst instance.Instance, size string, vmStateSize string, op *operations.Operation) error {
@@ -4440,6 +4793,19 @@ func (b *backend) EnsureImage(fingerprint string, op *operations.Operation) erro
 func (b *backend) shouldUseOptimizedImage(fingerprint string, contentType drivers.ContentType, volConfig map[string]string, op *operations.Operation) (bool, error) {
 	canOptimizeImage := b.driver.Info().OptimizedImages
 
+	// Encrypted volumes each have their own key so they can't be cloned from an optimized image.
+	if canOptimizeImage {
+		vol := b.GetVolume(drivers.VolumeTypeImage, contentType, fingerprint, volConfig)
+		err := b.Driver().FillVolumeConfig(vol)
+		if err != nil {
+			return false, err
+		}
+
+		if util.IsTrue(vol.Config()["block.encryption"]) {
+			return false, nil
+		}
+	}
+
 	// If the volume config is empty, the default pool configuration is used, making the driver's support
 	// for optimized images the determining factor. However, an optimized image cannot be utilized if the
 	// driver lacks support for it.
@@ -4692,6 +5058,24 @@ func (b *backend) CreateBucket(projectName string, bucket api.StorageBucketsPost
 	return nil
 }
 
+// validateBucketKeyQuota validates the quota of a bucket key, which is only enforced on local buckets.
+func validateBucketKeyQuota(quota string, local bool) error {
+	if quota == "" {
+		return nil
+	}
+
+	if !local {
+		return errors.New("Bucket key quotas are only supported on local storage pools")
+	}
+
+	err := validate.IsSize(quota)
+	if err != nil {
+		return fmt.Errorf("Invalid bucket key quota: %w", err)
+	}
+
+	return nil
+}
+
 func generateLocalBucketKey(accessKey, secretKey string) (*drivers.S3Credentials, error) {
 	const accessKeyAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	const secretKeyAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
@@ -4983,6 +5367,11 @@ func (b *backend) CreateBucketKey(projectName string, bucketName string, key api
 		return nil, err
 	}
 
+	err = validateBucketKeyQuota(key.Quota, memberSpecific)
+	if err != nil {
+		return nil, err
+	}
+
 	var newCreds *drivers.S3Credentials
 
 	if memberSpecific {
@@ -5012,6 +5401,7 @@ func (b *backend) CreateBucketKey(projectName string, bucketName string, key api
 			Role:        key.Role,
 			AccessKey:   key.AccessKey,
 			SecretKey:   key.SecretKey,
+			Quota:       key.Quota,
 		},
 	}
 
@@ -5097,6 +5487,11 @@ func (b *backend) UpdateBucketKey(projectName string, bucketName string, keyName
 		return err
 	}
 
+	err = validateBucketKeyQuota(key.Quota, memberSpecific)
+	if err != nil {
+		return err
+	}
+
 	if memberSpecific {
 		// For local buckets the key fields are stored only in the DB;
 		// generate any missing values here.
@@ -6512,6 +6907,9 @@ func (b *backend) GetCustomVolumeUsage(projectName, volName string) (*VolumeUsag
 		val.Used = size
 	}
 
+	// Get the detailed space accounting.
+	b.fillVolumeStats(vol, &val)
+
 	// Get the total size.
 	sizeStr, ok := vol.Config()["size"]
 	if ok {
@@ -7176,7 +7574,7 @@ func (b *backend) GenerateInstanceBackupConfig(inst instance.Instance, snapshots
 	}
 
 	config := &backupConfig.Config{
-		Pool:   &b.db,
+		Pool:   backupPool(b.db),
 		Volume: &volume.StorageVolume,
 	}
 
@@ -7202,8 +7600,7 @@ func (b *backend) GenerateInstanceBackupConfig(inst instance.Instance, snapshots
 				return err
 			}
 
-			poolDB := diskPool.ToAPI()
-			diskConfig.Pool = &poolDB
+			diskConfig.Pool = backupPool(diskPool.ToAPI())
 
 			config.DependentVolumes = append(config.DependentVolumes, diskConfig)
 
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"context"
 	"io"
 	"net"
 	"net/url"
@@ -82,6 +83,11 @@ func (b *mockBackend) GetResources() (*api.ResourcesStoragePool, error) {
 	return nil, nil
 }
 
+// Check runs an integrity check of the storage pool.
+func (b *mockBackend) Check(ctx context.Context, op *operations.Operation) ([]string, error) {
+	return nil, nil
+}
+
 // IsUsed returns whether the storage pool is in use.
 func (b *mockBackend) IsUsed() (bool, error) {
 	return false, nil
@@ -212,6 +218,21 @@ func (b *mockBackend) RefreshInstance(inst instance.Instance, src instance.Insta
 	return nil
 }
 
+// CreateInstanceFromLiveMove copies the volume of a running instance from its current pool.
+func (b *mockBackend) CreateInstanceFromLiveMove(inst instance.Instance, op *operations.Operation) (*MountInfo, revert.Hook, error) {
+	return nil, nil, nil
+}
+
+// DetachMovedInstance removes the database records of a moved instance volume.
+func (b *mockBackend) DetachMovedInstance(inst instance.Instance, op *operations.Operation) error {
+	return nil
+}
+
+// DeleteMovedInstance deletes the leftover volume of a moved instance.
+func (b *mockBackend) DeleteMovedInstance(inst instance.Instance, op *operations.Operation) error {
+	return nil
+}
+
 // BackupInstance creates an instance backup.
 func (b *mockBackend) BackupInstance(inst instance.Instance, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots bool, dependentVolumes bool, op *operations.Operation) error {
 	return nil
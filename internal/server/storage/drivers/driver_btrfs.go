@@ -1,13 +1,17 @@
 package drivers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/sys/unix"
 
@@ -317,6 +321,14 @@ func (d *btrfs) Validate(config map[string]string) error {
 	//  default: -
 	//  shortdesc: Path to an existing block device, loop file or Btrfs subvolume
 
+	// gendoc:generate(entity=storage_btrfs, group=common, key=check.schedule)
+	// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or leave empty to disable scheduled checks (the default).
+	// ---
+	//  type: string
+	//  scope: global
+	//  default: empty
+	//  shortdesc: Schedule for automatic integrity checks of the storage pool
+
 	// gendoc:generate(entity=storage_btrfs, group=common, key=source.wipe)
 	//
 	// ---
@@ -512,6 +524,80 @@ func (d *btrfs) GetResources() (*api.ResourcesStoragePool, error) {
 	return genericVFSGetResources(d)
 }
 
+// btrfsScrubRunning matches the status of a running scrub.
+var btrfsScrubRunning = regexp.MustCompile(`(?m)^\s*Status:\s+running`)
+
+// btrfsScrubProgress matches the completion percentage of a running scrub.
+var btrfsScrubProgress = regexp.MustCompile(`Bytes scrubbed:.*\(([0-9.]+)%\)`)
+
+// Check scrubs the filesystem of the storage pool and returns the problems which were found.
+func (d *btrfs) Check(ctx context.Context, progress func(percent int64), op *operations.Operation) ([]string, error) {
+	mountPath := GetPoolMountPath(d.name)
+
+	out, err := subprocess.RunCommand("btrfs", "scrub", "status", mountPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Wait for an already running scrub rather than failing to start a new one.
+	if !btrfsScrubRunning.MatchString(out) {
+		_, err = subprocess.RunCommand("btrfs", "scrub", "start", mountPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		out, err = subprocess.RunCommand("btrfs", "scrub", "status", mountPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if !btrfsScrubRunning.MatchString(out) {
+			break
+		}
+
+		match := btrfsScrubProgress.FindStringSubmatch(out)
+		if match != nil {
+			percent, err := strconv.ParseFloat(match[1], 64)
+			if err == nil {
+				progress(int64(percent))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			// Cancel the scrub rather than leaving it running in the background.
+			_, err := subprocess.RunCommand("btrfs", "scrub", "cancel", mountPath)
+			if err != nil {
+				d.logger.Warn("Failed cancelling scrub", logger.Ctx{"path": mountPath, "err": err})
+			}
+
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Second):
+		}
+	}
+
+	return btrfsScrubProblems(out), nil
+}
+
+// btrfsScrubProblems returns the errors reported in the status of a finished scrub.
+func btrfsScrubProblems(out string) []string {
+	for _, line := range strings.Split(out, "\n") {
+		summary, found := strings.CutPrefix(strings.TrimSpace(line), "Error summary:")
+		if !found {
+			continue
+		}
+
+		summary = strings.TrimSpace(summary)
+		if summary != "no errors found" {
+			return []string{"Scrub errors: " + summary}
+		}
+	}
+
+	return nil
+}
+
 // MigrationTypes returns the type of transfer methods to be used when doing migrations between pools in preference order.
 func (d *btrfs) MigrationTypes(contentType ContentType, refresh bool, copySnapshots bool, clusterMove bool, storageMove bool) []localMigration.Type {
 	var rsyncFeatures []string
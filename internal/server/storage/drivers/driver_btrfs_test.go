@@ -0,0 +1,43 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBtrfsScrubStatus(t *testing.T) {
+	running := `UUID:             0f1c1c4e-5b1a-4a8e-9d6e-6a0b9b1c2d3e
+Scrub started:    Sat Oct 17 10:00:00 2026
+Status:           running
+Duration:         0:00:10
+Time left:        0:00:30
+Bytes scrubbed:   1.00GiB  (25.00%)
+Rate:             102.40MiB/s
+Error summary:    no errors found
+`
+
+	assert.True(t, btrfsScrubRunning.MatchString(running))
+	assert.Equal(t, "25.00", btrfsScrubProgress.FindStringSubmatch(running)[1])
+
+	finished := `UUID:             0f1c1c4e-5b1a-4a8e-9d6e-6a0b9b1c2d3e
+Scrub started:    Sat Oct 17 10:00:00 2026
+Status:           finished
+Duration:         0:00:40
+Total to scrub:   4.00GiB
+Rate:             102.40MiB/s
+Error summary:    csum=2
+  Corrected:      1
+  Uncorrectable:  1
+  Unverified:     0
+`
+
+	assert.False(t, btrfsScrubRunning.MatchString(finished))
+	assert.Equal(t, []string{"Scrub errors: csum=2"}, btrfsScrubProblems(finished))
+
+	clean := `Status:           finished
+Error summary:    no errors found
+`
+
+	assert.Empty(t, btrfsScrubProblems(clean))
+}
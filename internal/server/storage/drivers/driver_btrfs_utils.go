@@ -14,6 +14,8 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 	"unsafe"
 
 	"github.com/google/uuid"
@@ -39,6 +41,20 @@ var (
 // btrfsISOVolSuffix suffix used for iso content type volumes.
 const btrfsISOVolSuffix = ".iso"
 
+// btrfsCompressionRatioTTL is how long compression ratios are cached, as compsize goes through every extent of the volume.
+const btrfsCompressionRatioTTL = 15 * time.Minute
+
+// btrfsCompressionRatio is a cached compression ratio.
+type btrfsCompressionRatio struct {
+	ratio   float64
+	expires time.Time
+}
+
+var (
+	btrfsCompressionRatiosMu sync.Mutex
+	btrfsCompressionRatios   = map[string]btrfsCompressionRatio{}
+)
+
 // setReceivedUUID sets the "Received UUID" field on a subvolume with the given path using ioctl.
 func setReceivedUUID(path string, UUID string) error {
 	type btrfsIoctlReceivedSubvolArgs struct {
@@ -346,6 +362,79 @@ func (d *btrfs) getQGroup(path string) (string, int64, error) {
 	return qgroup, usage, nil
 }
 
+// getCompressionRatio returns the ratio between the uncompressed and the stored size of the data under path.
+// The result is cached for btrfsCompressionRatioTTL.
+func (d *btrfs) getCompressionRatio(path string) (float64, error) {
+	btrfsCompressionRatiosMu.Lock()
+	cached, ok := btrfsCompressionRatios[path]
+	btrfsCompressionRatiosMu.Unlock()
+
+	now := time.Now()
+	if ok && now.Before(cached.expires) {
+		return cached.ratio, nil
+	}
+
+	ratio, err := d.computeCompressionRatio(path)
+	if err != nil {
+		return 0, err
+	}
+
+	btrfsCompressionRatiosMu.Lock()
+	defer btrfsCompressionRatiosMu.Unlock()
+
+	// Drop the expired entries, such as those of deleted volumes.
+	for cachedPath, cached := range btrfsCompressionRatios {
+		if now.After(cached.expires) {
+			delete(btrfsCompressionRatios, cachedPath)
+		}
+	}
+
+	btrfsCompressionRatios[path] = btrfsCompressionRatio{ratio: ratio, expires: now.Add(btrfsCompressionRatioTTL)}
+
+	return ratio, nil
+}
+
+// computeCompressionRatio runs compsize to get the compression ratio of the data under path.
+// It returns ErrNotSupported if compsize is missing.
+func (d *btrfs) computeCompressionRatio(path string) (float64, error) {
+	_, err := exec.LookPath("compsize")
+	if err != nil {
+		return 0, ErrNotSupported
+	}
+
+	output, err := subprocess.RunCommand("compsize", "--bytes", "--one-file-system", path)
+	if err != nil {
+		return 0, fmt.Errorf("Failed getting compression information for %q: %w", path, err)
+	}
+
+	// The totals line looks like "TOTAL 38% <disk usage> <uncompressed> <referenced>".
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[0] != "TOTAL" {
+			continue
+		}
+
+		diskUsage, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("Failed parsing disk usage (%q): %w", fields[2], err)
+		}
+
+		uncompressed, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("Failed parsing uncompressed size (%q): %w", fields[3], err)
+		}
+
+		if diskUsage == 0 {
+			return 0, nil
+		}
+
+		return float64(uncompressed) / float64(diskUsage), nil
+	}
+
+	// compsize doesn't print totals when there are no regular extents.
+	return 0, nil
+}
+
 func (d *btrfs) sendSubvolume(path string, parent string, conn io.ReadWriteCloser, tracker *ioprogress.ProgressTracker) error {
 	defer logger.WarnOnError(conn.Close, "Failed to close connection")
 
@@ -1143,6 +1143,83 @@ func (d *btrfs) ValidateVolume(vol Volume, removeUnknownKeys bool) error {
 	//  default: same as `volume.snapshot.schedule`
 	//  shortdesc: {{snapshot_schedule_format}}
 
+	// gendoc:generate(entity=storage_volume_btrfs, group=common, key=snapshots.retention)
+	// Snapshots not kept by any rule are deleted, including those created manually.
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: Comma-separated list of `<count> <period>` rules (periods being `latest`, `hourly`, `daily`, `weekly`, `monthly` or `yearly`), like `24 hourly, 7 daily`
+
+	// gendoc:generate(entity=storage_volume_btrfs, group=common, key=backups.expiry)
+	// Specify an expression like `1M 2H 3d 4w 5m 6y`.
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: When scheduled backups are to be deleted
+
+	// gendoc:generate(entity=storage_volume_btrfs, group=common, key=backups.retention.count)
+	// Backups created manually are never deleted.
+	// ---
+	//  type: int
+	//  condition: custom volume
+	//  default: `0` (unlimited)
+	//  shortdesc: Number of scheduled backups to keep
+
+	// gendoc:generate(entity=storage_volume_btrfs, group=common, key=backups.schedule)
+	//
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: Cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or empty to disable automatic backups (the default)
+
+	// gendoc:generate(entity=storage_volume_btrfs, group=common, key=backups.target)
+	// When set, scheduled backups are uploaded to that bucket instead of being stored on the server.
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: S3 URL (`https://<access key>:<secret key>@<host>/<bucket>[/<prefix>]`) to upload scheduled backups to
+
+	// gendoc:generate(entity=storage_volume_btrfs, group=common, key=backups.repository)
+	// When set, scheduled backups are stored in that backup repository instead of being stored on the server.
+	// Only the chunks which changed since the previous backup are stored.
+	// ---
+	//  type: string
+	//  condition: custom block volume
+	//  default: -
+	//  shortdesc: Name of the backup target to store scheduled backups in as a backup repository
+
+	// gendoc:generate(entity=storage_bucket_btrfs, group=common, key=lifecycle.abort_multipart.days)
+	//
+	// ---
+	//  type: int
+	//  default: -
+	//  shortdesc: Number of days after which incomplete multipart uploads are aborted
+
+	// gendoc:generate(entity=storage_bucket_btrfs, group=common, key=lifecycle.expiration.days)
+	//
+	// ---
+	//  type: int
+	//  default: -
+	//  shortdesc: Number of days after their last modification after which objects expire
+
+	// gendoc:generate(entity=storage_bucket_btrfs, group=common, key=lifecycle.noncurrent_expiration.days)
+	//
+	// ---
+	//  type: int
+	//  default: -
+	//  shortdesc: Number of days after which non-current object versions are removed
+
+	// gendoc:generate(entity=storage_bucket_btrfs, group=common, key=lifecycle.prefix)
+	//
+	// ---
+	//  type: string
+	//  default: -
+	//  shortdesc: Only apply the lifecycle rules to objects whose key starts with this prefix
+
 	// gendoc:generate(entity=storage_bucket_btrfs, group=common, key=size)
 	//
 	// ---
@@ -1151,6 +1228,28 @@ func (d *btrfs) ValidateVolume(vol Volume, removeUnknownKeys bool) error {
 	//  default: same as `volume.size`
 	//  shortdesc: Size/quota of the storage bucket
 
+	// gendoc:generate(entity=storage_bucket_btrfs, group=common, key=quota.objects)
+	// Writes creating objects past the quota are rejected with a `QuotaExceeded` error.
+	// ---
+	//  type: int
+	//  default: -
+	//  shortdesc: Maximum number of objects in the bucket
+
+	// gendoc:generate(entity=storage_bucket_btrfs, group=common, key=quota.size)
+	// Unlike `size`, the quota is enforced by the S3 server regardless of the storage driver.
+	// It includes object versions and incomplete multipart uploads, writes going past it are rejected with a `QuotaExceeded` error.
+	// ---
+	//  type: string
+	//  default: -
+	//  shortdesc: Maximum size of the data stored in the bucket
+
+	// gendoc:generate(entity=storage_bucket_btrfs, group=common, key=versioning)
+	// Once enabled, versioning can only be suspended, retaining the existing object versions.
+	// ---
+	//  type: string
+	//  default: -
+	//  shortdesc: Object versioning state of the bucket (`enabled` or `suspended`)
+
 	return d.validateVolume(vol, d.commonVolumeRules(), removeUnknownKeys)
 }
 
@@ -1192,6 +1291,49 @@ func (d *btrfs) GetVolumeUsage(vol Volume) (int64, error) {
 	return usage, nil
 }
 
+// GetVolumeStats returns the detailed space accounting of a volume.
+func (d *btrfs) GetVolumeStats(vol Volume) (*VolumeStats, error) {
+	stats := VolumeStats{SnapshotsUsed: -1}
+
+	// Add up the space exclusively used by each snapshot.
+	if !vol.IsSnapshot() {
+		snapshots, err := d.VolumeSnapshots(vol, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		stats.SnapshotsUsed = 0
+
+		for _, snapName := range snapshots {
+			snapPath := GetVolumeMountPath(d.name, vol.volType, GetSnapshotVolumeName(vol.name, snapName))
+
+			_, usage, err := d.getQGroup(snapPath)
+			if err != nil {
+				if errors.Is(err, errBtrfsNoQuota) || errors.Is(err, errBtrfsNoQGroup) {
+					stats.SnapshotsUsed = -1
+					break
+				}
+
+				return nil, err
+			}
+
+			stats.SnapshotsUsed += usage
+		}
+	}
+
+	// Only look for compressed extents when compression is enabled on the pool as this requires going through the whole volume.
+	if strings.Contains(d.getMountOptions(), "compress") {
+		ratio, err := d.getCompressionRatio(vol.MountPath())
+		if err != nil && !errors.Is(err, ErrNotSupported) {
+			return nil, err
+		}
+
+		stats.CompressionRatio = ratio
+	}
+
+	return &stats, nil
+}
+
 // SetVolumeQuota applies a size limit on volume.
 // Does nothing if supplied with an empty/zero size for block volumes, and for filesystem volumes removes quota.
 func (d *btrfs) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op *operations.Operation) error {
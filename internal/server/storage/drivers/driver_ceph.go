@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"os/exec"
+	"slices"
 	"strings"
 
 	"github.com/lxc/incus/v7/internal/migration"
@@ -84,6 +86,11 @@ func (d *ceph) isRemote() bool {
 	return true
 }
 
+// supportsEncryption returns true indicating this driver supports encrypted volumes.
+func (d *ceph) supportsEncryption() bool {
+	return true
+}
+
 // Info returns info about the driver and its environment.
 func (d *ceph) Info() Info {
 	return Info{
@@ -123,7 +130,7 @@ func (d *ceph) FillConfig() error {
 		d.config["ceph.osd.pg_num"] = "32"
 	}
 
-	return nil
+	return luksFillConfig(d.config)
 }
 
 // Create is called during pool creation and is effectively using an empty driver struct.
@@ -323,6 +330,14 @@ func (d *ceph) Validate(config map[string]string) error {
 	//  default: -
 	//  shortdesc: Existing OSD storage pool to use
 
+	// gendoc:generate(entity=storage_ceph, group=common, key=check.schedule)
+	// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or leave empty to disable scheduled checks (the default).
+	// ---
+	//  type: string
+	//  scope: global
+	//  default: empty
+	//  shortdesc: Schedule for automatic integrity checks of the storage pool
+
 	rules := map[string]func(value string) error{
 		// gendoc:generate(entity=storage_ceph, group=common, key=ceph.cluster_name)
 		//
@@ -415,6 +430,15 @@ func (d *ceph) Validate(config map[string]string) error {
 		"volatile.pool.pristine": validate.IsAny,
 	}
 
+	// gendoc:generate(entity=storage_ceph, group=common, key=volatile.encryption.master_key)
+	// It is generated when the storage pool is created and is required to access its encrypted volumes.
+	// ---
+	//  type: string
+	//  scope: global
+	//  default: random key
+	//  shortdesc: Base64 encoded key used to encrypt the keys of the encrypted volumes
+	maps.Copy(rules, luksPoolRules())
+
 	return d.validatePool(config, rules, d.commonVolumeRules())
 }
 
@@ -502,6 +526,60 @@ func (d *ceph) GetResources() (*api.ResourcesStoragePool, error) {
 	return &res, nil
 }
 
+// Check verifies the object maps of the RBD images of the storage pool and returns the problems which were found.
+func (d *ceph) Check(ctx context.Context, progress func(percent int64), op *operations.Operation) ([]string, error) {
+	images, err := d.rbdListPoolVolumes()
+	if err != nil {
+		return nil, err
+	}
+
+	problems := []string{}
+	for i, image := range images {
+		err = ctx.Err()
+		if err != nil {
+			return nil, err
+		}
+
+		// Images without an object map have nothing to verify.
+		info, err := d.rbdImageInfo(image)
+		if err != nil {
+			return nil, err
+		}
+
+		if !slices.Contains(info.Features, "object-map") {
+			continue
+		}
+
+		_, err = subprocess.RunCommand(
+			"rbd",
+			"--id", d.config["ceph.user.name"],
+			"--cluster", d.config["ceph.cluster_name"],
+			"--pool", d.config["ceph.osd.pool_name"],
+			"object-map",
+			"check",
+			image,
+		)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("Image %q: %v", image, err))
+			continue
+		}
+
+		// The check flags the object map as invalid when it doesn't match the image.
+		info, err = d.rbdImageInfo(image)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, flag := range info.Flags {
+			problems = append(problems, fmt.Sprintf("Image %q: %s", image, flag))
+		}
+
+		progress(int64((i + 1) * 100 / len(images)))
+	}
+
+	return problems, nil
+}
+
 // MigrationTypes returns the type of transfer methods to be used when doing migrations between pools in preference order.
 func (d *ceph) MigrationTypes(contentType ContentType, refresh bool, copySnapshots bool, clusterMove bool, storageMove bool) []localMigration.Type {
 	var rsyncFeatures []string
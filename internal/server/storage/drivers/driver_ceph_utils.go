@@ -121,6 +121,37 @@ func (d *ceph) rbdListPoolVolumes() ([]string, error) {
 	return images, nil
 }
 
+// rbdImageDetails contains the features and flags of an RBD image.
+type rbdImageDetails struct {
+	Features []string `json:"features"`
+	Flags    []string `json:"flags"`
+}
+
+// rbdImageInfo returns the features and flags of an RBD image of the OSD pool.
+func (d *ceph) rbdImageInfo(image string) (*rbdImageDetails, error) {
+	out, err := subprocess.RunCommand(
+		"rbd",
+		"--id", d.config["ceph.user.name"],
+		"--cluster", d.config["ceph.cluster_name"],
+		"--pool", d.config["ceph.osd.pool_name"],
+		"info",
+		"--format", "json",
+		image,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	info := rbdImageDetails{}
+
+	err = json.Unmarshal([]byte(out), &info)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing information of image %q: %w", image, err)
+	}
+
+	return &info, nil
+}
+
 // osdDeletePool destroys an OSD pool.
 //   - A call to osdDeletePool will destroy a pool including any storage
 //     volumes that still exist in the pool.
@@ -159,6 +190,10 @@ func (d *ceph) rbdCreateVolume(vol Volume, size string) error {
 		return err
 	}
 
+	if sizeBytes > 0 && isEncrypted(vol) {
+		sizeBytes += luksHeaderSize
+	}
+
 	cmd := []string{
 		"--id", d.config["ceph.user.name"],
 		"--cluster", d.config["ceph.cluster_name"],
@@ -210,7 +245,40 @@ func (d *ceph) rbdDeleteVolume(vol Volume) error {
 // rbdMapVolume maps a given RBD storage volume.
 // This will ensure that the RBD storage volume is accessible as a block device
 // in the /dev directory and is therefore necessary in order to mount it.
+// For encrypted volumes, the decrypted device path is returned.
 func (d *ceph) rbdMapVolume(vol Volume) (string, error) {
+	devPath, err := d.rbdMapDevice(vol)
+	if err != nil {
+		return "", err
+	}
+
+	return d.rbdContentDevPath(vol, devPath)
+}
+
+// rbdContentDevPath returns the path of the device holding the volume's content for the mapped RBD device.
+// For encrypted volumes this opens the LUKS device if needed and returns the decrypted device path.
+func (d *ceph) rbdContentDevPath(vol Volume, devPath string) (string, error) {
+	if !isEncrypted(vol) {
+		return devPath, nil
+	}
+
+	return d.luksOpen(vol, devPath)
+}
+
+// rbdFormatEncryptedVolume initializes the LUKS header of a newly created encrypted RBD volume.
+func (d *ceph) rbdFormatEncryptedVolume(vol Volume) error {
+	devPath, err := d.rbdMapDevice(vol)
+	if err != nil {
+		return err
+	}
+
+	defer logger.WarnOnError(func() error { return d.rbdUnmapVolume(vol, true) }, "Failed to unmap volume")
+
+	return d.luksFormat(vol, devPath)
+}
+
+// rbdMapDevice maps a given RBD storage volume and returns the path of the RBD device.
+func (d *ceph) rbdMapDevice(vol Volume) (string, error) {
 	rbdName := d.getRBDVolumeName(vol, "", false)
 	devPath, err := subprocess.RunCommand(
 		"rbd",
@@ -238,6 +306,14 @@ func (d *ceph) rbdMapVolume(vol Volume) (string, error) {
 // rbdUnmapVolume unmaps a given RBD storage volume.
 // This is a precondition in order to delete an RBD storage volume can.
 func (d *ceph) rbdUnmapVolume(vol Volume, unmapUntilEINVAL bool) error {
+	// Close the decrypted device first as it holds the RBD device open.
+	if isEncrypted(vol) {
+		err := luksClose(vol)
+		if err != nil {
+			return err
+		}
+	}
+
 	busyCount := 0
 	rbdVol := d.getRBDVolumeName(vol, "", false)
 
@@ -1265,11 +1341,13 @@ func (d *ceph) getRBDMappedDevPath(vol Volume, mapIfMissing bool) (bool, string,
 		if vol.IsSnapshot() {
 			// Volume is a snapshot, check device's snapshot name matches the volume's snapshot name.
 			if len(rbdNameParts) == 2 && rbdNameParts[1] == devSnapName {
-				return false, fmt.Sprintf("/dev/rbd%d", idx), nil // We found a match.
+				devPath, err := d.rbdContentDevPath(vol, fmt.Sprintf("/dev/rbd%d", idx))
+				return false, devPath, err // We found a match.
 			}
 		} else if slices.Contains([]string{"-", ""}, devSnapName) {
 			// Volume is not a snapshot and neither is this device.
-			return false, fmt.Sprintf("/dev/rbd%d", idx), nil // We found a match.
+			devPath, err := d.rbdContentDevPath(vol, fmt.Sprintf("/dev/rbd%d", idx))
+			return false, devPath, err // We found a match.
 		}
 
 		continue
@@ -1445,7 +1523,12 @@ func (d *ceph) receiveVolume(volumeName string, conn io.ReadWriteCloser, writeWr
 }
 
 // resizeVolume resizes an RBD volume. This function does not resize any filesystem inside the RBD volume.
+// For encrypted volumes, the size is that of the decrypted device.
 func (d *ceph) resizeVolume(vol Volume, sizeBytes int64, allowShrink bool) error {
+	if isEncrypted(vol) {
+		sizeBytes += luksHeaderSize
+	}
+
 	args := []string{
 		"resize",
 	}
@@ -1465,6 +1548,13 @@ func (d *ceph) resizeVolume(vol Volume, sizeBytes int64, allowShrink bool) error
 
 	// Resize the block device.
 	_, err := subprocess.TryRunCommand("rbd", args...)
+	if err != nil {
+		return err
+	}
 
-	return err
+	if isEncrypted(vol) {
+		return d.luksResize(vol)
+	}
+
+	return nil
 }
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"maps"
 	"net/http"
 	"os"
 	"os/exec"
@@ -148,6 +149,13 @@ func (d *ceph) CreateVolume(vol Volume, filler *VolumeFiller, op *operations.Ope
 
 	reverter.Add(func() { _ = d.DeleteVolume(vol, op) })
 
+	if isEncrypted(vol) {
+		err = d.rbdFormatEncryptedVolume(vol)
+		if err != nil {
+			return err
+		}
+	}
+
 	devPath, err := d.rbdMapVolume(vol)
 	if err != nil {
 		return err
@@ -644,13 +652,26 @@ func (d *ceph) CreateVolumeFromMigration(vol Volume, conn io.ReadWriteCloser, vo
 	}
 
 	// Map the RBD volume.
-	devPath, err := d.rbdMapVolume(vol)
+	rbdDevPath, err := d.rbdMapDevice(vol)
 	if err != nil {
 		return err
 	}
 
 	defer logger.WarnOnError(func() error { return d.rbdUnmapVolume(vol, true) }, "Failed to unmap volume")
 
+	// The received volume keeps its LUKS header, so check that its key unlocks it.
+	if isEncrypted(vol) {
+		err = d.luksCheckKey(vol, rbdDevPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	devPath, err := d.rbdContentDevPath(vol, rbdDevPath)
+	if err != nil {
+		return err
+	}
+
 	// Re-generate the UUID.
 	err = d.generateUUID(vol.ConfigBlockFilesystem(), devPath)
 	if err != nil {
@@ -902,12 +923,12 @@ func (d *ceph) FillVolumeConfig(vol Volume) error {
 		}
 	}
 
-	return nil
+	return d.luksFillVolumeConfig(vol)
 }
 
 // commonVolumeRules returns validation rules which are common for pool and volume.
 func (d *ceph) commonVolumeRules() map[string]func(value string) error {
-	return map[string]func(value string) error{
+	rules := map[string]func(value string) error{
 		// gendoc:generate(entity=storage_volume_ceph, group=common, key=block.filesystem)
 		//
 		// ---
@@ -934,7 +955,27 @@ func (d *ceph) commonVolumeRules() map[string]func(value string) error {
 		//  default: same as `volume.block.create_options`
 		//  shortdesc: Additional options to pass to the file system creation tool when formatting the volume
 		"block.create_options": validate.IsAny,
+
+		// gendoc:generate(entity=storage_volume_ceph, group=common, key=block.encryption)
+		// Unless `block.encryption.key_file` is set, a random key is generated and stored in the volume configuration, encrypted with the storage pool master key.
+		// ---
+		//  type: bool
+		//  condition: -
+		//  default: same as `volume.block.encryption` or `false`
+		//  shortdesc: Whether to encrypt the volume using LUKS (cannot be changed after creation)
+
+		// gendoc:generate(entity=storage_volume_ceph, group=common, key=block.encryption.key_file)
+		// The file must be placed in the `encryption-keys` directory of the server by the server administrator, on every server using the volume.
+		// ---
+		//  type: string
+		//  condition: encrypted volume
+		//  default: same as `volume.block.encryption.key_file`
+		//  shortdesc: Name of the file containing the encryption key of the volume
 	}
+
+	maps.Copy(rules, luksVolumeRules())
+
+	return rules
 }
 
 // ValidateVolume validates the supplied volume config.
@@ -1027,6 +1068,55 @@ func (d *ceph) ValidateVolume(vol Volume, removeUnknownKeys bool) error {
 	//  default: same as `volume.snapshot.schedule`
 	//  shortdesc: {{snapshot_schedule_format}}
 
+	// gendoc:generate(entity=storage_volume_ceph, group=common, key=snapshots.retention)
+	// Snapshots not kept by any rule are deleted, including those created manually.
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: Comma-separated list of `<count> <period>` rules (periods being `latest`, `hourly`, `daily`, `weekly`, `monthly` or `yearly`), like `24 hourly, 7 daily`
+
+	// gendoc:generate(entity=storage_volume_ceph, group=common, key=backups.expiry)
+	// Specify an expression like `1M 2H 3d 4w 5m 6y`.
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: When scheduled backups are to be deleted
+
+	// gendoc:generate(entity=storage_volume_ceph, group=common, key=backups.retention.count)
+	// Backups created manually are never deleted.
+	// ---
+	//  type: int
+	//  condition: custom volume
+	//  default: `0` (unlimited)
+	//  shortdesc: Number of scheduled backups to keep
+
+	// gendoc:generate(entity=storage_volume_ceph, group=common, key=backups.schedule)
+	//
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: Cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or empty to disable automatic backups (the default)
+
+	// gendoc:generate(entity=storage_volume_ceph, group=common, key=backups.target)
+	// When set, scheduled backups are uploaded to that bucket instead of being stored on the server.
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: S3 URL (`https://<access key>:<secret key>@<host>/<bucket>[/<prefix>]`) to upload scheduled backups to
+
+	// gendoc:generate(entity=storage_volume_ceph, group=common, key=backups.repository)
+	// When set, scheduled backups are stored in that backup repository instead of being stored on the server.
+	// Only the chunks which changed since the previous backup are stored.
+	// ---
+	//  type: string
+	//  condition: custom block volume
+	//  default: -
+	//  shortdesc: Name of the backup target to store scheduled backups in as a backup repository
+
 	commonRules := d.commonVolumeRules()
 
 	// Disallow block.* settings for regular custom block volumes. These settings only make sense
@@ -1051,6 +1141,11 @@ func (d *ceph) UpdateVolume(vol Volume, changedConfig map[string]string) error {
 		}
 	}
 
+	err := luksCheckConfigChange(changedConfig)
+	if err != nil {
+		return err
+	}
+
 	return d.updateVolume(vol, changedConfig)
 }
 
@@ -1596,7 +1691,7 @@ func (d *ceph) MigrateVolume(vol Volume, conn io.ReadWriteCloser, volSrcArgs *lo
 		parentName, snapOnlyName, _ := api.GetParentAndSnapshotName(vol.name)
 		sendName := fmt.Sprintf("%s/snapshots_%s_%s_start_clone", d.name, parentName, snapOnlyName)
 
-		cloneVol := NewVolume(d, d.name, vol.volType, vol.contentType, vol.name, nil, nil)
+		cloneVol := NewVolume(d, d.name, vol.volType, vol.contentType, vol.name, vol.config, nil)
 
 		// Mounting the volume snapshot will create the clone "snapshots_<parent>_<snap>_start_clone".
 		err := d.MountVolumeSnapshot(cloneVol, op)
@@ -1824,7 +1919,7 @@ func (d *ceph) MountVolumeSnapshot(snapVol Volume, op *operations.Operation) err
 
 		// Clone snapshot.
 		cloneName := fmt.Sprintf("%s_%s_start_clone", parentName, snapshotOnlyName)
-		cloneVol := NewVolume(d, d.name, VolumeType("snapshots"), ContentTypeFS, cloneName, nil, nil)
+		cloneVol := NewVolume(d, d.name, VolumeType("snapshots"), ContentTypeFS, cloneName, snapVol.config, nil)
 
 		err = d.rbdCreateClone(parentVol, prefixedSnapOnlyName, cloneVol)
 		if err != nil {
@@ -1914,7 +2009,7 @@ func (d *ceph) UnmountVolumeSnapshot(snapVol Volume, op *operations.Operation) (
 
 		parentName, snapshotOnlyName, _ := api.GetParentAndSnapshotName(snapVol.name)
 		cloneName := fmt.Sprintf("%s_%s_start_clone", parentName, snapshotOnlyName)
-		cloneVol := NewVolume(d, d.name, VolumeType("snapshots"), ContentTypeFS, cloneName, nil, nil)
+		cloneVol := NewVolume(d, d.name, VolumeType("snapshots"), ContentTypeFS, cloneName, snapVol.config, nil)
 
 		err = d.rbdUnmapVolume(cloneVol, true)
 		if err != nil {
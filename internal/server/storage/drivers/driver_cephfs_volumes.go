@@ -375,6 +375,46 @@ func (d *cephfs) ValidateVolume(vol Volume, removeUnknownKeys bool) error {
 	//  default: same as `volume.snapshot.schedule`
 	//  shortdesc: {{snapshot_schedule_format}}
 
+	// gendoc:generate(entity=storage_volume_cephfs, group=common, key=snapshots.retention)
+	// Snapshots not kept by any rule are deleted, including those created manually.
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: Comma-separated list of `<count> <period>` rules (periods being `latest`, `hourly`, `daily`, `weekly`, `monthly` or `yearly`), like `24 hourly, 7 daily`
+
+	// gendoc:generate(entity=storage_volume_cephfs, group=common, key=backups.expiry)
+	// Specify an expression like `1M 2H 3d 4w 5m 6y`.
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: When scheduled backups are to be deleted
+
+	// gendoc:generate(entity=storage_volume_cephfs, group=common, key=backups.retention.count)
+	// Backups created manually are never deleted.
+	// ---
+	//  type: int
+	//  condition: custom volume
+	//  default: `0` (unlimited)
+	//  shortdesc: Number of scheduled backups to keep
+
+	// gendoc:generate(entity=storage_volume_cephfs, group=common, key=backups.schedule)
+	//
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: Cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or empty to disable automatic backups (the default)
+
+	// gendoc:generate(entity=storage_volume_cephfs, group=common, key=backups.target)
+	// When set, scheduled backups are uploaded to that bucket instead of being stored on the server.
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: S3 URL (`https://<access key>:<secret key>@<host>/<bucket>[/<prefix>]`) to upload scheduled backups to
+
 	return d.validateVolume(vol, nil, removeUnknownKeys)
 }
 
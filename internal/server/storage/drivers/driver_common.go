@@ -1,6 +1,7 @@
 package drivers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -23,6 +24,7 @@ import (
 	"github.com/lxc/incus/v7/shared/revert"
 	"github.com/lxc/incus/v7/shared/subprocess"
 	"github.com/lxc/incus/v7/shared/util"
+	"github.com/lxc/incus/v7/shared/validate"
 )
 
 type common struct {
@@ -49,6 +51,11 @@ func (d *common) isRemote() bool {
 	return false
 }
 
+// supportsEncryption returns false indicating this driver does not support encrypted volumes.
+func (d *common) supportsEncryption() bool {
+	return false
+}
+
 // validatePool validates a pool config against common rules and optional driver specific rules.
 func (d *common) validatePool(config map[string]string, driverRules map[string]func(value string) error, volumeRules map[string]func(value string) error, skip ...string) error {
 	checkedFields := map[string]struct{}{}
@@ -94,6 +101,10 @@ key:
 			}
 		}
 
+		if strings.HasPrefix(k, "volume.block.encryption") {
+			return errLUKSNotSupported
+		}
+
 		return fmt.Errorf("Invalid option %q", k)
 	}
 
@@ -156,6 +167,11 @@ func (d *common) validateVolume(vol Volume, driverRules map[string]func(value st
 	// Get rules common for all drivers.
 	rules := d.commonRules.VolumeRules(vol)
 
+	// Buckets on local pools are served by the built-in S3 server which handles versioning and lifecycle rules.
+	if vol.volType == VolumeTypeBucket && vol.driver != nil && !vol.driver.Info().Remote {
+		maps.Copy(rules, localBucketRules())
+	}
+
 	// Merge driver specific rules into common rules.
 	maps.Copy(rules, driverRules)
 
@@ -188,6 +204,10 @@ key:
 			}
 		}
 
+		if !removeUnknownKeys && strings.HasPrefix(k, "block.encryption") {
+			return errLUKSNotSupported
+		}
+
 		if !removeUnknownKeys {
 			return fmt.Errorf("Invalid option for volume %q option %q", vol.name, k)
 		}
@@ -222,6 +242,11 @@ func (d *common) updateVolume(vol Volume, changedConfig map[string]string) error
 		return errors.New("dependent cannot be changed")
 	}
 
+	versioning, changed := changedConfig["versioning"]
+	if changed && vol.volType == VolumeTypeBucket && versioning == "" && vol.config["versioning"] != "" {
+		return errors.New("Bucket versioning can only be suspended once enabled")
+	}
+
 	return nil
 }
 
@@ -343,6 +368,16 @@ func (d *common) moveGPTAltHeader(devPath string) error {
 	return err
 }
 
+// Check runs an integrity check of the storage pool.
+func (d *common) Check(ctx context.Context, progress func(percent int64), op *operations.Operation) ([]string, error) {
+	return nil, ErrNotSupported
+}
+
+// CheckVolume runs an integrity check of a storage volume.
+func (d *common) CheckVolume(vol Volume, op *operations.Operation) ([]string, error) {
+	return nil, ErrNotSupported
+}
+
 // CreateVolume creates a new storage volume on disk.
 func (d *common) CreateVolume(vol Volume, filler *VolumeFiller, op *operations.Operation) error {
 	return ErrNotSupported
@@ -393,6 +428,11 @@ func (d *common) GetVolumeUsage(vol Volume) (int64, error) {
 	return -1, ErrNotSupported
 }
 
+// GetVolumeStats returns the detailed space accounting of a volume.
+func (d *common) GetVolumeStats(vol Volume) (*VolumeStats, error) {
+	return nil, ErrNotSupported
+}
+
 // SetVolumeQuota applies a size limit on volume.
 func (d *common) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op *operations.Operation) error {
 	return ErrNotSupported
@@ -484,6 +524,19 @@ func (d *common) RenameVolumeSnapshot(snapVol Volume, newSnapshotName string, op
 	return ErrNotSupported
 }
 
+// localBucketRules returns validation rules for buckets served by the built-in S3 server.
+func localBucketRules() map[string]func(value string) error {
+	return map[string]func(value string) error{
+		"versioning":                           validate.Optional(validate.IsOneOf("enabled", "suspended")),
+		"lifecycle.prefix":                     validate.IsAny,
+		"lifecycle.expiration.days":            validate.Optional(validate.IsUint32),
+		"lifecycle.noncurrent_expiration.days": validate.Optional(validate.IsUint32),
+		"lifecycle.abort_multipart.days":       validate.Optional(validate.IsUint32),
+		"quota.size":                           validate.Optional(validate.IsSize),
+		"quota.objects":                        validate.Optional(validate.IsUint32),
+	}
+}
+
 // ValidateBucket validates the supplied bucket name.
 func (d *common) ValidateBucket(bucket Volume) error {
 	projectName, bucketName := project.StorageVolumeParts(bucket.name)
@@ -23,6 +23,11 @@ func (d *dir) load() error {
 	return nil
 }
 
+// supportsEncryption returns true indicating this driver supports encrypted volumes.
+func (d *dir) supportsEncryption() bool {
+	return true
+}
+
 // Info returns info about the driver and its environment.
 func (d *dir) Info() Info {
 	return Info{
@@ -51,7 +56,7 @@ func (d *dir) FillConfig() error {
 		d.config["source"] = GetPoolMountPath(d.name)
 	}
 
-	return nil
+	return luksFillConfig(d.config)
 }
 
 // Create is called during pool creation and is effectively using an empty driver struct.
@@ -131,7 +136,15 @@ func (d *dir) Validate(config map[string]string) error {
 	//  default: -
 	//  shortdesc: Path to an existing directory
 
-	return d.validatePool(config, nil, nil)
+	// gendoc:generate(entity=storage_dir, group=common, key=volatile.encryption.master_key)
+	// It is generated when the storage pool is created and is required to access its encrypted volumes.
+	// ---
+	//  type: string
+	//  scope: global
+	//  default: random key
+	//  shortdesc: Base64 encoded key used to encrypt the keys of the encrypted volumes
+
+	return d.validatePool(config, luksPoolRules(), luksVolumeRules())
 }
 
 // Update applies any driver changes required from a configuration change.
@@ -44,7 +44,15 @@ func (d *dir) CreateVolume(vol Volume, filler *VolumeFiller, op *operations.Oper
 
 	// Get path to disk volume if volume is block or iso.
 	rootBlockPath := ""
-	if IsContentBlock(vol.contentType) {
+	if isEncryptedBlockFile(vol) {
+		// We expect the filler to copy the VM image into the opened encrypted volume.
+		rootBlockPath, err = d.createEncryptedBlockFile(vol)
+		if err != nil {
+			return err
+		}
+
+		defer logger.WarnOnError(func() error { return luksClose(vol) }, "Failed to close encrypted volume")
+	} else if IsContentBlock(vol.contentType) {
 		// We expect the filler to copy the VM image into this path.
 		rootBlockPath, err = d.GetVolumeDiskPath(vol)
 		if err != nil {
@@ -73,7 +81,15 @@ func (d *dir) CreateVolume(vol Volume, filler *VolumeFiller, op *operations.Oper
 	// If we are creating a block volume, resize it to the requested size or the default.
 	// For block volumes, we expect the filler function to have converted the qcow2 image to raw into the rootBlockPath.
 	// For ISOs the content will just be copied.
-	if IsContentBlock(vol.contentType) {
+	if isEncryptedBlockFile(vol) {
+		// The encrypted volume was created with the requested size and is only grown by the filler if needed.
+		if vol.IsVMBlock() && filler != nil && filler.Fill != nil {
+			err = d.moveGPTAltHeader(rootBlockPath)
+			if err != nil {
+				return err
+			}
+		}
+	} else if IsContentBlock(vol.contentType) {
 		// Convert to bytes.
 		sizeBytes, err := units.ParseByteSizeString(vol.ConfigSize())
 		if err != nil {
@@ -205,6 +221,11 @@ func (d *dir) DeleteVolume(vol Volume, op *operations.Operation) error {
 		return nil
 	}
 
+	err = luksClose(vol)
+	if err != nil {
+		return err
+	}
+
 	// Get the volume ID for the volume, which is used to remove project quota.
 	if vol.Type() != VolumeTypeBucket {
 		volID, err := d.getVolID(vol.volType, vol.name)
@@ -244,7 +265,13 @@ func (d *dir) HasVolume(vol Volume) (bool, error) {
 func (d *dir) FillVolumeConfig(vol Volume) error {
 	initialSize := vol.config["size"]
 
-	err := d.fillVolumeConfig(&vol)
+	// Only block volumes can be encrypted.
+	var excludedKeys []string
+	if vol.contentType != ContentTypeBlock {
+		excludedKeys = []string{"block.encryption", "block.encryption.key_file"}
+	}
+
+	err := d.fillVolumeConfig(&vol, excludedKeys...)
 	if err != nil {
 		return err
 	}
@@ -255,7 +282,7 @@ func (d *dir) FillVolumeConfig(vol Volume) error {
 		delete(vol.config, "size")
 	}
 
-	return nil
+	return d.luksFillVolumeConfig(vol)
 }
 
 // ValidateVolume validates the supplied volume config. Optionally removes invalid keys from the volume's config.
@@ -348,11 +375,130 @@ func (d *dir) ValidateVolume(vol Volume, removeUnknownKeys bool) error {
 	//  default: same as `volume.snapshot.schedule`
 	//  shortdesc: {{snapshot_schedule_format}}
 
-	err := d.validateVolume(vol, nil, removeUnknownKeys)
+	// gendoc:generate(entity=storage_volume_dir, group=common, key=snapshots.retention)
+	// Snapshots not kept by any rule are deleted, including those created manually.
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: Comma-separated list of `<count> <period>` rules (periods being `latest`, `hourly`, `daily`, `weekly`, `monthly` or `yearly`), like `24 hourly, 7 daily`
+
+	// gendoc:generate(entity=storage_volume_dir, group=common, key=backups.expiry)
+	// Specify an expression like `1M 2H 3d 4w 5m 6y`.
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: When scheduled backups are to be deleted
+
+	// gendoc:generate(entity=storage_volume_dir, group=common, key=backups.retention.count)
+	// Backups created manually are never deleted.
+	// ---
+	//  type: int
+	//  condition: custom volume
+	//  default: `0` (unlimited)
+	//  shortdesc: Number of scheduled backups to keep
+
+	// gendoc:generate(entity=storage_volume_dir, group=common, key=backups.schedule)
+	//
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: Cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or empty to disable automatic backups (the default)
+
+	// gendoc:generate(entity=storage_volume_dir, group=common, key=backups.target)
+	// When set, scheduled backups are uploaded to that bucket instead of being stored on the server.
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: S3 URL (`https://<access key>:<secret key>@<host>/<bucket>[/<prefix>]`) to upload scheduled backups to
+
+	// gendoc:generate(entity=storage_volume_dir, group=common, key=backups.repository)
+	// When set, scheduled backups are stored in that backup repository instead of being stored on the server.
+	// Only the chunks which changed since the previous backup are stored.
+	// ---
+	//  type: string
+	//  condition: custom block volume
+	//  default: -
+	//  shortdesc: Name of the backup target to store scheduled backups in as a backup repository
+
+	// gendoc:generate(entity=storage_bucket_dir, group=common, key=lifecycle.abort_multipart.days)
+	//
+	// ---
+	//  type: int
+	//  default: -
+	//  shortdesc: Number of days after which incomplete multipart uploads are aborted
+
+	// gendoc:generate(entity=storage_bucket_dir, group=common, key=lifecycle.expiration.days)
+	//
+	// ---
+	//  type: int
+	//  default: -
+	//  shortdesc: Number of days after their last modification after which objects expire
+
+	// gendoc:generate(entity=storage_bucket_dir, group=common, key=lifecycle.noncurrent_expiration.days)
+	//
+	// ---
+	//  type: int
+	//  default: -
+	//  shortdesc: Number of days after which non-current object versions are removed
+
+	// gendoc:generate(entity=storage_bucket_dir, group=common, key=lifecycle.prefix)
+	//
+	// ---
+	//  type: string
+	//  default: -
+	//  shortdesc: Only apply the lifecycle rules to objects whose key starts with this prefix
+
+	// gendoc:generate(entity=storage_bucket_dir, group=common, key=quota.objects)
+	// Writes creating objects past the quota are rejected with a `QuotaExceeded` error.
+	// ---
+	//  type: int
+	//  default: -
+	//  shortdesc: Maximum number of objects in the bucket
+
+	// gendoc:generate(entity=storage_bucket_dir, group=common, key=quota.size)
+	// The quota is enforced by the S3 server and includes object versions and incomplete multipart uploads.
+	// Writes going past it are rejected with a `QuotaExceeded` error.
+	// ---
+	//  type: string
+	//  default: -
+	//  shortdesc: Maximum size of the data stored in the bucket
+
+	// gendoc:generate(entity=storage_bucket_dir, group=common, key=versioning)
+	// Once enabled, versioning can only be suspended, retaining the existing object versions.
+	// ---
+	//  type: string
+	//  default: -
+	//  shortdesc: Object versioning state of the bucket (`enabled` or `suspended`)
+
+	// gendoc:generate(entity=storage_volume_dir, group=common, key=block.encryption)
+	// Unless `block.encryption.key_file` is set, a random key is generated and stored in the volume configuration, encrypted with the storage pool master key.
+	// ---
+	//  type: bool
+	//  condition: block-based volume
+	//  default: same as `volume.block.encryption` or `false`
+	//  shortdesc: Whether to encrypt the volume using LUKS (cannot be changed after creation)
+
+	// gendoc:generate(entity=storage_volume_dir, group=common, key=block.encryption.key_file)
+	// The file must be placed in the `encryption-keys` directory of the server by the server administrator.
+	// ---
+	//  type: string
+	//  condition: encrypted volume
+	//  default: same as `volume.block.encryption.key_file`
+	//  shortdesc: Name of the file containing the encryption key of the volume
+
+	err := d.validateVolume(vol, luksVolumeRules(), removeUnknownKeys)
 	if err != nil {
 		return err
 	}
 
+	if isEncrypted(vol) && vol.contentType != ContentTypeBlock && vol.volType != VolumeTypeVM {
+		return errors.New("Volume encryption is only supported on block volumes")
+	}
+
 	if vol.config["size"] != "" && vol.volType == VolumeTypeBucket {
 		return errors.New("Size cannot be specified for buckets")
 	}
@@ -362,6 +508,11 @@ func (d *dir) ValidateVolume(vol Volume, removeUnknownKeys bool) error {
 
 // UpdateVolume applies config changes to the volume.
 func (d *dir) UpdateVolume(vol Volume, changedConfig map[string]string) error {
+	err := luksCheckConfigChange(changedConfig)
+	if err != nil {
+		return err
+	}
+
 	newSize, sizeChanged := changedConfig["size"]
 	if sizeChanged {
 		err := d.SetVolumeQuota(vol, newSize, false, nil)
@@ -419,6 +570,10 @@ func (d *dir) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op
 			return nil
 		}
 
+		if isEncryptedBlockFile(vol) {
+			return d.setEncryptedVolumeQuota(vol, sizeBytes, allowUnsafeResize)
+		}
+
 		rootBlockPath, err := d.GetVolumeDiskPath(vol)
 		if err != nil {
 			return err
@@ -468,8 +623,14 @@ func (d *dir) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op
 }
 
 // GetVolumeDiskPath returns the location of a disk volume.
+// Encrypted volumes are opened and the path of the decrypted device is returned.
 func (d *dir) GetVolumeDiskPath(vol Volume) (string, error) {
-	return genericVFSGetVolumeDiskPath(vol)
+	diskPath, err := genericVFSGetVolumeDiskPath(vol)
+	if err != nil || !isEncryptedBlockFile(vol) {
+		return diskPath, err
+	}
+
+	return d.luksOpen(vol, diskPath)
 }
 
 // ListVolumes returns a list of volumes in storage pool.
@@ -515,6 +676,13 @@ func (d *dir) UnmountVolume(vol Volume, keepBlockDev bool, op *operations.Operat
 		return false, ErrInUse
 	}
 
+	if !keepBlockDev {
+		err = luksClose(vol)
+		if err != nil {
+			return false, err
+		}
+	}
+
 	return false, nil
 }
 
@@ -569,13 +737,14 @@ func (d *dir) CreateVolumeSnapshot(snapVol Volume, op *operations.Operation) err
 	}
 
 	if snapVol.IsVMBlock() || (snapVol.contentType == ContentTypeBlock && snapVol.volType == VolumeTypeCustom) {
+		// Encrypted volumes are copied as is, the snapshot sharing the key of its parent.
 		parentVol := NewVolume(d, d.name, snapVol.volType, snapVol.contentType, parentName, nil, d.config)
-		srcDevPath, err := d.GetVolumeDiskPath(parentVol)
+		srcDevPath, err := genericVFSGetVolumeDiskPath(parentVol)
 		if err != nil {
 			return err
 		}
 
-		targetDevPath, err := d.GetVolumeDiskPath(snapVol)
+		targetDevPath, err := genericVFSGetVolumeDiskPath(snapVol)
 		if err != nil {
 			return err
 		}
@@ -602,8 +771,13 @@ func (d *dir) CreateVolumeSnapshot(snapVol Volume, op *operations.Operation) err
 func (d *dir) DeleteVolumeSnapshot(snapVol Volume, op *operations.Operation) error {
 	snapPath := snapVol.MountPath()
 
+	err := luksClose(snapVol)
+	if err != nil {
+		return err
+	}
+
 	// Remove the snapshot from the storage device.
-	err := forceRemoveAll(snapPath)
+	err = forceRemoveAll(snapPath)
 	if err != nil && !errors.Is(err, fs.ErrNotExist) {
 		return fmt.Errorf("Failed to remove '%s': %w", snapPath, err)
 	}
@@ -667,6 +841,11 @@ func (d *dir) UnmountVolumeSnapshot(snapVol Volume, op *operations.Operation) (b
 			return false, ErrInUse
 		}
 
+		err = luksClose(snapVol)
+		if err != nil {
+			return false, err
+		}
+
 		snapPath := snapVol.MountPath()
 		return forceUnmount(snapPath)
 	}
@@ -710,12 +889,18 @@ func (d *dir) RestoreVolume(vol Volume, snapshotName string, op *operations.Oper
 
 	// Restore block volume.
 	if vol.IsVMBlock() || (vol.contentType == ContentTypeBlock && vol.volType == VolumeTypeCustom) {
-		srcDevPath, err := d.GetVolumeDiskPath(snapVol)
+		// Encrypted volumes are copied as is, the snapshot sharing the key of its parent.
+		err = luksClose(vol)
+		if err != nil {
+			return err
+		}
+
+		srcDevPath, err := genericVFSGetVolumeDiskPath(snapVol)
 		if err != nil {
 			return err
 		}
 
-		targetDevPath, err := d.GetVolumeDiskPath(vol)
+		targetDevPath, err := genericVFSGetVolumeDiskPath(vol)
 		if err != nil {
 			return err
 		}
@@ -756,6 +941,81 @@ func (d *dir) ActivateTask(vol Volume, task func(devPath string, op *operations.
 		return err
 	}
 
+	if isEncryptedBlockFile(vol) && !vol.MountInUse() {
+		defer logger.WarnOnError(func() error { return luksClose(vol) }, "Failed to close encrypted volume")
+	}
+
 	// Run the task.
 	return task(volDevPath, op)
 }
+
+// isEncryptedBlockFile returns true if the volume is a block volume stored in an encrypted file.
+func isEncryptedBlockFile(vol Volume) bool {
+	return vol.contentType == ContentTypeBlock && isEncrypted(vol)
+}
+
+// createEncryptedBlockFile creates the LUKS formatted file of an encrypted block volume and opens it.
+func (d *dir) createEncryptedBlockFile(vol Volume) (string, error) {
+	sizeBytes, err := units.ParseByteSizeString(vol.ConfigSize())
+	if err != nil {
+		return "", err
+	}
+
+	diskPath, err := genericVFSGetVolumeDiskPath(vol)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = ensureVolumeBlockFile(vol, diskPath, sizeBytes+luksHeaderSize, false)
+	if err != nil {
+		return "", err
+	}
+
+	err = d.luksFormat(vol, diskPath)
+	if err != nil {
+		return "", err
+	}
+
+	return d.luksOpen(vol, diskPath)
+}
+
+// setEncryptedVolumeQuota resizes the file of an encrypted block volume.
+func (d *dir) setEncryptedVolumeQuota(vol Volume, sizeBytes int64, allowUnsafeResize bool) error {
+	diskPath, err := genericVFSGetVolumeDiskPath(vol)
+	if err != nil {
+		return err
+	}
+
+	resized, err := ensureVolumeBlockFile(vol, diskPath, sizeBytes+luksHeaderSize, allowUnsafeResize)
+	if err != nil || !resized {
+		return err
+	}
+
+	// The opened volume doesn't follow the size of its file, so re-open it.
+	wasOpen := util.PathExists(luksDevicePath(vol))
+
+	err = luksClose(vol)
+	if err != nil {
+		return err
+	}
+
+	devPath, err := d.luksOpen(vol, diskPath)
+	if err != nil {
+		return err
+	}
+
+	if !wasOpen {
+		defer logger.WarnOnError(func() error { return luksClose(vol) }, "Failed to close encrypted volume")
+	}
+
+	// Move the GPT alt header to end of disk if needed (not needed in unsafe resize mode as it is
+	// expected the caller will do all necessary post resize actions themselves).
+	if vol.IsVMBlock() && !allowUnsafeResize {
+		err = d.moveGPTAltHeader(devPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
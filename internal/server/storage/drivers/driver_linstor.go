@@ -3,6 +3,7 @@ package drivers
 import (
 	"context"
 	"fmt"
+	"maps"
 	"strconv"
 	"time"
 
@@ -68,6 +69,11 @@ func (d *linstor) isRemote() bool {
 	return true
 }
 
+// supportsEncryption returns true indicating this driver supports encrypted volumes.
+func (d *linstor) supportsEncryption() bool {
+	return true
+}
+
 // Validate checks that all provide keys are supported and that no conflicting or missing configuration is present.
 func (d *linstor) Validate(config map[string]string) error {
 	rules := map[string]func(value string) error{
@@ -160,6 +166,15 @@ func (d *linstor) Validate(config map[string]string) error {
 	//  scope: global
 	//  shortdesc: Extra LINSTOR properties to set. For example, `BCache/PoolName` is encoded as `linstor.raw.BCache/PoolName`.
 
+	// gendoc:generate(entity=storage_linstor, group=common, key=volatile.encryption.master_key)
+	// It is generated when the storage pool is created and is required to access its encrypted volumes.
+	// ---
+	//  type: string
+	//  scope: global
+	//  default: random key
+	//  shortdesc: Base64 encoded key used to encrypt the keys of the encrypted volumes
+	maps.Copy(rules, luksPoolRules())
+
 	return d.validatePool(config, rules, d.commonVolumeRules(), LinstorRawConfigKeyPrefix)
 }
 
@@ -181,7 +196,7 @@ func (d *linstor) FillConfig() error {
 		d.config[DrbdAutoAddQuorumTiebreakerConfigKey] = "true"
 	}
 
-	return nil
+	return luksFillConfig(d.config)
 }
 
 // Create is called during storage pool creation.
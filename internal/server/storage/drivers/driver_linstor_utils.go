@@ -465,6 +465,30 @@ func (d *linstor) getLinstorDevPath(vol Volume) (string, error) {
 	return volumes[volumeIndex].DevicePath, nil
 }
 
+// getContentDevPath returns the path of the device holding the volume content, opening it first if encrypted.
+func (d *linstor) getContentDevPath(vol Volume) (string, error) {
+	devPath, err := d.getLinstorDevPath(vol)
+	if err != nil {
+		return "", err
+	}
+
+	if !isEncrypted(vol) {
+		return devPath, nil
+	}
+
+	return d.luksOpen(vol, devPath)
+}
+
+// formatEncryptedVolume initializes the LUKS header on the DRBD device of the volume.
+func (d *linstor) formatEncryptedVolume(vol Volume) error {
+	devPath, err := d.getLinstorDevPath(vol)
+	if err != nil {
+		return err
+	}
+
+	return d.luksFormat(vol, devPath)
+}
+
 // deleteDisklessResource deletes the diskless resource for the given volume in the current node if one exists.
 func (d *linstor) deleteDisklessResource(vol Volume) error {
 	l := d.logger.AddContext(logger.Ctx{"volume": vol.Name()})
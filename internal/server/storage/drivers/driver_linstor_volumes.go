@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"maps"
 	"os"
 	"slices"
 	"strings"
@@ -69,12 +70,12 @@ func (d *linstor) FillVolumeConfig(vol Volume) error {
 		}
 	}
 
-	return nil
+	return d.luksFillVolumeConfig(vol)
 }
 
 // commonVolumeRules returns validation rules which are common for pool and volume.
 func (d *linstor) commonVolumeRules() map[string]func(value string) error {
-	return map[string]func(value string) error{
+	rules := map[string]func(value string) error{
 		// gendoc:generate(entity=storage_volume_linstor, group=common, key=block.filesystem)
 		//
 		// ---
@@ -137,7 +138,27 @@ func (d *linstor) commonVolumeRules() map[string]func(value string) error {
 		//  default: same as `volume.linstor.remove_snapshots` or `false`
 		//  shortdesc: Remove snapshots as needed
 		LinstorRemoveSnapshotsConfigKey: validate.Optional(validate.IsBool),
+
+		// gendoc:generate(entity=storage_volume_linstor, group=common, key=block.encryption)
+		// Unless `block.encryption.key_file` is set, a random key is generated and stored in the volume configuration, encrypted with the storage pool master key.
+		// ---
+		//  type: bool
+		//  condition: -
+		//  default: same as `volume.block.encryption` or `false`
+		//  shortdesc: Whether to encrypt the volume using LUKS (cannot be changed after creation)
+
+		// gendoc:generate(entity=storage_volume_linstor, group=common, key=block.encryption.key_file)
+		// The file must be placed in the `encryption-keys` directory of the server by the server administrator, on every server using the volume.
+		// ---
+		//  type: string
+		//  condition: encrypted volume
+		//  default: same as `volume.block.encryption.key_file`
+		//  shortdesc: Name of the file containing the encryption key of the volume
 	}
+
+	maps.Copy(rules, luksVolumeRules())
+
+	return rules
 }
 
 // ValidateVolume validates the supplied volume config.
@@ -230,6 +251,55 @@ func (d *linstor) ValidateVolume(vol Volume, removeUnknownKeys bool) error {
 	//  default: same as `volume.snapshot.schedule`
 	//  shortdesc: {{snapshot_schedule_format}}
 
+	// gendoc:generate(entity=storage_volume_linstor, group=common, key=snapshots.retention)
+	// Snapshots not kept by any rule are deleted, including those created manually.
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: Comma-separated list of `<count> <period>` rules (periods being `latest`, `hourly`, `daily`, `weekly`, `monthly` or `yearly`), like `24 hourly, 7 daily`
+
+	// gendoc:generate(entity=storage_volume_linstor, group=common, key=backups.expiry)
+	// Specify an expression like `1M 2H 3d 4w 5m 6y`.
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: When scheduled backups are to be deleted
+
+	// gendoc:generate(entity=storage_volume_linstor, group=common, key=backups.retention.count)
+	// Backups created manually are never deleted.
+	// ---
+	//  type: int
+	//  condition: custom volume
+	//  default: `0` (unlimited)
+	//  shortdesc: Number of scheduled backups to keep
+
+	// gendoc:generate(entity=storage_volume_linstor, group=common, key=backups.schedule)
+	//
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: Cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or empty to disable automatic backups (the default)
+
+	// gendoc:generate(entity=storage_volume_linstor, group=common, key=backups.target)
+	// When set, scheduled backups are uploaded to that bucket instead of being stored on the server.
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: S3 URL (`https://<access key>:<secret key>@<host>/<bucket>[/<prefix>]`) to upload scheduled backups to
+
+	// gendoc:generate(entity=storage_volume_linstor, group=common, key=backups.repository)
+	// When set, scheduled backups are stored in that backup repository instead of being stored on the server.
+	// Only the chunks which changed since the previous backup are stored.
+	// ---
+	//  type: string
+	//  condition: custom block volume
+	//  default: -
+	//  shortdesc: Name of the backup target to store scheduled backups in as a backup repository
+
 	// gendoc:generate(entity=storage_volume_linstor, group=common, key=linstor.raw.*)
 	//
 	// ---
@@ -285,6 +355,11 @@ func (d *linstor) CreateVolume(vol Volume, filler *VolumeFiller, op *operations.
 		return err
 	}
 
+	// Encrypted volumes need extra space for the LUKS header.
+	if isEncrypted(vol) {
+		requiredBytes += luksHeaderSize
+	}
+
 	requiredKiB := requiredBytes / 1024
 	resourceDefinitionName := d.generateUUIDWithPrefix()
 
@@ -304,6 +379,10 @@ func (d *linstor) CreateVolume(vol Volume, filler *VolumeFiller, op *operations.
 			return err
 		}
 
+		if isEncrypted(fsVol) {
+			requiredBytes += luksHeaderSize
+		}
+
 		requiredKiB := requiredBytes / 1024
 
 		volumeSizes = append(volumeSizes, requiredKiB)
@@ -338,9 +417,24 @@ func (d *linstor) CreateVolume(vol Volume, filler *VolumeFiller, op *operations.
 		return fmt.Errorf("Unable to deploy resource: %w", err)
 	}
 
+	// Initialize the LUKS header of encrypted volumes.
+	if isEncrypted(vol) {
+		err = d.formatEncryptedVolume(vol)
+		if err != nil {
+			return err
+		}
+
+		if vol.IsVMBlock() {
+			err = d.formatEncryptedVolume(vol.NewVMBlockFilesystemVolume())
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	// Setup the filesystem.
 	if vol.contentType == ContentTypeFS {
-		devPath, err := d.getLinstorDevPath(vol)
+		devPath, err := d.getContentDevPath(vol)
 		if err != nil {
 			return fmt.Errorf("Could not get device path for filesystem creation: %w", err)
 		}
@@ -359,7 +453,7 @@ func (d *linstor) CreateVolume(vol Volume, filler *VolumeFiller, op *operations.
 		l.Debug("Creating filesystem on the associated filesystem volume")
 
 		fsVol := vol.NewVMBlockFilesystemVolume()
-		fsVolDevPath, err := d.getLinstorDevPath(fsVol)
+		fsVolDevPath, err := d.getContentDevPath(fsVol)
 		if err != nil {
 			return fmt.Errorf("Could not get device path for filesystem creation: %w", err)
 		}
@@ -479,11 +573,13 @@ func (d *linstor) CreateVolumeFromCopy(vol Volume, srcVol Volume, copySnapshots
 	}
 
 	if vol.contentType == ContentTypeFS {
-		devPath, err := d.getLinstorDevPath(vol)
+		devPath, err := d.getContentDevPath(vol)
 		if err != nil {
 			return err
 		}
 
+		defer logger.WarnOnError(func() error { return luksClose(vol) }, "Failed to close encrypted volume")
+
 		fsType := vol.ConfigBlockFilesystem()
 
 		// Generate a new filesystem UUID if needed (this is required because some filesystems won't allow
@@ -553,6 +649,19 @@ func (d *linstor) DeleteVolume(vol Volume, op *operations.Operation) error {
 			return err
 		}
 
+		// Close the decrypted devices as they hold the DRBD devices open.
+		err = luksClose(vol)
+		if err != nil {
+			return err
+		}
+
+		if vol.IsVMBlock() {
+			err = luksClose(vol.NewVMBlockFilesystemVolume())
+			if err != nil {
+				return err
+			}
+		}
+
 		err = d.deleteResourceDefinition(resourceDefinition.Name)
 		if err != nil {
 			return fmt.Errorf("Unable to delete the resource definition: %w", err)
@@ -616,7 +725,7 @@ func (d *linstor) HasVolume(vol Volume) (bool, error) {
 // GetVolumeDiskPath returns the location of a root disk block device.
 func (d *linstor) GetVolumeDiskPath(vol Volume) (string, error) {
 	if vol.IsVMBlock() || (vol.volType == VolumeTypeCustom && IsContentBlock(vol.contentType)) {
-		devPath, err := d.getLinstorDevPath(vol)
+		devPath, err := d.getContentDevPath(vol)
 		return devPath, err
 	}
 
@@ -693,11 +802,15 @@ func (d *linstor) ActivateTask(vol Volume, task func(devPath string, op *operati
 
 	defer unlock()
 
-	volDevPath, err := d.getLinstorDevPath(vol)
+	volDevPath, err := d.getContentDevPath(vol)
 	if err != nil {
 		return err
 	}
 
+	if isEncrypted(vol) && !vol.MountInUse() {
+		defer logger.WarnOnError(func() error { return luksClose(vol) }, "Failed to close encrypted volume")
+	}
+
 	// Run the task.
 	return task(volDevPath, op)
 }
@@ -716,7 +829,7 @@ func (d *linstor) MountVolume(vol Volume, op *operations.Operation) error {
 	rev := revert.New()
 	defer rev.Fail()
 
-	volDevPath, err := d.getLinstorDevPath(vol)
+	volDevPath, err := d.getContentDevPath(vol)
 	if err != nil {
 		return fmt.Errorf("Could not mount volume: %w", err)
 	}
@@ -821,12 +934,25 @@ func (d *linstor) UnmountVolume(vol Volume, keepBlockDev bool, op *operations.Op
 	} else if IsContentBlock(vol.contentType) {
 		// For VMs, unmount the filesystem volume.
 		if vol.IsVMBlock() {
+			if !keepBlockDev {
+				err = luksClose(vol)
+				if err != nil {
+					return false, err
+				}
+			}
+
 			fsVol := vol.NewVMBlockFilesystemVolume()
 			return d.UnmountVolume(fsVol, false, op)
 		}
 	}
 
 	if !keepBlockDev {
+		// Close the decrypted device as it holds the DRBD device open.
+		err = luksClose(vol)
+		if err != nil {
+			return false, err
+		}
+
 		err = d.deleteDisklessResource(vol)
 		if err != nil {
 			return false, fmt.Errorf("Could not delete diskless resource: %w", err)
@@ -1083,11 +1209,13 @@ func (d *linstor) MountVolumeSnapshot(snapVol Volume, op *operations.Operation)
 		rev.Add(func() { _ = d.deleteResourceDefinitionFromSnapshot(snapVol) })
 	}
 
-	volDevPath, err := d.getLinstorDevPath(snapVol)
+	volDevPath, err := d.getContentDevPath(snapVol)
 	if err != nil {
 		return fmt.Errorf("Could not mount volume: %w", err)
 	}
 
+	rev.Add(func() { _ = luksClose(snapVol) })
+
 	l.Debug("Volume is available on node", logger.Ctx{"volDevPath": volDevPath})
 
 	if snapVol.contentType == ContentTypeFS {
@@ -1163,6 +1291,11 @@ func (d *linstor) UnmountVolumeSnapshot(snapVol Volume, op *operations.Operation
 
 	// For VMs, unmount the filesystem volume.
 	if snapVol.IsVMBlock() {
+		err = luksClose(snapVol)
+		if err != nil {
+			return false, err
+		}
+
 		fsVol := snapVol.NewVMBlockFilesystemVolume()
 		return d.UnmountVolumeSnapshot(fsVol, op)
 	}
@@ -1186,6 +1319,11 @@ func (d *linstor) UnmountVolumeSnapshot(snapVol Volume, op *operations.Operation
 		l.Debug("Unmounted snapshot volume filesystem", logger.Ctx{"path": mountPath})
 	}
 
+	err = luksClose(snapVol)
+	if err != nil {
+		return false, err
+	}
+
 	l.Debug("Deleting temporary resource definition for snapshot mount")
 	err = d.deleteResourceDefinitionFromSnapshot(snapVol)
 	if err != nil {
@@ -1199,6 +1337,11 @@ func (d *linstor) UnmountVolumeSnapshot(snapVol Volume, op *operations.Operation
 
 // UpdateVolume applies config changes to the volume.
 func (d *linstor) UpdateVolume(vol Volume, changedConfig map[string]string) error {
+	err := luksCheckConfigChange(changedConfig)
+	if err != nil {
+		return err
+	}
+
 	newSize, sizeChanged := changedConfig["size"]
 	if sizeChanged {
 		err := d.SetVolumeQuota(vol, newSize, false, nil)
@@ -1212,7 +1355,7 @@ func (d *linstor) UpdateVolume(vol Volume, changedConfig map[string]string) erro
 		return nil
 	}
 
-	err := d.updateResourceDefinition(vol, changedConfig)
+	err = d.updateResourceDefinition(vol, changedConfig)
 	if err != nil {
 		return err
 	}
@@ -1253,8 +1396,18 @@ func (d *linstor) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool
 		return err
 	}
 
+	// Encrypted volumes need extra space for the LUKS header.
+	fsSizeBytes := sizeBytes
+	if isEncrypted(vol) {
+		sizeBytes += luksHeaderSize
+
+		if !util.PathExists(luksDevicePath(vol)) {
+			defer logger.WarnOnError(func() error { return luksClose(vol) }, "Failed to close encrypted volume")
+		}
+	}
+
 	// Get the device path.
-	devPath, err := d.getLinstorDevPath(vol)
+	devPath, err := d.getContentDevPath(vol)
 	if err != nil {
 		return err
 	}
@@ -1288,7 +1441,13 @@ func (d *linstor) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool
 
 			// Shrink filesystem first. Pass allowUnsafeResize to allow disabling of filesystem
 			// resize safety checks.
-			err = shrinkFileSystem(fsType, devPath, vol, sizeBytes, allowUnsafeResize)
+			err = shrinkFileSystem(fsType, devPath, vol, fsSizeBytes, allowUnsafeResize)
+			if err != nil {
+				return err
+			}
+
+			// Close the decrypted device before shrinking the block device under it.
+			err = luksClose(vol)
 			if err != nil {
 				return err
 			}
@@ -1305,6 +1464,11 @@ func (d *linstor) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool
 				return err
 			}
 
+			err = d.luksResize(vol)
+			if err != nil {
+				return err
+			}
+
 			// Grow the filesystem to fill block device.
 			err = growFileSystem(fsType, devPath, vol)
 			if err != nil {
@@ -1330,6 +1494,11 @@ func (d *linstor) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool
 			return err
 		}
 
+		err = d.luksResize(vol)
+		if err != nil {
+			return err
+		}
+
 		// Move the VM GPT alt header to end of disk if needed (not needed in unsafe resize mode as it is
 		// expected the caller will do all necessary post resize actions themselves).
 		if vol.IsVMBlock() && !allowUnsafeResize {
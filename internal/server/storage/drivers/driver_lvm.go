@@ -1,9 +1,11 @@
 package drivers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/fs"
+	"maps"
 	"net/http"
 	"os"
 	"os/exec"
@@ -140,6 +142,11 @@ func (d *lvm) isRemote() bool {
 	return d.clustered
 }
 
+// supportsEncryption returns true indicating this driver supports encrypted volumes.
+func (d *lvm) supportsEncryption() bool {
+	return true
+}
+
 // Info returns info about the driver and its environment.
 func (d *lvm) Info() Info {
 	name := "lvm"
@@ -179,7 +186,7 @@ func (d *lvm) FillConfig() error {
 		d.config["lvm.thinpool_name"] = lvmThinpoolDefaultName
 	}
 
-	return nil
+	return luksFillConfig(d.config)
 }
 
 // Create creates the storage pool on the storage device.
@@ -715,6 +722,14 @@ func (d *lvm) Validate(config map[string]string) error {
 	//  default: -
 	//  shortdesc: Path to an existing block device, loop file or LVM volume group.
 
+	// gendoc:generate(entity=storage_lvm, group=common, key=check.schedule)
+	// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or leave empty to disable scheduled checks (the default).
+	// ---
+	//  type: string
+	//  scope: global
+	//  default: empty
+	//  shortdesc: Schedule for automatic integrity checks of the storage pool
+
 	// gendoc:generate(entity=storage_lvm, group=common, key=source.wipe)
 	//
 	// ---
@@ -796,6 +811,15 @@ func (d *lvm) Validate(config map[string]string) error {
 		rules["lvm.vg.force_reuse"] = validate.Optional(validate.IsBool)
 	}
 
+	// gendoc:generate(entity=storage_lvm, group=common, key=volatile.encryption.master_key)
+	// It is generated when the storage pool is created and is required to access its encrypted volumes.
+	// ---
+	//  type: string
+	//  scope: global
+	//  default: random key
+	//  shortdesc: Base64 encoded key used to encrypt the keys of the encrypted volumes
+	maps.Copy(rules, luksPoolRules())
+
 	err := d.validatePool(config, rules, d.commonVolumeRules())
 	if err != nil {
 		return err
@@ -1133,6 +1157,23 @@ func (d *lvm) GetResources() (*api.ResourcesStoragePool, error) {
 	return &res, nil
 }
 
+// Check returns the logical volumes of the storage pool which aren't healthy, including the thin pool
+// and its metadata volume.
+func (d *lvm) Check(ctx context.Context, progress func(percent int64), op *operations.Operation) ([]string, error) {
+	// Internal volumes are included, as the thin pool metadata is kept in one of those.
+	out, err := subprocess.RunCommand("lvs", "--all", "--noheadings", "--separator", "|", "--options", "lv_name,lv_health_status,metadata_percent", d.config["lvm.vg_name"])
+	if err != nil {
+		return nil, err
+	}
+
+	thinPoolName := ""
+	if d.usesThinpool() {
+		thinPoolName = d.thinpoolName()
+	}
+
+	return lvmCheckProblems(out, thinPoolName), nil
+}
+
 // roundVolumeBlockSizeBytes returns sizeBytes rounded up to the next multiple
 // of the volume group extent size.
 func (d *lvm) roundVolumeBlockSizeBytes(vol Volume, sizeBytes int64) (int64, error) {
@@ -443,7 +443,8 @@ func (d *lvm) roundedSizeBytesString(size string) (int64, error) {
 
 // volumeBackingSizeBytes returns the size in bytes that the backing logical volume needs to be
 // to store the volume's content for the given size. For qcow2 block volumes the qcow2 metadata
-// overhead is added so a fully-allocated image always fits within the logical volume.
+// overhead is added so a fully-allocated image always fits within the logical volume, and for
+// encrypted volumes the LUKS header is added.
 func (d *lvm) volumeBackingSizeBytes(vol Volume, size string) (int64, error) {
 	sizeBytes, err := d.roundedSizeBytesString(size)
 	if err != nil {
@@ -460,6 +461,10 @@ func (d *lvm) volumeBackingSizeBytes(vol Volume, size string) (int64, error) {
 		sizeBytes = RoundAbove(512, sizeBytes)
 	}
 
+	if sizeBytes > 0 && isEncrypted(vol) {
+		sizeBytes += luksHeaderSize
+	}
+
 	return sizeBytes, nil
 }
 
@@ -524,6 +529,20 @@ func (d *lvm) createLogicalVolume(vgName, thinPoolName string, vol Volume, makeT
 		return err
 	}
 
+	if isEncrypted(vol) {
+		err = d.luksFormat(vol, volDevPath)
+		if err != nil {
+			return err
+		}
+
+		volDevPath, err = d.luksOpen(vol, volDevPath)
+		if err != nil {
+			return err
+		}
+
+		logCtx["encrypted"] = true
+	}
+
 	if vol.contentType == ContentTypeFS {
 		volFilesystem := vol.ConfigBlockFilesystem()
 		volCreateOptions := vol.ExpandedConfig("block.create_options")
@@ -533,8 +552,9 @@ func (d *lvm) createLogicalVolume(vgName, thinPoolName string, vol Volume, makeT
 		}
 
 		logCtx["fs"] = vol.ConfigBlockFilesystem()
-	} else if !d.usesThinpool() {
+	} else if !d.usesThinpool() && !isEncrypted(vol) {
 		// Make sure we get an empty LV.
+		// This isn't needed for encrypted volumes as any previous data can't be decrypted with the new key.
 		err := linux.ClearBlock(volDevPath, 0)
 		if err != nil {
 			return err
@@ -685,6 +705,21 @@ func (d *lvm) lvmPath(vgName string, volType VolumeType, contentType ContentType
 	return fmt.Sprintf("%s/%s", vgName, fullVolName)
 }
 
+// volumeContentDevPath returns the path of the device holding the volume's content for the LV.
+// For encrypted volumes this opens the LUKS device if needed and returns the decrypted device path.
+func (d *lvm) volumeContentDevPath(vol Volume, volPath string) (string, error) {
+	volDevPath, err := d.lvmDevPath(volPath)
+	if err != nil {
+		return "", err
+	}
+
+	if !isEncrypted(vol) {
+		return volDevPath, nil
+	}
+
+	return d.luksOpen(vol, volDevPath)
+}
+
 // lvmDevPath returns the /dev path for the LV.
 func (d *lvm) lvmDevPath(pathName string) (string, error) {
 	// Get the block dev.
@@ -847,7 +882,7 @@ func (d *lvm) copyThinpoolVolume(vol, srcVol Volume, srcSnapshots []Volume, refr
 				return err
 			}
 
-			volDevPath, err := d.lvmDevPath(volPath)
+			volDevPath, err := d.volumeContentDevPath(vol, volPath)
 			if err != nil {
 				return err
 			}
@@ -902,6 +937,41 @@ func (d *lvm) logicalVolumeSize(volDevPath string) (int64, error) {
 	return strconv.ParseInt(output, 10, 64)
 }
 
+// lvmThinpoolMetadataFullPercent is the thin pool metadata usage above which it's reported as a problem, as
+// running out of metadata space can damage the thin pool.
+const lvmThinpoolMetadataFullPercent = 90
+
+// lvmCheckProblems returns the problems reported by lvs for the logical volumes of a volume group. The output
+// is expected to contain the lv_name, lv_health_status and metadata_percent fields separated by "|".
+func lvmCheckProblems(out string, thinPoolName string) []string {
+	problems := []string{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "|")
+		if len(fields) < 3 {
+			continue
+		}
+
+		lvName := strings.TrimSpace(fields[0])
+		health := strings.TrimSpace(fields[1])
+		metadataPercent := strings.TrimSpace(fields[2])
+
+		if health != "" {
+			problems = append(problems, fmt.Sprintf("Logical volume %q: %s", lvName, health))
+		}
+
+		if thinPoolName == "" || lvName != thinPoolName || metadataPercent == "" {
+			continue
+		}
+
+		percent, err := strconv.ParseFloat(metadataPercent, 64)
+		if err == nil && percent >= lvmThinpoolMetadataFullPercent {
+			problems = append(problems, fmt.Sprintf("Thin pool %q: metadata %.2f%% full", lvName, percent))
+		}
+	}
+
+	return problems
+}
+
 func (d *lvm) thinPoolVolumeUsage(volDevPath string) (uint64, uint64, error) {
 	args := []string{
 		volDevPath,
@@ -1059,6 +1129,14 @@ func (d *lvm) activateVolume(vol Volume) (bool, error) {
 func (d *lvm) deactivateVolume(vol Volume) (bool, error) {
 	var volPath string
 
+	// Close the decrypted device first as it holds the logical volume open.
+	if isEncrypted(vol) {
+		err := luksClose(vol)
+		if err != nil {
+			return false, err
+		}
+	}
+
 	if d.usesThinpool() || IsQcow2Block(vol) {
 		volPath = d.lvmPath(d.config["lvm.vg_name"], vol.volType, vol.contentType, vol.name)
 	} else {
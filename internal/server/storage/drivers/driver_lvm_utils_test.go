@@ -2,6 +2,9 @@ package drivers
 
 import (
 	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func Example_lvm_parseLogicalVolumeName() {
@@ -66,3 +69,27 @@ func Example_lvm_parseLogicalVolumeName() {
 	// custom_proj_testvol--with--hyphens.block: Unrecognised
 	// custom_proj_testvol--with--hyphens.block-snap1--with--hyphens.block: snap1-with-hyphens.block
 }
+
+func TestLVMCheckProblems(t *testing.T) {
+	out := `  IncusThinPool|| 91.50
+  [IncusThinPool_tdata]||
+  [IncusThinPool_tmeta]|needs_check|
+  [lvol0_pmspare]||
+  containers_c1||
+  custom_default_vol1|partial|
+`
+
+	problems := lvmCheckProblems(out, "IncusThinPool")
+	assert.Equal(t, []string{
+		`Thin pool "IncusThinPool": metadata 91.50% full`,
+		`Logical volume "[IncusThinPool_tmeta]": needs_check`,
+		`Logical volume "custom_default_vol1": partial`,
+	}, problems)
+
+	// The metadata usage is only considered for the thin pool in use.
+	problems = lvmCheckProblems("  IncusThinPool||95.00\n", "")
+	assert.Empty(t, problems)
+
+	problems = lvmCheckProblems("  IncusThinPool||12.00\n", "IncusThinPool")
+	assert.Empty(t, problems)
+}
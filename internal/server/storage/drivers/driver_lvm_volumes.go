@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"maps"
 	"math"
 	"os"
 	"os/exec"
@@ -256,6 +257,13 @@ func (d *lvm) DeleteVolume(vol Volume, op *operations.Operation) error {
 			}
 		}
 
+		if isEncrypted(vol) {
+			err = luksClose(vol)
+			if err != nil {
+				return err
+			}
+		}
+
 		err = d.removeLogicalVolume(d.lvmPath(d.config["lvm.vg_name"], vol.volType, vol.contentType, vol.name))
 		if err != nil {
 			return fmt.Errorf("Error removing LVM logical volume: %w", err)
@@ -338,8 +346,10 @@ func (d *lvm) FillVolumeConfig(vol Volume) error {
 	}
 
 	if d.clustered && (vol.IsVMBlock() || vol.IsCustomBlock()) {
-		// Set default block type to qcow2.
-		if vol.config["block.type"] == "" {
+		// Set default block type to qcow2 (raw for encrypted volumes).
+		if vol.config["block.type"] == "" && isEncrypted(vol) {
+			vol.config["block.type"] = BlockVolumeTypeRaw
+		} else if vol.config["block.type"] == "" {
 			vol.config["block.type"] = BlockVolumeTypeQcow2
 		}
 
@@ -360,7 +370,7 @@ func (d *lvm) FillVolumeConfig(vol Volume) error {
 		}
 	}
 
-	return nil
+	return d.luksFillVolumeConfig(vol)
 }
 
 // commonVolumeRules returns validation rules which are common for pool and volume.
@@ -410,8 +420,27 @@ func (d *lvm) commonVolumeRules() map[string]func(value string) error {
 		//  default: same as `volume.lvm.stripes.size`
 		//  shortdesc: Size of stripes to use (at least 4096 bytes and multiple of 512 bytes)
 		"lvm.stripes.size": validate.Optional(validate.IsSize),
+
+		// gendoc:generate(entity=storage_volume_lvm, group=common, key=block.encryption)
+		// Unless `block.encryption.key_file` is set, a random key is generated and stored in the volume configuration, encrypted with the storage pool master key.
+		// Encrypted volumes on shared (`lvmcluster`) storage pools use the `raw` block type.
+		// ---
+		//  type: bool
+		//  condition: -
+		//  default: same as `volume.block.encryption` or `false`
+		//  shortdesc: Whether to encrypt the volume using LUKS (cannot be changed after creation)
+
+		// gendoc:generate(entity=storage_volume_lvm, group=common, key=block.encryption.key_file)
+		// The file must be placed in the `encryption-keys` directory of the server by the server administrator, on every server using the volume.
+		// ---
+		//  type: string
+		//  condition: encrypted volume
+		//  default: same as `volume.block.encryption.key_file`
+		//  shortdesc: Name of the file containing the encryption key of the volume
 	}
 
+	maps.Copy(rules, luksVolumeRules())
+
 	if d.clustered {
 		// gendoc:generate(entity=storage_lvm, group=common, key=block.type)
 		//
@@ -525,6 +554,83 @@ func (d *lvm) ValidateVolume(vol Volume, removeUnknownKeys bool) error {
 	//  default: same as `volume.snapshot.schedule`
 	//  shortdesc: {{snapshot_schedule_format}}
 
+	// gendoc:generate(entity=storage_volume_lvm, group=common, key=snapshots.retention)
+	// Snapshots not kept by any rule are deleted, including those created manually.
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: Comma-separated list of `<count> <period>` rules (periods being `latest`, `hourly`, `daily`, `weekly`, `monthly` or `yearly`), like `24 hourly, 7 daily`
+
+	// gendoc:generate(entity=storage_volume_lvm, group=common, key=backups.expiry)
+	// Specify an expression like `1M 2H 3d 4w 5m 6y`.
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: When scheduled backups are to be deleted
+
+	// gendoc:generate(entity=storage_volume_lvm, group=common, key=backups.retention.count)
+	// Backups created manually are never deleted.
+	// ---
+	//  type: int
+	//  condition: custom volume
+	//  default: `0` (unlimited)
+	//  shortdesc: Number of scheduled backups to keep
+
+	// gendoc:generate(entity=storage_volume_lvm, group=common, key=backups.schedule)
+	//
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: Cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or empty to disable automatic backups (the default)
+
+	// gendoc:generate(entity=storage_volume_lvm, group=common, key=backups.target)
+	// When set, scheduled backups are uploaded to that bucket instead of being stored on the server.
+	// ---
+	//  type: string
+	//  condition: custom volume
+	//  default: -
+	//  shortdesc: S3 URL (`https://<access key>:<secret key>@<host>/<bucket>[/<prefix>]`) to upload scheduled backups to
+
+	// gendoc:generate(entity=storage_volume_lvm, group=common, key=backups.repository)
+	// When set, scheduled backups are stored in that backup repository instead of being stored on the server.
+	// Only the chunks which changed since the previous backup are stored.
+	// ---
+	//  type: string
+	//  condition: custom block volume
+	//  default: -
+	//  shortdesc: Name of the backup target to store scheduled backups in as a backup repository
+
+	// gendoc:generate(entity=storage_bucket_lvm, group=common, key=lifecycle.abort_multipart.days)
+	//
+	// ---
+	//  type: int
+	//  default: -
+	//  shortdesc: Number of days after which incomplete multipart uploads are aborted
+
+	// gendoc:generate(entity=storage_bucket_lvm, group=common, key=lifecycle.expiration.days)
+	//
+	// ---
+	//  type: int
+	//  default: -
+	//  shortdesc: Number of days after their last modification after which objects expire
+
+	// gendoc:generate(entity=storage_bucket_lvm, group=common, key=lifecycle.noncurrent_expiration.days)
+	//
+	// ---
+	//  type: int
+	//  default: -
+	//  shortdesc: Number of days after which non-current object versions are removed
+
+	// gendoc:generate(entity=storage_bucket_lvm, group=common, key=lifecycle.prefix)
+	//
+	// ---
+	//  type: string
+	//  default: -
+	//  shortdesc: Only apply the lifecycle rules to objects whose key starts with this prefix
+
 	// gendoc:generate(entity=storage_bucket_lvm, group=common, key=size)
 	//
 	// ---
@@ -533,6 +639,28 @@ func (d *lvm) ValidateVolume(vol Volume, removeUnknownKeys bool) error {
 	//  default: same as `volume.size`
 	//  shortdesc: Size/quota of the storage bucket
 
+	// gendoc:generate(entity=storage_bucket_lvm, group=common, key=quota.objects)
+	// Writes creating objects past the quota are rejected with a `QuotaExceeded` error.
+	// ---
+	//  type: int
+	//  default: -
+	//  shortdesc: Maximum number of objects in the bucket
+
+	// gendoc:generate(entity=storage_bucket_lvm, group=common, key=quota.size)
+	// Unlike `size`, the quota is enforced by the S3 server regardless of the storage driver.
+	// It includes object versions and incomplete multipart uploads, writes going past it are rejected with a `QuotaExceeded` error.
+	// ---
+	//  type: string
+	//  default: -
+	//  shortdesc: Maximum size of the data stored in the bucket
+
+	// gendoc:generate(entity=storage_bucket_lvm, group=common, key=versioning)
+	// Once enabled, versioning can only be suspended, retaining the existing object versions.
+	// ---
+	//  type: string
+	//  default: -
+	//  shortdesc: Object versioning state of the bucket (`enabled` or `suspended`)
+
 	commonRules := d.commonVolumeRules()
 
 	// Disallow block.* settings for regular custom block volumes. These settings only make sense
@@ -561,6 +689,10 @@ func (d *lvm) ValidateVolume(vol Volume, removeUnknownKeys bool) error {
 		return errors.New("QCOW2 volume type is incompatible with the 'security.shared' option.")
 	}
 
+	if vol.config["block.type"] == BlockVolumeTypeQcow2 && isEncrypted(vol) {
+		return errors.New("QCOW2 volume type is incompatible with the 'block.encryption' option.")
+	}
+
 	return nil
 }
 
@@ -589,6 +721,11 @@ func (d *lvm) UpdateVolume(vol Volume, changedConfig map[string]string) error {
 		return errors.New("block.type cannot be changed after creation")
 	}
 
+	err := luksCheckConfigChange(changedConfig)
+	if err != nil {
+		return err
+	}
+
 	return d.updateVolume(vol, changedConfig)
 }
 
@@ -626,6 +763,28 @@ func (d *lvm) GetVolumeUsage(vol Volume) (int64, error) {
 	return -1, ErrNotSupported
 }
 
+// GetVolumeStats returns the detailed space accounting of a volume.
+func (d *lvm) GetVolumeStats(vol Volume) (*VolumeStats, error) {
+	// Only thin volumes have allocation information.
+	if !d.usesThinpool() {
+		return nil, ErrNotSupported
+	}
+
+	stats := VolumeStats{SnapshotsUsed: -1}
+
+	volPath := d.lvmPath(d.config["lvm.vg_name"], vol.volType, vol.contentType, vol.name)
+	totalSize, usedSize, err := d.thinPoolVolumeUsage(volPath)
+	if err != nil && !errors.Is(err, ErrNotSupported) {
+		return nil, err
+	}
+
+	if usedSize > 0 {
+		stats.OvercommitRatio = float64(totalSize) / float64(usedSize)
+	}
+
+	return &stats, nil
+}
+
 // SetVolumeQuota applies a size limit on volume.
 // Does nothing if supplied with an empty/zero size.
 func (d *lvm) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op *operations.Operation) error {
@@ -698,12 +857,17 @@ func (d *lvm) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op
 			// so that we can have more control over when we trigger unsafe filesystem resize mode,
 			// otherwise by passing -f to lvresize (required for other reasons) this would then pass
 			// -f onto resize2fs as well.
-			volDevPath, err := d.lvmDevPath(volPath)
+			volDevPath, err := d.volumeContentDevPath(vol, volPath)
 			if err != nil {
 				return err
 			}
 
-			err = shrinkFileSystem(fsType, volDevPath, vol, sizeBytes, allowUnsafeResize)
+			fsSizeBytes := sizeBytes
+			if isEncrypted(vol) {
+				fsSizeBytes -= luksHeaderSize
+			}
+
+			err = shrinkFileSystem(fsType, volDevPath, vol, fsSizeBytes, allowUnsafeResize)
 			if err != nil {
 				_, _ = d.deactivateVolume(vol)
 				return err
@@ -737,6 +901,13 @@ func (d *lvm) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op
 				return err
 			}
 
+			if isEncrypted(vol) {
+				err = d.luksResize(vol)
+				if err != nil {
+					return err
+				}
+			}
+
 			// Activate the volume for resizing.
 			activated, err := d.activateVolume(vol)
 			if err != nil {
@@ -750,7 +921,7 @@ func (d *lvm) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op
 			}
 
 			// Grow the filesystem to fill block device.
-			volDevPath, err := d.lvmDevPath(volPath)
+			volDevPath, err := d.volumeContentDevPath(vol, volPath)
 			if err != nil {
 				return err
 			}
@@ -785,8 +956,16 @@ func (d *lvm) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op
 			return err
 		}
 
+		if isEncrypted(vol) {
+			err = d.luksResize(vol)
+			if err != nil {
+				return err
+			}
+		}
+
 		// On thick pools, discard the blocks in the additional space when the volume is grown.
-		if !d.usesThinpool() && oldSizeBytes < sizeBytes {
+		// This isn't needed for encrypted volumes as the previous data can't be decrypted.
+		if !d.usesThinpool() && !isEncrypted(vol) && oldSizeBytes < sizeBytes {
 			// Activate the volume for discarding.
 			activated, err := d.activateVolume(vol)
 			if err != nil {
@@ -827,7 +1006,7 @@ func (d *lvm) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op
 			}
 
 			// Move the GPT alt header.
-			volDevPath, err := d.lvmDevPath(volPath)
+			volDevPath, err := d.volumeContentDevPath(vol, volPath)
 			if err != nil {
 				return err
 			}
@@ -845,12 +1024,35 @@ func (d *lvm) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op
 // GetVolumeDiskPath returns the location of a disk volume.
 func (d *lvm) GetVolumeDiskPath(vol Volume) (string, error) {
 	if vol.IsVMBlock() || (vol.volType == VolumeTypeCustom && IsContentBlock(vol.contentType)) {
-		return d.lvmDevPath(d.lvmPath(d.config["lvm.vg_name"], vol.volType, vol.contentType, vol.name))
+		return d.volumeContentDevPath(vol, d.lvmPath(d.config["lvm.vg_name"], vol.volType, vol.contentType, vol.name))
 	}
 
 	return "", ErrNotSupported
 }
 
+// CheckVolume checks the consistency of a qcow2 block volume and returns the problems which were found.
+func (d *lvm) CheckVolume(vol Volume, op *operations.Operation) ([]string, error) {
+	if !IsQcow2Block(vol) {
+		return nil, ErrNotSupported
+	}
+
+	activated, err := d.activateVolume(vol)
+	if err != nil {
+		return nil, err
+	}
+
+	if activated {
+		defer func() { _, _ = d.deactivateVolume(vol) }()
+	}
+
+	devPath, err := d.lvmDevPath(d.lvmPath(d.config["lvm.vg_name"], vol.volType, vol.contentType, vol.name))
+	if err != nil {
+		return nil, err
+	}
+
+	return Qcow2Check(devPath)
+}
+
 // ListVolumes returns a list of volumes in storage pool.
 func (d *lvm) ListVolumes() ([]Volume, error) {
 	vols := make(map[string]Volume)
@@ -998,7 +1200,7 @@ func (d *lvm) ActivateTask(vol Volume, task func(devPath string, op *operations.
 	}
 
 	// Get the device path.
-	volDevPath, err := d.lvmDevPath(d.lvmPath(d.config["lvm.vg_name"], vol.volType, vol.contentType, vol.name))
+	volDevPath, err := d.volumeContentDevPath(vol, d.lvmPath(d.config["lvm.vg_name"], vol.volType, vol.contentType, vol.name))
 	if err != nil {
 		return err
 	}
@@ -1043,7 +1245,7 @@ func (d *lvm) MountVolume(vol Volume, op *operations.Operation) error {
 		mountPath := vol.MountPath()
 		if !linux.IsMountPoint(mountPath) {
 			fsType := vol.ConfigBlockFilesystem()
-			volDevPath, err := d.lvmDevPath(d.lvmPath(d.config["lvm.vg_name"], vol.volType, vol.contentType, vol.name))
+			volDevPath, err := d.volumeContentDevPath(vol, d.lvmPath(d.config["lvm.vg_name"], vol.volType, vol.contentType, vol.name))
 			if err != nil {
 				return err
 			}
@@ -1574,7 +1776,7 @@ func (d *lvm) MountVolumeSnapshot(snapVol Volume, op *operations.Operation) erro
 			// Get volume path.
 			volPath := d.lvmPath(d.config["lvm.vg_name"], mountVol.volType, mountVol.contentType, mountVol.name)
 
-			volDevPath, err := d.lvmDevPath(volPath)
+			volDevPath, err := d.volumeContentDevPath(mountVol, volPath)
 			if err != nil {
 				return err
 			}
@@ -1703,6 +1905,14 @@ func (d *lvm) UnmountVolumeSnapshot(snapVol Volume, op *operations.Operation) (b
 		}
 
 		if exists {
+			tmpVol := NewVolume(d, d.name, snapVol.volType, snapVol.contentType, tmpVolName, snapVol.config, snapVol.poolConfig)
+			if isEncrypted(tmpVol) {
+				err = luksClose(tmpVol)
+				if err != nil {
+					return true, err
+				}
+			}
+
 			err = d.removeLogicalVolume(tmpVolPath)
 			if err != nil {
 				return true, fmt.Errorf("Failed to remove temporary LVM snapshot volume %q: %w", tmpVolPath, err)
@@ -1854,7 +2064,7 @@ func (d *lvm) RestoreVolume(vol Volume, snapshotName string, op *operations.Oper
 
 			d.logger.Debug("Regenerating filesystem UUID", logger.Ctx{"dev": volPath, "fs": vol.ConfigBlockFilesystem()})
 
-			volDevPath, err := d.lvmDevPath(volPath)
+			volDevPath, err := d.volumeContentDevPath(vol, volPath)
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,275 @@
+package drivers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/lxc/incus/v7/internal/linux"
+	"github.com/lxc/incus/v7/internal/migration"
+	deviceConfig "github.com/lxc/incus/v7/internal/server/device/config"
+	localMigration "github.com/lxc/incus/v7/internal/server/migration"
+	"github.com/lxc/incus/v7/internal/server/operations"
+	internalUtil "github.com/lxc/incus/v7/internal/util"
+	"github.com/lxc/incus/v7/shared/api"
+	"github.com/lxc/incus/v7/shared/logger"
+	"github.com/lxc/incus/v7/shared/subprocess"
+	"github.com/lxc/incus/v7/shared/util"
+	"github.com/lxc/incus/v7/shared/validate"
+)
+
+var (
+	nfsVersion string
+	nfsLoaded  bool
+)
+
+type nfs struct {
+	common
+}
+
+// load is used to run one-time action per-driver rather than per-pool.
+func (d *nfs) load() error {
+	// Done if previously loaded.
+	if nfsLoaded {
+		return nil
+	}
+
+	// Validate the required binaries.
+	_, err := exec.LookPath("mount.nfs")
+	if err != nil {
+		return errors.New("Required tool 'mount.nfs' is missing")
+	}
+
+	// Detect and record the version.
+	if nfsVersion == "" {
+		out, err := subprocess.RunCommand("mount.nfs", "-V")
+		if err != nil {
+			return err
+		}
+
+		// The output looks like "mount.nfs: (linux nfs-utils 2.6.4)".
+		fields := strings.Fields(strings.Trim(strings.TrimSpace(out), "()"))
+		if len(fields) > 0 {
+			nfsVersion = strings.TrimSuffix(fields[len(fields)-1], ")")
+		}
+	}
+
+	nfsLoaded = true
+	return nil
+}
+
+// isRemote returns true indicating this driver uses remote storage.
+func (d *nfs) isRemote() bool {
+	return true
+}
+
+// Info returns the pool driver information.
+func (d *nfs) Info() Info {
+	return Info{
+		Name:                         "nfs",
+		Version:                      nfsVersion,
+		DefaultVMBlockFilesystemSize: deviceConfig.DefaultVMBlockFilesystemSize,
+		OptimizedImages:              false,
+		PreservesInodes:              false,
+		Remote:                       d.isRemote(),
+		NearLiveMigration:            false,
+		VolumeTypes:                  []VolumeType{VolumeTypeCustom, VolumeTypeContainer},
+		VolumeMultiNode:              d.isRemote(),
+		BlockBacking:                 false,
+		RunningCopyFreeze:            true,
+		DirectIO:                     true,
+		MountedRoot:                  true,
+	}
+}
+
+// FillConfig populates the storage pool's configuration file with the default values.
+func (d *nfs) FillConfig() error {
+	return nil
+}
+
+// Create is called during pool creation and is effectively using an empty driver struct.
+// WARNING: The Create() function cannot rely on any of the struct attributes being set.
+func (d *nfs) Create() error {
+	err := d.FillConfig()
+	if err != nil {
+		return err
+	}
+
+	// Config validation.
+	if d.config["source"] == "" {
+		return errors.New("Missing required source export")
+	}
+
+	// Create a temporary mountpoint.
+	mountPath, err := os.MkdirTemp("", "incus_nfs_")
+	if err != nil {
+		return fmt.Errorf("Failed to create temporary directory under: %w", err)
+	}
+
+	defer logger.WarnOnError(func() error { return os.RemoveAll(mountPath) }, "Failed to remove temporary directory")
+
+	err = os.Chmod(mountPath, 0o700)
+	if err != nil {
+		return fmt.Errorf("Failed to chmod '%s': %w", mountPath, err)
+	}
+
+	mountPoint := filepath.Join(mountPath, "mount")
+
+	err = os.Mkdir(mountPoint, 0o700)
+	if err != nil {
+		return fmt.Errorf("Failed to create directory '%s': %w", mountPoint, err)
+	}
+
+	// Mount the export.
+	err = d.mountExport(mountPoint)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _, _ = forceUnmount(mountPoint) }()
+
+	// Check that the export is empty.
+	ok, _ := internalUtil.PathIsEmpty(mountPoint)
+	if !ok {
+		return errors.New("Only empty NFS exports can be used as a storage pool")
+	}
+
+	return nil
+}
+
+// Delete clears any local and remote data related to this driver instance.
+func (d *nfs) Delete(op *operations.Operation) error {
+	_, err := d.Mount()
+	if err != nil {
+		return err
+	}
+
+	// On delete, wipe everything in the directory.
+	err = wipeDirectory(GetPoolMountPath(d.name))
+	if err != nil {
+		return err
+	}
+
+	// Make sure the existing pool is unmounted.
+	_, err = d.Unmount()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Validate checks that all provide keys are supported and that no conflicting or missing configuration is present.
+func (d *nfs) Validate(config map[string]string) error {
+	// gendoc:generate(entity=storage_nfs, group=common, key=source)
+	//
+	// ---
+	//  type: string
+	//  scope: global
+	//  default: -
+	//  shortdesc: NFS export to use, in the `<host>:<path>` format
+
+	// gendoc:generate(entity=storage_nfs, group=common, key=rsync.bwlimit)
+	//
+	// ---
+	//  type: string
+	//  scope: global
+	//  default: `0` (no limit)
+	//  shortdesc: The upper limit to be placed on the socket I/O when `rsync` must be used to transfer storage entities
+
+	// gendoc:generate(entity=storage_nfs, group=common, key=rsync.compression)
+	//
+	// ---
+	//  type: bool
+	//  scope: global
+	//  default: `true`
+	//  shortdesc: Whether to use compression while migrating storage pools
+
+	rules := map[string]func(value string) error{
+		// gendoc:generate(entity=storage_nfs, group=common, key=nfs.mount_options)
+		//
+		// ---
+		//  type: string
+		//  scope: global
+		//  default: -
+		//  shortdesc: Comma-separated list of additional mount options to use when mounting the export (like `vers=4.2`)
+		"nfs.mount_options": validate.IsAny,
+	}
+
+	err := d.validatePool(config, rules, nil)
+	if err != nil {
+		return err
+	}
+
+	if config["source"] != "" {
+		host, path, found := strings.Cut(config["source"], ":")
+		if !found || host == "" || !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("Invalid NFS export %q, must be in the <host>:<path> format", config["source"])
+		}
+	}
+
+	return nil
+}
+
+// Update applies any driver changes required from a configuration change.
+func (d *nfs) Update(changedConfig map[string]string) error {
+	_, changed := changedConfig["source"]
+	if changed {
+		return errors.New("NFS export cannot be changed")
+	}
+
+	return nil
+}
+
+// Mount brings up the driver and sets it up to be used.
+func (d *nfs) Mount() (bool, error) {
+	// Check if already mounted.
+	if linux.IsMountPoint(GetPoolMountPath(d.name)) {
+		return false, nil
+	}
+
+	err := d.mountExport(GetPoolMountPath(d.name))
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Unmount clears any of the runtime state of the driver.
+func (d *nfs) Unmount() (bool, error) {
+	return forceUnmount(GetPoolMountPath(d.name))
+}
+
+// GetResources returns the pool resource usage information.
+func (d *nfs) GetResources() (*api.ResourcesStoragePool, error) {
+	return genericVFSGetResources(d)
+}
+
+// MigrationTypes returns the supported migration types and options supported by the driver.
+func (d *nfs) MigrationTypes(contentType ContentType, refresh bool, copySnapshots bool, clusterMove bool, storageMove bool) []localMigration.Type {
+	var rsyncFeatures []string
+
+	// Do not pass compression argument to rsync if the associated
+	// config key, that is rsync.compression, is set to false.
+	if util.IsFalse(d.Config()["rsync.compression"]) {
+		rsyncFeatures = []string{"delete", "bidirectional"}
+	} else {
+		rsyncFeatures = []string{"delete", "compress", "bidirectional"}
+	}
+
+	if contentType != ContentTypeFS {
+		return nil
+	}
+
+	// Most NFS exports can't store security extended attributes, so don't transfer them.
+	return []localMigration.Type{
+		{
+			FSType:   migration.MigrationFSType_RSYNC,
+			Features: rsyncFeatures,
+		},
+	}
+}
@@ -32,3 +32,10 @@ type VolumeFiller struct {
 
 	Fingerprint string // If the Filler will unpack an image, it should be this fingerprint.
 }
+
+// VolumeStats provides detailed space accounting for a volume.
+type VolumeStats struct {
+	SnapshotsUsed    int64   // Space used exclusively by the volume's snapshots in bytes (-1 if unknown).
+	CompressionRatio float64 // Ratio between the logical and the stored size of the data (0 if unknown).
+	OvercommitRatio  float64 // Ratio between the provisioned and the allocated size of a thin volume (0 if not thin).
+}
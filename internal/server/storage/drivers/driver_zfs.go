@@ -1,15 +1,18 @@
 package drivers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/lxc/incus/v7/internal/linux"
 	"github.com/lxc/incus/v7/internal/migration"
@@ -524,6 +527,14 @@ func (d *zfs) Validate(config map[string]string) error {
 	//  default: -
 	//  shortdesc: Path to existing block device(s), loop file or ZFS dataset/pool. Multiple block devices should be separated by `,`. When listing block devices, you can also prefix them with `vdev` type. To specify a `vdev` type, use an `=` sign between the `vdev` type and the block devices (e.g., `mirror=/dev/sda,/dev/sdb`). Only `stripe`, `mirror`, `raidz1` and `raidz2` `vdev` types are supported.
 
+	// gendoc:generate(entity=storage_zfs, group=common, key=check.schedule)
+	// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or leave empty to disable scheduled checks (the default).
+	// ---
+	//  type: string
+	//  scope: global
+	//  default: empty
+	//  shortdesc: Schedule for automatic integrity checks of the storage pool
+
 	// gendoc:generate(entity=storage_zfs, group=common, key=source.wipe)
 	//
 	// ---
@@ -767,6 +778,70 @@ func (d *zfs) GetResources() (*api.ResourcesStoragePool, error) {
 	return &res, nil
 }
 
+// zfsScrubProgress matches the completion percentage of a running scrub in the zpool status.
+var zfsScrubProgress = regexp.MustCompile(`([0-9.]+)% done`)
+
+// Check scrubs the zpool backing the storage pool and returns the problems which were found.
+func (d *zfs) Check(ctx context.Context, progress func(percent int64), op *operations.Operation) ([]string, error) {
+	// Scrubs apply to the whole zpool, even when only a dataset of it is used.
+	poolName, _, _ := strings.Cut(d.config["zfs.pool_name"], "/")
+
+	out, err := subprocess.RunCommand("zpool", "status", poolName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Wait for an already running scrub rather than restarting it.
+	if !strings.Contains(out, "scrub in progress") {
+		_, err = subprocess.RunCommand("zpool", "scrub", poolName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		out, err := subprocess.RunCommand("zpool", "status", poolName)
+		if err != nil {
+			return nil, err
+		}
+
+		if !strings.Contains(out, "scrub in progress") {
+			break
+		}
+
+		match := zfsScrubProgress.FindStringSubmatch(out)
+		if match != nil {
+			percent, err := strconv.ParseFloat(match[1], 64)
+			if err == nil {
+				progress(int64(percent))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			// Stop the scrub rather than leaving it running in the background.
+			_, err := subprocess.RunCommand("zpool", "scrub", "-s", poolName)
+			if err != nil {
+				d.logger.Warn("Failed stopping scrub", logger.Ctx{"pool": poolName, "err": err})
+			}
+
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Second):
+		}
+	}
+
+	out, err = subprocess.RunCommand("zpool", "status", "-x", poolName)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.Contains(out, "is healthy") {
+		return nil, nil
+	}
+
+	return []string{strings.TrimSpace(out)}, nil
+}
+
 // MigrationTypes returns the type of transfer methods to be used when doing migrations between pools in preference order.
 func (d *zfs) MigrationTypes(contentType ContentType, refresh bool, copySnapshots bool, clusterMove bool, storageMove bool) []localMigration.Type {
 	var rsyncFeatures []string
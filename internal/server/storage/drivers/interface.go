@@ -1,6 +1,7 @@
 package drivers
 
 import (
+	"context"
 	"io"
 	"net/url"
 
@@ -21,6 +22,7 @@ type driver interface {
 	init(s *state.State, name string, config map[string]string, log logger.Logger, volIDFunc func(volType VolumeType, volName string) (int64, error), commonRules *Validators)
 	load() error
 	isRemote() bool
+	supportsEncryption() bool
 }
 
 // Driver represents a low-level storage driver.
@@ -47,6 +49,11 @@ type Driver interface {
 	// Unmount unmounts a storage pool if needed, returns true if unmounted, false if was not mounted.
 	Unmount() (bool, error)
 	GetResources() (*api.ResourcesStoragePool, error)
+
+	// Check runs an integrity check of the storage pool, reporting its completion percentage through
+	// progress, and returns the problems which were found. The check is stopped when ctx is cancelled.
+	Check(ctx context.Context, progress func(percent int64), op *operations.Operation) ([]string, error)
+
 	Validate(config map[string]string) error
 	Update(changedConfig map[string]string) error
 	ApplyPatch(name string) error
@@ -72,8 +79,10 @@ type Driver interface {
 	RenameVolume(vol Volume, newName string, op *operations.Operation) error
 	UpdateVolume(vol Volume, changedConfig map[string]string) error
 	GetVolumeUsage(vol Volume) (int64, error)
+	GetVolumeStats(vol Volume) (*VolumeStats, error)
 	SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op *operations.Operation) error
 	GetVolumeDiskPath(vol Volume) (string, error)
+	CheckVolume(vol Volume, op *operations.Operation) ([]string, error)
 	ListVolumes() ([]Volume, error)
 
 	// ActivateTask is a low-level access function to get to the underlying storage.
@@ -13,6 +13,7 @@ var drivers = map[string]func() driver{
 	"dir":        func() driver { return &dir{} },
 	"lvm":        func() driver { return &lvm{} },
 	"lvmcluster": func() driver { return &lvm{clustered: true} },
+	"nfs":        func() driver { return &nfs{} },
 	"truenas":    func() driver { return &truenas{} },
 	"zfs":        func() driver { return &zfs{} },
 	"linstor":    func() driver { return &linstor{} },
@@ -91,3 +92,17 @@ func RemoteDriverNames() []string {
 
 	return driverNames
 }
+
+// EncryptionDriverNames returns a list of storage driver names supporting encrypted volumes.
+func EncryptionDriverNames() []string {
+	driverNames := make([]string, 0, len(drivers))
+	for driverName, driverFunc := range drivers {
+		if !driverFunc().supportsEncryption() {
+			continue
+		}
+
+		driverNames = append(driverNames, driverName)
+	}
+
+	return driverNames
+}
@@ -0,0 +1,320 @@
+package drivers
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	internalUtil "github.com/lxc/incus/v7/internal/util"
+	"github.com/lxc/incus/v7/shared/subprocess"
+	"github.com/lxc/incus/v7/shared/util"
+	"github.com/lxc/incus/v7/shared/validate"
+)
+
+// EncryptionMasterKeyConfigKey is the storage pool config key holding the master key wrapping the volume keys.
+const EncryptionMasterKeyConfigKey = "volatile.encryption.master_key"
+
+// EncryptionKeyConfigKey is the volume config key holding the wrapped volume key.
+const EncryptionKeyConfigKey = "volatile.encryption.key"
+
+// luksHeaderSize is the space reserved at the start of an encrypted volume for the LUKS2 header.
+const luksHeaderSize = 16 * 1024 * 1024
+
+// luksKeySize is the size of the randomly generated volume keys.
+const luksKeySize = 64
+
+// luksMasterKeySize is the size of the storage pool master key (AES-256).
+const luksMasterKeySize = 32
+
+// errLUKSNotSupported is returned when encryption is requested on a storage driver not supporting it.
+var errLUKSNotSupported = errors.New("Volume encryption is only supported on storage pools using the lvm, ceph, linstor or dir driver")
+
+// luksPoolRules returns the validation rules for the storage pool encryption options.
+func luksPoolRules() map[string]func(value string) error {
+	return map[string]func(value string) error{
+		EncryptionMasterKeyConfigKey: validate.Optional(luksValidateMasterKey),
+	}
+}
+
+// luksVolumeRules returns the validation rules for the block.encryption options.
+func luksVolumeRules() map[string]func(value string) error {
+	return map[string]func(value string) error{
+		"block.encryption":          validate.Optional(validate.IsBool),
+		"block.encryption.key_file": validate.Optional(luksValidateKeyFile),
+	}
+}
+
+// luksValidateMasterKey checks that the value is a base64 encoded master key.
+func luksValidateMasterKey(value string) error {
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(key) != luksMasterKeySize {
+		return fmt.Errorf("Must be a base64 encoded %d bytes key", luksMasterKeySize)
+	}
+
+	return nil
+}
+
+// luksValidateKeyFile checks that the value is the name of a file in the encryption keys directory.
+func luksValidateKeyFile(value string) error {
+	if value == "" || value == "." || value == ".." || filepath.Base(value) != value {
+		return fmt.Errorf("Must be the name of a file in %q", luksKeysPath())
+	}
+
+	return nil
+}
+
+// luksKeysPath returns the root-only directory holding the key files provided by the server administrator.
+func luksKeysPath() string {
+	return internalUtil.VarPath("encryption-keys")
+}
+
+// GenerateEncryptionMasterKey returns a new random base64 encoded storage pool master key.
+func GenerateEncryptionMasterKey() (string, error) {
+	key := make([]byte, luksMasterKeySize)
+	_, err := rand.Read(key)
+	if err != nil {
+		return "", fmt.Errorf("Failed generating encryption master key: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// luksFillConfig generates the master key of a new storage pool unless one is provided.
+func luksFillConfig(config map[string]string) error {
+	if config[EncryptionMasterKeyConfigKey] != "" {
+		return nil
+	}
+
+	key, err := GenerateEncryptionMasterKey()
+	if err != nil {
+		return err
+	}
+
+	config[EncryptionMasterKeyConfigKey] = key
+
+	return nil
+}
+
+// luksCipher returns the cipher used to wrap volume keys with the storage pool master key.
+func luksCipher(poolConfig map[string]string) (cipher.AEAD, error) {
+	masterKey, err := base64.StdEncoding.DecodeString(poolConfig[EncryptionMasterKeyConfigKey])
+	if err != nil || len(masterKey) != luksMasterKeySize {
+		return nil, errors.New("Storage pool encryption master key is missing or invalid")
+	}
+
+	block, err := aes.NewCipher(masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// luksWrapKey encrypts a volume key with the storage pool master key.
+func luksWrapKey(poolConfig map[string]string, key []byte) (string, error) {
+	aead, err := luksCipher(poolConfig)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return "", fmt.Errorf("Failed generating nonce: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, key, nil)), nil
+}
+
+// luksUnwrapKey decrypts a volume key with the storage pool master key.
+func luksUnwrapKey(poolConfig map[string]string, wrappedKey string) ([]byte, error) {
+	aead, err := luksCipher(poolConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(wrappedKey)
+	if err != nil || len(data) < aead.NonceSize() {
+		return nil, errors.New("Invalid wrapped encryption key")
+	}
+
+	key, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.New("Encryption key doesn't match the storage pool master key")
+	}
+
+	return key, nil
+}
+
+// isEncrypted returns true if the volume content is stored on a LUKS encrypted device.
+func isEncrypted(vol Volume) bool {
+	return util.IsTrue(vol.config["block.encryption"])
+}
+
+// luksFillVolumeConfig generates the key of a new encrypted volume unless it uses a key file, and stores it in the
+// volume config wrapped with the storage pool master key. An existing key which can be unwrapped is kept, as the
+// content of a volume copied within the pool is already encrypted with it.
+func (d *common) luksFillVolumeConfig(vol Volume) error {
+	if !isEncrypted(vol) || vol.config["block.encryption.key_file"] != "" {
+		return nil
+	}
+
+	if vol.config[EncryptionKeyConfigKey] != "" {
+		_, err := luksUnwrapKey(d.config, vol.config[EncryptionKeyConfigKey])
+		if err == nil {
+			return nil
+		}
+	}
+
+	key := make([]byte, luksKeySize)
+	_, err := rand.Read(key)
+	if err != nil {
+		return fmt.Errorf("Failed generating encryption key: %w", err)
+	}
+
+	wrappedKey, err := luksWrapKey(d.config, key)
+	if err != nil {
+		return err
+	}
+
+	vol.config[EncryptionKeyConfigKey] = wrappedKey
+
+	return nil
+}
+
+// luksKey returns the key of an encrypted volume.
+func (d *common) luksKey(vol Volume) ([]byte, error) {
+	keyFile := vol.config["block.encryption.key_file"]
+	if keyFile != "" {
+		err := luksValidateKeyFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		keyPath := filepath.Join(luksKeysPath(), keyFile)
+
+		key, err := os.ReadFile(keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("Failed reading encryption key file %q: %w", keyPath, err)
+		}
+
+		return key, nil
+	}
+
+	wrappedKey := vol.config[EncryptionKeyConfigKey]
+	if wrappedKey == "" {
+		return nil, fmt.Errorf("Encryption key of volume %q is missing", vol.name)
+	}
+
+	key, err := luksUnwrapKey(d.config, wrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading encryption key of volume %q: %w", vol.name, err)
+	}
+
+	return key, nil
+}
+
+// luksDeviceName returns the device mapper name used for the decrypted volume.
+func luksDeviceName(vol Volume) string {
+	hash := sha256.Sum256(fmt.Appendf(nil, "%s/%s/%s/%s", vol.pool, vol.volType, vol.contentType, vol.name))
+
+	return fmt.Sprintf("incus-crypt-%x", hash[:12])
+}
+
+// luksDevicePath returns the path of the decrypted volume.
+func luksDevicePath(vol Volume) string {
+	return filepath.Join("/dev/mapper", luksDeviceName(vol))
+}
+
+// luksRun runs cryptsetup, passing the volume key on stdin.
+func (d *common) luksRun(vol Volume, args ...string) error {
+	key, err := d.luksKey(vol)
+	if err != nil {
+		return err
+	}
+
+	return subprocess.RunCommandWithFds(context.TODO(), bytes.NewReader(key), nil, "cryptsetup", append([]string{"--batch-mode", "--key-file=-"}, args...)...)
+}
+
+// luksFormat initializes the LUKS header of an encrypted volume on its backing device.
+func (d *common) luksFormat(vol Volume, devPath string) error {
+	err := d.luksRun(vol, "luksFormat", "--type", "luks2", devPath)
+	if err != nil {
+		return fmt.Errorf("Failed formatting encrypted volume %q: %w", vol.name, err)
+	}
+
+	return nil
+}
+
+// luksOpen opens the encrypted volume on its backing device if not already open, and returns the decrypted device path.
+func (d *common) luksOpen(vol Volume, devPath string) (string, error) {
+	mapperPath := luksDevicePath(vol)
+	if util.PathExists(mapperPath) {
+		return mapperPath, nil
+	}
+
+	err := d.luksRun(vol, "open", "--type", "luks2", devPath, luksDeviceName(vol))
+	if err != nil {
+		return "", fmt.Errorf("Failed opening encrypted volume %q: %w", vol.name, err)
+	}
+
+	return mapperPath, nil
+}
+
+// luksCheckKey checks that the key of the volume unlocks the LUKS header on its backing device.
+func (d *common) luksCheckKey(vol Volume, devPath string) error {
+	err := d.luksRun(vol, "open", "--test-passphrase", "--type", "luks2", devPath)
+	if err != nil {
+		return fmt.Errorf("Encryption key of volume %q doesn't unlock its content, source and target storage pools must use the same %q: %w", vol.name, EncryptionMasterKeyConfigKey, err)
+	}
+
+	return nil
+}
+
+// luksClose closes the encrypted volume if open.
+func luksClose(vol Volume) error {
+	if !util.PathExists(luksDevicePath(vol)) {
+		return nil
+	}
+
+	_, err := subprocess.TryRunCommand("cryptsetup", "close", luksDeviceName(vol))
+	if err != nil {
+		return fmt.Errorf("Failed closing encrypted volume %q: %w", vol.name, err)
+	}
+
+	return nil
+}
+
+// luksResize resizes the open encrypted volume to match its backing device.
+func (d *common) luksResize(vol Volume) error {
+	if !util.PathExists(luksDevicePath(vol)) {
+		return nil
+	}
+
+	err := d.luksRun(vol, "resize", luksDeviceName(vol))
+	if err != nil {
+		return fmt.Errorf("Failed resizing encrypted volume %q: %w", vol.name, err)
+	}
+
+	return nil
+}
+
+// luksCheckConfigChange returns an error if an encryption setting is being changed on an existing volume.
+func luksCheckConfigChange(changedConfig map[string]string) error {
+	for _, key := range []string{"block.encryption", "block.encryption.key_file", EncryptionKeyConfigKey} {
+		_, changed := changedConfig[key]
+		if changed {
+			return errors.New("Volume encryption cannot be changed after creation")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLUKSTestPool(t *testing.T) *common {
+	t.Helper()
+
+	config := map[string]string{}
+	require.NoError(t, luksFillConfig(config))
+
+	return &common{name: "pool", config: config}
+}
+
+func TestLUKSWrapKey(t *testing.T) {
+	d := newLUKSTestPool(t)
+	key := []byte("0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef")
+
+	wrappedKey, err := luksWrapKey(d.config, key)
+	require.NoError(t, err)
+	assert.NotContains(t, wrappedKey, string(key))
+
+	unwrappedKey, err := luksUnwrapKey(d.config, wrappedKey)
+	require.NoError(t, err)
+	assert.Equal(t, key, unwrappedKey)
+
+	// A different master key can't unwrap the key.
+	other := newLUKSTestPool(t)
+	_, err = luksUnwrapKey(other.config, wrappedKey)
+	assert.Error(t, err)
+
+	// A missing master key is reported.
+	_, err = luksWrapKey(map[string]string{}, key)
+	assert.Error(t, err)
+}
+
+func TestLUKSValidateKeyFile(t *testing.T) {
+	for _, value := range []string{"key", "my-volume.key"} {
+		assert.NoError(t, luksValidateKeyFile(value), value)
+	}
+
+	for _, value := range []string{"", ".", "..", "/etc/shadow", "../key", "dir/key"} {
+		assert.Error(t, luksValidateKeyFile(value), value)
+	}
+}
+
+func TestLUKSValidateMasterKey(t *testing.T) {
+	key, err := GenerateEncryptionMasterKey()
+	require.NoError(t, err)
+	assert.NoError(t, luksValidateMasterKey(key))
+
+	assert.Error(t, luksValidateMasterKey("not base64"))
+	assert.Error(t, luksValidateMasterKey("c2hvcnQ="))
+}
+
+func TestLUKSFillVolumeConfig(t *testing.T) {
+	d := newLUKSTestPool(t)
+
+	// Unencrypted volumes and volumes using a key file don't get a key.
+	vol := Volume{name: "vol", config: map[string]string{}}
+	require.NoError(t, d.luksFillVolumeConfig(vol))
+	assert.Empty(t, vol.config[EncryptionKeyConfigKey])
+
+	vol.config["block.encryption"] = "true"
+	vol.config["block.encryption.key_file"] = "key"
+	require.NoError(t, d.luksFillVolumeConfig(vol))
+	assert.Empty(t, vol.config[EncryptionKeyConfigKey])
+
+	// Encrypted volumes get a key wrapped with the master key.
+	delete(vol.config, "block.encryption.key_file")
+	require.NoError(t, d.luksFillVolumeConfig(vol))
+	wrappedKey := vol.config[EncryptionKeyConfigKey]
+	require.NotEmpty(t, wrappedKey)
+
+	key, err := d.luksKey(vol)
+	require.NoError(t, err)
+	assert.Len(t, key, luksKeySize)
+
+	// A key which can be unwrapped is kept.
+	require.NoError(t, d.luksFillVolumeConfig(vol))
+	assert.Equal(t, wrappedKey, vol.config[EncryptionKeyConfigKey])
+
+	// A key wrapped with another master key is replaced.
+	other := newLUKSTestPool(t)
+	require.NoError(t, other.luksFillVolumeConfig(vol))
+	assert.NotEqual(t, wrappedKey, vol.config[EncryptionKeyConfigKey])
+
+	_, err = other.luksKey(vol)
+	assert.NoError(t, err)
+}
+
+func TestLUKSDeviceName(t *testing.T) {
+	vols := []Volume{
+		{pool: "pool1", volType: VolumeTypeCustom, contentType: ContentTypeBlock, name: "vol"},
+		{pool: "pool2", volType: VolumeTypeCustom, contentType: ContentTypeBlock, name: "vol"},
+		{pool: "pool1", volType: VolumeTypeVM, contentType: ContentTypeBlock, name: "vol"},
+		{pool: "pool1", volType: VolumeTypeVM, contentType: ContentTypeFS, name: "vol"},
+		{pool: "pool1", volType: VolumeTypeCustom, contentType: ContentTypeBlock, name: "vol/snap0"},
+	}
+
+	names := map[string]bool{}
+	for _, vol := range vols {
+		name := luksDeviceName(vol)
+		assert.False(t, names[name], name)
+		assert.Equal(t, name, luksDeviceName(vol))
+		names[name] = true
+	}
+}
+
+func TestLUKSCheckConfigChange(t *testing.T) {
+	assert.NoError(t, luksCheckConfigChange(map[string]string{"size": "10GiB"}))
+
+	for _, key := range []string{"block.encryption", "block.encryption.key_file", EncryptionKeyConfigKey} {
+		assert.Error(t, luksCheckConfigChange(map[string]string{key: ""}), key)
+	}
+}
@@ -179,6 +179,70 @@ func Qcow2BackingChain(path string) ([]string, error) {
 	return result, nil
 }
 
+// qcow2CheckResult contains the result of a qcow2 image consistency check.
+type qcow2CheckResult struct {
+	Corruptions int `json:"corruptions"`
+	Leaks       int `json:"leaks"`
+	CheckErrors int `json:"check-errors"`
+}
+
+// Qcow2Check checks the consistency of a qcow2 image, leaving its backing chain aside, and returns the
+// problems which were found.
+func Qcow2Check(path string) ([]string, error) {
+	fileDriver := "file"
+	if linux.IsBlockdevPath(path) {
+		fileDriver = "host_device"
+	}
+
+	// The backing images aren't checked, as they may not be accessible and get checked on their own.
+	imageSpec, err := json.Marshal(map[string]any{
+		"driver":  "qcow2",
+		"backing": nil,
+		"file": map[string]any{
+			"driver":   fileDriver,
+			"filename": path,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Problems are reported with non-zero exit codes along with the result. The image is locked during
+	// the check so that it can't get used in the meantime.
+	out, err := subprocess.RunCommand("qemu-img", "check", "--output=json", "json:"+string(imageSpec))
+	if err != nil {
+		var runErr subprocess.RunError
+		if !errors.As(err, &runErr) || runErr.StdOut().Len() == 0 {
+			return nil, err
+		}
+
+		out = runErr.StdOut().String()
+	}
+
+	result := qcow2CheckResult{}
+
+	err = json.Unmarshal([]byte(out), &result)
+	if err != nil {
+		return nil, fmt.Errorf("Failed unmarshalling check result of %q: %w (%q)", path, err, out)
+	}
+
+	problems := []string{}
+
+	if result.Corruptions > 0 {
+		problems = append(problems, fmt.Sprintf("%d corrupted clusters", result.Corruptions))
+	}
+
+	if result.Leaks > 0 {
+		problems = append(problems, fmt.Sprintf("%d leaked clusters", result.Leaks))
+	}
+
+	if result.CheckErrors > 0 {
+		problems = append(problems, fmt.Sprintf("%d errors during check", result.CheckErrors))
+	}
+
+	return problems, nil
+}
+
 // Qcow2MountConfigTask mounts the config filesystem volume with its snapshots and performs the task specified by the parameter.
 func Qcow2MountConfigTask(vol Volume, op *operations.Operation, task func(mountPath string) error) error {
 	mountPath := fmt.Sprintf("%s%s", vol.MountPath(), tmpVolSuffix)
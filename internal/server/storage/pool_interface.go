@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"context"
 	"io"
 	"net"
 	"net/url"
@@ -18,10 +19,13 @@ import (
 	"github.com/lxc/incus/v7/shared/revert"
 )
 
-// VolumeUsage contains the used and total size of a volume.
+// VolumeUsage contains the space accounting of a volume.
 type VolumeUsage struct {
-	Used  int64
-	Total int64
+	Used             int64
+	Total            int64
+	SnapshotsUsed    int64
+	CompressionRatio float64
+	OvercommitRatio  float64
 }
 
 // MountInfo represents info about the result of a mount operation.
@@ -50,6 +54,7 @@ type Pool interface {
 	ToAPI() api.StoragePool
 
 	GetResources() (*api.ResourcesStoragePool, error)
+	Check(ctx context.Context, op *operations.Operation) ([]string, error)
 	IsUsed() (bool, error)
 	Delete(clientType request.ClientType, op *operations.Operation) error
 	Update(clientType request.ClientType, newDesc string, newConfig map[string]string, op *operations.Operation) error
@@ -78,6 +83,9 @@ type Pool interface {
 
 	MigrateInstance(inst instance.Instance, conn io.ReadWriteCloser, args *migration.VolumeSourceArgs, op *operations.Operation) error
 	RefreshInstance(inst instance.Instance, src instance.Instance, srcSnapshots []instance.Instance, allowInconsistent bool, op *operations.Operation) error
+	CreateInstanceFromLiveMove(inst instance.Instance, op *operations.Operation) (*MountInfo, revert.Hook, error)
+	DetachMovedInstance(inst instance.Instance, op *operations.Operation) error
+	DeleteMovedInstance(inst instance.Instance, op *operations.Operation) error
 
 	GetInstanceUsage(inst instance.Instance) (*VolumeUsage, error)
 	SetInstanceQuota(inst instance.Instance, size string, vmStateSize string, op *operations.Operation) error
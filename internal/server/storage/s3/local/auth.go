@@ -28,12 +28,12 @@ const (
 )
 
 // authenticate verifies the SigV4 signature on the request and returns the
-// matching credential's role on success, or an *s3.Error response on failure.
+// matching credential on success, or an *s3.Error response on failure.
 //
 // On success, r.Body is replaced with a buffered copy if the body's hash had
 // to be computed for verification. The caller must use r.Body, not the
 // original.
-func (s *Server) authenticate(r *http.Request) (Role, *s3.Error) {
+func (s *Server) authenticate(r *http.Request) (*Credential, *s3.Error) {
 	query := r.URL.Query()
 
 	// Handle pre-signed SigV4.
@@ -48,27 +48,27 @@ func (s *Server) authenticate(r *http.Request) (Role, *s3.Error) {
 
 	authHeader := r.Header.Get("Authorization")
 	if authHeader == "" {
-		return "", &s3.Error{Code: s3.ErrorCodeInvalidAccessKeyID, Message: "Missing Authorization header."}
+		return nil, &s3.Error{Code: s3.ErrorCodeInvalidAccessKeyID, Message: "Missing Authorization header."}
 	}
 
 	accessKey := s3.AuthorizationHeaderAccessKey(authHeader)
 	if accessKey == "" {
-		return "", &s3.Error{Code: s3.ErrorCodeInvalidAccessKeyID, Message: "Could not extract access key."}
+		return nil, &s3.Error{Code: s3.ErrorCodeInvalidAccessKeyID, Message: "Could not extract access key."}
 	}
 
-	secret, role, found := s.lookupCredential(accessKey)
-	if !found {
-		return "", &s3.Error{Code: s3.ErrorCodeInvalidAccessKeyID, Message: "Unknown access key."}
+	cred := s.lookupCredential(accessKey)
+	if cred == nil {
+		return nil, &s3.Error{Code: s3.ErrorCodeInvalidAccessKeyID, Message: "Unknown access key."}
 	}
 
 	parsed, err := parseAuthorizationHeader(authHeader)
 	if err != nil {
-		return "", &s3.Error{Code: s3.ErrorInvalidRequest, Message: err.Error()}
+		return nil, &s3.Error{Code: s3.ErrorInvalidRequest, Message: err.Error()}
 	}
 
 	parsed.amzDate = r.Header.Get("X-Amz-Date")
 	if parsed.amzDate == "" {
-		return "", &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Missing X-Amz-Date header."}
+		return nil, &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Missing X-Amz-Date header."}
 	}
 
 	// Resolve the body hash for the canonical request.
@@ -90,12 +90,12 @@ func (s *Server) authenticate(r *http.Request) (Role, *s3.Error) {
 			buf, readErr := io.ReadAll(r.Body)
 			_ = r.Body.Close()
 			if readErr != nil {
-				return "", &s3.Error{Code: s3.ErrorCodeInternalError, Message: "Failed to read request body."}
+				return nil, &s3.Error{Code: s3.ErrorCodeInternalError, Message: "Failed to read request body."}
 			}
 
 			actual := sha256Hex(buf)
 			if actual != bodyHash {
-				return "", &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Body hash mismatch."}
+				return nil, &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Body hash mismatch."}
 			}
 
 			r.Body = io.NopCloser(bytes.NewReader(buf))
@@ -115,45 +115,45 @@ func (s *Server) authenticate(r *http.Request) (Role, *s3.Error) {
 		sha256Hex([]byte(canonical)),
 	}, "\n")
 
-	signingKey := deriveSigningKey(secret, parsed.scopeDate, parsed.scopeRegion, parsed.scopeService)
+	signingKey := deriveSigningKey(cred.SecretKey, parsed.scopeDate, parsed.scopeRegion, parsed.scopeService)
 	expected := hmacSHA256Hex(signingKey, stringToSign)
 
 	if !hmac.Equal([]byte(expected), []byte(parsed.signature)) {
-		return "", &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Signature mismatch."}
+		return nil, &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Signature mismatch."}
 	}
 
 	if streaming && r.Body != nil && hasAWSChunkedEncoding(r) {
 		err := wrapStreamingBody(r, bodyHash, parsed, signingKey)
 		if err != nil {
-			return "", &s3.Error{Code: s3.ErrorInvalidRequest, Message: err.Error()}
+			return nil, &s3.Error{Code: s3.ErrorInvalidRequest, Message: err.Error()}
 		}
 	}
 
-	return role, nil
+	return cred, nil
 }
 
-func (s *Server) lookupCredential(accessKey string) (string, Role, bool) {
-	for _, c := range s.creds {
+func (s *Server) lookupCredential(accessKey string) *Credential {
+	for i, c := range s.creds {
 		if c.AccessKey == accessKey {
-			return c.SecretKey, c.Role, true
+			return &s.creds[i]
 		}
 	}
 
-	return "", "", false
+	return nil
 }
 
 // Handle pre-signed SigV4 request validation.
-func (s *Server) authenticatePresignedV4(r *http.Request) (Role, *s3.Error) {
+func (s *Server) authenticatePresignedV4(r *http.Request) (*Credential, *s3.Error) {
 	q := r.URL.Query()
 
 	algorithm := q.Get("X-Amz-Algorithm")
 	if algorithm != "AWS4-HMAC-SHA256" {
-		return "", &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Unsupported presigned signature algorithm."}
+		return nil, &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Unsupported presigned signature algorithm."}
 	}
 
 	credential := q.Get("X-Amz-Credential")
 	if credential == "" {
-		return "", &s3.Error{Code: s3.ErrorCodeInvalidAccessKeyID, Message: "Missing X-Amz-Credential."}
+		return nil, &s3.Error{Code: s3.ErrorCodeInvalidAccessKeyID, Message: "Missing X-Amz-Credential."}
 	}
 
 	// <accessKey>/<date>/<region>/<service>/aws4_request
@@ -161,7 +161,7 @@ func (s *Server) authenticatePresignedV4(r *http.Request) (Role, *s3.Error) {
 	// Access keys may contain "/" so do a reverse split.
 	fields := strings.Split(credential, "/")
 	if len(fields) < 5 {
-		return "", &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Malformed X-Amz-Credential."}
+		return nil, &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Malformed X-Amz-Credential."}
 	}
 
 	accessKey := strings.Join(fields[:len(fields)-4], "/")
@@ -170,42 +170,42 @@ func (s *Server) authenticatePresignedV4(r *http.Request) (Role, *s3.Error) {
 	scopeService := fields[len(fields)-2]
 	scope := strings.Join(fields[len(fields)-4:], "/")
 
-	secret, role, found := s.lookupCredential(accessKey)
-	if !found {
-		return "", &s3.Error{Code: s3.ErrorCodeInvalidAccessKeyID, Message: "Unknown access key."}
+	cred := s.lookupCredential(accessKey)
+	if cred == nil {
+		return nil, &s3.Error{Code: s3.ErrorCodeInvalidAccessKeyID, Message: "Unknown access key."}
 	}
 
 	amzDate := q.Get("X-Amz-Date")
 	if amzDate == "" {
-		return "", &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Missing X-Amz-Date."}
+		return nil, &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Missing X-Amz-Date."}
 	}
 
 	signedAt, err := time.Parse("20060102T150405Z", amzDate)
 	if err != nil {
-		return "", &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Invalid X-Amz-Date."}
+		return nil, &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Invalid X-Amz-Date."}
 	}
 
 	expiresStr := q.Get("X-Amz-Expires")
 	if expiresStr == "" {
-		return "", &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Missing X-Amz-Expires."}
+		return nil, &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Missing X-Amz-Expires."}
 	}
 
 	expires, err := strconv.Atoi(expiresStr)
 	if err != nil || expires <= 0 {
-		return "", &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Invalid X-Amz-Expires."}
+		return nil, &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Invalid X-Amz-Expires."}
 	}
 
 	if time.Duration(expires)*time.Second > 7*24*time.Hour {
-		return "", &s3.Error{Code: s3.ErrorInvalidRequest, Message: "X-Amz-Expires exceeds the maximum of 7 days."}
+		return nil, &s3.Error{Code: s3.ErrorInvalidRequest, Message: "X-Amz-Expires exceeds the maximum of 7 days."}
 	}
 
 	if time.Now().UTC().After(signedAt.Add(time.Duration(expires) * time.Second)) {
-		return "", &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Presigned URL has expired."}
+		return nil, &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Presigned URL has expired."}
 	}
 
 	signedHeaders := strings.Split(q.Get("X-Amz-SignedHeaders"), ";")
 	if len(signedHeaders) == 0 || signedHeaders[0] == "" {
-		return "", &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Missing X-Amz-SignedHeaders."}
+		return nil, &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Missing X-Amz-SignedHeaders."}
 	}
 
 	sort.Strings(signedHeaders)
@@ -230,14 +230,14 @@ func (s *Server) authenticatePresignedV4(r *http.Request) (Role, *s3.Error) {
 		sha256Hex([]byte(canonical)),
 	}, "\n")
 
-	signingKey := deriveSigningKey(secret, scopeDate, scopeRegion, scopeService)
+	signingKey := deriveSigningKey(cred.SecretKey, scopeDate, scopeRegion, scopeService)
 	expected := hmacSHA256Hex(signingKey, stringToSign)
 
 	if !hmac.Equal([]byte(expected), []byte(q.Get("X-Amz-Signature"))) {
-		return "", &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Signature mismatch."}
+		return nil, &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Signature mismatch."}
 	}
 
-	return role, nil
+	return cred, nil
 }
 
 var presignedV2ResourceSubresources = []string{
@@ -246,37 +246,37 @@ var presignedV2ResourceSubresources = []string{
 }
 
 // Handle pre-signed SigV2 request validation.
-func (s *Server) authenticatePresignedV2(r *http.Request) (Role, *s3.Error) {
+func (s *Server) authenticatePresignedV2(r *http.Request) (*Credential, *s3.Error) {
 	q := r.URL.Query()
 
 	accessKey := q.Get("AWSAccessKeyId")
 	if accessKey == "" {
-		return "", &s3.Error{Code: s3.ErrorCodeInvalidAccessKeyID, Message: "Missing AWSAccessKeyId."}
+		return nil, &s3.Error{Code: s3.ErrorCodeInvalidAccessKeyID, Message: "Missing AWSAccessKeyId."}
 	}
 
-	secret, role, found := s.lookupCredential(accessKey)
-	if !found {
-		return "", &s3.Error{Code: s3.ErrorCodeInvalidAccessKeyID, Message: "Unknown access key."}
+	cred := s.lookupCredential(accessKey)
+	if cred == nil {
+		return nil, &s3.Error{Code: s3.ErrorCodeInvalidAccessKeyID, Message: "Unknown access key."}
 	}
 
 	providedSignature := q.Get("Signature")
 	if providedSignature == "" {
-		return "", &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Missing Signature."}
+		return nil, &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Missing Signature."}
 	}
 
 	expiresStr := q.Get("Expires")
 	if expiresStr == "" {
-		return "", &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Missing Expires."}
+		return nil, &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Missing Expires."}
 	}
 
 	// Expires is an absolute Unix timestamp at which the URL stops being valid.
 	expires, err := strconv.ParseInt(expiresStr, 10, 64)
 	if err != nil {
-		return "", &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Invalid Expires."}
+		return nil, &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Invalid Expires."}
 	}
 
 	if time.Now().Unix() > expires {
-		return "", &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Presigned URL has expired."}
+		return nil, &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Presigned URL has expired."}
 	}
 
 	// Build the canonical resource: the URI-encoded path (which includes the
@@ -303,15 +303,15 @@ func (s *Server) authenticatePresignedV2(r *http.Request) (Role, *s3.Error) {
 		resource,
 	}, "\n")
 
-	mac := hmac.New(sha1.New, []byte(secret))
+	mac := hmac.New(sha1.New, []byte(cred.SecretKey))
 	_, _ = mac.Write([]byte(stringToSign))
 	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
 
 	if !hmac.Equal([]byte(expected), []byte(providedSignature)) {
-		return "", &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Signature mismatch."}
+		return nil, &s3.Error{Code: s3.ErrorInvalidRequest, Message: "Signature mismatch."}
 	}
 
-	return role, nil
+	return cred, nil
 }
 
 // hasAWSChunkedEncoding returns true if the request advertises an
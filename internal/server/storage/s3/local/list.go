@@ -171,7 +171,7 @@ func (s *Server) collectKeys() ([]string, error) {
 		}
 
 		if d.IsDir() {
-			if rel == uploadsSubdir {
+			if rel == uploadsSubdir || rel == versionsSubdir {
 				return filepath.SkipDir
 			}
 
@@ -23,6 +23,15 @@ type objectMeta struct {
 	Size        int64             `json:"size"`
 	LastMod     time.Time         `json:"last_modified"`
 	UserMeta    map[string]string `json:"user_meta,omitempty"`
+
+	// Owner is the name of the key the object was written with, its size counting towards the key quota.
+	Owner string `json:"owner,omitempty"`
+
+	// Versioning fields, only set on objects written to versioned buckets.
+	// Key is recorded on archived versions as their path doesn't contain it.
+	Key          string `json:"key,omitempty"`
+	VersionID    string `json:"version_id,omitempty"`
+	DeleteMarker bool   `json:"delete_marker,omitempty"`
 }
 
 func readMeta(metaPath string) (*objectMeta, error) {
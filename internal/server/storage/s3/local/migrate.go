@@ -101,6 +101,12 @@ func MigrateMinioBucket(bucketDir, bucketName string) error {
 		return fmt.Errorf("Failed archiving minio directory: %w", err)
 	}
 
+	// The migrated objects aren't accounted for in any cached usage.
+	err = os.Remove(filepath.Join(bucketDir, usageFile))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
 	return nil
 }
 
@@ -27,6 +27,7 @@ type uploadInfo struct {
 	ContentType string            `json:"content_type,omitempty"`
 	UserMeta    map[string]string `json:"user_meta,omitempty"`
 	Initiated   time.Time         `json:"initiated"`
+	Owner       string            `json:"owner,omitempty"`
 }
 
 // uploadsRoot opens the uploads directory as an os.Root, confining all
@@ -40,7 +41,7 @@ func (s *Server) uploadsRoot() (*os.Root, error) {
 	return os.OpenRoot(s.uploadsDir())
 }
 
-func (s *Server) initiateMultipartUpload(w http.ResponseWriter, r *http.Request, key string) {
+func (s *Server) initiateMultipartUpload(w http.ResponseWriter, r *http.Request, cred *Credential, key string) {
 	id := uuid.New().String()
 
 	root, err := s.uploadsRoot()
@@ -62,6 +63,7 @@ func (s *Server) initiateMultipartUpload(w http.ResponseWriter, r *http.Request,
 		ContentType: r.Header.Get("Content-Type"),
 		UserMeta:    extractUserMeta(r.Header),
 		Initiated:   time.Now().UTC(),
+		Owner:       cred.Name,
 	}
 
 	b, err := json.Marshal(info)
@@ -95,7 +97,7 @@ func (s *Server) initiateMultipartUpload(w http.ResponseWriter, r *http.Request,
 	_, _ = w.Write(resp)
 }
 
-func (s *Server) uploadPart(w http.ResponseWriter, r *http.Request, key, uploadID string) {
+func (s *Server) uploadPart(w http.ResponseWriter, r *http.Request, cred *Credential, key, uploadID string) {
 	root, err := s.uploadsRoot()
 	if err != nil {
 		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
@@ -125,6 +127,28 @@ func (s *Server) uploadPart(w http.ResponseWriter, r *http.Request, key, uploadI
 	partPath := filepath.Join(uploadID, fmt.Sprintf("part-%05d", partNumber))
 	tmp := partPath + ".tmp"
 
+	// Parts count towards the quotas, a re-uploaded part replacing the previous one.
+	var replaced int64
+
+	fi, err := root.Stat(partPath)
+	if err == nil {
+		replaced = fi.Size()
+	}
+
+	objectWriteMu.Lock()
+	_, allowance, err := s.remainingQuota(cred, 0, replaced, replaced)
+	objectWriteMu.Unlock()
+	if err != nil {
+		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
+		return
+	}
+
+	s3Err := checkAllowance(r.ContentLength, allowance)
+	if s3Err != nil {
+		s3Err.Response(w)
+		return
+	}
+
 	f, err := root.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
 	if err != nil {
 		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
@@ -132,7 +156,7 @@ func (s *Server) uploadPart(w http.ResponseWriter, r *http.Request, key, uploadI
 	}
 
 	hasher := md5.New()
-	_, err = io.Copy(io.MultiWriter(f, hasher), r.Body)
+	written, err := io.Copy(io.MultiWriter(f, hasher), limitReader(r.Body, allowance))
 	closeErr := f.Close()
 	if err != nil || closeErr != nil {
 		_ = root.Remove(tmp)
@@ -145,6 +169,25 @@ func (s *Server) uploadPart(w http.ResponseWriter, r *http.Request, key, uploadI
 		return
 	}
 
+	objectWriteMu.Lock()
+	defer objectWriteMu.Unlock()
+
+	_, allowance, err = s.remainingQuota(cred, 0, replaced, replaced)
+	if err != nil {
+		_ = root.Remove(tmp)
+		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
+		return
+	}
+
+	s3Err = checkAllowance(written, allowance)
+	if s3Err != nil {
+		_ = root.Remove(tmp)
+		s3Err.Response(w)
+		return
+	}
+
+	defer s.trackUploadUsage(root, uploadID)()
+
 	err = root.Rename(tmp, partPath)
 	if err != nil {
 		_ = root.Remove(tmp)
@@ -168,7 +211,7 @@ type completePart struct {
 	ETag       string `xml:"ETag"`
 }
 
-func (s *Server) completeMultipartUpload(w http.ResponseWriter, r *http.Request, key, uploadID string) {
+func (s *Server) completeMultipartUpload(w http.ResponseWriter, r *http.Request, cred *Credential, key, uploadID string) {
 	root, err := s.uploadsRoot()
 	if err != nil {
 		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
@@ -279,13 +322,25 @@ func (s *Server) completeMultipartUpload(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
-	err = os.Rename(tmp, dataPath)
+	// The parts get released once the object is published.
+	uploadUsage, err := s.uploadUsage(root, uploadID)
 	if err != nil {
 		_ = os.Remove(tmp)
 		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
 		return
 	}
 
+	allowance, s3Err := s.writeAllowance(cred, dataPath, uploadUsage.Bytes)
+	if s3Err == nil {
+		s3Err = checkAllowance(size, allowance)
+	}
+
+	if s3Err != nil {
+		_ = os.Remove(tmp)
+		s3Err.Response(w)
+		return
+	}
+
 	etag := hex.EncodeToString(combined.Sum(nil))
 	meta := &objectMeta{
 		ContentType: info.ContentType,
@@ -293,11 +348,16 @@ func (s *Server) completeMultipartUpload(w http.ResponseWriter, r *http.Request,
 		Size:        size,
 		LastMod:     time.Now().UTC(),
 		UserMeta:    info.UserMeta,
+		Owner:       info.Owner,
 	}
 
-	err = writeMeta(metaPathFor(dataPath), meta)
+	trackKey := s.trackKeyUsage(key)
+	trackUpload := s.trackUploadUsage(root, uploadID)
+
+	err = s.publishObject(key, dataPath, tmp, meta)
 	if err != nil {
-		_ = os.Remove(dataPath)
+		trackKey()
+		_ = os.Remove(tmp)
 		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
 		return
 	}
@@ -305,6 +365,9 @@ func (s *Server) completeMultipartUpload(w http.ResponseWriter, r *http.Request,
 	// Clean up the upload directory.
 	_ = root.RemoveAll(uploadID)
 
+	trackKey()
+	trackUpload()
+
 	type completeResult struct {
 		XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
 		Key     string   `xml:"Key"`
@@ -317,6 +380,7 @@ func (s *Server) completeMultipartUpload(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	writeVersionHeader(w, meta)
 	w.Header().Set("Content-Type", "application/xml")
 	w.WriteHeader(http.StatusOK)
 	_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>`))
@@ -332,6 +396,11 @@ func (s *Server) abortMultipartUpload(w http.ResponseWriter, key, uploadID strin
 
 	defer func() { _ = root.Close() }()
 
+	objectWriteMu.Lock()
+	defer objectWriteMu.Unlock()
+
+	defer s.trackUploadUsage(root, uploadID)()
+
 	err = root.RemoveAll(uploadID)
 	if err != nil && !errors.Is(err, fs.ErrNotExist) {
 		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
@@ -70,50 +70,52 @@ func (s *Server) objectPath(key string) (string, error) {
 	}
 
 	first, _, _ := strings.Cut(key, "/")
-	if first == uploadsSubdir || strings.HasSuffix(key, metaSuffix) {
+	if first == uploadsSubdir || first == versionsSubdir || strings.HasSuffix(key, metaSuffix) {
 		return "", errors.New("Reserved object key")
 	}
 
 	return filepath.Join(s.dataDir(), key), nil
 }
 
-func (s *Server) headObject(w http.ResponseWriter, r *http.Request, key string) {
+// lookupObject returns the data path and metadata of key, or of one of its
+// versions when versionID is set.
+func (s *Server) lookupObject(key string, versionID string) (string, *objectMeta, *s3.Error) {
 	dataPath, err := s.objectPath(key)
 	if err != nil {
-		(&s3.Error{Code: s3.ErrorInvalidRequest, Message: err.Error()}).Response(w)
-		return
+		return "", nil, &s3.Error{Code: s3.ErrorInvalidRequest, Message: err.Error()}
+	}
+
+	if versionID != "" {
+		return s.lookupObjectVersion(key, versionID)
 	}
 
 	meta, err := loadOrInferMeta(dataPath)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
-			(&s3.Error{Code: s3.ErrorCodeNoSuchBucket, Message: "Object not found."}).Response(w)
-			return
+			return "", nil, &s3.Error{Code: s3.ErrorCodeNoSuchBucket, Message: "Object not found."}
 		}
 
-		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
-		return
+		return "", nil, &s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}
 	}
 
-	writeObjectHeaders(w, meta)
-	w.WriteHeader(http.StatusOK)
+	return dataPath, meta, nil
 }
 
-func (s *Server) getObject(w http.ResponseWriter, r *http.Request, key string) {
-	dataPath, err := s.objectPath(key)
-	if err != nil {
-		(&s3.Error{Code: s3.ErrorInvalidRequest, Message: err.Error()}).Response(w)
+func (s *Server) headObject(w http.ResponseWriter, r *http.Request, key string) {
+	_, meta, s3Err := s.lookupObject(key, r.URL.Query().Get("versionId"))
+	if s3Err != nil {
+		s3Err.Response(w)
 		return
 	}
 
-	meta, err := loadOrInferMeta(dataPath)
-	if err != nil {
-		if errors.Is(err, fs.ErrNotExist) {
-			(&s3.Error{Code: s3.ErrorCodeNoSuchBucket, Message: "Object not found."}).Response(w)
-			return
-		}
+	writeObjectHeaders(w, meta)
+	w.WriteHeader(http.StatusOK)
+}
 
-		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
+func (s *Server) getObject(w http.ResponseWriter, r *http.Request, key string) {
+	dataPath, meta, s3Err := s.lookupObject(key, r.URL.Query().Get("versionId"))
+	if s3Err != nil {
+		s3Err.Response(w)
 		return
 	}
 
@@ -154,7 +156,7 @@ func (s *Server) getObject(w http.ResponseWriter, r *http.Request, key string) {
 	_, _ = io.CopyN(w, f, length)
 }
 
-func (s *Server) putObject(w http.ResponseWriter, r *http.Request, key string) {
+func (s *Server) putObject(w http.ResponseWriter, r *http.Request, cred *Credential, key string) {
 	dataPath, err := s.objectPath(key)
 	if err != nil {
 		(&s3.Error{Code: s3.ErrorInvalidRequest, Message: err.Error()}).Response(w)
@@ -168,6 +170,20 @@ func (s *Server) putObject(w http.ResponseWriter, r *http.Request, key string) {
 		return
 	}
 
+	// Same for writes exceeding the quotas, the body being cut short past the allowance.
+	objectWriteMu.Lock()
+	allowance, s3Err := s.writeAllowance(cred, dataPath, 0)
+	objectWriteMu.Unlock()
+
+	if s3Err == nil {
+		s3Err = checkAllowance(r.ContentLength, allowance)
+	}
+
+	if s3Err != nil {
+		s3Err.Response(w)
+		return
+	}
+
 	err = os.MkdirAll(filepath.Dir(dataPath), 0o700)
 	if err != nil {
 		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
@@ -182,7 +198,7 @@ func (s *Server) putObject(w http.ResponseWriter, r *http.Request, key string) {
 	}
 
 	hasher := md5.New()
-	written, err := io.Copy(io.MultiWriter(f, hasher), r.Body)
+	written, err := io.Copy(io.MultiWriter(f, hasher), limitReader(r.Body, allowance))
 	closeErr := f.Close()
 	if err != nil || closeErr != nil {
 		_ = os.Remove(tmp)
@@ -200,16 +216,17 @@ func (s *Server) putObject(w http.ResponseWriter, r *http.Request, key string) {
 	defer objectWriteMu.Unlock()
 
 	s3Err = checkWritePreconditions(r, dataPath)
-	if s3Err != nil {
-		_ = os.Remove(tmp)
-		s3Err.Response(w)
-		return
+	if s3Err == nil {
+		allowance, s3Err = s.writeAllowance(cred, dataPath, 0)
 	}
 
-	err = os.Rename(tmp, dataPath)
-	if err != nil {
+	if s3Err == nil {
+		s3Err = checkAllowance(written, allowance)
+	}
+
+	if s3Err != nil {
 		_ = os.Remove(tmp)
-		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
+		s3Err.Response(w)
 		return
 	}
 
@@ -221,15 +238,19 @@ func (s *Server) putObject(w http.ResponseWriter, r *http.Request, key string) {
 		Size:        written,
 		LastMod:     time.Now().UTC(),
 		UserMeta:    extractUserMeta(r.Header),
+		Owner:       cred.Name,
 	}
 
-	err = writeMeta(metaPathFor(dataPath), meta)
+	defer s.trackKeyUsage(key)()
+
+	err = s.publishObject(key, dataPath, tmp, meta)
 	if err != nil {
-		_ = os.Remove(dataPath)
+		_ = os.Remove(tmp)
 		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
 		return
 	}
 
+	writeVersionHeader(w, meta)
 	w.Header().Set("ETag", `"`+etag+`"`)
 	w.WriteHeader(http.StatusOK)
 }
@@ -240,33 +261,26 @@ func (s *Server) putObject(w http.ResponseWriter, r *http.Request, key string) {
 // The metadata directive defaults to COPY, which preserves the source
 // object's content-type and user metadata. REPLACE substitutes the values
 // supplied on the request.
-func (s *Server) copyObject(w http.ResponseWriter, r *http.Request, key string) {
-	srcKey, ok := parseCopySource(r.Header.Get("X-Amz-Copy-Source"))
+func (s *Server) copyObject(w http.ResponseWriter, r *http.Request, cred *Credential, key string) {
+	srcKey, srcVersionID, ok := parseCopySource(r.Header.Get("X-Amz-Copy-Source"))
 	if !ok {
 		(&s3.Error{Code: s3.ErrorInvalidRequest, Message: "Invalid X-Amz-Copy-Source header."}).Response(w)
 		return
 	}
 
-	srcPath, err := s.objectPath(srcKey)
-	if err != nil {
-		(&s3.Error{Code: s3.ErrorInvalidRequest, Message: err.Error()}).Response(w)
-		return
-	}
-
 	dstPath, err := s.objectPath(key)
 	if err != nil {
 		(&s3.Error{Code: s3.ErrorInvalidRequest, Message: err.Error()}).Response(w)
 		return
 	}
 
-	srcMeta, err := loadOrInferMeta(srcPath)
-	if err != nil {
-		if errors.Is(err, fs.ErrNotExist) {
-			(&s3.Error{Code: s3.ErrorCodeNoSuchBucket, Message: "Source object not found."}).Response(w)
-			return
+	srcPath, srcMeta, s3Err := s.lookupObject(srcKey, srcVersionID)
+	if s3Err != nil {
+		if s3Err.Code == s3.ErrorCodeNoSuchBucket {
+			s3Err.Message = "Source object not found."
 		}
 
-		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
+		s3Err.Response(w)
 		return
 	}
 
@@ -283,6 +297,19 @@ func (s *Server) copyObject(w http.ResponseWriter, r *http.Request, key string)
 
 	defer logger.WarnOnError(src.Close, "Failed to close source file")
 
+	objectWriteMu.Lock()
+	allowance, s3Err := s.writeAllowance(cred, dstPath, 0)
+	objectWriteMu.Unlock()
+
+	if s3Err == nil {
+		s3Err = checkAllowance(srcMeta.Size, allowance)
+	}
+
+	if s3Err != nil {
+		s3Err.Response(w)
+		return
+	}
+
 	err = os.MkdirAll(filepath.Dir(dstPath), 0o700)
 	if err != nil {
 		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
@@ -297,7 +324,7 @@ func (s *Server) copyObject(w http.ResponseWriter, r *http.Request, key string)
 	}
 
 	hasher := md5.New()
-	written, err := io.Copy(io.MultiWriter(f, hasher), src)
+	written, err := io.Copy(io.MultiWriter(f, hasher), limitReader(src, allowance))
 	closeErr := f.Close()
 	if err != nil || closeErr != nil {
 		_ = os.Remove(tmp)
@@ -310,13 +337,6 @@ func (s *Server) copyObject(w http.ResponseWriter, r *http.Request, key string)
 		return
 	}
 
-	err = os.Rename(tmp, dstPath)
-	if err != nil {
-		_ = os.Remove(tmp)
-		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
-		return
-	}
-
 	contentType := srcMeta.ContentType
 	userMeta := srcMeta.UserMeta
 	if strings.EqualFold(r.Header.Get("X-Amz-Metadata-Directive"), "REPLACE") {
@@ -332,11 +352,28 @@ func (s *Server) copyObject(w http.ResponseWriter, r *http.Request, key string)
 		Size:        written,
 		LastMod:     lastMod,
 		UserMeta:    userMeta,
+		Owner:       cred.Name,
+	}
+
+	objectWriteMu.Lock()
+	defer objectWriteMu.Unlock()
+
+	allowance, s3Err = s.writeAllowance(cred, dstPath, 0)
+	if s3Err == nil {
+		s3Err = checkAllowance(written, allowance)
 	}
 
-	err = writeMeta(metaPathFor(dstPath), meta)
+	if s3Err != nil {
+		_ = os.Remove(tmp)
+		s3Err.Response(w)
+		return
+	}
+
+	defer s.trackKeyUsage(key)()
+
+	err = s.publishObject(key, dstPath, tmp, meta)
 	if err != nil {
-		_ = os.Remove(dstPath)
+		_ = os.Remove(tmp)
 		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
 		return
 	}
@@ -356,39 +393,51 @@ func (s *Server) copyObject(w http.ResponseWriter, r *http.Request, key string)
 		return
 	}
 
+	if srcVersionID != "" {
+		w.Header().Set("X-Amz-Copy-Source-Version-Id", srcVersionID)
+	}
+
+	writeVersionHeader(w, meta)
 	w.Header().Set("Content-Type", "application/xml")
 	w.WriteHeader(http.StatusOK)
 	_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>`))
 	_, _ = w.Write(resp)
 }
 
-// parseCopySource extracts the source object key from an X-Amz-Copy-Source
-// header value. The value has the form "[/]bucket/key" with the key
-// optionally percent-encoded and an optional "?versionId=..." suffix.
-func parseCopySource(v string) (string, bool) {
+// parseCopySource extracts the source object key and version from an
+// X-Amz-Copy-Source header value. The value has the form "[/]bucket/key"
+// with the key optionally percent-encoded and an optional "?versionId=..."
+// suffix.
+func parseCopySource(v string) (string, string, bool) {
 	if v == "" {
-		return "", false
+		return "", "", false
 	}
 
-	// Drop the optional version-id query suffix.
-	idx := strings.Index(v, "?")
-	if idx >= 0 {
-		v = v[:idx]
+	// Split off the optional version-id query suffix.
+	versionID := ""
+	v, query, found := strings.Cut(v, "?")
+	if found {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			return "", "", false
+		}
+
+		versionID = values.Get("versionId")
 	}
 
 	decoded, err := url.PathUnescape(v)
 	if err != nil {
-		return "", false
+		return "", "", false
 	}
 
 	decoded = strings.TrimPrefix(decoded, "/")
 
 	_, key, ok := strings.Cut(decoded, "/")
 	if !ok || key == "" {
-		return "", false
+		return "", "", false
 	}
 
-	return key, true
+	return key, versionID, true
 }
 
 // handleObjectACL stubs the object-level ?acl sub-resource.
@@ -429,13 +478,38 @@ func (s *Server) handleObjectACL(w http.ResponseWriter, r *http.Request, key str
 	}
 }
 
-func (s *Server) deleteObject(w http.ResponseWriter, key string) {
+func (s *Server) deleteObject(w http.ResponseWriter, r *http.Request, key string) {
+	versionID := r.URL.Query().Get("versionId")
+	if versionID != "" {
+		s.deleteObjectVersion(w, key, versionID)
+		return
+	}
+
 	dataPath, err := s.objectPath(key)
 	if err != nil {
 		(&s3.Error{Code: s3.ErrorInvalidRequest, Message: err.Error()}).Response(w)
 		return
 	}
 
+	objectWriteMu.Lock()
+	defer objectWriteMu.Unlock()
+
+	defer s.trackKeyUsage(key)()
+
+	// Versioned buckets retain the object behind a delete marker.
+	if s.versioning() != VersioningDisabled {
+		markerID, err := s.createDeleteMarker(key, dataPath)
+		if err != nil {
+			(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
+			return
+		}
+
+		w.Header().Set("X-Amz-Delete-Marker", "true")
+		w.Header().Set("X-Amz-Version-Id", markerID)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
 	err = os.Remove(dataPath)
 	if err != nil && !errors.Is(err, fs.ErrNotExist) {
 		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
@@ -462,6 +536,15 @@ func writeObjectHeaders(w http.ResponseWriter, meta *objectMeta) {
 	for k, v := range meta.UserMeta {
 		w.Header().Set("X-Amz-Meta-"+k, v)
 	}
+
+	writeVersionHeader(w, meta)
+}
+
+// writeVersionHeader sets the version ID header for objects of versioned buckets.
+func writeVersionHeader(w http.ResponseWriter, meta *objectMeta) {
+	if meta.VersionID != "" {
+		w.Header().Set("X-Amz-Version-Id", meta.VersionID)
+	}
 }
 
 func extractUserMeta(h http.Header) map[string]string {
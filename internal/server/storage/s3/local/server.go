@@ -9,15 +9,20 @@
 //	data/<key>           object data
 //	data/<key>.meta      object metadata (JSON)
 //	data/.uploads/<id>/  in-flight multipart upload state
+//	data/.versions/<h>/  non-current versions and delete markers of the key hashing to h
+//	usage.json           cached usage of the bucket, used to enforce quotas
 package local
 
 import (
+	"fmt"
 	"net/http"
 	"net/url"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/lxc/incus/v7/internal/server/storage/s3"
+	"github.com/lxc/incus/v7/shared/units"
 )
 
 const (
@@ -38,9 +43,24 @@ const (
 
 // Credential is an S3 access-key / secret-key pair authorised against the bucket.
 type Credential struct {
+	// Name identifies the key owning the objects written with the credential.
+	Name string
+
 	AccessKey string
 	SecretKey string
 	Role      Role
+
+	// Quota is the maximum size in bytes of the objects owned by the key, 0 meaning unlimited.
+	Quota int64
+}
+
+// Quota holds the limits of a bucket, 0 meaning unlimited.
+type Quota struct {
+	// Size is the maximum size in bytes of the stored data, including object versions and multipart uploads.
+	Size int64
+
+	// Objects is the maximum number of objects.
+	Objects int64
 }
 
 // Server serves S3 requests for a single bucket directory.
@@ -53,6 +73,20 @@ type Server struct {
 	// Errors are returned to the client as an internal-error response and
 	// dispatch is aborted.
 	OnAuthenticated func() error
+
+	// Versioning is the configured versioning state of the bucket.
+	Versioning Versioning
+
+	// Lifecycle holds the expiration rules of the bucket.
+	Lifecycle LifecycleRules
+
+	// Quota holds the limits of the bucket.
+	Quota Quota
+
+	// OnVersioningChange, if set, is invoked to persist a versioning state
+	// change requested through PutBucketVersioning. When unset, such
+	// requests are rejected.
+	OnVersioningChange func(status Versioning) error
 }
 
 // NewServer returns a Server rooted at bucketDir.
@@ -70,6 +104,63 @@ func NewServer(bucketDir string, creds []Credential) *Server {
 	}
 }
 
+// SetConfig applies the versioning, lifecycle and quota settings of an Incus bucket configuration.
+func (s *Server) SetConfig(config map[string]string) error {
+	switch config["versioning"] {
+	case "enabled":
+		s.Versioning = VersioningEnabled
+	case "suspended":
+		s.Versioning = VersioningSuspended
+	case "":
+		s.Versioning = VersioningDisabled
+	default:
+		return fmt.Errorf("Invalid versioning state %q", config["versioning"])
+	}
+
+	s.Lifecycle = LifecycleRules{Prefix: config["lifecycle.prefix"]}
+
+	days := map[string]*int{
+		"lifecycle.expiration.days":            &s.Lifecycle.ExpirationDays,
+		"lifecycle.noncurrent_expiration.days": &s.Lifecycle.NoncurrentExpirationDays,
+		"lifecycle.abort_multipart.days":       &s.Lifecycle.AbortMultipartDays,
+	}
+
+	for key, target := range days {
+		if config[key] == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(config[key])
+		if err != nil {
+			return fmt.Errorf("Invalid value for %q: %w", key, err)
+		}
+
+		*target = n
+	}
+
+	s.Quota = Quota{}
+
+	if config["quota.size"] != "" {
+		size, err := units.ParseByteSizeString(config["quota.size"])
+		if err != nil {
+			return fmt.Errorf("Invalid value for %q: %w", "quota.size", err)
+		}
+
+		s.Quota.Size = size
+	}
+
+	if config["quota.objects"] != "" {
+		objects, err := strconv.ParseInt(config["quota.objects"], 10, 64)
+		if err != nil {
+			return fmt.Errorf("Invalid value for %q: %w", "quota.objects", err)
+		}
+
+		s.Quota.Objects = objects
+	}
+
+	return nil
+}
+
 func (s *Server) dataDir() string {
 	return filepath.Join(s.bucketDir, dataSubdir)
 }
@@ -85,7 +176,7 @@ func (s *Server) uploadsDir() string {
 // already. Routing happens on the remainder of the path.
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Authenticate the request before any I/O.
-	role, authErr := s.authenticate(r)
+	cred, authErr := s.authenticate(r)
 	if authErr != nil {
 		authErr.Response(w)
 		return
@@ -99,7 +190,7 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		objectKey = ""
 	}
 
-	if !methodAllowedForRole(r.Method, role, objectKey, r.URL.Query()) {
+	if !methodAllowedForRole(r.Method, cred.Role, objectKey, r.URL.Query()) {
 		(&s3.Error{
 			Code:    s3.ErrorInvalidRequest,
 			Message: "Operation not permitted by credential role.",
@@ -120,7 +211,7 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.handleObject(w, r, objectKey)
+	s.handleObject(w, r, cred, objectKey)
 }
 
 func methodAllowedForRole(method string, role Role, objectKey string, q url.Values) bool {
@@ -171,10 +262,34 @@ func (s *Server) handleBucket(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		_, ok = q["versions"]
+		if ok {
+			s.listObjectVersions(w, r)
+			return
+		}
+
+		_, ok = q["lifecycle"]
+		if ok {
+			s.getBucketLifecycle(w)
+			return
+		}
+
 		s.listObjects(w, r)
 	case http.MethodHead:
 		// Bucket exist if we made it this far.
 		w.WriteHeader(http.StatusOK)
+	case http.MethodPut:
+		_, ok := r.URL.Query()["versioning"]
+		if ok {
+			s.putBucketVersioning(w, r)
+			return
+		}
+
+		// Lifecycle rules and the bucket itself are managed through the Incus API.
+		(&s3.Error{
+			Code:    s3.ErrorInvalidRequest,
+			Message: "Bucket lifecycle is managed by the Incus API.",
+		}).Response(w)
 	default:
 		// We don't allow bucket creation/deletion.
 		(&s3.Error{
@@ -184,22 +299,11 @@ func (s *Server) handleBucket(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// getBucketVersioning stubs the bucket-level ?versioning sub-resource.
-// Versioning is never enabled, so return an empty configuration.
-func (s *Server) getBucketVersioning(w http.ResponseWriter) {
-	const body = `<?xml version="1.0" encoding="UTF-8"?>` +
-		`<VersioningConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"></VersioningConfiguration>`
-
-	w.Header().Set("Content-Type", "application/xml")
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write([]byte(body))
-}
-
-func (s *Server) handleObject(w http.ResponseWriter, r *http.Request, objectKey string) {
+func (s *Server) handleObject(w http.ResponseWriter, r *http.Request, cred *Credential, objectKey string) {
 	q := r.URL.Query()
 	_, ok := q["uploads"]
 	if ok && r.Method == http.MethodPost {
-		s.initiateMultipartUpload(w, r, objectKey)
+		s.initiateMultipartUpload(w, r, cred, objectKey)
 		return
 	}
 
@@ -207,9 +311,9 @@ func (s *Server) handleObject(w http.ResponseWriter, r *http.Request, objectKey
 	if uploadID != "" {
 		switch r.Method {
 		case http.MethodPut:
-			s.uploadPart(w, r, objectKey, uploadID)
+			s.uploadPart(w, r, cred, objectKey, uploadID)
 		case http.MethodPost:
-			s.completeMultipartUpload(w, r, objectKey, uploadID)
+			s.completeMultipartUpload(w, r, cred, objectKey, uploadID)
 		case http.MethodDelete:
 			s.abortMultipartUpload(w, objectKey, uploadID)
 		default:
@@ -233,13 +337,13 @@ func (s *Server) handleObject(w http.ResponseWriter, r *http.Request, objectKey
 		s.headObject(w, r, objectKey)
 	case http.MethodPut:
 		if r.Header.Get("X-Amz-Copy-Source") != "" {
-			s.copyObject(w, r, objectKey)
+			s.copyObject(w, r, cred, objectKey)
 			return
 		}
 
-		s.putObject(w, r, objectKey)
+		s.putObject(w, r, cred, objectKey)
 	case http.MethodDelete:
-		s.deleteObject(w, objectKey)
+		s.deleteObject(w, r, objectKey)
 	default:
 		(&s3.Error{Code: s3.ErrorInvalidRequest, Message: "Unsupported method."}).Response(w)
 	}
@@ -0,0 +1,442 @@
+package local
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lxc/incus/v7/internal/server/storage/s3"
+)
+
+// usageFile caches the usage of the bucket, so that quotas can be enforced without walking the bucket.
+const usageFile = "usage.json"
+
+// Usage is the storage used by a bucket or by the objects owned by one of its keys.
+type Usage struct {
+	// Objects is the number of current objects.
+	Objects int64 `json:"objects"`
+
+	// Bytes is the size of the stored data, including non-current versions and multipart upload parts.
+	Bytes int64 `json:"bytes"`
+}
+
+// bucketUsage is the usage of a bucket along with the usage of each key owning objects.
+type bucketUsage struct {
+	Usage
+
+	Keys map[string]Usage `json:"keys,omitempty"`
+}
+
+// add accounts for objects and bytes owned by the named key (if any).
+func (u *bucketUsage) add(owner string, objects int64, bytes int64) {
+	u.Objects += objects
+	u.Bytes += bytes
+
+	u.addKey(owner, objects, bytes)
+}
+
+func (u *bucketUsage) addKey(owner string, objects int64, bytes int64) {
+	if owner == "" {
+		return
+	}
+
+	if u.Keys == nil {
+		u.Keys = map[string]Usage{}
+	}
+
+	keyUsage := u.Keys[owner]
+	keyUsage.Objects += objects
+	keyUsage.Bytes += bytes
+
+	if keyUsage == (Usage{}) {
+		delete(u.Keys, owner)
+		return
+	}
+
+	u.Keys[owner] = keyUsage
+}
+
+// merge adds the usage of other multiplied by sign.
+func (u *bucketUsage) merge(other *bucketUsage, sign int64) {
+	u.Objects += sign * other.Objects
+	u.Bytes += sign * other.Bytes
+
+	for owner, keyUsage := range other.Keys {
+		u.addKey(owner, sign*keyUsage.Objects, sign*keyUsage.Bytes)
+	}
+}
+
+func (s *Server) usagePath() string {
+	return filepath.Join(s.bucketDir, usageFile)
+}
+
+// Usage walks the bucket to compute its usage and the usage of each key, by key name.
+// The result refreshes the cached usage used to enforce quotas.
+func (s *Server) Usage() (Usage, map[string]Usage, error) {
+	objectWriteMu.Lock()
+	defer objectWriteMu.Unlock()
+
+	u, err := s.computeUsage()
+	if err != nil {
+		return Usage{}, nil, err
+	}
+
+	err = s.saveUsage(u)
+	if err != nil {
+		return Usage{}, nil, err
+	}
+
+	return u.Usage, u.Keys, nil
+}
+
+// readUsage returns the cached usage of the bucket, or nil if there's none.
+func (s *Server) readUsage() (*bucketUsage, error) {
+	b, err := os.ReadFile(s.usagePath())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	u := &bucketUsage{}
+	err = json.Unmarshal(b, u)
+	if err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+func (s *Server) saveUsage(u *bucketUsage) error {
+	b, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+
+	tmp := s.usagePath() + ".tmp"
+	err = os.WriteFile(tmp, b, 0o600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.usagePath())
+}
+
+// loadUsage returns the cached usage of the bucket, computing it if needed.
+// Must be called with objectWriteMu held.
+func (s *Server) loadUsage() (*bucketUsage, error) {
+	u, err := s.readUsage()
+	if err == nil && u != nil {
+		return u, nil
+	}
+
+	u, err = s.computeUsage()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.saveUsage(u)
+	if err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// invalidateUsage drops the cached usage, so that it gets computed again when next needed.
+func (s *Server) invalidateUsage() {
+	_ = os.Remove(s.usagePath())
+}
+
+// computeUsage walks the bucket to compute its usage.
+func (s *Server) computeUsage() (*bucketUsage, error) {
+	u := &bucketUsage{}
+
+	err := filepath.WalkDir(s.dataDir(), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+
+			return err
+		}
+
+		if entry.IsDir() {
+			if path == s.uploadsDir() {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		if strings.HasSuffix(path, metaSuffix) {
+			return nil
+		}
+
+		// Skip the temporary files of in-flight writes, which have no metadata.
+		meta, err := readMeta(metaPathFor(path))
+		if errors.Is(err, fs.ErrNotExist) && strings.HasSuffix(path, ".tmp") {
+			return nil
+		}
+
+		if err != nil {
+			meta, err = loadOrInferMeta(path)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Archived versions use space but aren't objects on their own.
+		objects := int64(1)
+		if strings.HasPrefix(path, s.versionsDir()+string(filepath.Separator)) {
+			objects = 0
+		}
+
+		u.add(meta.Owner, objects, meta.Size)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(s.uploadsDir())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		return u, nil
+	}
+
+	root, err := s.uploadsRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = root.Close() }()
+
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+
+		uploadUsage, err := s.uploadUsage(root, e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		u.merge(uploadUsage, 1)
+	}
+
+	return u, nil
+}
+
+// keyUsage returns the usage of the current and archived versions of key.
+func (s *Server) keyUsage(key string) (*bucketUsage, error) {
+	versions, err := s.objectVersions(key)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &bucketUsage{}
+
+	for _, v := range versions {
+		if v.meta.DeleteMarker {
+			continue
+		}
+
+		objects := int64(0)
+		if v.current {
+			objects = 1
+		}
+
+		u.add(v.meta.Owner, objects, v.meta.Size)
+	}
+
+	return u, nil
+}
+
+// uploadUsage returns the usage of the parts of the multipart upload with the given ID.
+func (s *Server) uploadUsage(root *os.Root, uploadID string) (*bucketUsage, error) {
+	u := &bucketUsage{}
+
+	entries, err := fs.ReadDir(root.FS(), uploadID)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return u, nil
+		}
+
+		return nil, err
+	}
+
+	info := &uploadInfo{}
+
+	b, err := root.ReadFile(filepath.Join(uploadID, "upload.json"))
+	if err == nil {
+		_ = json.Unmarshal(b, info)
+	}
+
+	for _, e := range entries {
+		if !strings.HasPrefix(e.Name(), "part-") || strings.HasSuffix(e.Name(), ".tmp") {
+			continue
+		}
+
+		fi, err := e.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		u.add(info.Owner, 0, fi.Size())
+	}
+
+	return u, nil
+}
+
+// trackUsage measures the usage of part of the bucket and returns a function applying its change since then to
+// the cached usage of the bucket. objectWriteMu must be held until the returned function is called.
+func (s *Server) trackUsage(measure func() (*bucketUsage, error)) func() {
+	before, err := measure()
+
+	return func() {
+		if err != nil {
+			s.invalidateUsage()
+			return
+		}
+
+		after, err := measure()
+		if err != nil {
+			s.invalidateUsage()
+			return
+		}
+
+		// Without a cached usage, it gets computed when next needed.
+		u, err := s.readUsage()
+		if err != nil || u == nil {
+			s.invalidateUsage()
+			return
+		}
+
+		u.merge(after, 1)
+		u.merge(before, -1)
+
+		err = s.saveUsage(u)
+		if err != nil {
+			s.invalidateUsage()
+		}
+	}
+}
+
+// trackKeyUsage tracks the change in the usage of the versions of key (see trackUsage).
+func (s *Server) trackKeyUsage(key string) func() {
+	return s.trackUsage(func() (*bucketUsage, error) { return s.keyUsage(key) })
+}
+
+// trackUploadUsage tracks the change in the usage of the parts of a multipart upload (see trackUsage).
+func (s *Server) trackUploadUsage(root *os.Root, uploadID string) func() {
+	return s.trackUsage(func() (*bucketUsage, error) { return s.uploadUsage(root, uploadID) })
+}
+
+// hasQuota returns whether writes with cred are subject to a quota.
+func (s *Server) hasQuota(cred *Credential) bool {
+	return s.Quota != (Quota{}) || (cred != nil && cred.Quota > 0)
+}
+
+// remainingQuota returns how many objects and bytes can still be added with cred, math.MaxInt64 meaning unlimited.
+// freedBytes and freedKeyBytes are the bytes released by the write from the bucket and from the key.
+// Must be called with objectWriteMu held.
+func (s *Server) remainingQuota(cred *Credential, freedObjects int64, freedBytes int64, freedKeyBytes int64) (int64, int64, error) {
+	objects := int64(math.MaxInt64)
+	bytes := int64(math.MaxInt64)
+
+	if !s.hasQuota(cred) {
+		return objects, bytes, nil
+	}
+
+	u, err := s.loadUsage()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if s.Quota.Objects > 0 {
+		objects = s.Quota.Objects - u.Objects + freedObjects
+	}
+
+	if s.Quota.Size > 0 {
+		bytes = s.Quota.Size - u.Bytes + freedBytes
+	}
+
+	if cred != nil && cred.Quota > 0 {
+		bytes = min(bytes, cred.Quota-u.Keys[cred.Name].Bytes+freedKeyBytes)
+	}
+
+	return objects, max(bytes, 0), nil
+}
+
+// writeAllowance returns how many bytes can be written to the object at dataPath with cred, or a QuotaExceeded
+// error when no more objects can be created. freed is the number of bytes released by the write besides the
+// object it replaces.
+// Must be called with objectWriteMu held.
+func (s *Server) writeAllowance(cred *Credential, dataPath string, freed int64) (int64, *s3.Error) {
+	if !s.hasQuota(cred) {
+		return math.MaxInt64, nil
+	}
+
+	var freedObjects, freedBytes, freedKeyBytes int64
+
+	current, err := loadOrInferMeta(dataPath)
+	if err == nil {
+		freedObjects = 1
+
+		// Unless versioning is enabled, the current object gets replaced.
+		if s.versioning() == VersioningDisabled {
+			freedBytes = current.Size
+			if cred != nil && current.Owner == cred.Name {
+				freedKeyBytes = current.Size
+			}
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return 0, &s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}
+	}
+
+	objects, bytes, err := s.remainingQuota(cred, freedObjects, freedBytes+freed, freedKeyBytes+freed)
+	if err != nil {
+		return 0, &s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}
+	}
+
+	if objects < 1 {
+		return 0, &s3.Error{Code: s3.ErrorCodeQuotaExceeded, Message: "Bucket object quota exceeded."}
+	}
+
+	return bytes, nil
+}
+
+// checkAllowance returns a QuotaExceeded error if size exceeds the allowance.
+func checkAllowance(size int64, allowance int64) *s3.Error {
+	if size > allowance {
+		return &s3.Error{Code: s3.ErrorCodeQuotaExceeded, Message: "Bucket or key size quota exceeded."}
+	}
+
+	return nil
+}
+
+// limitReader limits r to one byte past allowance, so that bodies exceeding it get detected without being
+// fully written to disk.
+func limitReader(r io.Reader, allowance int64) io.Reader {
+	if allowance == math.MaxInt64 {
+		return r
+	}
+
+	return io.LimitReader(r, allowance+1)
+}
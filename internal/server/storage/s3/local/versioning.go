@@ -0,0 +1,704 @@
+package local
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lxc/incus/v7/internal/server/storage/s3"
+	"github.com/lxc/incus/v7/shared/util"
+)
+
+// versionsSubdir holds the non-current versions and delete markers of objects.
+const versionsSubdir = ".versions"
+
+// nullVersionID is the version ID of objects written while versioning isn't enabled.
+const nullVersionID = "null"
+
+// Versioning is the versioning state of a bucket.
+type Versioning string
+
+const (
+	// VersioningDisabled means versioning was never enabled on the bucket.
+	VersioningDisabled Versioning = ""
+
+	// VersioningEnabled means every write creates a new object version.
+	VersioningEnabled Versioning = "Enabled"
+
+	// VersioningSuspended means writes replace the null version while existing versions are retained.
+	VersioningSuspended Versioning = "Suspended"
+)
+
+// objectVersion is a single version of an object, either current or archived.
+type objectVersion struct {
+	meta     *objectMeta
+	dataPath string
+	current  bool
+}
+
+func (s *Server) versionsDir() string {
+	return filepath.Join(s.dataDir(), versionsSubdir)
+}
+
+// keyVersionsDir returns the directory holding the archived versions of key.
+// Keys are hashed so that nested keys can't collide with version files.
+func (s *Server) keyVersionsDir(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.versionsDir(), hex.EncodeToString(sum[:]))
+}
+
+// versioning returns the effective versioning state of the bucket.
+// A bucket which holds archived versions can't go back to being unversioned,
+// so it is treated as suspended when versioning was since turned off.
+func (s *Server) versioning() Versioning {
+	if s.Versioning != VersioningDisabled {
+		return s.Versioning
+	}
+
+	if util.PathExists(s.versionsDir()) {
+		return VersioningSuspended
+	}
+
+	return VersioningDisabled
+}
+
+func newVersionID() (string, error) {
+	// Version 7 UUIDs are time ordered, which keeps listings stable.
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+// archivedVersions returns the archived versions of key, newest first.
+func (s *Server) archivedVersions(key string) ([]objectVersion, error) {
+	dir := s.keyVersionsDir(key)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	versions := []objectVersion{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), metaSuffix) {
+			continue
+		}
+
+		meta, err := readMeta(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		versions = append(versions, objectVersion{
+			meta:     meta,
+			dataPath: filepath.Join(dir, strings.TrimSuffix(e.Name(), metaSuffix)),
+		})
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		if versions[i].meta.LastMod.Equal(versions[j].meta.LastMod) {
+			// The null version predates any generated version ID.
+			if versions[i].meta.VersionID == nullVersionID || versions[j].meta.VersionID == nullVersionID {
+				return versions[j].meta.VersionID == nullVersionID
+			}
+
+			return versions[i].meta.VersionID > versions[j].meta.VersionID
+		}
+
+		return versions[i].meta.LastMod.After(versions[j].meta.LastMod)
+	})
+
+	return versions, nil
+}
+
+// objectVersions returns all versions of key, newest first. The current
+// version, when there is one, is always the newest.
+func (s *Server) objectVersions(key string) ([]objectVersion, error) {
+	dataPath, err := s.objectPath(key)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := []objectVersion{}
+
+	meta, err := loadOrInferMeta(dataPath)
+	if err == nil {
+		if meta.VersionID == "" {
+			meta.VersionID = nullVersionID
+		}
+
+		versions = append(versions, objectVersion{meta: meta, dataPath: dataPath, current: true})
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	archived, err := s.archivedVersions(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(versions, archived...), nil
+}
+
+// archiveCurrent moves the current version of key to the versions directory.
+// When dropNull is set, a current null version is removed instead, as S3
+// replaces the null version on writes to suspended buckets.
+// Must be called with objectWriteMu held.
+func (s *Server) archiveCurrent(key string, dataPath string, dropNull bool) error {
+	meta, err := loadOrInferMeta(dataPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	if meta.VersionID == "" {
+		meta.VersionID = nullVersionID
+	}
+
+	if dropNull && meta.VersionID == nullVersionID {
+		err = os.Remove(dataPath)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		return removeMeta(metaPathFor(dataPath))
+	}
+
+	dir := s.keyVersionsDir(key)
+	err = os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return err
+	}
+
+	target := filepath.Join(dir, meta.VersionID)
+	err = os.Rename(dataPath, target)
+	if err != nil {
+		return err
+	}
+
+	meta.Key = key
+	err = writeMeta(metaPathFor(target), meta)
+	if err != nil {
+		return err
+	}
+
+	return removeMeta(metaPathFor(dataPath))
+}
+
+// removeArchivedVersion permanently removes an archived version of key.
+// Must be called with objectWriteMu held.
+func (s *Server) removeArchivedVersion(key string, versionID string) error {
+	// Version IDs are generated by us, but also come from requests.
+	if versionID == "" || strings.ContainsAny(versionID, `/\`) || versionID == "." || versionID == ".." {
+		return nil
+	}
+
+	dataPath := filepath.Join(s.keyVersionsDir(key), versionID)
+	err := os.Remove(dataPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	err = removeMeta(metaPathFor(dataPath))
+	if err != nil {
+		return err
+	}
+
+	// Drop the directory once the last version is gone.
+	_ = os.Remove(s.keyVersionsDir(key))
+
+	return nil
+}
+
+// promoteLatest restores the newest archived version of key as the current
+// one after the current version was removed. Nothing is restored when the
+// newest version is a delete marker.
+// Must be called with objectWriteMu held.
+func (s *Server) promoteLatest(key string, dataPath string) error {
+	if util.PathExists(dataPath) {
+		return nil
+	}
+
+	archived, err := s.archivedVersions(key)
+	if err != nil {
+		return err
+	}
+
+	if len(archived) == 0 || archived[0].meta.DeleteMarker {
+		return nil
+	}
+
+	latest := archived[0]
+
+	err = os.MkdirAll(filepath.Dir(dataPath), 0o700)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(latest.dataPath, dataPath)
+	if err != nil {
+		return err
+	}
+
+	latest.meta.Key = ""
+	err = writeMeta(metaPathFor(dataPath), latest.meta)
+	if err != nil {
+		return err
+	}
+
+	return s.removeArchivedVersion(key, latest.meta.VersionID)
+}
+
+// publishObject moves a fully written temporary file into place as the
+// current version of key, retaining the previous version as needed by the
+// bucket's versioning state.
+// Must be called with objectWriteMu held.
+func (s *Server) publishObject(key string, dataPath string, tmp string, meta *objectMeta) error {
+	switch s.versioning() {
+	case VersioningEnabled:
+		versionID, err := newVersionID()
+		if err != nil {
+			return err
+		}
+
+		err = s.archiveCurrent(key, dataPath, false)
+		if err != nil {
+			return err
+		}
+
+		meta.VersionID = versionID
+	case VersioningSuspended:
+		err := s.archiveCurrent(key, dataPath, true)
+		if err != nil {
+			return err
+		}
+
+		err = s.removeArchivedVersion(key, nullVersionID)
+		if err != nil {
+			return err
+		}
+
+		meta.VersionID = nullVersionID
+	}
+
+	err := os.Rename(tmp, dataPath)
+	if err != nil {
+		_ = s.promoteLatest(key, dataPath)
+		return err
+	}
+
+	err = writeMeta(metaPathFor(dataPath), meta)
+	if err != nil {
+		_ = os.Remove(dataPath)
+		_ = s.promoteLatest(key, dataPath)
+		return err
+	}
+
+	return nil
+}
+
+// createDeleteMarker hides the current version of key behind a new delete
+// marker and returns the marker's version ID.
+// Must be called with objectWriteMu held.
+func (s *Server) createDeleteMarker(key string, dataPath string) (string, error) {
+	versionID := nullVersionID
+
+	if s.versioning() == VersioningEnabled {
+		var err error
+		versionID, err = newVersionID()
+		if err != nil {
+			return "", err
+		}
+
+		err = s.archiveCurrent(key, dataPath, false)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		err := s.archiveCurrent(key, dataPath, true)
+		if err != nil {
+			return "", err
+		}
+
+		err = s.removeArchivedVersion(key, nullVersionID)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	dir := s.keyVersionsDir(key)
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return "", err
+	}
+
+	marker := &objectMeta{
+		Key:          key,
+		VersionID:    versionID,
+		DeleteMarker: true,
+		LastMod:      time.Now().UTC(),
+	}
+
+	err = writeMeta(metaPathFor(filepath.Join(dir, versionID)), marker)
+	if err != nil {
+		return "", err
+	}
+
+	return versionID, nil
+}
+
+// lookupObjectVersion returns the data path and metadata of a specific version of key.
+func (s *Server) lookupObjectVersion(key string, versionID string) (string, *objectMeta, *s3.Error) {
+	versions, err := s.objectVersions(key)
+	if err != nil {
+		return "", nil, &s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}
+	}
+
+	for _, v := range versions {
+		if v.meta.VersionID != versionID {
+			continue
+		}
+
+		if v.meta.DeleteMarker {
+			return "", nil, &s3.Error{Code: s3.ErrorCodeMethodNotAllowed, Message: "The specified method is not allowed against a delete marker."}
+		}
+
+		return v.dataPath, v.meta, nil
+	}
+
+	return "", nil, &s3.Error{Code: s3.ErrorCodeNoSuchVersion, Message: "The specified version does not exist."}
+}
+
+// deleteObjectVersion permanently removes a specific version of key.
+func (s *Server) deleteObjectVersion(w http.ResponseWriter, key string, versionID string) {
+	dataPath, err := s.objectPath(key)
+	if err != nil {
+		(&s3.Error{Code: s3.ErrorInvalidRequest, Message: err.Error()}).Response(w)
+		return
+	}
+
+	objectWriteMu.Lock()
+	defer objectWriteMu.Unlock()
+
+	defer s.trackKeyUsage(key)()
+
+	versions, err := s.objectVersions(key)
+	if err != nil {
+		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
+		return
+	}
+
+	for _, v := range versions {
+		if v.meta.VersionID != versionID {
+			continue
+		}
+
+		if v.current {
+			err = os.Remove(dataPath)
+			if err == nil || errors.Is(err, fs.ErrNotExist) {
+				err = removeMeta(metaPathFor(dataPath))
+			}
+		} else {
+			err = s.removeArchivedVersion(key, versionID)
+		}
+
+		if err == nil {
+			err = s.promoteLatest(key, dataPath)
+		}
+
+		if err != nil {
+			(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
+			return
+		}
+
+		if v.meta.DeleteMarker {
+			w.Header().Set("X-Amz-Delete-Marker", "true")
+		}
+
+		break
+	}
+
+	w.Header().Set("X-Amz-Version-Id", versionID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// versioningConfiguration is the XML body of Get/PutBucketVersioning.
+type versioningConfiguration struct {
+	XMLName xml.Name `xml:"VersioningConfiguration"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
+	Status  string   `xml:"Status,omitempty"`
+}
+
+func (s *Server) getBucketVersioning(w http.ResponseWriter) {
+	body, err := xml.Marshal(&versioningConfiguration{
+		Xmlns:  "http://s3.amazonaws.com/doc/2006-03-01/",
+		Status: string(s.versioning()),
+	})
+	if err != nil {
+		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>`))
+	_, _ = w.Write(body)
+}
+
+func (s *Server) putBucketVersioning(w http.ResponseWriter, r *http.Request) {
+	if s.OnVersioningChange == nil {
+		(&s3.Error{Code: s3.ErrorCodeNotImplemented, Message: "Bucket versioning is managed by the Incus API."}).Response(w)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
+		return
+	}
+
+	req := &versioningConfiguration{}
+	err = xml.Unmarshal(body, req)
+	if err != nil {
+		(&s3.Error{Code: s3.ErrorInvalidRequest, Message: err.Error()}).Response(w)
+		return
+	}
+
+	status := Versioning(req.Status)
+	if status != VersioningEnabled && status != VersioningSuspended {
+		(&s3.Error{Code: s3.ErrorInvalidRequest, Message: "Versioning status must be Enabled or Suspended."}).Response(w)
+		return
+	}
+
+	err = s.OnVersioningChange(status)
+	if err != nil {
+		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
+		return
+	}
+
+	s.Versioning = status
+	w.WriteHeader(http.StatusOK)
+}
+
+// listVersionsResult is the XML root for ListObjectVersions responses.
+type listVersionsResult struct {
+	XMLName         xml.Name           `xml:"ListVersionsResult"`
+	Prefix          string             `xml:"Prefix"`
+	KeyMarker       string             `xml:"KeyMarker"`
+	VersionIDMarker string             `xml:"VersionIdMarker"`
+	NextKeyMarker   string             `xml:"NextKeyMarker,omitempty"`
+	MaxKeys         int                `xml:"MaxKeys"`
+	IsTruncated     bool               `xml:"IsTruncated"`
+	Versions        []listVersion      `xml:"Version"`
+	DeleteMarkers   []listDeleteMarker `xml:"DeleteMarker"`
+}
+
+type listVersion struct {
+	Key          string `xml:"Key"`
+	VersionID    string `xml:"VersionId"`
+	IsLatest     bool   `xml:"IsLatest"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int64  `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+type listDeleteMarker struct {
+	Key          string `xml:"Key"`
+	VersionID    string `xml:"VersionId"`
+	IsLatest     bool   `xml:"IsLatest"`
+	LastModified string `xml:"LastModified"`
+}
+
+// archivedKeys returns the keys which have archived versions or delete markers.
+func (s *Server) archivedKeys() ([]string, error) {
+	entries, err := os.ReadDir(s.versionsDir())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	keys := []string{}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+
+		files, err := os.ReadDir(filepath.Join(s.versionsDir(), e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, f := range files {
+			if !strings.HasSuffix(f.Name(), metaSuffix) {
+				continue
+			}
+
+			meta, err := readMeta(filepath.Join(s.versionsDir(), e.Name(), f.Name()))
+			if err != nil || meta.Key == "" {
+				continue
+			}
+
+			keys = append(keys, meta.Key)
+			break
+		}
+	}
+
+	return keys, nil
+}
+
+// listObjectVersions implements ListObjectVersions.
+// Pagination happens on key boundaries, all versions of a key are always
+// returned in the same page.
+func (s *Server) listObjectVersions(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	prefix := q.Get("prefix")
+	keyMarker := q.Get("key-marker")
+
+	maxKeys := 1000
+
+	v := q.Get("max-keys")
+	if v != "" {
+		n, err := strconv.Atoi(v)
+		if err == nil && n > 0 && n < 1000 {
+			maxKeys = n
+		}
+	}
+
+	current, err := s.collectKeys()
+	if err != nil {
+		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
+		return
+	}
+
+	archived, err := s.archivedKeys()
+	if err != nil {
+		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
+		return
+	}
+
+	keys := map[string]bool{}
+	for _, k := range append(current, archived...) {
+		keys[k] = true
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+
+	sort.Strings(sorted)
+
+	result := &listVersionsResult{
+		Prefix:    prefix,
+		KeyMarker: keyMarker,
+		MaxKeys:   maxKeys,
+	}
+
+	count := 0
+	for _, k := range sorted {
+		if keyMarker != "" && k <= keyMarker {
+			continue
+		}
+
+		if prefix != "" && !strings.HasPrefix(k, prefix) {
+			continue
+		}
+
+		versions, err := s.objectVersions(k)
+		if err != nil {
+			// Key vanished or became invalid between walk and read.
+			continue
+		}
+
+		if len(versions) == 0 {
+			continue
+		}
+
+		if count > 0 && count+len(versions) > maxKeys {
+			result.IsTruncated = true
+			result.NextKeyMarker = result.lastKey()
+			break
+		}
+
+		for i, ver := range versions {
+			lastMod := ver.meta.LastMod.UTC().Format("2006-01-02T15:04:05.000Z")
+			if ver.meta.DeleteMarker {
+				result.DeleteMarkers = append(result.DeleteMarkers, listDeleteMarker{
+					Key:          k,
+					VersionID:    ver.meta.VersionID,
+					IsLatest:     i == 0,
+					LastModified: lastMod,
+				})
+
+				continue
+			}
+
+			result.Versions = append(result.Versions, listVersion{
+				Key:          k,
+				VersionID:    ver.meta.VersionID,
+				IsLatest:     i == 0,
+				LastModified: lastMod,
+				ETag:         `"` + ver.meta.ETag + `"`,
+				Size:         ver.meta.Size,
+				StorageClass: "STANDARD",
+			})
+		}
+
+		count += len(versions)
+	}
+
+	body, err := xml.Marshal(result)
+	if err != nil {
+		(&s3.Error{Code: s3.ErrorCodeInternalError, Message: err.Error()}).Response(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>`))
+	_, _ = w.Write(body)
+}
+
+// lastKey returns the last key included in the listing.
+func (r *listVersionsResult) lastKey() string {
+	last := ""
+	for _, v := range r.Versions {
+		if v.Key > last {
+			last = v.Key
+		}
+	}
+
+	for _, m := range r.DeleteMarkers {
+		if m.Key > last {
+			last = m.Key
+		}
+	}
+
+	return last
+}
@@ -24,13 +24,29 @@ const ErrorCodePreconditionFailed = "PreconditionFailed"
 // ErrorCodeNotImplemented means the requested functionality isn't implemented.
 const ErrorCodeNotImplemented = "NotImplemented"
 
+// ErrorCodeNoSuchVersion means the specified object version does not exist.
+const ErrorCodeNoSuchVersion = "NoSuchVersion"
+
+// ErrorCodeMethodNotAllowed means the request isn't allowed against the targeted resource.
+const ErrorCodeMethodNotAllowed = "MethodNotAllowed"
+
+// ErrorCodeNoSuchLifecycleConfiguration means the bucket has no lifecycle configuration.
+const ErrorCodeNoSuchLifecycleConfiguration = "NoSuchLifecycleConfiguration"
+
+// ErrorCodeQuotaExceeded means the request would exceed the quota of the bucket or of the access key.
+const ErrorCodeQuotaExceeded = "QuotaExceeded"
+
 var errorHTTPStatusCodes = map[string]int{
-	ErrorCodeNoSuchBucket:       http.StatusNotFound,
-	ErrorCodeInternalError:      http.StatusInternalServerError,
-	ErrorCodeInvalidAccessKeyID: http.StatusForbidden,
-	ErrorInvalidRequest:         http.StatusBadRequest,
-	ErrorCodePreconditionFailed: http.StatusPreconditionFailed,
-	ErrorCodeNotImplemented:     http.StatusNotImplemented,
+	ErrorCodeNoSuchBucket:                 http.StatusNotFound,
+	ErrorCodeInternalError:                http.StatusInternalServerError,
+	ErrorCodeInvalidAccessKeyID:           http.StatusForbidden,
+	ErrorInvalidRequest:                   http.StatusBadRequest,
+	ErrorCodePreconditionFailed:           http.StatusPreconditionFailed,
+	ErrorCodeNotImplemented:               http.StatusNotImplemented,
+	ErrorCodeNoSuchVersion:                http.StatusNotFound,
+	ErrorCodeMethodNotAllowed:             http.StatusMethodNotAllowed,
+	ErrorCodeNoSuchLifecycleConfiguration: http.StatusNotFound,
+	ErrorCodeQuotaExceeded:                http.StatusForbidden,
 }
 
 // Error S3 error response.
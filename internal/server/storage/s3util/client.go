@@ -0,0 +1,39 @@
+package s3util
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	localtls "github.com/lxc/incus/v7/shared/tls"
+)
+
+// NewClient returns an S3 client for the endpoint at uri using path-style addressing.
+func NewClient(uri *url.URL, accessKey string, secretKey string) *s3.Client {
+	// Get a basic TLS client.
+	tlsConfig := localtls.InitTLSConfig()
+
+	// Setup the transport.
+	ts := &http.Transport{
+		MaxIdleConns:       10,
+		IdleConnTimeout:    30 * time.Second,
+		DisableCompression: true,
+		TLSClientConfig:    tlsConfig,
+	}
+
+	cfg := aws.Config{
+		Region:      RegionFromURL(uri),
+		Credentials: credentials.NewStaticCredentialsProvider(accessKey, secretKey, ""),
+		HTTPClient:  &http.Client{Transport: ts},
+	}
+
+	return s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.BaseEndpoint = aws.String(fmt.Sprintf("%s://%s", uri.Scheme, uri.Host))
+		o.UsePathStyle = true
+	})
+}
@@ -283,6 +283,20 @@ func VolumeDBCreate(pool Pool, projectName string, volumeName string, volumeDesc
 		if err != nil {
 			return err
 		}
+	} else if util.IsTrue(volumeConfig["block.encryption"]) {
+		// Snapshots of encrypted volumes share the key of their parent volume.
+		parentName, _, _ := api.GetParentAndSnapshotName(volumeName)
+
+		parentVol, err := VolumeDBGet(pool, projectName, parentName, volumeType)
+		if err != nil {
+			return err
+		}
+
+		if parentVol.Config[drivers.EncryptionKeyConfigKey] != "" {
+			volumeConfig[drivers.EncryptionKeyConfigKey] = parentVol.Config[drivers.EncryptionKeyConfigKey]
+		} else {
+			delete(volumeConfig, drivers.EncryptionKeyConfigKey)
+		}
 	}
 
 	// Validate config.
@@ -499,6 +513,18 @@ func poolAndVolumeCommonRules(vol *drivers.Volume) map[string]func(string) error
 		},
 		"snapshots.schedule": validate.Optional(validate.IsCron([]string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@annually", "@yearly"})),
 		"snapshots.pattern":  validate.IsAny,
+		"snapshots.retention": func(value string) error {
+			_, err := internalInstance.ParseSnapshotRetention(value)
+			return err
+		},
+		"backups.expiry": func(value string) error {
+			// Validate expression
+			_, err := internalInstance.GetExpiry(time.Time{}, value)
+			return err
+		},
+		"backups.retention.count": validate.Optional(validate.IsUint32),
+		"backups.schedule":        validate.Optional(validate.IsCron(internalInstance.BackupScheduleAliases)),
+		"backups.target":          validate.Optional(internalInstance.ValidateBackupTargetName),
 	}
 
 	// Options relevant for custom filesystem volumes.
@@ -510,9 +536,10 @@ func poolAndVolumeCommonRules(vol *drivers.Volume) map[string]func(string) error
 		rules["initial.mode"] = validate.Optional(validate.IsInt64)
 	}
 
-	// security.shared is only relevant for custom block volumes.
+	// security.shared and backups.repository are only relevant for custom block volumes.
 	if (vol == nil) || (vol != nil && vol.Type() == drivers.VolumeTypeCustom && vol.ContentType() == drivers.ContentTypeBlock) {
 		rules["security.shared"] = validate.Optional(validate.IsBool)
+		rules["backups.repository"] = validate.Optional(internalInstance.ValidateBackupRepository)
 	}
 
 	return rules
@@ -526,6 +553,7 @@ func validatePoolCommonRules() map[string]func(string) error {
 		"volatile.initial_source": validate.IsAny,
 		"rsync.bwlimit":           validate.Optional(validate.IsSize),
 		"rsync.compression":       validate.Optional(validate.IsBool),
+		"check.schedule":          validate.Optional(validate.IsCron([]string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@annually", "@yearly"})),
 	}
 
 	// Add to pool config rules (prefixed with volume.*) which are common for pool and volume.
@@ -557,6 +585,11 @@ func validateVolumeCommonRules(vol drivers.Volume) map[string]func(string) error
 		rules["block.filesystem"] = validate.IsAny
 	}
 
+	// volatile.encryption.key is only used for encrypted block backed volumes and block volumes.
+	if vol.IsBlockBacked() || vol.ContentType() == drivers.ContentTypeBlock {
+		rules[drivers.EncryptionKeyConfigKey] = validate.Optional(validate.IsBase64)
+	}
+
 	// volatile.rootfs.size is only used for image volumes.
 	if vol.Type() == drivers.VolumeTypeImage {
 		rules["volatile.rootfs.size"] = validate.Optional(validate.IsInt64)
@@ -1324,6 +1357,14 @@ func DisallowedStorageConfigForCreation(driverName string) []string {
 	return []string{}
 }
 
+// backupPool returns the storage pool to record in a backup file, without its encryption master key.
+func backupPool(pool api.StoragePool) *api.StoragePool {
+	pool.Config = util.CloneMap(pool.Config)
+	delete(pool.Config, drivers.EncryptionMasterKeyConfigKey)
+
+	return &pool
+}
+
 // ClusterWideStorageConfig returns a list of keys that are cluster wide.
 func ClusterWideStorageConfig(driverName string) []string {
 	if driverName == "lvmcluster" {
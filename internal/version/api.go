@@ -570,6 +570,32 @@ var APIExtensions = []string{
 	"device_burst_limits",
 	"network_ipv6_ra",
 	"qemu_scriptlet_nvram",
+	"storage_bucket_versioning",
+	"backup_schedule",
+	"snapshot_retention",
+	"storage_block_encryption",
+	"storage_driver_nfs",
+	"storage_volume_state_details",
+	"instance_pool_move_live",
+	"storage_pool_check",
+	"backup_repository",
+	"storage_bucket_quotas",
+	"network_bridge_load_balancers",
+	"network_bridge_peers",
+	"network_bgp_import",
+	"instance_nic_mirror",
+	"network_flow_export",
+	"network_egress_gateway",
+	"network_integrations_wireguard",
+	"network_capture",
+	"network_zones_dns_server",
+	"network_leases_reservations",
+	"network_qos_classes",
+	"instance_healthcheck",
+	"instance_boot_depends",
+	"instance_ignition",
+	"instance_memory_balloon",
+	"console_vnc_type",
 }
 
 // APIExtensionsCount returns the number of available API extensions.
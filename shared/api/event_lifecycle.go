@@ -44,6 +44,7 @@ const (
 	EventLifecycleInstanceFileDeleted               = "instance-file-deleted"
 	EventLifecycleInstanceFilePushed                = "instance-file-pushed"
 	EventLifecycleInstanceFileRetrieved             = "instance-file-retrieved"
+	EventLifecycleInstanceHealthChanged             = "instance-health-changed"
 	EventLifecycleInstanceLogDeleted                = "instance-log-deleted"
 	EventLifecycleInstanceLogRetrieved              = "instance-log-retrieved"
 	EventLifecycleInstanceMetadataRetrieved         = "instance-metadata-retrieved"
@@ -78,6 +79,9 @@ const (
 	EventLifecycleNetworkAddressSetUpdated          = "network-address-set-updated"
 	EventLifecycleNetworkCreated                    = "network-created"
 	EventLifecycleNetworkDeleted                    = "network-deleted"
+	EventLifecycleNetworkEgressGatewayCreated       = "network-egress-gateway-created"
+	EventLifecycleNetworkEgressGatewayDeleted       = "network-egress-gateway-deleted"
+	EventLifecycleNetworkEgressGatewayUpdated       = "network-egress-gateway-updated"
 	EventLifecycleNetworkForwardCreated             = "network-forward-created"
 	EventLifecycleNetworkForwardDeleted             = "network-forward-deleted"
 	EventLifecycleNetworkForwardUpdated             = "network-forward-updated"
@@ -85,6 +89,9 @@ const (
 	EventLifecycleNetworkIntegrationDeleted         = "network-integration-deleted"
 	EventLifecycleNetworkIntegrationRenamed         = "network-integration-renamed"
 	EventLifecycleNetworkIntegrationUpdated         = "network-integration-updated"
+	EventLifecycleNetworkLeaseCreated               = "network-lease-created"
+	EventLifecycleNetworkLeaseDeleted               = "network-lease-deleted"
+	EventLifecycleNetworkLeaseUpdated               = "network-lease-updated"
 	EventLifecycleNetworkLoadBalancerCreated        = "network-load-balancer-created"
 	EventLifecycleNetworkLoadBalancerDeleted        = "network-load-balancer-deleted"
 	EventLifecycleNetworkLoadBalancerUpdated        = "network-load-balancer-updated"
@@ -72,6 +72,13 @@ type InstanceBackupsPost struct {
 	//
 	// API extension: backup_s3_upload
 	Target *BackupTarget `json:"target" yaml:"target"`
+
+	// Backup repository (backup target name) to store the root disk of the virtual machine in
+	// Only the chunks which changed since the previous backup get stored.
+	// Example: /srv/backups
+	//
+	// API extension: backup_repository
+	Repository string `json:"repository,omitempty" yaml:"repository,omitempty"`
 }
 
 // InstanceBackup represents an instance backup.
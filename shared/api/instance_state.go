@@ -70,6 +70,11 @@ type InstanceState struct {
 	//
 	// API extension: instances_state_os_info.
 	OSInfo *InstanceStateOSInfo `json:"os_info" yaml:"os_info"`
+
+	// Health check information (only set when a health check is configured).
+	//
+	// API extension: instance_healthcheck.
+	Health *InstanceStateHealth `json:"health,omitempty" yaml:"health,omitempty"`
 }
 
 // InstanceStateDisk represents the disk information section of an instance's state.
@@ -133,6 +138,12 @@ type InstanceStateMemory struct {
 	// Peak SWAP usage in bytes
 	// Example: 12297557
 	SwapUsagePeak int64 `json:"swap_usage_peak" yaml:"swap_usage_peak"`
+
+	// Memory reclaimed by the balloon in bytes (virtual machines only)
+	// Example: 536870912
+	//
+	// API extension: instance_memory_balloon
+	Balloon int64 `json:"balloon,omitempty" yaml:"balloon,omitempty"`
 }
 
 // InstanceStateNetwork represents the network information section of an instance's state.
@@ -258,3 +269,26 @@ type InstanceStateOSInfo struct {
 	// Example: myhost.mydomain.local
 	FQDN string `json:"fqdn" yaml:"fqdn"`
 }
+
+// InstanceStateHealth represents the health check information section of an instance's state.
+//
+// swagger:model
+//
+// API extension: instance_healthcheck.
+type InstanceStateHealth struct {
+	// Health status (starting, healthy or unhealthy)
+	// Example: healthy
+	Status string `json:"status" yaml:"status"`
+
+	// Number of consecutive failed checks
+	// Example: 0
+	Failures int64 `json:"failures" yaml:"failures"`
+
+	// The time of the last check
+	// Example: 2021-03-23T20:00:00-04:00
+	LastCheck time.Time `json:"last_check" yaml:"last_check"`
+
+	// Error returned by the last failed check
+	// Example: Connection refused
+	LastError string `json:"last_error" yaml:"last_error"`
+}
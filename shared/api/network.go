@@ -1,5 +1,10 @@
 package api
 
+import (
+	"net"
+	"strings"
+)
+
 // NetworksPost represents the fields of a new network
 //
 // swagger:model
@@ -141,6 +146,83 @@ type NetworkLease struct {
 	//
 	// API extension: network_leases_location
 	Location string `json:"location" yaml:"location"`
+
+	// Description of the address reservation
+	// Example: Printer on the second floor
+	//
+	// API extension: network_leases_reservations
+	Description string `json:"description" yaml:"description"`
+}
+
+// Etag returns the values used for etag generation.
+//
+// API extension: network_leases_reservations.
+func (l *NetworkLease) Etag() []any {
+	return []any{l.Address, l.Description, l.Hwaddr, l.Hostname}
+}
+
+// Writable converts a full NetworkLease struct into a NetworkLeasePut struct (filters read-only fields).
+//
+// API extension: network_leases_reservations.
+func (l *NetworkLease) Writable() NetworkLeasePut {
+	return NetworkLeasePut{
+		Description: l.Description,
+		Hwaddr:      l.Hwaddr,
+		Hostname:    l.Hostname,
+	}
+}
+
+// NetworkLeasesPost represents the fields of a new address reservation
+//
+// swagger:model
+//
+// API extension: network_leases_reservations.
+type NetworkLeasesPost struct {
+	NetworkLeasePut `yaml:",inline"`
+
+	// The reserved IP address
+	// Example: 10.0.0.50
+	Address string `json:"address" yaml:"address"`
+}
+
+// Normalise normalises the fields in the address reservation so that they are comparable with ones stored.
+func (l *NetworkLeasesPost) Normalise() {
+	ip := net.ParseIP(l.Address)
+	if ip != nil {
+		l.Address = ip.String() // Replace with canonical form if specified.
+	}
+
+	l.NetworkLeasePut.Normalise()
+}
+
+// NetworkLeasePut represents the modifiable fields of an address reservation
+//
+// swagger:model
+//
+// API extension: network_leases_reservations.
+type NetworkLeasePut struct {
+	// Description of the address reservation
+	// Example: Printer on the second floor
+	Description string `json:"description" yaml:"description"`
+
+	// The MAC address the reserved address is handed out to over DHCP (empty to only keep the address out of the DHCP pool)
+	// Example: 10:66:6a:2c:89:d9
+	Hwaddr string `json:"hwaddr" yaml:"hwaddr"`
+
+	// The hostname associated with the reserved address
+	// Example: printer
+	Hostname string `json:"hostname" yaml:"hostname"`
+}
+
+// Normalise normalises the fields in the address reservation so that they are comparable with ones stored.
+func (l *NetworkLeasePut) Normalise() {
+	l.Description = strings.TrimSpace(l.Description)
+	l.Hostname = strings.TrimSpace(l.Hostname)
+
+	mac, err := net.ParseMAC(l.Hwaddr)
+	if err == nil {
+		l.Hwaddr = mac.String() // Replace with canonical form if specified.
+	}
 }
 
 // NetworkState represents the network state
@@ -188,6 +270,11 @@ type NetworkState struct {
 	//
 	// API extension: network_state_ovn
 	OVN *NetworkStateOVN `json:"ovn" yaml:"ovn"`
+
+	// BGP sessions and learned routes
+	//
+	// API extension: network_bgp_import
+	BGP *NetworkStateBGP `json:"bgp" yaml:"bgp"`
 }
 
 // NetworkStateAddress represents a network address
@@ -313,6 +400,65 @@ type NetworkStateVLAN struct {
 	VID uint64 `json:"vid" yaml:"vid"`
 }
 
+// NetworkStateBGP represents the BGP state of a network
+//
+// swagger:model
+//
+// API extension: network_bgp_import.
+type NetworkStateBGP struct {
+	// List of BGP sessions
+	Sessions []NetworkStateBGPSession `json:"sessions" yaml:"sessions"`
+
+	// List of routes learned from the BGP peers
+	Routes []NetworkStateBGPRoute `json:"routes" yaml:"routes"`
+}
+
+// NetworkStateBGPSession represents the state of a BGP session
+//
+// swagger:model
+//
+// API extension: network_bgp_import.
+type NetworkStateBGPSession struct {
+	// Peer address (or interface name for unnumbered peers)
+	// Example: 192.0.2.1
+	Peer string `json:"peer" yaml:"peer"`
+
+	// Peer AS number
+	// Example: 65000
+	ASN uint32 `json:"asn" yaml:"asn"`
+
+	// Session state
+	// Example: established
+	State string `json:"state" yaml:"state"`
+
+	// BFD session state (empty when BFD isn't enabled)
+	// Example: up
+	BFDState string `json:"bfd_state" yaml:"bfd_state"`
+}
+
+// NetworkStateBGPRoute represents a route learned from a BGP peer
+//
+// swagger:model
+//
+// API extension: network_bgp_import.
+type NetworkStateBGPRoute struct {
+	// Route prefix
+	// Example: 0.0.0.0/0
+	Prefix string `json:"prefix" yaml:"prefix"`
+
+	// Next hop address
+	// Example: 192.0.2.1
+	Nexthop string `json:"nexthop" yaml:"nexthop"`
+
+	// Peer the route was learned from
+	// Example: 192.0.2.1
+	Peer string `json:"peer" yaml:"peer"`
+
+	// Whether the route matches the network's import filter
+	// Example: true
+	Imported bool `json:"imported" yaml:"imported"`
+}
+
 // NetworkStateOVN represents OVN specific state
 //
 // swagger:model
@@ -0,0 +1,20 @@
+package api
+
+// NetworkCapturePost represents a packet capture request on a network or an instance NIC.
+//
+// swagger:model
+//
+// API extension: network_capture.
+type NetworkCapturePost struct {
+	// Capture filter expression selecting the captured packets (restricted subset of the pcap-filter syntax)
+	// Example: tcp port 443 and host 10.0.0.2
+	Filter string `json:"filter" yaml:"filter"`
+
+	// Maximum duration of the capture in seconds (0 for no limit)
+	// Example: 60
+	Duration int64 `json:"duration" yaml:"duration"`
+
+	// Maximum number of captured packets (0 for no limit)
+	// Example: 1000
+	Packets int64 `json:"packets" yaml:"packets"`
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"net"
+	"strings"
+)
+
+// NetworkEgressGatewaysPost represents the fields of a new network egress gateway
+//
+// swagger:model
+//
+// API extension: network_egress_gateway.
+type NetworkEgressGatewaysPost struct {
+	NetworkEgressGatewayPut `yaml:",inline"`
+
+	// The external address used as the source of the outbound traffic
+	// Example: 192.0.2.1
+	Address string `json:"address" yaml:"address"`
+}
+
+// Normalise normalises the fields in the egress gateway so that they are comparable with ones stored.
+func (g *NetworkEgressGatewaysPost) Normalise() {
+	ip := net.ParseIP(g.Address)
+	if ip != nil {
+		g.Address = ip.String() // Replace with canonical form if specified.
+	}
+
+	g.NetworkEgressGatewayPut.Normalise()
+}
+
+// NetworkEgressGatewayPut represents the modifiable fields of a network egress gateway
+//
+// swagger:model
+//
+// API extension: network_egress_gateway.
+type NetworkEgressGatewayPut struct {
+	// Description of the egress gateway
+	// Example: Outbound address of customer A
+	Description string `json:"description" yaml:"description"`
+
+	// Egress gateway configuration map (refer to doc/network-egress-gateways.md)
+	// Example: {"user.mykey": "foo"}
+	Config ConfigMap `json:"config" yaml:"config"`
+
+	// Instances using the egress gateway (optionally prefixed with their project as "<project>/<instance>")
+	// Example: ["c1", "other-project/c2"]
+	Instances []string `json:"instances" yaml:"instances"`
+
+	// Profiles whose instances use the egress gateway (optionally prefixed with their project as "<project>/<profile>")
+	// Example: ["customer-a"]
+	Profiles []string `json:"profiles" yaml:"profiles"`
+
+	// Projects whose instances use the egress gateway
+	// Example: ["customer-a"]
+	Projects []string `json:"projects" yaml:"projects"`
+}
+
+// Normalise normalises the fields in the egress gateway so that they are comparable with ones stored.
+func (g *NetworkEgressGatewayPut) Normalise() {
+	g.Description = strings.TrimSpace(g.Description)
+
+	for _, list := range [][]string{g.Instances, g.Profiles, g.Projects} {
+		for i := range list {
+			list[i] = strings.TrimSpace(list[i])
+		}
+	}
+}
+
+// NetworkEgressGateway used for displaying a network egress gateway
+//
+// swagger:model
+//
+// API extension: network_egress_gateway.
+type NetworkEgressGateway struct {
+	NetworkEgressGatewayPut `yaml:",inline"`
+
+	// The external address used as the source of the outbound traffic
+	// Example: 192.0.2.1
+	Address string `json:"address" yaml:"address"`
+}
+
+// Etag returns the values used for etag generation.
+func (g *NetworkEgressGateway) Etag() []any {
+	return []any{g.Address, g.Description, g.Config, g.Instances, g.Profiles, g.Projects}
+}
+
+// Writable converts a full NetworkEgressGateway struct into a NetworkEgressGatewayPut struct (filters read-only fields).
+func (g *NetworkEgressGateway) Writable() NetworkEgressGatewayPut {
+	return g.NetworkEgressGatewayPut
+}
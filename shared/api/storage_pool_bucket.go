@@ -141,6 +141,12 @@ type StorageBucketKeyPut struct {
 	//
 	// API extension: storage_buckets
 	SecretKey string `json:"secret-key" yaml:"secret-key"`
+
+	// Maximum size of the objects stored with the key (empty for unlimited)
+	// Example: 10GiB
+	//
+	// API extension: storage_bucket_quotas
+	Quota string `json:"quota" yaml:"quota"`
 }
 
 // StorageBucketKey represents the fields of a storage pool bucket key
@@ -165,7 +171,7 @@ func (b *StorageBucketKey) URL(apiVersion string, poolName string, projectName s
 
 // Etag returns the values used for etag generation.
 func (b *StorageBucketKey) Etag() []any {
-	return []any{b.Name, b.Description, b.Role, b.AccessKey, b.SecretKey}
+	return []any{b.Name, b.Description, b.Role, b.AccessKey, b.SecretKey, b.Quota}
 }
 
 // Writable converts a full StorageBucketKey struct into a StorageBucketKeyPut struct (filters read-only fields).
@@ -0,0 +1,37 @@
+package api
+
+// StorageBucketState represents the live state of a storage bucket
+//
+// swagger:model
+//
+// API extension: storage_bucket_quotas.
+type StorageBucketState struct {
+	// Bucket usage
+	Usage StorageBucketStateUsage `json:"usage" yaml:"usage"`
+
+	// Usage of each bucket key, by key name
+	Keys map[string]StorageBucketStateUsage `json:"keys" yaml:"keys"`
+}
+
+// StorageBucketStateUsage represents the usage of a storage bucket or of one of its keys
+//
+// swagger:model
+//
+// API extension: storage_bucket_quotas.
+type StorageBucketStateUsage struct {
+	// Number of objects
+	// Example: 1024
+	Objects int64 `json:"objects" yaml:"objects"`
+
+	// Used space in bytes, including object versions and incomplete multipart uploads
+	// Example: 1693552640
+	Used int64 `json:"used" yaml:"used"`
+
+	// Maximum number of objects (-1 for unlimited)
+	// Example: 10000
+	ObjectsQuota int64 `json:"objects_quota" yaml:"objects_quota"`
+
+	// Maximum used space in bytes (-1 for unlimited)
+	// Example: 5189222192
+	Total int64 `json:"total" yaml:"total"`
+}
@@ -201,11 +201,11 @@ type StorageVolumePut struct {
 //
 // API extension: storage_api_local_volume_handling.
 type StorageVolumeSource struct {
-	// Source volume name (for copy)
+	// Source volume name (for copy or repository, where it can be prefixed with the volume type)
 	// Example: foo
 	Name string `json:"name" yaml:"name"`
 
-	// Source type (copy or migration)
+	// Source type (copy, migration or repository)
 	// Example: copy
 	Type string `json:"type" yaml:"type"`
 
@@ -266,6 +266,18 @@ type StorageVolumeSource struct {
 	//
 	// API extension: cluster_internal_custom_volume_copy
 	Location string `json:"location" yaml:"location"`
+
+	// Backup repository (backup target name) to restore from (for repository)
+	// Example: /srv/backups
+	//
+	// API extension: backup_repository
+	Repository string `json:"repository,omitempty" yaml:"repository,omitempty"`
+
+	// Name of the backup to restore, defaults to the latest one (for repository)
+	// Example: 20261017-020000
+	//
+	// API extension: backup_repository
+	Backup string `json:"backup,omitempty" yaml:"backup,omitempty"`
 }
 
 // Writable converts a full StorageVolume struct into a StorageVolumePut struct (filters read-only fields).
@@ -62,6 +62,13 @@ type StorageVolumeBackupsPost struct {
 	//
 	// API extension: backup_s3_upload
 	Target *BackupTarget `json:"target" yaml:"target"`
+
+	// Backup repository (backup target name) to store the block volume in
+	// Only the chunks which changed since the previous backup get stored.
+	// Example: /srv/backups
+	//
+	// API extension: backup_repository
+	Repository string `json:"repository,omitempty" yaml:"repository,omitempty"`
 }
 
 // StorageVolumeBackupPost represents the fields available for the renaming of a volume backup
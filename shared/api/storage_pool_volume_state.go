@@ -25,4 +25,22 @@ type StorageVolumeStateUsage struct {
 	//
 	// API extension: storage_volume_state_total
 	Total int64 `json:"total" yaml:"total"`
+
+	// Space used exclusively by the volume's snapshots in bytes
+	// Example: 104857600
+	//
+	// API extension: storage_volume_state_details
+	Snapshots uint64 `json:"snapshots,omitempty" yaml:"snapshots,omitempty"`
+
+	// Ratio between the logical and the stored size of the volume data
+	// Example: 1.84
+	//
+	// API extension: storage_volume_state_details
+	CompressionRatio float64 `json:"compression_ratio,omitempty" yaml:"compression_ratio,omitempty"`
+
+	// Ratio between the provisioned and the allocated size of a thin-provisioned volume
+	// Example: 4.5
+	//
+	// API extension: storage_volume_state_details
+	OvercommitRatio float64 `json:"overcommit_ratio,omitempty" yaml:"overcommit_ratio,omitempty"`
 }